@@ -0,0 +1,17 @@
+-- migrate:up
+create table payments (
+    id int generated always as identity,
+    purchaser_id int not null,
+    amount int not null check (amount >= 0),
+    status varchar(20) not null check (status in ('accepted', 'declined', 'voided')),
+    -- Identifier of the payment with the payment processor.
+    reference text not null,
+    created_at timestamptz not null default now(),
+
+    foreign key (purchaser_id) references users (id),
+    primary key (id)
+);
+
+
+-- migrate:down
+drop table payments;
@@ -0,0 +1,34 @@
+-- migrate:up
+create table orders (
+    id int generated always as identity,
+    purchaser_id int not null,
+    payment_id int not null,
+    status varchar(20) not null check (status in ('completed')),
+    total int not null check (total >= 0),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+
+    foreign key (purchaser_id) references users (id),
+    foreign key (payment_id) references payments (id),
+    primary key (id)
+);
+
+create table order_items (
+    id int generated always as identity,
+    order_id int not null,
+    ticket_id int not null,
+    -- Price of the ticket at the time of purchase.
+    price int not null check (price >= 0),
+
+    unique (order_id, ticket_id),
+    foreign key (order_id) references orders (id),
+    foreign key (ticket_id) references tickets (id),
+    primary key (id)
+);
+
+create index on orders (purchaser_id);
+
+
+-- migrate:down
+drop table order_items;
+drop table orders;
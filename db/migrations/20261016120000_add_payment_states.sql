@@ -0,0 +1,35 @@
+-- migrate:up
+-- Payments move through pending -> authorized -> captured, or end as voided,
+-- failed or refunded.
+alter table payments drop constraint payments_status_check;
+update payments set status = 'captured' where status = 'accepted';
+update payments set status = 'failed' where status = 'declined';
+
+alter table payments
+    add constraint payments_status_check check (
+        status in ('pending', 'authorized', 'captured', 'voided', 'failed', 'refunded')
+    ),
+    -- Pending payments have not been sent to the payment processor yet.
+    alter column reference drop not null,
+    add column decline_reason text,
+    add column updated_at timestamptz not null default now();
+
+create index on payments (status, updated_at);
+
+
+-- migrate:down
+drop index payments_status_updated_at_idx;
+
+alter table payments
+    drop column updated_at,
+    drop column decline_reason,
+    drop constraint payments_status_check;
+
+update payments set status = 'accepted' where status in ('captured', 'refunded');
+update payments set status = 'declined' where status = 'failed';
+update payments set status = 'voided' where status in ('pending', 'authorized');
+update payments set reference = '' where reference is null;
+
+alter table payments
+    alter column reference set not null,
+    add constraint payments_status_check check (status in ('accepted', 'declined', 'voided'));
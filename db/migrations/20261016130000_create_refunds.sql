@@ -0,0 +1,31 @@
+-- migrate:up
+-- Voided tickets have been refunded and withdrawn from sale.
+alter table tickets add column voided boolean not null default false;
+
+create table refunds (
+    id int generated always as identity,
+    payment_id int not null,
+    ticket_id int not null,
+    amount int not null check (amount > 0),
+    -- Refunds are recorded as pending along with returning their ticket, and
+    -- are completed once the payment processor has made them. Refunds that the
+    -- payment processor won't make are recorded as failed.
+    status varchar(20) not null check (status in ('pending', 'completed', 'failed')),
+    -- Identifier of the refund with the payment processor, once it's been
+    -- made.
+    reference text,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+
+    foreign key (payment_id) references payments (id),
+    foreign key (ticket_id) references tickets (id),
+    primary key (id)
+);
+
+create index on refunds (payment_id);
+create index on refunds (updated_at) where status = 'pending';
+
+
+-- migrate:down
+drop table refunds;
+alter table tickets drop column voided;
@@ -0,0 +1,37 @@
+-- migrate:up
+create table event_cancellations (
+    id int generated always as identity,
+    event_id int not null unique,
+    -- Completed once every purchased ticket has been processed.
+    status varchar(20) not null check (status in ('pending', 'completed')),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+
+    foreign key (event_id) references events (id),
+    primary key (id)
+);
+
+-- Refunds owed for each ticket purchased for a cancelled event.
+create table cancellation_refunds (
+    id int generated always as identity,
+    cancellation_id int not null,
+    ticket_id int not null,
+    status varchar(20) not null check (status in ('pending', 'refunded', 'failed', 'skipped')),
+    attempts int not null default 0,
+    last_error text,
+    refund_id int,
+    updated_at timestamptz not null default now(),
+
+    unique (cancellation_id, ticket_id),
+    foreign key (cancellation_id) references event_cancellations (id),
+    foreign key (ticket_id) references tickets (id),
+    foreign key (refund_id) references refunds (id),
+    primary key (id)
+);
+
+create index on cancellation_refunds (status);
+
+
+-- migrate:down
+drop table cancellation_refunds;
+drop table event_cancellations;
@@ -0,0 +1,27 @@
+-- migrate:up
+-- Responses to mutating requests, so that retried requests can be replayed
+-- rather than processed again.
+create table idempotency_keys (
+    id int generated always as identity,
+    -- Keys are scoped to the user making the request, and are empty for
+    -- requests made without a user.
+    user_id text not null,
+    key varchar(255) not null,
+    -- Hash of the request's method, path and body.
+    fingerprint text not null,
+    -- Null until the response to the request has been recorded.
+    status_code int,
+    content_type text,
+    response_body bytea,
+    created_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+
+    unique (user_id, key),
+    primary key (id)
+);
+
+create index on idempotency_keys (expires_at);
+
+
+-- migrate:down
+drop table idempotency_keys;
@@ -0,0 +1,35 @@
+-- migrate:up
+-- Amounts are in the minor units of an ISO 4217 currency, e.g. cents for USD.
+-- Existing amounts were all in whole USD, so they're rescaled to cents.
+alter table tickets
+    alter column price type bigint using price * 100,
+    add column currency char(3) not null default 'USD';
+alter table tickets alter column currency drop default;
+
+alter table payments
+    alter column amount type bigint using amount * 100,
+    add column currency char(3) not null default 'USD';
+alter table payments alter column currency drop default;
+
+alter table orders
+    alter column total type bigint using total * 100,
+    add column currency char(3) not null default 'USD';
+alter table orders alter column currency drop default;
+
+-- Order items and refunds are in the currency of their order and payment.
+alter table order_items alter column price type bigint using price * 100;
+alter table refunds alter column amount type bigint using amount * 100;
+
+
+-- migrate:down
+alter table refunds alter column amount type int using amount / 100;
+alter table order_items alter column price type int using price / 100;
+alter table orders
+    drop column currency,
+    alter column total type int using total / 100;
+alter table payments
+    drop column currency,
+    alter column amount type int using amount / 100;
+alter table tickets
+    drop column currency,
+    alter column price type int using price / 100;
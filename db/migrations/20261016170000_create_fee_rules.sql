@@ -0,0 +1,55 @@
+-- migrate:up
+-- Fees charged on purchases of tickets for a venue's events, or for a single
+-- event. An event's rule takes precedence over its venue's. Flat amounts are
+-- in the minor units of the event's currency.
+create table fee_rules (
+    id int generated always as identity,
+    venue_id int unique,
+    event_id int unique,
+    -- Per ticket service fee, as a rate of the ticket's face value in basis
+    -- points plus a flat amount.
+    service_fee_rate int not null default 0 check (service_fee_rate >= 0),
+    service_fee_flat bigint not null default 0 check (service_fee_flat >= 0),
+    -- Per order facility fee.
+    facility_fee bigint not null default 0 check (facility_fee >= 0),
+    updated_at timestamptz not null default now(),
+
+    check (num_nonnulls(venue_id, event_id) = 1),
+    foreign key (venue_id) references venues (id),
+    foreign key (event_id) references events (id),
+    primary key (id)
+);
+
+-- Sales tax rates by region. A rate for a subdivision takes precedence over
+-- the rate for its country, which has an empty subdivision.
+create table tax_rates (
+    id int generated always as identity,
+    -- ISO 3166-1 alpha3
+    country_code char(3) not null,
+    subdivision varchar(60) not null default '',
+    -- In basis points.
+    rate int not null check (rate >= 0),
+
+    unique (country_code, subdivision),
+    primary key (id)
+);
+
+-- Fees and tax charged at the time of purchase.
+alter table orders
+    add column facility_fee bigint not null default 0 check (facility_fee >= 0),
+    add column tax bigint not null default 0 check (tax >= 0);
+
+alter table order_items
+    add column service_fee bigint not null default 0 check (service_fee >= 0),
+    add column tax bigint not null default 0 check (tax >= 0);
+
+
+-- migrate:down
+alter table order_items
+    drop column tax,
+    drop column service_fee;
+alter table orders
+    drop column tax,
+    drop column facility_fee;
+drop table tax_rates;
+drop table fee_rules;
@@ -0,0 +1,61 @@
+-- migrate:up
+-- Codes that discount purchases of tickets, either for a single event or for
+-- all events when the event isn't set.
+create table promo_codes (
+    id int generated always as identity,
+    code varchar(40) not null,
+    -- A percentage discount takes a rate off of each ticket's face value, in
+    -- basis points. A fixed discount takes an amount off of the order's face
+    -- value, in the minor units of its currency.
+    discount_type varchar(20) not null check (discount_type in ('percentage', 'fixed')),
+    percent_off int not null default 0 check (percent_off between 0 and 10000),
+    amount_off bigint not null default 0 check (amount_off >= 0),
+    currency char(3),
+    event_id int,
+    -- The code can be redeemed at any time if unset.
+    starts_at timestamptz,
+    ends_at timestamptz,
+    -- The code can be redeemed any number of times if unset.
+    max_redemptions int check (max_redemptions > 0),
+    max_redemptions_per_user int check (max_redemptions_per_user > 0),
+    redemptions int not null default 0 check (redemptions >= 0),
+    deleted boolean not null default false,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+
+    check (discount_type <> 'fixed' or currency is not null),
+    foreign key (event_id) references events (id),
+    primary key (id)
+);
+
+-- Deleted codes can be reused.
+create unique index on promo_codes (code) where deleted = false;
+
+-- Number of times each user has redeemed a code.
+create table promo_code_redemptions (
+    promo_code_id int not null,
+    purchaser_id int not null,
+    redemptions int not null check (redemptions > 0),
+
+    foreign key (promo_code_id) references promo_codes (id),
+    foreign key (purchaser_id) references users (id),
+    primary key (promo_code_id, purchaser_id)
+);
+
+-- Discounts given at the time of purchase.
+alter table orders
+    add column promo_code_id int references promo_codes (id),
+    add column discount bigint not null default 0 check (discount >= 0);
+
+alter table order_items
+    add column discount bigint not null default 0 check (discount >= 0);
+
+
+-- migrate:down
+alter table order_items
+    drop column discount;
+alter table orders
+    drop column discount,
+    drop column promo_code_id;
+drop table promo_code_redemptions;
+drop table promo_codes;
@@ -0,0 +1,54 @@
+-- migrate:up
+-- Seating layout of a venue, as sections made up of rows of seats.
+create table venue_sections (
+    id int generated always as identity,
+    venue_id int not null,
+    name varchar(40) not null,
+
+    foreign key (venue_id) references venues (id),
+    primary key (id),
+    unique (venue_id, name)
+);
+
+create table venue_rows (
+    id int generated always as identity,
+    section_id int not null,
+    name varchar(10) not null,
+
+    foreign key (section_id) references venue_sections (id) on delete cascade,
+    primary key (id),
+    unique (section_id, name)
+);
+
+create table venue_seats (
+    id int generated always as identity,
+    row_id int not null,
+    number varchar(10) not null,
+    -- Position of the seat on the venue's seating chart.
+    x double precision not null,
+    y double precision not null,
+    accessible boolean not null default false,
+    obstructed boolean not null default false,
+
+    foreign key (row_id) references venue_rows (id) on delete cascade,
+    primary key (id),
+    unique (row_id, number)
+);
+
+-- Tickets for seats of the venue's layout are labelled with the seat's section
+-- by default, so the label is widened to fit.
+alter table tickets
+    alter column seat type varchar(40),
+    add column venue_seat_id int references venue_seats (id);
+
+-- A seat is only released once per event, unless its ticket is voided.
+create unique index on tickets (event_id, venue_seat_id) where voided = false;
+
+
+-- migrate:down
+alter table tickets
+    drop column venue_seat_id,
+    alter column seat type varchar(10);
+drop table venue_seats;
+drop table venue_rows;
+drop table venue_sections;
@@ -0,0 +1,28 @@
+-- migrate:up
+-- General admission inventory for an event, priced by tier. Rather than
+-- releasing a ticket for each place up front, a tier has a capacity, and its
+-- tickets are only issued as they're purchased.
+create table ga_tiers (
+    id int generated always as identity,
+    event_id int not null,
+    name varchar(40) not null,
+    price bigint not null check (price >= 0),
+    currency char(3) not null,
+    capacity int not null check (capacity > 0),
+    -- Number of tickets issued for the tier, which can't exceed its capacity.
+    sold int not null default 0 check (sold >= 0),
+
+    check (sold <= capacity),
+    foreign key (event_id) references events (id),
+    primary key (id),
+    unique (event_id, name)
+);
+
+alter table tickets
+    add column ga_tier_id int references ga_tiers (id);
+
+
+-- migrate:down
+alter table tickets
+    drop column ga_tier_id;
+drop table ga_tiers;
@@ -0,0 +1,32 @@
+-- migrate:up
+-- Classifies an event's tickets, e.g. "VIP" or "Student", so that the same
+-- seats can be sold at different prices. Tickets of a type are priced at the
+-- type's price, and are only on sale within the type's sale window, if it has
+-- one.
+create table ticket_types (
+    id int generated always as identity,
+    event_id int not null,
+    name varchar(40) not null check (char_length(name) > 0),
+    description text,
+    price bigint not null check (price > 0),
+    currency char(3) not null,
+    -- Requirements a ticket's holder must meet to be admitted, e.g. "Valid
+    -- student ID".
+    eligibility text[] not null default '{}',
+    sale_starts_at timestamptz,
+    sale_ends_at timestamptz,
+
+    check (sale_ends_at > sale_starts_at),
+    foreign key (event_id) references events (id),
+    primary key (id),
+    unique (event_id, name)
+);
+
+alter table tickets
+    add column ticket_type_id int references ticket_types (id);
+
+
+-- migrate:down
+alter table tickets
+    drop column ticket_type_id;
+drop table ticket_types;
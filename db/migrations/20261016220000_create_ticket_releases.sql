@@ -0,0 +1,48 @@
+-- migrate:up
+-- A set of an event's tickets released together, which are only on sale from
+-- when the release goes on sale until it goes off sale, if it's scheduled.
+create table ticket_releases (
+    id int generated always as identity,
+    event_id int not null,
+    on_sale_at timestamptz,
+    off_sale_at timestamptz,
+
+    check (off_sale_at > on_sale_at),
+    foreign key (event_id) references events (id),
+    primary key (id)
+);
+
+-- Early access to a release's tickets, within the presale's window, for users
+-- that have its access code or that are on its allowlist.
+create table presales (
+    id int generated always as identity,
+    release_id int not null,
+    name varchar(40) not null check (char_length(name) > 0),
+    starts_at timestamptz not null,
+    ends_at timestamptz not null,
+    access_code varchar(40),
+
+    check (ends_at > starts_at),
+    foreign key (release_id) references ticket_releases (id) on delete cascade,
+    primary key (id)
+);
+
+create table presale_users (
+    presale_id int not null,
+    user_id int not null,
+
+    foreign key (presale_id) references presales (id) on delete cascade,
+    foreign key (user_id) references users (id),
+    primary key (presale_id, user_id)
+);
+
+alter table tickets
+    add column release_id int references ticket_releases (id);
+
+
+-- migrate:down
+alter table tickets
+    drop column release_id;
+drop table presale_users;
+drop table presales;
+drop table ticket_releases;
@@ -0,0 +1,17 @@
+-- migrate:up
+-- Events with a virtual waiting room, which queues users wanting to hold the
+-- event's tickets and admits them in batches, so that an on-sale isn't
+-- swamped. A batch of `batch_size` users is admitted at each admission
+-- interval.
+create table waiting_rooms (
+    event_id int not null,
+    batch_size int not null check (batch_size > 0),
+    updated_at timestamptz not null default now(),
+
+    foreign key (event_id) references events (id) on delete cascade,
+    primary key (event_id)
+);
+
+
+-- migrate:down
+drop table waiting_rooms;
@@ -0,0 +1,10 @@
+-- migrate:up
+-- The maximum number of an event's tickets that a user can hold and purchase,
+-- across all of their holds and purchases. Events without a limit are
+-- unlimited.
+alter table events
+    add column max_tickets_per_user int check (max_tickets_per_user > 0);
+
+
+-- migrate:down
+alter table events drop column max_tickets_per_user;
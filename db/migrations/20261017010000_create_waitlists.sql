@@ -0,0 +1,24 @@
+-- migrate:up
+-- Users waiting for `quantity` tickets to a sold-out event. As tickets return
+-- to inventory, the users are offered them in the order they joined, by a
+-- purchase hold that's exclusive to the user until the offer expires. The
+-- hold is identified by `offer_token`.
+create table waitlist_entries (
+    event_id int not null,
+    user_id int not null,
+    quantity int not null check (quantity > 0),
+    joined_at timestamptz not null default now(),
+    offer_token text,
+    offer_expires_at timestamptz,
+
+    check ((offer_token is null) = (offer_expires_at is null)),
+    foreign key (event_id) references events (id) on delete cascade,
+    foreign key (user_id) references users (id),
+    primary key (event_id, user_id)
+);
+
+create index on waitlist_entries (event_id, joined_at) where offer_token is null;
+
+
+-- migrate:down
+drop table waitlist_entries;
@@ -0,0 +1,29 @@
+-- migrate:up
+-- Transfers of purchased tickets from their owner, the sender, to another
+-- user. A transfer is pending until the recipient accepts it, at which point
+-- the ticket's ownership moves to the recipient, or the sender cancels it.
+-- Transfers are kept once accepted or cancelled, as the ticket's history.
+create table ticket_transfers (
+    id int generated always as identity,
+    ticket_id int not null,
+    sender_id int not null,
+    recipient_id int not null,
+    status varchar(20) not null default 'pending' check (status in ('pending', 'accepted', 'cancelled')),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+
+    check (sender_id <> recipient_id),
+    foreign key (ticket_id) references tickets (id),
+    foreign key (sender_id) references users (id),
+    foreign key (recipient_id) references users (id),
+    primary key (id)
+);
+
+-- A ticket can only have one pending transfer at a time.
+create unique index on ticket_transfers (ticket_id) where status = 'pending';
+create index on ticket_transfers (sender_id);
+create index on ticket_transfers (recipient_id);
+
+
+-- migrate:down
+drop table ticket_transfers;
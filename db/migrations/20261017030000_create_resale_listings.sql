@@ -0,0 +1,59 @@
+-- migrate:up
+-- Bounds on the price of a resale listing of the event's tickets, as rates of
+-- the ticket's face value in basis points. Events without a bound are
+-- unbounded.
+alter table events
+    add column resale_min_rate int check (resale_min_rate > 0),
+    add column resale_max_rate int check (resale_max_rate > 0),
+    add check (resale_min_rate <= resale_max_rate);
+
+-- Purchased tickets listed for resale by their owner, the seller. A listing is
+-- active until it's sold, or it's cancelled by the seller or as the ticket
+-- changes hands otherwise. Listings are kept once sold or cancelled, as the
+-- ticket's history.
+create table resale_listings (
+    id int generated always as identity,
+    ticket_id int not null,
+    seller_id int not null,
+    price bigint not null check (price > 0),
+    currency char(3) not null,
+    status varchar(20) not null default 'active' check (status in ('active', 'sold', 'cancelled')),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+
+    foreign key (ticket_id) references tickets (id),
+    foreign key (seller_id) references users (id),
+    primary key (id)
+);
+
+-- A ticket can only have one active listing at a time.
+create unique index on resale_listings (ticket_id) where status = 'active';
+create index on resale_listings (seller_id);
+
+-- The amount owed to the seller of a resold ticket, which is paid out of the
+-- buyer's payment.
+create table resale_payouts (
+    id int generated always as identity,
+    listing_id int not null,
+    seller_id int not null,
+    payment_id int not null,
+    amount bigint not null check (amount > 0),
+    currency char(3) not null,
+    created_at timestamptz not null default now(),
+
+    unique (listing_id),
+    foreign key (listing_id) references resale_listings (id),
+    foreign key (seller_id) references users (id),
+    foreign key (payment_id) references payments (id),
+    primary key (id)
+);
+
+create index on resale_payouts (seller_id);
+
+
+-- migrate:down
+drop table resale_payouts;
+drop table resale_listings;
+alter table events
+    drop column resale_max_rate,
+    drop column resale_min_rate;
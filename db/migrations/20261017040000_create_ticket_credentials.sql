@@ -0,0 +1,20 @@
+-- migrate:up
+-- The nonce that purchased tickets' scannable credentials are signed over,
+-- along with the owner the credential was issued to. Rotating the nonce
+-- invalidates credentials signed over the previous one, and a ticket's
+-- credential is removed once it changes hands, so that the previous owner's
+-- credential can't be used.
+create table ticket_credentials (
+    ticket_id int not null,
+    owner_id int not null,
+    nonce varchar(32) not null,
+    rotated_at timestamptz not null default now(),
+
+    foreign key (ticket_id) references tickets (id),
+    foreign key (owner_id) references users (id),
+    primary key (ticket_id)
+);
+
+
+-- migrate:down
+drop table ticket_credentials;
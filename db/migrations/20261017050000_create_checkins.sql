@@ -0,0 +1,24 @@
+-- migrate:up
+-- Admissions of purchased tickets to their event, as scanned at the event's
+-- gates. A ticket can only be checked in once.
+create table checkins (
+    id int generated always as identity,
+    ticket_id int not null,
+    event_id int not null,
+    owner_id int not null,
+    gate varchar(50) not null,
+    device varchar(100) not null,
+    scanned_at timestamptz not null default now(),
+
+    foreign key (ticket_id) references tickets (id),
+    foreign key (event_id) references events (id),
+    foreign key (owner_id) references users (id),
+    primary key (id)
+);
+
+create unique index on checkins (ticket_id);
+create index on checkins (event_id);
+
+
+-- migrate:down
+drop table checkins;
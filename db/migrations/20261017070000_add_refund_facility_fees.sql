@@ -0,0 +1,9 @@
+-- migrate:up
+-- The order's facility fee, and its tax, are refunded along with one of the
+-- order's tickets, and are included in the refund's amount.
+alter table refunds
+    add column facility_fee bigint not null default 0 check (facility_fee >= 0);
+
+
+-- migrate:down
+alter table refunds drop column facility_fee;
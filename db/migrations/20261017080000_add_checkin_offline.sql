@@ -0,0 +1,9 @@
+-- migrate:up
+-- Whether the check-in was scanned while offline, so that check-ins made
+-- online are never replaced by an earlier offline scan of the ticket. Existing
+-- check-ins are taken to have been made online.
+alter table checkins add column offline boolean not null default false;
+
+
+-- migrate:down
+alter table checkins drop column offline;
@@ -32,7 +32,22 @@ with delete_events as (
     -- Cascade delete to events.
     update events
     set deleted = true
-    where venue_id = @venue_id
+    where
+        venue_id = @venue_id
+        and deleted = false
+    returning id
+), cancel_events as (
+    insert into event_cancellations (event_id, status)
+    select id, 'pending'
+    from delete_events
+    returning id, event_id
+), queue_refunds as (
+    -- Queue a refund for every purchased ticket to the cancelled events.
+    insert into cancellation_refunds (cancellation_id, ticket_id, status)
+    select cancel_events.id, tickets.id, 'pending'
+    from cancel_events
+    inner join tickets on cancel_events.event_id = tickets.event_id
+    where tickets.purchaser_id is not null
 ), delete_venue as (
     update venues
     set deleted = true
@@ -43,6 +58,83 @@ with delete_events as (
 )
 select count(*) from delete_venue;
 
+-- name: GetVenueLayout :many
+select
+    venue_sections.id as section_id,
+    venue_sections.name as section_name,
+    venue_rows.id as row_id,
+    venue_rows.name as row_name,
+    sqlc.embed(venue_seats)
+from venue_sections
+inner join venue_rows on venue_sections.id = venue_rows.section_id
+inner join venue_seats on venue_rows.id = venue_seats.row_id
+where venue_sections.venue_id = @venue_id
+order by venue_sections.id, venue_rows.id, venue_seats.id;
+
+-- name: UpsertVenueSections :many
+-- Existing sections are updated in place, so that their ids are returned.
+insert into venue_sections (venue_id, name)
+select @venue_id::int, unnest(@names::text[])
+on conflict (venue_id, name) do update
+set name = excluded.name
+returning id, name;
+
+-- name: UpsertVenueRows :many
+-- Existing rows are updated in place, so that their ids are returned.
+insert into venue_rows (section_id, name)
+select unnest(@section_ids::int[]), unnest(@names::text[])
+on conflict (section_id, name) do update
+set name = excluded.name
+returning id, section_id, name;
+
+-- name: UpsertVenueSeats :many
+insert into venue_seats (row_id, number, x, y, accessible, obstructed)
+select
+    unnest(@row_ids::int[]),
+    unnest(@numbers::text[]),
+    unnest(@xs::float8[]),
+    unnest(@ys::float8[]),
+    unnest(@accessible::boolean[]),
+    unnest(@obstructed::boolean[])
+on conflict (row_id, number) do update
+set
+    x = excluded.x,
+    y = excluded.y,
+    accessible = excluded.accessible,
+    obstructed = excluded.obstructed
+returning id;
+
+-- name: TrimVenueSeats :exec
+-- Remove the seats of a venue's layout, other than those given.
+delete from venue_seats
+using venue_rows, venue_sections
+where
+    venue_seats.row_id = venue_rows.id
+    and venue_rows.section_id = venue_sections.id
+    and venue_sections.venue_id = @venue_id
+    and not venue_seats.id = any(@seat_ids::int[]);
+
+-- name: TrimVenueRows :exec
+-- Remove the rows of a venue's layout, other than those given.
+delete from venue_rows
+using venue_sections
+where
+    venue_rows.section_id = venue_sections.id
+    and venue_sections.venue_id = @venue_id
+    and not venue_rows.id = any(@row_ids::int[]);
+
+-- name: TrimVenueSections :exec
+-- Remove the sections of a venue's layout, other than those given.
+delete from venue_sections
+where
+    venue_id = @venue_id
+    and not id = any(@section_ids::int[]);
+
+-- name: DeleteVenueLayout :execrows
+-- Rows and seats are deleted along with their sections.
+delete from venue_sections
+where venue_id = @venue_id;
+
 -- name: WritePerformers :batchexec
 insert into performers (name) values (@name)
 on conflict (name) do nothing;
@@ -54,8 +146,26 @@ from performers
 where name = @name;
 
 -- name: CreateEvent :one
-insert into events (venue_id, name, starts_at, ends_at, description)
-values (@venue_id, @name, @starts_at, @ends_at, @description)
+insert into events (
+    venue_id,
+    name,
+    starts_at,
+    ends_at,
+    description,
+    max_tickets_per_user,
+    resale_min_rate,
+    resale_max_rate
+)
+values (
+    @venue_id,
+    @name,
+    @starts_at,
+    @ends_at,
+    @description,
+    @max_tickets_per_user,
+    @resale_min_rate,
+    @resale_max_rate
+)
 returning id;
 
 -- name: GetEvent :many
@@ -82,7 +192,10 @@ set
     name = @name,
     starts_at = @starts_at,
     ends_at = @ends_at,
-    description = @description
+    description = @description,
+    max_tickets_per_user = @max_tickets_per_user,
+    resale_min_rate = @resale_min_rate,
+    resale_max_rate = @resale_max_rate
 where
     id = @event_id
     and deleted = false
@@ -120,6 +233,18 @@ with delete_event as (
         id = @event_id
         and deleted = false
     returning id
+), cancel_event as (
+    insert into event_cancellations (event_id, status)
+    select id, 'pending'
+    from delete_event
+    returning id, event_id
+), queue_refunds as (
+    -- Queue a refund for every purchased ticket to the cancelled event.
+    insert into cancellation_refunds (cancellation_id, ticket_id, status)
+    select cancel_event.id, tickets.id, 'pending'
+    from cancel_event
+    inner join tickets on cancel_event.event_id = tickets.event_id
+    where tickets.purchaser_id is not null
 )
 select count(*) from delete_event;
 
@@ -129,33 +254,1452 @@ from tickets
 inner join events on tickets.event_id = events.id
 where 
     tickets.id = @ticket_id
+    and tickets.voided = false
     and events.deleted = false;
 
+-- name: EventExists :one
+select exists (
+    select 1
+    from events
+    where
+        id = @event_id
+        and deleted = false
+);
+
 -- name: GetAvailableTickets :many
+-- General admission tickets are only issued as they're purchased, so they're
+-- never available. Tickets of a type that isn't on sale aren't available, nor
+-- are tickets of a release that has gone off sale - tickets of a release that
+-- isn't on sale yet are, so that its schedule can be shown.
 select sqlc.embed(tickets)
 from tickets
 inner join events on tickets.event_id = events.id
+left join ticket_types on tickets.ticket_type_id = ticket_types.id
+left join ticket_releases on tickets.release_id = ticket_releases.id
 where 
     tickets.purchaser_id is null
+    and tickets.voided = false
+    and tickets.ga_tier_id is null
     and tickets.event_id = @event_id
-    and events.deleted = false;
+    and events.deleted = false
+    and (ticket_types.sale_starts_at is null or ticket_types.sale_starts_at <= now())
+    and (ticket_types.sale_ends_at is null or ticket_types.sale_ends_at > now())
+    and (ticket_releases.off_sale_at is null or ticket_releases.off_sale_at > now());
+
+-- name: GetAvailableSeatedTickets :many
+-- Available tickets for seats of the event's venue's layout, along with where
+-- each seat is. Seats are numbered by their position within their row, from
+-- left to right, so that adjacent seats have consecutive positions. Tickets of
+-- a type or release that isn't on sale aren't available, including those that
+-- are only on presale.
+with positioned_seats as (
+    select
+        venue_seats.id,
+        venue_seats.row_id,
+        venue_seats.number,
+        venue_seats.y,
+        row_number() over (
+            partition by venue_seats.row_id
+            order by venue_seats.x, venue_seats.id
+        )::int as position
+    from events
+    inner join venue_sections on events.venue_id = venue_sections.venue_id
+    inner join venue_rows on venue_sections.id = venue_rows.section_id
+    inner join venue_seats on venue_rows.id = venue_seats.row_id
+    where events.id = @event_id
+)
+select
+    sqlc.embed(tickets),
+    venue_sections.name as section_name,
+    venue_rows.id as row_id,
+    venue_rows.name as row_name,
+    positioned_seats.number as seat_number,
+    positioned_seats.position,
+    positioned_seats.y
+from tickets
+inner join events on tickets.event_id = events.id
+inner join positioned_seats on tickets.venue_seat_id = positioned_seats.id
+inner join venue_rows on positioned_seats.row_id = venue_rows.id
+inner join venue_sections on venue_rows.section_id = venue_sections.id
+left join ticket_types on tickets.ticket_type_id = ticket_types.id
+left join ticket_releases on tickets.release_id = ticket_releases.id
+where
+    tickets.purchaser_id is null
+    and tickets.voided = false
+    and tickets.event_id = @event_id
+    and events.deleted = false
+    and (ticket_types.sale_starts_at is null or ticket_types.sale_starts_at <= now())
+    and (ticket_types.sale_ends_at is null or ticket_types.sale_ends_at > now())
+    and (ticket_releases.on_sale_at is null or ticket_releases.on_sale_at <= now())
+    and (ticket_releases.off_sale_at is null or ticket_releases.off_sale_at > now())
+order by venue_sections.id, venue_rows.id, positioned_seats.position;
 
 -- name: WriteNewTickets :batchone
 -- The inserted record's id is returned so that the generated query will return
 -- an error (`sql.ErrNoRows`) if no record is inserted due to the where clause
--- not finding a matching event.
-insert into tickets (event_id, purchaser_id, price, seat)
-select events.id, null, @price, @seat
+-- not finding a matching event, the event's tickets being priced in another
+-- currency, the seat not being part of the event's venue's layout, or the
+-- ticket type or release not being one of the event's.
+insert into tickets (event_id, purchaser_id, price, currency, seat, venue_seat_id, ticket_type_id, release_id)
+select
+    events.id,
+    null,
+    @price,
+    @currency,
+    @seat,
+    sqlc.narg('venue_seat_id'),
+    sqlc.narg('ticket_type_id'),
+    sqlc.narg('release_id')
+from events
+where
+    events.id = @event_id
+    and events.deleted = false
+    and not exists (
+        select 1
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.currency <> @currency
+    )
+    and not exists (
+        select 1
+        from ga_tiers
+        where
+            ga_tiers.event_id = events.id
+            and ga_tiers.currency <> @currency
+    )
+    and not exists (
+        select 1
+        from ticket_types
+        where
+            ticket_types.event_id = events.id
+            and ticket_types.currency <> @currency
+    )
+    and (
+        sqlc.narg('venue_seat_id')::int is null
+        or exists (
+            select 1
+            from venue_seats
+            inner join venue_rows on venue_seats.row_id = venue_rows.id
+            inner join venue_sections on venue_rows.section_id = venue_sections.id
+            where
+                venue_seats.id = sqlc.narg('venue_seat_id')
+                and venue_sections.venue_id = events.venue_id
+        )
+    )
+    and (
+        sqlc.narg('ticket_type_id')::int is null
+        or exists (
+            select 1
+            from ticket_types
+            where
+                ticket_types.id = sqlc.narg('ticket_type_id')
+                and ticket_types.event_id = events.id
+        )
+    )
+    and (
+        sqlc.narg('release_id')::int is null
+        or exists (
+            select 1
+            from ticket_releases
+            where
+                ticket_releases.id = sqlc.narg('release_id')
+                and ticket_releases.event_id = events.id
+        )
+    )
+returning id;
+
+-- name: GetEventCurrencies :many
+select currency
+from tickets
+where event_id = @event_id
+union
+select currency
+from ga_tiers
+where event_id = @event_id
+union
+select currency
+from ticket_types
+where event_id = @event_id;
+
+-- name: CreateGaTier :one
+-- The inserted record's id is returned so that the generated query will return
+-- an error (`sql.ErrNoRows`) if the event doesn't exist, or if the event's
+-- tickets are priced in another currency.
+insert into ga_tiers (event_id, name, price, currency, capacity)
+select events.id, @name, @price, @currency, @capacity
+from events
+where
+    events.id = @event_id
+    and events.deleted = false
+    and not exists (
+        select 1
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.currency <> @currency
+    )
+    and not exists (
+        select 1
+        from ga_tiers
+        where
+            ga_tiers.event_id = events.id
+            and ga_tiers.currency <> @currency
+    )
+    and not exists (
+        select 1
+        from ticket_types
+        where
+            ticket_types.event_id = events.id
+            and ticket_types.currency <> @currency
+    )
+returning id;
+
+-- name: GetGaTier :one
+select ga_tiers.*
+from ga_tiers
+inner join events on ga_tiers.event_id = events.id
+where
+    ga_tiers.id = @ga_tier_id
+    and events.deleted = false;
+
+-- name: GetEventGaTiers :many
+select ga_tiers.*
+from ga_tiers
+inner join events on ga_tiers.event_id = events.id
+where
+    ga_tiers.event_id = @event_id
+    and events.deleted = false
+order by ga_tiers.id;
+
+-- name: CreateTicketType :one
+-- The inserted record's id is returned so that the generated query will return
+-- an error (`sql.ErrNoRows`) if the event doesn't exist, or if the event's
+-- tickets are priced in another currency.
+insert into ticket_types (
+    event_id,
+    name,
+    description,
+    price,
+    currency,
+    eligibility,
+    sale_starts_at,
+    sale_ends_at
+)
+select
+    events.id,
+    @name,
+    @description,
+    @price,
+    @currency,
+    @eligibility::text[],
+    sqlc.narg('sale_starts_at'),
+    sqlc.narg('sale_ends_at')
 from events
 where
     events.id = @event_id
     and events.deleted = false
+    and not exists (
+        select 1
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.currency <> @currency
+    )
+    and not exists (
+        select 1
+        from ga_tiers
+        where
+            ga_tiers.event_id = events.id
+            and ga_tiers.currency <> @currency
+    )
+    and not exists (
+        select 1
+        from ticket_types
+        where
+            ticket_types.event_id = events.id
+            and ticket_types.currency <> @currency
+    )
+returning id;
+
+-- name: GetEventTicketTypes :many
+select ticket_types.*
+from ticket_types
+inner join events on ticket_types.event_id = events.id
+where
+    ticket_types.event_id = @event_id
+    and events.deleted = false
+order by ticket_types.id;
+
+-- name: GetTicketTypes :many
+select *
+from ticket_types
+where id = any(@ticket_type_ids::int[]);
+
+-- name: CreateTicketRelease :one
+-- The inserted record's id is returned so that the generated query will return
+-- an error (`sql.ErrNoRows`) if the event doesn't exist.
+insert into ticket_releases (event_id, on_sale_at, off_sale_at)
+select events.id, sqlc.narg('on_sale_at'), sqlc.narg('off_sale_at')
+from events
+where
+    events.id = @event_id
+    and events.deleted = false
+returning id;
+
+-- name: CreatePresale :one
+insert into presales (release_id, name, starts_at, ends_at, access_code)
+values (@release_id, @name, @starts_at, @ends_at, sqlc.narg('access_code'))
+returning id;
+
+-- name: AddPresaleUsers :exec
+insert into presale_users (presale_id, user_id)
+select @presale_id, unnest(@user_ids::int[])
+on conflict do nothing;
+
+-- name: GetTicketReleases :many
+-- Releases along with their presales, if any, in the order that the presales
+-- start.
+select
+    ticket_releases.id,
+    ticket_releases.event_id,
+    ticket_releases.on_sale_at,
+    ticket_releases.off_sale_at,
+    presales.id as presale_id,
+    presales.name as presale_name,
+    presales.starts_at as presale_starts_at,
+    presales.ends_at as presale_ends_at,
+    presales.access_code as presale_access_code,
+    exists (
+        select 1
+        from presale_users
+        where presale_users.presale_id = presales.id
+    ) as presale_has_allowlist
+from ticket_releases
+left join presales on ticket_releases.id = presales.release_id
+where ticket_releases.id = any(@release_ids::int[])
+order by ticket_releases.id, presales.starts_at, presales.id;
+
+-- name: IsPresaleUser :one
+select exists (
+    select 1
+    from presale_users
+    where
+        presale_id = @presale_id
+        and user_id = @user_id
+);
+
+-- name: IssueGaTickets :many
+-- Issues tickets for the tier only if it has the capacity for all of them, so
+-- that a tier is never oversold.
+with tier as (
+    update ga_tiers
+    set sold = sold + @quantity::int
+    from events
+    where
+        ga_tiers.id = @ga_tier_id
+        and ga_tiers.event_id = events.id
+        and events.deleted = false
+        and ga_tiers.sold + @quantity::int <= ga_tiers.capacity
+    returning ga_tiers.id, ga_tiers.event_id, ga_tiers.name, ga_tiers.price, ga_tiers.currency
+)
+insert into tickets (event_id, purchaser_id, price, currency, seat, ga_tier_id)
+select tier.event_id, null, tier.price, tier.currency, tier.name, tier.id
+from tier, generate_series(1, @quantity::int)
 returning id;
 
+-- name: ReturnGaTickets :execrows
+-- Voids issued tickets that weren't purchased, returning them to their tier's
+-- capacity.
+with returned as (
+    update tickets
+    set voided = true
+    where
+        id = any(@ticket_ids::int[])
+        and purchaser_id is null
+        and voided = false
+        and ga_tier_id is not null
+    returning ga_tier_id
+)
+update ga_tiers
+set sold = ga_tiers.sold - counts.quantity
+from (
+    select ga_tier_id, count(*)::int as quantity
+    from returned
+    group by ga_tier_id
+) as counts
+where ga_tiers.id = counts.ga_tier_id;
+
+-- name: GetEventVenueSeats :many
+-- Of the given seats, those that are part of the event's venue's layout, and
+-- whether each already has a ticket for the event.
+select
+    venue_seats.id,
+    venue_sections.name as section_name,
+    exists (
+        select 1
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.venue_seat_id = venue_seats.id
+            and tickets.voided = false
+    ) as released
+from events
+inner join venue_sections on events.venue_id = venue_sections.venue_id
+inner join venue_rows on venue_sections.id = venue_rows.section_id
+inner join venue_seats on venue_rows.id = venue_seats.row_id
+where
+    events.id = @event_id
+    and venue_seats.id = any(@seat_ids::int[]);
+
 -- name: SetTicketPurchaser :one
 update tickets
 set purchaser_id = @purchaser_id
 where
     id = @ticket_id
     and purchaser_id is null
+    and voided = false
 returning id;
+
+-- name: GetTickets :many
+select sqlc.embed(tickets)
+from tickets
+inner join events on tickets.event_id = events.id
+where
+    tickets.id = any(@ticket_ids::int[])
+    and tickets.voided = false
+    and events.deleted = false;
+
+-- name: SetTicketsPurchaser :execrows
+-- Either all of the tickets are updated, or none are if any of them has already
+-- been purchased, so that a set of held tickets is purchased as a whole.
+with purchasable as (
+    select id
+    from tickets
+    where
+        id = any(@ticket_ids::int[])
+        and purchaser_id is null
+        and voided = false
+    for update
+)
+update tickets
+set purchaser_id = @purchaser_id
+where
+    id in (select id from purchasable)
+    and (select count(*) from purchasable) = cardinality(@ticket_ids::int[]);
+
+-- name: GetPurchaseLimits :many
+-- Gets the limit on tickets per user of each of the given events that has one,
+-- along with how many of the event's tickets the purchaser has purchased.
+select
+    events.id as event_id,
+    events.max_tickets_per_user::int as max_tickets_per_user,
+    count(tickets.id)::int as purchased
+from events
+left outer join tickets on
+    events.id = tickets.event_id
+    and tickets.purchaser_id = @purchaser_id
+where
+    events.id = any(@event_ids::int[])
+    and events.max_tickets_per_user is not null
+group by events.id;
+
+-- name: LockPurchaser :exec
+-- Serializes purchases by the same purchaser, so that concurrent purchases
+-- can't exceed an event's limit on tickets per user together.
+select id
+from users
+where id = @purchaser_id
+for no key update;
+
+-- name: GetExceededPurchaseLimits :many
+-- Finds the events, of the given tickets, of which the purchaser has purchased
+-- more tickets than the event's limit on tickets per user.
+select events.id
+from events
+inner join tickets on events.id = tickets.event_id
+where
+    events.id in (
+        select event_id
+        from tickets
+        where id = any(@ticket_ids::int[])
+    )
+    and tickets.purchaser_id = @purchaser_id
+group by events.id
+having count(*) > min(events.max_tickets_per_user);
+
+-- name: CreatePayment :one
+insert into payments (purchaser_id, amount, currency, status)
+values (@purchaser_id, @amount, @currency, @status)
+returning id;
+
+-- name: UpdatePaymentStatus :execrows
+-- Only updates the payment if it is in the expected status, so that concurrent
+-- transitions can't both succeed.
+update payments
+set
+    status = @status,
+    reference = coalesce(sqlc.narg('reference'), reference),
+    decline_reason = coalesce(sqlc.narg('decline_reason'), decline_reason),
+    updated_at = now()
+where id = @payment_id and status = @from_status;
+
+-- name: GetStaleAuthorizedPayments :many
+select *
+from payments
+where status = 'authorized' and updated_at < @updated_before
+order by updated_at
+limit sqlc.arg(max_payments);
+
+-- name: GetTicketPurchase :one
+-- Gets the most recent purchase of a ticket that is currently purchased.
+select
+    tickets.purchaser_id,
+    orders.purchaser_id as order_purchaser_id,
+    orders.id as order_id,
+    -- The price paid for the ticket, including its discount, fees and tax.
+    (order_items.price - order_items.discount + order_items.service_fee + order_items.tax)::bigint as price,
+    orders.currency,
+    payments.id as payment_id,
+    payments.reference as payment_reference
+from tickets
+inner join order_items on tickets.id = order_items.ticket_id
+inner join orders on order_items.order_id = orders.id
+inner join payments on orders.payment_id = payments.id
+where
+    tickets.id = @ticket_id
+    and tickets.purchaser_id is not null
+order by orders.created_at desc, orders.id desc
+limit 1;
+
+-- name: ClearTicketPurchaser :execrows
+-- Returns the ticket to inventory if it is re-released, otherwise voids it.
+-- General admission tickets are always voided, and are returned to their
+-- tier's capacity instead if re-released. The purchaser's resale listing of the
+-- ticket, if any, is cancelled, and their credential for it removed.
+with returned as (
+    update ga_tiers
+    set sold = sold - 1
+    where
+        @rerelease::boolean
+        and id = (
+            select ga_tier_id
+            from tickets
+            where
+                id = @ticket_id
+                and purchaser_id = @purchaser_id
+        )
+), cancel_listings as (
+    update resale_listings
+    set
+        status = 'cancelled',
+        updated_at = now()
+    where
+        ticket_id = @ticket_id
+        and seller_id = @purchaser_id
+        and status = 'active'
+), remove_credentials as (
+    delete from ticket_credentials
+    where
+        ticket_id = @ticket_id
+        and owner_id = @purchaser_id
+)
+update tickets
+set
+    purchaser_id = null,
+    voided = ga_tier_id is not null or not @rerelease::boolean
+where
+    id = @ticket_id
+    and purchaser_id = @purchaser_id;
+
+-- name: CreateRefund :one
+-- Refunds are created as pending, and completed once the payment processor has
+-- made them.
+insert into refunds (payment_id, ticket_id, amount, facility_fee, status)
+values (@payment_id, @ticket_id, @amount, @facility_fee, 'pending')
+returning id;
+
+-- name: GetUnrefundedFacilityFee :one
+-- Gets the facility fee of an order, along with its tax, unless it has already
+-- been refunded, and the number of the order's tickets that have yet to be
+-- refunded. A facility fee whose refund failed is refunded again with the next
+-- of the order's tickets to be refunded. The order is locked, so that its
+-- facility fee is only refunded once.
+select
+    (
+        case
+            when exists (
+                select 1
+                from refunds
+                where
+                    refunds.payment_id = orders.payment_id
+                    and refunds.facility_fee > 0
+                    and refunds.status <> 'failed'
+            ) then 0
+            -- The order's tax includes the tax on each of its items.
+            else orders.facility_fee + orders.tax - (
+                select coalesce(sum(order_items.tax), 0)
+                from order_items
+                where order_items.order_id = orders.id
+            )
+        end
+    )::bigint as facility_fee,
+    (
+        select count(*)
+        from order_items
+        where
+            order_items.order_id = orders.id
+            and not exists (
+                select 1
+                from refunds
+                where
+                    refunds.payment_id = orders.payment_id
+                    and refunds.ticket_id = order_items.ticket_id
+            )
+    ) as count_unrefunded
+from orders
+where orders.id = @order_id
+for update;
+
+-- name: CompleteRefund :execrows
+update refunds
+set
+    status = 'completed',
+    reference = @reference,
+    updated_at = now()
+where id = @refund_id and status = 'pending';
+
+-- name: FailRefund :execrows
+update refunds
+set
+    status = 'failed',
+    updated_at = now()
+where id = @refund_id and status = 'pending';
+
+-- name: GetStalePendingRefunds :many
+select
+    refunds.id,
+    refunds.payment_id,
+    refunds.ticket_id,
+    refunds.amount,
+    payments.currency,
+    payments.reference as payment_reference
+from refunds
+inner join payments on refunds.payment_id = payments.id
+where refunds.status = 'pending' and refunds.updated_at < @updated_before
+order by refunds.updated_at
+limit sqlc.arg(max_refunds);
+
+-- name: SetPaymentRefunded :execrows
+-- The payment is only marked as refunded once all of it has been refunded.
+update payments
+set
+    status = 'refunded',
+    updated_at = now()
+where
+    id = @payment_id
+    and status = 'captured'
+    and amount <= (
+        select coalesce(sum(refunds.amount), 0)
+        from refunds
+        where refunds.payment_id = @payment_id and refunds.status = 'completed'
+    );
+
+-- name: CreateOrder :one
+insert into orders (
+    purchaser_id,
+    payment_id,
+    status,
+    total,
+    currency,
+    facility_fee,
+    tax,
+    promo_code_id,
+    discount
+)
+values (
+    @purchaser_id,
+    @payment_id,
+    @status,
+    @total,
+    @currency,
+    @facility_fee,
+    @tax,
+    @promo_code_id,
+    @discount
+)
+returning id;
+
+-- name: WriteOrderItems :batchexec
+insert into order_items (order_id, ticket_id, price, service_fee, tax, discount)
+values (@order_id, @ticket_id, @price, @service_fee, @tax, @discount);
+
+-- name: GetOrder :many
+select
+    sqlc.embed(orders),
+    order_items.ticket_id as item_ticket_id,
+    order_items.price as item_price,
+    order_items.service_fee as item_service_fee,
+    order_items.tax as item_tax,
+    order_items.discount as item_discount
+from orders
+left outer join order_items on orders.id = order_items.order_id
+where orders.id = @order_id
+order by order_items.id;
+
+-- name: GetUserOrders :many
+select
+    sqlc.embed(orders),
+    order_items.ticket_id as item_ticket_id,
+    order_items.price as item_price,
+    order_items.service_fee as item_service_fee,
+    order_items.tax as item_tax,
+    order_items.discount as item_discount
+from orders
+left outer join order_items on orders.id = order_items.order_id
+where orders.purchaser_id = @purchaser_id
+order by orders.created_at desc, orders.id, order_items.id;
+
+-- name: GetPendingCancellationRefunds :many
+select *
+from cancellation_refunds
+where status = 'pending'
+order by id
+limit sqlc.arg(max_refunds);
+
+-- name: UpdateCancellationRefund :exec
+update cancellation_refunds
+set
+    status = @status,
+    attempts = @attempts,
+    last_error = @last_error,
+    refund_id = @refund_id,
+    updated_at = now()
+where id = @cancellation_refund_id;
+
+-- name: CompleteEventCancellations :execrows
+-- Completes cancellations that have no refunds left to process.
+update event_cancellations
+set
+    status = 'completed',
+    updated_at = now()
+where
+    status = 'pending'
+    and not exists (
+        select 1
+        from cancellation_refunds
+        where
+            cancellation_refunds.cancellation_id = event_cancellations.id
+            and cancellation_refunds.status = 'pending'
+    );
+
+-- name: GetEventCancellation :one
+select
+    sqlc.embed(event_cancellations),
+    count(cancellation_refunds.id) as total_count,
+    count(cancellation_refunds.id) filter (where cancellation_refunds.status = 'pending') as pending_count,
+    count(cancellation_refunds.id) filter (where cancellation_refunds.status = 'refunded') as refunded_count,
+    count(cancellation_refunds.id) filter (where cancellation_refunds.status = 'failed') as failed_count,
+    count(cancellation_refunds.id) filter (where cancellation_refunds.status = 'skipped') as skipped_count
+from event_cancellations
+left outer join cancellation_refunds on event_cancellations.id = cancellation_refunds.cancellation_id
+where event_cancellations.event_id = @event_id
+group by event_cancellations.id;
+
+-- name: ClaimIdempotencyKey :one
+-- Claims the key for a request, unless it has already been claimed by another
+-- request and hasn't expired. An expired key is claimed anew.
+insert into idempotency_keys (user_id, key, fingerprint, expires_at)
+values (@user_id, @key, @fingerprint, @expires_at)
+on conflict (user_id, key) do update
+set
+    fingerprint = excluded.fingerprint,
+    status_code = null,
+    content_type = null,
+    response_body = null,
+    created_at = now(),
+    expires_at = excluded.expires_at
+where idempotency_keys.expires_at <= now()
+returning id;
+
+-- name: GetIdempotencyKey :one
+select *
+from idempotency_keys
+where
+    user_id = @user_id
+    and key = @key
+    and expires_at > now();
+
+-- name: CompleteIdempotencyKey :exec
+update idempotency_keys
+set
+    status_code = @status_code,
+    content_type = @content_type,
+    response_body = @response_body
+where id = @idempotency_key_id;
+
+-- name: DeleteIdempotencyKey :exec
+delete from idempotency_keys
+where id = @idempotency_key_id;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+delete from idempotency_keys
+where expires_at <= now();
+
+-- name: GetApplicableFeeRule :one
+-- Gets the fee rule that applies to an event, preferring the event's own rule
+-- over its venue's.
+select fee_rules.*
+from fee_rules
+inner join events on
+    fee_rules.event_id = events.id
+    or fee_rules.venue_id = events.venue_id
+where
+    events.id = @event_id
+    and events.deleted = false
+order by fee_rules.event_id nulls last
+limit 1;
+
+-- name: GetVenueFeeRule :one
+select fee_rules.*
+from fee_rules
+inner join venues on fee_rules.venue_id = venues.id
+where
+    venues.id = @venue_id
+    and venues.deleted = false;
+
+-- name: GetEventFeeRule :one
+select fee_rules.*
+from fee_rules
+inner join events on fee_rules.event_id = events.id
+where
+    events.id = @event_id
+    and events.deleted = false;
+
+-- name: UpsertVenueFeeRule :one
+-- The inserted or updated record's id is returned so that the generated query
+-- will return an error (`sql.ErrNoRows`) if the venue doesn't exist.
+insert into fee_rules (venue_id, service_fee_rate, service_fee_flat, facility_fee)
+select venues.id, @service_fee_rate, @service_fee_flat, @facility_fee
+from venues
+where
+    venues.id = @venue_id
+    and venues.deleted = false
+on conflict (venue_id) do update
+set
+    service_fee_rate = excluded.service_fee_rate,
+    service_fee_flat = excluded.service_fee_flat,
+    facility_fee = excluded.facility_fee,
+    updated_at = now()
+returning id;
+
+-- name: UpsertEventFeeRule :one
+-- The inserted or updated record's id is returned so that the generated query
+-- will return an error (`sql.ErrNoRows`) if the event doesn't exist.
+insert into fee_rules (event_id, service_fee_rate, service_fee_flat, facility_fee)
+select events.id, @service_fee_rate, @service_fee_flat, @facility_fee
+from events
+where
+    events.id = @event_id
+    and events.deleted = false
+on conflict (event_id) do update
+set
+    service_fee_rate = excluded.service_fee_rate,
+    service_fee_flat = excluded.service_fee_flat,
+    facility_fee = excluded.facility_fee,
+    updated_at = now()
+returning id;
+
+-- name: GetEventTaxRate :one
+-- Gets the tax rate for the region of an event's venue, preferring the rate for
+-- the venue's subdivision over the rate for its country.
+select tax_rates.rate
+from events
+inner join venues on events.venue_id = venues.id
+inner join tax_rates on
+    tax_rates.country_code = venues.country_code
+    and tax_rates.subdivision in (venues.subdivision, '')
+where events.id = @event_id
+order by tax_rates.subdivision desc
+limit 1;
+
+-- name: UpsertTaxRate :exec
+insert into tax_rates (country_code, subdivision, rate)
+values (@country_code, @subdivision, @rate)
+on conflict (country_code, subdivision) do update
+set rate = excluded.rate;
+
+-- name: CreatePromoCode :one
+-- The inserted record's id is returned so that the generated query will return
+-- an error (`sql.ErrNoRows`) if the code is for an event that doesn't exist.
+insert into promo_codes (
+    code,
+    discount_type,
+    percent_off,
+    amount_off,
+    currency,
+    event_id,
+    starts_at,
+    ends_at,
+    max_redemptions,
+    max_redemptions_per_user
+)
+select
+    @code,
+    @discount_type,
+    @percent_off,
+    @amount_off,
+    sqlc.narg('currency'),
+    sqlc.narg('event_id')::int,
+    sqlc.narg('starts_at'),
+    sqlc.narg('ends_at'),
+    sqlc.narg('max_redemptions'),
+    sqlc.narg('max_redemptions_per_user')
+where
+    sqlc.narg('event_id')::int is null
+    or exists (
+        select 1
+        from events
+        where
+            events.id = sqlc.narg('event_id')::int
+            and events.deleted = false
+    )
+returning id;
+
+-- name: GetPromoCode :one
+select *
+from promo_codes
+where
+    id = @promo_code_id
+    and deleted = false;
+
+-- name: GetPromoCodeByCode :one
+select *
+from promo_codes
+where
+    code = @code
+    and deleted = false;
+
+-- name: UpdatePromoCode :one
+-- The updated record's id is returned so that the generated query will return
+-- an error (`sql.ErrNoRows`) if no record matches the where clause and no
+-- record is updated, including if the code is for an event that doesn't exist.
+update promo_codes
+set
+    code = @code,
+    discount_type = @discount_type,
+    percent_off = @percent_off,
+    amount_off = @amount_off,
+    currency = sqlc.narg('currency'),
+    event_id = sqlc.narg('event_id')::int,
+    starts_at = sqlc.narg('starts_at'),
+    ends_at = sqlc.narg('ends_at'),
+    max_redemptions = sqlc.narg('max_redemptions'),
+    max_redemptions_per_user = sqlc.narg('max_redemptions_per_user'),
+    updated_at = now()
+where
+    id = @promo_code_id
+    and deleted = false
+    and (
+        sqlc.narg('event_id')::int is null
+        or exists (
+            select 1
+            from events
+            where
+                events.id = sqlc.narg('event_id')::int
+                and events.deleted = false
+        )
+    )
+returning id;
+
+-- name: DeletePromoCode :execrows
+update promo_codes
+set
+    deleted = true,
+    updated_at = now()
+where
+    id = @promo_code_id
+    and deleted = false;
+
+-- name: GetUserPromoCodeRedemptions :one
+select redemptions
+from promo_code_redemptions
+where
+    promo_code_id = @promo_code_id
+    and purchaser_id = @purchaser_id;
+
+-- name: RedeemPromoCode :execrows
+-- Counts a redemption of the code, unless it can't be redeemed at this time or
+-- has no redemptions left. The row lock taken by the update serializes
+-- concurrent redemptions, so that the code can't be over-redeemed.
+update promo_codes
+set
+    redemptions = redemptions + 1,
+    updated_at = now()
+where
+    id = @promo_code_id
+    and deleted = false
+    and (starts_at is null or starts_at <= now())
+    and (ends_at is null or ends_at > now())
+    and (max_redemptions is null or redemptions < max_redemptions);
+
+-- name: RedeemPromoCodeForUser :execrows
+-- Counts a redemption of the code by a user, unless the user has no
+-- redemptions of the code left.
+insert into promo_code_redemptions (promo_code_id, purchaser_id, redemptions)
+values (@promo_code_id, @purchaser_id, 1)
+on conflict (promo_code_id, purchaser_id) do update
+set redemptions = promo_code_redemptions.redemptions + 1
+where not exists (
+    select 1
+    from promo_codes
+    where
+        promo_codes.id = excluded.promo_code_id
+        and promo_codes.max_redemptions_per_user <= promo_code_redemptions.redemptions
+);
+
+-- name: GetWaitingRoom :one
+select waiting_rooms.*
+from waiting_rooms
+inner join events on waiting_rooms.event_id = events.id
+where
+    events.id = @event_id
+    and events.deleted = false;
+
+-- name: GetWaitingRooms :many
+select waiting_rooms.*
+from waiting_rooms
+inner join events on waiting_rooms.event_id = events.id
+where events.deleted = false
+order by waiting_rooms.event_id;
+
+-- name: UpsertWaitingRoom :one
+-- The inserted or updated record's event id is returned so that the generated
+-- query will return an error (`sql.ErrNoRows`) if the event doesn't exist.
+insert into waiting_rooms (event_id, batch_size)
+select events.id, @batch_size
+from events
+where
+    events.id = @event_id
+    and events.deleted = false
+on conflict (event_id) do update
+set
+    batch_size = excluded.batch_size,
+    updated_at = now()
+returning event_id;
+
+-- name: DeleteWaitingRoom :execrows
+delete from waiting_rooms
+where event_id = @event_id;
+
+-- name: UpsertWaitlistEntry :one
+-- Joining a waitlist that the user is already waiting on only changes the
+-- quantity, keeping the user's place, whereas joining again once offered
+-- tickets puts the user at the back. The inserted or updated record's event id
+-- is returned so that the generated query will return an error
+-- (`sql.ErrNoRows`) if the event doesn't exist.
+insert into waitlist_entries (event_id, user_id, quantity)
+select events.id, @user_id, @quantity
+from events
+where
+    events.id = @event_id
+    and events.deleted = false
+on conflict (event_id, user_id) do update
+set
+    quantity = excluded.quantity,
+    joined_at = case
+        when waitlist_entries.offer_token is null then waitlist_entries.joined_at
+        else now()
+    end,
+    offer_token = null,
+    offer_expires_at = null
+returning event_id;
+
+-- name: GetWaitlistEntry :one
+-- Gets the user's entry on the event's waitlist, along with the number of
+-- users ahead of them that are still waiting.
+select
+    sqlc.embed(waitlist_entries),
+    (
+        select count(*)
+        from waitlist_entries as ahead
+        where
+            ahead.event_id = waitlist_entries.event_id
+            and ahead.offer_token is null
+            and (ahead.joined_at, ahead.user_id) < (waitlist_entries.joined_at, waitlist_entries.user_id)
+    )::int as ahead
+from waitlist_entries
+inner join events on waitlist_entries.event_id = events.id
+where
+    waitlist_entries.event_id = @event_id
+    and waitlist_entries.user_id = @user_id
+    and events.deleted = false;
+
+-- name: DeleteWaitlistEntry :execrows
+delete from waitlist_entries
+where
+    event_id = @event_id
+    and user_id = @user_id;
+
+-- name: GetWaitlistedEvents :many
+-- Gets the events with users waiting on their waitlist.
+select distinct waitlist_entries.event_id
+from waitlist_entries
+inner join events on waitlist_entries.event_id = events.id
+where
+    waitlist_entries.offer_token is null
+    and events.deleted = false
+order by waitlist_entries.event_id;
+
+-- name: GetWaitingEntries :many
+-- Gets the entries of the users waiting on the event's waitlist, in the order
+-- they joined.
+select *
+from waitlist_entries
+where
+    event_id = @event_id
+    and offer_token is null
+order by joined_at, user_id
+limit sqlc.arg(max_entries);
+
+-- name: SetWaitlistOffer :execrows
+update waitlist_entries
+set
+    offer_token = @offer_token,
+    offer_expires_at = @offer_expires_at
+where
+    event_id = @event_id
+    and user_id = @user_id
+    and offer_token is null;
+
+-- name: GetTicketOwnership :one
+-- Gets the ticket's purchaser, if it has been purchased, along with when its
+-- event starts.
+select
+    tickets.id as ticket_id,
+    tickets.purchaser_id,
+    events.starts_at
+from tickets
+inner join events on tickets.event_id = events.id
+where
+    tickets.id = @ticket_id
+    and tickets.voided = false
+    and events.deleted = false;
+
+-- name: CreateTicketTransfer :one
+insert into ticket_transfers (ticket_id, sender_id, recipient_id)
+values (@ticket_id, @sender_id, @recipient_id)
+returning *;
+
+-- name: GetTicketTransfer :one
+select *
+from ticket_transfers
+where id = @transfer_id;
+
+-- name: GetPendingTicketTransfer :one
+select *
+from ticket_transfers
+where
+    ticket_id = @ticket_id
+    and status = 'pending';
+
+-- name: GetTicketTransfers :many
+-- Gets the ticket's transfers, most recent first.
+select *
+from ticket_transfers
+where ticket_id = @ticket_id
+order by created_at desc, id desc;
+
+-- name: GetUserTicketTransfers :many
+-- Gets the transfers sent or received by the user, most recent first.
+select *
+from ticket_transfers
+where
+    sender_id = @user_id
+    or recipient_id = @user_id
+order by created_at desc, id desc;
+
+-- name: UpdateTicketTransferStatus :one
+-- Only updates the transfer if it is pending, so that a transfer can't be both
+-- accepted and cancelled.
+update ticket_transfers
+set
+    status = @status,
+    updated_at = now()
+where
+    id = @transfer_id
+    and status = 'pending'
+returning *;
+
+-- name: TransferTicket :execrows
+-- Moves the ticket to the recipient, as long as it's still owned by the sender
+-- and its event hasn't started. The sender's resale listing of the ticket, if
+-- any, is cancelled, and their credential for it removed.
+with cancel_listings as (
+    update resale_listings
+    set
+        status = 'cancelled',
+        updated_at = now()
+    where
+        ticket_id = @ticket_id
+        and seller_id = @sender_id
+        and status = 'active'
+), remove_credentials as (
+    delete from ticket_credentials
+    where
+        ticket_id = @ticket_id
+        and owner_id = @sender_id
+)
+update tickets
+set purchaser_id = @recipient_id
+where
+    id = @ticket_id
+    and purchaser_id = @sender_id
+    and voided = false
+    and event_id in (
+        select id
+        from events
+        where
+            starts_at > now()
+            and deleted = false
+    );
+
+-- name: GetTicketResaleTerms :one
+-- Gets the ticket, along with when its event starts and the event's bounds on
+-- the price of a resale listing.
+select
+    sqlc.embed(tickets),
+    events.starts_at,
+    events.resale_min_rate,
+    events.resale_max_rate
+from tickets
+inner join events on tickets.event_id = events.id
+where
+    tickets.id = @ticket_id
+    and tickets.voided = false
+    and events.deleted = false;
+
+-- name: CreateResaleListing :one
+insert into resale_listings (ticket_id, seller_id, price, currency)
+values (@ticket_id, @seller_id, @price, @currency)
+returning *;
+
+-- name: GetResaleListing :one
+select *
+from resale_listings
+where id = @listing_id;
+
+-- name: GetActiveResaleListing :one
+select *
+from resale_listings
+where
+    ticket_id = @ticket_id
+    and status = 'active';
+
+-- name: GetEventResaleListings :many
+-- Gets the active listings of the event's tickets, along with each ticket's
+-- seat and face value. Listings of tickets that are no longer owned by their
+-- seller, or whose event has started, can't be purchased, so aren't included.
+select
+    sqlc.embed(resale_listings),
+    tickets.seat,
+    tickets.price as face_value
+from resale_listings
+inner join tickets on resale_listings.ticket_id = tickets.id
+inner join events on tickets.event_id = events.id
+where
+    tickets.event_id = @event_id
+    and resale_listings.status = 'active'
+    and tickets.purchaser_id = resale_listings.seller_id
+    and tickets.voided = false
+    and events.deleted = false
+    and events.starts_at > now()
+order by resale_listings.price, resale_listings.id;
+
+-- name: GetSellerResaleListings :many
+-- Gets the seller's listings, most recent first.
+select *
+from resale_listings
+where seller_id = @seller_id
+order by created_at desc, id desc;
+
+-- name: UpdateResaleListingStatus :one
+-- Only updates the listing if it is active, so that a listing can't be both
+-- sold and cancelled.
+update resale_listings
+set
+    status = @status,
+    updated_at = now()
+where
+    id = @listing_id
+    and status = 'active'
+returning *;
+
+-- name: CreateResalePayout :one
+insert into resale_payouts (listing_id, seller_id, payment_id, amount, currency)
+values (@listing_id, @seller_id, @payment_id, @amount, @currency)
+returning id;
+
+-- name: GetTicketCredential :one
+-- Gets the ticket, along with the nonce of its owner's credential and when it
+-- was last rotated, if its owner has a credential.
+select
+    sqlc.embed(tickets),
+    ticket_credentials.nonce,
+    ticket_credentials.rotated_at
+from tickets
+inner join events on tickets.event_id = events.id
+left join ticket_credentials
+    on tickets.id = ticket_credentials.ticket_id
+    and tickets.purchaser_id = ticket_credentials.owner_id
+where
+    tickets.id = @ticket_id
+    and tickets.voided = false
+    and events.deleted = false;
+
+-- name: CreateTicketCredential :exec
+-- Creates the owner's credential for the ticket, replacing a credential issued
+-- to a previous owner. An existing credential of the owner is kept, so that
+-- concurrent requests agree on the nonce.
+insert into ticket_credentials (ticket_id, owner_id, nonce)
+select @ticket_id, @owner_id, @nonce
+where exists (
+    select 1
+    from tickets
+    where
+        id = @ticket_id
+        and purchaser_id = @owner_id
+        and voided = false
+)
+on conflict (ticket_id) do update
+set
+    owner_id = excluded.owner_id,
+    nonce = excluded.nonce,
+    rotated_at = now()
+where ticket_credentials.owner_id <> excluded.owner_id;
+
+-- name: RotateTicketCredential :one
+-- Sets the nonce of the owner's credential for the ticket, as long as they
+-- still own it.
+insert into ticket_credentials (ticket_id, owner_id, nonce)
+select @ticket_id, @owner_id, @nonce
+where exists (
+    select 1
+    from tickets
+    where
+        id = @ticket_id
+        and purchaser_id = @owner_id
+        and voided = false
+)
+on conflict (ticket_id) do update
+set
+    owner_id = excluded.owner_id,
+    nonce = excluded.nonce,
+    rotated_at = now()
+returning *;
+
+-- name: GetTicketAdmission :one
+-- Gets the ticket's event and owner, along with the nonce of its owner's
+-- credential, if they have one, and when the ticket was checked in, if it has
+-- been.
+select
+    tickets.id as ticket_id,
+    tickets.event_id,
+    tickets.purchaser_id,
+    ticket_credentials.nonce,
+    checkins.scanned_at
+from tickets
+inner join events on tickets.event_id = events.id
+left join ticket_credentials
+    on tickets.id = ticket_credentials.ticket_id
+    and tickets.purchaser_id = ticket_credentials.owner_id
+left join checkins on tickets.id = checkins.ticket_id
+where
+    tickets.id = @ticket_id
+    and tickets.voided = false
+    and events.deleted = false;
+
+-- name: CreateCheckin :one
+-- Records the ticket's admission to its event. Nothing is returned if the
+-- ticket has already been checked in, so that the generated query will return
+-- an error (`sql.ErrNoRows`).
+insert into checkins (ticket_id, event_id, owner_id, gate, device)
+values (@ticket_id, @event_id, @owner_id, @gate, @device)
+on conflict (ticket_id) do nothing
+returning *;
+
+-- name: GetEventAttendance :one
+-- Counts the event's checked in tickets, along with its purchased tickets.
+select
+    events.id as event_id,
+    (
+        select count(*)
+        from checkins
+        where checkins.event_id = events.id
+    ) as checked_in,
+    (
+        select count(*)
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.purchaser_id is not null
+            and tickets.voided = false
+    ) as purchased
+from events
+where
+    events.id = @event_id
+    and events.deleted = false;
+
+-- name: GetScannerTickets :many
+-- Gets the event's purchased tickets, along with their owner's credential's
+-- nonce, if they have one, and when they were checked in, if they have been.
+select
+    tickets.id as ticket_id,
+    tickets.purchaser_id,
+    ticket_credentials.nonce,
+    checkins.scanned_at
+from tickets
+inner join events on tickets.event_id = events.id
+left join ticket_credentials
+    on tickets.id = ticket_credentials.ticket_id
+    and tickets.purchaser_id = ticket_credentials.owner_id
+left join checkins on tickets.id = checkins.ticket_id
+where
+    tickets.event_id = @event_id
+    and tickets.purchaser_id is not null
+    and tickets.voided = false
+    and events.deleted = false
+order by tickets.id;
+
+-- name: GetRevokedTickets :many
+-- Gets the ids of the event's tickets that have been refunded, and haven't
+-- been purchased again since.
+select tickets.id
+from tickets
+where
+    tickets.event_id = @event_id
+    and (tickets.purchaser_id is null or tickets.voided = true)
+    and exists (
+        select 1
+        from refunds
+        where refunds.ticket_id = tickets.id
+    )
+order by tickets.id;
+
+-- name: CreateOfflineCheckin :one
+-- Records the ticket's admission to its event, as scanned while offline. Of
+-- the ticket's offline admissions, the earliest is kept, so that admissions
+-- recorded by devices that were offline are reconciled by when they were
+-- scanned. An admission made online is never replaced. Nothing is returned if
+-- the ticket was already admitted online, or earlier, so that the generated
+-- query will return an error (`sql.ErrNoRows`).
+insert into checkins (ticket_id, event_id, owner_id, gate, device, scanned_at, offline)
+values (@ticket_id, @event_id, @owner_id, @gate, @device, @scanned_at, true)
+on conflict (ticket_id) do update
+set
+    owner_id = excluded.owner_id,
+    gate = excluded.gate,
+    device = excluded.device,
+    scanned_at = excluded.scanned_at
+where checkins.offline and checkins.scanned_at > excluded.scanned_at
+returning *;
@@ -9,6 +9,7 @@ import (
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/money"
 	"github.com/dslaw/book-tickets/pkg/payment"
 	"github.com/dslaw/book-tickets/pkg/repos"
 	"github.com/dslaw/book-tickets/pkg/services"
@@ -90,6 +91,82 @@ func RegisterVenuesHandlers(api huma.API, service *services.VenuesService) {
 	})
 }
 
+func RegisterVenueLayoutsHandlers(api huma.API, service *services.VenueLayoutsService) {
+	// Read a venue's seating layout.
+	huma.Get(api, "/venues/{id}/layout", func(ctx context.Context, input *struct {
+		VenueID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		layout, err := service.GetVenueLayout(ctx, input.VenueID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching venue layout", "venue_id", input.VenueID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToVenueLayoutResponse(layout)}
+		return response, nil
+	})
+
+	// Create or replace a venue's seating layout.
+	huma.Put(api, "/venues/{id}/layout", func(ctx context.Context, input *struct {
+		VenueID int32 `path:"id"`
+		Body    WriteVenueLayoutRequest
+	}) (*struct{}, error) {
+		layout := MapToVenueLayout(input.Body, input.VenueID)
+		if !layout.IsValid() {
+			return nil, huma.Error422UnprocessableEntity("")
+		}
+
+		err := service.SetVenueLayout(ctx, layout)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, repos.ErrEntityInUse) {
+				slog.Error(
+					"Attempt to remove seats with released tickets from a venue layout",
+					"venue_id", input.VenueID,
+					"error", err,
+				)
+				return nil, huma.Error409Conflict("")
+			}
+
+			slog.Error(
+				"Issue setting venue layout",
+				"venue_id", input.VenueID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+
+	// Remove a venue's seating layout.
+	huma.Delete(api, "/venues/{id}/layout", func(ctx context.Context, input *struct {
+		VenueID int32 `path:"id"`
+	}) (*struct{}, error) {
+		err := service.DeleteVenueLayout(ctx, input.VenueID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, repos.ErrEntityInUse) {
+				return nil, huma.Error409Conflict("")
+			}
+
+			slog.Error("Issue deleting venue layout", "venue_id", input.VenueID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+}
+
 func RegisterEventsHandlers(api huma.API, service *services.EventsService) {
 	// Create a new event.
 	huma.Post(api, "/events", func(ctx context.Context, input *struct {
@@ -180,12 +257,35 @@ func RegisterTicketsHandlers(api huma.API, service *services.TicketsService) {
 		EventID int32 `path:"id"`
 		Body    WriteTicketReleaseRequest
 	}) (*struct{}, error) {
+		release := MapToTicketRelease(input.Body, input.EventID)
+		if !release.IsValid() {
+			return nil, huma.Error422UnprocessableEntity("Invalid sale window or presale")
+		}
+
 		tickets := MapToTickets(input.Body, input.EventID)
-		err := service.AddTickets(ctx, input.EventID, tickets)
+		err := service.ReleaseTickets(ctx, release, tickets)
 		if err != nil {
 			if errors.Is(err, repos.ErrNoSuchEntity) {
 				return nil, huma.Error404NotFound("")
 			}
+			if errors.Is(err, repos.ErrNoSuchUser) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+			if errors.Is(err, money.ErrUnsupportedCurrency) || errors.Is(err, money.ErrCurrencyMismatch) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrMissingSeat) || errors.Is(err, services.ErrNoSuchSeat) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if isTicketTypeError(err) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrSeatReleased) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
 
 			slog.Error(
 				"Issue releasing tickets",
@@ -202,7 +302,7 @@ func RegisterTicketsHandlers(api huma.API, service *services.TicketsService) {
 	huma.Get(api, "/events/{id}/tickets", func(ctx context.Context, input *struct {
 		EventID int32 `path:"id"`
 	}) (*ResponseEnvelope, error) {
-		ticketAggregates, err := service.GetAvailableTickets(ctx, input.EventID)
+		inventory, err := service.GetAvailableInventory(ctx, input.EventID)
 		if err != nil {
 			if errors.Is(err, repos.ErrNoSuchEntity) {
 				return nil, huma.Error404NotFound("")
@@ -217,18 +317,151 @@ func RegisterTicketsHandlers(api huma.API, service *services.TicketsService) {
 		}
 
 		response := &ResponseEnvelope{}
-		response.Body = MapToAvailableTicketsAggregateResponse(ticketAggregates)
+		response.Body = MapToAvailableInventoryResponse(inventory)
+		return response, nil
+	})
+
+	// Create a ticket type for an event.
+	huma.Post(api, "/events/{id}/ticket-types", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+		Body    WriteTicketTypeRequest
+	}) (*ResponseEnvelope, error) {
+		ticketType := MapToTicketType(input.Body, input.EventID)
+		if !ticketType.IsValid() {
+			return nil, huma.Error422UnprocessableEntity("")
+		}
+
+		id, err := service.CreateTicketType(ctx, ticketType)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, money.ErrUnsupportedCurrency) || errors.Is(err, money.ErrCurrencyMismatch) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrTicketTypeExists) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			slog.Error(
+				"Issue creating ticket type",
+				"event_id", input.EventID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: CreateTicketTypeResponse{ID: id}}, nil
+	})
+
+	// Read the ticket types of an event.
+	huma.Get(api, "/events/{id}/ticket-types", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		ticketTypes, err := service.GetTicketTypes(ctx, input.EventID)
+		if err != nil {
+			slog.Error(
+				"Issue fetching ticket types",
+				"event_id", input.EventID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToTicketTypesResponse(ticketTypes)}
 		return response, nil
 	})
 
+	// Create a general admission tier for an event.
+	huma.Post(api, "/events/{id}/ga-tiers", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+		Body    WriteGATierRequest
+	}) (*ResponseEnvelope, error) {
+		tier := MapToGATier(input.Body, input.EventID)
+		id, err := service.CreateGATier(ctx, tier)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, money.ErrUnsupportedCurrency) || errors.Is(err, money.ErrCurrencyMismatch) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrGATierExists) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			slog.Error(
+				"Issue creating general admission tier",
+				"event_id", input.EventID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: CreateGATierResponse{ID: id}}, nil
+	})
+
+	// Set a purchase hold on a quantity of a general admission tier.
+	huma.Post(api, "/ga-tiers/{id}/hold", func(ctx context.Context, input *struct {
+		ID             int32  `path:"id"`
+		UserID         string `header:"x-user-id"`
+		AdmissionToken string `header:"x-admission-token"`
+		Body           WriteGATierHoldRequest
+	}) (*ResponseEnvelope, error) {
+		holdID := input.UserID
+		access := MapToSaleAccess(input.UserID, "", input.AdmissionToken)
+		hold, err := service.HoldGATier(ctx, input.ID, input.Body.Quantity, holdID, access)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldID) || errors.Is(err, services.ErrEmptyHold) {
+				slog.Error("Invalid hold", "ga_tier_id", input.ID, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrGATierSoldOut) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPurchaseLimitExceeded) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if isAdmissionError(err) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			slog.Error(
+				"Issue setting a general admission tier hold",
+				"ga_tier_id", input.ID,
+				"hold_id", holdID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToGATierHoldResponse(hold)}, nil
+	})
+
 	// Set a purchase hold on a ticket.
 	huma.Post(api, "/tickets/{id}/hold", func(ctx context.Context, input *struct {
-		ID     int32  `path:"id"`
-		UserID string `header:"x-user-id"`
+		ID             int32  `path:"id"`
+		UserID         string `header:"x-user-id"`
+		AdmissionToken string `header:"x-admission-token"`
+		AccessCode     string `query:"access_code"`
 	}) (*struct{}, error) {
 		ticketID := input.ID
 		holdID := input.UserID
-		err := service.SetTicketHold(ctx, ticketID, holdID)
+		access := MapToSaleAccess(input.UserID, input.AccessCode, input.AdmissionToken)
+		err := service.SetTicketHold(ctx, ticketID, holdID, access)
 		if err != nil {
 			if errors.Is(err, services.ErrInvalidHoldID) {
 				slog.Error("Invalid hold id", "ticket_id", ticketID, "hold_id", holdID)
@@ -248,6 +481,18 @@ func RegisterTicketsHandlers(api huma.API, service *services.TicketsService) {
 				return nil, huma.Error422UnprocessableEntity("")
 			}
 
+			if isNotOnSaleError(err) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPurchaseLimitExceeded) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPresaleAccessDenied) || isAdmissionError(err) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
 			slog.Error(
 				"Issue setting a ticket hold",
 				"ticket_id", ticketID,
@@ -259,13 +504,110 @@ func RegisterTicketsHandlers(api huma.API, service *services.TicketsService) {
 		return nil, nil
 	})
 
+	// Release a purchase hold on a ticket.
+	huma.Delete(api, "/tickets/{id}/hold", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+	}) (*struct{}, error) {
+		ticketID := input.ID
+		holdID := input.UserID
+		err := service.ReleaseTicketHold(ctx, ticketID, holdID)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldID) {
+				slog.Error("Invalid hold id", "ticket_id", ticketID, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, cache.ErrNotFound) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrTicketInTicketsHold) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrHoldIDMismatch) {
+				slog.Error(
+					"Attempt to release a ticket hold with incorrect hold id",
+					"ticket_id", ticketID,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			slog.Error(
+				"Issue releasing a ticket hold",
+				"ticket_id", ticketID,
+				"hold_id", holdID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+
+	// Extend a purchase hold on a ticket.
+	huma.Post(api, "/tickets/{id}/hold/extend", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		ticketID := input.ID
+		holdID := input.UserID
+		expiresAt, err := service.ExtendTicketHold(ctx, ticketID, holdID)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldID) {
+				slog.Error("Invalid hold id", "ticket_id", ticketID, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, cache.ErrNotFound) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrTicketInTicketsHold) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrHoldIDMismatch) {
+				slog.Error(
+					"Attempt to extend a ticket hold with incorrect hold id",
+					"ticket_id", ticketID,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, cache.ErrMaxExtensions) {
+				slog.Error(
+					"Attempt to extend a ticket hold past the maximum number of extensions",
+					"ticket_id", ticketID,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			slog.Error(
+				"Issue extending a ticket hold",
+				"ticket_id", ticketID,
+				"hold_id", holdID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: ExtendTicketHoldResponse{ExpiresAt: expiresAt}}
+		return response, nil
+	})
+
 	// Purchase a ticket.
 	huma.Post(api, "/tickets/{id}/purchase", func(ctx context.Context, input *struct {
 		ID int32 `path:"id"`
 		// TODO: Should probably model user id as an int and convert to a string
 		// for hold id.
-		UserID string `header:"x-user-id"`
-		Card   Card
+		UserID     string `header:"x-user-id"`
+		PromoCode  string `query:"promo_code"`
+		AccessCode string `query:"access_code"`
+		Body       PurchaseTicketRequest
 	}) (*ResponseEnvelope, error) {
 		holdID := input.UserID
 		userID, err := strconv.Atoi(input.UserID)
@@ -274,16 +616,17 @@ func RegisterTicketsHandlers(api huma.API, service *services.TicketsService) {
 			return nil, huma.Error500InternalServerError("")
 		}
 
-		card := payment.Card{
-			Name:            input.Card.Name,
-			Address:         input.Card.Address,
-			Number:          input.Card.Number,
-			ExpirationMonth: input.Card.ExpirationMonth,
-			ExpirationYear:  input.Card.ExpirationYear,
-			CVC:             input.Card.CVC,
-		}
+		card := MapToCard(input.Body.Card)
 
-		ticket, err := service.GetHeldTicket(ctx, input.ID, holdID)
+		purchase, err := service.PurchaseTicket(
+			ctx,
+			input.ID,
+			holdID,
+			int32(userID),
+			card,
+			input.PromoCode,
+			input.AccessCode,
+		)
 		if err != nil {
 			if errors.Is(err, services.ErrInvalidHoldID) {
 				slog.Error("Invalid hold id", "ticket_id", input.ID, "hold_id", holdID)
@@ -310,50 +653,1475 @@ func RegisterTicketsHandlers(api huma.API, service *services.TicketsService) {
 				return nil, huma.Error422UnprocessableEntity("")
 			}
 
+			if errors.Is(err, services.ErrTicketPurchased) {
+				slog.Error(
+					"Attempt to purchase an already purchased ticket",
+					"ticket_id", input.ID,
+					"hold_id", holdID,
+					"error", err,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if isPromoCodeError(err) || errors.Is(err, services.ErrPurchaseLimitExceeded) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPurchaseInProgress) {
+				return nil, huma.Error409Conflict("")
+			}
+
+			if isNotOnSaleError(err) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPresaleAccessDenied) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, payment.ErrGatewayUnavailable) {
+				slog.Error(
+					"Payment gateway unavailable while purchasing a ticket",
+					"ticket_id", input.ID,
+					"error", err,
+				)
+				return nil, huma.Error503ServiceUnavailable("")
+			}
+
 			slog.Error(
 				"Issue purchasing a ticket",
 				"ticket_id", input.ID,
 				"hold_id", holdID,
+				"purchaser_id", userID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToPaymentResponse(purchase)}, nil
+	})
+
+	// Set a purchase hold on several tickets at once.
+	huma.Post(api, "/tickets/hold", func(ctx context.Context, input *struct {
+		UserID         string `header:"x-user-id"`
+		AdmissionToken string `header:"x-admission-token"`
+		Body           WriteTicketsHoldRequest
+	}) (*ResponseEnvelope, error) {
+		holdID := input.UserID
+		access := MapToSaleAccess(input.UserID, input.Body.AccessCode, input.AdmissionToken)
+		hold, err := service.SetTicketsHold(ctx, input.Body.TicketIDs, holdID, access)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldID) || errors.Is(err, services.ErrEmptyHold) {
+				slog.Error("Invalid hold", "ticket_ids", input.Body.TicketIDs, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrTicketPurchased) {
+				slog.Error(
+					"Attempt to place a hold on a purchased ticket",
+					"ticket_ids", input.Body.TicketIDs,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, cache.ErrAlreadyHasHold) {
+				slog.Error(
+					"Attempt to place a hold on an already held ticket",
+					"ticket_ids", input.Body.TicketIDs,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if isNotOnSaleError(err) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPurchaseLimitExceeded) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPresaleAccessDenied) || isAdmissionError(err) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			slog.Error(
+				"Issue setting a tickets hold",
+				"ticket_ids", input.Body.TicketIDs,
+				"hold_id", holdID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToTicketsHoldResponse(hold)}, nil
+	})
+
+	// Release a purchase hold, on all of its tickets.
+	huma.Delete(api, "/holds/{token}", func(ctx context.Context, input *struct {
+		Token  string `path:"token"`
+		UserID string `header:"x-user-id"`
+	}) (*struct{}, error) {
+		holdToken := input.Token
+		holdID := input.UserID
+		err := service.ReleaseTicketsHold(ctx, holdToken, holdID)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldToken) || errors.Is(err, services.ErrInvalidHoldID) {
+				slog.Error("Invalid hold", "hold_token", holdToken, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, cache.ErrNotFound) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrHoldIDMismatch) {
+				slog.Error(
+					"Attempt to release a hold with incorrect hold id",
+					"hold_token", holdToken,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			slog.Error(
+				"Issue releasing a hold",
+				"hold_token", holdToken,
+				"hold_id", holdID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+
+	// Extend a purchase hold, on all of its tickets.
+	huma.Post(api, "/holds/{token}/extend", func(ctx context.Context, input *struct {
+		Token  string `path:"token"`
+		UserID string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		holdToken := input.Token
+		holdID := input.UserID
+		expiresAt, err := service.ExtendTicketsHold(ctx, holdToken, holdID)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldToken) || errors.Is(err, services.ErrInvalidHoldID) {
+				slog.Error("Invalid hold", "hold_token", holdToken, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, cache.ErrNotFound) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrHoldIDMismatch) {
+				slog.Error(
+					"Attempt to extend a hold with incorrect hold id",
+					"hold_token", holdToken,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, cache.ErrMaxExtensions) {
+				slog.Error(
+					"Attempt to extend a hold past the maximum number of extensions",
+					"hold_token", holdToken,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			slog.Error(
+				"Issue extending a hold",
+				"hold_token", holdToken,
+				"hold_id", holdID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: ExtendTicketHoldResponse{ExpiresAt: expiresAt}}
+		return response, nil
+	})
+
+	// Find the best available adjacent seats for an event, and hold them.
+	huma.Post(api, "/events/{id}/best-available", func(ctx context.Context, input *struct {
+		EventID        int32  `path:"id"`
+		UserID         string `header:"x-user-id"`
+		AdmissionToken string `header:"x-admission-token"`
+		Body           BestAvailableRequest
+	}) (*ResponseEnvelope, error) {
+		holdID := input.UserID
+		selection := MapToSeatSelection(input.Body)
+		access := MapToSaleAccess(input.UserID, "", input.AdmissionToken)
+		hold, seats, err := service.HoldBestAvailableSeats(ctx, input.EventID, selection, holdID, access)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldID) || errors.Is(err, services.ErrEmptyHold) {
+				slog.Error("Invalid hold", "event_id", input.EventID, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, money.ErrUnsupportedCurrency) || errors.Is(err, money.ErrCurrencyMismatch) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrNoSeatsAvailable) || isNotOnSaleError(err) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPurchaseLimitExceeded) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if isAdmissionError(err) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			slog.Error(
+				"Issue holding best available seats",
+				"event_id", input.EventID,
+				"hold_id", holdID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToBestAvailableResponse(hold, seats)}, nil
+	})
+
+	// Itemize the price of purchasing several tickets together.
+	huma.Post(api, "/tickets/quote", func(ctx context.Context, input *struct {
+		Body QuoteTicketsRequest
+	}) (*ResponseEnvelope, error) {
+		quote, err := service.QuoteTickets(ctx, input.Body.TicketIDs, input.Body.PromoCode)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrTicketPurchased) || errors.Is(err, money.ErrCurrencyMismatch) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if isPromoCodeError(err) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			slog.Error("Issue quoting tickets", "ticket_ids", input.Body.TicketIDs, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToQuoteResponse(quote)}, nil
+	})
+
+	// Purchase all tickets held by a hold.
+	huma.Post(api, "/tickets/purchase", func(ctx context.Context, input *struct {
+		UserID string `header:"x-user-id"`
+		Body   PurchaseTicketsHoldRequest
+	}) (*ResponseEnvelope, error) {
+		holdID := input.UserID
+		holdToken := input.Body.HoldToken
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		card := MapToCard(input.Body.Card)
+
+		purchase, err := service.PurchaseHeldTickets(
+			ctx,
+			holdToken,
+			holdID,
+			int32(userID),
+			card,
+			input.Body.PromoCode,
+			input.Body.AccessCode,
+		)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldToken) || errors.Is(err, services.ErrInvalidHoldID) {
+				slog.Error("Invalid hold", "hold_token", holdToken, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, cache.ErrNotFound) {
+				slog.Error(
+					"Attempt to purchase tickets without a purchase hold",
+					"hold_token", holdToken,
+					"hold_id", holdID,
+					"error", err,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, services.ErrHoldIDMismatch) {
+				slog.Error(
+					"Attempt to purchase held tickets with incorrect hold id",
+					"hold_token", holdToken,
+					"hold_id", holdID,
+					"error", err,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, services.ErrTicketPurchased) {
+				slog.Error(
+					"Attempt to purchase an already purchased ticket",
+					"hold_token", holdToken,
+					"hold_id", holdID,
+					"error", err,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if isPromoCodeError(err) || errors.Is(err, services.ErrPurchaseLimitExceeded) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPurchaseInProgress) {
+				return nil, huma.Error409Conflict("")
+			}
+
+			if isNotOnSaleError(err) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPresaleAccessDenied) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			if errors.Is(err, services.ErrGATierSoldOut) ||
+				errors.Is(err, services.ErrListingNotActive) ||
+				errors.Is(err, services.ErrEventStarted) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrOwnListing) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, payment.ErrGatewayUnavailable) {
+				slog.Error(
+					"Payment gateway unavailable while purchasing held tickets",
+					"hold_token", holdToken,
+					"error", err,
+				)
+				return nil, huma.Error503ServiceUnavailable("")
+			}
+
+			slog.Error(
+				"Issue purchasing held tickets",
+				"hold_token", holdToken,
+				"hold_id", holdID,
+				"purchaser_id", userID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToPaymentResponse(purchase)}, nil
+	})
+
+	// Refund a purchased ticket.
+	huma.Post(api, "/tickets/{id}/refund", func(ctx context.Context, input *struct {
+		ID        int32  `path:"id"`
+		UserID    string `header:"x-user-id"`
+		Rerelease bool   `query:"rerelease" doc:"Return the ticket to inventory, instead of voiding it"`
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		refund, err := service.RefundTicket(ctx, input.ID, int32(userID), input.Rerelease)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotTicketOwner) {
+				slog.Error(
+					"Attempt to refund a ticket purchased by another user",
+					"ticket_id", input.ID,
+					"user_id", userID,
+				)
+				return nil, huma.Error403Forbidden("")
+			}
+
+			if errors.Is(err, services.ErrTicketTransferred) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPurchaseInProgress) {
+				return nil, huma.Error409Conflict("")
+			}
+
+			if errors.Is(err, payment.ErrInvalidTransition) || errors.Is(err, payment.ErrRefundExceedsPayment) {
+				slog.Error(
+					"Attempt to refund a ticket whose payment can't be refunded",
+					"ticket_id", input.ID,
+					"error", err,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			slog.Error(
+				"Issue refunding a ticket",
+				"ticket_id", input.ID,
+				"user_id", userID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToRefundResponse(refund, input.Rerelease)}, nil
+	})
+
+	// List a purchased ticket for resale.
+	huma.Post(api, "/tickets/{id}/resale", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+		Body   CreateResaleListingRequest
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		listing, err := service.ListTicketForResale(ctx, MapToResaleListing(input.Body, input.ID, int32(userID)))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotTicketOwner) {
+				slog.Error(
+					"Attempt to list a ticket purchased by another user",
+					"ticket_id", input.ID,
+					"user_id", userID,
+				)
+				return nil, huma.Error403Forbidden("")
+			}
+
+			if errors.Is(err, services.ErrTicketListed) || errors.Is(err, services.ErrEventStarted) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrResalePriceNotAllowed) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			slog.Error(
+				"Issue listing a ticket for resale",
+				"ticket_id", input.ID,
+				"user_id", userID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToResaleListingResponse(listing)}, nil
+	})
+
+	// Read the resale listings created by a user.
+	huma.Get(api, "/users/{id}/resale-listings", func(ctx context.Context, input *struct {
+		UserID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		listings, err := service.GetSellerResaleListings(ctx, input.UserID)
+		if err != nil {
+			slog.Error("Issue fetching user's resale listings", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToResaleListingsResponse(listings)}, nil
+	})
+
+	// Read an existing resale listing by id.
+	huma.Get(api, "/resale-listings/{id}", func(ctx context.Context, input *struct {
+		ID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		listing, err := service.GetResaleListing(ctx, input.ID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching resale listing", "listing_id", input.ID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToResaleListingResponse(listing)}, nil
+	})
+
+	// Cancel an active resale listing.
+	huma.Post(api, "/resale-listings/{id}/cancel", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		listing, err := service.CancelResaleListing(ctx, input.ID, int32(userID))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotListingSeller) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			if errors.Is(err, services.ErrListingNotActive) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			slog.Error(
+				"Issue cancelling a resale listing",
+				"listing_id", input.ID,
+				"user_id", userID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToResaleListingResponse(listing)}, nil
+	})
+
+	// Set a purchase hold on the ticket of an active resale listing, which is
+	// purchased along with other held tickets by the hold's token.
+	huma.Post(api, "/resale-listings/{id}/hold", func(ctx context.Context, input *struct {
+		ID             int32  `path:"id"`
+		UserID         string `header:"x-user-id"`
+		AdmissionToken string `header:"x-admission-token"`
+	}) (*ResponseEnvelope, error) {
+		holdID := input.UserID
+		access := MapToSaleAccess(input.UserID, "", input.AdmissionToken)
+		hold, err := service.HoldResaleListing(ctx, input.ID, holdID, access)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldID) {
+				slog.Error("Invalid hold", "listing_id", input.ID, "hold_id", holdID)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrListingNotActive) || errors.Is(err, services.ErrEventStarted) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, cache.ErrAlreadyHasHold) {
+				slog.Error(
+					"Attempt to place a hold on an already held resale listing",
+					"listing_id", input.ID,
+					"hold_id", holdID,
+				)
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, services.ErrOwnListing) || errors.Is(err, services.ErrPurchaseLimitExceeded) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			if isAdmissionError(err) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			slog.Error(
+				"Issue setting a resale listing hold",
+				"listing_id", input.ID,
+				"hold_id", holdID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToTicketsHoldResponse(hold)}, nil
+	})
+}
+
+// isTicketTypeError checks if the error is due to releasing tickets against,
+// or without, a ticket type, so that the reason can be given to the client.
+func isTicketTypeError(err error) bool {
+	return errors.Is(err, services.ErrNoSuchTicketType) ||
+		errors.Is(err, services.ErrTicketTypePriced) ||
+		errors.Is(err, services.ErrMissingPrice)
+}
+
+// isNotOnSaleError checks if the error is due to a ticket, or its type, not
+// being on sale.
+func isNotOnSaleError(err error) bool {
+	return errors.Is(err, services.ErrTicketTypeNotOnSale) || errors.Is(err, services.ErrNotOnSale)
+}
+
+// isAdmissionError checks if the error is due to the user not having been
+// admitted from an event's waiting room.
+func isAdmissionError(err error) bool {
+	return errors.Is(err, services.ErrAdmissionRequired) || errors.Is(err, services.ErrInvalidAdmissionToken)
+}
+
+// isPromoCodeError checks if the error is due to a promo code that can't be
+// applied, so that the reason can be given to the client.
+func isPromoCodeError(err error) bool {
+	return errors.Is(err, services.ErrInvalidPromoCode) ||
+		errors.Is(err, services.ErrPromoCodeUnavailable) ||
+		errors.Is(err, services.ErrPromoCodeNotApplicable)
+}
+
+func RegisterOrdersHandlers(api huma.API, service *services.OrdersService) {
+	// Read an existing order by id.
+	huma.Get(api, "/orders/{id}", func(ctx context.Context, input *struct {
+		ID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		order, err := service.GetOrder(ctx, input.ID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching order", "order_id", input.ID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToOrderResponse(order)}
+		return response, nil
+	})
+
+	// Read a user's orders.
+	huma.Get(api, "/users/{id}/orders", func(ctx context.Context, input *struct {
+		UserID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		orders, err := service.GetUserOrders(ctx, input.UserID)
+		if err != nil {
+			slog.Error("Issue fetching user's orders", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToUserOrdersResponse(orders)}
+		return response, nil
+	})
+}
+
+func RegisterCancellationsHandlers(api huma.API, service *services.CancellationsService) {
+	// Read the progress of refunds for a cancelled event.
+	huma.Get(api, "/events/{id}/cancellation", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		cancellation, err := service.GetEventCancellation(ctx, input.EventID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching event cancellation", "event_id", input.EventID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToEventCancellationResponse(cancellation)}
+		return response, nil
+	})
+}
+
+func RegisterPricingHandlers(api huma.API, service *services.PricingService) {
+	// Read the fees charged for a venue's events.
+	huma.Get(api, "/venues/{id}/fees", func(ctx context.Context, input *struct {
+		VenueID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		rule, err := service.GetVenueFeeRule(ctx, input.VenueID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching venue fee rule", "venue_id", input.VenueID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToFeeRuleResponse(rule)}, nil
+	})
+
+	// Set the fees charged for a venue's events, unless an event has its own.
+	huma.Put(api, "/venues/{id}/fees", func(ctx context.Context, input *struct {
+		VenueID int32 `path:"id"`
+		Body    FeeRule
+	}) (*struct{}, error) {
+		rule := MapToFeeRule(input.Body)
+		rule.VenueID = input.VenueID
+		err := service.SetVenueFeeRule(ctx, rule)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error(
+				"Issue setting venue fee rule",
+				"venue_id", input.VenueID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+
+	// Read the fees charged for an event, if set for the event itself.
+	huma.Get(api, "/events/{id}/fees", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		rule, err := service.GetEventFeeRule(ctx, input.EventID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching event fee rule", "event_id", input.EventID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		return &ResponseEnvelope{Body: MapToFeeRuleResponse(rule)}, nil
+	})
+
+	// Set the fees charged for an event, in place of its venue's.
+	huma.Put(api, "/events/{id}/fees", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+		Body    FeeRule
+	}) (*struct{}, error) {
+		rule := MapToFeeRule(input.Body)
+		rule.EventID = input.EventID
+		err := service.SetEventFeeRule(ctx, rule)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error(
+				"Issue setting event fee rule",
+				"event_id", input.EventID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+
+	// Set the sales tax rate for a region.
+	huma.Put(api, "/tax-rates", func(ctx context.Context, input *struct {
+		Body WriteTaxRateRequest
+	}) (*struct{}, error) {
+		err := service.SetTaxRate(ctx, MapToTaxRate(input.Body))
+		if err != nil {
+			slog.Error("Issue setting tax rate", "request_data", input.Body, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+}
+
+func RegisterPromoCodesHandlers(api huma.API, service *services.PromoCodesService) {
+	// Create a new promo code.
+	huma.Post(api, "/promo-codes", func(ctx context.Context, input *struct {
+		Body WritePromoCodeRequest
+	}) (*ResponseEnvelope, error) {
+		promoCode := MapToPromoCode(input.Body)
+		if !promoCode.IsValid() {
+			return nil, huma.Error422UnprocessableEntity("")
+		}
+
+		id, err := service.CreatePromoCode(ctx, promoCode)
+		if err != nil {
+			if errors.Is(err, services.ErrPromoCodeExists) {
+				return nil, huma.Error409Conflict("")
+			}
+
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error422UnprocessableEntity("No such event")
+			}
+
+			slog.Error("Issue creating promo code", "request_data", input.Body, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: CreatePromoCodeResponse{ID: id}}
+		return response, nil
+	})
+
+	// Read an existing promo code by id.
+	huma.Get(api, "/promo-codes/{id}", func(ctx context.Context, input *struct {
+		ID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		promoCode, err := service.GetPromoCode(ctx, input.ID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching promo code", "promo_code_id", input.ID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToPromoCodeResponse(promoCode)}
+		return response, nil
+	})
+
+	// Update an existing promo code.
+	huma.Put(api, "/promo-codes/{id}", func(ctx context.Context, input *struct {
+		ID   int32 `path:"id"`
+		Body WritePromoCodeRequest
+	}) (*struct{}, error) {
+		promoCode := MapToPromoCode(input.Body)
+		promoCode.ID = input.ID
+		if !promoCode.IsValid() {
+			return nil, huma.Error422UnprocessableEntity("")
+		}
+
+		err := service.UpdatePromoCode(ctx, promoCode)
+		if err != nil {
+			if errors.Is(err, services.ErrPromoCodeExists) {
+				return nil, huma.Error409Conflict("")
+			}
+
+			// The promo code and the event aren't distinguished here.
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error(
+				"Issue updating promo code",
+				"promo_code_id", input.ID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+
+	// Delete an existing promo code.
+	huma.Delete(api, "/promo-codes/{id}", func(ctx context.Context, input *struct {
+		ID int32 `path:"id"`
+	}) (*struct{}, error) {
+		err := service.DeletePromoCode(ctx, input.ID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue deleting promo code", "promo_code_id", input.ID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+}
+
+func RegisterWaitingRoomHandlers(api huma.API, service *services.WaitingRoomService) {
+	// Enable, or update, the waiting room for an event.
+	huma.Put(api, "/events/{id}/waiting-room", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+		Body    WriteWaitingRoomRequest
+	}) (*struct{}, error) {
+		err := service.SetWaitingRoom(ctx, MapToWaitingRoom(input.Body, input.EventID))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error(
+				"Issue setting waiting room",
+				"event_id", input.EventID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+
+	// Read the waiting room for an event.
+	huma.Get(api, "/events/{id}/waiting-room", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		room, err := service.GetWaitingRoom(ctx, input.EventID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching waiting room", "event_id", input.EventID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToWaitingRoomResponse(room)}
+		return response, nil
+	})
+
+	// Disable the waiting room for an event.
+	huma.Delete(api, "/events/{id}/waiting-room", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+	}) (*struct{}, error) {
+		err := service.DeleteWaitingRoom(ctx, input.EventID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue deleting waiting room", "event_id", input.EventID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+		return nil, nil
+	})
+
+	// Join the queue of an event's waiting room.
+	huma.Post(api, "/events/{id}/queue", func(ctx context.Context, input *struct {
+		EventID int32  `path:"id"`
+		UserID  string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		position, err := service.JoinQueue(ctx, input.EventID, input.UserID)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldID) {
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error(
+				"Issue joining waiting room queue",
+				"event_id", input.EventID,
+				"user_id", input.UserID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToQueuePositionResponse(position)}
+		return response, nil
+	})
+
+	// Read a user's position in the queue of an event's waiting room, and their
+	// admission token once admitted.
+	huma.Get(api, "/events/{id}/queue/position", func(ctx context.Context, input *struct {
+		EventID int32  `path:"id"`
+		UserID  string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		position, err := service.GetQueuePosition(ctx, input.EventID, input.UserID)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHoldID) {
+				return nil, huma.Error422UnprocessableEntity("")
+			}
+
+			if errors.Is(err, services.ErrNotInQueue) {
+				return nil, huma.Error404NotFound(err.Error())
+			}
+
+			slog.Error(
+				"Issue fetching waiting room queue position",
+				"event_id", input.EventID,
+				"user_id", input.UserID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToQueuePositionResponse(position)}
+		return response, nil
+	})
+}
+
+func RegisterWaitlistHandlers(api huma.API, service *services.WaitlistService) {
+	// Join the waitlist of a sold-out event, or change the quantity of tickets
+	// waited for.
+	huma.Post(api, "/events/{id}/waitlist", func(ctx context.Context, input *struct {
+		EventID int32  `path:"id"`
+		UserID  string `header:"x-user-id"`
+		Body    JoinWaitlistRequest
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		entry, err := service.JoinWaitlist(ctx, MapToWaitlistEntry(input.Body, input.EventID, int32(userID)))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotSoldOut) || errors.Is(err, services.ErrWaitlistOffered) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrEmptyHold) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			slog.Error(
+				"Issue joining waitlist",
+				"event_id", input.EventID,
+				"user_id", input.UserID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToWaitlistEntryResponse(entry, time.Now())}
+		return response, nil
+	})
+
+	// Read a user's place on an event's waitlist, and the hold they've been
+	// offered once tickets are available.
+	huma.Get(api, "/events/{id}/waitlist", func(ctx context.Context, input *struct {
+		EventID int32  `path:"id"`
+		UserID  string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		entry, err := service.GetWaitlistEntry(ctx, input.EventID, int32(userID))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error(
+				"Issue fetching waitlist entry",
+				"event_id", input.EventID,
+				"user_id", input.UserID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToWaitlistEntryResponse(entry, time.Now())}
+		return response, nil
+	})
+
+	// Leave an event's waitlist.
+	huma.Delete(api, "/events/{id}/waitlist", func(ctx context.Context, input *struct {
+		EventID int32  `path:"id"`
+		UserID  string `header:"x-user-id"`
+	}) (*struct{}, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		err = service.LeaveWaitlist(ctx, input.EventID, int32(userID))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error(
+				"Issue leaving waitlist",
+				"event_id", input.EventID,
+				"user_id", input.UserID,
 				"error", err,
 			)
 			return nil, huma.Error500InternalServerError("")
 		}
+		return nil, nil
+	})
+}
+
+func RegisterTransfersHandlers(api huma.API, service *services.TransfersService) {
+	// Transfer a purchased ticket to another user, pending until they accept.
+	huma.Post(api, "/tickets/{id}/transfer", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+		Body   CreateTicketTransferRequest
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
 
-		paymentClient := &payment.PaymentClient{}
-		paymentSuccessful, err := paymentClient.SubmitPayment(ticket, card)
+		transfer, err := service.CreateTransfer(ctx, MapToTicketTransfer(input.Body, input.ID, int32(userID)))
 		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotTicketOwner) {
+				slog.Error(
+					"Attempt to transfer a ticket purchased by another user",
+					"ticket_id", input.ID,
+					"user_id", userID,
+				)
+				return nil, huma.Error403Forbidden("")
+			}
+
+			if errors.Is(err, services.ErrTransferPending) || errors.Is(err, services.ErrEventStarted) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrTransferToSelf) || errors.Is(err, repos.ErrNoSuchUser) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
 			slog.Error(
-				"Issue on ticket payment submission",
+				"Issue transferring a ticket",
 				"ticket_id", input.ID,
-				"hold_id", holdID,
+				"user_id", userID,
+				"request_data", input.Body,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToTicketTransferResponse(transfer)}
+		return response, nil
+	})
+
+	// Read a ticket's transfer history.
+	huma.Get(api, "/tickets/{id}/transfers", func(ctx context.Context, input *struct {
+		ID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		transfers, err := service.GetTicketTransfers(ctx, input.ID)
+		if err != nil {
+			slog.Error("Issue fetching ticket's transfers", "ticket_id", input.ID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToTicketTransfersResponse(transfers)}
+		return response, nil
+	})
+
+	// Read the transfers sent or received by a user.
+	huma.Get(api, "/users/{id}/transfers", func(ctx context.Context, input *struct {
+		UserID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		transfers, err := service.GetUserTransfers(ctx, input.UserID)
+		if err != nil {
+			slog.Error("Issue fetching user's transfers", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToTicketTransfersResponse(transfers)}
+		return response, nil
+	})
+
+	// Read an existing transfer by id.
+	huma.Get(api, "/transfers/{id}", func(ctx context.Context, input *struct {
+		ID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		transfer, err := service.GetTransfer(ctx, input.ID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching transfer", "transfer_id", input.ID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToTicketTransferResponse(transfer)}
+		return response, nil
+	})
+
+	// Accept a pending transfer, taking ownership of its ticket.
+	huma.Post(api, "/transfers/{id}/accept", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		transfer, err := service.AcceptTransfer(ctx, input.ID, int32(userID))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotTransferRecipient) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			if errors.Is(err, services.ErrTransferNotPending) ||
+				errors.Is(err, services.ErrSenderNotOwner) ||
+				errors.Is(err, services.ErrEventStarted) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrPurchaseLimitExceeded) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			slog.Error(
+				"Issue accepting a transfer",
+				"transfer_id", input.ID,
+				"user_id", userID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToTicketTransferResponse(transfer)}
+		return response, nil
+	})
+
+	// Cancel a pending transfer.
+	huma.Post(api, "/transfers/{id}/cancel", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		transfer, err := service.CancelTransfer(ctx, input.ID, int32(userID))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotTransferSender) {
+				return nil, huma.Error403Forbidden(err.Error())
+			}
+
+			if errors.Is(err, services.ErrTransferNotPending) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			slog.Error(
+				"Issue cancelling a transfer",
+				"transfer_id", input.ID,
+				"user_id", userID,
 				"error", err,
 			)
 			return nil, huma.Error500InternalServerError("")
 		}
 
-		response := PaymentResponse{Success: paymentSuccessful}
+		response := &ResponseEnvelope{Body: MapToTicketTransferResponse(transfer)}
+		return response, nil
+	})
+}
 
-		if !paymentSuccessful {
-			return &ResponseEnvelope{Body: response}, nil
+func RegisterCredentialsHandlers(api huma.API, service *services.CredentialsService) {
+	// Read the signed credential of a purchased ticket, for its owner.
+	huma.Get(api, "/tickets/{id}/credential", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
 		}
 
-		err = service.SetTicketPurchaser(ctx, input.ID, int32(userID))
+		credential, err := service.GetCredential(ctx, input.ID, int32(userID))
 		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotTicketOwner) {
+				slog.Error(
+					"Attempt to read the credential of a ticket purchased by another user",
+					"ticket_id", input.ID,
+					"user_id", userID,
+				)
+				return nil, huma.Error403Forbidden("")
+			}
+
 			slog.Error(
-				"Issue setting ticket purchaser",
+				"Issue fetching ticket's credential",
 				"ticket_id", input.ID,
-				"purchaser_id", userID,
+				"user_id", userID,
 				"error", err,
 			)
 			return nil, huma.Error500InternalServerError("")
 		}
 
-		// NB: Purchase hold is left intact - as it only serves to filter out
-		// unavailable tickets, and the ticket is no longer available once
-		// purchased, there shouldn't be any impact to functionality. The
-		// purchase hold should also be removed from Redis once its expiration
-		// time is hit.
-		return &ResponseEnvelope{Body: response}, nil
+		response := &ResponseEnvelope{Body: MapToTicketCredentialResponse(credential)}
+		return response, nil
+	})
+
+	// Rotate the credential of a purchased ticket, so that copies of the
+	// previous credential are no longer accepted.
+	huma.Post(api, "/tickets/{id}/credential/rotate", func(ctx context.Context, input *struct {
+		ID     int32  `path:"id"`
+		UserID string `header:"x-user-id"`
+	}) (*ResponseEnvelope, error) {
+		userID, err := strconv.Atoi(input.UserID)
+		if err != nil {
+			slog.Error("Unable to parse user id", "user_id", input.UserID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		credential, err := service.RotateCredential(ctx, input.ID, int32(userID))
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			if errors.Is(err, services.ErrNotTicketOwner) {
+				slog.Error(
+					"Attempt to rotate the credential of a ticket purchased by another user",
+					"ticket_id", input.ID,
+					"user_id", userID,
+				)
+				return nil, huma.Error403Forbidden("")
+			}
+
+			slog.Error(
+				"Issue rotating ticket's credential",
+				"ticket_id", input.ID,
+				"user_id", userID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToTicketCredentialResponse(credential)}
+		return response, nil
+	})
+
+	// Read the public key that credentials are verified with, for scanners.
+	huma.Get(api, "/credentials/public-key", func(ctx context.Context, input *struct{}) (*ResponseEnvelope, error) {
+		response := &ResponseEnvelope{Body: MapToCredentialsPublicKeyResponse(service.PublicKey())}
+		return response, nil
+	})
+}
+
+func RegisterCheckinsHandlers(api huma.API, service *services.CheckinsService) {
+	// Check in a scanned ticket to the event.
+	huma.Post(api, "/events/{id}/checkins", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+		Body    CreateCheckinRequest
+	}) (*ResponseEnvelope, error) {
+		checkin, err := service.CheckIn(ctx, MapToCheckin(input.Body, input.EventID), input.Body.Credential)
+		if err != nil {
+			if errors.Is(err, services.ErrAlreadyCheckedIn) {
+				return nil, huma.Error409Conflict(err.Error())
+			}
+
+			if errors.Is(err, services.ErrInvalidCredential) ||
+				errors.Is(err, services.ErrCredentialNotCurrent) ||
+				errors.Is(err, services.ErrWrongEvent) {
+				slog.Error(
+					"Rejected a scanned ticket",
+					"event_id", input.EventID,
+					"ticket_id", input.Body.TicketID,
+					"gate", input.Body.Gate,
+					"device", input.Body.Device,
+					"error", err,
+				)
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			slog.Error(
+				"Issue checking in a ticket",
+				"event_id", input.EventID,
+				"ticket_id", input.Body.TicketID,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToCheckinResponse(checkin)}
+		return response, nil
+	})
+
+	// Read the number of an event's tickets that have been checked in.
+	huma.Get(api, "/events/{id}/attendance", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		attendance, err := service.GetAttendance(ctx, input.EventID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue fetching event's attendance", "event_id", input.EventID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToEventAttendanceResponse(attendance)}
+		return response, nil
+	})
+
+	// Read a signed bundle of an event's tickets, for scanners to check
+	// credentials against while offline.
+	huma.Get(api, "/events/{id}/scanner-bundle", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+	}) (*ResponseEnvelope, error) {
+		bundle, err := service.GetScannerBundle(ctx, input.EventID)
+		if err != nil {
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				return nil, huma.Error404NotFound("")
+			}
+
+			slog.Error("Issue creating scanner bundle", "event_id", input.EventID, "error", err)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToScannerBundleResponse(bundle)}
+		return response, nil
+	})
+
+	// Upload scans made by a device while it was offline, reconciling them
+	// with the event's check-ins.
+	huma.Post(api, "/events/{id}/checkins/offline", func(ctx context.Context, input *struct {
+		EventID int32 `path:"id"`
+		Body    SyncOfflineScansRequest
+	}) (*ResponseEnvelope, error) {
+		results, err := service.SyncOfflineScans(
+			ctx,
+			input.EventID,
+			input.Body.Bundle,
+			MapToOfflineScans(input.Body),
+		)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidBundle) || errors.Is(err, services.ErrWrongEvent) {
+				return nil, huma.Error422UnprocessableEntity(err.Error())
+			}
+
+			slog.Error(
+				"Issue syncing offline scans",
+				"event_id", input.EventID,
+				"device", input.Body.Device,
+				"error", err,
+			)
+			return nil, huma.Error500InternalServerError("")
+		}
+
+		response := &ResponseEnvelope{Body: MapToScanResultsResponse(results)}
+		return response, nil
 	})
 }
 
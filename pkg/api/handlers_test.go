@@ -2,6 +2,7 @@ package api_test
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -15,7 +16,9 @@ import (
 	"github.com/danielgtaylor/huma/v2/humatest"
 	pkgApi "github.com/dslaw/book-tickets/pkg/api"
 	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/credentials"
 	"github.com/dslaw/book-tickets/pkg/db"
+	"github.com/dslaw/book-tickets/pkg/payment"
 	"github.com/dslaw/book-tickets/pkg/repos"
 	"github.com/dslaw/book-tickets/pkg/search"
 	"github.com/dslaw/book-tickets/pkg/services"
@@ -33,6 +36,9 @@ const (
 	userID       = int32(1)
 	userIDString = "1"
 
+	otherUserID       = int32(2)
+	otherUserIDString = "2"
+
 	readVenueID    = int32(1)
 	updateVenueID  = int32(2)
 	deletedVenueID = int32(3)
@@ -46,7 +52,18 @@ const (
 	ticketID       = int32(1)
 	ticketIDString = "1"
 
+	otherTicketID       = int32(2)
+	otherTicketIDString = "2"
+
+	// Payments made with the test card are accepted by the fake payment
+	// processor, and payments made with the declined card are declined.
+	testCardNumber     = "4242424242424242"
+	declinedCardNumber = "4000000000000002"
+
+	gaTierID = int32(10)
+
 	ticketHoldDurationString = "1m"
+	ticketHoldMaxExtensions  = 1
 
 	eventDocument1ID = "1"
 	eventDocument2ID = "2"
@@ -54,13 +71,42 @@ const (
 	venueDocument2ID = "2"
 )
 
+// readEventStartsAt is when the test event to read starts, which has passed.
+var readEventStartsAt = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
 func ClearTestDatabase(ctx context.Context, conn *pgxpool.Pool) error {
 	tableNames := []string{
+		"idempotency_keys",
+		"fee_rules",
+		"tax_rates",
+		"cancellation_refunds",
+		"event_cancellations",
+		"refunds",
+		"resale_payouts",
+		"resale_listings",
+		"checkins",
+		"ticket_transfers",
+		"ticket_credentials",
+		"order_items",
+		"orders",
+		"promo_code_redemptions",
+		"promo_codes",
 		"performers",
 		"event_performers",
 		"tickets",
+		"ga_tiers",
+		"ticket_types",
+		"presale_users",
+		"presales",
+		"ticket_releases",
+		"waitlist_entries",
+		"waiting_rooms",
+		"venue_seats",
+		"venue_rows",
+		"venue_sections",
 		"events",
 		"venues",
+		"payments",
 		"users",
 	}
 
@@ -79,9 +125,11 @@ func WriteTestData(ctx context.Context, conn *pgxpool.Pool) error {
 	insertUsersStmt := `
 insert into users (id, name, email)
 overriding system value
-values ($1, 'Test user', 'test@user.com');
+values
+    ($1, 'Test user', 'test@user.com'),
+    ($2, 'Other test user', 'other@user.com');
 `
-	_, err := conn.Exec(ctx, insertUsersStmt, userID)
+	_, err := conn.Exec(ctx, insertUsersStmt, userID, otherUserID)
 	if err != nil {
 		return err
 	}
@@ -142,9 +190,9 @@ values
 func WriteTicket(t *testing.T, ctx context.Context, conn *pgxpool.Pool) {
 	_, err := conn.Exec(
 		ctx,
-		`insert into tickets (id, event_id, purchaser_id, price, seat)
+		`insert into tickets (id, event_id, purchaser_id, price, currency, seat)
             overriding system value
-            values ($1, $2, null, 20, 'Balcony');`,
+            values ($1, $2, null, 2000, 'USD', 'Balcony');`,
 		ticketID,
 		readEventID,
 	)
@@ -153,14 +201,174 @@ func WriteTicket(t *testing.T, ctx context.Context, conn *pgxpool.Pool) {
 	}
 }
 
-// DeleteTicket deletes the ticket inserted by `WriteTicket`.
+// WriteOtherTicket sets up a second ticket for the same event as the one set
+// up by `WriteTicket`.
+func WriteOtherTicket(t *testing.T, ctx context.Context, conn *pgxpool.Pool) {
+	_, err := conn.Exec(
+		ctx,
+		`insert into tickets (id, event_id, purchaser_id, price, currency, seat)
+            overriding system value
+            values ($1, $2, null, 2000, 'USD', 'Balcony');`,
+		otherTicketID,
+		readEventID,
+	)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+	}
+}
+
+// SetTicketPurchaser marks a ticket as purchased by the given user, without
+// going through a purchase.
+func SetTicketPurchaser(t *testing.T, ctx context.Context, conn *pgxpool.Pool, id int32, purchaserID int32) {
+	_, err := conn.Exec(ctx, "update tickets set purchaser_id = $2 where id = $1", id, purchaserID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+	}
+}
+
+// DeleteTicket deletes the tickets inserted by `WriteTicket` and
+// `WriteOtherTicket`.
 func DeleteTicket(t *testing.T, ctx context.Context, conn *pgxpool.Pool) {
-	_, err := conn.Exec(ctx, "delete from tickets where id = $1", ticketID)
+	ticketIDs := []int32{ticketID, otherTicketID}
+
+	// Rows that reference the tickets are deleted first.
+	statements := []string{
+		"delete from refunds where ticket_id = any($1)",
+		"delete from order_items where ticket_id = any($1)",
+		"delete from ticket_transfers where ticket_id = any($1)",
+		"delete from resale_payouts where listing_id in (select id from resale_listings where ticket_id = any($1))",
+		"delete from resale_listings where ticket_id = any($1)",
+		"delete from ticket_credentials where ticket_id = any($1)",
+		"delete from checkins where ticket_id = any($1)",
+		"delete from tickets where id = any($1)",
+	}
+	for _, stmt := range statements {
+		if _, err := conn.Exec(ctx, stmt, ticketIDs); err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to delete test data on teardown: %s", err))
+		}
+	}
+}
+
+// SetResaleMaxRate sets the test event's cap on resale prices, in basis points
+// of face value, or removes the cap if `rate` is zero.
+func SetResaleMaxRate(t *testing.T, ctx context.Context, conn *pgxpool.Pool, rate int32) {
+	_, err := conn.Exec(
+		ctx,
+		"update events set resale_max_rate = nullif($2, 0) where id = $1",
+		readEventID,
+		rate,
+	)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data: %s", err))
+	}
+}
+
+// DeleteWaitlistEntries deletes the entries on the test event's waitlist.
+func DeleteWaitlistEntries(t *testing.T, ctx context.Context, conn *pgxpool.Pool) {
+	_, err := conn.Exec(ctx, "delete from waitlist_entries where event_id = $1", readEventID)
 	if err != nil {
 		assert.FailNow(t, fmt.Sprintf("Unable to delete test data on teardown: %s", err))
 	}
 }
 
+// SetMaxTicketsPerUser sets the test event's limit on tickets per user, or
+// removes the limit if `limit` is zero.
+func SetMaxTicketsPerUser(t *testing.T, ctx context.Context, conn *pgxpool.Pool, limit int32) {
+	_, err := conn.Exec(
+		ctx,
+		"update events set max_tickets_per_user = nullif($2, 0) where id = $1",
+		readEventID,
+		limit,
+	)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data: %s", err))
+	}
+}
+
+// WriteGATier sets up a general admission tier, with the given capacity, that
+// can be held and purchased.
+func WriteGATier(t *testing.T, ctx context.Context, conn *pgxpool.Pool, capacity int32) {
+	_, err := conn.Exec(
+		ctx,
+		`insert into ga_tiers (id, event_id, name, price, currency, capacity)
+            overriding system value
+            values ($1, $2, 'Floor', 1000, 'USD', $3);`,
+		gaTierID,
+		readEventID,
+		capacity,
+	)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+	}
+}
+
+// DeleteGATiers deletes the general admission tiers of the test event, along
+// with the tickets issued for them.
+func DeleteGATiers(t *testing.T, ctx context.Context, conn *pgxpool.Pool) {
+	statements := []string{
+		`delete from order_items where ticket_id in (
+            select tickets.id
+            from tickets
+            inner join ga_tiers on tickets.ga_tier_id = ga_tiers.id
+            where ga_tiers.event_id = $1
+        )`,
+		"delete from tickets where ga_tier_id in (select id from ga_tiers where event_id = $1)",
+		"delete from ga_tiers where event_id = $1",
+	}
+	for _, stmt := range statements {
+		if _, err := conn.Exec(ctx, stmt, readEventID); err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to delete test data on teardown: %s", err))
+		}
+	}
+}
+
+// SetEventStart moves the start and end of the event given by `eventID` to
+// `startsAt`, e.g. so that the event hasn't started yet.
+func SetEventStart(t *testing.T, ctx context.Context, conn *pgxpool.Pool, eventID int32, startsAt time.Time) {
+	_, err := conn.Exec(
+		ctx,
+		"update events set starts_at = $2, ends_at = $2 where id = $1",
+		eventID,
+		startsAt,
+	)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to update test data: %s", err))
+	}
+}
+
+// FetchTicketCredential fetches the credential of the ticket given by
+// `ticketID` for its owner, given by `header`, as scanned at check-in.
+func FetchTicketCredential(suite *HandlersTestSuite, ticketID int32, header string) string {
+	t := suite.T()
+	api := CreateAPIForCredentials(suite)
+
+	response := api.Get(fmt.Sprintf("/tickets/%d/credential", ticketID), header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	credential := pkgApi.TicketCredentialResponse{}
+	json.NewDecoder(response.Body).Decode(&credential)
+	return credential.Credential
+}
+
+// FetchScannerBundle fetches the signed scanner bundle of the event given by
+// `eventID`, as downloaded by a scanner before going offline, along with the
+// time it was issued.
+func FetchScannerBundle(suite *HandlersTestSuite, eventID int32) (string, time.Time) {
+	t := suite.T()
+	api := CreateAPIForCheckins(suite)
+
+	response := api.Get(fmt.Sprintf("/events/%d/scanner-bundle", eventID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	signed := pkgApi.ScannerBundleResponse{}
+	json.NewDecoder(response.Body).Decode(&signed)
+
+	verifier := credentials.NewVerifier(CreateCredentialSigner().PublicKey())
+	bundle, err := verifier.VerifyBundle(signed.Bundle)
+	require.Nil(t, err)
+	return signed.Bundle, bundle.IssuedTime().UTC()
+}
+
 func TeardownTicketHolds(t *testing.T, ctx context.Context, conn *redis.Client) {
 	keys, err := conn.Keys(ctx, "*").Result()
 	err = conn.Del(ctx, keys...).Err()
@@ -345,6 +553,14 @@ func CreateAPIForVenues(suite *HandlersTestSuite) humatest.TestAPI {
 	return api
 }
 
+func CreateAPIForVenueLayouts(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewVenueLayoutsService(repos.NewVenueLayoutsRepo(suite.Conn))
+	_, api := humatest.New(t)
+	pkgApi.RegisterVenueLayoutsHandlers(api, service)
+	return api
+}
+
 func CreateAPIForEvents(suite *HandlersTestSuite) humatest.TestAPI {
 	t := suite.T()
 	service := services.NewEventsService(repos.NewEventsRepo(suite.Conn))
@@ -353,19 +569,132 @@ func CreateAPIForEvents(suite *HandlersTestSuite) humatest.TestAPI {
 	return api
 }
 
-func CreateAPIForTickets(suite *HandlersTestSuite) humatest.TestAPI {
-	t := suite.T()
+// CreateTicketsService creates a tickets service, for the handlers of tickets
+// and of the services that depend on it.
+func CreateTicketsService(suite *HandlersTestSuite) *services.TicketsService {
 	ticketHoldDuration, _ := time.ParseDuration(ticketHoldDurationString)
-	service := services.NewTicketsService(
+	return services.NewTicketsService(
 		repos.NewTicketsRepo(suite.Conn),
+		repos.NewPaymentsRepo(suite.Conn),
 		cache.NewTicketHoldClient(suite.RedisConn, ""),
+		payment.NewFakeProcessor(payment.DefaultFakeRules(), 0),
+		services.NewPricingService(repos.NewPricingRepo(suite.Conn)),
+		services.NewPromoCodesService(repos.NewPromoCodesRepo(suite.Conn)),
+		services.NewWaitingRoomService(
+			repos.NewWaitingRoomsRepo(suite.Conn),
+			cache.NewQueueClient(suite.RedisConn, "queue:"),
+			[]byte("secret"),
+			time.Second,
+			time.Minute,
+		),
 		ticketHoldDuration,
+		ticketHoldMaxExtensions,
 	)
+}
+
+// MakePurchaseTicketRequest makes the body of a request to purchase a held
+// ticket, paid for with the card given by `number`.
+func MakePurchaseTicketRequest(number string) map[string]any {
+	return map[string]any{
+		"card": map[string]any{
+			"name":             "Test user",
+			"address":          "11 Front Street",
+			"number":           number,
+			"expiration_month": 1,
+			"expiration_year":  99,
+			"cvc":              "123",
+		},
+	}
+}
+
+func CreateAPIForTickets(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := CreateTicketsService(suite)
 	_, api := humatest.New(t)
 	pkgApi.RegisterTicketsHandlers(api, service)
 	return api
 }
 
+func CreateAPIForOrders(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewOrdersService(repos.NewOrdersRepo(suite.Conn))
+	_, api := humatest.New(t)
+	pkgApi.RegisterOrdersHandlers(api, service)
+	return api
+}
+
+func CreateAPIForPricing(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewPricingService(repos.NewPricingRepo(suite.Conn))
+	_, api := humatest.New(t)
+	pkgApi.RegisterPricingHandlers(api, service)
+	return api
+}
+
+func CreateAPIForPromoCodes(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewPromoCodesService(repos.NewPromoCodesRepo(suite.Conn))
+	_, api := humatest.New(t)
+	pkgApi.RegisterPromoCodesHandlers(api, service)
+	return api
+}
+
+func CreateAPIForCancellations(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewCancellationsService(
+		repos.NewCancellationsRepo(suite.Conn),
+		repos.NewTicketsRepo(suite.Conn),
+		payment.NewFakeProcessor(nil, 0),
+		10,
+		1,
+	)
+	_, api := humatest.New(t)
+	pkgApi.RegisterCancellationsHandlers(api, service)
+	return api
+}
+
+func CreateAPIForTransfers(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewTransfersService(repos.NewTransfersRepo(suite.Conn))
+	_, api := humatest.New(t)
+	pkgApi.RegisterTransfersHandlers(api, service)
+	return api
+}
+
+// CreateCredentialSigner creates the signer of ticket credentials, which is the
+// same for each API so that credentials can be checked across them.
+func CreateCredentialSigner() *credentials.Signer {
+	return credentials.NewSigner(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+}
+
+func CreateAPIForCredentials(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewCredentialsService(repos.NewCredentialsRepo(suite.Conn), CreateCredentialSigner())
+	_, api := humatest.New(t)
+	pkgApi.RegisterCredentialsHandlers(api, service)
+	return api
+}
+
+func CreateAPIForCheckins(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewCheckinsService(repos.NewCheckinsRepo(suite.Conn), CreateCredentialSigner())
+	_, api := humatest.New(t)
+	pkgApi.RegisterCheckinsHandlers(api, service)
+	return api
+}
+
+func CreateAPIForWaitlist(suite *HandlersTestSuite) humatest.TestAPI {
+	t := suite.T()
+	service := services.NewWaitlistService(
+		repos.NewWaitlistsRepo(suite.Conn),
+		CreateTicketsService(suite),
+		time.Minute,
+	)
+	_, api := humatest.New(t)
+	pkgApi.RegisterWaitlistHandlers(api, service)
+	return api
+}
+
 func CreateAPIForSearch(suite *HandlersTestSuite) humatest.TestAPI {
 	t := suite.T()
 	client := search.NewSearchClientFromHTTPClient(
@@ -570,6 +899,98 @@ func (suite *HandlersTestSuite) TestDeleteVenueWhenDoesntExistOrDeleted() {
 	}
 }
 
+// Test setting, replacing and deleting a venue's seating layout.
+func (suite *HandlersTestSuite) TestSetVenueLayout() {
+	t := suite.T()
+	api := CreateAPIForVenueLayouts(suite)
+	path := fmt.Sprintf("/venues/%d/layout", updateVenueID)
+
+	data := map[string]any{
+		"sections": []map[string]any{
+			{"name": "Orchestra", "rows": []map[string]any{
+				{"name": "A", "seats": []map[string]any{
+					{"number": "1", "x": 0, "y": 0, "accessible": true},
+					{"number": "2", "x": 1, "y": 0},
+				}},
+			}},
+		},
+	}
+	response := api.Put(path, data)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	response = api.Get(path)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	created := pkgApi.VenueLayoutResponse{}
+	json.NewDecoder(response.Body).Decode(&created)
+	require.Len(t, created.Sections, 1)
+	require.Len(t, created.Sections[0].Rows, 1)
+	require.Len(t, created.Sections[0].Rows[0].Seats, 2)
+	keptSeat := created.Sections[0].Rows[0].Seats[0]
+	assert.Equal(t, "1", keptSeat.Number)
+	assert.True(t, keptSeat.Accessible)
+
+	// Replacing the layout keeps the ids of seats that are still part of it.
+	data["sections"] = []map[string]any{
+		{"name": "Orchestra", "rows": []map[string]any{
+			{"name": "A", "seats": []map[string]any{{"number": "1", "x": 0, "y": 0}}},
+		}},
+	}
+	response = api.Put(path, data)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	response = api.Get(path)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	replaced := pkgApi.VenueLayoutResponse{}
+	json.NewDecoder(response.Body).Decode(&replaced)
+	require.Len(t, replaced.Sections, 1)
+	assert.Equal(t, []pkgApi.VenueSeatResponse{
+		{ID: keptSeat.ID, Number: "1", X: 0, Y: 0, Accessible: false, Obstructed: false},
+	}, replaced.Sections[0].Rows[0].Seats)
+
+	response = api.Delete(path)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	response = api.Get(path)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	deleted := pkgApi.VenueLayoutResponse{}
+	json.NewDecoder(response.Body).Decode(&deleted)
+	assert.Empty(t, deleted.Sections)
+}
+
+// Test that a layout with duplicate seats is rejected.
+func (suite *HandlersTestSuite) TestSetVenueLayoutWhenInvalid() {
+	t := suite.T()
+	api := CreateAPIForVenueLayouts(suite)
+
+	data := map[string]any{
+		"sections": []map[string]any{
+			{"name": "Orchestra", "rows": []map[string]any{
+				{"name": "A", "seats": []map[string]any{
+					{"number": "1", "x": 0, "y": 0},
+					{"number": "1", "x": 1, "y": 0},
+				}},
+			}},
+		},
+	}
+	response := api.Put(fmt.Sprintf("/venues/%d/layout", updateVenueID), data)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test that the layout of a non-existent or deleted venue can't be set.
+func (suite *HandlersTestSuite) TestSetVenueLayoutWhenVenueDoesntExistOrDeleted() {
+	t := suite.T()
+	api := CreateAPIForVenueLayouts(suite)
+
+	for _, id := range []int32{missingVenueID, deletedVenueID} {
+		path := fmt.Sprintf("/venues/%d/layout", id)
+		response := api.Put(path, map[string]any{"sections": []map[string]any{}})
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	}
+}
+
 // Test creating a new event.
 func (suite *HandlersTestSuite) TestCreateEvent() {
 	t := suite.T()
@@ -876,8 +1297,8 @@ func (suite *HandlersTestSuite) TestReleaseTickets() {
 	api := CreateAPIForTickets(suite)
 
 	data := []map[string]any{
-		{"seat": "GA", "price": 10, "number": 2},
-		{"seat": "Balcony", "price": 20, "number": 1},
+		{"seat": "GA", "price": map[string]any{"amount": 1000, "currency": "USD"}, "number": 2},
+		{"seat": "Balcony", "price": map[string]any{"amount": 2000, "currency": "USD"}, "number": 1},
 	}
 	requestBody := map[string]any{"ticket_releases": data}
 
@@ -896,7 +1317,8 @@ func (suite *HandlersTestSuite) TestReleaseTickets() {
 	type partialTicket struct {
 		EventID          int32
 		PurchaserIDValid bool
-		Price            int32
+		Price            int64
+		Currency         string
 		Seat             string
 	}
 	actual := make([]partialTicket, len(rows))
@@ -905,14 +1327,15 @@ func (suite *HandlersTestSuite) TestReleaseTickets() {
 			EventID:          row.Ticket.EventID,
 			PurchaserIDValid: row.Ticket.PurchaserID.Valid,
 			Price:            row.Ticket.Price,
+			Currency:         row.Ticket.Currency,
 			Seat:             row.Ticket.Seat,
 		}
 	}
 
 	assert.EqualValues(t, []partialTicket{
-		{EventID: readEventID, PurchaserIDValid: false, Price: 10, Seat: "GA"},
-		{EventID: readEventID, PurchaserIDValid: false, Price: 10, Seat: "GA"},
-		{EventID: readEventID, PurchaserIDValid: false, Price: 20, Seat: "Balcony"},
+		{EventID: readEventID, PurchaserIDValid: false, Price: 1000, Currency: "USD", Seat: "GA"},
+		{EventID: readEventID, PurchaserIDValid: false, Price: 1000, Currency: "USD", Seat: "GA"},
+		{EventID: readEventID, PurchaserIDValid: false, Price: 2000, Currency: "USD", Seat: "Balcony"},
 	}, actual)
 }
 
@@ -922,8 +1345,8 @@ func (suite *HandlersTestSuite) TestReleaseTicketsWhenEventDoesntExistOrDeleted(
 	api := CreateAPIForTickets(suite)
 
 	data := []map[string]any{
-		{"seat": "GA", "price": 10, "number": 2},
-		{"seat": "Balcony", "price": 20, "number": 1},
+		{"seat": "GA", "price": map[string]any{"amount": 1000, "currency": "USD"}, "number": 2},
+		{"seat": "Balcony", "price": map[string]any{"amount": 2000, "currency": "USD"}, "number": 1},
 	}
 	requestBody := map[string]any{"ticket_releases": data}
 
@@ -934,6 +1357,164 @@ func (suite *HandlersTestSuite) TestReleaseTicketsWhenEventDoesntExistOrDeleted(
 	}
 }
 
+// Test releasing tickets priced in a different currency to the event's
+// existing tickets.
+func (suite *HandlersTestSuite) TestReleaseTicketsWhenCurrencyDiffers() {
+	t := suite.T()
+	ctx := context.Background()
+	WriteTicket(t, ctx, suite.Conn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForTickets(suite)
+
+	data := []map[string]any{
+		{"seat": "GA", "price": map[string]any{"amount": 1000, "currency": "EUR"}, "number": 2},
+	}
+	requestBody := map[string]any{"ticket_releases": data}
+
+	response := api.Post(fmt.Sprintf("/events/%d/tickets", readEventID), requestBody)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test releasing tickets for seats of the event's venue's layout.
+func (suite *HandlersTestSuite) TestReleaseTicketsForVenueSeats() {
+	t := suite.T()
+	ctx := context.Background()
+
+	layoutAPI := CreateAPIForVenueLayouts(suite)
+	layoutPath := fmt.Sprintf("/venues/%d/layout", readVenueID)
+	response := layoutAPI.Put(layoutPath, map[string]any{
+		"sections": []map[string]any{
+			{"name": "Orchestra", "rows": []map[string]any{
+				{"name": "A", "seats": []map[string]any{{"number": "1", "x": 0, "y": 0}}},
+			}},
+		},
+	})
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	defer func() {
+		_, err := suite.Conn.Exec(ctx, "delete from tickets where venue_seat_id is not null")
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to delete test data on teardown: %s", err))
+		}
+		_, err = suite.Conn.Exec(ctx, "delete from venue_sections where venue_id = $1", readVenueID)
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to delete test data on teardown: %s", err))
+		}
+	}()
+
+	response = layoutAPI.Get(layoutPath)
+	layout := pkgApi.VenueLayoutResponse{}
+	json.NewDecoder(response.Body).Decode(&layout)
+	seatID := layout.Sections[0].Rows[0].Seats[0].ID
+
+	api := CreateAPIForTickets(suite)
+	path := fmt.Sprintf("/events/%d/tickets", readEventID)
+	requestBody := map[string]any{"ticket_releases": []map[string]any{
+		{"seat_ids": []int32{seatID}, "price": map[string]any{"amount": 1000, "currency": "USD"}},
+	}}
+
+	response = api.Post(path, requestBody)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	var seat string
+	var venueSeatID int32
+	err := suite.Conn.QueryRow(
+		ctx,
+		"select seat, venue_seat_id from tickets where event_id = $1 and venue_seat_id is not null",
+		readEventID,
+	).Scan(&seat, &venueSeatID)
+	require.Nil(t, err)
+	assert.Equal(t, "Orchestra", seat)
+	assert.Equal(t, seatID, venueSeatID)
+
+	// A seat only has one ticket.
+	response = api.Post(path, requestBody)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	// Seats with tickets can't be removed from the layout.
+	response = layoutAPI.Delete(layoutPath)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	// Seats of other venues' layouts aren't part of the event's venue.
+	requestBody["ticket_releases"] = []map[string]any{
+		{"seat_ids": []int32{missingVenueID}, "price": map[string]any{"amount": 1000, "currency": "USD"}},
+	}
+	response = api.Post(path, requestBody)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test holding the best available adjacent seats for an event.
+func (suite *HandlersTestSuite) TestHoldBestAvailableSeats() {
+	t := suite.T()
+	ctx := context.Background()
+
+	layoutAPI := CreateAPIForVenueLayouts(suite)
+	layoutPath := fmt.Sprintf("/venues/%d/layout", readVenueID)
+	response := layoutAPI.Put(layoutPath, map[string]any{
+		"sections": []map[string]any{
+			{"name": "Orchestra", "rows": []map[string]any{
+				{"name": "A", "seats": []map[string]any{
+					{"number": "1", "x": 0, "y": 0},
+					{"number": "2", "x": 1, "y": 0},
+					{"number": "3", "x": 2, "y": 0},
+				}},
+				{"name": "B", "seats": []map[string]any{{"number": "1", "x": 0, "y": 1}}},
+			}},
+		},
+	})
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+	defer func() {
+		_, err := suite.Conn.Exec(ctx, "delete from tickets where venue_seat_id is not null")
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to delete test data on teardown: %s", err))
+		}
+		_, err = suite.Conn.Exec(ctx, "delete from venue_sections where venue_id = $1", readVenueID)
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to delete test data on teardown: %s", err))
+		}
+	}()
+
+	response = layoutAPI.Get(layoutPath)
+	layout := pkgApi.VenueLayoutResponse{}
+	json.NewDecoder(response.Body).Decode(&layout)
+	seatIDs := make([]int32, 0)
+	for _, row := range layout.Sections[0].Rows {
+		for _, seat := range row.Seats {
+			seatIDs = append(seatIDs, seat.ID)
+		}
+	}
+
+	api := CreateAPIForTickets(suite)
+	response = api.Post(fmt.Sprintf("/events/%d/tickets", readEventID), map[string]any{"ticket_releases": []map[string]any{
+		{"seat_ids": seatIDs, "price": map[string]any{"amount": 1000, "currency": "USD"}},
+	}})
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	path := fmt.Sprintf("/events/%d/best-available", readEventID)
+	requestBody := map[string]any{
+		"quantity":  2,
+		"max_price": map[string]any{"amount": 1000, "currency": "USD"},
+		"sections":  []string{"Orchestra"},
+	}
+
+	response = api.Post(path, "x-user-id: 123", requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	hold := pkgApi.BestAvailableResponse{}
+	json.NewDecoder(response.Body).Decode(&hold)
+	assert.NotEmpty(t, hold.HoldToken)
+	require.Len(t, hold.Seats, 2)
+	assert.Equal(t, "A", hold.Seats[0].Row)
+	assert.Equal(t, "A", hold.Seats[1].Row)
+
+	// The remaining seats aren't adjacent.
+	response = api.Post(path, "x-user-id: 456", requestBody)
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
 // Test fetching tickets for an existing event.
 func (suite *HandlersTestSuite) TestGetTickets() {
 	t := suite.T()
@@ -964,14 +1545,14 @@ func (suite *HandlersTestSuite) TestGetTickets() {
 	setup := func() {
 		_, err := suite.Conn.Exec(
 			ctx,
-			`insert into tickets (id, event_id, purchaser_id, price, seat)
+			`insert into tickets (id, event_id, purchaser_id, price, currency, seat)
             overriding system value
             values
-                ($3, $1, null, 10, 'GA'),
-                ($4, $1, null, 10, 'GA'),
-                ($5, $1, $2, 10, 'GA'),
-                ($6, $1, null, 20, 'Balcony'),
-                ($7, $1, null, 20, 'Balcony');
+                ($3, $1, null, 1000, 'USD', 'GA'),
+                ($4, $1, null, 1000, 'USD', 'GA'),
+                ($5, $1, $2, 1000, 'USD', 'GA'),
+                ($6, $1, null, 2000, 'USD', 'Balcony'),
+                ($7, $1, null, 2000, 'USD', 'Balcony');
             `,
 			readEventID,
 			userID,
@@ -1000,14 +1581,23 @@ func (suite *HandlersTestSuite) TestGetTickets() {
 
 	expected := pkgApi.GetAvailableTicketsAggregateResponse{
 		Available: []pkgApi.GetAvailableTicketsAggregate{
-			{Seat: "GA", Price: 10, TicketIDs: availableTicketGAIDs},
-			{Seat: "Balcony", Price: 20, TicketIDs: []int32{availableTicketBalconyID}},
+			{
+				Seat:      "GA",
+				Price:     pkgApi.Money{Amount: 1000, Currency: "USD"},
+				TicketIDs: availableTicketGAIDs,
+			},
+			{
+				Seat:      "Balcony",
+				Price:     pkgApi.Money{Amount: 2000, Currency: "USD"},
+				TicketIDs: []int32{availableTicketBalconyID},
+			},
 		},
 	}
 
 	actual := pkgApi.GetAvailableTicketsAggregateResponse{}
 	json.NewDecoder(response.Body).Decode(&actual)
 
+	assert.False(t, actual.SoldOut)
 	assert.ElementsMatch(t, expected.Available, actual.Available)
 }
 
@@ -1023,24 +1613,171 @@ func (suite *HandlersTestSuite) TestGetTicketsWhenEventDoesntExistOrDeleted() {
 	}
 }
 
-// Test placing a purchase hold on a ticket.
-func (suite *HandlersTestSuite) TestHoldTicket() {
+// Test creating a general admission tier for an event.
+func (suite *HandlersTestSuite) TestCreateGATier() {
 	t := suite.T()
 	ctx := context.Background()
-
-	userID := "123"
-	header := fmt.Sprintf("x-user-id: %s", userID)
-
-	WriteTicket(t, ctx, suite.Conn)
-	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
-	defer DeleteTicket(t, ctx, suite.Conn)
+	defer DeleteGATiers(t, ctx, suite.Conn)
 
 	api := CreateAPIForTickets(suite)
 
-	response := api.Post(fmt.Sprintf("/tickets/%d/hold", ticketID), header)
-	require.Equal(t, http.StatusNoContent, response.Code)
+	requestBody := map[string]any{
+		"name":     "Floor",
+		"price":    map[string]any{"amount": 1000, "currency": "USD"},
+		"capacity": 100,
+	}
+	response := api.Post(fmt.Sprintf("/events/%d/ga-tiers", readEventID), requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
 
-	actual, err := suite.RedisConn.Get(ctx, ticketIDString).Result()
+	actual := pkgApi.CreateGATierResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.NotZero(t, actual.ID)
+
+	var capacity, sold int32
+	err := suite.Conn.QueryRow(
+		ctx,
+		"select capacity, sold from ga_tiers where id = $1 and event_id = $2",
+		actual.ID,
+		readEventID,
+	).Scan(&capacity, &sold)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading general admission tier: %s", err))
+	}
+	assert.Equal(t, int32(100), capacity)
+	assert.Zero(t, sold)
+
+	// Tiers are uniquely named for their event.
+	response = api.Post(fmt.Sprintf("/events/%d/ga-tiers", readEventID), requestBody)
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+// Test creating a general admission tier for a non-existent or deleted event.
+func (suite *HandlersTestSuite) TestCreateGATierWhenEventDoesntExistOrDeleted() {
+	t := suite.T()
+	api := CreateAPIForTickets(suite)
+
+	requestBody := map[string]any{
+		"name":     "Floor",
+		"price":    map[string]any{"amount": 1000, "currency": "USD"},
+		"capacity": 100,
+	}
+	for _, id := range []int32{missingEventID, deletedEventID} {
+		response := api.Post(fmt.Sprintf("/events/%d/ga-tiers", id), requestBody)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	}
+}
+
+// Test holding and purchasing a quantity of a general admission tier.
+func (suite *HandlersTestSuite) TestHoldGATier() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteGATier(t, ctx, suite.Conn, 3)
+	defer DeleteGATiers(t, ctx, suite.Conn)
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+
+	response := api.Post(fmt.Sprintf("/ga-tiers/%d/hold", gaTierID), header, map[string]any{"quantity": 2})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	hold := pkgApi.GATierHoldResponse{}
+	json.NewDecoder(response.Body).Decode(&hold)
+	require.NotEmpty(t, hold.HoldToken)
+	assert.Equal(t, gaTierID, hold.GATierID)
+	assert.Equal(t, int32(2), hold.Quantity)
+
+	// The held quantity is no longer available.
+	response = api.Get(fmt.Sprintf("/events/%d/tickets", readEventID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	inventory := pkgApi.GetAvailableTicketsAggregateResponse{}
+	json.NewDecoder(response.Body).Decode(&inventory)
+	require.Len(t, inventory.GeneralAdmission, 1)
+	assert.Equal(t, int32(1), inventory.GeneralAdmission[0].Remaining)
+
+	// What's left of the tier can't cover another hold of the same quantity.
+	response = api.Post(
+		fmt.Sprintf("/ga-tiers/%d/hold", gaTierID),
+		fmt.Sprintf("x-user-id: %s", otherUserIDString),
+		map[string]any{"quantity": 2},
+	)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	requestBody := map[string]any{
+		"hold_token": hold.HoldToken,
+		"card": map[string]any{
+			"name":             "Test user",
+			"address":          "11 Front Street",
+			"number":           "4242424242424242",
+			"expiration_month": 1,
+			"expiration_year":  99,
+			"cvc":              "123",
+		},
+	}
+	response = api.Post("/tickets/purchase", header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	purchase := pkgApi.PaymentResponse{}
+	json.NewDecoder(response.Body).Decode(&purchase)
+	assert.True(t, purchase.Success)
+
+	// Tickets are issued for the purchased quantity.
+	var sold int32
+	err := suite.Conn.QueryRow(ctx, "select sold from ga_tiers where id = $1", gaTierID).Scan(&sold)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading general admission tier: %s", err))
+	}
+	assert.Equal(t, int32(2), sold)
+
+	var countPurchased int
+	err = suite.Conn.QueryRow(
+		ctx,
+		"select count(*) from tickets where ga_tier_id = $1 and purchaser_id = $2",
+		gaTierID,
+		userID,
+	).Scan(&countPurchased)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading tickets: %s", err))
+	}
+	assert.Equal(t, 2, countPurchased)
+}
+
+// Test placing a purchase hold on a general admission tier with a missing hold
+// id, or on a non-existent tier.
+func (suite *HandlersTestSuite) TestHoldGATierWhenInvalid() {
+	t := suite.T()
+	api := CreateAPIForTickets(suite)
+
+	requestBody := map[string]any{"quantity": 1}
+
+	response := api.Post("/ga-tiers/999/hold", requestBody)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = api.Post("/ga-tiers/999/hold", "x-user-id: 123", requestBody)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// Test placing a purchase hold on a ticket.
+func (suite *HandlersTestSuite) TestHoldTicket() {
+	t := suite.T()
+	ctx := context.Background()
+
+	userID := "123"
+	header := fmt.Sprintf("x-user-id: %s", userID)
+
+	WriteTicket(t, ctx, suite.Conn)
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForTickets(suite)
+
+	response := api.Post(fmt.Sprintf("/tickets/%d/hold", ticketID), header)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	actual, err := suite.RedisConn.Get(ctx, ticketIDString).Result()
 	if err != nil {
 		assert.FailNow(t, fmt.Sprintf("Error reading ticket hold: %s", err))
 	}
@@ -1096,60 +1833,278 @@ func (suite *HandlersTestSuite) TestHoldTicketWhenTicketAlreadyHeld() {
 	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
 }
 
-// Test purchasing a ticket that already has a purchase hold on it.
-func (suite *HandlersTestSuite) TestPurchaseTicket() {
+// Test releasing a purchase hold on a ticket.
+func (suite *HandlersTestSuite) TestReleaseTicketHold() {
 	t := suite.T()
+	ctx := context.Background()
+
+	userID := "123"
+	header := fmt.Sprintf("x-user-id: %s", userID)
+
+	err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+	}
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+
+	response := api.Delete(fmt.Sprintf("/tickets/%d/hold", ticketID), header)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	exists, err := suite.RedisConn.Exists(ctx, ticketIDString).Result()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket hold: %s", err))
+	}
+	assert.Equal(t, int64(0), exists)
+}
 
+// Test releasing a purchase hold on a ticket that is held by someone else.
+func (suite *HandlersTestSuite) TestReleaseTicketHoldWhenWrongHoldID() {
+	t := suite.T()
 	ctx := context.Background()
-	header := fmt.Sprintf("x-user-id: %s", userIDString)
 
-	// Add a ticket to be purchased set a purchase hold on it.
+	header := "x-user-id: 123"
+
+	err := suite.RedisConn.Set(ctx, ticketIDString, "111", 0).Err()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+	}
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+
+	response := api.Delete(fmt.Sprintf("/tickets/%d/hold", ticketID), header)
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	actual, err := suite.RedisConn.Get(ctx, ticketIDString).Result()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket hold: %s", err))
+	}
+	assert.Equal(t, "111", actual)
+}
+
+// Test extending a purchase hold on a ticket, up to the maximum number of
+// extensions.
+func (suite *HandlersTestSuite) TestExtendTicketHold() {
+	t := suite.T()
+	ctx := context.Background()
+
+	userID := "123"
+	header := fmt.Sprintf("x-user-id: %s", userID)
+
+	err := suite.RedisConn.Set(ctx, ticketIDString, userID, time.Second).Err()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+	}
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+	path := fmt.Sprintf("/tickets/%d/hold/extend", ticketID)
+
+	for range ticketHoldMaxExtensions {
+		response := api.Post(path, header)
+		require.Equal(t, http.StatusOK, response.Code)
+	}
+
+	ttl, err := suite.RedisConn.TTL(ctx, ticketIDString).Result()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading expiration time: %s", err))
+	}
+	assert.Greater(t, ttl, time.Second)
+
+	response := api.Post(path, header)
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test placing a purchase hold on several tickets at once.
+func (suite *HandlersTestSuite) TestHoldTickets() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := "x-user-id: 123"
+
+	WriteTicket(t, ctx, suite.Conn)
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForTickets(suite)
+
+	requestBody := map[string]any{"ticket_ids": []int32{ticketID}}
+	response := api.Post("/tickets/hold", header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	hold := pkgApi.TicketsHoldResponse{}
+	json.NewDecoder(response.Body).Decode(&hold)
+	require.NotEmpty(t, hold.HoldToken)
+	assert.Equal(t, []int32{ticketID}, hold.TicketIDs)
+
+	actual, err := suite.RedisConn.Get(ctx, ticketIDString).Result()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket hold: %s", err))
+	}
+	assert.Equal(t, hold.HoldToken, actual)
+}
+
+// Test placing a purchase hold on several tickets when one is already held.
+func (suite *HandlersTestSuite) TestHoldTicketsWhenTicketAlreadyHeld() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := "x-user-id: 123"
+
 	setup := func() {
 		WriteTicket(t, ctx, suite.Conn)
 
-		err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
+		_, err := suite.RedisConn.Set(ctx, ticketIDString, "111", 0).Result()
 		if err != nil {
-			assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+			assert.FailNow(t, fmt.Sprintf("Error writing ticket hold: %s", err))
 		}
 	}
 
 	setup()
+	defer DeleteTicket(t, ctx, suite.Conn)
 	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+
+	requestBody := map[string]any{"ticket_ids": []int32{ticketID}}
+	response := api.Post("/tickets/hold", header, requestBody)
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test placing purchase holds on more of an event's tickets than its limit on
+// tickets per user, which counts the holder's active holds.
+func (suite *HandlersTestSuite) TestHoldTicketsWhenPurchaseLimitExceeded() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := "x-user-id: 123"
+
+	WriteTicket(t, ctx, suite.Conn)
+	WriteOtherTicket(t, ctx, suite.Conn)
+	SetMaxTicketsPerUser(t, ctx, suite.Conn, 1)
 	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetMaxTicketsPerUser(t, ctx, suite.Conn, 0)
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
 
 	api := CreateAPIForTickets(suite)
-	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header)
-	require.Equal(t, http.StatusOK, response.Code)
 
-	actual := pkgApi.PaymentResponse{}
-	json.NewDecoder(response.Body).Decode(&actual)
-	assert.Equal(t, true, actual.Success)
+	requestBody := map[string]any{"ticket_ids": []int32{ticketID, otherTicketID}}
+	response := api.Post("/tickets/hold", header, requestBody)
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Contains(t, response.Body.String(), services.ErrPurchaseLimitExceeded.Error())
+
+	// Neither ticket is held.
+	exists, err := suite.RedisConn.Exists(ctx, ticketIDString, otherTicketIDString).Result()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket hold: %s", err))
+	}
+	assert.Equal(t, int64(0), exists)
+
+	response = api.Post(fmt.Sprintf("/tickets/%d/hold", ticketID), header)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	// The active hold counts against the limit.
+	response = api.Post(fmt.Sprintf("/tickets/%d/hold", otherTicketID), header)
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Contains(t, response.Body.String(), services.ErrPurchaseLimitExceeded.Error())
+
+	// Whereas other holders have limits of their own.
+	response = api.Post(fmt.Sprintf("/tickets/%d/hold", otherTicketID), "x-user-id: 111")
+	assert.Equal(t, http.StatusNoContent, response.Code)
 }
 
-// Test attempting to purchase a ticket that doesn't have a purchase hold on it.
-func (suite *HandlersTestSuite) TestPurchaseTicketWhenTicketIsntHeld() {
+// Test extending and releasing a purchase hold on several tickets, which acts
+// on all of the hold's tickets and its reservation against the event's limit
+// on tickets per user at once.
+func (suite *HandlersTestSuite) TestExtendAndReleaseTicketsHold() {
 	t := suite.T()
-	header := "x-user-id: 111"
-	ticketID := int32(999)
+	ctx := context.Background()
+
+	header := "x-user-id: 123"
+
+	WriteTicket(t, ctx, suite.Conn)
+	WriteOtherTicket(t, ctx, suite.Conn)
+	SetMaxTicketsPerUser(t, ctx, suite.Conn, 4)
+	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetMaxTicketsPerUser(t, ctx, suite.Conn, 0)
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
 
 	api := CreateAPIForTickets(suite)
-	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header)
+
+	requestBody := map[string]any{"ticket_ids": []int32{ticketID, otherTicketID}}
+	response := api.Post("/tickets/hold", header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	hold := pkgApi.TicketsHoldResponse{}
+	json.NewDecoder(response.Body).Decode(&hold)
+	require.NotEmpty(t, hold.HoldToken)
+
+	keys := []string{ticketIDString, otherTicketIDString, fmt.Sprintf("hold:%s", hold.HoldToken)}
+	limitKey := fmt.Sprintf("limit:%d:123", readEventID)
+
+	// Shorten the hold, so that extending it can be seen on each of its keys.
+	for _, key := range keys {
+		if err := suite.RedisConn.PExpire(ctx, key, time.Second).Err(); err != nil {
+			assert.FailNow(t, fmt.Sprintf("Error shortening ticket hold: %s", err))
+		}
+	}
+
+	response = api.Post(fmt.Sprintf("/holds/%s/extend", hold.HoldToken), header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	for _, key := range keys {
+		ttl, err := suite.RedisConn.TTL(ctx, key).Result()
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Error reading expiration time: %s", err))
+		}
+		assert.Greater(t, ttl, time.Second, key)
+	}
+
+	// The hold's tickets can't be released or extended on their own.
+	response = api.Delete(fmt.Sprintf("/tickets/%d/hold", ticketID), header)
+	require.Equal(t, http.StatusConflict, response.Code)
+	assert.Contains(t, response.Body.String(), "/holds/{token}")
+
+	response = api.Post(fmt.Sprintf("/tickets/%d/hold/extend", ticketID), header)
+	require.Equal(t, http.StatusConflict, response.Code)
+
+	// Another holder can't release the hold.
+	response = api.Delete(fmt.Sprintf("/holds/%s", hold.HoldToken), "x-user-id: 111")
 	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = api.Delete(fmt.Sprintf("/holds/%s", hold.HoldToken), header)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	exists, err := suite.RedisConn.Exists(ctx, keys...).Result()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket hold: %s", err))
+	}
+	assert.Equal(t, int64(0), exists)
+
+	reserved, err := suite.RedisConn.Get(ctx, fmt.Sprintf("%s:reserved", limitKey)).Int()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading reservations: %s", err))
+	}
+	assert.Zero(t, reserved)
+
+	response = api.Delete(fmt.Sprintf("/holds/%s", hold.HoldToken), header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
 }
 
-// Test attempting to purchase a ticket that has a hold placed on it, but the
-// hold id doesn't match.
-func (suite *HandlersTestSuite) TestPurchaseTicketWhenWrongHoldID() {
+// Test purchasing a ticket that already has a purchase hold on it.
+func (suite *HandlersTestSuite) TestPurchaseTicket() {
 	t := suite.T()
 
 	ctx := context.Background()
-	header := "x-user-id: 123"
-	ticketID := int32(1)
-	ticketIDString := "1"
-	actualHoldID := "111"
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
 
+	// Add a ticket to be purchased set a purchase hold on it.
 	setup := func() {
-		err := suite.RedisConn.Set(ctx, ticketIDString, actualHoldID, 0).Err()
+		WriteTicket(t, ctx, suite.Conn)
+
+		err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
 		if err != nil {
 			assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
 		}
@@ -1157,10 +2112,1546 @@ func (suite *HandlersTestSuite) TestPurchaseTicketWhenWrongHoldID() {
 
 	setup()
 	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+	defer DeleteTicket(t, ctx, suite.Conn)
 
 	api := CreateAPIForTickets(suite)
-	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header)
-	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header, MakePurchaseTicketRequest(testCardNumber))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.PaymentResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, true, actual.Success)
+	assert.NotZero(t, actual.OrderID)
+
+	// Check that the purchase hold has been removed.
+	exists, err := suite.RedisConn.Exists(ctx, ticketIDString).Result()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket hold: %s", err))
+	}
+	assert.Equal(t, int64(0), exists)
+
+	// Check that an order was recorded for the purchase.
+	ordersAPI := CreateAPIForOrders(suite)
+	response = ordersAPI.Get(fmt.Sprintf("/orders/%d", actual.OrderID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	order := pkgApi.GetOrderResponse{}
+	json.NewDecoder(response.Body).Decode(&order)
+	assert.Equal(t, userID, order.PurchaserID)
+	assert.Equal(t, "completed", order.Status)
+	assert.Len(t, order.Items, 1)
+	assert.Equal(t, ticketID, order.Items[0].TicketID)
+}
+
+// Test purchasing a held ticket with a card that the payment processor
+// declines.
+func (suite *HandlersTestSuite) TestPurchaseTicketWhenCardDeclined() {
+	t := suite.T()
+
+	ctx := context.Background()
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+	}
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+	response := api.Post(
+		fmt.Sprintf("/tickets/%d/purchase", ticketID),
+		header,
+		MakePurchaseTicketRequest(declinedCardNumber),
+	)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.PaymentResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, pkgApi.PaymentResponse{
+		Success:       false,
+		DeclineReason: payment.DeclineReasonCardDeclined,
+	}, actual)
+
+	// The declined payment is recorded, and the ticket isn't purchased.
+	var status string
+	var declineReason string
+	err = suite.Conn.QueryRow(
+		ctx,
+		"select status, decline_reason from payments where purchaser_id = $1 order by id desc limit 1",
+		userID,
+	).Scan(&status, &declineReason)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading payment: %s", err))
+	}
+	assert.Equal(t, "failed", status)
+	assert.Equal(t, payment.DeclineReasonCardDeclined, declineReason)
+
+	var purchaserID pgtype.Int4
+	err = suite.Conn.QueryRow(ctx, "select purchaser_id from tickets where id = $1", ticketID).Scan(&purchaserID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket: %s", err))
+	}
+	assert.False(t, purchaserID.Valid)
+}
+
+// Test attempting to purchase a ticket that doesn't have a purchase hold on it.
+func (suite *HandlersTestSuite) TestPurchaseTicketWhenTicketIsntHeld() {
+	t := suite.T()
+	header := "x-user-id: 111"
+	ticketID := int32(999)
+
+	api := CreateAPIForTickets(suite)
+	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header, MakePurchaseTicketRequest(testCardNumber))
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test attempting to purchase a ticket that has a hold placed on it, but the
+// hold id doesn't match.
+func (suite *HandlersTestSuite) TestPurchaseTicketWhenWrongHoldID() {
+	t := suite.T()
+
+	ctx := context.Background()
+	header := "x-user-id: 123"
+	ticketID := int32(1)
+	ticketIDString := "1"
+	actualHoldID := "111"
+
+	setup := func() {
+		err := suite.RedisConn.Set(ctx, ticketIDString, actualHoldID, 0).Err()
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+		}
+	}
+
+	setup()
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header, MakePurchaseTicketRequest(testCardNumber))
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test purchasing a held ticket when the purchaser has already purchased as
+// many of the event's tickets as its limit on tickets per user.
+func (suite *HandlersTestSuite) TestPurchaseTicketWhenPurchaseLimitExceeded() {
+	t := suite.T()
+
+	ctx := context.Background()
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	WriteOtherTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, otherTicketID, userID)
+	SetMaxTicketsPerUser(t, ctx, suite.Conn, 1)
+	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetMaxTicketsPerUser(t, ctx, suite.Conn, 0)
+
+	// The hold is placed directly, as placing it through the API would
+	// already be refused.
+	err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+	}
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header, MakePurchaseTicketRequest(testCardNumber))
+	require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Contains(t, response.Body.String(), services.ErrPurchaseLimitExceeded.Error())
+
+	var purchaserID pgtype.Int4
+	err = suite.Conn.QueryRow(ctx, "select purchaser_id from tickets where id = $1", ticketID).Scan(&purchaserID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket: %s", err))
+	}
+	assert.False(t, purchaserID.Valid)
+}
+
+// Test quoting the itemized price of tickets, including fees.
+func (suite *HandlersTestSuite) TestQuoteTickets() {
+	t := suite.T()
+
+	ctx := context.Background()
+	WriteTicket(t, ctx, suite.Conn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	pricingAPI := CreateAPIForPricing(suite)
+	response := pricingAPI.Put(fmt.Sprintf("/events/%d/fees", readEventID), map[string]any{
+		"service_fee_rate": 1000,
+		"service_fee_flat": 50,
+		"facility_fee":     300,
+	})
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	api := CreateAPIForTickets(suite)
+	response = api.Post("/tickets/quote", map[string]any{"ticket_ids": []int32{ticketID}})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.QuoteResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Len(t, actual.Items, 1)
+	assert.Equal(t, pkgApi.Money{Amount: 2000, Currency: "USD"}, actual.FaceValue)
+	assert.Equal(t, pkgApi.Money{Amount: 250, Currency: "USD"}, actual.ServiceFees)
+	assert.Equal(t, pkgApi.Money{Amount: 300, Currency: "USD"}, actual.FacilityFee)
+	assert.Equal(t, pkgApi.Money{Amount: 2550, Currency: "USD"}, actual.Total)
+}
+
+// Test quoting the price of tickets with a promo code, and with a non-existent
+// one.
+func (suite *HandlersTestSuite) TestQuoteTicketsWithPromoCode() {
+	t := suite.T()
+
+	ctx := context.Background()
+	WriteTicket(t, ctx, suite.Conn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	promoCodesAPI := CreateAPIForPromoCodes(suite)
+	response := promoCodesAPI.Post("/promo-codes", map[string]any{
+		"code":          "quote25",
+		"discount_type": "percentage",
+		"percent_off":   2500,
+		"event_id":      readEventID,
+	})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	api := CreateAPIForTickets(suite)
+	response = api.Post("/tickets/quote", map[string]any{
+		"ticket_ids": []int32{ticketID},
+		"promo_code": "QUOTE25",
+	})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.QuoteResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, pkgApi.Money{Amount: 2000, Currency: "USD"}, actual.FaceValue)
+	assert.Equal(t, pkgApi.Money{Amount: 500, Currency: "USD"}, actual.Discount)
+	assert.Equal(t, pkgApi.Money{Amount: 500, Currency: "USD"}, actual.Items[0].Discount)
+
+	response = api.Post("/tickets/quote", map[string]any{
+		"ticket_ids": []int32{ticketID},
+		"promo_code": "NOSUCHCODE",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test refunding a purchased ticket and re-releasing it.
+func (suite *HandlersTestSuite) TestRefundTicket() {
+	t := suite.T()
+
+	ctx := context.Background()
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	setup := func() {
+		WriteTicket(t, ctx, suite.Conn)
+
+		err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+		}
+	}
+
+	setup()
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	pricingAPI := CreateAPIForPricing(suite)
+	response := pricingAPI.Put(fmt.Sprintf("/events/%d/fees", readEventID), map[string]any{
+		"service_fee_rate": 1000,
+		"service_fee_flat": 50,
+		"facility_fee":     300,
+	})
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	api := CreateAPIForTickets(suite)
+	response = api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header, MakePurchaseTicketRequest(testCardNumber))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = api.Post(fmt.Sprintf("/tickets/%d/refund?rerelease=true", ticketID), header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// The ticket is the only one of its order, so the facility fee is refunded
+	// along with it.
+	actual := pkgApi.RefundResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, ticketID, actual.TicketID)
+	assert.Equal(t, pkgApi.Money{Amount: 2550, Currency: "USD"}, actual.Amount)
+	assert.Equal(t, "completed", actual.Status)
+	assert.True(t, actual.Rereleased)
+
+	// Check that the payment has been refunded in full.
+	var paymentStatus string
+	row := suite.Conn.QueryRow(
+		ctx,
+		`select payments.status
+        from refunds
+        inner join payments on refunds.payment_id = payments.id
+        where refunds.id = $1`,
+		actual.ID,
+	)
+	if err := row.Scan(&paymentStatus); err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to read payment: %s", err))
+	}
+	assert.Equal(t, "refunded", paymentStatus)
+
+	// Check that the ticket is available again.
+	var purchaserID pgtype.Int4
+	row = suite.Conn.QueryRow(ctx, "select purchaser_id from tickets where id = $1", ticketID)
+	if err := row.Scan(&purchaserID); err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to read ticket: %s", err))
+	}
+	assert.False(t, purchaserID.Valid)
+
+	// The ticket can't be refunded twice.
+	response = api.Post(fmt.Sprintf("/tickets/%d/refund", ticketID), header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// Test that a facility fee whose refund failed is refunded with the order's
+// next refund.
+func (suite *HandlersTestSuite) TestRefundTicketAfterFacilityFeeRefundFailed() {
+	t := suite.T()
+
+	ctx := context.Background()
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	setup := func() {
+		WriteTicket(t, ctx, suite.Conn)
+		WriteOtherTicket(t, ctx, suite.Conn)
+
+		err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+		}
+	}
+
+	setup()
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	pricingAPI := CreateAPIForPricing(suite)
+	response := pricingAPI.Put(fmt.Sprintf("/events/%d/fees", readEventID), map[string]any{
+		"service_fee_rate": 1000,
+		"service_fee_flat": 50,
+		"facility_fee":     300,
+	})
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	api := CreateAPIForTickets(suite)
+	response = api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header, MakePurchaseTicketRequest(testCardNumber))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// Record an earlier refund of the facility fee, against the same payment,
+	// that the payment processor wouldn't make.
+	_, err := suite.Conn.Exec(
+		ctx,
+		`insert into refunds (payment_id, ticket_id, amount, facility_fee, status)
+        select orders.payment_id, $2, 300, 300, 'failed'
+        from order_items
+        inner join orders on order_items.order_id = orders.id
+        where order_items.ticket_id = $1`,
+		ticketID,
+		otherTicketID,
+	)
+	require.Nil(t, err)
+
+	response = api.Post(fmt.Sprintf("/tickets/%d/refund", ticketID), header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// The failed refund doesn't count, so the facility fee is refunded along
+	// with the ticket.
+	actual := pkgApi.RefundResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, pkgApi.Money{Amount: 2550, Currency: "USD"}, actual.Amount)
+	assert.Equal(t, "completed", actual.Status)
+}
+
+// Test refunding a ticket that was purchased by another user.
+func (suite *HandlersTestSuite) TestRefundTicketWhenNotOwner() {
+	t := suite.T()
+
+	ctx := context.Background()
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	setup := func() {
+		WriteTicket(t, ctx, suite.Conn)
+
+		err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+		}
+	}
+
+	setup()
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForTickets(suite)
+	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header, MakePurchaseTicketRequest(testCardNumber))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = api.Post(fmt.Sprintf("/tickets/%d/refund", ticketID), "x-user-id: 999")
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
+
+// Test refunding a ticket that was transferred after it was purchased.
+func (suite *HandlersTestSuite) TestRefundTicketWhenTransferred() {
+	t := suite.T()
+
+	ctx := context.Background()
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	setup := func() {
+		WriteTicket(t, ctx, suite.Conn)
+
+		err := suite.RedisConn.Set(ctx, ticketIDString, userID, 0).Err()
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("Unable to write test data on setup: %s", err))
+		}
+	}
+
+	setup()
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForTickets(suite)
+	response := api.Post(fmt.Sprintf("/tickets/%d/purchase", ticketID), header, MakePurchaseTicketRequest(testCardNumber))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	countUpdated, err := db.New(suite.Conn).TransferTicket(ctx, db.TransferTicketParams{
+		TicketID:    ticketID,
+		SenderID:    userID,
+		RecipientID: repos.MapPurchaserID(otherUserID),
+	})
+	require.Nil(t, err)
+	require.Equal(t, int64(1), countUpdated)
+
+	// Neither the recipient nor the sender can refund the ticket.
+	response = api.Post(fmt.Sprintf("/tickets/%d/refund", ticketID), otherHeader)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = api.Post(fmt.Sprintf("/tickets/%d/refund", ticketID), header)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	var countRefunds int
+	row := suite.Conn.QueryRow(ctx, "select count(*) from refunds where ticket_id = $1", ticketID)
+	if err := row.Scan(&countRefunds); err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to read refunds: %s", err))
+	}
+	assert.Zero(t, countRefunds)
+}
+
+// Test listing a purchased ticket for resale, and another user holding and
+// purchasing it.
+func (suite *HandlersTestSuite) TestListTicketForResale() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	SetEventStart(t, ctx, suite.Conn, readEventID, time.Now().Add(24*time.Hour))
+	// Tickets can be resold for up to 150% of their face value.
+	SetResaleMaxRate(t, ctx, suite.Conn, 15000)
+	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetEventStart(t, ctx, suite.Conn, readEventID, readEventStartsAt)
+	defer SetResaleMaxRate(t, ctx, suite.Conn, 0)
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+	path := fmt.Sprintf("/tickets/%d/resale", ticketID)
+
+	response := api.Post(path, header, map[string]any{"price": map[string]any{"amount": 4000, "currency": "USD"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	requestBody := map[string]any{"price": map[string]any{"amount": 2500, "currency": "USD"}}
+	response = api.Post(path, otherHeader, requestBody)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = api.Post(path, header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	listing := pkgApi.ResaleListingResponse{}
+	json.NewDecoder(response.Body).Decode(&listing)
+	assert.NotZero(t, listing.ID)
+	assert.Equal(t, ticketID, listing.TicketID)
+	assert.Equal(t, userID, listing.SellerID)
+	assert.Equal(t, pkgApi.Money{Amount: 2500, Currency: "USD"}, listing.Price)
+	assert.Equal(t, "active", listing.Status)
+
+	// A ticket can only have one active listing.
+	response = api.Post(path, header, requestBody)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = api.Get(fmt.Sprintf("/users/%d/resale-listings", userID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	listings := pkgApi.ResaleListingsResponse{}
+	json.NewDecoder(response.Body).Decode(&listings)
+	require.Len(t, listings.Listings, 1)
+	assert.Equal(t, listing.ID, listings.Listings[0].ID)
+
+	// The listing is available alongside the event's own inventory.
+	response = api.Get(fmt.Sprintf("/events/%d/tickets", readEventID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	inventory := pkgApi.GetAvailableTicketsAggregateResponse{}
+	json.NewDecoder(response.Body).Decode(&inventory)
+	require.Len(t, inventory.Resale, 1)
+	assert.Equal(t, listing.ID, inventory.Resale[0].ID)
+	assert.Equal(t, pkgApi.Money{Amount: 2000, Currency: "USD"}, inventory.Resale[0].FaceValue)
+
+	// The seller can't hold their own listing.
+	holdPath := fmt.Sprintf("/resale-listings/%d/hold", listing.ID)
+	response = api.Post(holdPath, header)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = api.Post(holdPath, otherHeader)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	hold := pkgApi.TicketsHoldResponse{}
+	json.NewDecoder(response.Body).Decode(&hold)
+	require.NotEmpty(t, hold.HoldToken)
+
+	purchaseBody := map[string]any{
+		"hold_token": hold.HoldToken,
+		"card": map[string]any{
+			"name":             "Other test user",
+			"address":          "12 Front Street",
+			"number":           "4242424242424242",
+			"expiration_month": 1,
+			"expiration_year":  99,
+			"cvc":              "123",
+		},
+	}
+	response = api.Post("/tickets/purchase", otherHeader, purchaseBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	purchase := pkgApi.PaymentResponse{}
+	json.NewDecoder(response.Body).Decode(&purchase)
+	assert.True(t, purchase.Success)
+
+	// The ticket has moved to the buyer, and the seller is owed a payout.
+	var purchaserID int32
+	err := suite.Conn.QueryRow(ctx, "select purchaser_id from tickets where id = $1", ticketID).Scan(&purchaserID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket: %s", err))
+	}
+	assert.Equal(t, otherUserID, purchaserID)
+
+	var payoutSellerID int32
+	var payoutAmount int64
+	err = suite.Conn.QueryRow(
+		ctx,
+		"select seller_id, amount from resale_payouts where listing_id = $1",
+		listing.ID,
+	).Scan(&payoutSellerID, &payoutAmount)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading resale payout: %s", err))
+	}
+	assert.Equal(t, userID, payoutSellerID)
+	assert.Equal(t, int64(2500), payoutAmount)
+
+	response = api.Get(fmt.Sprintf("/resale-listings/%d", listing.ID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.ResaleListingResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, "sold", actual.Status)
+
+	response = api.Post(fmt.Sprintf("/resale-listings/%d/cancel", listing.ID), header)
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+// Test cancelling a resale listing, which only its seller can do.
+func (suite *HandlersTestSuite) TestCancelResaleListing() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	SetEventStart(t, ctx, suite.Conn, readEventID, time.Now().Add(24*time.Hour))
+	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetEventStart(t, ctx, suite.Conn, readEventID, readEventStartsAt)
+
+	api := CreateAPIForTickets(suite)
+
+	requestBody := map[string]any{"price": map[string]any{"amount": 2500, "currency": "USD"}}
+	response := api.Post(fmt.Sprintf("/tickets/%d/resale", ticketID), header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	listing := pkgApi.ResaleListingResponse{}
+	json.NewDecoder(response.Body).Decode(&listing)
+
+	path := fmt.Sprintf("/resale-listings/%d/cancel", listing.ID)
+	response = api.Post(path, otherHeader)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = api.Post(path, header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	cancelled := pkgApi.ResaleListingResponse{}
+	json.NewDecoder(response.Body).Decode(&cancelled)
+	assert.Equal(t, "cancelled", cancelled.Status)
+
+	// A cancelled listing can't be held.
+	response = api.Post(fmt.Sprintf("/resale-listings/%d/hold", listing.ID), otherHeader)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = api.Post(path, header)
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+// Test holding and purchasing a resale listing, created before its event
+// started, once the event has started.
+func (suite *HandlersTestSuite) TestHoldResaleListingWhenEventStarted() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	SetEventStart(t, ctx, suite.Conn, readEventID, time.Now().Add(24*time.Hour))
+	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetEventStart(t, ctx, suite.Conn, readEventID, readEventStartsAt)
+	defer TeardownTicketHolds(t, ctx, suite.RedisConn)
+
+	api := CreateAPIForTickets(suite)
+
+	requestBody := map[string]any{"price": map[string]any{"amount": 2500, "currency": "USD"}}
+	response := api.Post(fmt.Sprintf("/tickets/%d/resale", ticketID), header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	listing := pkgApi.ResaleListingResponse{}
+	json.NewDecoder(response.Body).Decode(&listing)
+
+	holdPath := fmt.Sprintf("/resale-listings/%d/hold", listing.ID)
+	response = api.Post(holdPath, otherHeader)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	hold := pkgApi.TicketsHoldResponse{}
+	json.NewDecoder(response.Body).Decode(&hold)
+
+	SetEventStart(t, ctx, suite.Conn, readEventID, time.Now().Add(-time.Minute))
+
+	// The listing can't be purchased, even though it was held before the
+	// event started, or held again.
+	purchaseBody := map[string]any{
+		"hold_token": hold.HoldToken,
+		"card": map[string]any{
+			"name":             "Other test user",
+			"address":          "12 Front Street",
+			"number":           testCardNumber,
+			"expiration_month": 1,
+			"expiration_year":  99,
+			"cvc":              "123",
+		},
+	}
+	response = api.Post("/tickets/purchase", otherHeader, purchaseBody)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = api.Delete(fmt.Sprintf("/holds/%s", hold.HoldToken), otherHeader)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	response = api.Post(holdPath, otherHeader)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	var purchaserID int32
+	err := suite.Conn.QueryRow(ctx, "select purchaser_id from tickets where id = $1", ticketID).Scan(&purchaserID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket: %s", err))
+	}
+	assert.Equal(t, userID, purchaserID)
+}
+
+// Test listing a ticket for resale once its event has started, and listing a
+// non-existent ticket, along with acting on a non-existent listing.
+func (suite *HandlersTestSuite) TestListTicketForResaleWhenInvalid() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForTickets(suite)
+
+	// The test event has already started.
+	requestBody := map[string]any{"price": map[string]any{"amount": 2500, "currency": "USD"}}
+	response := api.Post(fmt.Sprintf("/tickets/%d/resale", ticketID), header, requestBody)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = api.Post("/tickets/999/resale", header, requestBody)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Get("/resale-listings/999")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Post("/resale-listings/999/cancel", header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Post("/resale-listings/999/hold", header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Post("/resale-listings/999/hold")
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+// Test getting an order that doesn't exist.
+func (suite *HandlersTestSuite) TestGetOrderWhenDoesntExist() {
+	t := suite.T()
+	api := CreateAPIForOrders(suite)
+	response := api.Get("/orders/999")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// Test that deleting an event cancels it.
+func (suite *HandlersTestSuite) TestCreatePromoCode() {
+	t := suite.T()
+	api := CreateAPIForPromoCodes(suite)
+
+	data := map[string]any{
+		"code":            "summer-10",
+		"discount_type":   "fixed",
+		"amount_off":      map[string]any{"amount": 1000, "currency": "USD"},
+		"max_redemptions": 100,
+	}
+
+	response := api.Post("/promo-codes", data)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	created := pkgApi.CreatePromoCodeResponse{}
+	json.NewDecoder(response.Body).Decode(&created)
+	require.NotEmpty(t, created.ID)
+
+	response = api.Get(fmt.Sprintf("/promo-codes/%d", created.ID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.GetPromoCodeResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, pkgApi.GetPromoCodeResponse{
+		ID:             created.ID,
+		Code:           "SUMMER-10",
+		DiscountType:   "fixed",
+		AmountOff:      &pkgApi.Money{Amount: 1000, Currency: "USD"},
+		MaxRedemptions: 100,
+	}, actual)
+
+	// Codes are matched regardless of case.
+	data["code"] = "Summer-10"
+	response = api.Post("/promo-codes", data)
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+func (suite *HandlersTestSuite) TestCreatePromoCodeWhenInvalid() {
+	t := suite.T()
+	api := CreateAPIForPromoCodes(suite)
+
+	// A fixed discount requires an amount off.
+	response := api.Post("/promo-codes", map[string]any{"code": "NOAMOUNT", "discount_type": "fixed"})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+func (suite *HandlersTestSuite) TestDeletePromoCode() {
+	t := suite.T()
+	api := CreateAPIForPromoCodes(suite)
+
+	response := api.Post("/promo-codes", map[string]any{
+		"code":          "DELETEME",
+		"discount_type": "percentage",
+		"percent_off":   1000,
+	})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	created := pkgApi.CreatePromoCodeResponse{}
+	json.NewDecoder(response.Body).Decode(&created)
+
+	response = api.Delete(fmt.Sprintf("/promo-codes/%d", created.ID))
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	response = api.Get(fmt.Sprintf("/promo-codes/%d", created.ID))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func (suite *HandlersTestSuite) TestGetEventCancellation() {
+	toCancelEventID := int32(12)
+	t := suite.T()
+
+	// Set up an event to be cancelled.
+	_, err := suite.Conn.Exec(context.Background(), `
+insert into events (id, venue_id, name, starts_at, ends_at)
+overriding system value
+values ($1, $2, 'Test event to cancel', '2020-01-01:00:00.00Z', '2020-01-01:00:00.00Z')
+`, toCancelEventID, readVenueID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to write test data: %s", err))
+	}
+
+	response := CreateAPIForEvents(suite).Delete(fmt.Sprintf("/events/%d", toCancelEventID))
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	api := CreateAPIForCancellations(suite)
+	response = api.Get(fmt.Sprintf("/events/%d/cancellation", toCancelEventID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.EventCancellationResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, toCancelEventID, actual.EventID)
+	assert.Equal(t, "pending", actual.Status)
+	assert.Equal(t, int64(0), actual.Refunds.Total)
+}
+
+// Test getting the cancellation of an event that hasn't been cancelled.
+func (suite *HandlersTestSuite) TestGetEventCancellationWhenNotCancelled() {
+	t := suite.T()
+	api := CreateAPIForCancellations(suite)
+	response := api.Get(fmt.Sprintf("/events/%d/cancellation", readEventID))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// Test joining, reading and leaving the waitlist of a sold-out event.
+func (suite *HandlersTestSuite) TestJoinWaitlist() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+	path := fmt.Sprintf("/events/%d/waitlist", readEventID)
+
+	defer DeleteWaitlistEntries(t, ctx, suite.Conn)
+
+	// The event has no tickets available, so is sold out.
+	api := CreateAPIForWaitlist(suite)
+
+	response := api.Post(path, header, map[string]any{"quantity": 2})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.WaitlistEntryResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, pkgApi.WaitlistEntryResponse{
+		EventID:  readEventID,
+		Status:   "waiting",
+		Position: 1,
+		Quantity: 2,
+	}, actual)
+
+	response = api.Post(path, otherHeader, map[string]any{"quantity": 1})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual = pkgApi.WaitlistEntryResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, int32(2), actual.Position)
+
+	// A user that's already waiting can change the quantity they're waiting
+	// for.
+	response = api.Post(path, header, map[string]any{"quantity": 1})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = api.Get(path, header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual = pkgApi.WaitlistEntryResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, "waiting", actual.Status)
+	assert.Equal(t, int32(1), actual.Position)
+	assert.Equal(t, int32(1), actual.Quantity)
+
+	response = api.Delete(path, header)
+	require.Equal(t, http.StatusNoContent, response.Code)
+
+	response = api.Get(path, header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Delete(path, header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	// The other user moves up the waitlist.
+	response = api.Get(path, otherHeader)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual = pkgApi.WaitlistEntryResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, int32(1), actual.Position)
+}
+
+// Test joining the waitlist of an event that has tickets available.
+func (suite *HandlersTestSuite) TestJoinWaitlistWhenNotSoldOut() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForWaitlist(suite)
+
+	response := api.Post(fmt.Sprintf("/events/%d/waitlist", readEventID), header, map[string]any{"quantity": 1})
+	require.Equal(t, http.StatusConflict, response.Code)
+
+	response = api.Get(fmt.Sprintf("/events/%d/waitlist", readEventID), header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// Test joining the waitlist of a non-existent or deleted event.
+func (suite *HandlersTestSuite) TestJoinWaitlistWhenEventDoesntExistOrDeleted() {
+	t := suite.T()
+	api := CreateAPIForWaitlist(suite)
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	for _, id := range []int32{missingEventID, deletedEventID} {
+		response := api.Post(fmt.Sprintf("/events/%d/waitlist", id), header, map[string]any{"quantity": 1})
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	}
+}
+
+// Test transferring a purchased ticket to another user, who accepts it.
+func (suite *HandlersTestSuite) TestTransferTicket() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	SetEventStart(t, ctx, suite.Conn, readEventID, time.Now().Add(24*time.Hour))
+	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetEventStart(t, ctx, suite.Conn, readEventID, readEventStartsAt)
+
+	api := CreateAPIForTransfers(suite)
+
+	requestBody := map[string]any{"recipient_id": otherUserID}
+	response := api.Post(fmt.Sprintf("/tickets/%d/transfer", ticketID), header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	transfer := pkgApi.TicketTransferResponse{}
+	json.NewDecoder(response.Body).Decode(&transfer)
+	assert.NotZero(t, transfer.ID)
+	assert.Equal(t, ticketID, transfer.TicketID)
+	assert.Equal(t, userID, transfer.SenderID)
+	assert.Equal(t, otherUserID, transfer.RecipientID)
+	assert.Equal(t, "pending", transfer.Status)
+
+	// A ticket can only have one pending transfer.
+	response = api.Post(fmt.Sprintf("/tickets/%d/transfer", ticketID), header, requestBody)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	// Only the recipient can accept the transfer.
+	response = api.Post(fmt.Sprintf("/transfers/%d/accept", transfer.ID), header)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = api.Post(fmt.Sprintf("/transfers/%d/accept", transfer.ID), otherHeader)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	accepted := pkgApi.TicketTransferResponse{}
+	json.NewDecoder(response.Body).Decode(&accepted)
+	assert.Equal(t, transfer.ID, accepted.ID)
+	assert.Equal(t, "accepted", accepted.Status)
+
+	var purchaserID int32
+	err := suite.Conn.QueryRow(ctx, "select purchaser_id from tickets where id = $1", ticketID).Scan(&purchaserID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket: %s", err))
+	}
+	assert.Equal(t, otherUserID, purchaserID)
+
+	response = api.Post(fmt.Sprintf("/transfers/%d/accept", transfer.ID), otherHeader)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = api.Get(fmt.Sprintf("/transfers/%d", transfer.ID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.TicketTransferResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, "accepted", actual.Status)
+
+	for _, path := range []string{
+		fmt.Sprintf("/tickets/%d/transfers", ticketID),
+		fmt.Sprintf("/users/%d/transfers", userID),
+		fmt.Sprintf("/users/%d/transfers", otherUserID),
+	} {
+		response = api.Get(path)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		transfers := pkgApi.TicketTransfersResponse{}
+		json.NewDecoder(response.Body).Decode(&transfers)
+		require.Len(t, transfers.Transfers, 1, path)
+		assert.Equal(t, transfer.ID, transfers.Transfers[0].ID, path)
+	}
+}
+
+// Test accepting a transfer that would give the recipient more of the event's
+// tickets than its limit on tickets per user.
+func (suite *HandlersTestSuite) TestAcceptTransferWhenPurchaseLimitExceeded() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	WriteOtherTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	SetTicketPurchaser(t, ctx, suite.Conn, otherTicketID, otherUserID)
+	SetEventStart(t, ctx, suite.Conn, readEventID, time.Now().Add(24*time.Hour))
+	SetMaxTicketsPerUser(t, ctx, suite.Conn, 1)
+	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetEventStart(t, ctx, suite.Conn, readEventID, readEventStartsAt)
+	defer SetMaxTicketsPerUser(t, ctx, suite.Conn, 0)
+
+	api := CreateAPIForTransfers(suite)
+
+	requestBody := map[string]any{"recipient_id": otherUserID}
+	response := api.Post(fmt.Sprintf("/tickets/%d/transfer", ticketID), header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	transfer := pkgApi.TicketTransferResponse{}
+	json.NewDecoder(response.Body).Decode(&transfer)
+
+	// The recipient already has as many of the event's tickets as they can.
+	response = api.Post(fmt.Sprintf("/transfers/%d/accept", transfer.ID), otherHeader)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	// Nothing is written, so the transfer is still pending and the ticket
+	// stays with its sender.
+	response = api.Get(fmt.Sprintf("/transfers/%d", transfer.ID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.TicketTransferResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, "pending", actual.Status)
+
+	var purchaserID int32
+	err := suite.Conn.QueryRow(ctx, "select purchaser_id from tickets where id = $1", ticketID).Scan(&purchaserID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket: %s", err))
+	}
+	assert.Equal(t, userID, purchaserID)
+}
+
+// Test cancelling a pending transfer, which only its sender can do.
+func (suite *HandlersTestSuite) TestCancelTransfer() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	SetEventStart(t, ctx, suite.Conn, readEventID, time.Now().Add(24*time.Hour))
+	defer DeleteTicket(t, ctx, suite.Conn)
+	defer SetEventStart(t, ctx, suite.Conn, readEventID, readEventStartsAt)
+
+	api := CreateAPIForTransfers(suite)
+
+	requestBody := map[string]any{"recipient_id": otherUserID}
+	response := api.Post(fmt.Sprintf("/tickets/%d/transfer", ticketID), header, requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	transfer := pkgApi.TicketTransferResponse{}
+	json.NewDecoder(response.Body).Decode(&transfer)
+
+	response = api.Post(fmt.Sprintf("/transfers/%d/cancel", transfer.ID), otherHeader)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = api.Post(fmt.Sprintf("/transfers/%d/cancel", transfer.ID), header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	cancelled := pkgApi.TicketTransferResponse{}
+	json.NewDecoder(response.Body).Decode(&cancelled)
+	assert.Equal(t, "cancelled", cancelled.Status)
+
+	// A cancelled transfer can't be accepted, and the ticket stays with its
+	// sender.
+	response = api.Post(fmt.Sprintf("/transfers/%d/accept", transfer.ID), otherHeader)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	var purchaserID int32
+	err := suite.Conn.QueryRow(ctx, "select purchaser_id from tickets where id = $1", ticketID).Scan(&purchaserID)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Error reading ticket: %s", err))
+	}
+	assert.Equal(t, userID, purchaserID)
+}
+
+// Test transferring a ticket to its owner, by a user that doesn't own it, once
+// its event has started, and transferring a non-existent ticket.
+func (suite *HandlersTestSuite) TestTransferTicketWhenInvalid() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForTransfers(suite)
+	path := fmt.Sprintf("/tickets/%d/transfer", ticketID)
+
+	response := api.Post(path, header, map[string]any{"recipient_id": userID})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = api.Post(path, otherHeader, map[string]any{"recipient_id": otherUserID + 1})
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	// The test event has already started.
+	response = api.Post(path, header, map[string]any{"recipient_id": otherUserID})
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = api.Post("/tickets/999/transfer", header, map[string]any{"recipient_id": otherUserID})
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Get("/transfers/999")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Post("/transfers/999/accept", otherHeader)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Post("/transfers/999/cancel", header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// Test fetching and rotating the credential of a purchased ticket, which only
+// its owner can do.
+func (suite *HandlersTestSuite) TestGetTicketCredential() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+	otherHeader := fmt.Sprintf("x-user-id: %s", otherUserIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForCredentials(suite)
+	path := fmt.Sprintf("/tickets/%d/credential", ticketID)
+
+	response := api.Get(path, header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	credential := pkgApi.TicketCredentialResponse{}
+	json.NewDecoder(response.Body).Decode(&credential)
+	assert.Equal(t, ticketID, credential.TicketID)
+	assert.Equal(t, readEventID, credential.EventID)
+
+	verifier := credentials.NewVerifier(CreateCredentialSigner().PublicKey())
+	claims, err := verifier.Verify(credential.Credential)
+	require.Nil(t, err)
+	assert.Equal(t, ticketID, claims.TicketID)
+	assert.Equal(t, readEventID, claims.EventID)
+	assert.Equal(t, userID, claims.OwnerID)
+
+	// The credential is the same until it's rotated.
+	response = api.Get(path, header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.TicketCredentialResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, credential.Credential, actual.Credential)
+
+	response = api.Get(path, otherHeader)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = api.Post(fmt.Sprintf("%s/rotate", path), otherHeader)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = api.Post(fmt.Sprintf("%s/rotate", path), header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	rotated := pkgApi.TicketCredentialResponse{}
+	json.NewDecoder(response.Body).Decode(&rotated)
+	assert.NotEqual(t, credential.Credential, rotated.Credential)
+
+	rotatedClaims, err := verifier.Verify(rotated.Credential)
+	require.Nil(t, err)
+	assert.NotEqual(t, claims.Nonce, rotatedClaims.Nonce)
+}
+
+// Test fetching the credential of a ticket that hasn't been purchased, or
+// doesn't exist.
+func (suite *HandlersTestSuite) TestGetTicketCredentialWhenNotPurchasedOrDoesntExist() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	api := CreateAPIForCredentials(suite)
+
+	response := api.Get(fmt.Sprintf("/tickets/%d/credential", ticketID), header)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = api.Get("/tickets/999/credential", header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = api.Post("/tickets/999/credential/rotate", header)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+// Test fetching the public key that credentials are verified with.
+func (suite *HandlersTestSuite) TestGetCredentialsPublicKey() {
+	t := suite.T()
+	api := CreateAPIForCredentials(suite)
+
+	response := api.Get("/credentials/public-key")
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.CredentialsPublicKeyResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, "Ed25519", actual.Algorithm)
+	assert.Equal(t, credentials.EncodePublicKey(CreateCredentialSigner().PublicKey()), actual.PublicKey)
+}
+
+// Test checking in a purchased ticket, which can only be done once.
+func (suite *HandlersTestSuite) TestCheckIn() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	credential := FetchTicketCredential(suite, ticketID, header)
+	api := CreateAPIForCheckins(suite)
+
+	requestBody := map[string]any{
+		"ticket_id":  ticketID,
+		"credential": credential,
+		"gate":       "North",
+		"device":     "scanner-1",
+	}
+
+	// The credential is only valid for the ticket's own event.
+	response := api.Post(fmt.Sprintf("/events/%d/checkins", updateEventID), requestBody)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = api.Post(fmt.Sprintf("/events/%d/checkins", readEventID), requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	checkin := pkgApi.CheckinResponse{}
+	json.NewDecoder(response.Body).Decode(&checkin)
+	assert.NotZero(t, checkin.ID)
+	assert.Equal(t, ticketID, checkin.TicketID)
+	assert.Equal(t, readEventID, checkin.EventID)
+	assert.Equal(t, "North", checkin.Gate)
+	assert.Equal(t, "scanner-1", checkin.Device)
+
+	response = api.Post(fmt.Sprintf("/events/%d/checkins", readEventID), requestBody)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = api.Get(fmt.Sprintf("/events/%d/attendance", readEventID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	attendance := pkgApi.EventAttendanceResponse{}
+	json.NewDecoder(response.Body).Decode(&attendance)
+	assert.Equal(t, pkgApi.EventAttendanceResponse{EventID: readEventID, CheckedIn: 1, Purchased: 1}, attendance)
+}
+
+// Test checking in a ticket with a credential that has been rotated, or that
+// wasn't issued for the ticket.
+func (suite *HandlersTestSuite) TestCheckInWhenCredentialInvalid() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	credential := FetchTicketCredential(suite, ticketID, header)
+
+	credentialsAPI := CreateAPIForCredentials(suite)
+	response := credentialsAPI.Post(fmt.Sprintf("/tickets/%d/credential/rotate", ticketID), header)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	api := CreateAPIForCheckins(suite)
+	path := fmt.Sprintf("/events/%d/checkins", readEventID)
+
+	for _, requestBody := range []map[string]any{
+		{"ticket_id": ticketID, "credential": credential, "gate": "North", "device": "scanner-1"},
+		{"ticket_id": otherTicketID, "credential": credential, "gate": "North", "device": "scanner-1"},
+		{"ticket_id": ticketID, "credential": "not-a-credential", "gate": "North", "device": "scanner-1"},
+	} {
+		response = api.Post(path, requestBody)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	}
+
+	var countCheckins int
+	row := suite.Conn.QueryRow(ctx, "select count(*) from checkins where ticket_id = $1", ticketID)
+	if err := row.Scan(&countCheckins); err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to read check-ins: %s", err))
+	}
+	assert.Zero(t, countCheckins)
+}
+
+// Test fetching the attendance of a non-existent or deleted event.
+func (suite *HandlersTestSuite) TestGetAttendanceWhenEventDoesntExistOrDeleted() {
+	t := suite.T()
+	api := CreateAPIForCheckins(suite)
+
+	for _, id := range []int32{missingEventID, deletedEventID} {
+		response := api.Get(fmt.Sprintf("/events/%d/attendance", id))
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	}
+}
+
+// Test fetching the scanner bundle of an event, which scanners verify with the
+// public key that credentials are verified with.
+func (suite *HandlersTestSuite) TestGetScannerBundle() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	credential := FetchTicketCredential(suite, ticketID, header)
+	api := CreateAPIForCheckins(suite)
+
+	response := api.Get(fmt.Sprintf("/events/%d/scanner-bundle", readEventID))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.ScannerBundleResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	assert.Equal(t, readEventID, actual.EventID)
+	assert.Equal(t, 1, actual.Tickets)
+
+	verifier := credentials.NewVerifier(CreateCredentialSigner().PublicKey())
+	bundle, err := verifier.VerifyBundle(actual.Bundle)
+	require.Nil(t, err)
+	assert.Equal(t, readEventID, bundle.EventID)
+
+	// The ticket's current credential can be checked against the bundle while
+	// offline.
+	claims, err := verifier.Verify(credential)
+	require.Nil(t, err)
+	assert.True(t, bundle.IsCurrent(claims))
+}
+
+// Test fetching the scanner bundle of a non-existent or deleted event.
+func (suite *HandlersTestSuite) TestGetScannerBundleWhenEventDoesntExistOrDeleted() {
+	t := suite.T()
+	api := CreateAPIForCheckins(suite)
+
+	for _, id := range []int32{missingEventID, deletedEventID} {
+		response := api.Get(fmt.Sprintf("/events/%d/scanner-bundle", id))
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	}
+}
+
+// Test uploading scans made while offline, of which the earliest scan of a
+// ticket is kept as its check-in.
+func (suite *HandlersTestSuite) TestSyncOfflineScans() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	credential := FetchTicketCredential(suite, ticketID, header)
+	bundle, scannedAt := FetchScannerBundle(suite, readEventID)
+	api := CreateAPIForCheckins(suite)
+
+	requestBody := map[string]any{
+		"device": "scanner-1",
+		"bundle": bundle,
+		"scans": []map[string]any{
+			{
+				"ticket_id":  ticketID,
+				"credential": credential,
+				"gate":       "South",
+				"scanned_at": scannedAt.Add(time.Minute),
+			},
+			{
+				"ticket_id":  ticketID,
+				"credential": credential,
+				"gate":       "North",
+				"scanned_at": scannedAt,
+			},
+			{
+				"ticket_id":  otherTicketID,
+				"credential": credential,
+				"gate":       "North",
+				"scanned_at": scannedAt,
+			},
+		},
+	}
+	response := api.Post(fmt.Sprintf("/events/%d/checkins/offline", readEventID), requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.ScanResultsResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	require.Len(t, actual.Results, 3)
+	assert.Equal(t, pkgApi.ScanResultResponse{TicketID: ticketID, Status: "duplicate"}, actual.Results[0])
+	assert.Equal(t, pkgApi.ScanResultResponse{TicketID: ticketID, Status: "admitted"}, actual.Results[1])
+	assert.Equal(t, otherTicketID, actual.Results[2].TicketID)
+	assert.Equal(t, "rejected", actual.Results[2].Status)
+	assert.NotEmpty(t, actual.Results[2].Reason)
+
+	var gate string
+	var actualScannedAt time.Time
+	err := suite.Conn.QueryRow(
+		ctx,
+		"select gate, scanned_at from checkins where ticket_id = $1",
+		ticketID,
+	).Scan(&gate, &actualScannedAt)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to read check-ins: %s", err))
+	}
+	assert.Equal(t, "North", gate)
+	assert.True(t, scannedAt.Equal(actualScannedAt))
+
+	// Uploading the scans again, e.g. from another device, only gives
+	// duplicates.
+	response = api.Post(fmt.Sprintf("/events/%d/checkins/offline", readEventID), requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual = pkgApi.ScanResultsResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	require.Len(t, actual.Results, 3)
+	assert.Equal(t, "duplicate", actual.Results[0].Status)
+	assert.Equal(t, "duplicate", actual.Results[1].Status)
+}
+
+// Test uploading an offline scan of a ticket that has since been checked in
+// online, which keeps the online check-in even though the scan is earlier.
+func (suite *HandlersTestSuite) TestSyncOfflineScansWhenCheckedInOnline() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	credential := FetchTicketCredential(suite, ticketID, header)
+	bundle, scannedAt := FetchScannerBundle(suite, readEventID)
+	api := CreateAPIForCheckins(suite)
+
+	response := api.Post(fmt.Sprintf("/events/%d/checkins", readEventID), map[string]any{
+		"ticket_id":  ticketID,
+		"credential": credential,
+		"gate":       "North",
+		"device":     "scanner-1",
+	})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	requestBody := map[string]any{
+		"device": "scanner-2",
+		"bundle": bundle,
+		"scans": []map[string]any{
+			{
+				"ticket_id":  ticketID,
+				"credential": credential,
+				"gate":       "South",
+				"scanned_at": scannedAt,
+			},
+			{
+				"ticket_id":  ticketID,
+				"credential": credential,
+				"gate":       "South",
+				"scanned_at": scannedAt.Add(-time.Minute),
+			},
+		},
+	}
+	response = api.Post(fmt.Sprintf("/events/%d/checkins/offline", readEventID), requestBody)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	actual := pkgApi.ScanResultsResponse{}
+	json.NewDecoder(response.Body).Decode(&actual)
+	require.Len(t, actual.Results, 2)
+	assert.Equal(t, "duplicate", actual.Results[0].Status)
+	// A scan from before the bundle was downloaded can't have been checked
+	// against it.
+	assert.Equal(t, "rejected", actual.Results[1].Status)
+	assert.NotEmpty(t, actual.Results[1].Reason)
+
+	var gate, device string
+	var offline bool
+	err := suite.Conn.QueryRow(
+		ctx,
+		"select gate, device, offline from checkins where ticket_id = $1",
+		ticketID,
+	).Scan(&gate, &device, &offline)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to read check-ins: %s", err))
+	}
+	assert.Equal(t, "North", gate)
+	assert.Equal(t, "scanner-1", device)
+	assert.False(t, offline)
+}
+
+// Test uploading offline scans with a scanner bundle that can't be verified,
+// or that was issued for another event.
+func (suite *HandlersTestSuite) TestSyncOfflineScansWhenBundleInvalid() {
+	t := suite.T()
+	ctx := context.Background()
+
+	header := fmt.Sprintf("x-user-id: %s", userIDString)
+
+	WriteTicket(t, ctx, suite.Conn)
+	SetTicketPurchaser(t, ctx, suite.Conn, ticketID, userID)
+	defer DeleteTicket(t, ctx, suite.Conn)
+
+	credential := FetchTicketCredential(suite, ticketID, header)
+	bundle, scannedAt := FetchScannerBundle(suite, readEventID)
+	api := CreateAPIForCheckins(suite)
+
+	scans := []map[string]any{
+		{
+			"ticket_id":  ticketID,
+			"credential": credential,
+			"gate":       "North",
+			"scanned_at": scannedAt,
+		},
+	}
+	for _, tc := range []struct {
+		eventID int32
+		bundle  string
+	}{
+		{readEventID, "not-a-bundle"},
+		{readEventID, credential},
+		{updateEventID, bundle},
+	} {
+		requestBody := map[string]any{"device": "scanner-1", "bundle": tc.bundle, "scans": scans}
+		response := api.Post(fmt.Sprintf("/events/%d/checkins/offline", tc.eventID), requestBody)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	}
+
+	var countCheckins int
+	row := suite.Conn.QueryRow(ctx, "select count(*) from checkins where ticket_id = $1", ticketID)
+	if err := row.Scan(&countCheckins); err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to read check-ins: %s", err))
+	}
+	assert.Zero(t, countCheckins)
 }
 
 // Test searching for events.
@@ -1,7 +1,14 @@
 package api
 
 import (
+	"crypto/ed25519"
+	"strconv"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/credentials"
 	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
 	"github.com/dslaw/book-tickets/pkg/search"
 )
 
@@ -31,14 +38,70 @@ func MapToVenueResponse(venue entities.Venue) GetVenueResponse {
 	return response
 }
 
+func MapToVenueLayout(data WriteVenueLayoutRequest, venueID int32) entities.VenueLayout {
+	layout := entities.VenueLayout{
+		VenueID:  venueID,
+		Sections: make([]entities.VenueSection, len(data.Sections)),
+	}
+
+	for sectionIdx, section := range data.Sections {
+		rows := make([]entities.VenueRow, len(section.Rows))
+		for rowIdx, row := range section.Rows {
+			seats := make([]entities.VenueSeat, len(row.Seats))
+			for seatIdx, seat := range row.Seats {
+				seats[seatIdx] = entities.VenueSeat{
+					Number:     seat.Number,
+					X:          seat.X,
+					Y:          seat.Y,
+					Accessible: seat.Accessible,
+					Obstructed: seat.Obstructed,
+				}
+			}
+			rows[rowIdx] = entities.VenueRow{Name: row.Name, Seats: seats}
+		}
+		layout.Sections[sectionIdx] = entities.VenueSection{Name: section.Name, Rows: rows}
+	}
+	return layout
+}
+
+func MapToVenueLayoutResponse(layout entities.VenueLayout) VenueLayoutResponse {
+	response := VenueLayoutResponse{
+		VenueID:  layout.VenueID,
+		Sections: make([]VenueSectionResponse, len(layout.Sections)),
+	}
+
+	for sectionIdx, section := range layout.Sections {
+		rows := make([]VenueRowResponse, len(section.Rows))
+		for rowIdx, row := range section.Rows {
+			seats := make([]VenueSeatResponse, len(row.Seats))
+			for seatIdx, seat := range row.Seats {
+				seats[seatIdx] = VenueSeatResponse{
+					ID:         seat.ID,
+					Number:     seat.Number,
+					X:          seat.X,
+					Y:          seat.Y,
+					Accessible: seat.Accessible,
+					Obstructed: seat.Obstructed,
+				}
+			}
+			rows[rowIdx] = VenueRowResponse{ID: row.ID, Name: row.Name, Seats: seats}
+		}
+		response.Sections[sectionIdx] = VenueSectionResponse{ID: section.ID, Name: section.Name, Rows: rows}
+	}
+	return response
+}
+
 func MapToEvent(data WriteEventRequest) entities.Event {
 	event := entities.Event{
-		Name:        data.Name,
-		Description: data.Description,
-		StartsAt:    data.StartsAt,
-		EndsAt:      data.EndsAt,
-		Venue:       entities.EventVenue{ID: data.VenueID},
-		Performers:  make([]entities.Performer, len(data.Performers)),
+		Name:              data.Name,
+		Description:       data.Description,
+		StartsAt:          data.StartsAt,
+		EndsAt:            data.EndsAt,
+		Venue:             entities.EventVenue{ID: data.VenueID},
+		Performers:        make([]entities.Performer, len(data.Performers)),
+		MaxTicketsPerUser: data.MaxTicketsPerUser,
+		ResaleMinRate:     data.ResaleMinRate,
+		ResaleMaxRate:     data.ResaleMaxRate,
 	}
 
 	for idx, performer := range data.Performers {
@@ -58,7 +121,10 @@ func MapToEventResponse(event entities.Event) GetEventResponse {
 			ID:   event.Venue.ID,
 			Name: event.Venue.Name,
 		},
-		Performers: make([]EventPerformerResponse, len(event.Performers)),
+		Performers:        make([]EventPerformerResponse, len(event.Performers)),
+		MaxTicketsPerUser: event.MaxTicketsPerUser,
+		ResaleMinRate:     event.ResaleMinRate,
+		ResaleMaxRate:     event.ResaleMaxRate,
 	}
 
 	for idx, performer := range event.Performers {
@@ -67,20 +133,53 @@ func MapToEventResponse(event entities.Event) GetEventResponse {
 	return response
 }
 
+func MapToMoney(data Money) money.Money {
+	return money.New(data.Amount, data.Currency)
+}
+
+func MapToMoneyResponse(amount money.Money) Money {
+	return Money{Amount: amount.Amount, Currency: amount.Currency}
+}
+
 func MapToTickets(data WriteTicketReleaseRequest, eventID int32) []entities.Ticket {
 	totalTickets := 0
 	for _, batch := range data.TicketReleases {
-		totalTickets += int(batch.Number)
+		if len(batch.SeatIDs) > 0 {
+			totalTickets += len(batch.SeatIDs)
+		} else {
+			totalTickets += int(batch.Number)
+		}
 	}
 
 	tickets := make([]entities.Ticket, totalTickets)
 	idx := 0
 	for _, batch := range data.TicketReleases {
+		// Tickets of a ticket type are priced by their type.
+		var price money.Money
+		if batch.Price != nil {
+			price = MapToMoney(*batch.Price)
+		}
+
+		if len(batch.SeatIDs) > 0 {
+			for _, seatID := range batch.SeatIDs {
+				tickets[idx] = entities.Ticket{
+					EventID:      eventID,
+					Price:        price,
+					Seat:         batch.Seat,
+					VenueSeatID:  seatID,
+					TicketTypeID: batch.TicketTypeID,
+				}
+				idx++
+			}
+			continue
+		}
+
 		for range batch.Number {
 			tickets[idx] = entities.Ticket{
-				EventID: eventID,
-				Price:   batch.Price,
-				Seat:    batch.Seat,
+				EventID:      eventID,
+				Price:        price,
+				Seat:         batch.Seat,
+				TicketTypeID: batch.TicketTypeID,
 			}
 			idx++
 		}
@@ -89,18 +188,538 @@ func MapToTickets(data WriteTicketReleaseRequest, eventID int32) []entities.Tick
 	return tickets
 }
 
+func MapToTicketRelease(data WriteTicketReleaseRequest, eventID int32) entities.TicketRelease {
+	presales := make([]entities.Presale, len(data.Presales))
+	for idx, presale := range data.Presales {
+		presales[idx] = entities.Presale{
+			Name:       presale.Name,
+			StartsAt:   presale.StartsAt,
+			EndsAt:     presale.EndsAt,
+			AccessCode: presale.AccessCode,
+			UserIDs:    presale.UserIDs,
+		}
+	}
+	return entities.TicketRelease{
+		EventID:   eventID,
+		OnSaleAt:  data.OnSaleAt,
+		OffSaleAt: data.OffSaleAt,
+		Presales:  presales,
+	}
+}
+
+// MapToSaleAccess maps the user making a request, and the presale access code
+// and waiting room admission token they gave, to their access to presales and
+// events with a waiting room. A user id that isn't numeric can't be on a
+// presale's allowlist, so is ignored.
+func MapToSaleAccess(userID string, accessCode string, admissionToken string) entities.SaleAccess {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		id = 0
+	}
+	return entities.SaleAccess{UserID: int32(id), AccessCode: accessCode, AdmissionToken: admissionToken}
+}
+
 func MapToAvailableTicketsAggregateResponse(ticketAggregates []entities.AvailableTicketAggregate) GetAvailableTicketsAggregateResponse {
 	aggregates := make([]GetAvailableTicketsAggregate, len(ticketAggregates))
 	for idx, ticketAggregate := range ticketAggregates {
 		aggregates[idx] = GetAvailableTicketsAggregate{
-			Seat:      ticketAggregate.Seat,
-			Price:     ticketAggregate.Price,
-			TicketIDs: ticketAggregate.IDs,
+			Seat:         ticketAggregate.Seat,
+			TicketTypeID: ticketAggregate.TicketTypeID,
+			ReleaseID:    ticketAggregate.ReleaseID,
+			Price:        MapToMoneyResponse(ticketAggregate.Price),
+			TicketIDs:    ticketAggregate.IDs,
 		}
 	}
 	return GetAvailableTicketsAggregateResponse{Available: aggregates}
 }
 
+func MapToAvailableInventoryResponse(inventory entities.AvailableInventory) GetAvailableTicketsAggregateResponse {
+	response := MapToAvailableTicketsAggregateResponse(inventory.Seated)
+	response.SoldOut = inventory.IsSoldOut()
+	response.GeneralAdmission = make([]GetAvailableGATier, len(inventory.GeneralAdmission))
+	for idx, tier := range inventory.GeneralAdmission {
+		response.GeneralAdmission[idx] = GetAvailableGATier{
+			ID:        tier.ID,
+			Name:      tier.Name,
+			Price:     MapToMoneyResponse(tier.Price),
+			Remaining: tier.Remaining,
+		}
+	}
+	response.TicketTypes = MapToTicketTypesResponse(inventory.TicketTypes)
+	response.Releases = make([]GetTicketReleaseResponse, len(inventory.Releases))
+	for idx, release := range inventory.Releases {
+		response.Releases[idx] = MapToTicketReleaseResponse(release)
+	}
+	response.Resale = make([]GetAvailableResaleListing, len(inventory.Resale))
+	for idx, listing := range inventory.Resale {
+		response.Resale[idx] = GetAvailableResaleListing{
+			ID:        listing.ID,
+			TicketID:  listing.TicketID,
+			Seat:      listing.Seat,
+			Price:     MapToMoneyResponse(listing.Price),
+			FaceValue: MapToMoneyResponse(listing.FaceValue),
+		}
+	}
+	return response
+}
+
+// MapToTicketReleaseResponse maps a release to its response, leaving out its
+// presales' access codes.
+func MapToTicketReleaseResponse(release entities.TicketRelease) GetTicketReleaseResponse {
+	response := GetTicketReleaseResponse{
+		ID:       release.ID,
+		Presales: make([]GetPresaleResponse, len(release.Presales)),
+	}
+	if !release.OnSaleAt.IsZero() {
+		response.OnSaleAt = &release.OnSaleAt
+	}
+	if !release.OffSaleAt.IsZero() {
+		response.OffSaleAt = &release.OffSaleAt
+	}
+	for idx, presale := range release.Presales {
+		response.Presales[idx] = GetPresaleResponse{
+			Name:     presale.Name,
+			StartsAt: presale.StartsAt,
+			EndsAt:   presale.EndsAt,
+		}
+	}
+	return response
+}
+
+func MapToTicketType(data WriteTicketTypeRequest, eventID int32) entities.TicketType {
+	eligibility := data.Eligibility
+	if eligibility == nil {
+		eligibility = []string{}
+	}
+	return entities.TicketType{
+		EventID:      eventID,
+		Name:         data.Name,
+		Description:  data.Description,
+		Price:        MapToMoney(data.Price),
+		Eligibility:  eligibility,
+		SaleStartsAt: data.SaleStartsAt,
+		SaleEndsAt:   data.SaleEndsAt,
+	}
+}
+
+func MapToTicketTypeResponse(ticketType entities.TicketType) GetTicketTypeResponse {
+	response := GetTicketTypeResponse{
+		ID:          ticketType.ID,
+		Name:        ticketType.Name,
+		Description: ticketType.Description,
+		Price:       MapToMoneyResponse(ticketType.Price),
+		Eligibility: ticketType.Eligibility,
+	}
+	if !ticketType.SaleStartsAt.IsZero() {
+		response.SaleStartsAt = &ticketType.SaleStartsAt
+	}
+	if !ticketType.SaleEndsAt.IsZero() {
+		response.SaleEndsAt = &ticketType.SaleEndsAt
+	}
+	return response
+}
+
+func MapToTicketTypesResponse(ticketTypes []entities.TicketType) []GetTicketTypeResponse {
+	response := make([]GetTicketTypeResponse, len(ticketTypes))
+	for idx, ticketType := range ticketTypes {
+		response[idx] = MapToTicketTypeResponse(ticketType)
+	}
+	return response
+}
+
+func MapToGATier(data WriteGATierRequest, eventID int32) entities.GATier {
+	return entities.GATier{
+		EventID:  eventID,
+		Name:     data.Name,
+		Price:    MapToMoney(data.Price),
+		Capacity: data.Capacity,
+	}
+}
+
+func MapToGATierHoldResponse(hold entities.GATierHold) GATierHoldResponse {
+	return GATierHoldResponse{
+		HoldToken: hold.Token,
+		GATierID:  hold.GATierID,
+		Quantity:  hold.Quantity,
+		ExpiresAt: hold.ExpiresAt,
+	}
+}
+
+func MapToTicketsHoldResponse(hold entities.TicketHold) TicketsHoldResponse {
+	return TicketsHoldResponse{
+		HoldToken: hold.Token,
+		TicketIDs: hold.TicketIDs,
+		ExpiresAt: hold.ExpiresAt,
+	}
+}
+
+func MapToSeatSelection(data BestAvailableRequest) entities.SeatSelection {
+	selection := entities.SeatSelection{Quantity: data.Quantity, Sections: data.Sections}
+	if data.MaxPrice != nil {
+		maxPrice := MapToMoney(*data.MaxPrice)
+		selection.MaxPrice = &maxPrice
+	}
+	return selection
+}
+
+func MapToBestAvailableResponse(hold entities.TicketHold, seats []entities.SeatedTicket) BestAvailableResponse {
+	response := BestAvailableResponse{
+		HoldToken: hold.Token,
+		TicketIDs: hold.TicketIDs,
+		ExpiresAt: hold.ExpiresAt,
+		Seats:     make([]HeldSeatResponse, len(seats)),
+	}
+	for idx, seat := range seats {
+		response.Seats[idx] = HeldSeatResponse{
+			TicketID: seat.Ticket.ID,
+			Section:  seat.Position.Section,
+			Row:      seat.Position.Row,
+			Number:   seat.Position.Number,
+			Price:    MapToMoneyResponse(seat.Ticket.Price),
+		}
+	}
+	return response
+}
+
+func MapToQuoteResponse(quote entities.Quote) QuoteResponse {
+	response := QuoteResponse{
+		Items:       make([]QuoteItemResponse, len(quote.Items)),
+		FaceValue:   MapToMoneyResponse(quote.FaceValue),
+		Discount:    MapToMoneyResponse(quote.Discount),
+		ServiceFees: MapToMoneyResponse(quote.ServiceFees),
+		FacilityFee: MapToMoneyResponse(quote.FacilityFee),
+		Tax:         MapToMoneyResponse(quote.Tax),
+		Total:       MapToMoneyResponse(quote.Total),
+	}
+
+	for idx, item := range quote.Items {
+		response.Items[idx] = QuoteItemResponse{
+			TicketID:   item.TicketID,
+			FaceValue:  MapToMoneyResponse(item.FaceValue),
+			Discount:   MapToMoneyResponse(item.Discount),
+			ServiceFee: MapToMoneyResponse(item.ServiceFee),
+			Tax:        MapToMoneyResponse(item.Tax),
+			Total:      MapToMoneyResponse(item.Total),
+		}
+	}
+	return response
+}
+
+func MapToCard(data Card) payment.Card {
+	return payment.Card{
+		Name:            data.Name,
+		Address:         data.Address,
+		Number:          data.Number,
+		ExpirationMonth: data.ExpirationMonth,
+		ExpirationYear:  data.ExpirationYear,
+		CVC:             data.CVC,
+	}
+}
+
+func MapToPaymentResponse(purchase entities.PurchaseResult) PaymentResponse {
+	return PaymentResponse{
+		Success:       purchase.Accepted,
+		OrderID:       purchase.OrderID,
+		DeclineReason: purchase.DeclineReason,
+	}
+}
+
+func MapToRefundResponse(refund entities.Refund, rereleased bool) RefundResponse {
+	return RefundResponse{
+		ID:         refund.ID,
+		TicketID:   refund.TicketID,
+		Amount:     MapToMoneyResponse(refund.Amount),
+		Status:     refund.Status,
+		Rereleased: rereleased,
+	}
+}
+
+func MapToOrderResponse(order entities.Order) GetOrderResponse {
+	response := GetOrderResponse{
+		ID:          order.ID,
+		PurchaserID: order.PurchaserID,
+		PaymentID:   order.PaymentID,
+		Status:      order.Status,
+		Total:       MapToMoneyResponse(order.Total),
+		PromoCodeID: order.PromoCodeID,
+		Discount:    MapToMoneyResponse(order.Discount),
+		FacilityFee: MapToMoneyResponse(order.FacilityFee),
+		Tax:         MapToMoneyResponse(order.Tax),
+		CreatedAt:   order.CreatedAt,
+		UpdatedAt:   order.UpdatedAt,
+		Items:       make([]OrderItemResponse, len(order.Items)),
+	}
+
+	for idx, item := range order.Items {
+		response.Items[idx] = OrderItemResponse{
+			TicketID:   item.TicketID,
+			Price:      MapToMoneyResponse(item.Price),
+			Discount:   MapToMoneyResponse(item.Discount),
+			ServiceFee: MapToMoneyResponse(item.ServiceFee),
+			Tax:        MapToMoneyResponse(item.Tax),
+		}
+	}
+	return response
+}
+
+func MapToUserOrdersResponse(orders []entities.Order) GetUserOrdersResponse {
+	response := GetUserOrdersResponse{Orders: make([]GetOrderResponse, len(orders))}
+	for idx, order := range orders {
+		response.Orders[idx] = MapToOrderResponse(order)
+	}
+	return response
+}
+
+func MapToFeeRule(data FeeRule) entities.FeeRule {
+	return entities.FeeRule{
+		ServiceFeeRate: data.ServiceFeeRate,
+		ServiceFeeFlat: data.ServiceFeeFlat,
+		FacilityFee:    data.FacilityFee,
+	}
+}
+
+func MapToFeeRuleResponse(rule entities.FeeRule) FeeRule {
+	return FeeRule{
+		ServiceFeeRate: rule.ServiceFeeRate,
+		ServiceFeeFlat: rule.ServiceFeeFlat,
+		FacilityFee:    rule.FacilityFee,
+	}
+}
+
+func MapToTaxRate(data WriteTaxRateRequest) entities.TaxRate {
+	return entities.TaxRate{
+		CountryCode: data.CountryCode,
+		Subdivision: data.Subdivision,
+		Rate:        data.Rate,
+	}
+}
+
+func MapToPromoCode(data WritePromoCodeRequest) entities.PromoCode {
+	promoCode := entities.PromoCode{
+		Code:                  data.Code,
+		DiscountType:          data.DiscountType,
+		PercentOff:            data.PercentOff,
+		EventID:               data.EventID,
+		StartsAt:              data.StartsAt,
+		EndsAt:                data.EndsAt,
+		MaxRedemptions:        data.MaxRedemptions,
+		MaxRedemptionsPerUser: data.MaxRedemptionsPerUser,
+	}
+	if data.AmountOff != nil {
+		promoCode.AmountOff = MapToMoney(*data.AmountOff)
+	}
+	return promoCode
+}
+
+func MapToPromoCodeResponse(promoCode entities.PromoCode) GetPromoCodeResponse {
+	response := GetPromoCodeResponse{
+		ID:                    promoCode.ID,
+		Code:                  promoCode.Code,
+		DiscountType:          promoCode.DiscountType,
+		PercentOff:            promoCode.PercentOff,
+		EventID:               promoCode.EventID,
+		MaxRedemptions:        promoCode.MaxRedemptions,
+		MaxRedemptionsPerUser: promoCode.MaxRedemptionsPerUser,
+		Redemptions:           promoCode.Redemptions,
+	}
+	if promoCode.DiscountType == entities.DiscountTypeFixed {
+		amountOff := MapToMoneyResponse(promoCode.AmountOff)
+		response.AmountOff = &amountOff
+	}
+	if !promoCode.StartsAt.IsZero() {
+		response.StartsAt = &promoCode.StartsAt
+	}
+	if !promoCode.EndsAt.IsZero() {
+		response.EndsAt = &promoCode.EndsAt
+	}
+	return response
+}
+
+func MapToEventCancellationResponse(cancellation entities.EventCancellation) EventCancellationResponse {
+	response := EventCancellationResponse{
+		EventID:   cancellation.EventID,
+		Status:    cancellation.Status,
+		CreatedAt: cancellation.CreatedAt,
+		UpdatedAt: cancellation.UpdatedAt,
+	}
+	response.Refunds.Total = cancellation.TotalCount
+	response.Refunds.Pending = cancellation.PendingCount
+	response.Refunds.Refunded = cancellation.RefundedCount
+	response.Refunds.Failed = cancellation.FailedCount
+	response.Refunds.Skipped = cancellation.SkippedCount
+	return response
+}
+
+func MapToWaitingRoom(data WriteWaitingRoomRequest, eventID int32) entities.WaitingRoom {
+	return entities.WaitingRoom{EventID: eventID, BatchSize: data.BatchSize}
+}
+
+func MapToWaitingRoomResponse(room entities.WaitingRoom) GetWaitingRoomResponse {
+	return GetWaitingRoomResponse{EventID: room.EventID, BatchSize: room.BatchSize}
+}
+
+func MapToQueuePositionResponse(position entities.QueuePosition) QueuePositionResponse {
+	response := QueuePositionResponse{
+		EventID:        position.EventID,
+		Position:       position.Position,
+		Admitted:       position.IsAdmitted(),
+		AdmissionToken: position.AdmissionToken,
+	}
+	if !position.ExpiresAt.IsZero() {
+		response.ExpiresAt = &position.ExpiresAt
+	}
+	return response
+}
+
+func MapToWaitlistEntry(data JoinWaitlistRequest, eventID int32, userID int32) entities.WaitlistEntry {
+	return entities.WaitlistEntry{EventID: eventID, UserID: userID, Quantity: data.Quantity}
+}
+
+// MapToWaitlistEntryResponse maps a waitlist entry to its response, with its
+// status as of the given time. The offer's token is left out once the offer
+// has expired.
+func MapToWaitlistEntryResponse(entry entities.WaitlistEntry, at time.Time) WaitlistEntryResponse {
+	response := WaitlistEntryResponse{
+		EventID:  entry.EventID,
+		Status:   entry.Status(at),
+		Position: entry.Position,
+		Quantity: entry.Quantity,
+	}
+	if response.Status == entities.WaitlistStatusOffered {
+		response.OfferToken = entry.OfferToken
+		response.OfferExpiresAt = &entry.OfferExpiresAt
+	}
+	return response
+}
+
+func MapToTicketTransfer(data CreateTicketTransferRequest, ticketID int32, senderID int32) entities.TicketTransfer {
+	return entities.TicketTransfer{TicketID: ticketID, SenderID: senderID, RecipientID: data.RecipientID}
+}
+
+func MapToTicketTransferResponse(transfer entities.TicketTransfer) TicketTransferResponse {
+	return TicketTransferResponse{
+		ID:          transfer.ID,
+		TicketID:    transfer.TicketID,
+		SenderID:    transfer.SenderID,
+		RecipientID: transfer.RecipientID,
+		Status:      transfer.Status,
+		CreatedAt:   transfer.CreatedAt,
+		UpdatedAt:   transfer.UpdatedAt,
+	}
+}
+
+func MapToTicketTransfersResponse(transfers []entities.TicketTransfer) TicketTransfersResponse {
+	response := TicketTransfersResponse{Transfers: make([]TicketTransferResponse, len(transfers))}
+	for idx, transfer := range transfers {
+		response.Transfers[idx] = MapToTicketTransferResponse(transfer)
+	}
+	return response
+}
+
+func MapToResaleListing(data CreateResaleListingRequest, ticketID int32, sellerID int32) entities.ResaleListing {
+	return entities.ResaleListing{TicketID: ticketID, SellerID: sellerID, Price: MapToMoney(data.Price)}
+}
+
+func MapToResaleListingResponse(listing entities.ResaleListing) ResaleListingResponse {
+	return ResaleListingResponse{
+		ID:        listing.ID,
+		TicketID:  listing.TicketID,
+		SellerID:  listing.SellerID,
+		Price:     MapToMoneyResponse(listing.Price),
+		Status:    listing.Status,
+		CreatedAt: listing.CreatedAt,
+		UpdatedAt: listing.UpdatedAt,
+	}
+}
+
+func MapToResaleListingsResponse(listings []entities.ResaleListing) ResaleListingsResponse {
+	response := ResaleListingsResponse{Listings: make([]ResaleListingResponse, len(listings))}
+	for idx, listing := range listings {
+		response.Listings[idx] = MapToResaleListingResponse(listing)
+	}
+	return response
+}
+
+func MapToTicketCredentialResponse(credential entities.TicketCredential) TicketCredentialResponse {
+	return TicketCredentialResponse{
+		TicketID:   credential.TicketID,
+		EventID:    credential.EventID,
+		Credential: credential.Token,
+		RotatedAt:  credential.RotatedAt,
+	}
+}
+
+func MapToCredentialsPublicKeyResponse(key ed25519.PublicKey) CredentialsPublicKeyResponse {
+	return CredentialsPublicKeyResponse{
+		Algorithm: "Ed25519",
+		PublicKey: credentials.EncodePublicKey(key),
+	}
+}
+
+func MapToCheckin(data CreateCheckinRequest, eventID int32) entities.Checkin {
+	return entities.Checkin{
+		TicketID: data.TicketID,
+		EventID:  eventID,
+		Gate:     data.Gate,
+		Device:   data.Device,
+	}
+}
+
+func MapToCheckinResponse(checkin entities.Checkin) CheckinResponse {
+	return CheckinResponse{
+		ID:        checkin.ID,
+		TicketID:  checkin.TicketID,
+		EventID:   checkin.EventID,
+		Gate:      checkin.Gate,
+		Device:    checkin.Device,
+		ScannedAt: checkin.ScannedAt,
+	}
+}
+
+func MapToEventAttendanceResponse(attendance entities.EventAttendance) EventAttendanceResponse {
+	return EventAttendanceResponse{
+		EventID:   attendance.EventID,
+		CheckedIn: attendance.CheckedIn,
+		Purchased: attendance.Purchased,
+	}
+}
+
+func MapToScannerBundleResponse(bundle entities.ScannerBundle) ScannerBundleResponse {
+	return ScannerBundleResponse{
+		EventID:  bundle.EventID,
+		IssuedAt: bundle.IssuedAt,
+		Tickets:  len(bundle.Tickets),
+		Revoked:  len(bundle.Revoked),
+		Bundle:   bundle.Token,
+	}
+}
+
+func MapToOfflineScans(data SyncOfflineScansRequest) []entities.OfflineScan {
+	scans := make([]entities.OfflineScan, len(data.Scans))
+	for idx, scan := range data.Scans {
+		scans[idx] = entities.OfflineScan{
+			TicketID:   scan.TicketID,
+			Credential: scan.Credential,
+			Gate:       scan.Gate,
+			Device:     data.Device,
+			ScannedAt:  scan.ScannedAt,
+		}
+	}
+	return scans
+}
+
+func MapToScanResultsResponse(results []entities.ScanResult) ScanResultsResponse {
+	response := ScanResultsResponse{Results: make([]ScanResultResponse, len(results))}
+	for idx, result := range results {
+		response.Results[idx] = ScanResultResponse{
+			TicketID: result.TicketID,
+			Status:   result.Status,
+			Reason:   result.Reason,
+		}
+	}
+	return response
+}
+
 func MapToEventsSearchResponse(documents []search.EventDocument) EventsSearchResponse {
 	size := len(documents)
 	results := make([]EventSearchResult, size)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/dslaw/book-tickets/pkg/api"
 	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
 	"github.com/dslaw/book-tickets/pkg/search"
 	"github.com/stretchr/testify/assert"
 )
@@ -74,6 +75,9 @@ func TestMapToEvent(t *testing.T) {
 			{Name: "Performer 1"},
 			{Name: "Performer 2"},
 		},
+		MaxTicketsPerUser: 4,
+		ResaleMinRate:     8000,
+		ResaleMaxRate:     12000,
 	}
 
 	expected := entities.Event{
@@ -86,6 +90,9 @@ func TestMapToEvent(t *testing.T) {
 			{Name: "Performer 1"},
 			{Name: "Performer 2"},
 		},
+		MaxTicketsPerUser: 4,
+		ResaleMinRate:     8000,
+		ResaleMaxRate:     12000,
 	}
 
 	actual := api.MapToEvent(requestData)
@@ -110,6 +117,9 @@ func TestMapToEventResponse(t *testing.T) {
 			{ID: 1, Name: "Test Performer 1"},
 			{ID: 2, Name: "Test Performer 2"},
 		},
+		MaxTicketsPerUser: 4,
+		ResaleMinRate:     8000,
+		ResaleMaxRate:     12000,
 	}
 	expected := api.GetEventResponse{
 		ID:          1,
@@ -125,6 +135,9 @@ func TestMapToEventResponse(t *testing.T) {
 			{ID: 1, Name: "Test Performer 1"},
 			{ID: 2, Name: "Test Performer 2"},
 		},
+		MaxTicketsPerUser: 4,
+		ResaleMinRate:     8000,
+		ResaleMaxRate:     12000,
 	}
 
 	actual := api.MapToEventResponse(event)
@@ -135,32 +148,97 @@ func TestMapToTickets(t *testing.T) {
 	eventID := int32(1)
 	requestData := api.WriteTicketReleaseRequest{
 		TicketReleases: []api.WriteTicketRelease{
-			{Number: 2, Seat: "GA", Price: 10},
-			{Number: 3, Seat: "Balcony", Price: 20},
+			{Number: 2, Seat: "GA", Price: &api.Money{Amount: 1000, Currency: "USD"}},
+			{Number: 3, Seat: "Balcony", Price: &api.Money{Amount: 2000, Currency: "USD"}},
 		},
 	}
 
 	expected := []entities.Ticket{
-		{EventID: eventID, Price: 10, Seat: "GA"},
-		{EventID: eventID, Price: 10, Seat: "GA"},
-		{EventID: eventID, Price: 20, Seat: "Balcony"},
-		{EventID: eventID, Price: 20, Seat: "Balcony"},
-		{EventID: eventID, Price: 20, Seat: "Balcony"},
+		{EventID: eventID, Price: money.New(1000, "USD"), Seat: "GA"},
+		{EventID: eventID, Price: money.New(1000, "USD"), Seat: "GA"},
+		{EventID: eventID, Price: money.New(2000, "USD"), Seat: "Balcony"},
+		{EventID: eventID, Price: money.New(2000, "USD"), Seat: "Balcony"},
+		{EventID: eventID, Price: money.New(2000, "USD"), Seat: "Balcony"},
 	}
 
 	actual := api.MapToTickets(requestData, eventID)
 	assert.EqualValues(t, expected, actual)
 }
 
+func TestMapToTicketsWithSeatIDs(t *testing.T) {
+	eventID := int32(1)
+	requestData := api.WriteTicketReleaseRequest{
+		TicketReleases: []api.WriteTicketRelease{
+			{SeatIDs: []int32{11, 12}, Price: &api.Money{Amount: 1000, Currency: "USD"}},
+			{Number: 1, Seat: "GA", Price: &api.Money{Amount: 500, Currency: "USD"}},
+		},
+	}
+
+	expected := []entities.Ticket{
+		{EventID: eventID, Price: money.New(1000, "USD"), VenueSeatID: 11},
+		{EventID: eventID, Price: money.New(1000, "USD"), VenueSeatID: 12},
+		{EventID: eventID, Price: money.New(500, "USD"), Seat: "GA"},
+	}
+
+	actual := api.MapToTickets(requestData, eventID)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapToTicketsWithTicketType(t *testing.T) {
+	eventID := int32(1)
+	requestData := api.WriteTicketReleaseRequest{
+		TicketReleases: []api.WriteTicketRelease{
+			{SeatIDs: []int32{11}, TicketTypeID: 2},
+			{Number: 1, Seat: "GA", TicketTypeID: 3},
+		},
+	}
+
+	expected := []entities.Ticket{
+		{EventID: eventID, VenueSeatID: 11, TicketTypeID: 2},
+		{EventID: eventID, Seat: "GA", TicketTypeID: 3},
+	}
+
+	actual := api.MapToTickets(requestData, eventID)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapToVenueLayout(t *testing.T) {
+	requestData := api.WriteVenueLayoutRequest{
+		Sections: []api.WriteVenueSection{
+			{Name: "Orchestra", Rows: []api.WriteVenueRow{
+				{Name: "A", Seats: []api.WriteVenueSeat{
+					{Number: "1", X: 0, Y: 0, Accessible: true},
+					{Number: "2", X: 1, Y: 0, Obstructed: true},
+				}},
+			}},
+		},
+	}
+
+	expected := entities.VenueLayout{
+		VenueID: 1,
+		Sections: []entities.VenueSection{
+			{Name: "Orchestra", Rows: []entities.VenueRow{
+				{Name: "A", Seats: []entities.VenueSeat{
+					{Number: "1", X: 0, Y: 0, Accessible: true},
+					{Number: "2", X: 1, Y: 0, Obstructed: true},
+				}},
+			}},
+		},
+	}
+
+	actual := api.MapToVenueLayout(requestData, 1)
+	assert.EqualValues(t, expected, actual)
+}
+
 func TestMapToAvailableTicketsAggregateResponse(t *testing.T) {
 	ticketAggregates := []entities.AvailableTicketAggregate{
-		{Seat: "GA", Price: 10, IDs: []int32{1, 2, 3}},
-		{Seat: "Balcony", Price: 20, IDs: []int32{4, 5}},
+		{Seat: "GA", Price: money.New(1000, "USD"), IDs: []int32{1, 2, 3}},
+		{Seat: "Balcony", Price: money.New(2000, "USD"), IDs: []int32{4, 5}},
 	}
 	expected := api.GetAvailableTicketsAggregateResponse{
 		Available: []api.GetAvailableTicketsAggregate{
-			{Seat: "GA", Price: 10, TicketIDs: []int32{1, 2, 3}},
-			{Seat: "Balcony", Price: 20, TicketIDs: []int32{4, 5}},
+			{Seat: "GA", Price: api.Money{Amount: 1000, Currency: "USD"}, TicketIDs: []int32{1, 2, 3}},
+			{Seat: "Balcony", Price: api.Money{Amount: 2000, Currency: "USD"}, TicketIDs: []int32{4, 5}},
 		},
 	}
 
@@ -168,6 +246,434 @@ func TestMapToAvailableTicketsAggregateResponse(t *testing.T) {
 	assert.EqualValues(t, expected, actual)
 }
 
+func TestMapToTicketsHoldResponse(t *testing.T) {
+	expiresAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:10:00Z")
+	hold := entities.TicketHold{
+		Token:     "abc",
+		HolderID:  "1",
+		TicketIDs: []int32{1, 2},
+		ExpiresAt: expiresAt,
+	}
+	expected := api.TicketsHoldResponse{
+		HoldToken: "abc",
+		TicketIDs: []int32{1, 2},
+		ExpiresAt: expiresAt,
+	}
+
+	actual := api.MapToTicketsHoldResponse(hold)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapToSeatSelection(t *testing.T) {
+	requestData := api.BestAvailableRequest{
+		Quantity: 4,
+		MaxPrice: &api.Money{Amount: 5000, Currency: "USD"},
+		Sections: []string{"Orchestra", "Mezzanine"},
+	}
+
+	maxPrice := money.New(5000, "USD")
+	expected := entities.SeatSelection{
+		Quantity: 4,
+		MaxPrice: &maxPrice,
+		Sections: []string{"Orchestra", "Mezzanine"},
+	}
+
+	actual := api.MapToSeatSelection(requestData)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapToPaymentResponseWhenDeclined(t *testing.T) {
+	purchase := entities.PurchaseResult{Accepted: false, DeclineReason: "card_declined"}
+	expected := api.PaymentResponse{Success: false, DeclineReason: "card_declined"}
+
+	actual := api.MapToPaymentResponse(purchase)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapToRefundResponse(t *testing.T) {
+	refund := entities.Refund{
+		ID:        1,
+		PaymentID: 2,
+		TicketID:  3,
+		Amount:    money.New(2000, "USD"),
+		Status:    entities.RefundStatusCompleted,
+		Reference: "abc",
+	}
+	expected := api.RefundResponse{
+		ID:         1,
+		TicketID:   3,
+		Amount:     api.Money{Amount: 2000, Currency: "USD"},
+		Status:     "completed",
+		Rereleased: true,
+	}
+
+	actual := api.MapToRefundResponse(refund, true)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapToOrderResponse(t *testing.T) {
+	createdAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	order := entities.Order{
+		ID:          1,
+		PurchaserID: 2,
+		PaymentID:   3,
+		Status:      entities.OrderStatusCompleted,
+		Total:       money.New(3000, "USD"),
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+		Items: []entities.OrderItem{
+			{TicketID: 1, Price: money.New(1000, "USD")},
+			{TicketID: 2, Price: money.New(2000, "USD")},
+		},
+	}
+	expected := api.GetOrderResponse{
+		ID:          1,
+		PurchaserID: 2,
+		PaymentID:   3,
+		Status:      "completed",
+		Total:       api.Money{Amount: 3000, Currency: "USD"},
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+		Items: []api.OrderItemResponse{
+			{TicketID: 1, Price: api.Money{Amount: 1000, Currency: "USD"}},
+			{TicketID: 2, Price: api.Money{Amount: 2000, Currency: "USD"}},
+		},
+	}
+
+	actual := api.MapToOrderResponse(order)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapToPromoCode(t *testing.T) {
+	endsAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	data := api.WritePromoCodeRequest{
+		Code:                  "SUMMER10",
+		DiscountType:          "fixed",
+		AmountOff:             &api.Money{Amount: 1000, Currency: "USD"},
+		EventID:               1,
+		EndsAt:                endsAt,
+		MaxRedemptionsPerUser: 2,
+	}
+	expected := entities.PromoCode{
+		Code:                  "SUMMER10",
+		DiscountType:          entities.DiscountTypeFixed,
+		AmountOff:             money.New(1000, "USD"),
+		EventID:               1,
+		EndsAt:                endsAt,
+		MaxRedemptionsPerUser: 2,
+	}
+
+	actual := api.MapToPromoCode(data)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapToPromoCodeResponse(t *testing.T) {
+	startsAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	promoCode := entities.PromoCode{
+		ID:           1,
+		Code:         "SUMMER25",
+		DiscountType: entities.DiscountTypePercentage,
+		PercentOff:   2500,
+		StartsAt:     startsAt,
+		Redemptions:  3,
+	}
+	expected := api.GetPromoCodeResponse{
+		ID:           1,
+		Code:         "SUMMER25",
+		DiscountType: "percentage",
+		PercentOff:   2500,
+		StartsAt:     &startsAt,
+		Redemptions:  3,
+	}
+
+	actual := api.MapToPromoCodeResponse(promoCode)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapToTicketRelease(t *testing.T) {
+	onSaleAt, _ := time.Parse(time.RFC3339, "2020-01-02T00:00:00Z")
+	startsAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	data := api.WriteTicketReleaseRequest{
+		OnSaleAt: onSaleAt,
+		Presales: []api.WritePresale{
+			{Name: "Fan Club", StartsAt: startsAt, EndsAt: onSaleAt, AccessCode: "FANS"},
+			{Name: "Members", StartsAt: startsAt, EndsAt: onSaleAt, UserIDs: []int32{1, 2}},
+		},
+	}
+	expected := entities.TicketRelease{
+		EventID:  1,
+		OnSaleAt: onSaleAt,
+		Presales: []entities.Presale{
+			{Name: "Fan Club", StartsAt: startsAt, EndsAt: onSaleAt, AccessCode: "FANS"},
+			{Name: "Members", StartsAt: startsAt, EndsAt: onSaleAt, UserIDs: []int32{1, 2}},
+		},
+	}
+
+	actual := api.MapToTicketRelease(data, 1)
+	assert.Equal(t, expected, actual)
+	assert.True(t, actual.IsScheduled())
+}
+
+func TestMapToSaleAccess(t *testing.T) {
+	assert.Equal(t, entities.SaleAccess{UserID: 123, AccessCode: "FANS"}, api.MapToSaleAccess("123", "FANS", ""))
+	assert.Equal(t, entities.SaleAccess{}, api.MapToSaleAccess("guest", "", ""))
+	assert.Equal(
+		t,
+		entities.SaleAccess{UserID: 123, AdmissionToken: "token"},
+		api.MapToSaleAccess("123", "", "token"),
+	)
+}
+
+func TestMapToTicketReleaseResponse(t *testing.T) {
+	onSaleAt, _ := time.Parse(time.RFC3339, "2020-01-02T00:00:00Z")
+	startsAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	release := entities.TicketRelease{
+		ID:       1,
+		EventID:  2,
+		OnSaleAt: onSaleAt,
+		Presales: []entities.Presale{
+			{ID: 3, Name: "Fan Club", StartsAt: startsAt, EndsAt: onSaleAt, AccessCode: "FANS"},
+		},
+	}
+	expected := api.GetTicketReleaseResponse{
+		ID:       1,
+		OnSaleAt: &onSaleAt,
+		Presales: []api.GetPresaleResponse{
+			{Name: "Fan Club", StartsAt: startsAt, EndsAt: onSaleAt},
+		},
+	}
+
+	actual := api.MapToTicketReleaseResponse(release)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapToEventCancellationResponse(t *testing.T) {
+	createdAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	cancellation := entities.EventCancellation{
+		ID:            2,
+		EventID:       1,
+		Status:        entities.CancellationStatusPending,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+		TotalCount:    4,
+		PendingCount:  1,
+		RefundedCount: 1,
+		FailedCount:   1,
+		SkippedCount:  1,
+	}
+	expected := api.EventCancellationResponse{
+		EventID:   1,
+		Status:    "pending",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	expected.Refunds.Total = 4
+	expected.Refunds.Pending = 1
+	expected.Refunds.Refunded = 1
+	expected.Refunds.Failed = 1
+	expected.Refunds.Skipped = 1
+
+	actual := api.MapToEventCancellationResponse(cancellation)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapToQueuePositionResponse(t *testing.T) {
+	expiresAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:15:00Z")
+
+	type testCase struct {
+		Name     string
+		Position entities.QueuePosition
+		Expected api.QueuePositionResponse
+	}
+
+	testCases := []testCase{
+		{
+			Name:     "Queued",
+			Position: entities.QueuePosition{EventID: 1, Position: 42},
+			Expected: api.QueuePositionResponse{EventID: 1, Position: 42},
+		},
+		{
+			Name:     "Admitted",
+			Position: entities.QueuePosition{EventID: 1, AdmissionToken: "token", ExpiresAt: expiresAt},
+			Expected: api.QueuePositionResponse{
+				EventID:        1,
+				Admitted:       true,
+				AdmissionToken: "token",
+				ExpiresAt:      &expiresAt,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			actual := api.MapToQueuePositionResponse(tc.Position)
+			assert.Equal(t, tc.Expected, actual)
+		})
+	}
+}
+
+func TestMapToWaitlistEntryResponse(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	expiresAt := now.Add(15 * time.Minute)
+
+	type testCase struct {
+		Name     string
+		Entry    entities.WaitlistEntry
+		Expected api.WaitlistEntryResponse
+	}
+
+	testCases := []testCase{
+		{
+			Name:     "Waiting",
+			Entry:    entities.WaitlistEntry{EventID: 1, UserID: 123, Quantity: 2, Position: 4},
+			Expected: api.WaitlistEntryResponse{EventID: 1, Status: "waiting", Position: 4, Quantity: 2},
+		},
+		{
+			Name: "Offered",
+			Entry: entities.WaitlistEntry{
+				EventID:        1,
+				UserID:         123,
+				Quantity:       2,
+				OfferToken:     "token",
+				OfferExpiresAt: expiresAt,
+			},
+			Expected: api.WaitlistEntryResponse{
+				EventID:        1,
+				Status:         "offered",
+				Quantity:       2,
+				OfferToken:     "token",
+				OfferExpiresAt: &expiresAt,
+			},
+		},
+		{
+			Name: "Expired",
+			Entry: entities.WaitlistEntry{
+				EventID:        1,
+				UserID:         123,
+				Quantity:       2,
+				OfferToken:     "token",
+				OfferExpiresAt: now.Add(-time.Minute),
+			},
+			Expected: api.WaitlistEntryResponse{EventID: 1, Status: "expired", Quantity: 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			actual := api.MapToWaitlistEntryResponse(tc.Entry, now)
+			assert.Equal(t, tc.Expected, actual)
+		})
+	}
+}
+
+func TestMapToTicketTransfersResponse(t *testing.T) {
+	createdAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	updatedAt, _ := time.Parse(time.RFC3339, "2020-01-01T01:00:00Z")
+	transfers := []entities.TicketTransfer{
+		{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "accepted", CreatedAt: createdAt, UpdatedAt: updatedAt},
+	}
+	expected := api.TicketTransfersResponse{
+		Transfers: []api.TicketTransferResponse{
+			{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "accepted", CreatedAt: createdAt, UpdatedAt: updatedAt},
+		},
+	}
+
+	actual := api.MapToTicketTransfersResponse(transfers)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapToResaleListingsResponse(t *testing.T) {
+	createdAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	listings := []entities.ResaleListing{
+		{
+			ID:        4,
+			TicketID:  1,
+			SellerID:  2,
+			Price:     money.New(1500, "USD"),
+			Status:    "active",
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		},
+	}
+	expected := api.ResaleListingsResponse{
+		Listings: []api.ResaleListingResponse{
+			{
+				ID:        4,
+				TicketID:  1,
+				SellerID:  2,
+				Price:     api.Money{Amount: 1500, Currency: "USD"},
+				Status:    "active",
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+		},
+	}
+
+	actual := api.MapToResaleListingsResponse(listings)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapToTicketCredentialResponse(t *testing.T) {
+	rotatedAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	credential := entities.TicketCredential{
+		TicketID:  1,
+		EventID:   2,
+		OwnerID:   3,
+		Nonce:     "abc",
+		RotatedAt: rotatedAt,
+		Token:     "payload.signature",
+	}
+	expected := api.TicketCredentialResponse{
+		TicketID:   1,
+		EventID:    2,
+		Credential: "payload.signature",
+		RotatedAt:  rotatedAt,
+	}
+
+	actual := api.MapToTicketCredentialResponse(credential)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapToCheckinResponse(t *testing.T) {
+	scannedAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	checkin := entities.Checkin{
+		ID:        4,
+		TicketID:  1,
+		EventID:   2,
+		OwnerID:   3,
+		Gate:      "North",
+		Device:    "scanner-1",
+		ScannedAt: scannedAt,
+	}
+	expected := api.CheckinResponse{
+		ID:        4,
+		TicketID:  1,
+		EventID:   2,
+		Gate:      "North",
+		Device:    "scanner-1",
+		ScannedAt: scannedAt,
+	}
+
+	actual := api.MapToCheckinResponse(checkin)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapToOfflineScans(t *testing.T) {
+	scannedAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	data := api.SyncOfflineScansRequest{
+		Device: "scanner-1",
+		Scans: []api.OfflineScan{
+			{TicketID: 1, Credential: "payload.signature", Gate: "North", ScannedAt: scannedAt},
+		},
+	}
+	expected := []entities.OfflineScan{
+		{TicketID: 1, Credential: "payload.signature", Gate: "North", Device: "scanner-1", ScannedAt: scannedAt},
+	}
+
+	actual := api.MapToOfflineScans(data)
+	assert.Equal(t, expected, actual)
+}
+
 func TestMapToEventsSearchResponse(t *testing.T) {
 	document1StartsAt, _ := time.Parse(time.RFC3339, "2024-01-01T00:00:00+00:00")
 	document1EndsAt, _ := time.Parse(time.RFC3339, "2024-01-01T03:00:00+00:00")
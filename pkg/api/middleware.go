@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/dslaw/book-tickets/pkg/services"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1024 * 1024
+)
+
+// isMutating is whether requests with the method can change state, and so
+// should only be processed once.
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// fingerprintRequest hashes the parts of a request that determine its
+// response, so that a key reused for a different request can be detected.
+func fingerprintRequest(method string, u url.URL, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(u.RequestURI()))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// humaContext is embedded in `recordingContext` under a name that doesn't
+// clash with the `Context` method.
+type humaContext = huma.Context
+
+// recordingContext replays the already read request body to the handler, and
+// records the response written by the handler as it's written.
+type recordingContext struct {
+	humaContext
+	body        io.Reader
+	contentType string
+	response    bytes.Buffer
+}
+
+func (c *recordingContext) BodyReader() io.Reader {
+	return c.body
+}
+
+func (c *recordingContext) SetHeader(name, value string) {
+	if http.CanonicalHeaderKey(name) == "Content-Type" {
+		c.contentType = value
+	}
+	c.humaContext.SetHeader(name, value)
+}
+
+func (c *recordingContext) BodyWriter() io.Writer {
+	return io.MultiWriter(c.humaContext.BodyWriter(), &c.response)
+}
+
+// NewIdempotencyMiddleware makes middleware that processes mutating requests
+// made with an `Idempotency-Key` header at most once per key and user. Retries
+// with the same key are given the original response, and requests that reuse
+// a key for a different request are rejected. Responses to requests that fail
+// with a server error aren't kept, so that those requests can be retried. The
+// middleware must be added before handlers are registered.
+func NewIdempotencyMiddleware(api huma.API, service *services.IdempotencyService) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		key := ctx.Header(IdempotencyKeyHeader)
+		if key == "" || !isMutating(ctx.Method()) {
+			next(ctx)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			huma.WriteErr(api, ctx, http.StatusUnprocessableEntity, "Idempotency key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(ctx.BodyReader(), maxIdempotentRequestBytes+1))
+		if err != nil {
+			huma.WriteErr(api, ctx, http.StatusBadRequest, "Unable to read request body")
+			return
+		}
+		if len(body) > maxIdempotentRequestBytes {
+			huma.WriteErr(api, ctx, http.StatusRequestEntityTooLarge, "Request body is too large")
+			return
+		}
+
+		userID := ctx.Header("x-user-id")
+		fingerprint := fingerprintRequest(ctx.Method(), ctx.URL(), body)
+		record, replay, err := service.Begin(ctx.Context(), userID, key, fingerprint)
+		if err != nil {
+			if errors.Is(err, services.ErrIdempotencyKeyMismatch) {
+				huma.WriteErr(api, ctx, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			if errors.Is(err, services.ErrIdempotencyKeyInProgress) {
+				huma.WriteErr(api, ctx, http.StatusConflict, err.Error())
+				return
+			}
+
+			slog.Error("Issue claiming idempotency key", "idempotency_key", key, "error", err)
+			huma.WriteErr(api, ctx, http.StatusInternalServerError, "")
+			return
+		}
+
+		if replay {
+			if record.ContentType != "" {
+				ctx.SetHeader("Content-Type", record.ContentType)
+			}
+			ctx.SetHeader(IdempotentReplayedHeader, "true")
+			ctx.SetStatus(record.StatusCode)
+			ctx.BodyWriter().Write(record.ResponseBody)
+			return
+		}
+
+		recorder := &recordingContext{humaContext: ctx, body: bytes.NewReader(body)}
+		next(recorder)
+
+		// Record the response even if the request has been cancelled, as the
+		// request may have been processed regardless.
+		recordCtx := context.WithoutCancel(ctx.Context())
+		status := ctx.Status()
+		if status == 0 || status >= http.StatusInternalServerError {
+			if err := service.Release(recordCtx, record.ID); err != nil {
+				slog.Error("Issue releasing idempotency key", "idempotency_key", key, "error", err)
+			}
+			return
+		}
+
+		record.StatusCode = status
+		record.ContentType = recorder.contentType
+		record.ResponseBody = recorder.response.Bytes()
+		if err := service.Complete(recordCtx, record); err != nil {
+			slog.Error("Issue recording idempotent response", "idempotency_key", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	pkgApi "github.com/dslaw/book-tickets/pkg/api"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdempotencyRepo keeps idempotency keys in memory, without expiring
+// them.
+type memoryIdempotencyRepo struct {
+	keys map[string]entities.IdempotencyKey
+}
+
+func (r *memoryIdempotencyRepo) ClaimIdempotencyKey(
+	ctx context.Context,
+	key entities.IdempotencyKey,
+	expiresAt time.Time,
+) (int32, bool, error) {
+	if _, ok := r.keys[key.Key]; ok {
+		return 0, false, nil
+	}
+	key.ID = int32(len(r.keys) + 1)
+	r.keys[key.Key] = key
+	return key.ID, true, nil
+}
+
+func (r *memoryIdempotencyRepo) GetIdempotencyKey(ctx context.Context, userID string, key string) (entities.IdempotencyKey, error) {
+	record, ok := r.keys[key]
+	if !ok {
+		return entities.IdempotencyKey{}, repos.ErrNoSuchEntity
+	}
+	return record, nil
+}
+
+func (r *memoryIdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, key entities.IdempotencyKey) error {
+	r.keys[key.Key] = key
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, id int32) error {
+	for key, record := range r.keys {
+		if record.ID == id {
+			delete(r.keys, key)
+		}
+	}
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+type countRequest struct {
+	Body struct {
+		Name string `json:"name"`
+	}
+}
+
+// createAPIForIdempotency creates an API with an endpoint that counts how many
+// times it's been called, and fails if the name given is "fail".
+func createAPIForIdempotency(t *testing.T, count *int) humatest.TestAPI {
+	service := services.NewIdempotencyService(
+		&memoryIdempotencyRepo{keys: make(map[string]entities.IdempotencyKey)},
+		time.Hour,
+	)
+	_, api := humatest.New(t)
+	api.UseMiddleware(pkgApi.NewIdempotencyMiddleware(api, service))
+
+	huma.Post(api, "/count", func(ctx context.Context, input *countRequest) (*pkgApi.ResponseEnvelope, error) {
+		*count++
+		if input.Body.Name == "fail" {
+			return nil, huma.Error503ServiceUnavailable("")
+		}
+		return &pkgApi.ResponseEnvelope{Body: map[string]int{"count": *count}}, nil
+	})
+	return api
+}
+
+func TestIdempotencyMiddlewareReplaysResponse(t *testing.T) {
+	count := 0
+	api := createAPIForIdempotency(t, &count)
+	body := map[string]string{"name": "a"}
+
+	first := api.Post("/count", "Idempotency-Key: abc", body)
+	require.Equal(t, http.StatusOK, first.Code)
+
+	second := api.Post("/count", "Idempotency-Key: abc", body)
+	require.Equal(t, http.StatusOK, second.Code)
+
+	assert.Equal(t, 1, count)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(pkgApi.IdempotentReplayedHeader))
+}
+
+func TestIdempotencyMiddlewareWhenPayloadDiffers(t *testing.T) {
+	count := 0
+	api := createAPIForIdempotency(t, &count)
+
+	response := api.Post("/count", "Idempotency-Key: abc", map[string]string{"name": "a"})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = api.Post("/count", "Idempotency-Key: abc", map[string]string{"name": "b"})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, 1, count)
+}
+
+func TestIdempotencyMiddlewareWhenServerError(t *testing.T) {
+	count := 0
+	api := createAPIForIdempotency(t, &count)
+	body := map[string]string{"name": "fail"}
+
+	response := api.Post("/count", "Idempotency-Key: abc", body)
+	require.Equal(t, http.StatusServiceUnavailable, response.Code)
+
+	// The key is released, so that the request can be retried.
+	response = api.Post("/count", "Idempotency-Key: abc", body)
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, 2, count)
+}
+
+func TestIdempotencyMiddlewareWithoutKey(t *testing.T) {
+	count := 0
+	api := createAPIForIdempotency(t, &count)
+	body := map[string]string{"name": "a"}
+
+	api.Post("/count", body)
+	api.Post("/count", body)
+
+	assert.Equal(t, 2, count)
+}
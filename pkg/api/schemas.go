@@ -33,17 +33,76 @@ type GetVenueResponse struct {
 	} `json:"location"`
 }
 
+type WriteVenueSeat struct {
+	Number     string  `json:"number" minLength:"1" maxLength:"10"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Accessible bool    `json:"accessible" required:"false"`
+	Obstructed bool    `json:"obstructed" required:"false"`
+}
+
+type WriteVenueRow struct {
+	Name  string           `json:"name" minLength:"1" maxLength:"10"`
+	Seats []WriteVenueSeat `json:"seats" minItems:"1"`
+}
+
+type WriteVenueSection struct {
+	Name string          `json:"name" minLength:"1" maxLength:"40"`
+	Rows []WriteVenueRow `json:"rows" minItems:"1"`
+}
+
+// WriteVenueLayoutRequest replaces a venue's seating layout. Seats are
+// positioned by their coordinates on the venue's seating chart, and are
+// matched to the existing layout by section name, row name and seat number.
+type WriteVenueLayoutRequest struct {
+	Sections []WriteVenueSection `json:"sections"`
+}
+
+type VenueSeatResponse struct {
+	ID         int32   `json:"id"`
+	Number     string  `json:"number"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Accessible bool    `json:"accessible"`
+	Obstructed bool    `json:"obstructed"`
+}
+
+type VenueRowResponse struct {
+	ID    int32               `json:"id"`
+	Name  string              `json:"name"`
+	Seats []VenueSeatResponse `json:"seats"`
+}
+
+type VenueSectionResponse struct {
+	ID   int32              `json:"id"`
+	Name string             `json:"name"`
+	Rows []VenueRowResponse `json:"rows"`
+}
+
+type VenueLayoutResponse struct {
+	VenueID  int32                  `json:"venue_id"`
+	Sections []VenueSectionResponse `json:"sections"`
+}
+
 type WritePerformerRequest struct {
 	Name string `json:"name" minLength:"1" maxLength:"50"`
 }
 
+// WriteEventRequest is an event, optionally with a limit on the number of its
+// tickets each user can hold and purchase. The event has no limit if
+// `max_tickets_per_user` is zero or not given. The price of a resale listing
+// of the event's tickets can be bounded by rates of the ticket's face value, in
+// basis points, where a rate that's zero or not given is unbounded.
 type WriteEventRequest struct {
-	VenueID     int32                   `json:"venue_id"`
-	Name        string                  `json:"name" minLength:"1" maxLength:"50"`
-	Description string                  `json:"description" required:"false" maxLength:"200"`
-	StartsAt    time.Time               `json:"starts_at"`
-	EndsAt      time.Time               `json:"ends_at"`
-	Performers  []WritePerformerRequest `json:"performers"`
+	VenueID           int32                   `json:"venue_id"`
+	Name              string                  `json:"name" minLength:"1" maxLength:"50"`
+	Description       string                  `json:"description" required:"false" maxLength:"200"`
+	StartsAt          time.Time               `json:"starts_at"`
+	EndsAt            time.Time               `json:"ends_at"`
+	Performers        []WritePerformerRequest `json:"performers"`
+	MaxTicketsPerUser int32                   `json:"max_tickets_per_user" required:"false" minimum:"0"`
+	ResaleMinRate     int32                   `json:"resale_min_rate" required:"false" minimum:"0"`
+	ResaleMaxRate     int32                   `json:"resale_max_rate" required:"false" minimum:"0"`
 }
 
 type CreateEventResponse struct {
@@ -61,33 +120,157 @@ type EventPerformerResponse struct {
 }
 
 type GetEventResponse struct {
-	ID          int32                    `json:"id"`
-	Name        string                   `json:"name"`
-	Description string                   `json:"description"`
-	StartsAt    time.Time                `json:"starts_at"`
-	EndsAt      time.Time                `json:"ends_at"`
-	Venue       EventVenueResponse       `json:"venue"`
-	Performers  []EventPerformerResponse `json:"performers"`
+	ID                int32                    `json:"id"`
+	Name              string                   `json:"name"`
+	Description       string                   `json:"description"`
+	StartsAt          time.Time                `json:"starts_at"`
+	EndsAt            time.Time                `json:"ends_at"`
+	Venue             EventVenueResponse       `json:"venue"`
+	Performers        []EventPerformerResponse `json:"performers"`
+	MaxTicketsPerUser int32                    `json:"max_tickets_per_user,omitempty"`
+	ResaleMinRate     int32                    `json:"resale_min_rate,omitempty"`
+	ResaleMaxRate     int32                    `json:"resale_max_rate,omitempty"`
 }
 
+// Money is an amount in the minor units of an ISO 4217 currency, e.g. cents
+// for USD.
+type Money struct {
+	Amount   int64  `json:"amount" minimum:"0"`
+	Currency string `json:"currency" pattern:"^[A-Z]{3}$"`
+}
+
+// WriteTicketRelease releases `number` tickets labelled with the seat, or one
+// ticket for each of the seats of the venue's layout given by `seat_ids`. The
+// tickets for seats of the layout are labelled with the seat's section, unless
+// a label is given. Tickets are either given a price, or released against one
+// of the event's ticket types and priced at the type's price.
 type WriteTicketRelease struct {
-	Number uint8  `json:"number" minimum:"0"`
-	Seat   string `json:"seat" minLength:"1" maxLength:"10"`
-	Price  uint8  `json:"price" minimum:"0"`
+	Number       uint8   `json:"number" required:"false" minimum:"0"`
+	Seat         string  `json:"seat" required:"false" minLength:"1" maxLength:"40"`
+	SeatIDs      []int32 `json:"seat_ids" required:"false"`
+	Price        *Money  `json:"price" required:"false"`
+	TicketTypeID int32   `json:"ticket_type_id" required:"false"`
+}
+
+// WritePresale gives early access to a release's tickets within its window, to
+// users that give the access code, or whose ids are given in `user_ids`.
+type WritePresale struct {
+	Name       string    `json:"name" minLength:"1" maxLength:"40"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	AccessCode string    `json:"access_code" required:"false" minLength:"1" maxLength:"40"`
+	UserIDs    []int32   `json:"user_ids" required:"false"`
 }
 
+// WriteTicketReleaseRequest releases tickets for an event. The tickets are on
+// sale from `on_sale_at` until `off_sale_at`, if given, and are otherwise on
+// sale as soon as they're released. Presales give early access to the tickets.
 type WriteTicketReleaseRequest struct {
 	TicketReleases []WriteTicketRelease `json:"ticket_releases"`
+	OnSaleAt       time.Time            `json:"on_sale_at" required:"false"`
+	OffSaleAt      time.Time            `json:"off_sale_at" required:"false"`
+	Presales       []WritePresale       `json:"presales" required:"false"`
 }
 
 type GetAvailableTicketsAggregate struct {
-	Seat      string  `json:"seat"`
-	Price     uint8   `json:"price"`
-	TicketIDs []int32 `json:"ticket_ids"`
+	Seat         string  `json:"seat"`
+	TicketTypeID int32   `json:"ticket_type_id,omitempty"`
+	ReleaseID    int32   `json:"release_id,omitempty"`
+	Price        Money   `json:"price"`
+	TicketIDs    []int32 `json:"ticket_ids"`
+}
+
+type GetPresaleResponse struct {
+	Name     string    `json:"name"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type GetTicketReleaseResponse struct {
+	ID        int32                `json:"id"`
+	OnSaleAt  *time.Time           `json:"on_sale_at,omitempty"`
+	OffSaleAt *time.Time           `json:"off_sale_at,omitempty"`
+	Presales  []GetPresaleResponse `json:"presales"`
+}
+
+type GetAvailableGATier struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	Price     Money  `json:"price"`
+	Remaining int32  `json:"remaining"`
+}
+
+// GetAvailableResaleListing is a resale listing of a ticket, priced at
+// `price`, along with the ticket's face value.
+type GetAvailableResaleListing struct {
+	ID        int32  `json:"id"`
+	TicketID  int32  `json:"ticket_id"`
+	Seat      string `json:"seat"`
+	Price     Money  `json:"price"`
+	FaceValue Money  `json:"face_value"`
 }
 
+// GetAvailableTicketsAggregateResponse is an event's available inventory. An
+// event that's sold out has no inventory available, and can be waitlisted.
+// Resale listings are available regardless, and don't count towards the event
+// being sold out.
 type GetAvailableTicketsAggregateResponse struct {
-	Available []GetAvailableTicketsAggregate `json:"available"`
+	SoldOut          bool                           `json:"sold_out"`
+	Available        []GetAvailableTicketsAggregate `json:"available"`
+	GeneralAdmission []GetAvailableGATier           `json:"general_admission"`
+	TicketTypes      []GetTicketTypeResponse        `json:"ticket_types"`
+	Releases         []GetTicketReleaseResponse     `json:"releases"`
+	Resale           []GetAvailableResaleListing    `json:"resale"`
+}
+
+// WriteTicketTypeRequest creates a ticket type for an event, e.g. "VIP" or
+// "Student". Eligibility lists the requirements a ticket's holder must meet to
+// be admitted, and the type's tickets are only on sale within the sale window,
+// where times that aren't given are unbounded.
+type WriteTicketTypeRequest struct {
+	Name         string    `json:"name" minLength:"1" maxLength:"40"`
+	Description  string    `json:"description" required:"false"`
+	Price        Money     `json:"price"`
+	Eligibility  []string  `json:"eligibility" required:"false"`
+	SaleStartsAt time.Time `json:"sale_starts_at" required:"false"`
+	SaleEndsAt   time.Time `json:"sale_ends_at" required:"false"`
+}
+
+type CreateTicketTypeResponse struct {
+	ID int32 `json:"id"`
+}
+
+type GetTicketTypeResponse struct {
+	ID           int32      `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description,omitempty"`
+	Price        Money      `json:"price"`
+	Eligibility  []string   `json:"eligibility"`
+	SaleStartsAt *time.Time `json:"sale_starts_at,omitempty"`
+	SaleEndsAt   *time.Time `json:"sale_ends_at,omitempty"`
+}
+
+// WriteGATierRequest creates a general admission tier of `capacity` tickets,
+// which are issued as they're purchased.
+type WriteGATierRequest struct {
+	Name     string `json:"name" minLength:"1" maxLength:"40"`
+	Price    Money  `json:"price"`
+	Capacity int32  `json:"capacity" minimum:"1"`
+}
+
+type CreateGATierResponse struct {
+	ID int32 `json:"id"`
+}
+
+type WriteGATierHoldRequest struct {
+	Quantity int32 `json:"quantity" minimum:"1"`
+}
+
+type GATierHoldResponse struct {
+	HoldToken string    `json:"hold_token"`
+	GATierID  int32     `json:"ga_tier_id"`
+	Quantity  int32     `json:"quantity"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type Card struct {
@@ -99,8 +282,344 @@ type Card struct {
 	CVC             string `json:"cvc" minLength:"3" maxLength:"3"`
 }
 
+type ExtendTicketHoldResponse struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type WriteTicketsHoldRequest struct {
+	TicketIDs  []int32 `json:"ticket_ids" minItems:"1"`
+	AccessCode string  `json:"access_code" required:"false" maxLength:"40"`
+}
+
+type TicketsHoldResponse struct {
+	HoldToken string    `json:"hold_token"`
+	TicketIDs []int32   `json:"ticket_ids"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BestAvailableRequest selects `quantity` adjacent seats in the same row,
+// each priced at most `max_price` if given. Seats are taken from the first of
+// `sections` that has a matching block of seats, or from any section if none
+// are given.
+type BestAvailableRequest struct {
+	Quantity int      `json:"quantity" minimum:"1" maximum:"10"`
+	MaxPrice *Money   `json:"max_price" required:"false"`
+	Sections []string `json:"sections" required:"false"`
+}
+
+type HeldSeatResponse struct {
+	TicketID int32  `json:"ticket_id"`
+	Section  string `json:"section"`
+	Row      string `json:"row"`
+	Number   string `json:"number"`
+	Price    Money  `json:"price"`
+}
+
+type BestAvailableResponse struct {
+	HoldToken string             `json:"hold_token"`
+	TicketIDs []int32            `json:"ticket_ids"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	Seats     []HeldSeatResponse `json:"seats"`
+}
+
+type QuoteTicketsRequest struct {
+	TicketIDs []int32 `json:"ticket_ids" minItems:"1"`
+	PromoCode string  `json:"promo_code" required:"false" maxLength:"40"`
+}
+
+type QuoteItemResponse struct {
+	TicketID   int32 `json:"ticket_id"`
+	FaceValue  Money `json:"face_value"`
+	Discount   Money `json:"discount"`
+	ServiceFee Money `json:"service_fee"`
+	Tax        Money `json:"tax"`
+	Total      Money `json:"total"`
+}
+
+type QuoteResponse struct {
+	Items       []QuoteItemResponse `json:"items"`
+	FaceValue   Money               `json:"face_value"`
+	Discount    Money               `json:"discount"`
+	ServiceFees Money               `json:"service_fees"`
+	FacilityFee Money               `json:"facility_fee"`
+	Tax         Money               `json:"tax"`
+	Total       Money               `json:"total"`
+}
+
+type PurchaseTicketRequest struct {
+	Card Card `json:"card"`
+}
+
+type PurchaseTicketsHoldRequest struct {
+	HoldToken  string `json:"hold_token" minLength:"1"`
+	Card       Card   `json:"card"`
+	PromoCode  string `json:"promo_code" required:"false" maxLength:"40"`
+	AccessCode string `json:"access_code" required:"false" maxLength:"40"`
+}
+
 type PaymentResponse struct {
-	Success bool `json:"success"`
+	Success       bool   `json:"success"`
+	OrderID       int32  `json:"order_id,omitempty"`
+	DeclineReason string `json:"decline_reason,omitempty"`
+}
+
+type RefundResponse struct {
+	ID         int32  `json:"id"`
+	TicketID   int32  `json:"ticket_id"`
+	Amount     Money  `json:"amount"`
+	Status     string `json:"status" enum:"pending,completed,failed"`
+	Rereleased bool   `json:"rereleased"`
+}
+
+type OrderItemResponse struct {
+	TicketID   int32 `json:"ticket_id"`
+	Price      Money `json:"price"`
+	Discount   Money `json:"discount"`
+	ServiceFee Money `json:"service_fee"`
+	Tax        Money `json:"tax"`
+}
+
+type GetOrderResponse struct {
+	ID          int32               `json:"id"`
+	PurchaserID int32               `json:"purchaser_id"`
+	PaymentID   int32               `json:"payment_id"`
+	Status      string              `json:"status"`
+	Total       Money               `json:"total"`
+	PromoCodeID int32               `json:"promo_code_id,omitempty"`
+	Discount    Money               `json:"discount"`
+	FacilityFee Money               `json:"facility_fee"`
+	Tax         Money               `json:"tax"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	Items       []OrderItemResponse `json:"items"`
+}
+
+type GetUserOrdersResponse struct {
+	Orders []GetOrderResponse `json:"orders"`
+}
+
+// FeeRule is the fees charged on a purchase. Service fees are charged per
+// ticket, as a rate of the ticket's face value in basis points plus a flat
+// amount, and the facility fee per order. Amounts are in the minor units of the
+// event's currency.
+type FeeRule struct {
+	ServiceFeeRate int32 `json:"service_fee_rate" minimum:"0" maximum:"10000"`
+	ServiceFeeFlat int64 `json:"service_fee_flat" minimum:"0"`
+	FacilityFee    int64 `json:"facility_fee" minimum:"0"`
+}
+
+// WriteTaxRateRequest sets the sales tax rate, in basis points, for a region.
+// Omitting the subdivision sets the rate for the whole country.
+type WriteTaxRateRequest struct {
+	CountryCode string `json:"country_code" minLength:"3" maxLength:"3"`
+	Subdivision string `json:"subdivision" required:"false" maxLength:"60"`
+	Rate        int32  `json:"rate" minimum:"0" maximum:"10000"`
+}
+
+// WritePromoCodeRequest creates or updates a promo code. A percentage discount
+// is in basis points off of each ticket's face value, and a fixed discount is
+// an amount off of the order's face value. The code applies to all events if
+// the event isn't given, and times and limits that aren't given are unbounded.
+type WritePromoCodeRequest struct {
+	Code                  string    `json:"code" minLength:"1" maxLength:"40" pattern:"^[A-Za-z0-9_-]+$"`
+	DiscountType          string    `json:"discount_type" enum:"percentage,fixed"`
+	PercentOff            int32     `json:"percent_off" required:"false" minimum:"0" maximum:"10000"`
+	AmountOff             *Money    `json:"amount_off" required:"false"`
+	EventID               int32     `json:"event_id" required:"false"`
+	StartsAt              time.Time `json:"starts_at" required:"false"`
+	EndsAt                time.Time `json:"ends_at" required:"false"`
+	MaxRedemptions        int32     `json:"max_redemptions" required:"false" minimum:"0"`
+	MaxRedemptionsPerUser int32     `json:"max_redemptions_per_user" required:"false" minimum:"0"`
+}
+
+type CreatePromoCodeResponse struct {
+	ID int32 `json:"id"`
+}
+
+type GetPromoCodeResponse struct {
+	ID                    int32      `json:"id"`
+	Code                  string     `json:"code"`
+	DiscountType          string     `json:"discount_type"`
+	PercentOff            int32      `json:"percent_off,omitempty"`
+	AmountOff             *Money     `json:"amount_off,omitempty"`
+	EventID               int32      `json:"event_id,omitempty"`
+	StartsAt              *time.Time `json:"starts_at,omitempty"`
+	EndsAt                *time.Time `json:"ends_at,omitempty"`
+	MaxRedemptions        int32      `json:"max_redemptions,omitempty"`
+	MaxRedemptionsPerUser int32      `json:"max_redemptions_per_user,omitempty"`
+	Redemptions           int32      `json:"redemptions"`
+}
+
+type EventCancellationResponse struct {
+	EventID   int32     `json:"event_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Refunds   struct {
+		Total    int64 `json:"total"`
+		Pending  int64 `json:"pending"`
+		Refunded int64 `json:"refunded"`
+		Failed   int64 `json:"failed"`
+		Skipped  int64 `json:"skipped"`
+	} `json:"refunds"`
+}
+
+// WriteWaitingRoomRequest enables an event's waiting room, which admits
+// `batch_size` users from its queue at a time.
+type WriteWaitingRoomRequest struct {
+	BatchSize int32 `json:"batch_size" minimum:"1"`
+}
+
+type GetWaitingRoomResponse struct {
+	EventID   int32 `json:"event_id"`
+	BatchSize int32 `json:"batch_size"`
+}
+
+// QueuePositionResponse is a user's position in an event's queue. Once
+// admitted, the user is given an admission token to hold the event's tickets
+// with, until it expires.
+type QueuePositionResponse struct {
+	EventID        int32      `json:"event_id"`
+	Position       int64      `json:"position"`
+	Admitted       bool       `json:"admitted"`
+	AdmissionToken string     `json:"admission_token,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+}
+
+// JoinWaitlistRequest joins a sold-out event's waitlist, waiting for
+// `quantity` tickets.
+type JoinWaitlistRequest struct {
+	Quantity int32 `json:"quantity" minimum:"1"`
+}
+
+// WaitlistEntryResponse is a user's place on an event's waitlist. Once offered
+// tickets, the user is given the token of a purchase hold on them, which is
+// theirs until it expires.
+type WaitlistEntryResponse struct {
+	EventID        int32      `json:"event_id"`
+	Status         string     `json:"status" enum:"waiting,offered,expired"`
+	Position       int32      `json:"position,omitempty"`
+	Quantity       int32      `json:"quantity"`
+	OfferToken     string     `json:"offer_token,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+}
+
+// CreateTicketTransferRequest transfers a purchased ticket to another user,
+// who takes ownership of it once they accept the transfer.
+type CreateTicketTransferRequest struct {
+	RecipientID int32 `json:"recipient_id"`
+}
+
+type TicketTransferResponse struct {
+	ID          int32     `json:"id"`
+	TicketID    int32     `json:"ticket_id"`
+	SenderID    int32     `json:"sender_id"`
+	RecipientID int32     `json:"recipient_id"`
+	Status      string    `json:"status" enum:"pending,accepted,cancelled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type TicketTransfersResponse struct {
+	Transfers []TicketTransferResponse `json:"transfers"`
+}
+
+// CreateResaleListingRequest lists a purchased ticket for resale at `price`,
+// which must be in the currency of the ticket's face value and within its
+// event's resale rates of it.
+type CreateResaleListingRequest struct {
+	Price Money `json:"price"`
+}
+
+type ResaleListingResponse struct {
+	ID        int32     `json:"id"`
+	TicketID  int32     `json:"ticket_id"`
+	SellerID  int32     `json:"seller_id"`
+	Price     Money     `json:"price"`
+	Status    string    `json:"status" enum:"active,sold,cancelled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ResaleListingsResponse struct {
+	Listings []ResaleListingResponse `json:"listings"`
+}
+
+// TicketCredentialResponse is a ticket's signed credential, presented by its
+// owner for entry. The credential stops being current when it is rotated.
+type TicketCredentialResponse struct {
+	TicketID   int32     `json:"ticket_id"`
+	EventID    int32     `json:"event_id"`
+	Credential string    `json:"credential"`
+	RotatedAt  time.Time `json:"rotated_at"`
+}
+
+// CredentialsPublicKeyResponse is the public key that ticket credentials are
+// verified with, base64 encoded.
+type CredentialsPublicKeyResponse struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+}
+
+// CreateCheckinRequest checks in a ticket to the event, given the ticket's
+// credential as scanned by the device at the gate.
+type CreateCheckinRequest struct {
+	TicketID   int32  `json:"ticket_id"`
+	Credential string `json:"credential" minLength:"1"`
+	Gate       string `json:"gate" minLength:"1" maxLength:"50"`
+	Device     string `json:"device" minLength:"1" maxLength:"100"`
+}
+
+type CheckinResponse struct {
+	ID        int32     `json:"id"`
+	TicketID  int32     `json:"ticket_id"`
+	EventID   int32     `json:"event_id"`
+	Gate      string    `json:"gate"`
+	Device    string    `json:"device"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+type EventAttendanceResponse struct {
+	EventID   int32 `json:"event_id"`
+	CheckedIn int64 `json:"checked_in"`
+	Purchased int64 `json:"purchased"`
+}
+
+// ScannerBundleResponse is a signed bundle of an event's purchased and
+// revoked tickets, for scanners to check credentials against while offline.
+// The bundle is verified with the same public key as credentials.
+type ScannerBundleResponse struct {
+	EventID  int32     `json:"event_id"`
+	IssuedAt time.Time `json:"issued_at"`
+	Tickets  int       `json:"tickets"`
+	Revoked  int       `json:"revoked"`
+	Bundle   string    `json:"bundle"`
+}
+
+type OfflineScan struct {
+	TicketID   int32     `json:"ticket_id"`
+	Credential string    `json:"credential" minLength:"1"`
+	Gate       string    `json:"gate" minLength:"1" maxLength:"50"`
+	ScannedAt  time.Time `json:"scanned_at"`
+}
+
+// SyncOfflineScansRequest uploads the scans made by the device while it was
+// offline, to be reconciled with the event's check-ins, along with the signed
+// bundle that the device checked them against.
+type SyncOfflineScansRequest struct {
+	Device string        `json:"device" minLength:"1" maxLength:"100"`
+	Bundle string        `json:"bundle" minLength:"1"`
+	Scans  []OfflineScan `json:"scans" minItems:"1" maxItems:"1000"`
+}
+
+type ScanResultResponse struct {
+	TicketID int32  `json:"ticket_id"`
+	Status   string `json:"status" enum:"admitted,duplicate,rejected"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type ScanResultsResponse struct {
+	Results []ScanResultResponse `json:"results"`
 }
 
 type EventSearchResult struct {
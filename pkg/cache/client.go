@@ -14,7 +14,199 @@ type CacheClienter interface {
 	Get(context.Context, string) (string, error)
 	Set(context.Context, string, string, time.Duration) error
 	GetMany(context.Context, ...string) (map[string]string, error)
+	SetMany(context.Context, map[string]string, time.Duration) error
+	CompareAndDelete(context.Context, string, string) error
+	CompareAndExtend(context.Context, string, string, time.Duration, int) error
+	ReleaseHold(context.Context, map[string]string, string, []string) error
+	ExtendHold(context.Context, map[string]string, string, []string, time.Duration, int) error
 	MakeKey(int32) string
+	MakeHoldKey(string) string
+	MakeLockKey(int32) string
+	ReserveQuantity(context.Context, string, string, int, int, time.Duration) error
+	ReleaseQuantity(context.Context, string, string) error
+	GetReservedQuantity(context.Context, string) (int, error)
+	MakeReservationsKey(int32) string
+	MakeLimitKey(int32, string) string
+}
+
+// setManyScript sets all of the given keys, only if none of them already
+// exist. Values are given by `ARGV` in the same order as `KEYS`, with the
+// expiration in milliseconds as the last argument.
+var setManyScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+    if redis.call("EXISTS", key) == 1 then
+        return 0
+    end
+end
+for idx, key in ipairs(KEYS) do
+    redis.call("SET", key, ARGV[idx], "PX", ARGV[#ARGV])
+end
+return 1
+`)
+
+// compareAndDeleteScript deletes the key, and its extensions counter, only if
+// the key's value matches `ARGV[1]`.
+var compareAndDeleteScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value then
+    return -1
+end
+if value ~= ARGV[1] then
+    return 0
+end
+redis.call("DEL", KEYS[1], KEYS[2])
+return 1
+`)
+
+// compareAndExtendScript resets the key's expiration to `ARGV[2]`
+// milliseconds, only if the key's value matches `ARGV[1]` and the key has been
+// extended fewer than `ARGV[3]` times. The number of extensions is tracked by
+// a counter key, which expires along with the key.
+var compareAndExtendScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value then
+    return -1
+end
+if value ~= ARGV[1] then
+    return 0
+end
+local count = tonumber(redis.call("GET", KEYS[2]) or "0")
+if count >= tonumber(ARGV[3]) then
+    return -2
+end
+redis.call("SET", KEYS[2], count + 1, "PX", ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1
+`)
+
+// compareHoldKeys is shared by the hold scripts, and checks that each of the
+// first `ARGV[1]` keys is set to its value. The values are given by `ARGV` in
+// the same order as `KEYS`, following the first `ARGV[2]` arguments.
+const compareHoldKeys = `
+local count = tonumber(ARGV[1])
+local offset = tonumber(ARGV[2])
+for idx = 1, count do
+    local value = redis.call("GET", KEYS[idx])
+    if not value then
+        return -1
+    end
+    if value ~= ARGV[offset + idx] then
+        return 0
+    end
+end
+`
+
+// releaseHoldScript deletes all of a hold's keys, along with their extensions
+// counters, only if each key is set to its value. The hold's reservations,
+// under the reservations keys that follow the extensions counters in `KEYS`,
+// are removed, returning their quantity. The hold's token is given by
+// `ARGV[3]`.
+var releaseHoldScript = redis.NewScript(compareHoldKeys + `
+for idx = 1, 2 * count do
+    redis.call("DEL", KEYS[idx])
+end
+for idx = 2 * count + 1, #KEYS, 3 do
+    local quantity = redis.call("HGET", KEYS[idx + 2], ARGV[3])
+    if quantity then
+        redis.call("DECRBY", KEYS[idx], quantity)
+        redis.call("HDEL", KEYS[idx + 2], ARGV[3])
+    end
+    redis.call("ZREM", KEYS[idx + 1], ARGV[3])
+end
+return 1
+`)
+
+// extendHoldScript resets the expiration of all of a hold's keys to `ARGV[4]`
+// milliseconds, only if each key is set to its value and none of the keys
+// have been extended `ARGV[5]` times. The hold's reservations, under the
+// reservations keys that follow the extensions counters in `KEYS`, are
+// extended along with the keys. The hold's token is given by `ARGV[3]`.
+var extendHoldScript = redis.NewScript(compareHoldKeys + `
+for idx = count + 1, 2 * count do
+    if tonumber(redis.call("GET", KEYS[idx]) or "0") >= tonumber(ARGV[5]) then
+        return -2
+    end
+end
+for idx = 1, count do
+    local extensions = tonumber(redis.call("GET", KEYS[count + idx]) or "0")
+    redis.call("SET", KEYS[count + idx], extensions + 1, "PX", ARGV[4])
+    redis.call("PEXPIRE", KEYS[idx], ARGV[4])
+end
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+for idx = 2 * count + 1, #KEYS, 3 do
+    redis.call("ZADD", KEYS[idx + 1], "XX", now + tonumber(ARGV[4]), ARGV[3])
+end
+return 1
+`)
+
+// reclaimReservations is shared by the reservation scripts, and removes expired
+// reservations from the count of reserved quantity. The count is given by
+// `KEYS[1]`, each reservation's expiration time by the sorted set `KEYS[2]` and
+// each reservation's quantity by the hash `KEYS[3]`, all keyed by the
+// reservation's token.
+const reclaimReservations = `
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local expired = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", now)
+for _, token in ipairs(expired) do
+    local quantity = redis.call("HGET", KEYS[3], token)
+    if quantity then
+        redis.call("DECRBY", KEYS[1], quantity)
+        redis.call("HDEL", KEYS[3], token)
+    end
+end
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", now)
+`
+
+// reserveQuantityScript reserves `ARGV[2]` of a limited quantity for the
+// reservation `ARGV[1]`, for `ARGV[4]` milliseconds, only if no more than
+// `ARGV[3]` would then be reserved in total. An existing reservation with the
+// same token is replaced.
+var reserveQuantityScript = redis.NewScript(reclaimReservations + `
+local reserved = tonumber(redis.call("GET", KEYS[1]) or "0")
+local previous = tonumber(redis.call("HGET", KEYS[3], ARGV[1]) or "0")
+if reserved - previous + tonumber(ARGV[2]) > tonumber(ARGV[3]) then
+    return 0
+end
+redis.call("INCRBY", KEYS[1], tonumber(ARGV[2]) - previous)
+redis.call("HSET", KEYS[3], ARGV[1], ARGV[2])
+redis.call("ZADD", KEYS[2], now + tonumber(ARGV[4]), ARGV[1])
+return 1
+`)
+
+// releaseQuantityScript removes the reservation `ARGV[1]`, returning its
+// quantity to the limited quantity.
+var releaseQuantityScript = redis.NewScript(reclaimReservations + `
+local quantity = redis.call("HGET", KEYS[3], ARGV[1])
+if not quantity then
+    return 0
+end
+redis.call("DECRBY", KEYS[1], quantity)
+redis.call("HDEL", KEYS[3], ARGV[1])
+redis.call("ZREM", KEYS[2], ARGV[1])
+return 1
+`)
+
+// getReservedQuantityScript counts the quantity that is currently reserved.
+var getReservedQuantityScript = redis.NewScript(reclaimReservations + `
+return tonumber(redis.call("GET", KEYS[1]) or "0")
+`)
+
+// mapCompareResult maps the result of a compare-and-* script to an error.
+func mapCompareResult(result int) error {
+	switch result {
+	case 1:
+		return nil
+	case 0:
+		return ErrValueMismatch
+	case -1:
+		return ErrNotFound
+	case -2:
+		return ErrMaxExtensions
+	default:
+		return fmt.Errorf("Unexpected script result: %d", result)
+	}
 }
 
 type TicketHoldClient struct {
@@ -47,6 +239,46 @@ func (repo *TicketHoldClient) MakeKey(id int32) string {
 	return fmt.Sprintf("%s%d", repo.ticketHoldPrefix, id)
 }
 
+// MakeHoldKey creates a Redis key, i.e. a string, from a hold token.
+func (repo *TicketHoldClient) MakeHoldKey(token string) string {
+	return fmt.Sprintf("%shold:%s", repo.ticketHoldPrefix, token)
+}
+
+// MakeLockKey creates a Redis key, i.e. a string, for the purchase lock on a
+// ticket from the ticket's id.
+func (repo *TicketHoldClient) MakeLockKey(id int32) string {
+	return fmt.Sprintf("%slock:%d", repo.ticketHoldPrefix, id)
+}
+
+// MakeReservationsKey creates a Redis key, i.e. a string, for the reservations
+// of a general admission tier's quantity from the tier's id.
+func (repo *TicketHoldClient) MakeReservationsKey(id int32) string {
+	return fmt.Sprintf("%sga:%d", repo.ticketHoldPrefix, id)
+}
+
+// MakeLimitKey creates a Redis key, i.e. a string, for the reservations of a
+// holder against an event's limit on tickets per user, from the event's id and
+// the holder's id.
+func (repo *TicketHoldClient) MakeLimitKey(eventID int32, holderID string) string {
+	return fmt.Sprintf("%slimit:%d:%s", repo.ticketHoldPrefix, eventID, holderID)
+}
+
+// makeReservationKeys creates the keys for the reserved count, reservation
+// expirations and reservation quantities of the given reservations key.
+func (repo *TicketHoldClient) makeReservationKeys(key string) []string {
+	return []string{
+		fmt.Sprintf("%s:reserved", key),
+		fmt.Sprintf("%s:expirations", key),
+		fmt.Sprintf("%s:quantities", key),
+	}
+}
+
+// makeExtensionsKey creates the key for counting the number of times the given
+// key's expiration has been extended.
+func (repo *TicketHoldClient) makeExtensionsKey(key string) string {
+	return fmt.Sprintf("%s:extensions", key)
+}
+
 func (repo *TicketHoldClient) ExpireAt(ctx context.Context, key string, expirationTime time.Time) error {
 	return repo.conn.ExpireAt(ctx, key, expirationTime).Err()
 }
@@ -98,3 +330,189 @@ func (repo *TicketHoldClient) GetMany(ctx context.Context, keys ...string) (map[
 	}
 	return repo.JoinMGetResults(keys, result), nil
 }
+
+// SetMany atomically sets all of the given keys to their respective values,
+// with a shared expiration. If any of the keys already exist, none are set.
+func (repo *TicketHoldClient) SetMany(
+	ctx context.Context,
+	values map[string]string,
+	expiration time.Duration,
+) error {
+	keys := make([]string, 0, len(values))
+	args := make([]interface{}, 0, len(values)+1)
+	for key, value := range values {
+		keys = append(keys, key)
+		args = append(args, value)
+	}
+	args = append(args, expiration.Milliseconds())
+
+	keysSet, err := setManyScript.Run(ctx, repo.conn, keys, args...).Int()
+	if err != nil {
+		return err
+	}
+	if keysSet == 0 {
+		return ErrAlreadyHasHold
+	}
+
+	return nil
+}
+
+// CompareAndDelete atomically deletes the key, only if its current value
+// matches the given value.
+func (repo *TicketHoldClient) CompareAndDelete(ctx context.Context, key string, value string) error {
+	keys := []string{key, repo.makeExtensionsKey(key)}
+	result, err := compareAndDeleteScript.Run(ctx, repo.conn, keys, value).Int()
+	if err != nil {
+		return err
+	}
+	return mapCompareResult(result)
+}
+
+// CompareAndExtend atomically resets the key's expiration, only if its current
+// value matches the given value and it has been extended fewer than
+// `maxExtensions` times.
+func (repo *TicketHoldClient) CompareAndExtend(
+	ctx context.Context,
+	key string,
+	value string,
+	expiration time.Duration,
+	maxExtensions int,
+) error {
+	keys := []string{key, repo.makeExtensionsKey(key)}
+	result, err := compareAndExtendScript.Run(
+		ctx,
+		repo.conn,
+		keys,
+		value,
+		expiration.Milliseconds(),
+		maxExtensions,
+	).Int()
+	if err != nil {
+		return err
+	}
+	return mapCompareResult(result)
+}
+
+// makeHoldScriptArgs creates the keys and arguments of a hold script, for the
+// hold's keys and their values, given by `holds`, and the keys that the hold's
+// reservations, made by `token`, are under. The script's own arguments follow
+// the hold's count of keys and token.
+func (repo *TicketHoldClient) makeHoldScriptArgs(
+	holds map[string]string,
+	token string,
+	reservationsKeys []string,
+	scriptArgs ...interface{},
+) ([]string, []interface{}) {
+	keys := make([]string, 0, 2*len(holds)+3*len(reservationsKeys))
+	for key := range holds {
+		keys = append(keys, key)
+	}
+	for _, key := range keys[:len(holds)] {
+		keys = append(keys, repo.makeExtensionsKey(key))
+	}
+	for _, key := range reservationsKeys {
+		keys = append(keys, repo.makeReservationKeys(key)...)
+	}
+
+	args := []interface{}{len(holds), 3 + len(scriptArgs), token}
+	args = append(args, scriptArgs...)
+	for _, key := range keys[:len(holds)] {
+		args = append(args, holds[key])
+	}
+	return keys, args
+}
+
+// ReleaseHold atomically deletes all of a hold's keys, only if each is set to
+// its value, given by `holds`. The hold's reservations, made by `token` under
+// each of `reservationsKeys`, are removed at the same time.
+func (repo *TicketHoldClient) ReleaseHold(
+	ctx context.Context,
+	holds map[string]string,
+	token string,
+	reservationsKeys []string,
+) error {
+	keys, args := repo.makeHoldScriptArgs(holds, token, reservationsKeys)
+	result, err := releaseHoldScript.Run(ctx, repo.conn, keys, args...).Int()
+	if err != nil {
+		return err
+	}
+	return mapCompareResult(result)
+}
+
+// ExtendHold atomically resets the expiration of all of a hold's keys, only if
+// each is set to its value, given by `holds`, and the hold has been extended
+// fewer than `maxExtensions` times. The hold's reservations, made by `token`
+// under each of `reservationsKeys`, are extended at the same time.
+func (repo *TicketHoldClient) ExtendHold(
+	ctx context.Context,
+	holds map[string]string,
+	token string,
+	reservationsKeys []string,
+	expiration time.Duration,
+	maxExtensions int,
+) error {
+	keys, args := repo.makeHoldScriptArgs(
+		holds,
+		token,
+		reservationsKeys,
+		expiration.Milliseconds(),
+		maxExtensions,
+	)
+	result, err := extendHoldScript.Run(ctx, repo.conn, keys, args...).Int()
+	if err != nil {
+		return err
+	}
+	return mapCompareResult(result)
+}
+
+// ReserveQuantity atomically reserves `quantity` for the reservation given by
+// `token`, which expires after `expiration`, only if no more than `available`
+// would then be reserved under the key. Expired reservations are reclaimed
+// first, and an existing reservation with the same token is replaced, e.g. to
+// renew it.
+func (repo *TicketHoldClient) ReserveQuantity(
+	ctx context.Context,
+	key string,
+	token string,
+	quantity int,
+	available int,
+	expiration time.Duration,
+) error {
+	keys := repo.makeReservationKeys(key)
+	reserved, err := reserveQuantityScript.Run(
+		ctx,
+		repo.conn,
+		keys,
+		token,
+		quantity,
+		available,
+		expiration.Milliseconds(),
+	).Int()
+	if err != nil {
+		return err
+	}
+	if reserved == 0 {
+		return ErrInsufficientQuantity
+	}
+	return nil
+}
+
+// ReleaseQuantity atomically removes the reservation given by `token`.
+func (repo *TicketHoldClient) ReleaseQuantity(ctx context.Context, key string, token string) error {
+	keys := repo.makeReservationKeys(key)
+	released, err := releaseQuantityScript.Run(ctx, repo.conn, keys, token).Int()
+	if err != nil {
+		return err
+	}
+	if released == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetReservedQuantity counts the quantity reserved under the key by
+// reservations that haven't expired.
+func (repo *TicketHoldClient) GetReservedQuantity(ctx context.Context, key string) (int, error) {
+	keys := repo.makeReservationKeys(key)
+	return getReservedQuantityScript.Run(ctx, repo.conn, keys).Int()
+}
@@ -22,3 +22,27 @@ func TestTicketHoldRepoJoinMGetResults(t *testing.T) {
 	actual := repo.JoinMGetResults(fields, values)
 	assert.Equal(t, map[string]string{"a": "1", "c": "3"}, actual)
 }
+
+func TestTicketHoldRepoMakeHoldKey(t *testing.T) {
+	repo := cache.TicketHoldClient{}
+	actual := repo.MakeHoldKey("abc")
+	assert.Equal(t, "hold:abc", actual)
+}
+
+func TestTicketHoldRepoMakeLockKey(t *testing.T) {
+	repo := cache.TicketHoldClient{}
+	actual := repo.MakeLockKey(int32(123))
+	assert.Equal(t, "lock:123", actual)
+}
+
+func TestTicketHoldRepoMakeReservationsKey(t *testing.T) {
+	repo := cache.TicketHoldClient{}
+	actual := repo.MakeReservationsKey(int32(123))
+	assert.Equal(t, "ga:123", actual)
+}
+
+func TestTicketHoldRepoMakeLimitKey(t *testing.T) {
+	repo := cache.TicketHoldClient{}
+	actual := repo.MakeLimitKey(int32(123), "456")
+	assert.Equal(t, "limit:123:456", actual)
+}
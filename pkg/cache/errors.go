@@ -5,4 +5,8 @@ import "errors"
 var (
 	ErrAlreadyHasHold = errors.New("The ticket is already held")
 	ErrNotFound       = errors.New("The key or field was not found")
+	ErrValueMismatch  = errors.New("The key's value does not match")
+	ErrMaxExtensions  = errors.New("The key's expiration has been extended the maximum number of times")
+
+	ErrInsufficientQuantity = errors.New("Not enough of the quantity is left to reserve")
 )
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type QueueClienter interface {
+	Close() error
+	JoinQueue(context.Context, string, string) (int64, time.Time, error)
+	GetQueuePosition(context.Context, string, string) (int64, time.Time, error)
+	AdmitBatch(context.Context, string, int, time.Duration, time.Duration) (int, error)
+	MakeQueueKey(int32) string
+}
+
+// queueNow is shared by the queue scripts, and gets the current time, in
+// milliseconds, from the server so that all clients agree on it.
+const queueNow = `
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+`
+
+// queuePosition is shared by the queue scripts, and gives the position of the
+// member `ARGV[1]` in the queue `KEYS[1]`, along with when its admission
+// expires. Admitted members are kept in the sorted set `KEYS[2]`, scored by
+// when their admission expires, and have a position of zero. Members that are
+// neither queued nor admitted have a position of -1.
+const queuePosition = `
+local admittedUntil = tonumber(redis.call("ZSCORE", KEYS[2], ARGV[1]) or "0")
+if admittedUntil > now then
+    return {0, admittedUntil}
+end
+local rank = redis.call("ZRANK", KEYS[1], ARGV[1])
+if not rank then
+    return {-1, 0}
+end
+return {rank + 1, 0}
+`
+
+// joinQueueScript adds the member `ARGV[1]` to the back of the queue, unless
+// it's already queued or admitted.
+var joinQueueScript = redis.NewScript(queueNow + `
+local admitted = tonumber(redis.call("ZSCORE", KEYS[2], ARGV[1]) or "0")
+if admitted <= now then
+    redis.call("ZADD", KEYS[1], "NX", now, ARGV[1])
+end
+` + queuePosition)
+
+// getQueuePositionScript gives the position of the member `ARGV[1]`.
+var getQueuePositionScript = redis.NewScript(queueNow + queuePosition)
+
+// admitBatchScript admits up to `ARGV[1]` members from the front of the queue,
+// for `ARGV[2]` milliseconds. A batch is admitted at most once every `ARGV[3]`
+// milliseconds, as tracked by the key `KEYS[3]`, however many clients are
+// admitting batches.
+var admitBatchScript = redis.NewScript(queueNow + `
+if not redis.call("SET", KEYS[3], 1, "NX", "PX", ARGV[3]) then
+    return 0
+end
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", now)
+local popped = redis.call("ZPOPMIN", KEYS[1], ARGV[1])
+local count = 0
+for idx = 1, #popped, 2 do
+    redis.call("ZADD", KEYS[2], now + tonumber(ARGV[2]), popped[idx])
+    count = count + 1
+end
+return count
+`)
+
+type QueueClient struct {
+	conn        *redis.Client
+	queuePrefix string
+}
+
+func NewQueueClient(conn *redis.Client, queuePrefix string) *QueueClient {
+	return &QueueClient{conn: conn, queuePrefix: queuePrefix}
+}
+
+func NewQueueClientFromURL(url string, queuePrefix string) (*QueueClient, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return &QueueClient{conn: redis.NewClient(opts), queuePrefix: queuePrefix}, nil
+}
+
+// Close closes the underlying Redis connection.
+func (repo *QueueClient) Close() error {
+	return repo.conn.Close()
+}
+
+// MakeQueueKey creates a Redis key, i.e. a string, for an event's waiting room
+// queue from the event's id.
+func (repo *QueueClient) MakeQueueKey(eventID int32) string {
+	return fmt.Sprintf("%s%d", repo.queuePrefix, eventID)
+}
+
+// makeQueueKeys creates the keys for the queued members, admitted members and
+// batch admission of the given queue key.
+func (repo *QueueClient) makeQueueKeys(key string) []string {
+	return []string{
+		key,
+		fmt.Sprintf("%s:admitted", key),
+		fmt.Sprintf("%s:admitting", key),
+	}
+}
+
+// mapQueuePosition maps the result of a queue position script to the member's
+// position and admission expiration.
+func mapQueuePosition(result []int64) (int64, time.Time, error) {
+	if len(result) != 2 {
+		return 0, time.Time{}, fmt.Errorf("Unexpected script result: %v", result)
+	}
+
+	position, admittedUntil := result[0], result[1]
+	if position < 0 {
+		return 0, time.Time{}, ErrNotFound
+	}
+	if position == 0 {
+		return 0, time.UnixMilli(admittedUntil), nil
+	}
+	return position, time.Time{}, nil
+}
+
+// JoinQueue atomically adds the member to the back of the queue under the key,
+// unless it's already queued or admitted, and gives its position. The
+// position of an admitted member is zero, and is given along with when its
+// admission expires.
+func (repo *QueueClient) JoinQueue(ctx context.Context, key string, member string) (int64, time.Time, error) {
+	keys := repo.makeQueueKeys(key)
+	result, err := joinQueueScript.Run(ctx, repo.conn, keys[:2], member).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return mapQueuePosition(result)
+}
+
+// GetQueuePosition gives the member's position in the queue under the key, as
+// with JoinQueue. If the member is neither queued nor admitted, `ErrNotFound`
+// is returned.
+func (repo *QueueClient) GetQueuePosition(ctx context.Context, key string, member string) (int64, time.Time, error) {
+	keys := repo.makeQueueKeys(key)
+	result, err := getQueuePositionScript.Run(ctx, repo.conn, keys[:2], member).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return mapQueuePosition(result)
+}
+
+// AdmitBatch atomically admits up to `batchSize` members from the front of the
+// queue under the key, for `admission`, and gives how many were admitted. No
+// members are admitted if a batch was already admitted within `interval`.
+func (repo *QueueClient) AdmitBatch(
+	ctx context.Context,
+	key string,
+	batchSize int,
+	admission time.Duration,
+	interval time.Duration,
+) (int, error) {
+	keys := repo.makeQueueKeys(key)
+	return admitBatchScript.Run(
+		ctx,
+		repo.conn,
+		keys,
+		batchSize,
+		admission.Milliseconds(),
+		interval.Milliseconds(),
+	).Int()
+}
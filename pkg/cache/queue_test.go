@@ -0,0 +1,14 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueClientMakeQueueKey(t *testing.T) {
+	repo := cache.QueueClient{}
+	actual := repo.MakeQueueKey(int32(123))
+	assert.Equal(t, "123", actual)
+}
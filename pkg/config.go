@@ -7,18 +7,35 @@ import (
 )
 
 type Config struct {
-	APIVersion         string
-	DatabaseURL        string
-	Port               string
-	CacheURL           string
-	TicketHoldDuration time.Duration
-	TicketHoldPrefix   string
-	SearchURL          string
-	SearchUser         string
-	SearchPassword     string
-	SearchMaxResults   int32
-	SearchEventsIndex  string
-	SearchVenuesIndex  string
+	APIVersion              string
+	DatabaseURL             string
+	Port                    string
+	CacheURL                string
+	TicketHoldDuration      time.Duration
+	TicketHoldPrefix        string
+	TicketHoldMaxExtensions int
+	PaymentProcessor        string
+	PaymentGatewayURL       string
+	PaymentAuthMaxAge       time.Duration
+	PaymentSweepInterval    time.Duration
+	RefundRetryMaxAge       time.Duration
+	RefundRetryInterval     time.Duration
+	CancellationInterval    time.Duration
+	CancellationMaxAttempts int32
+	IdempotencyKeyTTL       time.Duration
+	WaitingRoomPrefix       string
+	WaitingRoomSecret       string
+	WaitingRoomInterval     time.Duration
+	WaitingRoomAdmission    time.Duration
+	WaitlistOfferDuration   time.Duration
+	WaitlistInterval        time.Duration
+	CredentialSigningKey    string
+	SearchURL               string
+	SearchUser              string
+	SearchPassword          string
+	SearchMaxResults        int32
+	SearchEventsIndex       string
+	SearchVenuesIndex       string
 }
 
 func NewConfig() (*Config, bool) {
@@ -51,6 +68,140 @@ func NewConfig() (*Config, bool) {
 		return nil, false
 	}
 
+	ticketHoldMaxExtensionsString, ok := os.LookupEnv("TICKET_HOLD_MAX_EXTENSIONS")
+	if !ok {
+		return nil, false
+	}
+	ticketHoldMaxExtensions, err := strconv.Atoi(ticketHoldMaxExtensionsString)
+	if err != nil {
+		return nil, false
+	}
+
+	paymentProcessor, ok := os.LookupEnv("PAYMENT_PROCESSOR")
+	if !ok {
+		return nil, false
+	}
+
+	paymentGatewayURL, ok := os.LookupEnv("PAYMENT_GATEWAY_URL")
+	if !ok {
+		return nil, false
+	}
+
+	paymentAuthMaxAgeString, ok := os.LookupEnv("PAYMENT_AUTHORIZATION_MAX_AGE")
+	if !ok {
+		return nil, false
+	}
+	paymentAuthMaxAge, err := time.ParseDuration(paymentAuthMaxAgeString)
+	if err != nil {
+		return nil, false
+	}
+
+	paymentSweepIntervalString, ok := os.LookupEnv("PAYMENT_SWEEP_INTERVAL")
+	if !ok {
+		return nil, false
+	}
+	paymentSweepInterval, err := time.ParseDuration(paymentSweepIntervalString)
+	if err != nil {
+		return nil, false
+	}
+
+	refundRetryMaxAgeString, ok := os.LookupEnv("REFUND_RETRY_MAX_AGE")
+	if !ok {
+		return nil, false
+	}
+	refundRetryMaxAge, err := time.ParseDuration(refundRetryMaxAgeString)
+	if err != nil {
+		return nil, false
+	}
+
+	refundRetryIntervalString, ok := os.LookupEnv("REFUND_RETRY_INTERVAL")
+	if !ok {
+		return nil, false
+	}
+	refundRetryInterval, err := time.ParseDuration(refundRetryIntervalString)
+	if err != nil {
+		return nil, false
+	}
+
+	cancellationIntervalString, ok := os.LookupEnv("CANCELLATION_REFUND_INTERVAL")
+	if !ok {
+		return nil, false
+	}
+	cancellationInterval, err := time.ParseDuration(cancellationIntervalString)
+	if err != nil {
+		return nil, false
+	}
+
+	cancellationMaxAttemptsString, ok := os.LookupEnv("CANCELLATION_REFUND_MAX_ATTEMPTS")
+	if !ok {
+		return nil, false
+	}
+	cancellationMaxAttemptsI64, err := strconv.ParseInt(cancellationMaxAttemptsString, 10, 32)
+	if err != nil {
+		return nil, false
+	}
+	cancellationMaxAttempts := int32(cancellationMaxAttemptsI64)
+
+	idempotencyKeyTTLString, ok := os.LookupEnv("IDEMPOTENCY_KEY_TTL")
+	if !ok {
+		return nil, false
+	}
+	idempotencyKeyTTL, err := time.ParseDuration(idempotencyKeyTTLString)
+	if err != nil {
+		return nil, false
+	}
+
+	waitingRoomPrefix, ok := os.LookupEnv("WAITING_ROOM_PREFIX")
+	if !ok {
+		return nil, false
+	}
+
+	waitingRoomSecret, ok := os.LookupEnv("WAITING_ROOM_SECRET")
+	if !ok {
+		return nil, false
+	}
+
+	waitingRoomIntervalString, ok := os.LookupEnv("WAITING_ROOM_ADMIT_INTERVAL")
+	if !ok {
+		return nil, false
+	}
+	waitingRoomInterval, err := time.ParseDuration(waitingRoomIntervalString)
+	if err != nil {
+		return nil, false
+	}
+
+	waitingRoomAdmissionString, ok := os.LookupEnv("WAITING_ROOM_ADMISSION_DURATION")
+	if !ok {
+		return nil, false
+	}
+	waitingRoomAdmission, err := time.ParseDuration(waitingRoomAdmissionString)
+	if err != nil {
+		return nil, false
+	}
+
+	waitlistOfferDurationString, ok := os.LookupEnv("WAITLIST_OFFER_DURATION")
+	if !ok {
+		return nil, false
+	}
+	waitlistOfferDuration, err := time.ParseDuration(waitlistOfferDurationString)
+	if err != nil {
+		return nil, false
+	}
+
+	waitlistIntervalString, ok := os.LookupEnv("WAITLIST_OFFER_INTERVAL")
+	if !ok {
+		return nil, false
+	}
+	waitlistInterval, err := time.ParseDuration(waitlistIntervalString)
+	if err != nil {
+		return nil, false
+	}
+
+	credentialSigningKey, ok := os.LookupEnv("CREDENTIAL_SIGNING_KEY")
+	if !ok {
+		return nil, false
+	}
+
 	searchURL, ok := os.LookupEnv("SEARCH_URL")
 	if !ok {
 		return nil, false
@@ -87,17 +238,34 @@ func NewConfig() (*Config, bool) {
 	}
 
 	return &Config{
-		APIVersion:         "",
-		DatabaseURL:        databaseURL,
-		Port:               port,
-		CacheURL:           cacheURL,
-		TicketHoldPrefix:   ticketHoldPrefix,
-		TicketHoldDuration: ticketHoldDuration,
-		SearchURL:          searchURL,
-		SearchPassword:     searchPassword,
-		SearchUser:         searchUser,
-		SearchMaxResults:   searchMaxResults,
-		SearchEventsIndex:  searchEventsIndex,
-		SearchVenuesIndex:  searchVenuesIndex,
+		APIVersion:              "",
+		DatabaseURL:             databaseURL,
+		Port:                    port,
+		CacheURL:                cacheURL,
+		TicketHoldPrefix:        ticketHoldPrefix,
+		TicketHoldDuration:      ticketHoldDuration,
+		TicketHoldMaxExtensions: ticketHoldMaxExtensions,
+		PaymentProcessor:        paymentProcessor,
+		PaymentGatewayURL:       paymentGatewayURL,
+		PaymentAuthMaxAge:       paymentAuthMaxAge,
+		PaymentSweepInterval:    paymentSweepInterval,
+		RefundRetryMaxAge:       refundRetryMaxAge,
+		RefundRetryInterval:     refundRetryInterval,
+		CancellationInterval:    cancellationInterval,
+		CancellationMaxAttempts: cancellationMaxAttempts,
+		IdempotencyKeyTTL:       idempotencyKeyTTL,
+		WaitingRoomPrefix:       waitingRoomPrefix,
+		WaitingRoomSecret:       waitingRoomSecret,
+		WaitingRoomInterval:     waitingRoomInterval,
+		WaitingRoomAdmission:    waitingRoomAdmission,
+		WaitlistOfferDuration:   waitlistOfferDuration,
+		WaitlistInterval:        waitlistInterval,
+		CredentialSigningKey:    credentialSigningKey,
+		SearchURL:               searchURL,
+		SearchPassword:          searchPassword,
+		SearchUser:              searchUser,
+		SearchMaxResults:        searchMaxResults,
+		SearchEventsIndex:       searchEventsIndex,
+		SearchVenuesIndex:       searchVenuesIndex,
 	}, true
 }
@@ -0,0 +1,94 @@
+package credentials_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dslaw/book-tickets/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBundle() credentials.Bundle {
+	return credentials.Bundle{
+		EventID:  2,
+		IssuedAt: 1577836800,
+		Tickets: []credentials.BundleTicket{
+			{TicketID: 1, OwnerID: 3, Nonce: "abc"},
+			{TicketID: 4, OwnerID: 5},
+			{TicketID: 6, OwnerID: 3, Nonce: "def", CheckedIn: true},
+		},
+		Revoked: []int32{7, 9},
+	}
+}
+
+func TestVerifierVerifyBundle(t *testing.T) {
+	signer := newTestSigner()
+	bundle := newTestBundle()
+
+	signed, err := signer.SignBundle(bundle)
+	assert.Nil(t, err)
+
+	actual, err := credentials.NewVerifier(signer.PublicKey()).VerifyBundle(signed)
+
+	assert.Nil(t, err)
+	assert.Equal(t, bundle, actual)
+}
+
+func TestVerifierVerifyBundleWhenInvalid(t *testing.T) {
+	signer := newTestSigner()
+	signed, _ := signer.SignBundle(newTestBundle())
+	payload, _, _ := strings.Cut(signed, ".")
+
+	// A credential is signed by the same signer, but isn't a bundle.
+	credential, _ := signer.Sign(credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"})
+	_, credentialSignature, _ := strings.Cut(credential, ".")
+
+	verifier := credentials.NewVerifier(signer.PublicKey())
+	for _, invalid := range []string{"", payload, payload + "." + credentialSignature} {
+		_, err := verifier.VerifyBundle(invalid)
+		assert.ErrorIs(t, err, credentials.ErrInvalidBundle)
+	}
+}
+
+// Test that credentials and bundles, which are signed by the same signer and
+// decode as each other, can't be verified as each other.
+func TestVerifierVerifyBundleWhenCredential(t *testing.T) {
+	signer := newTestSigner()
+	verifier := credentials.NewVerifier(signer.PublicKey())
+
+	credential, err := signer.Sign(credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", IssuedAt: 1577836800})
+	assert.Nil(t, err)
+	_, err = verifier.VerifyBundle(credential)
+	assert.ErrorIs(t, err, credentials.ErrInvalidBundle)
+
+	signed, err := signer.SignBundle(newTestBundle())
+	assert.Nil(t, err)
+	_, err = verifier.Verify(signed)
+	assert.ErrorIs(t, err, credentials.ErrInvalidCredential)
+}
+
+func TestBundleIsCurrent(t *testing.T) {
+	type testCase struct {
+		Name     string
+		Claims   credentials.Claims
+		Expected bool
+	}
+
+	testCases := []testCase{
+		{Name: "Current", Claims: credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"}, Expected: true},
+		{Name: "CheckedIn", Claims: credentials.Claims{TicketID: 6, EventID: 2, OwnerID: 3, Nonce: "def"}, Expected: true},
+		{Name: "OtherEvent", Claims: credentials.Claims{TicketID: 1, EventID: 8, OwnerID: 3, Nonce: "abc"}},
+		{Name: "Rotated", Claims: credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "old"}},
+		{Name: "PreviousOwner", Claims: credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 5, Nonce: "abc"}},
+		{Name: "NoNonce", Claims: credentials.Claims{TicketID: 4, EventID: 2, OwnerID: 5}},
+		{Name: "Revoked", Claims: credentials.Claims{TicketID: 7, EventID: 2, OwnerID: 3, Nonce: "abc"}},
+		{Name: "NotInBundle", Claims: credentials.Claims{TicketID: 8, EventID: 2, OwnerID: 3, Nonce: "abc"}},
+	}
+
+	bundle := newTestBundle()
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, bundle.IsCurrent(testCase.Claims))
+		})
+	}
+}
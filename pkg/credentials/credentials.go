@@ -0,0 +1,149 @@
+package credentials
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Claims are the claims of a ticket's credential: that the ticket, for the
+// event, is owned by the owner. The nonce is rotated to invalidate the
+// credentials signed over the previous one, so a credential is only current
+// if its nonce is the ticket's current nonce. The claims are kept short, as
+// credentials are encoded in barcodes.
+type Claims struct {
+	TicketID int32  `json:"tid"`
+	EventID  int32  `json:"eid"`
+	OwnerID  int32  `json:"oid"`
+	Nonce    string `json:"n"`
+	IssuedAt int64  `json:"iat"`
+}
+
+// IssuedTime gives the time the credential was issued.
+func (c Claims) IssuedTime() time.Time {
+	return time.Unix(c.IssuedAt, 0)
+}
+
+// Each kind of signed value is signed under its own domain, which prefixes
+// the signed payload, so that a value signed as one kind can't be verified as
+// another, as they're signed with the same key.
+const (
+	credentialDomain = "credential"
+	bundleDomain     = "bundle"
+)
+
+// signedMessage gives the message signed for the payload, under the domain.
+// Payloads are base64 encoded, so can't contain the separator.
+func signedMessage(domain string, payload string) []byte {
+	return []byte(domain + ":" + payload)
+}
+
+// Signer signs credentials with an Ed25519 private key, so that they can be
+// verified by scanners with only the public key.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// PublicKey gives the public key that the signer's credentials are verified
+// with.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// sign encodes the value as JSON, base64 encoded, followed by its signature
+// under the domain, separated by a ".".
+func (s *Signer) sign(domain string, value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	signature := ed25519.Sign(s.key, signedMessage(domain, payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Sign creates a credential for the claims.
+func (s *Signer) Sign(claims Claims) (string, error) {
+	return s.sign(credentialDomain, claims)
+}
+
+// Verifier verifies credentials with the public key of their signer. It needs
+// no access to the service, so that scanners can verify credentials offline.
+type Verifier struct {
+	key ed25519.PublicKey
+}
+
+func NewVerifier(key ed25519.PublicKey) *Verifier {
+	return &Verifier{key: key}
+}
+
+// verify checks that the signed value was signed by the verifier's signer,
+// under the domain, and decodes it into `value`. `invalidErr` is returned if
+// it wasn't.
+func (v *Verifier) verify(domain string, signed string, value any, invalidErr error) error {
+	if len(v.key) != ed25519.PublicKeySize {
+		return ErrInvalidKey
+	}
+
+	payload, encodedSignature, ok := strings.Cut(signed, ".")
+	if !ok {
+		return invalidErr
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !ed25519.Verify(v.key, signedMessage(domain, payload), signature) {
+		return invalidErr
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return invalidErr
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return invalidErr
+	}
+	return nil
+}
+
+// Verify checks that the credential was signed by the verifier's signer, and
+// gives its claims. Whether the credential is current - that its owner still
+// owns the ticket, and its nonce hasn't been rotated - is left to the caller,
+// which can check it against a `Bundle` when offline.
+func (v *Verifier) Verify(credential string) (Claims, error) {
+	var claims Claims
+	if err := v.verify(credentialDomain, credential, &claims, ErrInvalidCredential); err != nil {
+		return Claims{}, err
+	}
+	return claims, nil
+}
+
+// ParsePrivateKey parses a base64 encoded Ed25519 seed into the private key
+// it generates.
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ParsePublicKey parses a base64 encoded Ed25519 public key.
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// EncodePublicKey base64 encodes the public key, as parsed by
+// `ParsePublicKey`.
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
@@ -0,0 +1,93 @@
+package credentials_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/dslaw/book-tickets/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSeed() []byte {
+	seed := make([]byte, ed25519.SeedSize)
+	for idx := range seed {
+		seed[idx] = byte(idx)
+	}
+	return seed
+}
+
+func newTestSigner() *credentials.Signer {
+	return credentials.NewSigner(ed25519.NewKeyFromSeed(newTestSeed()))
+}
+
+func TestVerifierVerify(t *testing.T) {
+	signer := newTestSigner()
+	claims := credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", IssuedAt: 1577836800}
+
+	credential, err := signer.Sign(claims)
+	assert.Nil(t, err)
+
+	verifier := credentials.NewVerifier(signer.PublicKey())
+	actual, err := verifier.Verify(credential)
+
+	assert.Nil(t, err)
+	assert.Equal(t, claims, actual)
+}
+
+func TestVerifierVerifyWhenInvalid(t *testing.T) {
+	signer := newTestSigner()
+	claims := credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", IssuedAt: 1577836800}
+	credential, _ := signer.Sign(claims)
+	payload, signature, _ := strings.Cut(credential, ".")
+
+	// The claims of another ticket, under the original signature.
+	otherClaims := base64.RawURLEncoding.EncodeToString(
+		[]byte(`{"tid":9,"eid":2,"oid":3,"n":"abc","iat":1577836800}`),
+	)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	otherCredential, _ := credentials.NewSigner(otherKey).Sign(claims)
+
+	type testCase struct {
+		Name       string
+		Credential string
+	}
+
+	testCases := []testCase{
+		{Name: "Empty", Credential: ""},
+		{Name: "MissingSignature", Credential: payload},
+		{Name: "MalformedSignature", Credential: payload + ".!"},
+		{Name: "TamperedClaims", Credential: otherClaims + "." + signature},
+		{Name: "OtherSigner", Credential: otherCredential},
+	}
+
+	verifier := credentials.NewVerifier(signer.PublicKey())
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := verifier.Verify(testCase.Credential)
+			assert.ErrorIs(t, err, credentials.ErrInvalidCredential)
+		})
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, err := credentials.ParsePrivateKey(base64.StdEncoding.EncodeToString(newTestSeed()))
+
+	assert.Nil(t, err)
+	assert.Equal(t, newTestSigner().PublicKey(), key.Public())
+}
+
+func TestParsePrivateKeyWhenInvalid(t *testing.T) {
+	_, err := credentials.ParsePrivateKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorIs(t, err, credentials.ErrInvalidKey)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	expected := newTestSigner().PublicKey()
+
+	actual, err := credentials.ParsePublicKey(credentials.EncodePublicKey(expected))
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,9 @@
+package credentials
+
+import "errors"
+
+var (
+	ErrInvalidCredential = errors.New("The credential is invalid")
+	ErrInvalidBundle     = errors.New("The bundle is invalid")
+	ErrInvalidKey        = errors.New("The key is invalid")
+)
@@ -10,6 +10,7 @@ import (
 	"errors"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 var (
@@ -69,12 +70,73 @@ func (b *LinkPerformersBatchResults) Close() error {
 }
 
 const writeNewTickets = `-- name: WriteNewTickets :batchone
-insert into tickets (event_id, purchaser_id, price, seat)
-select events.id, null, $1, $2
+insert into tickets (event_id, purchaser_id, price, currency, seat, venue_seat_id, ticket_type_id, release_id)
+select
+    events.id,
+    null,
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
 from events
 where
-    events.id = $3
+    events.id = $7
     and events.deleted = false
+    and not exists (
+        select 1
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.currency <> $2
+    )
+    and not exists (
+        select 1
+        from ga_tiers
+        where
+            ga_tiers.event_id = events.id
+            and ga_tiers.currency <> $2
+    )
+    and not exists (
+        select 1
+        from ticket_types
+        where
+            ticket_types.event_id = events.id
+            and ticket_types.currency <> $2
+    )
+    and (
+        $4::int is null
+        or exists (
+            select 1
+            from venue_seats
+            inner join venue_rows on venue_seats.row_id = venue_rows.id
+            inner join venue_sections on venue_rows.section_id = venue_sections.id
+            where
+                venue_seats.id = $4
+                and venue_sections.venue_id = events.venue_id
+        )
+    )
+    and (
+        $5::int is null
+        or exists (
+            select 1
+            from ticket_types
+            where
+                ticket_types.id = $5
+                and ticket_types.event_id = events.id
+        )
+    )
+    and (
+        $6::int is null
+        or exists (
+            select 1
+            from ticket_releases
+            where
+                ticket_releases.id = $6
+                and ticket_releases.event_id = events.id
+        )
+    )
 returning id
 `
 
@@ -85,20 +147,30 @@ type WriteNewTicketsBatchResults struct {
 }
 
 type WriteNewTicketsParams struct {
-	Price   int32
-	Seat    string
-	EventID int32
+	Price        int64
+	Currency     string
+	Seat         string
+	VenueSeatID  pgtype.Int4
+	TicketTypeID pgtype.Int4
+	ReleaseID    pgtype.Int4
+	EventID      int32
 }
 
 // The inserted record's id is returned so that the generated query will return
 // an error (`sql.ErrNoRows`) if no record is inserted due to the where clause
-// not finding a matching event.
+// not finding a matching event, the event's tickets being priced in another
+// currency, the seat not being part of the event's venue's layout, or the
+// ticket type or release not being one of the event's.
 func (q *Queries) WriteNewTickets(ctx context.Context, arg []WriteNewTicketsParams) *WriteNewTicketsBatchResults {
 	batch := &pgx.Batch{}
 	for _, a := range arg {
 		vals := []interface{}{
 			a.Price,
+			a.Currency,
 			a.Seat,
+			a.VenueSeatID,
+			a.TicketTypeID,
+			a.ReleaseID,
 			a.EventID,
 		}
 		batch.Queue(writeNewTickets, vals...)
@@ -130,6 +202,64 @@ func (b *WriteNewTicketsBatchResults) Close() error {
 	return b.br.Close()
 }
 
+const writeOrderItems = `-- name: WriteOrderItems :batchexec
+insert into order_items (order_id, ticket_id, price, service_fee, tax, discount)
+values ($1, $2, $3, $4, $5, $6)
+`
+
+type WriteOrderItemsBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type WriteOrderItemsParams struct {
+	OrderID    int32
+	TicketID   int32
+	Price      int64
+	ServiceFee int64
+	Tax        int64
+	Discount   int64
+}
+
+func (q *Queries) WriteOrderItems(ctx context.Context, arg []WriteOrderItemsParams) *WriteOrderItemsBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.OrderID,
+			a.TicketID,
+			a.Price,
+			a.ServiceFee,
+			a.Tax,
+			a.Discount,
+		}
+		batch.Queue(writeOrderItems, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &WriteOrderItemsBatchResults{br, len(arg), false}
+}
+
+func (b *WriteOrderItemsBatchResults) Exec(f func(int, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		if b.closed {
+			if f != nil {
+				f(t, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		_, err := b.br.Exec()
+		if f != nil {
+			f(t, err)
+		}
+	}
+}
+
+func (b *WriteOrderItemsBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
+
 const writePerformers = `-- name: WritePerformers :batchexec
 insert into performers (name) values ($1)
 on conflict (name) do nothing
@@ -0,0 +1,92 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readMigration reads the up and down sections of the migration file given by
+// name.
+func readMigration(t *testing.T, name string) (string, string) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "db", "migrations", name))
+	require.Nil(t, err)
+
+	up, down, ok := strings.Cut(string(data), "-- migrate:down")
+	require.True(t, ok)
+	return strings.TrimPrefix(up, "-- migrate:up"), down
+}
+
+// Test that amounts written before currencies were added, in whole units, are
+// rescaled to minor units, and back again.
+func TestAddCurrenciesMigration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping testing in short mode")
+	}
+
+	ctx := context.Background()
+	up, down := readMigration(t, "20261016160000_add_currencies.sql")
+
+	conn, err := pgx.Connect(ctx, os.Getenv("TEST_DATABASE_URL_LOCAL"))
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unable to connect to test database: %s", err))
+	}
+	defer conn.Close(ctx)
+
+	// The migration is run against copies of the tables it alters, as they were
+	// before it, in a schema that's discarded along with the transaction.
+	tx, err := conn.Begin(ctx)
+	require.Nil(t, err)
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+create schema add_currencies_test;
+set local search_path to add_currencies_test;
+create table tickets (id int primary key, price int not null check (price > 0));
+create table payments (id int primary key, amount int not null check (amount >= 0));
+create table orders (id int primary key, total int not null check (total >= 0));
+create table order_items (id int primary key, price int not null check (price >= 0));
+create table refunds (id int primary key, amount int not null check (amount > 0));
+insert into tickets values (1, 20);
+insert into payments values (1, 20);
+insert into orders values (1, 20);
+insert into order_items values (1, 20);
+insert into refunds values (1, 20);
+`)
+	require.Nil(t, err)
+
+	readAmounts := func() []int64 {
+		var ticketPrice, paymentAmount, orderTotal, itemPrice, refundAmount int64
+		err := tx.QueryRow(ctx, `
+select
+    (select price from tickets where id = 1),
+    (select amount from payments where id = 1),
+    (select total from orders where id = 1),
+    (select price from order_items where id = 1),
+    (select amount from refunds where id = 1)
+`).Scan(&ticketPrice, &paymentAmount, &orderTotal, &itemPrice, &refundAmount)
+		require.Nil(t, err)
+		return []int64{ticketPrice, paymentAmount, orderTotal, itemPrice, refundAmount}
+	}
+
+	_, err = tx.Exec(ctx, up)
+	require.Nil(t, err)
+	assert.Equal(t, []int64{2000, 2000, 2000, 2000, 2000}, readAmounts())
+
+	var currency string
+	err = tx.QueryRow(ctx, "select currency from tickets where id = 1").Scan(&currency)
+	require.Nil(t, err)
+	assert.Equal(t, "USD", currency)
+
+	_, err = tx.Exec(ctx, down)
+	require.Nil(t, err)
+	assert.Equal(t, []int64{20, 20, 20, 20, 20}, readAmounts())
+}
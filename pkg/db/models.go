@@ -8,14 +8,47 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CancellationRefund struct {
+	ID             int32
+	CancellationID int32
+	TicketID       int32
+	Status         string
+	Attempts       int32
+	LastError      pgtype.Text
+	RefundID       pgtype.Int4
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type Checkin struct {
+	ID        int32
+	TicketID  int32
+	EventID   int32
+	OwnerID   int32
+	Gate      string
+	Device    string
+	ScannedAt pgtype.Timestamptz
+	Offline   bool
+}
+
 type Event struct {
-	ID          int32
-	VenueID     int32
-	Name        string
-	StartsAt    pgtype.Timestamptz
-	EndsAt      pgtype.Timestamptz
-	Description pgtype.Text
-	Deleted     bool
+	ID                int32
+	VenueID           int32
+	Name              string
+	StartsAt          pgtype.Timestamptz
+	EndsAt            pgtype.Timestamptz
+	Description       pgtype.Text
+	Deleted           bool
+	MaxTicketsPerUser pgtype.Int4
+	ResaleMinRate     pgtype.Int4
+	ResaleMaxRate     pgtype.Int4
+}
+
+type EventCancellation struct {
+	ID        int32
+	EventID   int32
+	Status    string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
 }
 
 type EventPerformer struct {
@@ -24,17 +57,206 @@ type EventPerformer struct {
 	PerformerID int32
 }
 
+type FeeRule struct {
+	ID             int32
+	VenueID        pgtype.Int4
+	EventID        pgtype.Int4
+	ServiceFeeRate int32
+	ServiceFeeFlat int64
+	FacilityFee    int64
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type GaTier struct {
+	ID       int32
+	EventID  int32
+	Name     string
+	Price    int64
+	Currency string
+	Capacity int32
+	Sold     int32
+}
+
+type IdempotencyKey struct {
+	ID           int32
+	UserID       string
+	Key          string
+	Fingerprint  string
+	StatusCode   pgtype.Int4
+	ContentType  pgtype.Text
+	ResponseBody []byte
+	CreatedAt    pgtype.Timestamptz
+	ExpiresAt    pgtype.Timestamptz
+}
+
+type Order struct {
+	ID          int32
+	PurchaserID int32
+	PaymentID   int32
+	Status      string
+	Total       int64
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	Currency    string
+	FacilityFee int64
+	Tax         int64
+	PromoCodeID pgtype.Int4
+	Discount    int64
+}
+
+type OrderItem struct {
+	ID         int32
+	OrderID    int32
+	TicketID   int32
+	Price      int64
+	ServiceFee int64
+	Tax        int64
+	Discount   int64
+}
+
+type Payment struct {
+	ID            int32
+	PurchaserID   int32
+	Amount        int64
+	Status        string
+	Reference     pgtype.Text
+	CreatedAt     pgtype.Timestamptz
+	DeclineReason pgtype.Text
+	UpdatedAt     pgtype.Timestamptz
+	Currency      string
+}
+
 type Performer struct {
 	ID   int32
 	Name string
 }
 
+type Presale struct {
+	ID         int32
+	ReleaseID  int32
+	Name       string
+	StartsAt   pgtype.Timestamptz
+	EndsAt     pgtype.Timestamptz
+	AccessCode pgtype.Text
+}
+
+type PresaleUser struct {
+	PresaleID int32
+	UserID    int32
+}
+
+type PromoCode struct {
+	ID                    int32
+	Code                  string
+	DiscountType          string
+	PercentOff            int32
+	AmountOff             int64
+	Currency              pgtype.Text
+	EventID               pgtype.Int4
+	StartsAt              pgtype.Timestamptz
+	EndsAt                pgtype.Timestamptz
+	MaxRedemptions        pgtype.Int4
+	MaxRedemptionsPerUser pgtype.Int4
+	Redemptions           int32
+	Deleted               bool
+	CreatedAt             pgtype.Timestamptz
+	UpdatedAt             pgtype.Timestamptz
+}
+
+type PromoCodeRedemption struct {
+	PromoCodeID int32
+	PurchaserID int32
+	Redemptions int32
+}
+
+type Refund struct {
+	ID          int32
+	PaymentID   int32
+	TicketID    int32
+	Amount      int64
+	Status      string
+	Reference   pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	FacilityFee int64
+}
+
+type ResaleListing struct {
+	ID        int32
+	TicketID  int32
+	SellerID  int32
+	Price     int64
+	Currency  string
+	Status    string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type ResalePayout struct {
+	ID        int32
+	ListingID int32
+	SellerID  int32
+	PaymentID int32
+	Amount    int64
+	Currency  string
+	CreatedAt pgtype.Timestamptz
+}
+
+type TaxRate struct {
+	ID          int32
+	CountryCode string
+	Subdivision string
+	Rate        int32
+}
+
 type Ticket struct {
+	ID           int32
+	EventID      int32
+	PurchaserID  pgtype.Int4
+	Price        int64
+	Seat         string
+	Voided       bool
+	Currency     string
+	VenueSeatID  pgtype.Int4
+	GaTierID     pgtype.Int4
+	TicketTypeID pgtype.Int4
+	ReleaseID    pgtype.Int4
+}
+
+type TicketCredential struct {
+	TicketID  int32
+	OwnerID   int32
+	Nonce     string
+	RotatedAt pgtype.Timestamptz
+}
+
+type TicketRelease struct {
+	ID        int32
+	EventID   int32
+	OnSaleAt  pgtype.Timestamptz
+	OffSaleAt pgtype.Timestamptz
+}
+
+type TicketTransfer struct {
 	ID          int32
-	EventID     int32
-	PurchaserID pgtype.Int4
-	Price       int32
-	Seat        string
+	TicketID    int32
+	SenderID    int32
+	RecipientID int32
+	Status      string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type TicketType struct {
+	ID           int32
+	EventID      int32
+	Name         string
+	Description  pgtype.Text
+	Price        int64
+	Currency     string
+	Eligibility  []string
+	SaleStartsAt pgtype.Timestamptz
+	SaleEndsAt   pgtype.Timestamptz
 }
 
 type User struct {
@@ -53,3 +275,40 @@ type Venue struct {
 	CountryCode string
 	Deleted     bool
 }
+
+type VenueRow struct {
+	ID        int32
+	SectionID int32
+	Name      string
+}
+
+type VenueSeat struct {
+	ID         int32
+	RowID      int32
+	Number     string
+	X          float64
+	Y          float64
+	Accessible bool
+	Obstructed bool
+}
+
+type VenueSection struct {
+	ID      int32
+	VenueID int32
+	Name    string
+}
+
+type WaitingRoom struct {
+	EventID   int32
+	BatchSize int32
+	UpdatedAt pgtype.Timestamptz
+}
+
+type WaitlistEntry struct {
+	EventID        int32
+	UserID         int32
+	Quantity       int32
+	JoinedAt       pgtype.Timestamptz
+	OfferToken     pgtype.Text
+	OfferExpiresAt pgtype.Timestamptz
+}
@@ -9,30 +9,262 @@ import (
 )
 
 type Querier interface {
+	AddPresaleUsers(ctx context.Context, arg AddPresaleUsersParams) error
+	// Claims the key for a request, unless it has already been claimed by another
+	// request and hasn't expired. An expired key is claimed anew.
+	ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (int32, error)
+	// Returns the ticket to inventory if it is re-released, otherwise voids it.
+	// General admission tickets are always voided, and are returned to their
+	// tier's capacity instead if re-released. The purchaser's resale listing of the
+	// ticket, if any, is cancelled, and their credential for it removed.
+	ClearTicketPurchaser(ctx context.Context, arg ClearTicketPurchaserParams) (int64, error)
+	// Completes cancellations that have no refunds left to process.
+	CompleteEventCancellations(ctx context.Context) (int64, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error
+	CompleteRefund(ctx context.Context, arg CompleteRefundParams) (int64, error)
+	// Records the ticket's admission to its event. Nothing is returned if the
+	// ticket has already been checked in, so that the generated query will return
+	// an error (`sql.ErrNoRows`).
+	CreateCheckin(ctx context.Context, arg CreateCheckinParams) (Checkin, error)
 	CreateEvent(ctx context.Context, arg CreateEventParams) (int32, error)
+	// The inserted record's id is returned so that the generated query will return
+	// an error (`sql.ErrNoRows`) if the event doesn't exist, or if the event's
+	// tickets are priced in another currency.
+	CreateGaTier(ctx context.Context, arg CreateGaTierParams) (int32, error)
+	// Records the ticket's admission to its event, as scanned while offline. Of
+	// the ticket's admissions, the earliest is kept, so that admissions recorded
+	// by devices that were offline are reconciled by when they were scanned.
+	// Nothing is returned if the ticket was already admitted earlier, so that the
+	// generated query will return an error (`sql.ErrNoRows`).
+	CreateOfflineCheckin(ctx context.Context, arg CreateOfflineCheckinParams) (Checkin, error)
+	CreateOrder(ctx context.Context, arg CreateOrderParams) (int32, error)
+	CreatePayment(ctx context.Context, arg CreatePaymentParams) (int32, error)
+	CreatePresale(ctx context.Context, arg CreatePresaleParams) (int32, error)
+	// The inserted record's id is returned so that the generated query will return
+	// an error (`sql.ErrNoRows`) if the code is for an event that doesn't exist.
+	CreatePromoCode(ctx context.Context, arg CreatePromoCodeParams) (int32, error)
+	// Refunds are created as pending, and completed once the payment processor has
+	// made them.
+	CreateRefund(ctx context.Context, arg CreateRefundParams) (int32, error)
+	CreateResaleListing(ctx context.Context, arg CreateResaleListingParams) (ResaleListing, error)
+	CreateResalePayout(ctx context.Context, arg CreateResalePayoutParams) (int32, error)
+	// Creates the owner's credential for the ticket, replacing a credential issued
+	// to a previous owner. An existing credential of the owner is kept, so that
+	// concurrent requests agree on the nonce.
+	CreateTicketCredential(ctx context.Context, arg CreateTicketCredentialParams) error
+	// The inserted record's id is returned so that the generated query will return
+	// an error (`sql.ErrNoRows`) if the event doesn't exist.
+	CreateTicketRelease(ctx context.Context, arg CreateTicketReleaseParams) (int32, error)
+	CreateTicketTransfer(ctx context.Context, arg CreateTicketTransferParams) (TicketTransfer, error)
+	// The inserted record's id is returned so that the generated query will return
+	// an error (`sql.ErrNoRows`) if the event doesn't exist, or if the event's
+	// tickets are priced in another currency.
+	CreateTicketType(ctx context.Context, arg CreateTicketTypeParams) (int32, error)
 	CreateVenue(ctx context.Context, arg CreateVenueParams) (int32, error)
 	DeleteEvent(ctx context.Context, eventID int32) (int64, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, idempotencyKeyID int32) error
+	DeletePromoCode(ctx context.Context, promoCodeID int32) (int64, error)
 	DeleteVenue(ctx context.Context, venueID int32) (int64, error)
+	// Rows and seats are deleted along with their sections.
+	DeleteVenueLayout(ctx context.Context, venueID int32) (int64, error)
+	DeleteWaitingRoom(ctx context.Context, eventID int32) (int64, error)
+	DeleteWaitlistEntry(ctx context.Context, arg DeleteWaitlistEntryParams) (int64, error)
+	EventExists(ctx context.Context, eventID int32) (bool, error)
+	FailRefund(ctx context.Context, refundID int32) (int64, error)
+	GetActiveResaleListing(ctx context.Context, ticketID int32) (ResaleListing, error)
+	// Gets the fee rule that applies to an event, preferring the event's own rule
+	// over its venue's.
+	GetApplicableFeeRule(ctx context.Context, eventID int32) (FeeRule, error)
+	// Available tickets for seats of the event's venue's layout, along with where
+	// each seat is. Seats are numbered by their position within their row, from
+	// left to right, so that adjacent seats have consecutive positions. Tickets of
+	// a type or release that isn't on sale aren't available, including those that
+	// are only on presale.
+	GetAvailableSeatedTickets(ctx context.Context, eventID int32) ([]GetAvailableSeatedTicketsRow, error)
+	// General admission tickets are only issued as they're purchased, so they're
+	// never available. Tickets of a type that isn't on sale aren't available, nor
+	// are tickets of a release that has gone off sale - tickets of a release that
+	// isn't on sale yet are, so that its schedule can be shown.
 	GetAvailableTickets(ctx context.Context, eventID int32) ([]GetAvailableTicketsRow, error)
 	GetEvent(ctx context.Context, eventID int32) ([]GetEventRow, error)
+	// Counts the event's checked in tickets, along with its purchased tickets.
+	GetEventAttendance(ctx context.Context, eventID int32) (GetEventAttendanceRow, error)
+	GetEventCancellation(ctx context.Context, eventID int32) (GetEventCancellationRow, error)
+	GetEventCurrencies(ctx context.Context, eventID int32) ([]string, error)
+	GetEventFeeRule(ctx context.Context, eventID int32) (FeeRule, error)
+	GetEventGaTiers(ctx context.Context, eventID int32) ([]GaTier, error)
+	// Gets the tax rate for the region of an event's venue, preferring the rate for
+	// the venue's subdivision over the rate for its country.
+	// Gets the active listings of the event's tickets, along with each ticket's
+	// seat and face value. Listings of tickets that are no longer owned by their
+	// seller, or whose event has started, can't be purchased, so aren't included.
+	GetEventResaleListings(ctx context.Context, eventID int32) ([]GetEventResaleListingsRow, error)
+	GetEventTaxRate(ctx context.Context, eventID int32) (int32, error)
+	GetEventTicketTypes(ctx context.Context, eventID int32) ([]TicketType, error)
+	// Of the given seats, those that are part of the event's venue's layout, and
+	// whether each already has a ticket for the event.
+	GetEventVenueSeats(ctx context.Context, arg GetEventVenueSeatsParams) ([]GetEventVenueSeatsRow, error)
+	// Finds the events, of the given tickets, of which the purchaser has purchased
+	// more tickets than the event's limit on tickets per user.
+	GetExceededPurchaseLimits(ctx context.Context, arg GetExceededPurchaseLimitsParams) ([]int32, error)
+	GetGaTier(ctx context.Context, gaTierID int32) (GaTier, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetOrder(ctx context.Context, orderID int32) ([]GetOrderRow, error)
+	GetPendingCancellationRefunds(ctx context.Context, maxRefunds int32) ([]CancellationRefund, error)
+	GetPendingTicketTransfer(ctx context.Context, ticketID int32) (TicketTransfer, error)
+	GetPromoCode(ctx context.Context, promoCodeID int32) (PromoCode, error)
+	GetPromoCodeByCode(ctx context.Context, code string) (PromoCode, error)
+	// Gets the limit on tickets per user of each of the given events that has one,
+	// along with how many of the event's tickets the purchaser has purchased.
+	GetPurchaseLimits(ctx context.Context, arg GetPurchaseLimitsParams) ([]GetPurchaseLimitsRow, error)
+	GetResaleListing(ctx context.Context, listingID int32) (ResaleListing, error)
+	// Gets the ids of the event's tickets that have been refunded, and haven't
+	// been purchased again since.
+	GetRevokedTickets(ctx context.Context, eventID int32) ([]int32, error)
+	// Gets the event's purchased tickets, along with their owner's credential's
+	// nonce, if they have one, and when they were checked in, if they have been.
+	GetScannerTickets(ctx context.Context, eventID int32) ([]GetScannerTicketsRow, error)
+	// Gets the seller's listings, most recent first.
+	GetSellerResaleListings(ctx context.Context, sellerID int32) ([]ResaleListing, error)
+	GetStaleAuthorizedPayments(ctx context.Context, arg GetStaleAuthorizedPaymentsParams) ([]Payment, error)
+	GetStalePendingRefunds(ctx context.Context, arg GetStalePendingRefundsParams) ([]GetStalePendingRefundsRow, error)
 	GetTicket(ctx context.Context, ticketID int32) (GetTicketRow, error)
+	// Gets the ticket's event and owner, along with the nonce of its owner's
+	// credential, if they have one, and when the ticket was checked in, if it has
+	// been.
+	GetTicketAdmission(ctx context.Context, ticketID int32) (GetTicketAdmissionRow, error)
+	// Gets the ticket, along with the nonce of its owner's credential and when it
+	// was last rotated, if its owner has a credential.
+	GetTicketCredential(ctx context.Context, ticketID int32) (GetTicketCredentialRow, error)
+	// Gets the ticket's purchaser, if it has been purchased, along with when its
+	// event starts.
+	GetTicketOwnership(ctx context.Context, ticketID int32) (GetTicketOwnershipRow, error)
+	// Gets the most recent purchase of a ticket that is currently purchased.
+	GetTicketPurchase(ctx context.Context, ticketID int32) (GetTicketPurchaseRow, error)
+	// Releases along with their presales, if any, in the order that the presales
+	// start.
+	GetTicketReleases(ctx context.Context, releaseIds []int32) ([]GetTicketReleasesRow, error)
+	// Gets the ticket, along with when its event starts and the event's bounds on
+	// the price of a resale listing.
+	GetTicketResaleTerms(ctx context.Context, ticketID int32) (GetTicketResaleTermsRow, error)
+	GetTicketTransfer(ctx context.Context, transferID int32) (TicketTransfer, error)
+	// Gets the ticket's transfers, most recent first.
+	GetTicketTransfers(ctx context.Context, ticketID int32) ([]TicketTransfer, error)
+	GetTicketTypes(ctx context.Context, ticketTypeIds []int32) ([]TicketType, error)
+	GetTickets(ctx context.Context, ticketIds []int32) ([]GetTicketsRow, error)
+	// Gets the facility fee of an order, along with its tax, unless it has already
+	// been refunded, and the number of the order's tickets that have yet to be
+	// refunded. The order is locked, so that its facility fee is only refunded
+	// once.
+	GetUnrefundedFacilityFee(ctx context.Context, orderID int32) (GetUnrefundedFacilityFeeRow, error)
+	GetUserOrders(ctx context.Context, purchaserID int32) ([]GetUserOrdersRow, error)
+	GetUserPromoCodeRedemptions(ctx context.Context, arg GetUserPromoCodeRedemptionsParams) (int32, error)
+	// Gets the transfers sent or received by the user, most recent first.
+	GetUserTicketTransfers(ctx context.Context, userID int32) ([]TicketTransfer, error)
 	GetVenue(ctx context.Context, venueID int32) (GetVenueRow, error)
+	GetVenueFeeRule(ctx context.Context, venueID int32) (FeeRule, error)
+	GetVenueLayout(ctx context.Context, venueID int32) ([]GetVenueLayoutRow, error)
+	// Gets the entries of the users waiting on the event's waitlist, in the order
+	// they joined.
+	GetWaitingEntries(ctx context.Context, arg GetWaitingEntriesParams) ([]WaitlistEntry, error)
+	GetWaitingRoom(ctx context.Context, eventID int32) (WaitingRoom, error)
+	GetWaitingRooms(ctx context.Context) ([]WaitingRoom, error)
+	// Gets the user's entry on the event's waitlist, along with the number of
+	// users ahead of them that are still waiting.
+	GetWaitlistEntry(ctx context.Context, arg GetWaitlistEntryParams) (GetWaitlistEntryRow, error)
+	// Gets the events with users waiting on their waitlist.
+	GetWaitlistedEvents(ctx context.Context) ([]int32, error)
+	IsPresaleUser(ctx context.Context, arg IsPresaleUserParams) (bool, error)
+	// Issues tickets for the tier only if it has the capacity for all of them, so
+	// that a tier is never oversold.
+	IssueGaTickets(ctx context.Context, arg IssueGaTicketsParams) ([]int32, error)
 	LinkPerformers(ctx context.Context, arg []LinkPerformersParams) *LinkPerformersBatchResults
 	LinkUpdatedPerformers(ctx context.Context, arg LinkUpdatedPerformersParams) error
+	// Serializes purchases by the same purchaser, so that concurrent purchases
+	// can't exceed an event's limit on tickets per user together.
+	LockPurchaser(ctx context.Context, purchaserID int32) error
+	// Counts a redemption of the code, unless it can't be redeemed at this time or
+	// has no redemptions left. The row lock taken by the update serializes
+	// concurrent redemptions, so that the code can't be over-redeemed.
+	RedeemPromoCode(ctx context.Context, promoCodeID int32) (int64, error)
+	// Counts a redemption of the code by a user, unless the user has no
+	// redemptions of the code left.
+	RedeemPromoCodeForUser(ctx context.Context, arg RedeemPromoCodeForUserParams) (int64, error)
+	// Voids issued tickets that weren't purchased, returning them to their tier's
+	// capacity.
+	ReturnGaTickets(ctx context.Context, ticketIds []int32) (int64, error)
+	// Sets the nonce of the owner's credential for the ticket, as long as they
+	// still own it.
+	RotateTicketCredential(ctx context.Context, arg RotateTicketCredentialParams) (TicketCredential, error)
+	// The payment is only marked as refunded once all of it has been refunded.
+	SetPaymentRefunded(ctx context.Context, paymentID int32) (int64, error)
 	SetTicketPurchaser(ctx context.Context, arg SetTicketPurchaserParams) (int32, error)
+	// Either all of the tickets are updated, or none are if any of them has already
+	// been purchased, so that a set of held tickets is purchased as a whole.
+	SetTicketsPurchaser(ctx context.Context, arg SetTicketsPurchaserParams) (int64, error)
+	SetWaitlistOffer(ctx context.Context, arg SetWaitlistOfferParams) (int64, error)
+	// Moves the ticket to the recipient, as long as it's still owned by the sender
+	// and its event hasn't started. The sender's resale listing of the ticket, if
+	// any, is cancelled, and their credential for it removed.
+	TransferTicket(ctx context.Context, arg TransferTicketParams) (int64, error)
 	TrimUpdatedEventPerformers(ctx context.Context, eventID int32) error
+	// Remove the rows of a venue's layout, other than those given.
+	TrimVenueRows(ctx context.Context, arg TrimVenueRowsParams) error
+	// Remove the seats of a venue's layout, other than those given.
+	TrimVenueSeats(ctx context.Context, arg TrimVenueSeatsParams) error
+	// Remove the sections of a venue's layout, other than those given.
+	TrimVenueSections(ctx context.Context, arg TrimVenueSectionsParams) error
+	UpdateCancellationRefund(ctx context.Context, arg UpdateCancellationRefundParams) error
 	// The updated record's id is returned so that the generated query will return
 	// an error (`sql.ErrNoRows`) if no record matches the where clause and no
 	// record is updated.
 	UpdateEvent(ctx context.Context, arg UpdateEventParams) (int32, error)
+	// Only updates the payment if it is in the expected status, so that concurrent
+	// transitions can't both succeed.
+	UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) (int64, error)
+	// The updated record's id is returned so that the generated query will return
+	// an error (`sql.ErrNoRows`) if no record matches the where clause and no
+	// record is updated, including if the code is for an event that doesn't exist.
+	UpdatePromoCode(ctx context.Context, arg UpdatePromoCodeParams) (int32, error)
+	// Only updates the listing if it is active, so that a listing can't be both
+	// sold and cancelled.
+	UpdateResaleListingStatus(ctx context.Context, arg UpdateResaleListingStatusParams) (ResaleListing, error)
+	// Only updates the transfer if it is pending, so that a transfer can't be both
+	// accepted and cancelled.
+	UpdateTicketTransferStatus(ctx context.Context, arg UpdateTicketTransferStatusParams) (TicketTransfer, error)
 	// The updated record's id is returned so that the generated query will return
 	// an error (`sql.ErrNoRows`) if no record matches the where clause and no
 	// record is updated.
 	UpdateVenue(ctx context.Context, arg UpdateVenueParams) (int32, error)
+	// The inserted or updated record's id is returned so that the generated query
+	// will return an error (`sql.ErrNoRows`) if the event doesn't exist.
+	UpsertEventFeeRule(ctx context.Context, arg UpsertEventFeeRuleParams) (int32, error)
+	UpsertTaxRate(ctx context.Context, arg UpsertTaxRateParams) error
+	// The inserted or updated record's id is returned so that the generated query
+	// will return an error (`sql.ErrNoRows`) if the venue doesn't exist.
+	UpsertVenueFeeRule(ctx context.Context, arg UpsertVenueFeeRuleParams) (int32, error)
+	// Existing rows are updated in place, so that their ids are returned.
+	UpsertVenueRows(ctx context.Context, arg UpsertVenueRowsParams) ([]VenueRow, error)
+	UpsertVenueSeats(ctx context.Context, arg UpsertVenueSeatsParams) ([]int32, error)
+	// Existing sections are updated in place, so that their ids are returned.
+	UpsertVenueSections(ctx context.Context, arg UpsertVenueSectionsParams) ([]UpsertVenueSectionsRow, error)
+	// The inserted or updated record's event id is returned so that the generated
+	// query will return an error (`sql.ErrNoRows`) if the event doesn't exist.
+	UpsertWaitingRoom(ctx context.Context, arg UpsertWaitingRoomParams) (int32, error)
+	// Joining a waitlist that the user is already waiting on only changes the
+	// quantity, keeping the user's place, whereas joining again once offered
+	// tickets puts the user at the back. The inserted or updated record's event id
+	// is returned so that the generated query will return an error
+	// (`sql.ErrNoRows`) if the event doesn't exist.
+	UpsertWaitlistEntry(ctx context.Context, arg UpsertWaitlistEntryParams) (int32, error)
 	// The inserted record's id is returned so that the generated query will return
 	// an error (`sql.ErrNoRows`) if no record is inserted due to the where clause
-	// not finding a matching event.
+	// not finding a matching event, the event's tickets being priced in another
+	// currency, the seat not being part of the event's venue's layout, or the
+	// ticket type or release not being one of the event's.
 	WriteNewTickets(ctx context.Context, arg []WriteNewTicketsParams) *WriteNewTicketsBatchResults
+	WriteOrderItems(ctx context.Context, arg []WriteOrderItemsParams) *WriteOrderItemsBatchResults
 	WritePerformers(ctx context.Context, name []string) *WritePerformersBatchResults
 }
 
@@ -11,18 +11,259 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createEvent = `-- name: CreateEvent :one
-insert into events (venue_id, name, starts_at, ends_at, description)
+const addPresaleUsers = `-- name: AddPresaleUsers :exec
+insert into presale_users (presale_id, user_id)
+select $1, unnest($2::int[])
+on conflict do nothing
+`
+
+type AddPresaleUsersParams struct {
+	PresaleID int32
+	UserIds   []int32
+}
+
+func (q *Queries) AddPresaleUsers(ctx context.Context, arg AddPresaleUsersParams) error {
+	_, err := q.db.Exec(ctx, addPresaleUsers, arg.PresaleID, arg.UserIds)
+	return err
+}
+
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :one
+insert into idempotency_keys (user_id, key, fingerprint, expires_at)
+values ($1, $2, $3, $4)
+on conflict (user_id, key) do update
+set
+    fingerprint = excluded.fingerprint,
+    status_code = null,
+    content_type = null,
+    response_body = null,
+    created_at = now(),
+    expires_at = excluded.expires_at
+where idempotency_keys.expires_at <= now()
+returning id
+`
+
+type ClaimIdempotencyKeyParams struct {
+	UserID      string
+	Key         string
+	Fingerprint string
+	ExpiresAt   pgtype.Timestamptz
+}
+
+// Claims the key for a request, unless it has already been claimed by another
+// request and hasn't expired. An expired key is claimed anew.
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (int32, error) {
+	row := q.db.QueryRow(ctx, claimIdempotencyKey,
+		arg.UserID,
+		arg.Key,
+		arg.Fingerprint,
+		arg.ExpiresAt,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const clearTicketPurchaser = `-- name: ClearTicketPurchaser :execrows
+with returned as (
+    update ga_tiers
+    set sold = sold - 1
+    where
+        $1::boolean
+        and id = (
+            select ga_tier_id
+            from tickets
+            where
+                id = $2
+                and purchaser_id = $3
+        )
+), cancel_listings as (
+    update resale_listings
+    set
+        status = 'cancelled',
+        updated_at = now()
+    where
+        ticket_id = $2
+        and seller_id = $3
+        and status = 'active'
+), remove_credentials as (
+    delete from ticket_credentials
+    where
+        ticket_id = $2
+        and owner_id = $3
+)
+update tickets
+set
+    purchaser_id = null,
+    voided = ga_tier_id is not null or not $1::boolean
+where
+    id = $2
+    and purchaser_id = $3
+`
+
+type ClearTicketPurchaserParams struct {
+	Rerelease   bool
+	TicketID    int32
+	PurchaserID pgtype.Int4
+}
+
+// Returns the ticket to inventory if it is re-released, otherwise voids it.
+// General admission tickets are always voided, and are returned to their
+// tier's capacity instead if re-released. The purchaser's resale listing of the
+// ticket, if any, is cancelled, and their credential for it removed.
+func (q *Queries) ClearTicketPurchaser(ctx context.Context, arg ClearTicketPurchaserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, clearTicketPurchaser, arg.Rerelease, arg.TicketID, arg.PurchaserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const completeEventCancellations = `-- name: CompleteEventCancellations :execrows
+update event_cancellations
+set
+    status = 'completed',
+    updated_at = now()
+where
+    status = 'pending'
+    and not exists (
+        select 1
+        from cancellation_refunds
+        where
+            cancellation_refunds.cancellation_id = event_cancellations.id
+            and cancellation_refunds.status = 'pending'
+    )
+`
+
+// Completes cancellations that have no refunds left to process.
+func (q *Queries) CompleteEventCancellations(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, completeEventCancellations)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+update idempotency_keys
+set
+    status_code = $1,
+    content_type = $2,
+    response_body = $3
+where id = $4
+`
+
+type CompleteIdempotencyKeyParams struct {
+	StatusCode       pgtype.Int4
+	ContentType      pgtype.Text
+	ResponseBody     []byte
+	IdempotencyKeyID int32
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, completeIdempotencyKey,
+		arg.StatusCode,
+		arg.ContentType,
+		arg.ResponseBody,
+		arg.IdempotencyKeyID,
+	)
+	return err
+}
+
+const completeRefund = `-- name: CompleteRefund :execrows
+update refunds
+set
+    status = 'completed',
+    reference = $1,
+    updated_at = now()
+where id = $2 and status = 'pending'
+`
+
+type CompleteRefundParams struct {
+	Reference pgtype.Text
+	RefundID  int32
+}
+
+func (q *Queries) CompleteRefund(ctx context.Context, arg CompleteRefundParams) (int64, error) {
+	result, err := q.db.Exec(ctx, completeRefund, arg.Reference, arg.RefundID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createCheckin = `-- name: CreateCheckin :one
+insert into checkins (ticket_id, event_id, owner_id, gate, device)
 values ($1, $2, $3, $4, $5)
+on conflict (ticket_id) do nothing
+returning id, ticket_id, event_id, owner_id, gate, device, scanned_at, offline
+`
+
+type CreateCheckinParams struct {
+	TicketID int32
+	EventID  int32
+	OwnerID  int32
+	Gate     string
+	Device   string
+}
+
+// Records the ticket's admission to its event. Nothing is returned if the
+// ticket has already been checked in, so that the generated query will return
+// an error (`sql.ErrNoRows`).
+func (q *Queries) CreateCheckin(ctx context.Context, arg CreateCheckinParams) (Checkin, error) {
+	row := q.db.QueryRow(ctx, createCheckin,
+		arg.TicketID,
+		arg.EventID,
+		arg.OwnerID,
+		arg.Gate,
+		arg.Device,
+	)
+	var i Checkin
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.EventID,
+		&i.OwnerID,
+		&i.Gate,
+		&i.Device,
+		&i.ScannedAt,
+		&i.Offline,
+	)
+	return i, err
+}
+
+const createEvent = `-- name: CreateEvent :one
+insert into events (
+    venue_id,
+    name,
+    starts_at,
+    ends_at,
+    description,
+    max_tickets_per_user,
+    resale_min_rate,
+    resale_max_rate
+)
+values (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
 returning id
 `
 
 type CreateEventParams struct {
-	VenueID     int32
-	Name        string
-	StartsAt    pgtype.Timestamptz
-	EndsAt      pgtype.Timestamptz
-	Description pgtype.Text
+	VenueID           int32
+	Name              string
+	StartsAt          pgtype.Timestamptz
+	EndsAt            pgtype.Timestamptz
+	Description       pgtype.Text
+	MaxTicketsPerUser pgtype.Int4
+	ResaleMinRate     pgtype.Int4
+	ResaleMaxRate     pgtype.Int4
 }
 
 func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (int32, error) {
@@ -32,6 +273,542 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (int32
 		arg.StartsAt,
 		arg.EndsAt,
 		arg.Description,
+		arg.MaxTicketsPerUser,
+		arg.ResaleMinRate,
+		arg.ResaleMaxRate,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createGaTier = `-- name: CreateGaTier :one
+insert into ga_tiers (event_id, name, price, currency, capacity)
+select events.id, $1, $2, $3, $4
+from events
+where
+    events.id = $5
+    and events.deleted = false
+    and not exists (
+        select 1
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.currency <> $3
+    )
+    and not exists (
+        select 1
+        from ga_tiers
+        where
+            ga_tiers.event_id = events.id
+            and ga_tiers.currency <> $3
+    )
+    and not exists (
+        select 1
+        from ticket_types
+        where
+            ticket_types.event_id = events.id
+            and ticket_types.currency <> $3
+    )
+returning id
+`
+
+type CreateGaTierParams struct {
+	Name     string
+	Price    int64
+	Currency string
+	Capacity int32
+	EventID  int32
+}
+
+// The inserted record's id is returned so that the generated query will return
+// an error (`sql.ErrNoRows`) if the event doesn't exist, or if the event's
+// tickets are priced in another currency.
+func (q *Queries) CreateGaTier(ctx context.Context, arg CreateGaTierParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createGaTier,
+		arg.Name,
+		arg.Price,
+		arg.Currency,
+		arg.Capacity,
+		arg.EventID,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createOfflineCheckin = `-- name: CreateOfflineCheckin :one
+insert into checkins (ticket_id, event_id, owner_id, gate, device, scanned_at, offline)
+values ($1, $2, $3, $4, $5, $6, true)
+on conflict (ticket_id) do update
+set
+    owner_id = excluded.owner_id,
+    gate = excluded.gate,
+    device = excluded.device,
+    scanned_at = excluded.scanned_at
+where checkins.offline and checkins.scanned_at > excluded.scanned_at
+returning id, ticket_id, event_id, owner_id, gate, device, scanned_at, offline
+`
+
+type CreateOfflineCheckinParams struct {
+	TicketID  int32
+	EventID   int32
+	OwnerID   int32
+	Gate      string
+	Device    string
+	ScannedAt pgtype.Timestamptz
+}
+
+// Records the ticket's admission to its event, as scanned while offline. Of
+// the ticket's offline admissions, the earliest is kept, so that admissions
+// recorded by devices that were offline are reconciled by when they were
+// scanned. An admission made online is never replaced. Nothing is returned if
+// the ticket was already admitted online, or earlier, so that the generated
+// query will return an error (`sql.ErrNoRows`).
+func (q *Queries) CreateOfflineCheckin(ctx context.Context, arg CreateOfflineCheckinParams) (Checkin, error) {
+	row := q.db.QueryRow(ctx, createOfflineCheckin,
+		arg.TicketID,
+		arg.EventID,
+		arg.OwnerID,
+		arg.Gate,
+		arg.Device,
+		arg.ScannedAt,
+	)
+	var i Checkin
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.EventID,
+		&i.OwnerID,
+		&i.Gate,
+		&i.Device,
+		&i.ScannedAt,
+		&i.Offline,
+	)
+	return i, err
+}
+
+const createOrder = `-- name: CreateOrder :one
+insert into orders (
+    purchaser_id,
+    payment_id,
+    status,
+    total,
+    currency,
+    facility_fee,
+    tax,
+    promo_code_id,
+    discount
+)
+values (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+returning id
+`
+
+type CreateOrderParams struct {
+	PurchaserID int32
+	PaymentID   int32
+	Status      string
+	Total       int64
+	Currency    string
+	FacilityFee int64
+	Tax         int64
+	PromoCodeID pgtype.Int4
+	Discount    int64
+}
+
+func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createOrder,
+		arg.PurchaserID,
+		arg.PaymentID,
+		arg.Status,
+		arg.Total,
+		arg.Currency,
+		arg.FacilityFee,
+		arg.Tax,
+		arg.PromoCodeID,
+		arg.Discount,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createPayment = `-- name: CreatePayment :one
+insert into payments (purchaser_id, amount, currency, status)
+values ($1, $2, $3, $4)
+returning id
+`
+
+type CreatePaymentParams struct {
+	PurchaserID int32
+	Amount      int64
+	Currency    string
+	Status      string
+}
+
+func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createPayment,
+		arg.PurchaserID,
+		arg.Amount,
+		arg.Currency,
+		arg.Status,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createPresale = `-- name: CreatePresale :one
+insert into presales (release_id, name, starts_at, ends_at, access_code)
+values ($1, $2, $3, $4, $5)
+returning id
+`
+
+type CreatePresaleParams struct {
+	ReleaseID  int32
+	Name       string
+	StartsAt   pgtype.Timestamptz
+	EndsAt     pgtype.Timestamptz
+	AccessCode pgtype.Text
+}
+
+func (q *Queries) CreatePresale(ctx context.Context, arg CreatePresaleParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createPresale,
+		arg.ReleaseID,
+		arg.Name,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.AccessCode,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createPromoCode = `-- name: CreatePromoCode :one
+insert into promo_codes (
+    code,
+    discount_type,
+    percent_off,
+    amount_off,
+    currency,
+    event_id,
+    starts_at,
+    ends_at,
+    max_redemptions,
+    max_redemptions_per_user
+)
+select
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6::int,
+    $7,
+    $8,
+    $9,
+    $10
+where
+    $6::int is null
+    or exists (
+        select 1
+        from events
+        where
+            events.id = $6::int
+            and events.deleted = false
+    )
+returning id
+`
+
+type CreatePromoCodeParams struct {
+	Code                  string
+	DiscountType          string
+	PercentOff            int32
+	AmountOff             int64
+	Currency              pgtype.Text
+	EventID               pgtype.Int4
+	StartsAt              pgtype.Timestamptz
+	EndsAt                pgtype.Timestamptz
+	MaxRedemptions        pgtype.Int4
+	MaxRedemptionsPerUser pgtype.Int4
+}
+
+// The inserted record's id is returned so that the generated query will return
+// an error (`sql.ErrNoRows`) if the code is for an event that doesn't exist.
+func (q *Queries) CreatePromoCode(ctx context.Context, arg CreatePromoCodeParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createPromoCode,
+		arg.Code,
+		arg.DiscountType,
+		arg.PercentOff,
+		arg.AmountOff,
+		arg.Currency,
+		arg.EventID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.MaxRedemptions,
+		arg.MaxRedemptionsPerUser,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createRefund = `-- name: CreateRefund :one
+insert into refunds (payment_id, ticket_id, amount, facility_fee, status)
+values ($1, $2, $3, $4, 'pending')
+returning id
+`
+
+type CreateRefundParams struct {
+	PaymentID   int32
+	TicketID    int32
+	Amount      int64
+	FacilityFee int64
+}
+
+// Refunds are created as pending, and completed once the payment processor has
+// made them.
+func (q *Queries) CreateRefund(ctx context.Context, arg CreateRefundParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createRefund,
+		arg.PaymentID,
+		arg.TicketID,
+		arg.Amount,
+		arg.FacilityFee,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createResaleListing = `-- name: CreateResaleListing :one
+insert into resale_listings (ticket_id, seller_id, price, currency)
+values ($1, $2, $3, $4)
+returning id, ticket_id, seller_id, price, currency, status, created_at, updated_at
+`
+
+type CreateResaleListingParams struct {
+	TicketID int32
+	SellerID int32
+	Price    int64
+	Currency string
+}
+
+func (q *Queries) CreateResaleListing(ctx context.Context, arg CreateResaleListingParams) (ResaleListing, error) {
+	row := q.db.QueryRow(ctx, createResaleListing,
+		arg.TicketID,
+		arg.SellerID,
+		arg.Price,
+		arg.Currency,
+	)
+	var i ResaleListing
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.SellerID,
+		&i.Price,
+		&i.Currency,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createResalePayout = `-- name: CreateResalePayout :one
+insert into resale_payouts (listing_id, seller_id, payment_id, amount, currency)
+values ($1, $2, $3, $4, $5)
+returning id
+`
+
+type CreateResalePayoutParams struct {
+	ListingID int32
+	SellerID  int32
+	PaymentID int32
+	Amount    int64
+	Currency  string
+}
+
+func (q *Queries) CreateResalePayout(ctx context.Context, arg CreateResalePayoutParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createResalePayout,
+		arg.ListingID,
+		arg.SellerID,
+		arg.PaymentID,
+		arg.Amount,
+		arg.Currency,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createTicketCredential = `-- name: CreateTicketCredential :exec
+insert into ticket_credentials (ticket_id, owner_id, nonce)
+select $1, $2, $3
+where exists (
+    select 1
+    from tickets
+    where
+        id = $1
+        and purchaser_id = $2
+        and voided = false
+)
+on conflict (ticket_id) do update
+set
+    owner_id = excluded.owner_id,
+    nonce = excluded.nonce,
+    rotated_at = now()
+where ticket_credentials.owner_id <> excluded.owner_id
+`
+
+type CreateTicketCredentialParams struct {
+	TicketID int32
+	OwnerID  int32
+	Nonce    string
+}
+
+// Creates the owner's credential for the ticket, replacing a credential issued
+// to a previous owner. An existing credential of the owner is kept, so that
+// concurrent requests agree on the nonce.
+func (q *Queries) CreateTicketCredential(ctx context.Context, arg CreateTicketCredentialParams) error {
+	_, err := q.db.Exec(ctx, createTicketCredential, arg.TicketID, arg.OwnerID, arg.Nonce)
+	return err
+}
+
+const createTicketRelease = `-- name: CreateTicketRelease :one
+insert into ticket_releases (event_id, on_sale_at, off_sale_at)
+select events.id, $1, $2
+from events
+where
+    events.id = $3
+    and events.deleted = false
+returning id
+`
+
+type CreateTicketReleaseParams struct {
+	OnSaleAt  pgtype.Timestamptz
+	OffSaleAt pgtype.Timestamptz
+	EventID   int32
+}
+
+// The inserted record's id is returned so that the generated query will return
+// an error (`sql.ErrNoRows`) if the event doesn't exist.
+func (q *Queries) CreateTicketRelease(ctx context.Context, arg CreateTicketReleaseParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createTicketRelease, arg.OnSaleAt, arg.OffSaleAt, arg.EventID)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createTicketTransfer = `-- name: CreateTicketTransfer :one
+insert into ticket_transfers (ticket_id, sender_id, recipient_id)
+values ($1, $2, $3)
+returning id, ticket_id, sender_id, recipient_id, status, created_at, updated_at
+`
+
+type CreateTicketTransferParams struct {
+	TicketID    int32
+	SenderID    int32
+	RecipientID int32
+}
+
+func (q *Queries) CreateTicketTransfer(ctx context.Context, arg CreateTicketTransferParams) (TicketTransfer, error) {
+	row := q.db.QueryRow(ctx, createTicketTransfer, arg.TicketID, arg.SenderID, arg.RecipientID)
+	var i TicketTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createTicketType = `-- name: CreateTicketType :one
+insert into ticket_types (
+    event_id,
+    name,
+    description,
+    price,
+    currency,
+    eligibility,
+    sale_starts_at,
+    sale_ends_at
+)
+select
+    events.id,
+    $1,
+    $2,
+    $3,
+    $4,
+    $5::text[],
+    $6,
+    $7
+from events
+where
+    events.id = $8
+    and events.deleted = false
+    and not exists (
+        select 1
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.currency <> $4
+    )
+    and not exists (
+        select 1
+        from ga_tiers
+        where
+            ga_tiers.event_id = events.id
+            and ga_tiers.currency <> $4
+    )
+    and not exists (
+        select 1
+        from ticket_types
+        where
+            ticket_types.event_id = events.id
+            and ticket_types.currency <> $4
+    )
+returning id
+`
+
+type CreateTicketTypeParams struct {
+	Name         string
+	Description  pgtype.Text
+	Price        int64
+	Currency     string
+	Eligibility  []string
+	SaleStartsAt pgtype.Timestamptz
+	SaleEndsAt   pgtype.Timestamptz
+	EventID      int32
+}
+
+// The inserted record's id is returned so that the generated query will return
+// an error (`sql.ErrNoRows`) if the event doesn't exist, or if the event's
+// tickets are priced in another currency.
+func (q *Queries) CreateTicketType(ctx context.Context, arg CreateTicketTypeParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createTicketType,
+		arg.Name,
+		arg.Description,
+		arg.Price,
+		arg.Currency,
+		arg.Eligibility,
+		arg.SaleStartsAt,
+		arg.SaleEndsAt,
+		arg.EventID,
 	)
 	var id int32
 	err := row.Scan(&id)
@@ -75,6 +852,18 @@ with delete_event as (
         id = $1
         and deleted = false
     returning id
+), cancel_event as (
+    insert into event_cancellations (event_id, status)
+    select id, 'pending'
+    from delete_event
+    returning id, event_id
+), queue_refunds as (
+    -- Queue a refund for every purchased ticket to the cancelled event.
+    insert into cancellation_refunds (cancellation_id, ticket_id, status)
+    select cancel_event.id, tickets.id, 'pending'
+    from cancel_event
+    inner join tickets on cancel_event.event_id = tickets.event_id
+    where tickets.purchaser_id is not null
 )
 select count(*) from delete_event
 `
@@ -86,12 +875,68 @@ func (q *Queries) DeleteEvent(ctx context.Context, eventID int32) (int64, error)
 	return count, err
 }
 
-const deleteVenue = `-- name: DeleteVenue :one
-with delete_events as (
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+delete from idempotency_keys
+where expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+delete from idempotency_keys
+where id = $1
+`
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, idempotencyKeyID int32) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, idempotencyKeyID)
+	return err
+}
+
+const deletePromoCode = `-- name: DeletePromoCode :execrows
+update promo_codes
+set
+    deleted = true,
+    updated_at = now()
+where
+    id = $1
+    and deleted = false
+`
+
+func (q *Queries) DeletePromoCode(ctx context.Context, promoCodeID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePromoCode, promoCodeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteVenue = `-- name: DeleteVenue :one
+with delete_events as (
     -- Cascade delete to events.
     update events
     set deleted = true
-    where venue_id = $1
+    where
+        venue_id = $1
+        and deleted = false
+    returning id
+), cancel_events as (
+    insert into event_cancellations (event_id, status)
+    select id, 'pending'
+    from delete_events
+    returning id, event_id
+), queue_refunds as (
+    -- Queue a refund for every purchased ticket to the cancelled events.
+    insert into cancellation_refunds (cancellation_id, ticket_id, status)
+    select cancel_events.id, tickets.id, 'pending'
+    from cancel_events
+    inner join tickets on cancel_events.event_id = tickets.event_id
+    where tickets.purchaser_id is not null
 ), delete_venue as (
     update venues
     set deleted = true
@@ -110,20 +955,262 @@ func (q *Queries) DeleteVenue(ctx context.Context, venueID int32) (int64, error)
 	return count, err
 }
 
+const deleteVenueLayout = `-- name: DeleteVenueLayout :execrows
+delete from venue_sections
+where venue_id = $1
+`
+
+// Rows and seats are deleted along with their sections.
+func (q *Queries) DeleteVenueLayout(ctx context.Context, venueID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteVenueLayout, venueID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteWaitingRoom = `-- name: DeleteWaitingRoom :execrows
+delete from waiting_rooms
+where event_id = $1
+`
+
+func (q *Queries) DeleteWaitingRoom(ctx context.Context, eventID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWaitingRoom, eventID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteWaitlistEntry = `-- name: DeleteWaitlistEntry :execrows
+delete from waitlist_entries
+where
+    event_id = $1
+    and user_id = $2
+`
+
+type DeleteWaitlistEntryParams struct {
+	EventID int32
+	UserID  int32
+}
+
+func (q *Queries) DeleteWaitlistEntry(ctx context.Context, arg DeleteWaitlistEntryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWaitlistEntry, arg.EventID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const eventExists = `-- name: EventExists :one
+select exists (
+    select 1
+    from events
+    where
+        id = $1
+        and deleted = false
+)
+`
+
+func (q *Queries) EventExists(ctx context.Context, eventID int32) (bool, error) {
+	row := q.db.QueryRow(ctx, eventExists, eventID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const failRefund = `-- name: FailRefund :execrows
+update refunds
+set
+    status = 'failed',
+    updated_at = now()
+where id = $1 and status = 'pending'
+`
+
+func (q *Queries) FailRefund(ctx context.Context, refundID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, failRefund, refundID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getActiveResaleListing = `-- name: GetActiveResaleListing :one
+select id, ticket_id, seller_id, price, currency, status, created_at, updated_at
+from resale_listings
+where
+    ticket_id = $1
+    and status = 'active'
+`
+
+func (q *Queries) GetActiveResaleListing(ctx context.Context, ticketID int32) (ResaleListing, error) {
+	row := q.db.QueryRow(ctx, getActiveResaleListing, ticketID)
+	var i ResaleListing
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.SellerID,
+		&i.Price,
+		&i.Currency,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getApplicableFeeRule = `-- name: GetApplicableFeeRule :one
+select fee_rules.id, fee_rules.venue_id, fee_rules.event_id, fee_rules.service_fee_rate, fee_rules.service_fee_flat, fee_rules.facility_fee, fee_rules.updated_at
+from fee_rules
+inner join events on
+    fee_rules.event_id = events.id
+    or fee_rules.venue_id = events.venue_id
+where
+    events.id = $1
+    and events.deleted = false
+order by fee_rules.event_id nulls last
+limit 1
+`
+
+// Gets the fee rule that applies to an event, preferring the event's own rule
+// over its venue's.
+func (q *Queries) GetApplicableFeeRule(ctx context.Context, eventID int32) (FeeRule, error) {
+	row := q.db.QueryRow(ctx, getApplicableFeeRule, eventID)
+	var i FeeRule
+	err := row.Scan(
+		&i.ID,
+		&i.VenueID,
+		&i.EventID,
+		&i.ServiceFeeRate,
+		&i.ServiceFeeFlat,
+		&i.FacilityFee,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAvailableSeatedTickets = `-- name: GetAvailableSeatedTickets :many
+with positioned_seats as (
+    select
+        venue_seats.id,
+        venue_seats.row_id,
+        venue_seats.number,
+        venue_seats.y,
+        row_number() over (
+            partition by venue_seats.row_id
+            order by venue_seats.x, venue_seats.id
+        )::int as position
+    from events
+    inner join venue_sections on events.venue_id = venue_sections.venue_id
+    inner join venue_rows on venue_sections.id = venue_rows.section_id
+    inner join venue_seats on venue_rows.id = venue_seats.row_id
+    where events.id = $1
+)
+select
+    tickets.id, tickets.event_id, tickets.purchaser_id, tickets.price, tickets.seat, tickets.voided, tickets.currency, tickets.venue_seat_id, tickets.ga_tier_id, tickets.ticket_type_id, tickets.release_id,
+    venue_sections.name as section_name,
+    venue_rows.id as row_id,
+    venue_rows.name as row_name,
+    positioned_seats.number as seat_number,
+    positioned_seats.position,
+    positioned_seats.y
+from tickets
+inner join events on tickets.event_id = events.id
+inner join positioned_seats on tickets.venue_seat_id = positioned_seats.id
+inner join venue_rows on positioned_seats.row_id = venue_rows.id
+inner join venue_sections on venue_rows.section_id = venue_sections.id
+left join ticket_types on tickets.ticket_type_id = ticket_types.id
+left join ticket_releases on tickets.release_id = ticket_releases.id
+where
+    tickets.purchaser_id is null
+    and tickets.voided = false
+    and tickets.event_id = $1
+    and events.deleted = false
+    and (ticket_types.sale_starts_at is null or ticket_types.sale_starts_at <= now())
+    and (ticket_types.sale_ends_at is null or ticket_types.sale_ends_at > now())
+    and (ticket_releases.on_sale_at is null or ticket_releases.on_sale_at <= now())
+    and (ticket_releases.off_sale_at is null or ticket_releases.off_sale_at > now())
+order by venue_sections.id, venue_rows.id, positioned_seats.position
+`
+
+type GetAvailableSeatedTicketsRow struct {
+	Ticket      Ticket
+	SectionName string
+	RowID       int32
+	RowName     string
+	SeatNumber  string
+	Position    int32
+	Y           float64
+}
+
+// Available tickets for seats of the event's venue's layout, along with where
+// each seat is. Seats are numbered by their position within their row, from
+// left to right, so that adjacent seats have consecutive positions. Tickets of
+// a type or release that isn't on sale aren't available, including those that
+// are only on presale.
+func (q *Queries) GetAvailableSeatedTickets(ctx context.Context, eventID int32) ([]GetAvailableSeatedTicketsRow, error) {
+	rows, err := q.db.Query(ctx, getAvailableSeatedTickets, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAvailableSeatedTicketsRow
+	for rows.Next() {
+		var i GetAvailableSeatedTicketsRow
+		if err := rows.Scan(
+			&i.Ticket.ID,
+			&i.Ticket.EventID,
+			&i.Ticket.PurchaserID,
+			&i.Ticket.Price,
+			&i.Ticket.Seat,
+			&i.Ticket.Voided,
+			&i.Ticket.Currency,
+			&i.Ticket.VenueSeatID,
+			&i.Ticket.GaTierID,
+			&i.Ticket.TicketTypeID,
+			&i.Ticket.ReleaseID,
+			&i.SectionName,
+			&i.RowID,
+			&i.RowName,
+			&i.SeatNumber,
+			&i.Position,
+			&i.Y,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAvailableTickets = `-- name: GetAvailableTickets :many
-select tickets.id, tickets.event_id, tickets.purchaser_id, tickets.price, tickets.seat
+select tickets.id, tickets.event_id, tickets.purchaser_id, tickets.price, tickets.seat, tickets.voided, tickets.currency, tickets.venue_seat_id, tickets.ga_tier_id, tickets.ticket_type_id, tickets.release_id
 from tickets
 inner join events on tickets.event_id = events.id
+left join ticket_types on tickets.ticket_type_id = ticket_types.id
+left join ticket_releases on tickets.release_id = ticket_releases.id
 where 
     tickets.purchaser_id is null
+    and tickets.voided = false
+    and tickets.ga_tier_id is null
     and tickets.event_id = $1
     and events.deleted = false
+    and (ticket_types.sale_starts_at is null or ticket_types.sale_starts_at <= now())
+    and (ticket_types.sale_ends_at is null or ticket_types.sale_ends_at > now())
+    and (ticket_releases.off_sale_at is null or ticket_releases.off_sale_at > now())
 `
 
 type GetAvailableTicketsRow struct {
 	Ticket Ticket
 }
 
+// General admission tickets are only issued as they're purchased, so they're
+// never available. Tickets of a type that isn't on sale aren't available, nor
+// are tickets of a release that has gone off sale - tickets of a release that
+// isn't on sale yet are, so that its schedule can be shown.
 func (q *Queries) GetAvailableTickets(ctx context.Context, eventID int32) ([]GetAvailableTicketsRow, error) {
 	rows, err := q.db.Query(ctx, getAvailableTickets, eventID)
 	if err != nil {
@@ -139,6 +1226,12 @@ func (q *Queries) GetAvailableTickets(ctx context.Context, eventID int32) ([]Get
 			&i.Ticket.PurchaserID,
 			&i.Ticket.Price,
 			&i.Ticket.Seat,
+			&i.Ticket.Voided,
+			&i.Ticket.Currency,
+			&i.Ticket.VenueSeatID,
+			&i.Ticket.GaTierID,
+			&i.Ticket.TicketTypeID,
+			&i.Ticket.ReleaseID,
 		); err != nil {
 			return nil, err
 		}
@@ -152,7 +1245,7 @@ func (q *Queries) GetAvailableTickets(ctx context.Context, eventID int32) ([]Get
 
 const getEvent = `-- name: GetEvent :many
 select
-    events.id, events.venue_id, events.name, events.starts_at, events.ends_at, events.description, events.deleted,
+    events.id, events.venue_id, events.name, events.starts_at, events.ends_at, events.description, events.deleted, events.max_tickets_per_user, events.resale_min_rate, events.resale_max_rate,
     venues.name as venue_name,
     performers.id as performer_id,
     performers.name as performer_name
@@ -190,6 +1283,9 @@ func (q *Queries) GetEvent(ctx context.Context, eventID int32) ([]GetEventRow, e
 			&i.Event.EndsAt,
 			&i.Event.Description,
 			&i.Event.Deleted,
+			&i.Event.MaxTicketsPerUser,
+			&i.Event.ResaleMinRate,
+			&i.Event.ResaleMaxRate,
 			&i.VenueName,
 			&i.PerformerID,
 			&i.PerformerName,
@@ -204,119 +1300,2309 @@ func (q *Queries) GetEvent(ctx context.Context, eventID int32) ([]GetEventRow, e
 	return items, nil
 }
 
-const getTicket = `-- name: GetTicket :one
-select tickets.id, tickets.event_id, tickets.purchaser_id, tickets.price, tickets.seat
-from tickets
-inner join events on tickets.event_id = events.id
-where 
-    tickets.id = $1
+const getEventAttendance = `-- name: GetEventAttendance :one
+select
+    events.id as event_id,
+    (
+        select count(*)
+        from checkins
+        where checkins.event_id = events.id
+    ) as checked_in,
+    (
+        select count(*)
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.purchaser_id is not null
+            and tickets.voided = false
+    ) as purchased
+from events
+where
+    events.id = $1
     and events.deleted = false
 `
 
-type GetTicketRow struct {
-	Ticket Ticket
+type GetEventAttendanceRow struct {
+	EventID   int32
+	CheckedIn int64
+	Purchased int64
 }
 
-func (q *Queries) GetTicket(ctx context.Context, ticketID int32) (GetTicketRow, error) {
-	row := q.db.QueryRow(ctx, getTicket, ticketID)
-	var i GetTicketRow
+// Counts the event's checked in tickets, along with its purchased tickets.
+func (q *Queries) GetEventAttendance(ctx context.Context, eventID int32) (GetEventAttendanceRow, error) {
+	row := q.db.QueryRow(ctx, getEventAttendance, eventID)
+	var i GetEventAttendanceRow
+	err := row.Scan(&i.EventID, &i.CheckedIn, &i.Purchased)
+	return i, err
+}
+
+const getEventCancellation = `-- name: GetEventCancellation :one
+select
+    event_cancellations.id, event_cancellations.event_id, event_cancellations.status, event_cancellations.created_at, event_cancellations.updated_at,
+    count(cancellation_refunds.id) as total_count,
+    count(cancellation_refunds.id) filter (where cancellation_refunds.status = 'pending') as pending_count,
+    count(cancellation_refunds.id) filter (where cancellation_refunds.status = 'refunded') as refunded_count,
+    count(cancellation_refunds.id) filter (where cancellation_refunds.status = 'failed') as failed_count,
+    count(cancellation_refunds.id) filter (where cancellation_refunds.status = 'skipped') as skipped_count
+from event_cancellations
+left outer join cancellation_refunds on event_cancellations.id = cancellation_refunds.cancellation_id
+where event_cancellations.event_id = $1
+group by event_cancellations.id
+`
+
+type GetEventCancellationRow struct {
+	EventCancellation EventCancellation
+	TotalCount        int64
+	PendingCount      int64
+	RefundedCount     int64
+	FailedCount       int64
+	SkippedCount      int64
+}
+
+func (q *Queries) GetEventCancellation(ctx context.Context, eventID int32) (GetEventCancellationRow, error) {
+	row := q.db.QueryRow(ctx, getEventCancellation, eventID)
+	var i GetEventCancellationRow
 	err := row.Scan(
-		&i.Ticket.ID,
-		&i.Ticket.EventID,
-		&i.Ticket.PurchaserID,
-		&i.Ticket.Price,
-		&i.Ticket.Seat,
+		&i.EventCancellation.ID,
+		&i.EventCancellation.EventID,
+		&i.EventCancellation.Status,
+		&i.EventCancellation.CreatedAt,
+		&i.EventCancellation.UpdatedAt,
+		&i.TotalCount,
+		&i.PendingCount,
+		&i.RefundedCount,
+		&i.FailedCount,
+		&i.SkippedCount,
+	)
+	return i, err
+}
+
+const getEventCurrencies = `-- name: GetEventCurrencies :many
+select currency
+from tickets
+where event_id = $1
+union
+select currency
+from ga_tiers
+where event_id = $1
+union
+select currency
+from ticket_types
+where event_id = $1
+`
+
+func (q *Queries) GetEventCurrencies(ctx context.Context, eventID int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, getEventCurrencies, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var currency string
+		if err := rows.Scan(&currency); err != nil {
+			return nil, err
+		}
+		items = append(items, currency)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventFeeRule = `-- name: GetEventFeeRule :one
+select fee_rules.id, fee_rules.venue_id, fee_rules.event_id, fee_rules.service_fee_rate, fee_rules.service_fee_flat, fee_rules.facility_fee, fee_rules.updated_at
+from fee_rules
+inner join events on fee_rules.event_id = events.id
+where
+    events.id = $1
+    and events.deleted = false
+`
+
+func (q *Queries) GetEventFeeRule(ctx context.Context, eventID int32) (FeeRule, error) {
+	row := q.db.QueryRow(ctx, getEventFeeRule, eventID)
+	var i FeeRule
+	err := row.Scan(
+		&i.ID,
+		&i.VenueID,
+		&i.EventID,
+		&i.ServiceFeeRate,
+		&i.ServiceFeeFlat,
+		&i.FacilityFee,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getVenue = `-- name: GetVenue :one
-select venues.id, venues.name, venues.description, venues.address, venues.city, venues.subdivision, venues.country_code, venues.deleted
-from venues
+const getEventGaTiers = `-- name: GetEventGaTiers :many
+select ga_tiers.id, ga_tiers.event_id, ga_tiers.name, ga_tiers.price, ga_tiers.currency, ga_tiers.capacity, ga_tiers.sold
+from ga_tiers
+inner join events on ga_tiers.event_id = events.id
+where
+    ga_tiers.event_id = $1
+    and events.deleted = false
+order by ga_tiers.id
+`
+
+func (q *Queries) GetEventGaTiers(ctx context.Context, eventID int32) ([]GaTier, error) {
+	rows, err := q.db.Query(ctx, getEventGaTiers, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GaTier
+	for rows.Next() {
+		var i GaTier
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Name,
+			&i.Price,
+			&i.Currency,
+			&i.Capacity,
+			&i.Sold,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventResaleListings = `-- name: GetEventResaleListings :many
+select
+    resale_listings.id, resale_listings.ticket_id, resale_listings.seller_id, resale_listings.price, resale_listings.currency, resale_listings.status, resale_listings.created_at, resale_listings.updated_at,
+    tickets.seat,
+    tickets.price as face_value
+from resale_listings
+inner join tickets on resale_listings.ticket_id = tickets.id
+inner join events on tickets.event_id = events.id
+where
+    tickets.event_id = $1
+    and resale_listings.status = 'active'
+    and tickets.purchaser_id = resale_listings.seller_id
+    and tickets.voided = false
+    and events.deleted = false
+    and events.starts_at > now()
+order by resale_listings.price, resale_listings.id
+`
+
+type GetEventResaleListingsRow struct {
+	ResaleListing ResaleListing
+	Seat          string
+	FaceValue     int64
+}
+
+// Gets the active listings of the event's tickets, along with each ticket's
+// seat and face value. Listings of tickets that are no longer owned by their
+// seller, or whose event has started, can't be purchased, so aren't included.
+func (q *Queries) GetEventResaleListings(ctx context.Context, eventID int32) ([]GetEventResaleListingsRow, error) {
+	rows, err := q.db.Query(ctx, getEventResaleListings, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEventResaleListingsRow
+	for rows.Next() {
+		var i GetEventResaleListingsRow
+		if err := rows.Scan(
+			&i.ResaleListing.ID,
+			&i.ResaleListing.TicketID,
+			&i.ResaleListing.SellerID,
+			&i.ResaleListing.Price,
+			&i.ResaleListing.Currency,
+			&i.ResaleListing.Status,
+			&i.ResaleListing.CreatedAt,
+			&i.ResaleListing.UpdatedAt,
+			&i.Seat,
+			&i.FaceValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventTaxRate = `-- name: GetEventTaxRate :one
+select tax_rates.rate
+from events
+inner join venues on events.venue_id = venues.id
+inner join tax_rates on
+    tax_rates.country_code = venues.country_code
+    and tax_rates.subdivision in (venues.subdivision, '')
+where events.id = $1
+order by tax_rates.subdivision desc
+limit 1
+`
+
+// Gets the tax rate for the region of an event's venue, preferring the rate for
+// the venue's subdivision over the rate for its country.
+func (q *Queries) GetEventTaxRate(ctx context.Context, eventID int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getEventTaxRate, eventID)
+	var rate int32
+	err := row.Scan(&rate)
+	return rate, err
+}
+
+const getEventTicketTypes = `-- name: GetEventTicketTypes :many
+select ticket_types.id, ticket_types.event_id, ticket_types.name, ticket_types.description, ticket_types.price, ticket_types.currency, ticket_types.eligibility, ticket_types.sale_starts_at, ticket_types.sale_ends_at
+from ticket_types
+inner join events on ticket_types.event_id = events.id
+where
+    ticket_types.event_id = $1
+    and events.deleted = false
+order by ticket_types.id
+`
+
+func (q *Queries) GetEventTicketTypes(ctx context.Context, eventID int32) ([]TicketType, error) {
+	rows, err := q.db.Query(ctx, getEventTicketTypes, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TicketType
+	for rows.Next() {
+		var i TicketType
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Name,
+			&i.Description,
+			&i.Price,
+			&i.Currency,
+			&i.Eligibility,
+			&i.SaleStartsAt,
+			&i.SaleEndsAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventVenueSeats = `-- name: GetEventVenueSeats :many
+select
+    venue_seats.id,
+    venue_sections.name as section_name,
+    exists (
+        select 1
+        from tickets
+        where
+            tickets.event_id = events.id
+            and tickets.venue_seat_id = venue_seats.id
+            and tickets.voided = false
+    ) as released
+from events
+inner join venue_sections on events.venue_id = venue_sections.venue_id
+inner join venue_rows on venue_sections.id = venue_rows.section_id
+inner join venue_seats on venue_rows.id = venue_seats.row_id
+where
+    events.id = $1
+    and venue_seats.id = any($2::int[])
+`
+
+type GetEventVenueSeatsParams struct {
+	EventID int32
+	SeatIds []int32
+}
+
+type GetEventVenueSeatsRow struct {
+	ID          int32
+	SectionName string
+	Released    bool
+}
+
+// Of the given seats, those that are part of the event's venue's layout, and
+// whether each already has a ticket for the event.
+func (q *Queries) GetEventVenueSeats(ctx context.Context, arg GetEventVenueSeatsParams) ([]GetEventVenueSeatsRow, error) {
+	rows, err := q.db.Query(ctx, getEventVenueSeats, arg.EventID, arg.SeatIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEventVenueSeatsRow
+	for rows.Next() {
+		var i GetEventVenueSeatsRow
+		if err := rows.Scan(&i.ID, &i.SectionName, &i.Released); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExceededPurchaseLimits = `-- name: GetExceededPurchaseLimits :many
+select events.id
+from events
+inner join tickets on events.id = tickets.event_id
+where
+    events.id in (
+        select event_id
+        from tickets
+        where id = any($1::int[])
+    )
+    and tickets.purchaser_id = $2
+group by events.id
+having count(*) > min(events.max_tickets_per_user)
+`
+
+type GetExceededPurchaseLimitsParams struct {
+	TicketIds   []int32
+	PurchaserID pgtype.Int4
+}
+
+// Finds the events, of the given tickets, of which the purchaser has purchased
+// more tickets than the event's limit on tickets per user.
+func (q *Queries) GetExceededPurchaseLimits(ctx context.Context, arg GetExceededPurchaseLimitsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, getExceededPurchaseLimits, arg.TicketIds, arg.PurchaserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGaTier = `-- name: GetGaTier :one
+select ga_tiers.id, ga_tiers.event_id, ga_tiers.name, ga_tiers.price, ga_tiers.currency, ga_tiers.capacity, ga_tiers.sold
+from ga_tiers
+inner join events on ga_tiers.event_id = events.id
+where
+    ga_tiers.id = $1
+    and events.deleted = false
+`
+
+func (q *Queries) GetGaTier(ctx context.Context, gaTierID int32) (GaTier, error) {
+	row := q.db.QueryRow(ctx, getGaTier, gaTierID)
+	var i GaTier
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Price,
+		&i.Currency,
+		&i.Capacity,
+		&i.Sold,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+select id, user_id, key, fingerprint, status_code, content_type, response_body, created_at, expires_at
+from idempotency_keys
+where
+    user_id = $1
+    and key = $2
+    and expires_at > now()
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID string
+	Key    string
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.UserID, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Key,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&i.ContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getOrder = `-- name: GetOrder :many
+select
+    orders.id, orders.purchaser_id, orders.payment_id, orders.status, orders.total, orders.created_at, orders.updated_at, orders.currency, orders.facility_fee, orders.tax, orders.promo_code_id, orders.discount,
+    order_items.ticket_id as item_ticket_id,
+    order_items.price as item_price,
+    order_items.service_fee as item_service_fee,
+    order_items.tax as item_tax,
+    order_items.discount as item_discount
+from orders
+left outer join order_items on orders.id = order_items.order_id
+where orders.id = $1
+order by order_items.id
+`
+
+type GetOrderRow struct {
+	Order          Order
+	ItemTicketID   pgtype.Int4
+	ItemPrice      pgtype.Int8
+	ItemServiceFee pgtype.Int8
+	ItemTax        pgtype.Int8
+	ItemDiscount   pgtype.Int8
+}
+
+func (q *Queries) GetOrder(ctx context.Context, orderID int32) ([]GetOrderRow, error) {
+	rows, err := q.db.Query(ctx, getOrder, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderRow
+	for rows.Next() {
+		var i GetOrderRow
+		if err := rows.Scan(
+			&i.Order.ID,
+			&i.Order.PurchaserID,
+			&i.Order.PaymentID,
+			&i.Order.Status,
+			&i.Order.Total,
+			&i.Order.CreatedAt,
+			&i.Order.UpdatedAt,
+			&i.Order.Currency,
+			&i.Order.FacilityFee,
+			&i.Order.Tax,
+			&i.Order.PromoCodeID,
+			&i.Order.Discount,
+			&i.ItemTicketID,
+			&i.ItemPrice,
+			&i.ItemServiceFee,
+			&i.ItemTax,
+			&i.ItemDiscount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingCancellationRefunds = `-- name: GetPendingCancellationRefunds :many
+select id, cancellation_id, ticket_id, status, attempts, last_error, refund_id, updated_at
+from cancellation_refunds
+where status = 'pending'
+order by id
+limit $1
+`
+
+func (q *Queries) GetPendingCancellationRefunds(ctx context.Context, maxRefunds int32) ([]CancellationRefund, error) {
+	rows, err := q.db.Query(ctx, getPendingCancellationRefunds, maxRefunds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CancellationRefund
+	for rows.Next() {
+		var i CancellationRefund
+		if err := rows.Scan(
+			&i.ID,
+			&i.CancellationID,
+			&i.TicketID,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.RefundID,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingTicketTransfer = `-- name: GetPendingTicketTransfer :one
+select id, ticket_id, sender_id, recipient_id, status, created_at, updated_at
+from ticket_transfers
+where
+    ticket_id = $1
+    and status = 'pending'
+`
+
+func (q *Queries) GetPendingTicketTransfer(ctx context.Context, ticketID int32) (TicketTransfer, error) {
+	row := q.db.QueryRow(ctx, getPendingTicketTransfer, ticketID)
+	var i TicketTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPromoCode = `-- name: GetPromoCode :one
+select id, code, discount_type, percent_off, amount_off, currency, event_id, starts_at, ends_at, max_redemptions, max_redemptions_per_user, redemptions, deleted, created_at, updated_at
+from promo_codes
+where
+    id = $1
+    and deleted = false
+`
+
+func (q *Queries) GetPromoCode(ctx context.Context, promoCodeID int32) (PromoCode, error) {
+	row := q.db.QueryRow(ctx, getPromoCode, promoCodeID)
+	var i PromoCode
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.DiscountType,
+		&i.PercentOff,
+		&i.AmountOff,
+		&i.Currency,
+		&i.EventID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.MaxRedemptions,
+		&i.MaxRedemptionsPerUser,
+		&i.Redemptions,
+		&i.Deleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPromoCodeByCode = `-- name: GetPromoCodeByCode :one
+select id, code, discount_type, percent_off, amount_off, currency, event_id, starts_at, ends_at, max_redemptions, max_redemptions_per_user, redemptions, deleted, created_at, updated_at
+from promo_codes
+where
+    code = $1
+    and deleted = false
+`
+
+func (q *Queries) GetPromoCodeByCode(ctx context.Context, code string) (PromoCode, error) {
+	row := q.db.QueryRow(ctx, getPromoCodeByCode, code)
+	var i PromoCode
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.DiscountType,
+		&i.PercentOff,
+		&i.AmountOff,
+		&i.Currency,
+		&i.EventID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.MaxRedemptions,
+		&i.MaxRedemptionsPerUser,
+		&i.Redemptions,
+		&i.Deleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPurchaseLimits = `-- name: GetPurchaseLimits :many
+select
+    events.id as event_id,
+    events.max_tickets_per_user::int as max_tickets_per_user,
+    count(tickets.id)::int as purchased
+from events
+left outer join tickets on
+    events.id = tickets.event_id
+    and tickets.purchaser_id = $1
+where
+    events.id = any($2::int[])
+    and events.max_tickets_per_user is not null
+group by events.id
+`
+
+type GetPurchaseLimitsParams struct {
+	PurchaserID pgtype.Int4
+	EventIds    []int32
+}
+
+type GetPurchaseLimitsRow struct {
+	EventID           int32
+	MaxTicketsPerUser int32
+	Purchased         int32
+}
+
+// Gets the limit on tickets per user of each of the given events that has one,
+// along with how many of the event's tickets the purchaser has purchased.
+func (q *Queries) GetPurchaseLimits(ctx context.Context, arg GetPurchaseLimitsParams) ([]GetPurchaseLimitsRow, error) {
+	rows, err := q.db.Query(ctx, getPurchaseLimits, arg.PurchaserID, arg.EventIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPurchaseLimitsRow
+	for rows.Next() {
+		var i GetPurchaseLimitsRow
+		if err := rows.Scan(&i.EventID, &i.MaxTicketsPerUser, &i.Purchased); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getResaleListing = `-- name: GetResaleListing :one
+select id, ticket_id, seller_id, price, currency, status, created_at, updated_at
+from resale_listings
+where id = $1
+`
+
+func (q *Queries) GetResaleListing(ctx context.Context, listingID int32) (ResaleListing, error) {
+	row := q.db.QueryRow(ctx, getResaleListing, listingID)
+	var i ResaleListing
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.SellerID,
+		&i.Price,
+		&i.Currency,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRevokedTickets = `-- name: GetRevokedTickets :many
+select tickets.id
+from tickets
+where
+    tickets.event_id = $1
+    and (tickets.purchaser_id is null or tickets.voided = true)
+    and exists (
+        select 1
+        from refunds
+        where refunds.ticket_id = tickets.id
+    )
+order by tickets.id
+`
+
+// Gets the ids of the event's tickets that have been refunded, and haven't
+// been purchased again since.
+func (q *Queries) GetRevokedTickets(ctx context.Context, eventID int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, getRevokedTickets, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getScannerTickets = `-- name: GetScannerTickets :many
+select
+    tickets.id as ticket_id,
+    tickets.purchaser_id,
+    ticket_credentials.nonce,
+    checkins.scanned_at
+from tickets
+inner join events on tickets.event_id = events.id
+left join ticket_credentials
+    on tickets.id = ticket_credentials.ticket_id
+    and tickets.purchaser_id = ticket_credentials.owner_id
+left join checkins on tickets.id = checkins.ticket_id
+where
+    tickets.event_id = $1
+    and tickets.purchaser_id is not null
+    and tickets.voided = false
+    and events.deleted = false
+order by tickets.id
+`
+
+type GetScannerTicketsRow struct {
+	TicketID    int32
+	PurchaserID pgtype.Int4
+	Nonce       pgtype.Text
+	ScannedAt   pgtype.Timestamptz
+}
+
+// Gets the event's purchased tickets, along with their owner's credential's
+// nonce, if they have one, and when they were checked in, if they have been.
+func (q *Queries) GetScannerTickets(ctx context.Context, eventID int32) ([]GetScannerTicketsRow, error) {
+	rows, err := q.db.Query(ctx, getScannerTickets, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetScannerTicketsRow
+	for rows.Next() {
+		var i GetScannerTicketsRow
+		if err := rows.Scan(
+			&i.TicketID,
+			&i.PurchaserID,
+			&i.Nonce,
+			&i.ScannedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSellerResaleListings = `-- name: GetSellerResaleListings :many
+select id, ticket_id, seller_id, price, currency, status, created_at, updated_at
+from resale_listings
+where seller_id = $1
+order by created_at desc, id desc
+`
+
+// Gets the seller's listings, most recent first.
+func (q *Queries) GetSellerResaleListings(ctx context.Context, sellerID int32) ([]ResaleListing, error) {
+	rows, err := q.db.Query(ctx, getSellerResaleListings, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ResaleListing
+	for rows.Next() {
+		var i ResaleListing
+		if err := rows.Scan(
+			&i.ID,
+			&i.TicketID,
+			&i.SellerID,
+			&i.Price,
+			&i.Currency,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStaleAuthorizedPayments = `-- name: GetStaleAuthorizedPayments :many
+select id, purchaser_id, amount, status, reference, created_at, decline_reason, updated_at, currency
+from payments
+where status = 'authorized' and updated_at < $1
+order by updated_at
+limit $2
+`
+
+type GetStaleAuthorizedPaymentsParams struct {
+	UpdatedBefore pgtype.Timestamptz
+	MaxPayments   int32
+}
+
+func (q *Queries) GetStaleAuthorizedPayments(ctx context.Context, arg GetStaleAuthorizedPaymentsParams) ([]Payment, error) {
+	rows, err := q.db.Query(ctx, getStaleAuthorizedPayments, arg.UpdatedBefore, arg.MaxPayments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payment
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.ID,
+			&i.PurchaserID,
+			&i.Amount,
+			&i.Status,
+			&i.Reference,
+			&i.CreatedAt,
+			&i.DeclineReason,
+			&i.UpdatedAt,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStalePendingRefunds = `-- name: GetStalePendingRefunds :many
+select
+    refunds.id,
+    refunds.payment_id,
+    refunds.ticket_id,
+    refunds.amount,
+    payments.currency,
+    payments.reference as payment_reference
+from refunds
+inner join payments on refunds.payment_id = payments.id
+where refunds.status = 'pending' and refunds.updated_at < $1
+order by refunds.updated_at
+limit $2
+`
+
+type GetStalePendingRefundsParams struct {
+	UpdatedBefore pgtype.Timestamptz
+	MaxRefunds    int32
+}
+
+type GetStalePendingRefundsRow struct {
+	ID               int32
+	PaymentID        int32
+	TicketID         int32
+	Amount           int64
+	Currency         string
+	PaymentReference pgtype.Text
+}
+
+func (q *Queries) GetStalePendingRefunds(ctx context.Context, arg GetStalePendingRefundsParams) ([]GetStalePendingRefundsRow, error) {
+	rows, err := q.db.Query(ctx, getStalePendingRefunds, arg.UpdatedBefore, arg.MaxRefunds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStalePendingRefundsRow
+	for rows.Next() {
+		var i GetStalePendingRefundsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PaymentID,
+			&i.TicketID,
+			&i.Amount,
+			&i.Currency,
+			&i.PaymentReference,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTicket = `-- name: GetTicket :one
+select tickets.id, tickets.event_id, tickets.purchaser_id, tickets.price, tickets.seat, tickets.voided, tickets.currency, tickets.venue_seat_id, tickets.ga_tier_id, tickets.ticket_type_id, tickets.release_id
+from tickets
+inner join events on tickets.event_id = events.id
+where 
+    tickets.id = $1
+    and tickets.voided = false
+    and events.deleted = false
+`
+
+type GetTicketRow struct {
+	Ticket Ticket
+}
+
+func (q *Queries) GetTicket(ctx context.Context, ticketID int32) (GetTicketRow, error) {
+	row := q.db.QueryRow(ctx, getTicket, ticketID)
+	var i GetTicketRow
+	err := row.Scan(
+		&i.Ticket.ID,
+		&i.Ticket.EventID,
+		&i.Ticket.PurchaserID,
+		&i.Ticket.Price,
+		&i.Ticket.Seat,
+		&i.Ticket.Voided,
+		&i.Ticket.Currency,
+		&i.Ticket.VenueSeatID,
+		&i.Ticket.GaTierID,
+		&i.Ticket.TicketTypeID,
+		&i.Ticket.ReleaseID,
+	)
+	return i, err
+}
+
+const getTicketAdmission = `-- name: GetTicketAdmission :one
+select
+    tickets.id as ticket_id,
+    tickets.event_id,
+    tickets.purchaser_id,
+    ticket_credentials.nonce,
+    checkins.scanned_at
+from tickets
+inner join events on tickets.event_id = events.id
+left join ticket_credentials
+    on tickets.id = ticket_credentials.ticket_id
+    and tickets.purchaser_id = ticket_credentials.owner_id
+left join checkins on tickets.id = checkins.ticket_id
+where
+    tickets.id = $1
+    and tickets.voided = false
+    and events.deleted = false
+`
+
+type GetTicketAdmissionRow struct {
+	TicketID    int32
+	EventID     int32
+	PurchaserID pgtype.Int4
+	Nonce       pgtype.Text
+	ScannedAt   pgtype.Timestamptz
+}
+
+// Gets the ticket's event and owner, along with the nonce of its owner's
+// credential, if they have one, and when the ticket was checked in, if it has
+// been.
+func (q *Queries) GetTicketAdmission(ctx context.Context, ticketID int32) (GetTicketAdmissionRow, error) {
+	row := q.db.QueryRow(ctx, getTicketAdmission, ticketID)
+	var i GetTicketAdmissionRow
+	err := row.Scan(
+		&i.TicketID,
+		&i.EventID,
+		&i.PurchaserID,
+		&i.Nonce,
+		&i.ScannedAt,
+	)
+	return i, err
+}
+
+const getTicketCredential = `-- name: GetTicketCredential :one
+select
+    tickets.id, tickets.event_id, tickets.purchaser_id, tickets.price, tickets.seat, tickets.voided, tickets.currency, tickets.venue_seat_id, tickets.ga_tier_id, tickets.ticket_type_id, tickets.release_id,
+    ticket_credentials.nonce,
+    ticket_credentials.rotated_at
+from tickets
+inner join events on tickets.event_id = events.id
+left join ticket_credentials
+    on tickets.id = ticket_credentials.ticket_id
+    and tickets.purchaser_id = ticket_credentials.owner_id
+where
+    tickets.id = $1
+    and tickets.voided = false
+    and events.deleted = false
+`
+
+type GetTicketCredentialRow struct {
+	Ticket    Ticket
+	Nonce     pgtype.Text
+	RotatedAt pgtype.Timestamptz
+}
+
+// Gets the ticket, along with the nonce of its owner's credential and when it
+// was last rotated, if its owner has a credential.
+func (q *Queries) GetTicketCredential(ctx context.Context, ticketID int32) (GetTicketCredentialRow, error) {
+	row := q.db.QueryRow(ctx, getTicketCredential, ticketID)
+	var i GetTicketCredentialRow
+	err := row.Scan(
+		&i.Ticket.ID,
+		&i.Ticket.EventID,
+		&i.Ticket.PurchaserID,
+		&i.Ticket.Price,
+		&i.Ticket.Seat,
+		&i.Ticket.Voided,
+		&i.Ticket.Currency,
+		&i.Ticket.VenueSeatID,
+		&i.Ticket.GaTierID,
+		&i.Ticket.TicketTypeID,
+		&i.Ticket.ReleaseID,
+		&i.Nonce,
+		&i.RotatedAt,
+	)
+	return i, err
+}
+
+const getTicketOwnership = `-- name: GetTicketOwnership :one
+select
+    tickets.id as ticket_id,
+    tickets.purchaser_id,
+    events.starts_at
+from tickets
+inner join events on tickets.event_id = events.id
+where
+    tickets.id = $1
+    and tickets.voided = false
+    and events.deleted = false
+`
+
+type GetTicketOwnershipRow struct {
+	TicketID    int32
+	PurchaserID pgtype.Int4
+	StartsAt    pgtype.Timestamptz
+}
+
+// Gets the ticket's purchaser, if it has been purchased, along with when its
+// event starts.
+func (q *Queries) GetTicketOwnership(ctx context.Context, ticketID int32) (GetTicketOwnershipRow, error) {
+	row := q.db.QueryRow(ctx, getTicketOwnership, ticketID)
+	var i GetTicketOwnershipRow
+	err := row.Scan(&i.TicketID, &i.PurchaserID, &i.StartsAt)
+	return i, err
+}
+
+const getTicketPurchase = `-- name: GetTicketPurchase :one
+select
+    tickets.purchaser_id,
+    orders.purchaser_id as order_purchaser_id,
+    orders.id as order_id,
+    -- The price paid for the ticket, including its discount, fees and tax.
+    (order_items.price - order_items.discount + order_items.service_fee + order_items.tax)::bigint as price,
+    orders.currency,
+    payments.id as payment_id,
+    payments.reference as payment_reference
+from tickets
+inner join order_items on tickets.id = order_items.ticket_id
+inner join orders on order_items.order_id = orders.id
+inner join payments on orders.payment_id = payments.id
+where
+    tickets.id = $1
+    and tickets.purchaser_id is not null
+order by orders.created_at desc, orders.id desc
+limit 1
+`
+
+type GetTicketPurchaseRow struct {
+	PurchaserID      pgtype.Int4
+	OrderPurchaserID int32
+	OrderID          int32
+	Price            int64
+	Currency         string
+	PaymentID        int32
+	PaymentReference pgtype.Text
+}
+
+// Gets the most recent purchase of a ticket that is currently purchased.
+func (q *Queries) GetTicketPurchase(ctx context.Context, ticketID int32) (GetTicketPurchaseRow, error) {
+	row := q.db.QueryRow(ctx, getTicketPurchase, ticketID)
+	var i GetTicketPurchaseRow
+	err := row.Scan(
+		&i.PurchaserID,
+		&i.OrderPurchaserID,
+		&i.OrderID,
+		&i.Price,
+		&i.Currency,
+		&i.PaymentID,
+		&i.PaymentReference,
+	)
+	return i, err
+}
+
+const getTicketReleases = `-- name: GetTicketReleases :many
+select
+    ticket_releases.id,
+    ticket_releases.event_id,
+    ticket_releases.on_sale_at,
+    ticket_releases.off_sale_at,
+    presales.id as presale_id,
+    presales.name as presale_name,
+    presales.starts_at as presale_starts_at,
+    presales.ends_at as presale_ends_at,
+    presales.access_code as presale_access_code,
+    exists (
+        select 1
+        from presale_users
+        where presale_users.presale_id = presales.id
+    ) as presale_has_allowlist
+from ticket_releases
+left join presales on ticket_releases.id = presales.release_id
+where ticket_releases.id = any($1::int[])
+order by ticket_releases.id, presales.starts_at, presales.id
+`
+
+type GetTicketReleasesRow struct {
+	ID                  int32
+	EventID             int32
+	OnSaleAt            pgtype.Timestamptz
+	OffSaleAt           pgtype.Timestamptz
+	PresaleID           pgtype.Int4
+	PresaleName         pgtype.Text
+	PresaleStartsAt     pgtype.Timestamptz
+	PresaleEndsAt       pgtype.Timestamptz
+	PresaleAccessCode   pgtype.Text
+	PresaleHasAllowlist bool
+}
+
+// Releases along with their presales, if any, in the order that the presales
+// start.
+func (q *Queries) GetTicketReleases(ctx context.Context, releaseIds []int32) ([]GetTicketReleasesRow, error) {
+	rows, err := q.db.Query(ctx, getTicketReleases, releaseIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTicketReleasesRow
+	for rows.Next() {
+		var i GetTicketReleasesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.OnSaleAt,
+			&i.OffSaleAt,
+			&i.PresaleID,
+			&i.PresaleName,
+			&i.PresaleStartsAt,
+			&i.PresaleEndsAt,
+			&i.PresaleAccessCode,
+			&i.PresaleHasAllowlist,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTicketResaleTerms = `-- name: GetTicketResaleTerms :one
+select
+    tickets.id, tickets.event_id, tickets.purchaser_id, tickets.price, tickets.seat, tickets.voided, tickets.currency, tickets.venue_seat_id, tickets.ga_tier_id, tickets.ticket_type_id, tickets.release_id,
+    events.starts_at,
+    events.resale_min_rate,
+    events.resale_max_rate
+from tickets
+inner join events on tickets.event_id = events.id
+where
+    tickets.id = $1
+    and tickets.voided = false
+    and events.deleted = false
+`
+
+type GetTicketResaleTermsRow struct {
+	Ticket        Ticket
+	StartsAt      pgtype.Timestamptz
+	ResaleMinRate pgtype.Int4
+	ResaleMaxRate pgtype.Int4
+}
+
+// Gets the ticket, along with when its event starts and the event's bounds on
+// the price of a resale listing.
+func (q *Queries) GetTicketResaleTerms(ctx context.Context, ticketID int32) (GetTicketResaleTermsRow, error) {
+	row := q.db.QueryRow(ctx, getTicketResaleTerms, ticketID)
+	var i GetTicketResaleTermsRow
+	err := row.Scan(
+		&i.Ticket.ID,
+		&i.Ticket.EventID,
+		&i.Ticket.PurchaserID,
+		&i.Ticket.Price,
+		&i.Ticket.Seat,
+		&i.Ticket.Voided,
+		&i.Ticket.Currency,
+		&i.Ticket.VenueSeatID,
+		&i.Ticket.GaTierID,
+		&i.Ticket.TicketTypeID,
+		&i.Ticket.ReleaseID,
+		&i.StartsAt,
+		&i.ResaleMinRate,
+		&i.ResaleMaxRate,
+	)
+	return i, err
+}
+
+const getTicketTransfer = `-- name: GetTicketTransfer :one
+select id, ticket_id, sender_id, recipient_id, status, created_at, updated_at
+from ticket_transfers
+where id = $1
+`
+
+func (q *Queries) GetTicketTransfer(ctx context.Context, transferID int32) (TicketTransfer, error) {
+	row := q.db.QueryRow(ctx, getTicketTransfer, transferID)
+	var i TicketTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTicketTransfers = `-- name: GetTicketTransfers :many
+select id, ticket_id, sender_id, recipient_id, status, created_at, updated_at
+from ticket_transfers
+where ticket_id = $1
+order by created_at desc, id desc
+`
+
+// Gets the ticket's transfers, most recent first.
+func (q *Queries) GetTicketTransfers(ctx context.Context, ticketID int32) ([]TicketTransfer, error) {
+	rows, err := q.db.Query(ctx, getTicketTransfers, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TicketTransfer
+	for rows.Next() {
+		var i TicketTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.TicketID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTicketTypes = `-- name: GetTicketTypes :many
+select id, event_id, name, description, price, currency, eligibility, sale_starts_at, sale_ends_at
+from ticket_types
+where id = any($1::int[])
+`
+
+func (q *Queries) GetTicketTypes(ctx context.Context, ticketTypeIds []int32) ([]TicketType, error) {
+	rows, err := q.db.Query(ctx, getTicketTypes, ticketTypeIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TicketType
+	for rows.Next() {
+		var i TicketType
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Name,
+			&i.Description,
+			&i.Price,
+			&i.Currency,
+			&i.Eligibility,
+			&i.SaleStartsAt,
+			&i.SaleEndsAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTickets = `-- name: GetTickets :many
+select tickets.id, tickets.event_id, tickets.purchaser_id, tickets.price, tickets.seat, tickets.voided, tickets.currency, tickets.venue_seat_id, tickets.ga_tier_id, tickets.ticket_type_id, tickets.release_id
+from tickets
+inner join events on tickets.event_id = events.id
+where
+    tickets.id = any($1::int[])
+    and tickets.voided = false
+    and events.deleted = false
+`
+
+type GetTicketsRow struct {
+	Ticket Ticket
+}
+
+func (q *Queries) GetTickets(ctx context.Context, ticketIds []int32) ([]GetTicketsRow, error) {
+	rows, err := q.db.Query(ctx, getTickets, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTicketsRow
+	for rows.Next() {
+		var i GetTicketsRow
+		if err := rows.Scan(
+			&i.Ticket.ID,
+			&i.Ticket.EventID,
+			&i.Ticket.PurchaserID,
+			&i.Ticket.Price,
+			&i.Ticket.Seat,
+			&i.Ticket.Voided,
+			&i.Ticket.Currency,
+			&i.Ticket.VenueSeatID,
+			&i.Ticket.GaTierID,
+			&i.Ticket.TicketTypeID,
+			&i.Ticket.ReleaseID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnrefundedFacilityFee = `-- name: GetUnrefundedFacilityFee :one
+select
+    (
+        case
+            when exists (
+                select 1
+                from refunds
+                where
+                    refunds.payment_id = orders.payment_id
+                    and refunds.facility_fee > 0
+                    and refunds.status <> 'failed'
+            ) then 0
+            -- The order's tax includes the tax on each of its items.
+            else orders.facility_fee + orders.tax - (
+                select coalesce(sum(order_items.tax), 0)
+                from order_items
+                where order_items.order_id = orders.id
+            )
+        end
+    )::bigint as facility_fee,
+    (
+        select count(*)
+        from order_items
+        where
+            order_items.order_id = orders.id
+            and not exists (
+                select 1
+                from refunds
+                where
+                    refunds.payment_id = orders.payment_id
+                    and refunds.ticket_id = order_items.ticket_id
+            )
+    ) as count_unrefunded
+from orders
+where orders.id = $1
+for update
+`
+
+type GetUnrefundedFacilityFeeRow struct {
+	FacilityFee     int64
+	CountUnrefunded int64
+}
+
+// Gets the facility fee of an order, along with its tax, unless it has already
+// been refunded, and the number of the order's tickets that have yet to be
+// refunded. A facility fee whose refund failed is refunded again with the next
+// of the order's tickets to be refunded. The order is locked, so that its
+// facility fee is only refunded once.
+func (q *Queries) GetUnrefundedFacilityFee(ctx context.Context, orderID int32) (GetUnrefundedFacilityFeeRow, error) {
+	row := q.db.QueryRow(ctx, getUnrefundedFacilityFee, orderID)
+	var i GetUnrefundedFacilityFeeRow
+	err := row.Scan(&i.FacilityFee, &i.CountUnrefunded)
+	return i, err
+}
+
+const getUserOrders = `-- name: GetUserOrders :many
+select
+    orders.id, orders.purchaser_id, orders.payment_id, orders.status, orders.total, orders.created_at, orders.updated_at, orders.currency, orders.facility_fee, orders.tax, orders.promo_code_id, orders.discount,
+    order_items.ticket_id as item_ticket_id,
+    order_items.price as item_price,
+    order_items.service_fee as item_service_fee,
+    order_items.tax as item_tax,
+    order_items.discount as item_discount
+from orders
+left outer join order_items on orders.id = order_items.order_id
+where orders.purchaser_id = $1
+order by orders.created_at desc, orders.id, order_items.id
+`
+
+type GetUserOrdersRow struct {
+	Order          Order
+	ItemTicketID   pgtype.Int4
+	ItemPrice      pgtype.Int8
+	ItemServiceFee pgtype.Int8
+	ItemTax        pgtype.Int8
+	ItemDiscount   pgtype.Int8
+}
+
+func (q *Queries) GetUserOrders(ctx context.Context, purchaserID int32) ([]GetUserOrdersRow, error) {
+	rows, err := q.db.Query(ctx, getUserOrders, purchaserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserOrdersRow
+	for rows.Next() {
+		var i GetUserOrdersRow
+		if err := rows.Scan(
+			&i.Order.ID,
+			&i.Order.PurchaserID,
+			&i.Order.PaymentID,
+			&i.Order.Status,
+			&i.Order.Total,
+			&i.Order.CreatedAt,
+			&i.Order.UpdatedAt,
+			&i.Order.Currency,
+			&i.Order.FacilityFee,
+			&i.Order.Tax,
+			&i.Order.PromoCodeID,
+			&i.Order.Discount,
+			&i.ItemTicketID,
+			&i.ItemPrice,
+			&i.ItemServiceFee,
+			&i.ItemTax,
+			&i.ItemDiscount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserPromoCodeRedemptions = `-- name: GetUserPromoCodeRedemptions :one
+select redemptions
+from promo_code_redemptions
+where
+    promo_code_id = $1
+    and purchaser_id = $2
+`
+
+type GetUserPromoCodeRedemptionsParams struct {
+	PromoCodeID int32
+	PurchaserID int32
+}
+
+func (q *Queries) GetUserPromoCodeRedemptions(ctx context.Context, arg GetUserPromoCodeRedemptionsParams) (int32, error) {
+	row := q.db.QueryRow(ctx, getUserPromoCodeRedemptions, arg.PromoCodeID, arg.PurchaserID)
+	var redemptions int32
+	err := row.Scan(&redemptions)
+	return redemptions, err
+}
+
+const getUserTicketTransfers = `-- name: GetUserTicketTransfers :many
+select id, ticket_id, sender_id, recipient_id, status, created_at, updated_at
+from ticket_transfers
+where
+    sender_id = $1
+    or recipient_id = $1
+order by created_at desc, id desc
+`
+
+// Gets the transfers sent or received by the user, most recent first.
+func (q *Queries) GetUserTicketTransfers(ctx context.Context, userID int32) ([]TicketTransfer, error) {
+	rows, err := q.db.Query(ctx, getUserTicketTransfers, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TicketTransfer
+	for rows.Next() {
+		var i TicketTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.TicketID,
+			&i.SenderID,
+			&i.RecipientID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVenue = `-- name: GetVenue :one
+select venues.id, venues.name, venues.description, venues.address, venues.city, venues.subdivision, venues.country_code, venues.deleted
+from venues
+where
+    id = $1
+    and deleted = false
+`
+
+type GetVenueRow struct {
+	Venue Venue
+}
+
+func (q *Queries) GetVenue(ctx context.Context, venueID int32) (GetVenueRow, error) {
+	row := q.db.QueryRow(ctx, getVenue, venueID)
+	var i GetVenueRow
+	err := row.Scan(
+		&i.Venue.ID,
+		&i.Venue.Name,
+		&i.Venue.Description,
+		&i.Venue.Address,
+		&i.Venue.City,
+		&i.Venue.Subdivision,
+		&i.Venue.CountryCode,
+		&i.Venue.Deleted,
+	)
+	return i, err
+}
+
+const getVenueFeeRule = `-- name: GetVenueFeeRule :one
+select fee_rules.id, fee_rules.venue_id, fee_rules.event_id, fee_rules.service_fee_rate, fee_rules.service_fee_flat, fee_rules.facility_fee, fee_rules.updated_at
+from fee_rules
+inner join venues on fee_rules.venue_id = venues.id
+where
+    venues.id = $1
+    and venues.deleted = false
+`
+
+func (q *Queries) GetVenueFeeRule(ctx context.Context, venueID int32) (FeeRule, error) {
+	row := q.db.QueryRow(ctx, getVenueFeeRule, venueID)
+	var i FeeRule
+	err := row.Scan(
+		&i.ID,
+		&i.VenueID,
+		&i.EventID,
+		&i.ServiceFeeRate,
+		&i.ServiceFeeFlat,
+		&i.FacilityFee,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getVenueLayout = `-- name: GetVenueLayout :many
+select
+    venue_sections.id as section_id,
+    venue_sections.name as section_name,
+    venue_rows.id as row_id,
+    venue_rows.name as row_name,
+    venue_seats.id, venue_seats.row_id, venue_seats.number, venue_seats.x, venue_seats.y, venue_seats.accessible, venue_seats.obstructed
+from venue_sections
+inner join venue_rows on venue_sections.id = venue_rows.section_id
+inner join venue_seats on venue_rows.id = venue_seats.row_id
+where venue_sections.venue_id = $1
+order by venue_sections.id, venue_rows.id, venue_seats.id
+`
+
+type GetVenueLayoutRow struct {
+	SectionID   int32
+	SectionName string
+	RowID       int32
+	RowName     string
+	VenueSeat   VenueSeat
+}
+
+func (q *Queries) GetVenueLayout(ctx context.Context, venueID int32) ([]GetVenueLayoutRow, error) {
+	rows, err := q.db.Query(ctx, getVenueLayout, venueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetVenueLayoutRow
+	for rows.Next() {
+		var i GetVenueLayoutRow
+		if err := rows.Scan(
+			&i.SectionID,
+			&i.SectionName,
+			&i.RowID,
+			&i.RowName,
+			&i.VenueSeat.ID,
+			&i.VenueSeat.RowID,
+			&i.VenueSeat.Number,
+			&i.VenueSeat.X,
+			&i.VenueSeat.Y,
+			&i.VenueSeat.Accessible,
+			&i.VenueSeat.Obstructed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWaitingEntries = `-- name: GetWaitingEntries :many
+select event_id, user_id, quantity, joined_at, offer_token, offer_expires_at
+from waitlist_entries
+where
+    event_id = $1
+    and offer_token is null
+order by joined_at, user_id
+limit $2
+`
+
+type GetWaitingEntriesParams struct {
+	EventID    int32
+	MaxEntries int32
+}
+
+// Gets the entries of the users waiting on the event's waitlist, in the order
+// they joined.
+func (q *Queries) GetWaitingEntries(ctx context.Context, arg GetWaitingEntriesParams) ([]WaitlistEntry, error) {
+	rows, err := q.db.Query(ctx, getWaitingEntries, arg.EventID, arg.MaxEntries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WaitlistEntry
+	for rows.Next() {
+		var i WaitlistEntry
+		if err := rows.Scan(
+			&i.EventID,
+			&i.UserID,
+			&i.Quantity,
+			&i.JoinedAt,
+			&i.OfferToken,
+			&i.OfferExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWaitingRoom = `-- name: GetWaitingRoom :one
+select waiting_rooms.event_id, waiting_rooms.batch_size, waiting_rooms.updated_at
+from waiting_rooms
+inner join events on waiting_rooms.event_id = events.id
+where
+    events.id = $1
+    and events.deleted = false
+`
+
+func (q *Queries) GetWaitingRoom(ctx context.Context, eventID int32) (WaitingRoom, error) {
+	row := q.db.QueryRow(ctx, getWaitingRoom, eventID)
+	var i WaitingRoom
+	err := row.Scan(&i.EventID, &i.BatchSize, &i.UpdatedAt)
+	return i, err
+}
+
+const getWaitingRooms = `-- name: GetWaitingRooms :many
+select waiting_rooms.event_id, waiting_rooms.batch_size, waiting_rooms.updated_at
+from waiting_rooms
+inner join events on waiting_rooms.event_id = events.id
+where events.deleted = false
+order by waiting_rooms.event_id
+`
+
+func (q *Queries) GetWaitingRooms(ctx context.Context) ([]WaitingRoom, error) {
+	rows, err := q.db.Query(ctx, getWaitingRooms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WaitingRoom
+	for rows.Next() {
+		var i WaitingRoom
+		if err := rows.Scan(&i.EventID, &i.BatchSize, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWaitlistEntry = `-- name: GetWaitlistEntry :one
+select
+    waitlist_entries.event_id, waitlist_entries.user_id, waitlist_entries.quantity, waitlist_entries.joined_at, waitlist_entries.offer_token, waitlist_entries.offer_expires_at,
+    (
+        select count(*)
+        from waitlist_entries as ahead
+        where
+            ahead.event_id = waitlist_entries.event_id
+            and ahead.offer_token is null
+            and (ahead.joined_at, ahead.user_id) < (waitlist_entries.joined_at, waitlist_entries.user_id)
+    )::int as ahead
+from waitlist_entries
+inner join events on waitlist_entries.event_id = events.id
+where
+    waitlist_entries.event_id = $1
+    and waitlist_entries.user_id = $2
+    and events.deleted = false
+`
+
+type GetWaitlistEntryParams struct {
+	EventID int32
+	UserID  int32
+}
+
+type GetWaitlistEntryRow struct {
+	WaitlistEntry WaitlistEntry
+	Ahead         int32
+}
+
+// Gets the user's entry on the event's waitlist, along with the number of
+// users ahead of them that are still waiting.
+func (q *Queries) GetWaitlistEntry(ctx context.Context, arg GetWaitlistEntryParams) (GetWaitlistEntryRow, error) {
+	row := q.db.QueryRow(ctx, getWaitlistEntry, arg.EventID, arg.UserID)
+	var i GetWaitlistEntryRow
+	err := row.Scan(
+		&i.WaitlistEntry.EventID,
+		&i.WaitlistEntry.UserID,
+		&i.WaitlistEntry.Quantity,
+		&i.WaitlistEntry.JoinedAt,
+		&i.WaitlistEntry.OfferToken,
+		&i.WaitlistEntry.OfferExpiresAt,
+		&i.Ahead,
+	)
+	return i, err
+}
+
+const getWaitlistedEvents = `-- name: GetWaitlistedEvents :many
+select distinct waitlist_entries.event_id
+from waitlist_entries
+inner join events on waitlist_entries.event_id = events.id
+where
+    waitlist_entries.offer_token is null
+    and events.deleted = false
+order by waitlist_entries.event_id
+`
+
+// Gets the events with users waiting on their waitlist.
+func (q *Queries) GetWaitlistedEvents(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.Query(ctx, getWaitlistedEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var event_id int32
+		if err := rows.Scan(&event_id); err != nil {
+			return nil, err
+		}
+		items = append(items, event_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isPresaleUser = `-- name: IsPresaleUser :one
+select exists (
+    select 1
+    from presale_users
+    where
+        presale_id = $1
+        and user_id = $2
+)
+`
+
+type IsPresaleUserParams struct {
+	PresaleID int32
+	UserID    int32
+}
+
+func (q *Queries) IsPresaleUser(ctx context.Context, arg IsPresaleUserParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isPresaleUser, arg.PresaleID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const issueGaTickets = `-- name: IssueGaTickets :many
+with tier as (
+    update ga_tiers
+    set sold = sold + $1::int
+    from events
+    where
+        ga_tiers.id = $2
+        and ga_tiers.event_id = events.id
+        and events.deleted = false
+        and ga_tiers.sold + $1::int <= ga_tiers.capacity
+    returning ga_tiers.id, ga_tiers.event_id, ga_tiers.name, ga_tiers.price, ga_tiers.currency
+)
+insert into tickets (event_id, purchaser_id, price, currency, seat, ga_tier_id)
+select tier.event_id, null, tier.price, tier.currency, tier.name, tier.id
+from tier, generate_series(1, $1::int)
+returning id
+`
+
+type IssueGaTicketsParams struct {
+	Quantity int32
+	GaTierID int32
+}
+
+// Issues tickets for the tier only if it has the capacity for all of them, so
+// that a tier is never oversold.
+func (q *Queries) IssueGaTickets(ctx context.Context, arg IssueGaTicketsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, issueGaTickets, arg.Quantity, arg.GaTierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const linkUpdatedPerformers = `-- name: LinkUpdatedPerformers :exec
+with performer_ids as (
+    select id
+    from performers
+    where name = any($2::text[])
+), del as (
+    delete from event_performers
+    where
+        event_id = $1
+        and not exists (
+            select 1
+            from performer_ids
+            where performer_ids.id = event_performers.performer_id
+        )
+)
+insert into event_performers (event_id, performer_id)
+select $1, id
+from performer_ids
+on conflict (event_id, performer_id) do nothing
+`
+
+type LinkUpdatedPerformersParams struct {
+	EventID int32
+	Names   []string
+}
+
+func (q *Queries) LinkUpdatedPerformers(ctx context.Context, arg LinkUpdatedPerformersParams) error {
+	_, err := q.db.Exec(ctx, linkUpdatedPerformers, arg.EventID, arg.Names)
+	return err
+}
+
+const lockPurchaser = `-- name: LockPurchaser :exec
+select id
+from users
+where id = $1
+for no key update
+`
+
+// Serializes purchases by the same purchaser, so that concurrent purchases
+// can't exceed an event's limit on tickets per user together.
+func (q *Queries) LockPurchaser(ctx context.Context, purchaserID int32) error {
+	_, err := q.db.Exec(ctx, lockPurchaser, purchaserID)
+	return err
+}
+
+const redeemPromoCode = `-- name: RedeemPromoCode :execrows
+update promo_codes
+set
+    redemptions = redemptions + 1,
+    updated_at = now()
+where
+    id = $1
+    and deleted = false
+    and (starts_at is null or starts_at <= now())
+    and (ends_at is null or ends_at > now())
+    and (max_redemptions is null or redemptions < max_redemptions)
+`
+
+// Counts a redemption of the code, unless it can't be redeemed at this time or
+// has no redemptions left. The row lock taken by the update serializes
+// concurrent redemptions, so that the code can't be over-redeemed.
+func (q *Queries) RedeemPromoCode(ctx context.Context, promoCodeID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, redeemPromoCode, promoCodeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const redeemPromoCodeForUser = `-- name: RedeemPromoCodeForUser :execrows
+insert into promo_code_redemptions (promo_code_id, purchaser_id, redemptions)
+values ($1, $2, 1)
+on conflict (promo_code_id, purchaser_id) do update
+set redemptions = promo_code_redemptions.redemptions + 1
+where not exists (
+    select 1
+    from promo_codes
+    where
+        promo_codes.id = excluded.promo_code_id
+        and promo_codes.max_redemptions_per_user <= promo_code_redemptions.redemptions
+)
+`
+
+type RedeemPromoCodeForUserParams struct {
+	PromoCodeID int32
+	PurchaserID int32
+}
+
+// Counts a redemption of the code by a user, unless the user has no
+// redemptions of the code left.
+func (q *Queries) RedeemPromoCodeForUser(ctx context.Context, arg RedeemPromoCodeForUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, redeemPromoCodeForUser, arg.PromoCodeID, arg.PurchaserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const returnGaTickets = `-- name: ReturnGaTickets :execrows
+with returned as (
+    update tickets
+    set voided = true
+    where
+        id = any($1::int[])
+        and purchaser_id is null
+        and voided = false
+        and ga_tier_id is not null
+    returning ga_tier_id
+)
+update ga_tiers
+set sold = ga_tiers.sold - counts.quantity
+from (
+    select ga_tier_id, count(*)::int as quantity
+    from returned
+    group by ga_tier_id
+) as counts
+where ga_tiers.id = counts.ga_tier_id
+`
+
+// Voids issued tickets that weren't purchased, returning them to their tier's
+// capacity.
+func (q *Queries) ReturnGaTickets(ctx context.Context, ticketIds []int32) (int64, error) {
+	result, err := q.db.Exec(ctx, returnGaTickets, ticketIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rotateTicketCredential = `-- name: RotateTicketCredential :one
+insert into ticket_credentials (ticket_id, owner_id, nonce)
+select $1, $2, $3
+where exists (
+    select 1
+    from tickets
+    where
+        id = $1
+        and purchaser_id = $2
+        and voided = false
+)
+on conflict (ticket_id) do update
+set
+    owner_id = excluded.owner_id,
+    nonce = excluded.nonce,
+    rotated_at = now()
+returning ticket_id, owner_id, nonce, rotated_at
+`
+
+type RotateTicketCredentialParams struct {
+	TicketID int32
+	OwnerID  int32
+	Nonce    string
+}
+
+// Sets the nonce of the owner's credential for the ticket, as long as they
+// still own it.
+func (q *Queries) RotateTicketCredential(ctx context.Context, arg RotateTicketCredentialParams) (TicketCredential, error) {
+	row := q.db.QueryRow(ctx, rotateTicketCredential, arg.TicketID, arg.OwnerID, arg.Nonce)
+	var i TicketCredential
+	err := row.Scan(
+		&i.TicketID,
+		&i.OwnerID,
+		&i.Nonce,
+		&i.RotatedAt,
+	)
+	return i, err
+}
+
+const setPaymentRefunded = `-- name: SetPaymentRefunded :execrows
+update payments
+set
+    status = 'refunded',
+    updated_at = now()
+where
+    id = $1
+    and status = 'captured'
+    and amount <= (
+        select coalesce(sum(refunds.amount), 0)
+        from refunds
+        where refunds.payment_id = $1 and refunds.status = 'completed'
+    )
+`
+
+// The payment is only marked as refunded once all of it has been refunded.
+func (q *Queries) SetPaymentRefunded(ctx context.Context, paymentID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, setPaymentRefunded, paymentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setTicketPurchaser = `-- name: SetTicketPurchaser :one
+update tickets
+set purchaser_id = $1
+where
+    id = $2
+    and purchaser_id is null
+    and voided = false
+returning id
+`
+
+type SetTicketPurchaserParams struct {
+	PurchaserID pgtype.Int4
+	TicketID    int32
+}
+
+func (q *Queries) SetTicketPurchaser(ctx context.Context, arg SetTicketPurchaserParams) (int32, error) {
+	row := q.db.QueryRow(ctx, setTicketPurchaser, arg.PurchaserID, arg.TicketID)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const setTicketsPurchaser = `-- name: SetTicketsPurchaser :execrows
+with purchasable as (
+    select id
+    from tickets
+    where
+        id = any($1::int[])
+        and purchaser_id is null
+        and voided = false
+    for update
+)
+update tickets
+set purchaser_id = $2
+where
+    id in (select id from purchasable)
+    and (select count(*) from purchasable) = cardinality($1::int[])
+`
+
+type SetTicketsPurchaserParams struct {
+	TicketIds   []int32
+	PurchaserID pgtype.Int4
+}
+
+// Either all of the tickets are updated, or none are if any of them has already
+// been purchased, so that a set of held tickets is purchased as a whole.
+func (q *Queries) SetTicketsPurchaser(ctx context.Context, arg SetTicketsPurchaserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setTicketsPurchaser, arg.TicketIds, arg.PurchaserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setWaitlistOffer = `-- name: SetWaitlistOffer :execrows
+update waitlist_entries
+set
+    offer_token = $1,
+    offer_expires_at = $2
+where
+    event_id = $3
+    and user_id = $4
+    and offer_token is null
+`
+
+type SetWaitlistOfferParams struct {
+	OfferToken     pgtype.Text
+	OfferExpiresAt pgtype.Timestamptz
+	EventID        int32
+	UserID         int32
+}
+
+func (q *Queries) SetWaitlistOffer(ctx context.Context, arg SetWaitlistOfferParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setWaitlistOffer,
+		arg.OfferToken,
+		arg.OfferExpiresAt,
+		arg.EventID,
+		arg.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const transferTicket = `-- name: TransferTicket :execrows
+with cancel_listings as (
+    update resale_listings
+    set
+        status = 'cancelled',
+        updated_at = now()
+    where
+        ticket_id = $1
+        and seller_id = $2
+        and status = 'active'
+), remove_credentials as (
+    delete from ticket_credentials
+    where
+        ticket_id = $1
+        and owner_id = $2
+)
+update tickets
+set purchaser_id = $3
+where
+    id = $1
+    and purchaser_id = $2
+    and voided = false
+    and event_id in (
+        select id
+        from events
+        where
+            starts_at > now()
+            and deleted = false
+    )
+`
+
+type TransferTicketParams struct {
+	TicketID    int32
+	SenderID    int32
+	RecipientID pgtype.Int4
+}
+
+// Moves the ticket to the recipient, as long as it's still owned by the sender
+// and its event hasn't started. The sender's resale listing of the ticket, if
+// any, is cancelled, and their credential for it removed.
+func (q *Queries) TransferTicket(ctx context.Context, arg TransferTicketParams) (int64, error) {
+	result, err := q.db.Exec(ctx, transferTicket, arg.TicketID, arg.SenderID, arg.RecipientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const trimUpdatedEventPerformers = `-- name: TrimUpdatedEventPerformers :exec
+delete from event_performers
+where event_id = $1
+`
+
+func (q *Queries) TrimUpdatedEventPerformers(ctx context.Context, eventID int32) error {
+	_, err := q.db.Exec(ctx, trimUpdatedEventPerformers, eventID)
+	return err
+}
+
+const trimVenueRows = `-- name: TrimVenueRows :exec
+delete from venue_rows
+using venue_sections
 where
-    id = $1
-    and deleted = false
+    venue_rows.section_id = venue_sections.id
+    and venue_sections.venue_id = $1
+    and not venue_rows.id = any($2::int[])
 `
 
-type GetVenueRow struct {
-	Venue Venue
+type TrimVenueRowsParams struct {
+	VenueID int32
+	RowIds  []int32
 }
 
-func (q *Queries) GetVenue(ctx context.Context, venueID int32) (GetVenueRow, error) {
-	row := q.db.QueryRow(ctx, getVenue, venueID)
-	var i GetVenueRow
-	err := row.Scan(
-		&i.Venue.ID,
-		&i.Venue.Name,
-		&i.Venue.Description,
-		&i.Venue.Address,
-		&i.Venue.City,
-		&i.Venue.Subdivision,
-		&i.Venue.CountryCode,
-		&i.Venue.Deleted,
-	)
-	return i, err
+// Remove the rows of a venue's layout, other than those given.
+func (q *Queries) TrimVenueRows(ctx context.Context, arg TrimVenueRowsParams) error {
+	_, err := q.db.Exec(ctx, trimVenueRows, arg.VenueID, arg.RowIds)
+	return err
 }
 
-const linkUpdatedPerformers = `-- name: LinkUpdatedPerformers :exec
-with performer_ids as (
-    select id
-    from performers
-    where name = any($2::text[])
-), del as (
-    delete from event_performers
-    where
-        event_id = $1
-        and not exists (
-            select 1
-            from performer_ids
-            where performer_ids.id = event_performers.performer_id
-        )
-)
-insert into event_performers (event_id, performer_id)
-select $1, id
-from performer_ids
-on conflict (event_id, performer_id) do nothing
+const trimVenueSeats = `-- name: TrimVenueSeats :exec
+delete from venue_seats
+using venue_rows, venue_sections
+where
+    venue_seats.row_id = venue_rows.id
+    and venue_rows.section_id = venue_sections.id
+    and venue_sections.venue_id = $1
+    and not venue_seats.id = any($2::int[])
 `
 
-type LinkUpdatedPerformersParams struct {
-	EventID int32
-	Names   []string
+type TrimVenueSeatsParams struct {
+	VenueID int32
+	SeatIds []int32
 }
 
-func (q *Queries) LinkUpdatedPerformers(ctx context.Context, arg LinkUpdatedPerformersParams) error {
-	_, err := q.db.Exec(ctx, linkUpdatedPerformers, arg.EventID, arg.Names)
+// Remove the seats of a venue's layout, other than those given.
+func (q *Queries) TrimVenueSeats(ctx context.Context, arg TrimVenueSeatsParams) error {
+	_, err := q.db.Exec(ctx, trimVenueSeats, arg.VenueID, arg.SeatIds)
 	return err
 }
 
-const setTicketPurchaser = `-- name: SetTicketPurchaser :one
-update tickets
-set purchaser_id = $1
+const trimVenueSections = `-- name: TrimVenueSections :exec
+delete from venue_sections
 where
-    id = $2
-    and purchaser_id is null
-returning id
+    venue_id = $1
+    and not id = any($2::int[])
 `
 
-type SetTicketPurchaserParams struct {
-	PurchaserID pgtype.Int4
-	TicketID    int32
+type TrimVenueSectionsParams struct {
+	VenueID    int32
+	SectionIds []int32
 }
 
-func (q *Queries) SetTicketPurchaser(ctx context.Context, arg SetTicketPurchaserParams) (int32, error) {
-	row := q.db.QueryRow(ctx, setTicketPurchaser, arg.PurchaserID, arg.TicketID)
-	var id int32
-	err := row.Scan(&id)
-	return id, err
+// Remove the sections of a venue's layout, other than those given.
+func (q *Queries) TrimVenueSections(ctx context.Context, arg TrimVenueSectionsParams) error {
+	_, err := q.db.Exec(ctx, trimVenueSections, arg.VenueID, arg.SectionIds)
+	return err
 }
 
-const trimUpdatedEventPerformers = `-- name: TrimUpdatedEventPerformers :exec
-delete from event_performers
-where event_id = $1
+const updateCancellationRefund = `-- name: UpdateCancellationRefund :exec
+update cancellation_refunds
+set
+    status = $1,
+    attempts = $2,
+    last_error = $3,
+    refund_id = $4,
+    updated_at = now()
+where id = $5
 `
 
-func (q *Queries) TrimUpdatedEventPerformers(ctx context.Context, eventID int32) error {
-	_, err := q.db.Exec(ctx, trimUpdatedEventPerformers, eventID)
+type UpdateCancellationRefundParams struct {
+	Status               string
+	Attempts             int32
+	LastError            pgtype.Text
+	RefundID             pgtype.Int4
+	CancellationRefundID int32
+}
+
+func (q *Queries) UpdateCancellationRefund(ctx context.Context, arg UpdateCancellationRefundParams) error {
+	_, err := q.db.Exec(ctx, updateCancellationRefund,
+		arg.Status,
+		arg.Attempts,
+		arg.LastError,
+		arg.RefundID,
+		arg.CancellationRefundID,
+	)
 	return err
 }
 
@@ -326,19 +3612,25 @@ set
     name = $1,
     starts_at = $2,
     ends_at = $3,
-    description = $4
+    description = $4,
+    max_tickets_per_user = $5,
+    resale_min_rate = $6,
+    resale_max_rate = $7
 where
-    id = $5
+    id = $8
     and deleted = false
 returning id
 `
 
 type UpdateEventParams struct {
-	Name        string
-	StartsAt    pgtype.Timestamptz
-	EndsAt      pgtype.Timestamptz
-	Description pgtype.Text
-	EventID     int32
+	Name              string
+	StartsAt          pgtype.Timestamptz
+	EndsAt            pgtype.Timestamptz
+	Description       pgtype.Text
+	MaxTicketsPerUser pgtype.Int4
+	ResaleMinRate     pgtype.Int4
+	ResaleMaxRate     pgtype.Int4
+	EventID           int32
 }
 
 // The updated record's id is returned so that the generated query will return
@@ -350,13 +3642,183 @@ func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (int32
 		arg.StartsAt,
 		arg.EndsAt,
 		arg.Description,
+		arg.MaxTicketsPerUser,
+		arg.ResaleMinRate,
+		arg.ResaleMaxRate,
+		arg.EventID,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updatePaymentStatus = `-- name: UpdatePaymentStatus :execrows
+update payments
+set
+    status = $1,
+    reference = coalesce($2, reference),
+    decline_reason = coalesce($3, decline_reason),
+    updated_at = now()
+where id = $4 and status = $5
+`
+
+type UpdatePaymentStatusParams struct {
+	Status        string
+	Reference     pgtype.Text
+	DeclineReason pgtype.Text
+	PaymentID     int32
+	FromStatus    string
+}
+
+// Only updates the payment if it is in the expected status, so that concurrent
+// transitions can't both succeed.
+func (q *Queries) UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updatePaymentStatus,
+		arg.Status,
+		arg.Reference,
+		arg.DeclineReason,
+		arg.PaymentID,
+		arg.FromStatus,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updatePromoCode = `-- name: UpdatePromoCode :one
+update promo_codes
+set
+    code = $1,
+    discount_type = $2,
+    percent_off = $3,
+    amount_off = $4,
+    currency = $5,
+    event_id = $6::int,
+    starts_at = $7,
+    ends_at = $8,
+    max_redemptions = $9,
+    max_redemptions_per_user = $10,
+    updated_at = now()
+where
+    id = $11
+    and deleted = false
+    and (
+        $6::int is null
+        or exists (
+            select 1
+            from events
+            where
+                events.id = $6::int
+                and events.deleted = false
+        )
+    )
+returning id
+`
+
+type UpdatePromoCodeParams struct {
+	Code                  string
+	DiscountType          string
+	PercentOff            int32
+	AmountOff             int64
+	Currency              pgtype.Text
+	EventID               pgtype.Int4
+	StartsAt              pgtype.Timestamptz
+	EndsAt                pgtype.Timestamptz
+	MaxRedemptions        pgtype.Int4
+	MaxRedemptionsPerUser pgtype.Int4
+	PromoCodeID           int32
+}
+
+// The updated record's id is returned so that the generated query will return
+// an error (`sql.ErrNoRows`) if no record matches the where clause and no
+// record is updated, including if the code is for an event that doesn't exist.
+func (q *Queries) UpdatePromoCode(ctx context.Context, arg UpdatePromoCodeParams) (int32, error) {
+	row := q.db.QueryRow(ctx, updatePromoCode,
+		arg.Code,
+		arg.DiscountType,
+		arg.PercentOff,
+		arg.AmountOff,
+		arg.Currency,
 		arg.EventID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.MaxRedemptions,
+		arg.MaxRedemptionsPerUser,
+		arg.PromoCodeID,
 	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
 }
 
+const updateResaleListingStatus = `-- name: UpdateResaleListingStatus :one
+update resale_listings
+set
+    status = $1,
+    updated_at = now()
+where
+    id = $2
+    and status = 'active'
+returning id, ticket_id, seller_id, price, currency, status, created_at, updated_at
+`
+
+type UpdateResaleListingStatusParams struct {
+	Status    string
+	ListingID int32
+}
+
+// Only updates the listing if it is active, so that a listing can't be both
+// sold and cancelled.
+func (q *Queries) UpdateResaleListingStatus(ctx context.Context, arg UpdateResaleListingStatusParams) (ResaleListing, error) {
+	row := q.db.QueryRow(ctx, updateResaleListingStatus, arg.Status, arg.ListingID)
+	var i ResaleListing
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.SellerID,
+		&i.Price,
+		&i.Currency,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateTicketTransferStatus = `-- name: UpdateTicketTransferStatus :one
+update ticket_transfers
+set
+    status = $1,
+    updated_at = now()
+where
+    id = $2
+    and status = 'pending'
+returning id, ticket_id, sender_id, recipient_id, status, created_at, updated_at
+`
+
+type UpdateTicketTransferStatusParams struct {
+	Status     string
+	TransferID int32
+}
+
+// Only updates the transfer if it is pending, so that a transfer can't be both
+// accepted and cancelled.
+func (q *Queries) UpdateTicketTransferStatus(ctx context.Context, arg UpdateTicketTransferStatusParams) (TicketTransfer, error) {
+	row := q.db.QueryRow(ctx, updateTicketTransferStatus, arg.Status, arg.TransferID)
+	var i TicketTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.TicketID,
+		&i.SenderID,
+		&i.RecipientID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateVenue = `-- name: UpdateVenue :one
 update venues
 set
@@ -399,3 +3861,287 @@ func (q *Queries) UpdateVenue(ctx context.Context, arg UpdateVenueParams) (int32
 	err := row.Scan(&id)
 	return id, err
 }
+
+const upsertEventFeeRule = `-- name: UpsertEventFeeRule :one
+insert into fee_rules (event_id, service_fee_rate, service_fee_flat, facility_fee)
+select events.id, $1, $2, $3
+from events
+where
+    events.id = $4
+    and events.deleted = false
+on conflict (event_id) do update
+set
+    service_fee_rate = excluded.service_fee_rate,
+    service_fee_flat = excluded.service_fee_flat,
+    facility_fee = excluded.facility_fee,
+    updated_at = now()
+returning id
+`
+
+type UpsertEventFeeRuleParams struct {
+	ServiceFeeRate int32
+	ServiceFeeFlat int64
+	FacilityFee    int64
+	EventID        int32
+}
+
+// The inserted or updated record's id is returned so that the generated query
+// will return an error (`sql.ErrNoRows`) if the event doesn't exist.
+func (q *Queries) UpsertEventFeeRule(ctx context.Context, arg UpsertEventFeeRuleParams) (int32, error) {
+	row := q.db.QueryRow(ctx, upsertEventFeeRule,
+		arg.ServiceFeeRate,
+		arg.ServiceFeeFlat,
+		arg.FacilityFee,
+		arg.EventID,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const upsertTaxRate = `-- name: UpsertTaxRate :exec
+insert into tax_rates (country_code, subdivision, rate)
+values ($1, $2, $3)
+on conflict (country_code, subdivision) do update
+set rate = excluded.rate
+`
+
+type UpsertTaxRateParams struct {
+	CountryCode string
+	Subdivision string
+	Rate        int32
+}
+
+func (q *Queries) UpsertTaxRate(ctx context.Context, arg UpsertTaxRateParams) error {
+	_, err := q.db.Exec(ctx, upsertTaxRate, arg.CountryCode, arg.Subdivision, arg.Rate)
+	return err
+}
+
+const upsertVenueFeeRule = `-- name: UpsertVenueFeeRule :one
+insert into fee_rules (venue_id, service_fee_rate, service_fee_flat, facility_fee)
+select venues.id, $1, $2, $3
+from venues
+where
+    venues.id = $4
+    and venues.deleted = false
+on conflict (venue_id) do update
+set
+    service_fee_rate = excluded.service_fee_rate,
+    service_fee_flat = excluded.service_fee_flat,
+    facility_fee = excluded.facility_fee,
+    updated_at = now()
+returning id
+`
+
+type UpsertVenueFeeRuleParams struct {
+	ServiceFeeRate int32
+	ServiceFeeFlat int64
+	FacilityFee    int64
+	VenueID        int32
+}
+
+// The inserted or updated record's id is returned so that the generated query
+// will return an error (`sql.ErrNoRows`) if the venue doesn't exist.
+func (q *Queries) UpsertVenueFeeRule(ctx context.Context, arg UpsertVenueFeeRuleParams) (int32, error) {
+	row := q.db.QueryRow(ctx, upsertVenueFeeRule,
+		arg.ServiceFeeRate,
+		arg.ServiceFeeFlat,
+		arg.FacilityFee,
+		arg.VenueID,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const upsertVenueRows = `-- name: UpsertVenueRows :many
+insert into venue_rows (section_id, name)
+select unnest($1::int[]), unnest($2::text[])
+on conflict (section_id, name) do update
+set name = excluded.name
+returning id, section_id, name
+`
+
+type UpsertVenueRowsParams struct {
+	SectionIds []int32
+	Names      []string
+}
+
+// Existing rows are updated in place, so that their ids are returned.
+func (q *Queries) UpsertVenueRows(ctx context.Context, arg UpsertVenueRowsParams) ([]VenueRow, error) {
+	rows, err := q.db.Query(ctx, upsertVenueRows, arg.SectionIds, arg.Names)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VenueRow
+	for rows.Next() {
+		var i VenueRow
+		if err := rows.Scan(&i.ID, &i.SectionID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertVenueSeats = `-- name: UpsertVenueSeats :many
+insert into venue_seats (row_id, number, x, y, accessible, obstructed)
+select
+    unnest($1::int[]),
+    unnest($2::text[]),
+    unnest($3::float8[]),
+    unnest($4::float8[]),
+    unnest($5::boolean[]),
+    unnest($6::boolean[])
+on conflict (row_id, number) do update
+set
+    x = excluded.x,
+    y = excluded.y,
+    accessible = excluded.accessible,
+    obstructed = excluded.obstructed
+returning id
+`
+
+type UpsertVenueSeatsParams struct {
+	RowIds     []int32
+	Numbers    []string
+	Xs         []float64
+	Ys         []float64
+	Accessible []bool
+	Obstructed []bool
+}
+
+func (q *Queries) UpsertVenueSeats(ctx context.Context, arg UpsertVenueSeatsParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, upsertVenueSeats,
+		arg.RowIds,
+		arg.Numbers,
+		arg.Xs,
+		arg.Ys,
+		arg.Accessible,
+		arg.Obstructed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertVenueSections = `-- name: UpsertVenueSections :many
+insert into venue_sections (venue_id, name)
+select $1::int, unnest($2::text[])
+on conflict (venue_id, name) do update
+set name = excluded.name
+returning id, name
+`
+
+type UpsertVenueSectionsParams struct {
+	VenueID int32
+	Names   []string
+}
+
+type UpsertVenueSectionsRow struct {
+	ID   int32
+	Name string
+}
+
+// Existing sections are updated in place, so that their ids are returned.
+func (q *Queries) UpsertVenueSections(ctx context.Context, arg UpsertVenueSectionsParams) ([]UpsertVenueSectionsRow, error) {
+	rows, err := q.db.Query(ctx, upsertVenueSections, arg.VenueID, arg.Names)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UpsertVenueSectionsRow
+	for rows.Next() {
+		var i UpsertVenueSectionsRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertWaitingRoom = `-- name: UpsertWaitingRoom :one
+insert into waiting_rooms (event_id, batch_size)
+select events.id, $1
+from events
+where
+    events.id = $2
+    and events.deleted = false
+on conflict (event_id) do update
+set
+    batch_size = excluded.batch_size,
+    updated_at = now()
+returning event_id
+`
+
+type UpsertWaitingRoomParams struct {
+	BatchSize int32
+	EventID   int32
+}
+
+// The inserted or updated record's event id is returned so that the generated
+// query will return an error (`sql.ErrNoRows`) if the event doesn't exist.
+func (q *Queries) UpsertWaitingRoom(ctx context.Context, arg UpsertWaitingRoomParams) (int32, error) {
+	row := q.db.QueryRow(ctx, upsertWaitingRoom, arg.BatchSize, arg.EventID)
+	var event_id int32
+	err := row.Scan(&event_id)
+	return event_id, err
+}
+
+const upsertWaitlistEntry = `-- name: UpsertWaitlistEntry :one
+insert into waitlist_entries (event_id, user_id, quantity)
+select events.id, $1, $2
+from events
+where
+    events.id = $3
+    and events.deleted = false
+on conflict (event_id, user_id) do update
+set
+    quantity = excluded.quantity,
+    joined_at = case
+        when waitlist_entries.offer_token is null then waitlist_entries.joined_at
+        else now()
+    end,
+    offer_token = null,
+    offer_expires_at = null
+returning event_id
+`
+
+type UpsertWaitlistEntryParams struct {
+	UserID   int32
+	Quantity int32
+	EventID  int32
+}
+
+// Joining a waitlist that the user is already waiting on only changes the
+// quantity, keeping the user's place, whereas joining again once offered
+// tickets puts the user at the back. The inserted or updated record's event id
+// is returned so that the generated query will return an error
+// (`sql.ErrNoRows`) if the event doesn't exist.
+func (q *Queries) UpsertWaitlistEntry(ctx context.Context, arg UpsertWaitlistEntryParams) (int32, error) {
+	row := q.db.QueryRow(ctx, upsertWaitlistEntry, arg.UserID, arg.Quantity, arg.EventID)
+	var event_id int32
+	err := row.Scan(&event_id)
+	return event_id, err
+}
@@ -1,6 +1,10 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/money"
+)
 
 type VenueLocation struct {
 	Address     string
@@ -16,6 +20,65 @@ type Venue struct {
 	Location    VenueLocation
 }
 
+// VenueSeat is a seat in a venue's seating layout, with its position on the
+// venue's seating chart.
+type VenueSeat struct {
+	ID         int32
+	Number     string
+	X          float64
+	Y          float64
+	Accessible bool
+	Obstructed bool
+}
+
+type VenueRow struct {
+	ID    int32
+	Name  string
+	Seats []VenueSeat
+}
+
+type VenueSection struct {
+	ID   int32
+	Name string
+	Rows []VenueRow
+}
+
+// VenueLayout is the seating layout of a venue, made up of sections of rows of
+// seats.
+type VenueLayout struct {
+	VenueID  int32
+	Sections []VenueSection
+}
+
+// IsValid checks that the layout's sections, the rows of each section and the
+// seats of each row are uniquely named, and that no section or row is empty.
+func (l *VenueLayout) IsValid() bool {
+	sectionNames := make(map[string]bool)
+	for _, section := range l.Sections {
+		if sectionNames[section.Name] || len(section.Rows) == 0 {
+			return false
+		}
+		sectionNames[section.Name] = true
+
+		rowNames := make(map[string]bool)
+		for _, row := range section.Rows {
+			if rowNames[row.Name] || len(row.Seats) == 0 {
+				return false
+			}
+			rowNames[row.Name] = true
+
+			seatNumbers := make(map[string]bool)
+			for _, seat := range row.Seats {
+				if seatNumbers[seat.Number] {
+					return false
+				}
+				seatNumbers[seat.Number] = true
+			}
+		}
+	}
+	return true
+}
+
 type Performer struct {
 	ID   int32
 	Name string
@@ -26,34 +89,773 @@ type EventVenue struct {
 	Name string
 }
 
+// Event is an event held at a venue. A user can hold and purchase at most
+// `MaxTicketsPerUser` of the event's tickets, or any number if it's zero.
+// Resale listings of the event's tickets are priced within the resale rates,
+// in basis points of the ticket's face value, where unset rates are unbounded.
 type Event struct {
-	ID          int32
-	Name        string
-	StartsAt    time.Time
-	EndsAt      time.Time
-	Description string
-	Venue       EventVenue
-	Performers  []Performer
+	ID                int32
+	Name              string
+	StartsAt          time.Time
+	EndsAt            time.Time
+	Description       string
+	Venue             EventVenue
+	Performers        []Performer
+	MaxTicketsPerUser int32
+	ResaleMinRate     int32
+	ResaleMaxRate     int32
 }
 
 func (e *Event) IsValid() bool {
 	if e.EndsAt.Before(e.StartsAt) {
 		return false
 	}
+	if e.ResaleMinRate < 0 || e.ResaleMaxRate < 0 {
+		return false
+	}
+	if e.ResaleMinRate != 0 && e.ResaleMaxRate != 0 && e.ResaleMinRate > e.ResaleMaxRate {
+		return false
+	}
 	return true
 }
 
+// PurchaseLimit is an event's limit on the number of its tickets a user can
+// hold and purchase, along with how many of them the user has purchased.
+type PurchaseLimit struct {
+	EventID           int32
+	MaxTicketsPerUser int32
+	Purchased         int32
+}
+
+// Remaining is how many more of the event's tickets the user can hold or
+// purchase.
+func (l *PurchaseLimit) Remaining() int32 {
+	return max(l.MaxTicketsPerUser-l.Purchased, 0)
+}
+
 type Ticket struct {
 	ID          int32
 	EventID     int32
 	PurchaserID int32
 	IsPurchased bool
-	Price       uint8
+	Price       money.Money
 	Seat        string
+	// The seat of the venue's layout that the ticket is for, if any.
+	VenueSeatID int32
+	// The ticket's type, if any, which the ticket is priced by.
+	TicketTypeID int32
+	// The scheduled release that the ticket is part of, if any.
+	ReleaseID int32
+}
+
+// TicketType classifies an event's tickets, e.g. "VIP" or "Student", so that
+// the same seats can be sold at different prices. Eligibility lists the
+// requirements that a ticket's holder must meet to be admitted. Tickets of a
+// type are only on sale within its sale window, where unset times are
+// unbounded.
+type TicketType struct {
+	ID           int32
+	EventID      int32
+	Name         string
+	Description  string
+	Price        money.Money
+	Eligibility  []string
+	SaleStartsAt time.Time
+	SaleEndsAt   time.Time
+}
+
+func (t *TicketType) IsValid() bool {
+	if !t.SaleStartsAt.IsZero() && !t.SaleEndsAt.IsZero() && !t.SaleEndsAt.After(t.SaleStartsAt) {
+		return false
+	}
+	return true
+}
+
+// IsOnSale is whether the type's tickets are on sale at the given time.
+func (t *TicketType) IsOnSale(at time.Time) bool {
+	if !t.SaleStartsAt.IsZero() && at.Before(t.SaleStartsAt) {
+		return false
+	}
+	return t.SaleEndsAt.IsZero() || at.Before(t.SaleEndsAt)
+}
+
+// TicketRelease schedules a set of an event's tickets released together, which
+// are on sale from when the release goes on sale until it goes off sale, where
+// unset times are unbounded. Presales give early access to the release's
+// tickets.
+type TicketRelease struct {
+	ID        int32
+	EventID   int32
+	OnSaleAt  time.Time
+	OffSaleAt time.Time
+	Presales  []Presale
+}
+
+// IsScheduled is whether the release has a sale window or presales, rather than
+// being on sale as soon as it's released.
+func (r *TicketRelease) IsScheduled() bool {
+	return !r.OnSaleAt.IsZero() || !r.OffSaleAt.IsZero() || len(r.Presales) > 0
+}
+
+func (r *TicketRelease) IsValid() bool {
+	if !r.OnSaleAt.IsZero() && !r.OffSaleAt.IsZero() && !r.OffSaleAt.After(r.OnSaleAt) {
+		return false
+	}
+	for _, presale := range r.Presales {
+		if !presale.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsOnSale is whether the release's tickets are on general sale at the given
+// time.
+func (r *TicketRelease) IsOnSale(at time.Time) bool {
+	if !r.OnSaleAt.IsZero() && at.Before(r.OnSaleAt) {
+		return false
+	}
+	return r.OffSaleAt.IsZero() || at.Before(r.OffSaleAt)
+}
+
+// Presale gives early access to a release's tickets within its window, to users
+// that give its access code, or that are on its allowlist of users.
+type Presale struct {
+	ID         int32
+	Name       string
+	StartsAt   time.Time
+	EndsAt     time.Time
+	AccessCode string
+	// The users on the allowlist, which is only set when creating a presale.
+	UserIDs      []int32
+	HasAllowlist bool
+}
+
+// IsValid checks that the presale has a window, and is gated by an access code
+// or an allowlist.
+func (p *Presale) IsValid() bool {
+	if p.StartsAt.IsZero() || !p.EndsAt.After(p.StartsAt) {
+		return false
+	}
+	return p.AccessCode != "" || len(p.UserIDs) > 0
+}
+
+// IsActive is whether the presale is running at the given time.
+func (p *Presale) IsActive(at time.Time) bool {
+	return !at.Before(p.StartsAt) && at.Before(p.EndsAt)
+}
+
+// SaleAccess is who is buying tickets, and the presale access code and waiting
+// room admission token they gave, if any, for checking their access to
+// presales and to events with a waiting room.
+type SaleAccess struct {
+	UserID         int32
+	AccessCode     string
+	AdmissionToken string
+}
+
+// WaitingRoom queues the users wanting to hold an event's tickets, and admits
+// them in batches of `BatchSize` users at a time.
+type WaitingRoom struct {
+	EventID   int32
+	BatchSize int32
+}
+
+// QueuePosition is a user's place in an event's waiting room queue. Once the
+// user has been admitted, their position is zero and they're given an
+// admission token, which is valid until it expires.
+type QueuePosition struct {
+	EventID        int32
+	Position       int64
+	AdmissionToken string
+	ExpiresAt      time.Time
+}
+
+// IsAdmitted is whether the user has been admitted from the queue.
+func (p *QueuePosition) IsAdmitted() bool {
+	return p.AdmissionToken != ""
+}
+
+const (
+	WaitlistStatusWaiting = "waiting"
+	WaitlistStatusOffered = "offered"
+	WaitlistStatusExpired = "expired"
+)
+
+// WaitlistEntry is a user's place on the waitlist of a sold-out event, waiting
+// for `Quantity` tickets. As tickets return to inventory, users are offered
+// them in the order they joined, by a purchase hold that's exclusive to the
+// user until the offer expires, and which is identified by `OfferToken`. The
+// user's position is among the users still waiting, and is zero once they've
+// been offered tickets.
+type WaitlistEntry struct {
+	EventID        int32
+	UserID         int32
+	Quantity       int32
+	JoinedAt       time.Time
+	Position       int32
+	OfferToken     string
+	OfferExpiresAt time.Time
+}
+
+// Status is whether the user is waiting, has been offered tickets, or has let
+// the offer expire, at the given time.
+func (e *WaitlistEntry) Status(at time.Time) string {
+	if e.OfferToken == "" {
+		return WaitlistStatusWaiting
+	}
+	if at.Before(e.OfferExpiresAt) {
+		return WaitlistStatusOffered
+	}
+	return WaitlistStatusExpired
+}
+
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusCancelled = "cancelled"
+)
+
+// TicketTransfer is the transfer of a purchased ticket from its owner, the
+// sender, to another user. The transfer is pending until the recipient accepts
+// it, taking ownership of the ticket, or the sender cancels it.
+type TicketTransfer struct {
+	ID          int32
+	TicketID    int32
+	SenderID    int32
+	RecipientID int32
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TicketOwnership is who a ticket is owned by, if it's been purchased, and
+// when the ticket's event starts.
+type TicketOwnership struct {
+	TicketID      int32
+	OwnerID       int32
+	EventStartsAt time.Time
+}
+
+// HasEventStarted checks whether the ticket's event has started at the given
+// time, after which the ticket can't be transferred.
+func (o *TicketOwnership) HasEventStarted(at time.Time) bool {
+	return !at.Before(o.EventStartsAt)
+}
+
+const (
+	ResaleListingStatusActive    = "active"
+	ResaleListingStatusSold      = "sold"
+	ResaleListingStatusCancelled = "cancelled"
+)
+
+// ResaleListing is a purchased ticket listed for resale by its owner, the
+// seller. The listing is active until the ticket is sold to a buyer, or the
+// listing is cancelled, either by the seller or as the ticket changes hands
+// otherwise.
+type ResaleListing struct {
+	ID        int32
+	TicketID  int32
+	SellerID  int32
+	Price     money.Money
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TicketResaleTerms are the terms under which a ticket can be listed for
+// resale: only by its owner, until its event starts, and at a price within its
+// event's resale rates of its face value.
+type TicketResaleTerms struct {
+	Ticket        Ticket
+	EventStartsAt time.Time
+	MinRate       int32
+	MaxRate       int32
+}
+
+// HasEventStarted checks whether the ticket's event has started at the given
+// time, after which the ticket can't be resold.
+func (t *TicketResaleTerms) HasEventStarted(at time.Time) bool {
+	return !at.Before(t.EventStartsAt)
+}
+
+// IsPriceAllowed checks that the price is in the currency of the ticket's face
+// value, and within the event's resale rates of it.
+func (t *TicketResaleTerms) IsPriceAllowed(price money.Money) bool {
+	faceValue := t.Ticket.Price
+	if price.Currency != faceValue.Currency || price.Amount <= 0 {
+		return false
+	}
+	if t.MinRate != 0 && price.Amount < faceValue.ApplyRate(int64(t.MinRate)).Amount {
+		return false
+	}
+	if t.MaxRate != 0 && price.Amount > faceValue.ApplyRate(int64(t.MaxRate)).Amount {
+		return false
+	}
+	return true
+}
+
+// TicketCredential is the scannable credential of a purchased ticket, signed
+// over the ticket, its event, its owner and a nonce. The nonce is generated
+// when the owner first fetches the credential, and rotating it invalidates
+// the credentials signed over the previous nonce. The token is only set once
+// the credential has been signed.
+type TicketCredential struct {
+	TicketID  int32
+	EventID   int32
+	OwnerID   int32
+	Nonce     string
+	RotatedAt time.Time
+	Token     string
+}
+
+// HasNonce checks whether a nonce has been generated for the credential.
+func (c *TicketCredential) HasNonce() bool {
+	return c.Nonce != ""
+}
+
+// Checkin is the admission of a purchased ticket to its event, scanned by a
+// device at one of the event's gates.
+type Checkin struct {
+	ID        int32
+	TicketID  int32
+	EventID   int32
+	OwnerID   int32
+	Gate      string
+	Device    string
+	ScannedAt time.Time
+}
+
+// TicketAdmission is what a ticket's credential is checked against when the
+// ticket is scanned: the ticket's event and owner, the nonce of its owner's
+// credential, and whether the ticket has already been checked in.
+type TicketAdmission struct {
+	TicketID  int32
+	EventID   int32
+	OwnerID   int32
+	Nonce     string
+	CheckedIn bool
+}
+
+// IsCurrent checks whether a credential issued to the owner, signed over the
+// nonce, is the ticket's current credential.
+func (a *TicketAdmission) IsCurrent(ownerID int32, nonce string) bool {
+	return a.OwnerID != 0 && a.OwnerID == ownerID && a.Nonce != "" && a.Nonce == nonce
+}
+
+// EventAttendance is the number of an event's tickets that have been checked
+// in, out of its purchased tickets.
+type EventAttendance struct {
+	EventID   int32
+	CheckedIn int64
+	Purchased int64
+}
+
+// ScannerTicket is a purchased ticket, as synced to scanners so that they can
+// check its credential while offline.
+type ScannerTicket struct {
+	TicketID  int32
+	OwnerID   int32
+	Nonce     string
+	CheckedIn bool
+}
+
+// ScannerBundle is a snapshot of an event's purchased tickets, and of its
+// tickets that have been revoked, for scanners to check credentials against
+// while offline. The token is only set once the bundle has been signed.
+type ScannerBundle struct {
+	EventID  int32
+	IssuedAt time.Time
+	Tickets  []ScannerTicket
+	Revoked  []int32
+	Token    string
+}
+
+// OfflineScan is a ticket's credential as scanned by a device while it was
+// offline, and uploaded once it reconnected.
+type OfflineScan struct {
+	TicketID   int32
+	Credential string
+	Gate       string
+	Device     string
+	ScannedAt  time.Time
+}
+
+const (
+	ScanStatusAdmitted  = "admitted"
+	ScanStatusDuplicate = "duplicate"
+	ScanStatusRejected  = "rejected"
+)
+
+// ScanResult is the outcome of reconciling an offline scan. A scan is a
+// duplicate if its ticket was also admitted by another scan, and is rejected,
+// for the reason given, if its credential isn't valid for the event.
+type ScanResult struct {
+	TicketID int32
+	Status   string
+	Reason   string
+}
+
+// EventVenueSeat is a seat of the layout of an event's venue, and whether a
+// ticket has been released for it for the event.
+type EventVenueSeat struct {
+	ID          int32
+	SectionName string
+	IsReleased  bool
+}
+
+// SeatPosition is where a ticket's seat is in the venue's layout. Seats are
+// ordered within their row by position, so that adjacent seats have
+// consecutive positions, and rows are ordered by their distance from the stage,
+// `Y`, on the venue's seating chart.
+type SeatPosition struct {
+	Section  string
+	RowID    int32
+	Row      string
+	Number   string
+	Position int32
+	Y        float64
+}
+
+// SeatedTicket is a ticket for a seat of the venue's layout.
+type SeatedTicket struct {
+	Ticket   Ticket
+	Position SeatPosition
+}
+
+// SeatSelection describes the seats wanted by a buyer: `Quantity` adjacent
+// seats in the same row, each priced at most `MaxPrice` if given, and in one
+// of `Sections` in order of preference if any are given.
+type SeatSelection struct {
+	Quantity int
+	MaxPrice *money.Money
+	Sections []string
 }
 
 type AvailableTicketAggregate struct {
-	Price uint8
-	Seat  string
-	IDs   []int32
+	Price        money.Money
+	Seat         string
+	TicketTypeID int32
+	ReleaseID    int32
+	IDs          []int32
+}
+
+// GATier is general admission inventory for an event at a single price. Rather
+// than being released up front, a tier's tickets are issued as they're
+// purchased, up to its capacity.
+type GATier struct {
+	ID       int32
+	EventID  int32
+	Name     string
+	Price    money.Money
+	Capacity int32
+	Sold     int32
+}
+
+// AvailableGATier is a general admission tier, along with how much of its
+// capacity is left to purchase once purchase holds are accounted for.
+type AvailableGATier struct {
+	ID        int32
+	Name      string
+	Price     money.Money
+	Remaining int32
+}
+
+// AvailableResaleListing is an active resale listing that isn't held, along
+// with its ticket's seat and face value.
+type AvailableResaleListing struct {
+	ID        int32
+	TicketID  int32
+	Seat      string
+	Price     money.Money
+	FaceValue money.Money
+}
+
+// AvailableInventory is an event's inventory that is available for purchase,
+// both seated and general admission, along with the types and scheduled
+// releases of the available seated tickets. Tickets listed for resale are kept
+// apart from the event's own inventory.
+type AvailableInventory struct {
+	Seated           []AvailableTicketAggregate
+	GeneralAdmission []AvailableGATier
+	TicketTypes      []TicketType
+	Releases         []TicketRelease
+	Resale           []AvailableResaleListing
+}
+
+// IsSoldOut is whether none of the event's inventory is available, either
+// because it's all been purchased or because what's left is held. Tickets
+// listed for resale aren't part of the event's inventory, so don't count.
+func (i *AvailableInventory) IsSoldOut() bool {
+	if len(i.Seated) > 0 {
+		return false
+	}
+	for _, tier := range i.GeneralAdmission {
+		if tier.Remaining > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GATierHold is a purchase hold placed on a quantity of a general admission
+// tier, which is identified by its token.
+type GATierHold struct {
+	Token     string
+	HolderID  string
+	GATierID  int32
+	Quantity  int32
+	ExpiresAt time.Time
+}
+
+// TicketHold is a purchase hold placed on a set of tickets at once, which is
+// identified by its token.
+type TicketHold struct {
+	Token     string
+	HolderID  string
+	TicketIDs []int32
+	ExpiresAt time.Time
+}
+
+const (
+	PaymentStatusPending    = "pending"
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusCaptured   = "captured"
+	PaymentStatusVoided     = "voided"
+	PaymentStatusFailed     = "failed"
+	PaymentStatusRefunded   = "refunded"
+)
+
+type Payment struct {
+	ID            int32
+	PurchaserID   int32
+	Amount        money.Money
+	Status        string
+	Reference     string
+	DeclineReason string
+}
+
+// TicketPurchase is the purchase of a ticket that is currently purchased,
+// with the price paid, including fees and tax, and the payment it was paid
+// with.
+type TicketPurchase struct {
+	TicketID    int32
+	PurchaserID int32
+	// The user that the ticket's order was placed by, which differs from the
+	// ticket's purchaser once the ticket has been transferred.
+	OrderPurchaserID int32
+	OrderID          int32
+	Price            money.Money
+	PaymentID        int32
+	PaymentReference string
+}
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusCompleted = "completed"
+	RefundStatusFailed    = "failed"
+)
+
+// Refund is the refund of a ticket's price. A refund is pending until it has
+// been made by the payment processor, at which point it's given a reference.
+type Refund struct {
+	ID        int32
+	PaymentID int32
+	TicketID  int32
+	Amount    money.Money
+	Status    string
+	Reference string
+	// The reference of the refunded payment with the payment processor.
+	PaymentReference string
+}
+
+const OrderStatusCompleted = "completed"
+
+// OrderItem is a ticket purchased in an order, with its face value and the
+// discount, fees and tax charged for it.
+type OrderItem struct {
+	TicketID   int32
+	Price      money.Money
+	Discount   money.Money
+	ServiceFee money.Money
+	Tax        money.Money
+}
+
+type Order struct {
+	ID          int32
+	PurchaserID int32
+	PaymentID   int32
+	Status      string
+	Total       money.Money
+	PromoCodeID int32
+	Discount    money.Money
+	FacilityFee money.Money
+	Tax         money.Money
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Items       []OrderItem
+}
+
+// FeeRule is the fees charged on purchases of tickets for a venue's events, or
+// for a single event. Service fees are charged per ticket, as a rate of the
+// ticket's face value in basis points plus a flat amount, and facility fees
+// per order. Flat amounts are in the minor units of the event's currency.
+type FeeRule struct {
+	VenueID        int32
+	EventID        int32
+	ServiceFeeRate int32
+	ServiceFeeFlat int64
+	FacilityFee    int64
+}
+
+// TaxRate is the sales tax rate for a region, in basis points. A rate with an
+// empty subdivision applies to the whole country.
+type TaxRate struct {
+	CountryCode string
+	Subdivision string
+	Rate        int32
+}
+
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+// PromoCode discounts purchases of tickets for an event, or for all events if
+// the event isn't set. A percentage discount takes a rate, in basis points, off
+// of each ticket's face value, and a fixed discount takes an amount off of the
+// tickets' face value together. Times and limits that are unset are unbounded.
+type PromoCode struct {
+	ID                    int32
+	Code                  string
+	DiscountType          string
+	PercentOff            int32
+	AmountOff             money.Money
+	EventID               int32
+	StartsAt              time.Time
+	EndsAt                time.Time
+	MaxRedemptions        int32
+	MaxRedemptionsPerUser int32
+	Redemptions           int32
+}
+
+func (c *PromoCode) IsValid() bool {
+	switch c.DiscountType {
+	case DiscountTypePercentage:
+		if c.PercentOff <= 0 || c.PercentOff > 10000 {
+			return false
+		}
+	case DiscountTypeFixed:
+		if c.AmountOff.Amount <= 0 || c.AmountOff.Validate() != nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if !c.StartsAt.IsZero() && !c.EndsAt.IsZero() && !c.EndsAt.After(c.StartsAt) {
+		return false
+	}
+	return true
+}
+
+// IsRedeemable is whether the code can be redeemed at the given time, and has
+// redemptions left.
+func (c *PromoCode) IsRedeemable(at time.Time) bool {
+	if !c.StartsAt.IsZero() && at.Before(c.StartsAt) {
+		return false
+	}
+	if !c.EndsAt.IsZero() && !at.Before(c.EndsAt) {
+		return false
+	}
+	return c.MaxRedemptions == 0 || c.Redemptions < c.MaxRedemptions
+}
+
+// QuoteItem is the price of a ticket, itemized.
+type QuoteItem struct {
+	TicketID   int32
+	FaceValue  money.Money
+	Discount   money.Money
+	ServiceFee money.Money
+	Tax        money.Money
+	Total      money.Money
+}
+
+// Quote is the itemized price of purchasing a set of tickets together. The tax
+// includes the tax on each item, as well as on the facility fee. The promo
+// code is only set if a code was applied.
+type Quote struct {
+	Items       []QuoteItem
+	PromoCodeID int32
+	FaceValue   money.Money
+	Discount    money.Money
+	ServiceFees money.Money
+	FacilityFee money.Money
+	Tax         money.Money
+	Total       money.Money
+}
+
+// PurchaseResult is the outcome of purchasing tickets. The order id is only
+// set if the payment was accepted, and the decline reason only if it was not.
+type PurchaseResult struct {
+	Accepted      bool
+	OrderID       int32
+	DeclineReason string
+}
+
+const (
+	CancellationStatusPending   = "pending"
+	CancellationStatusCompleted = "completed"
+)
+
+const (
+	CancellationRefundStatusPending  = "pending"
+	CancellationRefundStatusRefunded = "refunded"
+	CancellationRefundStatusFailed   = "failed"
+	CancellationRefundStatusSkipped  = "skipped"
+)
+
+// CancellationRefund is the refund owed for a ticket purchased for a cancelled
+// event. Refunds for tickets that are no longer purchased are skipped.
+type CancellationRefund struct {
+	ID             int32
+	CancellationID int32
+	TicketID       int32
+	Status         string
+	Attempts       int32
+	LastError      string
+	RefundID       int32
+}
+
+// EventCancellation is the progress of refunding the purchased tickets for a
+// cancelled event.
+type EventCancellation struct {
+	ID            int32
+	EventID       int32
+	Status        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	TotalCount    int64
+	PendingCount  int64
+	RefundedCount int64
+	FailedCount   int64
+	SkippedCount  int64
+}
+
+// IdempotencyKey is a key given by a client to identify a request, along with
+// the response to the request once it has completed, so that retries of the
+// request are replayed rather than processed again.
+type IdempotencyKey struct {
+	ID           int32
+	UserID       string
+	Key          string
+	Fingerprint  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+}
+
+// IsCompleted is whether the response to the request has been recorded.
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.StatusCode != 0
 }
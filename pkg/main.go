@@ -6,11 +6,14 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humago"
 	pkgApi "github.com/dslaw/book-tickets/pkg/api"
 	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/credentials"
+	"github.com/dslaw/book-tickets/pkg/payment"
 	"github.com/dslaw/book-tickets/pkg/repos"
 	"github.com/dslaw/book-tickets/pkg/search"
 	"github.com/dslaw/book-tickets/pkg/services"
@@ -18,6 +21,15 @@ import (
 	"github.com/joho/godotenv"
 )
 
+const (
+	paymentGatewayTimeout = 10 * time.Second
+	paymentSweepBatchSize = 100
+	refundRetryBatchSize  = 100
+	cancellationBatchSize = 100
+
+	idempotencyPruneInterval = time.Hour
+)
+
 func init() {
 	godotenv.Load()
 
@@ -49,6 +61,13 @@ func main() {
 	}
 	defer ticketHoldClient.Close()
 
+	queueClient, err := cache.NewQueueClientFromURL(config.CacheURL, config.WaitingRoomPrefix)
+	if err != nil {
+		slog.Error("Unable to connect to Redis", "error", err)
+		os.Exit(1)
+	}
+	defer queueClient.Close()
+
 	searchClient, err := search.NewSearchClient(
 		config.SearchURL,
 		config.SearchUser,
@@ -61,13 +80,103 @@ func main() {
 		os.Exit(1)
 	}
 
+	var paymentProcessor payment.PaymentProcessor
+	switch config.PaymentProcessor {
+	case "fake":
+		paymentProcessor = payment.NewFakeProcessor(payment.DefaultFakeRules(), 0)
+	case "http":
+		paymentProcessor = payment.NewHTTPProcessor(
+			config.PaymentGatewayURL,
+			&http.Client{Timeout: paymentGatewayTimeout},
+		)
+	default:
+		slog.Error("Unknown payment processor", "payment_processor", config.PaymentProcessor)
+		os.Exit(1)
+	}
+
+	paymentsRepo := repos.NewPaymentsRepo(pool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sweeper := payment.NewSweeper(
+		paymentsRepo,
+		paymentProcessor,
+		config.PaymentAuthMaxAge,
+		paymentSweepBatchSize,
+	)
+	go sweeper.Run(ctx, config.PaymentSweepInterval)
+
+	ticketsRepo := repos.NewTicketsRepo(pool)
+
+	refundsService := services.NewRefundsService(
+		ticketsRepo,
+		paymentProcessor,
+		config.RefundRetryMaxAge,
+		refundRetryBatchSize,
+	)
+	go refundsService.Run(ctx, config.RefundRetryInterval)
+
+	cancellationsService := services.NewCancellationsService(
+		repos.NewCancellationsRepo(pool),
+		ticketsRepo,
+		paymentProcessor,
+		cancellationBatchSize,
+		config.CancellationMaxAttempts,
+	)
+	go cancellationsService.Run(ctx, config.CancellationInterval)
+
 	venuesService := services.NewVenuesService(repos.NewVenuesRepo(pool))
+	venueLayoutsService := services.NewVenueLayoutsService(repos.NewVenueLayoutsRepo(pool))
 	eventsService := services.NewEventsService(repos.NewEventsRepo(pool))
+	pricingService := services.NewPricingService(repos.NewPricingRepo(pool))
+	promoCodesService := services.NewPromoCodesService(repos.NewPromoCodesRepo(pool))
+
+	waitingRoomService := services.NewWaitingRoomService(
+		repos.NewWaitingRoomsRepo(pool),
+		queueClient,
+		[]byte(config.WaitingRoomSecret),
+		config.WaitingRoomInterval,
+		config.WaitingRoomAdmission,
+	)
+	go waitingRoomService.Run(ctx)
+
 	ticketsService := services.NewTicketsService(
-		repos.NewTicketsRepo(pool),
+		ticketsRepo,
+		paymentsRepo,
 		ticketHoldClient,
+		paymentProcessor,
+		pricingService,
+		promoCodesService,
+		waitingRoomService,
 		config.TicketHoldDuration,
+		config.TicketHoldMaxExtensions,
+	)
+	ordersService := services.NewOrdersService(repos.NewOrdersRepo(pool))
+	transfersService := services.NewTransfersService(repos.NewTransfersRepo(pool))
+
+	credentialSigningKey, err := credentials.ParsePrivateKey(config.CredentialSigningKey)
+	if err != nil {
+		slog.Error("Unable to parse credential signing key", "error", err)
+		os.Exit(1)
+	}
+	credentialSigner := credentials.NewSigner(credentialSigningKey)
+	credentialsService := services.NewCredentialsService(repos.NewCredentialsRepo(pool), credentialSigner)
+	checkinsService := services.NewCheckinsService(repos.NewCheckinsRepo(pool), credentialSigner)
+
+	waitlistService := services.NewWaitlistService(
+		repos.NewWaitlistsRepo(pool),
+		ticketsService,
+		config.WaitlistOfferDuration,
+	)
+	go waitlistService.Run(ctx, config.WaitlistInterval)
+
+	idempotencyService := services.NewIdempotencyService(
+		repos.NewIdempotencyRepo(pool),
+		config.IdempotencyKeyTTL,
 	)
+	go idempotencyService.Run(ctx, idempotencyPruneInterval)
+
 	searchService, err := services.NewSearchService(searchClient, config.SearchMaxResults)
 	if err != nil {
 		slog.Error("Unable to create a search service", "error", err)
@@ -76,10 +185,21 @@ func main() {
 
 	router := http.NewServeMux()
 	api := humago.New(router, huma.DefaultConfig("API", config.APIVersion))
+	api.UseMiddleware(pkgApi.NewIdempotencyMiddleware(api, idempotencyService))
 
 	pkgApi.RegisterVenuesHandlers(api, venuesService)
+	pkgApi.RegisterVenueLayoutsHandlers(api, venueLayoutsService)
 	pkgApi.RegisterEventsHandlers(api, eventsService)
 	pkgApi.RegisterTicketsHandlers(api, ticketsService)
+	pkgApi.RegisterPricingHandlers(api, pricingService)
+	pkgApi.RegisterPromoCodesHandlers(api, promoCodesService)
+	pkgApi.RegisterOrdersHandlers(api, ordersService)
+	pkgApi.RegisterTransfersHandlers(api, transfersService)
+	pkgApi.RegisterCredentialsHandlers(api, credentialsService)
+	pkgApi.RegisterCheckinsHandlers(api, checkinsService)
+	pkgApi.RegisterCancellationsHandlers(api, cancellationsService)
+	pkgApi.RegisterWaitingRoomHandlers(api, waitingRoomService)
+	pkgApi.RegisterWaitlistHandlers(api, waitlistService)
 	pkgApi.RegisterSearchHandlers(api, searchService)
 
 	address := fmt.Sprintf(":%s", config.Port)
@@ -0,0 +1,8 @@
+package money
+
+import "errors"
+
+var (
+	ErrUnsupportedCurrency = errors.New("The currency is not supported")
+	ErrCurrencyMismatch    = errors.New("The amounts are in different currencies")
+)
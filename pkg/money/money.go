@@ -0,0 +1,123 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minorUnits gives the number of digits after the decimal separator for each
+// supported ISO 4217 currency.
+var minorUnits = map[string]int{
+	"AUD": 2,
+	"BHD": 3,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"DKK": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MXN": 2,
+	"NOK": 2,
+	"NZD": 2,
+	"SEK": 2,
+	"SGD": 2,
+	"USD": 2,
+	"ZAR": 2,
+}
+
+// IsSupportedCurrency is whether the code is a supported ISO 4217 currency.
+func IsSupportedCurrency(code string) bool {
+	_, ok := minorUnits[code]
+	return ok
+}
+
+// Money is an amount of a currency, in the currency's minor units, e.g. cents
+// for USD.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Validate checks that the currency is supported.
+func (m Money) Validate() error {
+	if !IsSupportedCurrency(m.Currency) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedCurrency, m.Currency)
+	}
+	return nil
+}
+
+// Add sums two amounts of the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Multiply scales the amount by `n`, e.g. for a number of tickets.
+func (m Money) Multiply(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// ApplyRate gives the portion of the amount at a rate in basis points, e.g. a
+// rate of 825 gives 8.25% of the amount. Half minor units are rounded away from
+// zero.
+func (m Money) ApplyRate(basisPoints int64) Money {
+	product := m.Amount * basisPoints
+	amount := product / 10000
+	if remainder := product % 10000; remainder >= 5000 {
+		amount++
+	} else if remainder <= -5000 {
+		amount--
+	}
+	return Money{Amount: amount, Currency: m.Currency}
+}
+
+// Sum totals amounts that are all of the same currency. The sum of no amounts
+// is zero, without a currency.
+func Sum(amounts ...Money) (Money, error) {
+	if len(amounts) == 0 {
+		return Money{}, nil
+	}
+
+	total := Money{Currency: amounts[0].Currency}
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// String formats the amount in major units, e.g. "12.50 USD".
+func (m Money) String() string {
+	digits := minorUnits[m.Currency]
+	if digits == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	scale := int64(1)
+	for range digits {
+		scale *= 10
+	}
+	minor := fmt.Sprintf("%d", amount%scale)
+	minor = strings.Repeat("0", digits-len(minor)) + minor
+	return fmt.Sprintf("%s%d.%s %s", sign, amount/scale, minor, m.Currency)
+}
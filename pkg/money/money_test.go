@@ -0,0 +1,53 @@
+package money_test
+
+import (
+	"testing"
+
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyValidate(t *testing.T) {
+	assert.Nil(t, money.New(100, "USD").Validate())
+	assert.ErrorIs(t, money.New(100, "usd").Validate(), money.ErrUnsupportedCurrency)
+	assert.ErrorIs(t, money.New(100, "").Validate(), money.ErrUnsupportedCurrency)
+}
+
+func TestMoneyAdd(t *testing.T) {
+	actual, err := money.New(100, "USD").Add(money.New(250, "USD"))
+	assert.Nil(t, err)
+	assert.Equal(t, money.New(350, "USD"), actual)
+}
+
+func TestMoneyAddWhenCurrenciesDiffer(t *testing.T) {
+	_, err := money.New(100, "USD").Add(money.New(250, "EUR"))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
+
+func TestMoneyApplyRate(t *testing.T) {
+	assert.Equal(t, money.New(83, "USD"), money.New(1000, "USD").ApplyRate(825))
+	assert.Equal(t, money.New(1, "USD"), money.New(10, "USD").ApplyRate(500))
+	assert.Equal(t, money.New(0, "USD"), money.New(10, "USD").ApplyRate(499))
+	assert.Equal(t, money.New(-1, "USD"), money.New(-10, "USD").ApplyRate(500))
+	assert.Equal(t, money.New(0, "USD"), money.New(1000, "USD").ApplyRate(0))
+}
+
+func TestSum(t *testing.T) {
+	actual, err := money.Sum(money.New(100, "EUR"), money.New(250, "EUR"), money.New(5, "EUR"))
+	assert.Nil(t, err)
+	assert.Equal(t, money.New(355, "EUR"), actual)
+
+	actual, err = money.Sum()
+	assert.Nil(t, err)
+	assert.Equal(t, money.Money{}, actual)
+
+	_, err = money.Sum(money.New(100, "EUR"), money.New(250, "USD"))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
+
+func TestMoneyString(t *testing.T) {
+	assert.Equal(t, "12.05 USD", money.New(1205, "USD").String())
+	assert.Equal(t, "-0.50 EUR", money.New(-50, "EUR").String())
+	assert.Equal(t, "1500 JPY", money.New(1500, "JPY").String())
+	assert.Equal(t, "1.250 KWD", money.New(1250, "KWD").String())
+}
@@ -0,0 +1,23 @@
+package payment
+
+import (
+	"errors"
+
+	"github.com/dslaw/book-tickets/pkg/money"
+)
+
+var (
+	ErrGatewayUnavailable   = errors.New("The payment gateway is unavailable")
+	ErrUnknownPayment       = errors.New("The payment is not known to the payment gateway")
+	ErrInvalidTransition    = errors.New("The payment can't be moved to the given status")
+	ErrRefundExceedsPayment = errors.New("The refund is more than the payment's remaining amount")
+)
+
+// IsPermanent reports whether the error is given by a payment processor for
+// every attempt of a call, so that the call shouldn't be retried.
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrUnknownPayment) ||
+		errors.Is(err, ErrInvalidTransition) ||
+		errors.Is(err, ErrRefundExceedsPayment) ||
+		errors.Is(err, money.ErrCurrencyMismatch)
+}
@@ -0,0 +1,190 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+)
+
+// FakeRule declines, or fails, payments made with a card whose number matches
+// the pattern. If `Err` is set, the payment fails with the error instead of
+// being declined.
+type FakeRule struct {
+	Pattern       *regexp.Regexp
+	DeclineReason string
+	Err           error
+}
+
+// DefaultFakeRules returns rules for a set of well-known test card numbers.
+// Payments made with any other card are accepted.
+func DefaultFakeRules() []FakeRule {
+	return []FakeRule{
+		{Pattern: regexp.MustCompile(`^4000000000000002$`), DeclineReason: DeclineReasonCardDeclined},
+		{Pattern: regexp.MustCompile(`^4000000000009995$`), DeclineReason: DeclineReasonInsufficientFunds},
+		{Pattern: regexp.MustCompile(`^4000000000000069$`), DeclineReason: DeclineReasonExpiredCard},
+		{Pattern: regexp.MustCompile(`^4000000000000127$`), DeclineReason: DeclineReasonIncorrectCVC},
+		{Pattern: regexp.MustCompile(`^4000000000000119$`), Err: ErrGatewayUnavailable},
+	}
+}
+
+// FakeProcessor is an in-process payment processor, for local development and
+// testing. Every call waits for `Latency` before completing.
+type FakeProcessor struct {
+	Rules   []FakeRule
+	Latency time.Duration
+
+	mu       sync.Mutex
+	sequence int
+	statuses map[string]string
+	amounts  map[string]money.Money
+	refunded map[string]money.Money
+	// References of refunds, by the key they were made with, if any.
+	refunds map[string]string
+}
+
+func NewFakeProcessor(rules []FakeRule, latency time.Duration) *FakeProcessor {
+	return &FakeProcessor{
+		Rules:    rules,
+		Latency:  latency,
+		statuses: make(map[string]string),
+		amounts:  make(map[string]money.Money),
+		refunded: make(map[string]money.Money),
+		refunds:  make(map[string]string),
+	}
+}
+
+func (proc *FakeProcessor) wait(ctx context.Context) error {
+	if proc.Latency <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(proc.Latency)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (proc *FakeProcessor) nextReference() string {
+	proc.sequence++
+	return fmt.Sprintf("fake-%d", proc.sequence)
+}
+
+// transition moves the payment given by the reference to the given status.
+func (proc *FakeProcessor) transition(ctx context.Context, reference string, status string) error {
+	if err := proc.wait(ctx); err != nil {
+		return err
+	}
+
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+
+	current, ok := proc.statuses[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if err := ValidateTransition(current, status); err != nil {
+		return err
+	}
+	proc.statuses[reference] = status
+	return nil
+}
+
+func (proc *FakeProcessor) Authorize(ctx context.Context, amount money.Money, card Card) (PaymentResult, error) {
+	if err := proc.wait(ctx); err != nil {
+		return PaymentResult{}, err
+	}
+
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+
+	for _, rule := range proc.Rules {
+		if !rule.Pattern.MatchString(card.Number) {
+			continue
+		}
+		if rule.Err != nil {
+			return PaymentResult{}, rule.Err
+		}
+
+		reference := proc.nextReference()
+		proc.statuses[reference] = entities.PaymentStatusFailed
+		return PaymentResult{
+			Accepted:      false,
+			Reference:     reference,
+			DeclineReason: rule.DeclineReason,
+		}, nil
+	}
+
+	reference := proc.nextReference()
+	proc.statuses[reference] = entities.PaymentStatusAuthorized
+	proc.amounts[reference] = amount
+	proc.refunded[reference] = money.New(0, amount.Currency)
+	return PaymentResult{Accepted: true, Reference: reference}, nil
+}
+
+func (proc *FakeProcessor) Capture(ctx context.Context, reference string) error {
+	return proc.transition(ctx, reference, entities.PaymentStatusCaptured)
+}
+
+func (proc *FakeProcessor) VoidPayment(ctx context.Context, reference string) error {
+	return proc.transition(ctx, reference, entities.PaymentStatusVoided)
+}
+
+func (proc *FakeProcessor) Refund(ctx context.Context, reference string, amount money.Money, key string) (string, error) {
+	if err := proc.wait(ctx); err != nil {
+		return "", err
+	}
+
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+
+	if refundReference, ok := proc.refunds[key]; ok && key != "" {
+		return refundReference, nil
+	}
+
+	current, ok := proc.statuses[reference]
+	if !ok {
+		return "", ErrUnknownPayment
+	}
+	if err := ValidateTransition(current, entities.PaymentStatusRefunded); err != nil {
+		return "", err
+	}
+
+	refunded, err := proc.refunded[reference].Add(amount)
+	if err != nil {
+		return "", err
+	}
+	if refunded.Amount > proc.amounts[reference].Amount {
+		return "", ErrRefundExceedsPayment
+	}
+
+	proc.refunded[reference] = refunded
+	if refunded == proc.amounts[reference] {
+		proc.statuses[reference] = entities.PaymentStatusRefunded
+	}
+
+	refundReference := fmt.Sprintf("%s-refund-%d", reference, refunded.Amount)
+	if key != "" {
+		proc.refunds[key] = refundReference
+	}
+	return refundReference, nil
+}
+
+// Status returns the status of the payment given by the reference, if the
+// payment is known.
+func (proc *FakeProcessor) Status(reference string) (string, bool) {
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+
+	status, ok := proc.statuses[reference]
+	return status, ok
+}
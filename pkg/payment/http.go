@@ -0,0 +1,289 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dslaw/book-tickets/pkg/money"
+)
+
+const (
+	gatewayStatusApproved = "approved"
+	gatewayStatusDeclined = "declined"
+)
+
+type gatewayCard struct {
+	Name            string `json:"name"`
+	Address         string `json:"address"`
+	Number          string `json:"number"`
+	ExpirationMonth uint8  `json:"expiration_month"`
+	ExpirationYear  uint8  `json:"expiration_year"`
+	CVC             string `json:"cvc"`
+}
+
+// Amounts are in the minor units of the currency.
+type gatewayPaymentRequest struct {
+	Amount   int64       `json:"amount"`
+	Currency string      `json:"currency"`
+	Card     gatewayCard `json:"card"`
+}
+
+type gatewayRefundRequest struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type gatewayRefundResponse struct {
+	Reference string `json:"reference"`
+}
+
+type gatewayPaymentResponse struct {
+	Reference     string `json:"reference"`
+	Status        string `json:"status"`
+	DeclineReason string `json:"decline_reason,omitempty"`
+}
+
+// HTTPProcessor is a payment processor that talks to a payment gateway over
+// HTTP.
+type HTTPProcessor struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPProcessor(baseURL string, client *http.Client) *HTTPProcessor {
+	return &HTTPProcessor{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// post sends the body to the gateway. The idempotency key is only sent if
+// given.
+func (proc *HTTPProcessor) post(ctx context.Context, path string, body any, key string) (*http.Response, error) {
+	buf := &bytes.Buffer{}
+	if body != nil {
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, proc.baseURL+path, buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	resp, err := proc.client.Do(req)
+	if err != nil {
+		return nil, errors.Join(ErrGatewayUnavailable, err)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: status %d", ErrGatewayUnavailable, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (proc *HTTPProcessor) Authorize(ctx context.Context, amount money.Money, card Card) (PaymentResult, error) {
+	body := gatewayPaymentRequest{
+		Amount:   amount.Amount,
+		Currency: amount.Currency,
+		Card: gatewayCard{
+			Name:            card.Name,
+			Address:         card.Address,
+			Number:          card.Number,
+			ExpirationMonth: card.ExpirationMonth,
+			ExpirationYear:  card.ExpirationYear,
+			CVC:             card.CVC,
+		},
+	}
+
+	resp, err := proc.post(ctx, "/payments", body, "")
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return PaymentResult{}, fmt.Errorf("Unexpected response status from payment gateway: %d", resp.StatusCode)
+	}
+
+	var data gatewayPaymentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return PaymentResult{}, err
+	}
+
+	switch data.Status {
+	case gatewayStatusApproved:
+		return PaymentResult{Accepted: true, Reference: data.Reference}, nil
+	case gatewayStatusDeclined:
+		return PaymentResult{
+			Accepted:      false,
+			Reference:     data.Reference,
+			DeclineReason: data.DeclineReason,
+		}, nil
+	default:
+		return PaymentResult{}, fmt.Errorf("Unexpected payment status from payment gateway: %s", data.Status)
+	}
+}
+
+// act performs an action, e.g. capturing, on the payment given by the
+// reference.
+func (proc *HTTPProcessor) act(ctx context.Context, reference string, action string) error {
+	path := fmt.Sprintf("/payments/%s/%s", url.PathEscape(reference), action)
+	resp, err := proc.post(ctx, path, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return ErrUnknownPayment
+	case http.StatusConflict:
+		return ErrInvalidTransition
+	default:
+		return fmt.Errorf("Unexpected response status from payment gateway: %d", resp.StatusCode)
+	}
+}
+
+func (proc *HTTPProcessor) Capture(ctx context.Context, reference string) error {
+	return proc.act(ctx, reference, "capture")
+}
+
+func (proc *HTTPProcessor) VoidPayment(ctx context.Context, reference string) error {
+	return proc.act(ctx, reference, "void")
+}
+
+func (proc *HTTPProcessor) Refund(ctx context.Context, reference string, amount money.Money, key string) (string, error) {
+	path := fmt.Sprintf("/payments/%s/refunds", url.PathEscape(reference))
+	body := gatewayRefundRequest{Amount: amount.Amount, Currency: amount.Currency}
+	resp, err := proc.post(ctx, path, body, key)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", ErrUnknownPayment
+	case http.StatusConflict:
+		return "", ErrInvalidTransition
+	case http.StatusUnprocessableEntity:
+		return "", ErrRefundExceedsPayment
+	default:
+		return "", fmt.Errorf("Unexpected response status from payment gateway: %d", resp.StatusCode)
+	}
+
+	var data gatewayRefundResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return "", err
+	}
+	return data.Reference, nil
+}
+
+// NewGatewayHandler returns a handler that serves the payment gateway API
+// expected by `HTTPProcessor`, backed by the given processor. It is intended
+// to be used as a local stand-in for a real payment gateway.
+func NewGatewayHandler(processor PaymentProcessor) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /payments", func(w http.ResponseWriter, r *http.Request) {
+		var data gatewayPaymentRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		card := Card{
+			Name:            data.Card.Name,
+			Address:         data.Card.Address,
+			Number:          data.Card.Number,
+			ExpirationMonth: data.Card.ExpirationMonth,
+			ExpirationYear:  data.Card.ExpirationYear,
+			CVC:             data.Card.CVC,
+		}
+		amount := money.New(data.Amount, data.Currency)
+		result, err := processor.Authorize(r.Context(), amount, card)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		response := gatewayPaymentResponse{
+			Reference:     result.Reference,
+			Status:        gatewayStatusApproved,
+			DeclineReason: result.DeclineReason,
+		}
+		if !result.Accepted {
+			response.Status = gatewayStatusDeclined
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	handleAction := func(action func(context.Context, string) error) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			err := action(r.Context(), r.PathValue("reference"))
+			if errors.Is(err, ErrUnknownPayment) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if errors.Is(err, ErrInvalidTransition) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+
+	mux.HandleFunc("POST /payments/{reference}/capture", handleAction(processor.Capture))
+	mux.HandleFunc("POST /payments/{reference}/void", handleAction(processor.VoidPayment))
+
+	mux.HandleFunc("POST /payments/{reference}/refunds", func(w http.ResponseWriter, r *http.Request) {
+		var data gatewayRefundRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		amount := money.New(data.Amount, data.Currency)
+		key := r.Header.Get("Idempotency-Key")
+		reference, err := processor.Refund(r.Context(), r.PathValue("reference"), amount, key)
+		if errors.Is(err, ErrUnknownPayment) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrInvalidTransition) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrRefundExceedsPayment) || errors.Is(err, money.ErrCurrencyMismatch) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gatewayRefundResponse{Reference: reference})
+	})
+
+	return mux
+}
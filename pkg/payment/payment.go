@@ -1,6 +1,10 @@
 package payment
 
-import "github.com/dslaw/book-tickets/pkg/entities"
+import (
+	"context"
+
+	"github.com/dslaw/book-tickets/pkg/money"
+)
 
 type Card struct {
 	Name            string
@@ -11,11 +15,42 @@ type Card struct {
 	CVC             string
 }
 
-// This, and "implemented" methods, are a stub/placeholder.
-type PaymentClient struct{}
+// Reasons given by a payment processor for declining a payment.
+const (
+	DeclineReasonCardDeclined      = "card_declined"
+	DeclineReasonInsufficientFunds = "insufficient_funds"
+	DeclineReasonExpiredCard       = "expired_card"
+	DeclineReasonIncorrectCVC      = "incorrect_cvc"
+)
+
+// PaymentResult is the outcome of authorizing a payment. The reference
+// identifies the payment with the third-party service, and is needed to
+// capture or void the payment. The decline reason is only set if the payment
+// was declined.
+type PaymentResult struct {
+	Accepted      bool
+	Reference     string
+	DeclineReason string
+}
 
-// SubmitPayment submits a user's payment for a ticket to a third-party service,
-// and returns a boolean indicating whether the payment was accepted or not.
-func (svc *PaymentClient) SubmitPayment(ticket entities.Ticket, card Card) (bool, error) {
-	return true, nil
+// PaymentProcessor makes payments with a third-party payment service, in two
+// phases: a payment is first authorized, reserving the funds, and is then
+// either captured, charging the user, or voided.
+type PaymentProcessor interface {
+	// Authorize submits a user's payment of `amount` as a single charge for
+	// authorization, and returns whether the payment was accepted or not.
+	Authorize(ctx context.Context, amount money.Money, card Card) (PaymentResult, error)
+	// Capture charges the user for an authorized payment, given by its
+	// reference.
+	Capture(ctx context.Context, reference string) error
+	// VoidPayment cancels an authorized payment, given by its reference, so
+	// that the user is not charged.
+	VoidPayment(ctx context.Context, reference string) error
+	// Refund returns `amount` of a captured payment, given by its reference,
+	// to the user, and returns the refund's reference. A payment may be
+	// refunded in parts, up to the captured amount, in the payment's currency.
+	// If `key` is given, it identifies the refund, so that retrying it with
+	// the same key gives the original refund's reference rather than refunding
+	// the payment again.
+	Refund(ctx context.Context, reference string, amount money.Money, key string) (string, error)
 }
@@ -0,0 +1,185 @@
+package payment_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeProcessorAuthorize(t *testing.T) {
+	processor := payment.NewFakeProcessor(payment.DefaultFakeRules(), 0)
+	actual, err := processor.Authorize(context.Background(), money.New(10, "USD"), payment.Card{Number: "4242424242424242"})
+
+	assert.Nil(t, err)
+	assert.True(t, actual.Accepted)
+	assert.NotEmpty(t, actual.Reference)
+	assert.Empty(t, actual.DeclineReason)
+}
+
+func TestFakeProcessorAuthorizeWhenDeclined(t *testing.T) {
+	processor := payment.NewFakeProcessor(payment.DefaultFakeRules(), 0)
+	actual, err := processor.Authorize(context.Background(), money.New(10, "USD"), payment.Card{Number: "4000000000009995"})
+
+	assert.Nil(t, err)
+	assert.False(t, actual.Accepted)
+	assert.NotEmpty(t, actual.Reference)
+	assert.Equal(t, payment.DeclineReasonInsufficientFunds, actual.DeclineReason)
+}
+
+func TestFakeProcessorAuthorizeWhenErrorInjected(t *testing.T) {
+	rules := []payment.FakeRule{
+		{Pattern: regexp.MustCompile(`1$`), Err: payment.ErrGatewayUnavailable},
+	}
+	processor := payment.NewFakeProcessor(rules, 0)
+	_, err := processor.Authorize(context.Background(), money.New(10, "USD"), payment.Card{Number: "4000000000000001"})
+
+	assert.ErrorIs(t, err, payment.ErrGatewayUnavailable)
+}
+
+func TestFakeProcessorAuthorizeWhenCancelledDuringLatency(t *testing.T) {
+	processor := payment.NewFakeProcessor(nil, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFakeProcessorCapture(t *testing.T) {
+	ctx := context.Background()
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{})
+
+	assert.Nil(t, processor.Capture(ctx, result.Reference))
+	status, _ := processor.Status(result.Reference)
+	assert.Equal(t, entities.PaymentStatusCaptured, status)
+
+	// A captured payment can't be voided.
+	assert.ErrorIs(t, processor.VoidPayment(ctx, result.Reference), payment.ErrInvalidTransition)
+}
+
+func TestFakeProcessorCaptureWhenDeclined(t *testing.T) {
+	ctx := context.Background()
+	processor := payment.NewFakeProcessor(payment.DefaultFakeRules(), 0)
+	result, _ := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{Number: "4000000000000002"})
+
+	assert.ErrorIs(t, processor.Capture(ctx, result.Reference), payment.ErrInvalidTransition)
+}
+
+func TestFakeProcessorVoidPayment(t *testing.T) {
+	ctx := context.Background()
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{})
+
+	assert.Nil(t, processor.VoidPayment(ctx, result.Reference))
+	// A payment can only be voided once, and can't be captured once voided.
+	assert.ErrorIs(t, processor.VoidPayment(ctx, result.Reference), payment.ErrInvalidTransition)
+	assert.ErrorIs(t, processor.Capture(ctx, result.Reference), payment.ErrInvalidTransition)
+	assert.ErrorIs(t, processor.VoidPayment(ctx, "unknown"), payment.ErrUnknownPayment)
+}
+
+func TestFakeProcessorRefund(t *testing.T) {
+	ctx := context.Background()
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(30, "USD"), payment.Card{})
+
+	// Only captured payments can be refunded.
+	_, err := processor.Refund(ctx, result.Reference, money.New(10, "USD"), "")
+	assert.ErrorIs(t, err, payment.ErrInvalidTransition)
+
+	processor.Capture(ctx, result.Reference)
+
+	reference, err := processor.Refund(ctx, result.Reference, money.New(10, "USD"), "")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, reference)
+	status, _ := processor.Status(result.Reference)
+	assert.Equal(t, entities.PaymentStatusCaptured, status)
+
+	_, err = processor.Refund(ctx, result.Reference, money.New(30, "USD"), "")
+	assert.ErrorIs(t, err, payment.ErrRefundExceedsPayment)
+
+	_, err = processor.Refund(ctx, result.Reference, money.New(20, "EUR"), "")
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	_, err = processor.Refund(ctx, result.Reference, money.New(20, "USD"), "")
+	assert.Nil(t, err)
+	status, _ = processor.Status(result.Reference)
+	assert.Equal(t, entities.PaymentStatusRefunded, status)
+}
+
+func TestFakeProcessorRefundWithKey(t *testing.T) {
+	ctx := context.Background()
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(30, "USD"), payment.Card{})
+	processor.Capture(ctx, result.Reference)
+
+	reference, err := processor.Refund(ctx, result.Reference, money.New(20, "USD"), "refund-1")
+	assert.Nil(t, err)
+
+	// Retrying the refund doesn't refund the payment again.
+	retried, err := processor.Refund(ctx, result.Reference, money.New(20, "USD"), "refund-1")
+	assert.Nil(t, err)
+	assert.Equal(t, reference, retried)
+
+	_, err = processor.Refund(ctx, result.Reference, money.New(20, "USD"), "refund-2")
+	assert.ErrorIs(t, err, payment.ErrRefundExceedsPayment)
+}
+
+func TestHTTPProcessor(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(
+		payment.NewGatewayHandler(payment.NewFakeProcessor(payment.DefaultFakeRules(), 0)),
+	)
+	defer server.Close()
+
+	processor := payment.NewHTTPProcessor(server.URL, server.Client())
+
+	accepted, err := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{Number: "4242424242424242"})
+	assert.Nil(t, err)
+	assert.True(t, accepted.Accepted)
+
+	declined, err := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{Number: "4000000000000002"})
+	assert.Nil(t, err)
+	assert.False(t, declined.Accepted)
+	assert.Equal(t, payment.DeclineReasonCardDeclined, declined.DeclineReason)
+
+	_, err = processor.Authorize(ctx, money.New(10, "USD"), payment.Card{Number: "4000000000000119"})
+	assert.ErrorIs(t, err, payment.ErrGatewayUnavailable)
+
+	assert.Nil(t, processor.Capture(ctx, accepted.Reference))
+
+	refundReference, err := processor.Refund(ctx, accepted.Reference, money.New(5, "USD"), "")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, refundReference)
+	_, err = processor.Refund(ctx, accepted.Reference, money.New(10, "USD"), "")
+	assert.ErrorIs(t, err, payment.ErrRefundExceedsPayment)
+
+	// The idempotency key is passed through to the gateway.
+	keyedReference, err := processor.Refund(ctx, accepted.Reference, money.New(5, "USD"), "refund-1")
+	assert.Nil(t, err)
+	retriedReference, err := processor.Refund(ctx, accepted.Reference, money.New(5, "USD"), "refund-1")
+	assert.Nil(t, err)
+	assert.Equal(t, keyedReference, retriedReference)
+
+	assert.ErrorIs(t, processor.VoidPayment(ctx, accepted.Reference), payment.ErrInvalidTransition)
+	assert.ErrorIs(t, processor.VoidPayment(ctx, "unknown"), payment.ErrUnknownPayment)
+}
+
+func TestHTTPProcessorWhenGatewayUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	processor := payment.NewHTTPProcessor(server.URL, http.DefaultClient)
+	_, err := processor.Authorize(context.Background(), money.New(10, "USD"), payment.Card{})
+
+	assert.ErrorIs(t, err, payment.ErrGatewayUnavailable)
+}
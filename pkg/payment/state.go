@@ -0,0 +1,62 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+)
+
+// transitions gives the statuses that a payment can move to from each status.
+// Payments start as pending, and voided, failed and refunded payments can't be
+// moved out of. An authorized payment fails if the payment processor won't
+// void it, e.g. as it doesn't know of it.
+var transitions = map[string][]string{
+	entities.PaymentStatusPending: {
+		entities.PaymentStatusAuthorized,
+		entities.PaymentStatusFailed,
+	},
+	entities.PaymentStatusAuthorized: {
+		entities.PaymentStatusCaptured,
+		entities.PaymentStatusVoided,
+		entities.PaymentStatusFailed,
+	},
+	entities.PaymentStatusCaptured: {
+		entities.PaymentStatusRefunded,
+	},
+}
+
+// ValidateTransition checks that a payment can move from the `from` status to
+// the `to` status.
+func ValidateTransition(from string, to string) error {
+	if !slices.Contains(transitions[from], to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
+// PaymentStore persists the status of payments.
+type PaymentStore interface {
+	// UpdatePaymentStatus updates the payment's status, reference and decline
+	// reason, if its currently stored status is `from`.
+	UpdatePaymentStatus(ctx context.Context, payment entities.Payment, from string) error
+}
+
+// Transition moves the payment to the given status, both in the store and on
+// the given payment. The payment is left unchanged if the transition is
+// invalid or can't be stored.
+func Transition(ctx context.Context, store PaymentStore, payment *entities.Payment, status string) error {
+	if err := ValidateTransition(payment.Status, status); err != nil {
+		return err
+	}
+
+	updated := *payment
+	updated.Status = status
+	if err := store.UpdatePaymentStatus(ctx, updated, payment.Status); err != nil {
+		return err
+	}
+
+	*payment = updated
+	return nil
+}
@@ -0,0 +1,87 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubStore struct {
+	err   error
+	calls int
+	from  string
+}
+
+func (store *stubStore) UpdatePaymentStatus(ctx context.Context, p entities.Payment, from string) error {
+	store.calls++
+	store.from = from
+	return store.err
+}
+
+func TestValidateTransition(t *testing.T) {
+	type testCase struct {
+		From  string
+		To    string
+		Valid bool
+	}
+
+	testCases := []testCase{
+		{entities.PaymentStatusPending, entities.PaymentStatusAuthorized, true},
+		{entities.PaymentStatusPending, entities.PaymentStatusFailed, true},
+		{entities.PaymentStatusPending, entities.PaymentStatusCaptured, false},
+		{entities.PaymentStatusAuthorized, entities.PaymentStatusCaptured, true},
+		{entities.PaymentStatusAuthorized, entities.PaymentStatusVoided, true},
+		{entities.PaymentStatusAuthorized, entities.PaymentStatusFailed, true},
+		{entities.PaymentStatusCaptured, entities.PaymentStatusRefunded, true},
+		{entities.PaymentStatusCaptured, entities.PaymentStatusVoided, false},
+		{entities.PaymentStatusVoided, entities.PaymentStatusCaptured, false},
+		{entities.PaymentStatusFailed, entities.PaymentStatusAuthorized, false},
+		{entities.PaymentStatusRefunded, entities.PaymentStatusCaptured, false},
+	}
+
+	for _, tc := range testCases {
+		err := payment.ValidateTransition(tc.From, tc.To)
+		if tc.Valid {
+			assert.Nil(t, err, "%s to %s", tc.From, tc.To)
+		} else {
+			assert.ErrorIs(t, err, payment.ErrInvalidTransition, "%s to %s", tc.From, tc.To)
+		}
+	}
+}
+
+func TestTransition(t *testing.T) {
+	store := &stubStore{}
+	record := entities.Payment{ID: 1, Status: entities.PaymentStatusPending}
+
+	err := payment.Transition(context.Background(), store, &record, entities.PaymentStatusAuthorized)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.PaymentStatusAuthorized, record.Status)
+	assert.Equal(t, entities.PaymentStatusPending, store.from)
+}
+
+func TestTransitionWhenInvalid(t *testing.T) {
+	store := &stubStore{}
+	record := entities.Payment{ID: 1, Status: entities.PaymentStatusPending}
+
+	err := payment.Transition(context.Background(), store, &record, entities.PaymentStatusCaptured)
+
+	assert.ErrorIs(t, err, payment.ErrInvalidTransition)
+	assert.Equal(t, entities.PaymentStatusPending, record.Status)
+	assert.Equal(t, 0, store.calls)
+}
+
+func TestTransitionWhenStoreFails(t *testing.T) {
+	storeErr := errors.New("store failed")
+	store := &stubStore{err: storeErr}
+	record := entities.Payment{ID: 1, Status: entities.PaymentStatusPending}
+
+	err := payment.Transition(context.Background(), store, &record, entities.PaymentStatusAuthorized)
+
+	assert.ErrorIs(t, err, storeErr)
+	assert.Equal(t, entities.PaymentStatusPending, record.Status)
+}
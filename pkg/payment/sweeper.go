@@ -0,0 +1,99 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+)
+
+// AuthorizationStore persists payments, and finds authorizations that have
+// not been captured or voided.
+type AuthorizationStore interface {
+	PaymentStore
+	// GetStaleAuthorizedPayments fetches up to `limit` payments that have been
+	// authorized, and not updated since `before`.
+	GetStaleAuthorizedPayments(ctx context.Context, before time.Time, limit int32) ([]entities.Payment, error)
+}
+
+// Sweeper voids authorized payments that have not been captured within
+// `MaxAge`, e.g. if the purchase they were made for crashed part-way through,
+// so that users' funds aren't reserved indefinitely. `MaxAge` should be longer
+// than a purchase can take, so that in-progress purchases aren't voided.
+type Sweeper struct {
+	store     AuthorizationStore
+	processor PaymentProcessor
+	MaxAge    time.Duration
+	BatchSize int32
+}
+
+func NewSweeper(
+	store AuthorizationStore,
+	processor PaymentProcessor,
+	maxAge time.Duration,
+	batchSize int32,
+) *Sweeper {
+	return &Sweeper{
+		store:     store,
+		processor: processor,
+		MaxAge:    maxAge,
+		BatchSize: batchSize,
+	}
+}
+
+// Sweep voids a batch of stale authorizations, and returns the number of
+// payments voided. Failing to void one payment doesn't stop the others from
+// being voided. A payment that the payment processor won't void, e.g. as it
+// doesn't know of it or has already captured it, is marked as failed so that
+// it isn't swept again, and is left to be reconciled by hand. Payments that
+// couldn't be voided otherwise are retried by the next sweep.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	before := time.Now().Add(-s.MaxAge)
+	payments, err := s.store.GetStaleAuthorizedPayments(ctx, before, s.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	countVoided := 0
+	var errs []error
+	for _, payment := range payments {
+		if err := s.processor.VoidPayment(ctx, payment.Reference); err != nil {
+			if IsPermanent(err) {
+				err = errors.Join(err, Transition(ctx, s.store, &payment, entities.PaymentStatusFailed))
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if err := Transition(ctx, s.store, &payment, entities.PaymentStatusVoided); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		countVoided++
+	}
+
+	return countVoided, errors.Join(errs...)
+}
+
+// Run sweeps stale authorizations every `interval`, until the context is
+// done.
+func (s *Sweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			countVoided, err := s.Sweep(ctx)
+			if err != nil {
+				slog.Error("Issue voiding stale payment authorizations", "error", err)
+			}
+			if countVoided > 0 {
+				slog.Info("Voided stale payment authorizations", "count", countVoided)
+			}
+		}
+	}
+}
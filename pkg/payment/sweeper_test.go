@@ -0,0 +1,123 @@
+package payment_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAuthorizationStore struct {
+	stubStore
+	payments []entities.Payment
+	before   time.Time
+	// Statuses that payments have been updated to, by id.
+	statuses map[int32]string
+}
+
+func (store *stubAuthorizationStore) UpdatePaymentStatus(ctx context.Context, p entities.Payment, from string) error {
+	if store.statuses == nil {
+		store.statuses = make(map[int32]string)
+	}
+	store.statuses[p.ID] = p.Status
+	return store.stubStore.UpdatePaymentStatus(ctx, p, from)
+}
+
+func (store *stubAuthorizationStore) GetStaleAuthorizedPayments(
+	ctx context.Context,
+	before time.Time,
+	limit int32,
+) ([]entities.Payment, error) {
+	store.before = before
+	return store.payments, nil
+}
+
+func TestSweeperSweep(t *testing.T) {
+	ctx := context.Background()
+	processor := payment.NewFakeProcessor(nil, 0)
+	authorized, _ := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{})
+	captured, _ := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{})
+	processor.Capture(ctx, captured.Reference)
+
+	store := &stubAuthorizationStore{
+		payments: []entities.Payment{
+			{ID: 1, Status: entities.PaymentStatusAuthorized, Reference: authorized.Reference},
+			// Already captured with the processor, so can't be voided.
+			{ID: 2, Status: entities.PaymentStatusAuthorized, Reference: captured.Reference},
+		},
+	}
+
+	sweeper := payment.NewSweeper(store, processor, time.Minute, 10)
+	countVoided, err := sweeper.Sweep(ctx)
+
+	assert.Equal(t, 1, countVoided)
+	assert.ErrorIs(t, err, payment.ErrInvalidTransition)
+	assert.WithinDuration(t, time.Now().Add(-time.Minute), store.before, time.Second)
+	assert.Equal(t, 2, store.calls)
+
+	status, _ := processor.Status(authorized.Reference)
+	assert.Equal(t, entities.PaymentStatusVoided, status)
+
+	// The captured payment won't be voided, so is failed rather than swept
+	// again, and remains captured with the processor.
+	assert.Equal(t, map[int32]string{
+		1: entities.PaymentStatusVoided,
+		2: entities.PaymentStatusFailed,
+	}, store.statuses)
+	status, _ = processor.Status(captured.Reference)
+	assert.Equal(t, entities.PaymentStatusCaptured, status)
+}
+
+func TestSweeperSweepWhenUnknownPayment(t *testing.T) {
+	ctx := context.Background()
+	processor := payment.NewFakeProcessor(nil, 0)
+
+	store := &stubAuthorizationStore{
+		payments: []entities.Payment{
+			{ID: 1, Status: entities.PaymentStatusAuthorized, Reference: "unknown"},
+		},
+	}
+
+	sweeper := payment.NewSweeper(store, processor, time.Minute, 10)
+	countVoided, err := sweeper.Sweep(ctx)
+
+	assert.Equal(t, 0, countVoided)
+	assert.ErrorIs(t, err, payment.ErrUnknownPayment)
+	assert.Equal(t, map[int32]string{1: entities.PaymentStatusFailed}, store.statuses)
+}
+
+// unavailableProcessor is a payment processor that can't be reached to void
+// payments.
+type unavailableProcessor struct {
+	*payment.FakeProcessor
+}
+
+func (proc *unavailableProcessor) VoidPayment(ctx context.Context, reference string) error {
+	return payment.ErrGatewayUnavailable
+}
+
+func TestSweeperSweepWhenGatewayUnavailable(t *testing.T) {
+	ctx := context.Background()
+	processor := &unavailableProcessor{payment.NewFakeProcessor(nil, 0)}
+	authorized, _ := processor.Authorize(ctx, money.New(10, "USD"), payment.Card{})
+
+	store := &stubAuthorizationStore{
+		payments: []entities.Payment{
+			{ID: 1, Status: entities.PaymentStatusAuthorized, Reference: authorized.Reference},
+		},
+	}
+
+	sweeper := payment.NewSweeper(store, processor, time.Minute, 10)
+	countVoided, err := sweeper.Sweep(ctx)
+
+	// The payment is left authorized, to be swept again.
+	assert.Equal(t, 0, countVoided)
+	assert.ErrorIs(t, err, payment.ErrGatewayUnavailable)
+	assert.Empty(t, store.statuses)
+	status, _ := processor.Status(authorized.Reference)
+	assert.Equal(t, entities.PaymentStatusAuthorized, status)
+}
@@ -5,4 +5,13 @@ import "errors"
 var (
 	ErrNoSuchEntity  = errors.New("Entity does not exist")
 	ErrEntityDeleted = errors.New("Entity has been deleted")
+	ErrEntityInUse   = errors.New("Entity is referenced by another entity")
+
+	ErrNoRedemptionsLeft = errors.New("No redemptions are left")
+	ErrNoCapacityLeft    = errors.New("No capacity is left")
+	ErrNoSuchUser        = errors.New("User does not exist")
+
+	ErrPurchaseLimitExceeded = errors.New("Purchase limit is exceeded")
+
+	ErrAlreadyCheckedIn = errors.New("Ticket has already been checked in")
 )
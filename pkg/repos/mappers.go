@@ -5,6 +5,7 @@ import (
 
 	"github.com/dslaw/book-tickets/pkg/db"
 	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -20,6 +21,18 @@ func MapPurchaserID(id int32) pgtype.Int4 {
 	return pgtype.Int4{Int32: id, Valid: true}
 }
 
+func MapNullableID(id int32) pgtype.Int4 {
+	return pgtype.Int4{Int32: id, Valid: id != 0}
+}
+
+func MapNullableInt(n int32) pgtype.Int4 {
+	return pgtype.Int4{Int32: n, Valid: n != 0}
+}
+
+func MapNullableTime(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: !t.IsZero()}
+}
+
 func MapGetEventRows(rows []db.GetEventRow) entities.Event {
 	if len(rows) == 0 {
 		return entities.Event{}
@@ -49,17 +62,23 @@ func MapGetEventRows(rows []db.GetEventRow) entities.Event {
 			ID:   row.Event.VenueID,
 			Name: row.VenueName,
 		},
+		MaxTicketsPerUser: row.Event.MaxTicketsPerUser.Int32,
+		ResaleMinRate:     row.Event.ResaleMinRate.Int32,
+		ResaleMaxRate:     row.Event.ResaleMaxRate.Int32,
 	}
 }
 
 func MapTicket(model db.Ticket) entities.Ticket {
 	return entities.Ticket{
-		ID:          model.ID,
-		EventID:     model.EventID,
-		PurchaserID: model.PurchaserID.Int32,
-		IsPurchased: model.PurchaserID.Valid,
-		Price:       uint8(model.Price),
-		Seat:        model.Seat,
+		ID:           model.ID,
+		EventID:      model.EventID,
+		PurchaserID:  model.PurchaserID.Int32,
+		IsPurchased:  model.PurchaserID.Valid,
+		Price:        money.New(model.Price, model.Currency),
+		Seat:         model.Seat,
+		VenueSeatID:  model.VenueSeatID.Int32,
+		TicketTypeID: model.TicketTypeID.Int32,
+		ReleaseID:    model.ReleaseID.Int32,
 	}
 }
 
@@ -70,3 +89,463 @@ func MapGetAvailableTicketRows(rows []db.GetAvailableTicketsRow) []entities.Tick
 	}
 	return tickets
 }
+
+func MapGetAvailableSeatedTicketsRows(rows []db.GetAvailableSeatedTicketsRow) []entities.SeatedTicket {
+	tickets := make([]entities.SeatedTicket, len(rows))
+	for idx, row := range rows {
+		tickets[idx] = entities.SeatedTicket{
+			Ticket: MapTicket(row.Ticket),
+			Position: entities.SeatPosition{
+				Section:  row.SectionName,
+				RowID:    row.RowID,
+				Row:      row.RowName,
+				Number:   row.SeatNumber,
+				Position: row.Position,
+				Y:        row.Y,
+			},
+		}
+	}
+	return tickets
+}
+
+func MapGATier(model db.GaTier) entities.GATier {
+	return entities.GATier{
+		ID:       model.ID,
+		EventID:  model.EventID,
+		Name:     model.Name,
+		Price:    money.New(model.Price, model.Currency),
+		Capacity: model.Capacity,
+		Sold:     model.Sold,
+	}
+}
+
+func MapTicketType(model db.TicketType) entities.TicketType {
+	eligibility := model.Eligibility
+	if eligibility == nil {
+		eligibility = []string{}
+	}
+	return entities.TicketType{
+		ID:           model.ID,
+		EventID:      model.EventID,
+		Name:         model.Name,
+		Description:  model.Description.String,
+		Price:        money.New(model.Price, model.Currency),
+		Eligibility:  eligibility,
+		SaleStartsAt: model.SaleStartsAt.Time,
+		SaleEndsAt:   model.SaleEndsAt.Time,
+	}
+}
+
+func MapGetTicketReleasesRows(rows []db.GetTicketReleasesRow) []entities.TicketRelease {
+	releases := make([]entities.TicketRelease, 0)
+	for _, row := range rows {
+		// Rows are ordered by release, so a release's presales are contiguous.
+		if len(releases) == 0 || releases[len(releases)-1].ID != row.ID {
+			releases = append(releases, entities.TicketRelease{
+				ID:        row.ID,
+				EventID:   row.EventID,
+				OnSaleAt:  row.OnSaleAt.Time,
+				OffSaleAt: row.OffSaleAt.Time,
+				Presales:  []entities.Presale{},
+			})
+		}
+		if !row.PresaleID.Valid {
+			continue
+		}
+
+		release := &releases[len(releases)-1]
+		release.Presales = append(release.Presales, entities.Presale{
+			ID:           row.PresaleID.Int32,
+			Name:         row.PresaleName.String,
+			StartsAt:     row.PresaleStartsAt.Time,
+			EndsAt:       row.PresaleEndsAt.Time,
+			AccessCode:   row.PresaleAccessCode.String,
+			HasAllowlist: row.PresaleHasAllowlist,
+		})
+	}
+	return releases
+}
+
+func MapGetTicketsRows(rows []db.GetTicketsRow) []entities.Ticket {
+	tickets := make([]entities.Ticket, len(rows))
+	for idx, row := range rows {
+		tickets[idx] = MapTicket(row.Ticket)
+	}
+	return tickets
+}
+
+// MapGetVenueLayoutRows nests the seats of a venue's layout under their rows
+// and sections. Rows are expected to be grouped by section and then by row.
+func MapGetVenueLayoutRows(venueID int32, rows []db.GetVenueLayoutRow) entities.VenueLayout {
+	layout := entities.VenueLayout{VenueID: venueID, Sections: make([]entities.VenueSection, 0)}
+	for _, row := range rows {
+		sections := layout.Sections
+		if len(sections) == 0 || sections[len(sections)-1].ID != row.SectionID {
+			layout.Sections = append(layout.Sections, entities.VenueSection{
+				ID:   row.SectionID,
+				Name: row.SectionName,
+				Rows: make([]entities.VenueRow, 0),
+			})
+		}
+
+		section := &layout.Sections[len(layout.Sections)-1]
+		if len(section.Rows) == 0 || section.Rows[len(section.Rows)-1].ID != row.RowID {
+			section.Rows = append(section.Rows, entities.VenueRow{
+				ID:    row.RowID,
+				Name:  row.RowName,
+				Seats: make([]entities.VenueSeat, 0),
+			})
+		}
+
+		venueRow := &section.Rows[len(section.Rows)-1]
+		venueRow.Seats = append(venueRow.Seats, entities.VenueSeat{
+			ID:         row.VenueSeat.ID,
+			Number:     row.VenueSeat.Number,
+			X:          row.VenueSeat.X,
+			Y:          row.VenueSeat.Y,
+			Accessible: row.VenueSeat.Accessible,
+			Obstructed: row.VenueSeat.Obstructed,
+		})
+	}
+	return layout
+}
+
+// appendOrderRow adds the order item from a row of an order query to its
+// order, adding the order to `orders` if it isn't the last order added. Rows
+// are expected to be grouped by order.
+func appendOrderRow(
+	orders []entities.Order,
+	model db.Order,
+	itemTicketID pgtype.Int4,
+	itemPrice pgtype.Int8,
+	itemServiceFee pgtype.Int8,
+	itemTax pgtype.Int8,
+	itemDiscount pgtype.Int8,
+) []entities.Order {
+	if len(orders) == 0 || orders[len(orders)-1].ID != model.ID {
+		orders = append(orders, entities.Order{
+			ID:          model.ID,
+			PurchaserID: model.PurchaserID,
+			PaymentID:   model.PaymentID,
+			Status:      model.Status,
+			Total:       money.New(model.Total, model.Currency),
+			PromoCodeID: model.PromoCodeID.Int32,
+			Discount:    money.New(model.Discount, model.Currency),
+			FacilityFee: money.New(model.FacilityFee, model.Currency),
+			Tax:         money.New(model.Tax, model.Currency),
+			CreatedAt:   model.CreatedAt.Time,
+			UpdatedAt:   model.UpdatedAt.Time,
+			Items:       make([]entities.OrderItem, 0),
+		})
+	}
+
+	if itemTicketID.Valid {
+		order := &orders[len(orders)-1]
+		order.Items = append(order.Items, entities.OrderItem{
+			TicketID:   itemTicketID.Int32,
+			Price:      money.New(itemPrice.Int64, model.Currency),
+			Discount:   money.New(itemDiscount.Int64, model.Currency),
+			ServiceFee: money.New(itemServiceFee.Int64, model.Currency),
+			Tax:        money.New(itemTax.Int64, model.Currency),
+		})
+	}
+	return orders
+}
+
+func MapGetOrderRows(rows []db.GetOrderRow) entities.Order {
+	orders := make([]entities.Order, 0, 1)
+	for _, row := range rows {
+		orders = appendOrderRow(
+			orders,
+			row.Order,
+			row.ItemTicketID,
+			row.ItemPrice,
+			row.ItemServiceFee,
+			row.ItemTax,
+			row.ItemDiscount,
+		)
+	}
+
+	if len(orders) == 0 {
+		return entities.Order{}
+	}
+	return orders[0]
+}
+
+func MapGetUserOrdersRows(rows []db.GetUserOrdersRow) []entities.Order {
+	orders := make([]entities.Order, 0)
+	for _, row := range rows {
+		orders = appendOrderRow(
+			orders,
+			row.Order,
+			row.ItemTicketID,
+			row.ItemPrice,
+			row.ItemServiceFee,
+			row.ItemTax,
+			row.ItemDiscount,
+		)
+	}
+	return orders
+}
+
+func MapFeeRule(row db.FeeRule) entities.FeeRule {
+	return entities.FeeRule{
+		VenueID:        row.VenueID.Int32,
+		EventID:        row.EventID.Int32,
+		ServiceFeeRate: row.ServiceFeeRate,
+		ServiceFeeFlat: row.ServiceFeeFlat,
+		FacilityFee:    row.FacilityFee,
+	}
+}
+
+func MapWaitingRoom(row db.WaitingRoom) entities.WaitingRoom {
+	return entities.WaitingRoom{EventID: row.EventID, BatchSize: row.BatchSize}
+}
+
+func MapWaitlistEntry(row db.WaitlistEntry) entities.WaitlistEntry {
+	return entities.WaitlistEntry{
+		EventID:        row.EventID,
+		UserID:         row.UserID,
+		Quantity:       row.Quantity,
+		JoinedAt:       row.JoinedAt.Time,
+		OfferToken:     row.OfferToken.String,
+		OfferExpiresAt: row.OfferExpiresAt.Time,
+	}
+}
+
+// MapGetWaitlistEntryRow maps the entry, giving users that are still waiting
+// their position after the users ahead of them.
+func MapGetWaitlistEntryRow(row db.GetWaitlistEntryRow) entities.WaitlistEntry {
+	entry := MapWaitlistEntry(row.WaitlistEntry)
+	if entry.OfferToken == "" {
+		entry.Position = row.Ahead + 1
+	}
+	return entry
+}
+
+func MapGetPurchaseLimitsRows(rows []db.GetPurchaseLimitsRow) []entities.PurchaseLimit {
+	limits := make([]entities.PurchaseLimit, len(rows))
+	for idx, row := range rows {
+		limits[idx] = entities.PurchaseLimit{
+			EventID:           row.EventID,
+			MaxTicketsPerUser: row.MaxTicketsPerUser,
+			Purchased:         row.Purchased,
+		}
+	}
+	return limits
+}
+
+func MapPromoCode(row db.PromoCode) entities.PromoCode {
+	return entities.PromoCode{
+		ID:                    row.ID,
+		Code:                  row.Code,
+		DiscountType:          row.DiscountType,
+		PercentOff:            row.PercentOff,
+		AmountOff:             money.New(row.AmountOff, row.Currency.String),
+		EventID:               row.EventID.Int32,
+		StartsAt:              row.StartsAt.Time,
+		EndsAt:                row.EndsAt.Time,
+		MaxRedemptions:        row.MaxRedemptions.Int32,
+		MaxRedemptionsPerUser: row.MaxRedemptionsPerUser.Int32,
+		Redemptions:           row.Redemptions,
+	}
+}
+
+func MapPayment(row db.Payment) entities.Payment {
+	return entities.Payment{
+		ID:            row.ID,
+		PurchaserID:   row.PurchaserID,
+		Amount:        money.New(row.Amount, row.Currency),
+		Status:        row.Status,
+		Reference:     row.Reference.String,
+		DeclineReason: row.DeclineReason.String,
+	}
+}
+
+func MapGetTicketPurchaseRow(ticketID int32, row db.GetTicketPurchaseRow) entities.TicketPurchase {
+	return entities.TicketPurchase{
+		TicketID:         ticketID,
+		PurchaserID:      row.PurchaserID.Int32,
+		OrderPurchaserID: row.OrderPurchaserID,
+		OrderID:          row.OrderID,
+		Price:            money.New(row.Price, row.Currency),
+		PaymentID:        row.PaymentID,
+		PaymentReference: row.PaymentReference.String,
+	}
+}
+
+func MapGetStalePendingRefundsRow(row db.GetStalePendingRefundsRow) entities.Refund {
+	return entities.Refund{
+		ID:               row.ID,
+		PaymentID:        row.PaymentID,
+		TicketID:         row.TicketID,
+		Amount:           money.New(row.Amount, row.Currency),
+		Status:           entities.RefundStatusPending,
+		PaymentReference: row.PaymentReference.String,
+	}
+}
+
+func MapCancellationRefund(row db.CancellationRefund) entities.CancellationRefund {
+	return entities.CancellationRefund{
+		ID:             row.ID,
+		CancellationID: row.CancellationID,
+		TicketID:       row.TicketID,
+		Status:         row.Status,
+		Attempts:       row.Attempts,
+		LastError:      row.LastError.String,
+		RefundID:       row.RefundID.Int32,
+	}
+}
+
+func MapUpdateCancellationRefundParams(refund entities.CancellationRefund) db.UpdateCancellationRefundParams {
+	return db.UpdateCancellationRefundParams{
+		Status:               refund.Status,
+		Attempts:             refund.Attempts,
+		LastError:            MapNullableString(refund.LastError),
+		RefundID:             MapNullableID(refund.RefundID),
+		CancellationRefundID: refund.ID,
+	}
+}
+
+func MapGetEventCancellationRow(row db.GetEventCancellationRow) entities.EventCancellation {
+	return entities.EventCancellation{
+		ID:            row.EventCancellation.ID,
+		EventID:       row.EventCancellation.EventID,
+		Status:        row.EventCancellation.Status,
+		CreatedAt:     row.EventCancellation.CreatedAt.Time,
+		UpdatedAt:     row.EventCancellation.UpdatedAt.Time,
+		TotalCount:    row.TotalCount,
+		PendingCount:  row.PendingCount,
+		RefundedCount: row.RefundedCount,
+		FailedCount:   row.FailedCount,
+		SkippedCount:  row.SkippedCount,
+	}
+}
+
+func MapIdempotencyKey(row db.IdempotencyKey) entities.IdempotencyKey {
+	return entities.IdempotencyKey{
+		ID:           row.ID,
+		UserID:       row.UserID,
+		Key:          row.Key,
+		Fingerprint:  row.Fingerprint,
+		StatusCode:   int(row.StatusCode.Int32),
+		ContentType:  row.ContentType.String,
+		ResponseBody: row.ResponseBody,
+	}
+}
+
+func MapTicketTransfer(row db.TicketTransfer) entities.TicketTransfer {
+	return entities.TicketTransfer{
+		ID:          row.ID,
+		TicketID:    row.TicketID,
+		SenderID:    row.SenderID,
+		RecipientID: row.RecipientID,
+		Status:      row.Status,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+}
+
+func MapTicketTransfers(rows []db.TicketTransfer) []entities.TicketTransfer {
+	transfers := make([]entities.TicketTransfer, len(rows))
+	for idx, row := range rows {
+		transfers[idx] = MapTicketTransfer(row)
+	}
+	return transfers
+}
+
+func MapGetTicketOwnershipRow(row db.GetTicketOwnershipRow) entities.TicketOwnership {
+	return entities.TicketOwnership{
+		TicketID:      row.TicketID,
+		OwnerID:       row.PurchaserID.Int32,
+		EventStartsAt: row.StartsAt.Time,
+	}
+}
+
+func MapResaleListing(row db.ResaleListing) entities.ResaleListing {
+	return entities.ResaleListing{
+		ID:        row.ID,
+		TicketID:  row.TicketID,
+		SellerID:  row.SellerID,
+		Price:     money.New(row.Price, row.Currency),
+		Status:    row.Status,
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+}
+
+func MapResaleListings(rows []db.ResaleListing) []entities.ResaleListing {
+	listings := make([]entities.ResaleListing, len(rows))
+	for idx, row := range rows {
+		listings[idx] = MapResaleListing(row)
+	}
+	return listings
+}
+
+func MapGetEventResaleListingsRows(rows []db.GetEventResaleListingsRow) []entities.AvailableResaleListing {
+	listings := make([]entities.AvailableResaleListing, len(rows))
+	for idx, row := range rows {
+		listings[idx] = entities.AvailableResaleListing{
+			ID:        row.ResaleListing.ID,
+			TicketID:  row.ResaleListing.TicketID,
+			Seat:      row.Seat,
+			Price:     money.New(row.ResaleListing.Price, row.ResaleListing.Currency),
+			FaceValue: money.New(row.FaceValue, row.ResaleListing.Currency),
+		}
+	}
+	return listings
+}
+
+func MapGetTicketResaleTermsRow(row db.GetTicketResaleTermsRow) entities.TicketResaleTerms {
+	return entities.TicketResaleTerms{
+		Ticket:        MapTicket(row.Ticket),
+		EventStartsAt: row.StartsAt.Time,
+		MinRate:       row.ResaleMinRate.Int32,
+		MaxRate:       row.ResaleMaxRate.Int32,
+	}
+}
+
+func MapGetTicketCredentialRow(row db.GetTicketCredentialRow) entities.TicketCredential {
+	return entities.TicketCredential{
+		TicketID:  row.Ticket.ID,
+		EventID:   row.Ticket.EventID,
+		OwnerID:   row.Ticket.PurchaserID.Int32,
+		Nonce:     row.Nonce.String,
+		RotatedAt: row.RotatedAt.Time,
+	}
+}
+
+func MapCheckin(row db.Checkin) entities.Checkin {
+	return entities.Checkin{
+		ID:        row.ID,
+		TicketID:  row.TicketID,
+		EventID:   row.EventID,
+		OwnerID:   row.OwnerID,
+		Gate:      row.Gate,
+		Device:    row.Device,
+		ScannedAt: row.ScannedAt.Time,
+	}
+}
+
+func MapGetTicketAdmissionRow(row db.GetTicketAdmissionRow) entities.TicketAdmission {
+	return entities.TicketAdmission{
+		TicketID:  row.TicketID,
+		EventID:   row.EventID,
+		OwnerID:   row.PurchaserID.Int32,
+		Nonce:     row.Nonce.String,
+		CheckedIn: row.ScannedAt.Valid,
+	}
+}
+
+func MapGetScannerTicketsRows(rows []db.GetScannerTicketsRow) []entities.ScannerTicket {
+	tickets := make([]entities.ScannerTicket, len(rows))
+	for idx, row := range rows {
+		tickets[idx] = entities.ScannerTicket{
+			TicketID:  row.TicketID,
+			OwnerID:   row.PurchaserID.Int32,
+			Nonce:     row.Nonce.String,
+			CheckedIn: row.ScannedAt.Valid,
+		}
+	}
+	return tickets
+}
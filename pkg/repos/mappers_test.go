@@ -6,6 +6,7 @@ import (
 
 	"github.com/dslaw/book-tickets/pkg/db"
 	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
 	"github.com/dslaw/book-tickets/pkg/repos"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
@@ -141,7 +142,8 @@ func TestMapTicket(t *testing.T) {
 		ID:          int32(1),
 		EventID:     int32(11),
 		PurchaserID: pgtype.Int4{Int32: 0, Valid: false},
-		Price:       int32(25),
+		Price:       25,
+		Currency:    "USD",
 		Seat:        "GA",
 	}
 	expected := entities.Ticket{
@@ -149,7 +151,7 @@ func TestMapTicket(t *testing.T) {
 		EventID:     int32(11),
 		PurchaserID: int32(0),
 		IsPurchased: false,
-		Price:       uint8(25),
+		Price:       money.New(25, "USD"),
 		Seat:        "GA",
 	}
 
@@ -159,14 +161,14 @@ func TestMapTicket(t *testing.T) {
 
 func TestMapGetAvailableTicketRows(t *testing.T) {
 	rows := []db.GetAvailableTicketsRow{
-		{Ticket: db.Ticket{ID: 1, EventID: 1, PurchaserID: pgtype.Int4{Int32: 1, Valid: true}, Price: 10, Seat: "GA"}},
-		{Ticket: db.Ticket{ID: 2, EventID: 1, PurchaserID: pgtype.Int4{Int32: 0, Valid: false}, Price: 10, Seat: "GA"}},
-		{Ticket: db.Ticket{ID: 3, EventID: 1, PurchaserID: pgtype.Int4{Int32: 0, Valid: false}, Price: 20, Seat: "Balcony"}},
+		{Ticket: db.Ticket{ID: 1, EventID: 1, PurchaserID: pgtype.Int4{Int32: 1, Valid: true}, Price: 10, Currency: "USD", Seat: "GA"}},
+		{Ticket: db.Ticket{ID: 2, EventID: 1, PurchaserID: pgtype.Int4{Int32: 0, Valid: false}, Price: 10, Currency: "USD", Seat: "GA"}},
+		{Ticket: db.Ticket{ID: 3, EventID: 1, PurchaserID: pgtype.Int4{Int32: 0, Valid: false}, Price: 20, Currency: "USD", Seat: "Balcony"}},
 	}
 	expected := []entities.Ticket{
-		{ID: 1, EventID: 1, PurchaserID: 1, IsPurchased: true, Price: uint8(10), Seat: "GA"},
-		{ID: 2, EventID: 1, PurchaserID: 0, IsPurchased: false, Price: uint8(10), Seat: "GA"},
-		{ID: 3, EventID: 1, PurchaserID: 0, IsPurchased: false, Price: uint8(20), Seat: "Balcony"},
+		{ID: 1, EventID: 1, PurchaserID: 1, IsPurchased: true, Price: money.New(10, "USD"), Seat: "GA"},
+		{ID: 2, EventID: 1, PurchaserID: 0, IsPurchased: false, Price: money.New(10, "USD"), Seat: "GA"},
+		{ID: 3, EventID: 1, PurchaserID: 0, IsPurchased: false, Price: money.New(20, "USD"), Seat: "Balcony"},
 	}
 
 	actual := repos.MapGetAvailableTicketRows(rows)
@@ -178,3 +180,193 @@ func TestMapGetAvailableTicketRowsWhenEmptyResultSet(t *testing.T) {
 	actual := repos.MapGetAvailableTicketRows(rows)
 	assert.Empty(t, actual)
 }
+
+func TestMapGetAvailableSeatedTicketsRows(t *testing.T) {
+	rows := []db.GetAvailableSeatedTicketsRow{
+		{
+			Ticket:      db.Ticket{ID: 1, EventID: 1, Price: 10, Currency: "USD", Seat: "Orchestra", VenueSeatID: pgtype.Int4{Int32: 5, Valid: true}},
+			SectionName: "Orchestra",
+			RowID:       2,
+			RowName:     "A",
+			SeatNumber:  "101",
+			Position:    1,
+			Y:           3.5,
+		},
+	}
+	expected := []entities.SeatedTicket{
+		{
+			Ticket: entities.Ticket{ID: 1, EventID: 1, Price: money.New(10, "USD"), Seat: "Orchestra", VenueSeatID: 5},
+			Position: entities.SeatPosition{
+				Section:  "Orchestra",
+				RowID:    2,
+				Row:      "A",
+				Number:   "101",
+				Position: 1,
+				Y:        3.5,
+			},
+		},
+	}
+
+	actual := repos.MapGetAvailableSeatedTicketsRows(rows)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapGetTicketReleasesRows(t *testing.T) {
+	onSaleAt := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	startsAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []db.GetTicketReleasesRow{
+		{
+			ID:                1,
+			EventID:           1,
+			OnSaleAt:          pgtype.Timestamptz{Time: onSaleAt, Valid: true},
+			PresaleID:         pgtype.Int4{Int32: 1, Valid: true},
+			PresaleName:       pgtype.Text{String: "Fan Club", Valid: true},
+			PresaleStartsAt:   pgtype.Timestamptz{Time: startsAt, Valid: true},
+			PresaleEndsAt:     pgtype.Timestamptz{Time: onSaleAt, Valid: true},
+			PresaleAccessCode: pgtype.Text{String: "FANS", Valid: true},
+		},
+		{
+			ID:                  1,
+			EventID:             1,
+			OnSaleAt:            pgtype.Timestamptz{Time: onSaleAt, Valid: true},
+			PresaleID:           pgtype.Int4{Int32: 2, Valid: true},
+			PresaleName:         pgtype.Text{String: "Members", Valid: true},
+			PresaleStartsAt:     pgtype.Timestamptz{Time: startsAt, Valid: true},
+			PresaleEndsAt:       pgtype.Timestamptz{Time: onSaleAt, Valid: true},
+			PresaleHasAllowlist: true,
+		},
+		{ID: 2, EventID: 1, OnSaleAt: pgtype.Timestamptz{Time: onSaleAt, Valid: true}},
+	}
+	expected := []entities.TicketRelease{
+		{
+			ID:       1,
+			EventID:  1,
+			OnSaleAt: onSaleAt,
+			Presales: []entities.Presale{
+				{ID: 1, Name: "Fan Club", StartsAt: startsAt, EndsAt: onSaleAt, AccessCode: "FANS"},
+				{ID: 2, Name: "Members", StartsAt: startsAt, EndsAt: onSaleAt, HasAllowlist: true},
+			},
+		},
+		{ID: 2, EventID: 1, OnSaleAt: onSaleAt, Presales: []entities.Presale{}},
+	}
+
+	actual := repos.MapGetTicketReleasesRows(rows)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMapGetVenueLayoutRows(t *testing.T) {
+	rows := []db.GetVenueLayoutRow{
+		{SectionID: 1, SectionName: "Orchestra", RowID: 1, RowName: "A", VenueSeat: db.VenueSeat{ID: 1, RowID: 1, Number: "1", X: 0, Y: 0, Accessible: true}},
+		{SectionID: 1, SectionName: "Orchestra", RowID: 1, RowName: "A", VenueSeat: db.VenueSeat{ID: 2, RowID: 1, Number: "2", X: 1, Y: 0}},
+		{SectionID: 1, SectionName: "Orchestra", RowID: 2, RowName: "B", VenueSeat: db.VenueSeat{ID: 3, RowID: 2, Number: "1", X: 0, Y: 1}},
+		{SectionID: 2, SectionName: "Balcony", RowID: 3, RowName: "A", VenueSeat: db.VenueSeat{ID: 4, RowID: 3, Number: "1", X: 0, Y: 5, Obstructed: true}},
+	}
+	expected := entities.VenueLayout{
+		VenueID: 1,
+		Sections: []entities.VenueSection{
+			{ID: 1, Name: "Orchestra", Rows: []entities.VenueRow{
+				{ID: 1, Name: "A", Seats: []entities.VenueSeat{
+					{ID: 1, Number: "1", X: 0, Y: 0, Accessible: true},
+					{ID: 2, Number: "2", X: 1, Y: 0},
+				}},
+				{ID: 2, Name: "B", Seats: []entities.VenueSeat{{ID: 3, Number: "1", X: 0, Y: 1}}},
+			}},
+			{ID: 2, Name: "Balcony", Rows: []entities.VenueRow{
+				{ID: 3, Name: "A", Seats: []entities.VenueSeat{{ID: 4, Number: "1", X: 0, Y: 5, Obstructed: true}}},
+			}},
+		},
+	}
+
+	actual := repos.MapGetVenueLayoutRows(1, rows)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapGetUserOrdersRows(t *testing.T) {
+	createdAt, _ := time.Parse(time.DateOnly, "2020-01-01")
+	order1 := db.Order{
+		ID:        2,
+		Status:    "completed",
+		Total:     10,
+		Currency:  "USD",
+		CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+		UpdatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+	}
+	order2 := db.Order{
+		ID:        1,
+		Status:    "completed",
+		Total:     30,
+		Currency:  "USD",
+		CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+		UpdatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+	}
+	rows := []db.GetUserOrdersRow{
+		{Order: order1, ItemTicketID: pgtype.Int4{Int32: 3, Valid: true}, ItemPrice: pgtype.Int8{Int64: 10, Valid: true}},
+		{Order: order2, ItemTicketID: pgtype.Int4{Int32: 1, Valid: true}, ItemPrice: pgtype.Int8{Int64: 10, Valid: true}},
+		{Order: order2, ItemTicketID: pgtype.Int4{Int32: 2, Valid: true}, ItemPrice: pgtype.Int8{Int64: 20, Valid: true}},
+	}
+	noFee := money.New(0, "USD")
+	expected := []entities.Order{
+		{
+			ID:          2,
+			Status:      "completed",
+			Total:       money.New(10, "USD"),
+			Discount:    noFee,
+			FacilityFee: noFee,
+			Tax:         noFee,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+			Items: []entities.OrderItem{
+				{TicketID: 3, Price: money.New(10, "USD"), Discount: noFee, ServiceFee: noFee, Tax: noFee},
+			},
+		},
+		{
+			ID:          1,
+			Status:      "completed",
+			Total:       money.New(30, "USD"),
+			Discount:    noFee,
+			FacilityFee: noFee,
+			Tax:         noFee,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+			Items: []entities.OrderItem{
+				{TicketID: 1, Price: money.New(10, "USD"), Discount: noFee, ServiceFee: noFee, Tax: noFee},
+				{TicketID: 2, Price: money.New(20, "USD"), Discount: noFee, ServiceFee: noFee, Tax: noFee},
+			},
+		},
+	}
+
+	actual := repos.MapGetUserOrdersRows(rows)
+	assert.EqualValues(t, expected, actual)
+}
+
+func TestMapGetUserOrdersRowsWhenEmptyResultSet(t *testing.T) {
+	rows := []db.GetUserOrdersRow{}
+	actual := repos.MapGetUserOrdersRows(rows)
+	assert.Empty(t, actual)
+}
+
+func TestMapPromoCode(t *testing.T) {
+	startsAt, _ := time.Parse(time.DateOnly, "2020-01-01")
+	row := db.PromoCode{
+		ID:                    1,
+		Code:                  "SUMMER25",
+		DiscountType:          "percentage",
+		PercentOff:            2500,
+		StartsAt:              pgtype.Timestamptz{Time: startsAt, Valid: true},
+		MaxRedemptionsPerUser: pgtype.Int4{Int32: 1, Valid: true},
+		Redemptions:           3,
+	}
+	expected := entities.PromoCode{
+		ID:                    1,
+		Code:                  "SUMMER25",
+		DiscountType:          entities.DiscountTypePercentage,
+		PercentOff:            2500,
+		AmountOff:             money.New(0, ""),
+		StartsAt:              startsAt,
+		MaxRedemptionsPerUser: 1,
+		Redemptions:           3,
+	}
+
+	actual := repos.MapPromoCode(row)
+	assert.Equal(t, expected, actual)
+}
@@ -11,11 +11,111 @@ type MockQuerier struct {
 	mock.Mock
 }
 
+func (mock *MockQuerier) AddPresaleUsers(ctx context.Context, params db.AddPresaleUsersParams) error {
+	args := mock.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) ClaimIdempotencyKey(ctx context.Context, params db.ClaimIdempotencyKeyParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) ClearTicketPurchaser(ctx context.Context, params db.ClearTicketPurchaserParams) (int64, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) CompleteEventCancellations(ctx context.Context) (int64, error) {
+	args := mock.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) CompleteIdempotencyKey(ctx context.Context, params db.CompleteIdempotencyKeyParams) error {
+	args := mock.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) CompleteRefund(ctx context.Context, params db.CompleteRefundParams) (int64, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateCheckin(ctx context.Context, params db.CreateCheckinParams) (db.Checkin, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.Checkin), args.Error(1)
+}
+
 func (mock *MockQuerier) CreateEvent(ctx context.Context, params db.CreateEventParams) (int32, error) {
 	args := mock.Called(ctx, params)
 	return args.Get(0).(int32), args.Error(1)
 }
 
+func (mock *MockQuerier) CreateGaTier(ctx context.Context, arg db.CreateGaTierParams) (int32, error) {
+	args := mock.Called(ctx, arg)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateOfflineCheckin(ctx context.Context, params db.CreateOfflineCheckinParams) (db.Checkin, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.Checkin), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateOrder(ctx context.Context, params db.CreateOrderParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) CreatePayment(ctx context.Context, params db.CreatePaymentParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) CreatePresale(ctx context.Context, params db.CreatePresaleParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) CreatePromoCode(ctx context.Context, params db.CreatePromoCodeParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateRefund(ctx context.Context, params db.CreateRefundParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateResaleListing(ctx context.Context, params db.CreateResaleListingParams) (db.ResaleListing, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.ResaleListing), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateResalePayout(ctx context.Context, params db.CreateResalePayoutParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateTicketCredential(ctx context.Context, params db.CreateTicketCredentialParams) error {
+	args := mock.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) CreateTicketRelease(ctx context.Context, params db.CreateTicketReleaseParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateTicketTransfer(ctx context.Context, params db.CreateTicketTransferParams) (db.TicketTransfer, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockQuerier) CreateTicketType(ctx context.Context, arg db.CreateTicketTypeParams) (int32, error) {
+	args := mock.Called(ctx, arg)
+	return args.Get(0).(int32), args.Error(1)
+}
+
 func (mock *MockQuerier) CreateVenue(ctx context.Context, params db.CreateVenueParams) (int32, error) {
 	args := mock.Called(ctx, params)
 	return args.Get(0).(int32), args.Error(1)
@@ -26,11 +126,66 @@ func (mock *MockQuerier) DeleteEvent(ctx context.Context, id int32) (int64, erro
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (mock *MockQuerier) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	args := mock.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) DeleteIdempotencyKey(ctx context.Context, id int32) error {
+	args := mock.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) DeletePromoCode(ctx context.Context, promoCodeID int32) (int64, error) {
+	args := mock.Called(ctx, promoCodeID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (mock *MockQuerier) DeleteVenue(ctx context.Context, id int32) (int64, error) {
 	args := mock.Called(ctx, id)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (mock *MockQuerier) DeleteVenueLayout(ctx context.Context, venueID int32) (int64, error) {
+	args := mock.Called(ctx, venueID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) DeleteWaitingRoom(ctx context.Context, eventID int32) (int64, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) DeleteWaitlistEntry(ctx context.Context, params db.DeleteWaitlistEntryParams) (int64, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) EventExists(ctx context.Context, eventID int32) (bool, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(bool), args.Error(1)
+}
+
+func (mock *MockQuerier) FailRefund(ctx context.Context, refundID int32) (int64, error) {
+	args := mock.Called(ctx, refundID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) GetActiveResaleListing(ctx context.Context, ticketID int32) (db.ResaleListing, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(db.ResaleListing), args.Error(1)
+}
+
+func (mock *MockQuerier) GetApplicableFeeRule(ctx context.Context, eventID int32) (db.FeeRule, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(db.FeeRule), args.Error(1)
+}
+
+func (mock *MockQuerier) GetAvailableSeatedTickets(ctx context.Context, eventID int32) ([]db.GetAvailableSeatedTicketsRow, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]db.GetAvailableSeatedTicketsRow), args.Error(1)
+}
+
 func (mock *MockQuerier) GetAvailableTickets(ctx context.Context, eventID int32) ([]db.GetAvailableTicketsRow, error) {
 	args := mock.Called(ctx, eventID)
 	return args.Get(0).([]db.GetAvailableTicketsRow), args.Error(1)
@@ -41,16 +196,257 @@ func (mock *MockQuerier) GetEvent(ctx context.Context, id int32) ([]db.GetEventR
 	return args.Get(0).([]db.GetEventRow), args.Error(1)
 }
 
+func (mock *MockQuerier) GetEventAttendance(ctx context.Context, eventID int32) (db.GetEventAttendanceRow, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(db.GetEventAttendanceRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetEventCancellation(ctx context.Context, eventID int32) (db.GetEventCancellationRow, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(db.GetEventCancellationRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetEventCurrencies(ctx context.Context, eventID int32) ([]string, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (mock *MockQuerier) GetEventFeeRule(ctx context.Context, eventID int32) (db.FeeRule, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(db.FeeRule), args.Error(1)
+}
+
+func (mock *MockQuerier) GetEventGaTiers(ctx context.Context, eventID int32) ([]db.GaTier, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]db.GaTier), args.Error(1)
+}
+
+func (mock *MockQuerier) GetEventResaleListings(ctx context.Context, eventID int32) ([]db.GetEventResaleListingsRow, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]db.GetEventResaleListingsRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetEventTaxRate(ctx context.Context, eventID int32) (int32, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) GetEventTicketTypes(ctx context.Context, eventID int32) ([]db.TicketType, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]db.TicketType), args.Error(1)
+}
+
+func (mock *MockQuerier) GetEventVenueSeats(ctx context.Context, params db.GetEventVenueSeatsParams) ([]db.GetEventVenueSeatsRow, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]db.GetEventVenueSeatsRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetExceededPurchaseLimits(ctx context.Context, params db.GetExceededPurchaseLimitsParams) ([]int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (mock *MockQuerier) GetGaTier(ctx context.Context, gaTierID int32) (db.GaTier, error) {
+	args := mock.Called(ctx, gaTierID)
+	return args.Get(0).(db.GaTier), args.Error(1)
+}
+
+func (mock *MockQuerier) GetIdempotencyKey(ctx context.Context, params db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.IdempotencyKey), args.Error(1)
+}
+
+func (mock *MockQuerier) GetOrder(ctx context.Context, id int32) ([]db.GetOrderRow, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).([]db.GetOrderRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetPendingCancellationRefunds(ctx context.Context, maxRefunds int32) ([]db.CancellationRefund, error) {
+	args := mock.Called(ctx, maxRefunds)
+	return args.Get(0).([]db.CancellationRefund), args.Error(1)
+}
+
+func (mock *MockQuerier) GetPendingTicketTransfer(ctx context.Context, ticketID int32) (db.TicketTransfer, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(db.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockQuerier) GetPromoCode(ctx context.Context, promoCodeID int32) (db.PromoCode, error) {
+	args := mock.Called(ctx, promoCodeID)
+	return args.Get(0).(db.PromoCode), args.Error(1)
+}
+
+func (mock *MockQuerier) GetPromoCodeByCode(ctx context.Context, code string) (db.PromoCode, error) {
+	args := mock.Called(ctx, code)
+	return args.Get(0).(db.PromoCode), args.Error(1)
+}
+
+func (mock *MockQuerier) GetPurchaseLimits(ctx context.Context, params db.GetPurchaseLimitsParams) ([]db.GetPurchaseLimitsRow, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]db.GetPurchaseLimitsRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetResaleListing(ctx context.Context, id int32) (db.ResaleListing, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(db.ResaleListing), args.Error(1)
+}
+
+func (mock *MockQuerier) GetRevokedTickets(ctx context.Context, eventID int32) ([]int32, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (mock *MockQuerier) GetScannerTickets(ctx context.Context, eventID int32) ([]db.GetScannerTicketsRow, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]db.GetScannerTicketsRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetSellerResaleListings(ctx context.Context, sellerID int32) ([]db.ResaleListing, error) {
+	args := mock.Called(ctx, sellerID)
+	return args.Get(0).([]db.ResaleListing), args.Error(1)
+}
+
+func (mock *MockQuerier) GetStaleAuthorizedPayments(ctx context.Context, params db.GetStaleAuthorizedPaymentsParams) ([]db.Payment, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]db.Payment), args.Error(1)
+}
+
+func (mock *MockQuerier) GetStalePendingRefunds(
+	ctx context.Context,
+	params db.GetStalePendingRefundsParams,
+) ([]db.GetStalePendingRefundsRow, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]db.GetStalePendingRefundsRow), args.Error(1)
+}
+
 func (mock *MockQuerier) GetTicket(ctx context.Context, id int32) (db.GetTicketRow, error) {
 	args := mock.Called(ctx, id)
 	return args.Get(0).(db.GetTicketRow), args.Error(1)
 }
 
+func (mock *MockQuerier) GetTicketAdmission(ctx context.Context, ticketID int32) (db.GetTicketAdmissionRow, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(db.GetTicketAdmissionRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTicketCredential(ctx context.Context, ticketID int32) (db.GetTicketCredentialRow, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(db.GetTicketCredentialRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTicketOwnership(ctx context.Context, ticketID int32) (db.GetTicketOwnershipRow, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(db.GetTicketOwnershipRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTicketPurchase(ctx context.Context, id int32) (db.GetTicketPurchaseRow, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(db.GetTicketPurchaseRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTicketReleases(ctx context.Context, ids []int32) ([]db.GetTicketReleasesRow, error) {
+	args := mock.Called(ctx, ids)
+	return args.Get(0).([]db.GetTicketReleasesRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTicketResaleTerms(ctx context.Context, ticketID int32) (db.GetTicketResaleTermsRow, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(db.GetTicketResaleTermsRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTicketTransfer(ctx context.Context, id int32) (db.TicketTransfer, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(db.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTicketTransfers(ctx context.Context, ticketID int32) ([]db.TicketTransfer, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).([]db.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTicketTypes(ctx context.Context, ids []int32) ([]db.TicketType, error) {
+	args := mock.Called(ctx, ids)
+	return args.Get(0).([]db.TicketType), args.Error(1)
+}
+
+func (mock *MockQuerier) GetTickets(ctx context.Context, ids []int32) ([]db.GetTicketsRow, error) {
+	args := mock.Called(ctx, ids)
+	return args.Get(0).([]db.GetTicketsRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetUnrefundedFacilityFee(ctx context.Context, orderID int32) (db.GetUnrefundedFacilityFeeRow, error) {
+	args := mock.Called(ctx, orderID)
+	return args.Get(0).(db.GetUnrefundedFacilityFeeRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetUserOrders(ctx context.Context, purchaserID int32) ([]db.GetUserOrdersRow, error) {
+	args := mock.Called(ctx, purchaserID)
+	return args.Get(0).([]db.GetUserOrdersRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetUserPromoCodeRedemptions(
+	ctx context.Context,
+	params db.GetUserPromoCodeRedemptionsParams,
+) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) GetUserTicketTransfers(ctx context.Context, userID int32) ([]db.TicketTransfer, error) {
+	args := mock.Called(ctx, userID)
+	return args.Get(0).([]db.TicketTransfer), args.Error(1)
+}
+
 func (mock *MockQuerier) GetVenue(ctx context.Context, venueID int32) (db.GetVenueRow, error) {
 	args := mock.Called(ctx, venueID)
 	return args.Get(0).(db.GetVenueRow), args.Error(1)
 }
 
+func (mock *MockQuerier) GetVenueFeeRule(ctx context.Context, venueID int32) (db.FeeRule, error) {
+	args := mock.Called(ctx, venueID)
+	return args.Get(0).(db.FeeRule), args.Error(1)
+}
+
+func (mock *MockQuerier) GetVenueLayout(ctx context.Context, venueID int32) ([]db.GetVenueLayoutRow, error) {
+	args := mock.Called(ctx, venueID)
+	return args.Get(0).([]db.GetVenueLayoutRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetWaitingEntries(ctx context.Context, params db.GetWaitingEntriesParams) ([]db.WaitlistEntry, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]db.WaitlistEntry), args.Error(1)
+}
+
+func (mock *MockQuerier) GetWaitingRoom(ctx context.Context, eventID int32) (db.WaitingRoom, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(db.WaitingRoom), args.Error(1)
+}
+
+func (mock *MockQuerier) GetWaitingRooms(ctx context.Context) ([]db.WaitingRoom, error) {
+	args := mock.Called(ctx)
+	return args.Get(0).([]db.WaitingRoom), args.Error(1)
+}
+
+func (mock *MockQuerier) GetWaitlistEntry(ctx context.Context, params db.GetWaitlistEntryParams) (db.GetWaitlistEntryRow, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.GetWaitlistEntryRow), args.Error(1)
+}
+
+func (mock *MockQuerier) GetWaitlistedEvents(ctx context.Context) ([]int32, error) {
+	args := mock.Called(ctx)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (mock *MockQuerier) IsPresaleUser(ctx context.Context, params db.IsPresaleUserParams) (bool, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(bool), args.Error(1)
+}
+
+func (mock *MockQuerier) IssueGaTickets(ctx context.Context, arg db.IssueGaTicketsParams) ([]int32, error) {
+	args := mock.Called(ctx, arg)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
 func (mock *MockQuerier) LinkPerformers(ctx context.Context, params []db.LinkPerformersParams) *db.LinkPerformersBatchResults {
 	args := mock.Called(ctx, params)
 	return args.Get(0).(*db.LinkPerformersBatchResults)
@@ -61,31 +457,173 @@ func (mock *MockQuerier) LinkUpdatedPerformers(ctx context.Context, params db.Li
 	return args.Error(0)
 }
 
+func (mock *MockQuerier) LockPurchaser(ctx context.Context, purchaserID int32) error {
+	args := mock.Called(ctx, purchaserID)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) RedeemPromoCode(ctx context.Context, promoCodeID int32) (int64, error) {
+	args := mock.Called(ctx, promoCodeID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) RedeemPromoCodeForUser(ctx context.Context, params db.RedeemPromoCodeForUserParams) (int64, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) ReturnGaTickets(ctx context.Context, ticketIds []int32) (int64, error) {
+	args := mock.Called(ctx, ticketIds)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) RotateTicketCredential(
+	ctx context.Context,
+	params db.RotateTicketCredentialParams,
+) (db.TicketCredential, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.TicketCredential), args.Error(1)
+}
+
+func (mock *MockQuerier) SetPaymentRefunded(ctx context.Context, id int32) (int64, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (mock *MockQuerier) SetTicketPurchaser(ctx context.Context, params db.SetTicketPurchaserParams) (int32, error) {
 	args := mock.Called(ctx, params)
 	return args.Get(0).(int32), args.Error(1)
 }
 
+func (mock *MockQuerier) SetTicketsPurchaser(ctx context.Context, params db.SetTicketsPurchaserParams) (int64, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) SetWaitlistOffer(ctx context.Context, params db.SetWaitlistOfferParams) (int64, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) TransferTicket(ctx context.Context, params db.TransferTicketParams) (int64, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (mock *MockQuerier) TrimUpdatedEventPerformers(ctx context.Context, id int32) error {
 	args := mock.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (mock *MockQuerier) TrimVenueRows(ctx context.Context, params db.TrimVenueRowsParams) error {
+	args := mock.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) TrimVenueSeats(ctx context.Context, params db.TrimVenueSeatsParams) error {
+	args := mock.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) TrimVenueSections(ctx context.Context, params db.TrimVenueSectionsParams) error {
+	args := mock.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) UpdateCancellationRefund(ctx context.Context, params db.UpdateCancellationRefundParams) error {
+	args := mock.Called(ctx, params)
+	return args.Error(0)
+}
+
 func (mock *MockQuerier) UpdateEvent(ctx context.Context, params db.UpdateEventParams) (int32, error) {
 	args := mock.Called(ctx, params)
 	return args.Get(0).(int32), args.Error(1)
 }
 
+func (mock *MockQuerier) UpdatePaymentStatus(ctx context.Context, params db.UpdatePaymentStatusParams) (int64, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockQuerier) UpdatePromoCode(ctx context.Context, params db.UpdatePromoCodeParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) UpdateResaleListingStatus(
+	ctx context.Context,
+	params db.UpdateResaleListingStatusParams,
+) (db.ResaleListing, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.ResaleListing), args.Error(1)
+}
+
+func (mock *MockQuerier) UpdateTicketTransferStatus(
+	ctx context.Context,
+	params db.UpdateTicketTransferStatusParams,
+) (db.TicketTransfer, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(db.TicketTransfer), args.Error(1)
+}
+
 func (mock *MockQuerier) UpdateVenue(ctx context.Context, params db.UpdateVenueParams) (int32, error) {
 	args := mock.Called(ctx, params)
 	return args.Get(0).(int32), args.Error(1)
 }
 
+func (mock *MockQuerier) UpsertEventFeeRule(ctx context.Context, params db.UpsertEventFeeRuleParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) UpsertTaxRate(ctx context.Context, params db.UpsertTaxRateParams) error {
+	args := mock.Called(ctx, params)
+	return args.Error(0)
+}
+
+func (mock *MockQuerier) UpsertVenueFeeRule(ctx context.Context, params db.UpsertVenueFeeRuleParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) UpsertVenueRows(ctx context.Context, params db.UpsertVenueRowsParams) ([]db.VenueRow, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]db.VenueRow), args.Error(1)
+}
+
+func (mock *MockQuerier) UpsertVenueSeats(ctx context.Context, params db.UpsertVenueSeatsParams) ([]int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (mock *MockQuerier) UpsertVenueSections(
+	ctx context.Context,
+	params db.UpsertVenueSectionsParams,
+) ([]db.UpsertVenueSectionsRow, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).([]db.UpsertVenueSectionsRow), args.Error(1)
+}
+
+func (mock *MockQuerier) UpsertWaitingRoom(ctx context.Context, params db.UpsertWaitingRoomParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockQuerier) UpsertWaitlistEntry(ctx context.Context, params db.UpsertWaitlistEntryParams) (int32, error) {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(int32), args.Error(1)
+}
+
 func (mock *MockQuerier) WriteNewTickets(ctx context.Context, params []db.WriteNewTicketsParams) *db.WriteNewTicketsBatchResults {
 	args := mock.Called(ctx, params)
 	return args.Get(0).(*db.WriteNewTicketsBatchResults)
 }
 
+func (mock *MockQuerier) WriteOrderItems(ctx context.Context, params []db.WriteOrderItemsParams) *db.WriteOrderItemsBatchResults {
+	args := mock.Called(ctx, params)
+	return args.Get(0).(*db.WriteOrderItemsBatchResults)
+}
+
 func (mock *MockQuerier) WritePerformers(ctx context.Context, params []string) *db.WritePerformersBatchResults {
 	args := mock.Called(ctx, params)
 	return args.Get(0).(*db.WritePerformersBatchResults)
@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/dslaw/book-tickets/pkg/db"
 	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,6 +22,26 @@ func closeBatch(br Closable) error {
 	return br.Close()
 }
 
+// foreignKeyViolation is the Postgres error code for a foreign key constraint
+// violation.
+const foreignKeyViolation = "23503"
+
+// isForeignKeyViolation checks if the error is due to a foreign key constraint
+// violation.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
+
+// mapForeignKeyViolation maps an error due to removing a record that is still
+// referenced by another to `ErrEntityInUse`.
+func mapForeignKeyViolation(err error) error {
+	if isForeignKeyViolation(err) {
+		return ErrEntityInUse
+	}
+	return err
+}
+
 type QueryRowable interface {
 	QueryRow(func(int, int32, error))
 }
@@ -107,6 +130,180 @@ func (r *VenuesRepo) DeleteVenue(ctx context.Context, id int32) error {
 	return nil
 }
 
+type VenueLayoutsRepo struct {
+	Conn    *pgxpool.Pool
+	queries db.Querier
+}
+
+func NewVenueLayoutsRepo(conn *pgxpool.Pool) *VenueLayoutsRepo {
+	return &VenueLayoutsRepo{Conn: conn, queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewVenueLayoutsRepoFromQueries(queries db.Querier) *VenueLayoutsRepo {
+	return &VenueLayoutsRepo{Conn: nil, queries: queries}
+}
+
+// GetVenueLayout fetches the seating layout of the venue, given by id, from the
+// database of record. A venue without a layout has no sections.
+func (r *VenueLayoutsRepo) GetVenueLayout(ctx context.Context, venueID int32) (entities.VenueLayout, error) {
+	rows, err := r.queries.GetVenueLayout(ctx, venueID)
+	if err != nil {
+		return entities.VenueLayout{}, err
+	}
+	if len(rows) == 0 {
+		// Check that the venue exists, with lack of an error indicating that
+		// it exists.
+		if _, err := r.queries.GetVenue(ctx, venueID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return entities.VenueLayout{}, ErrNoSuchEntity
+			}
+			return entities.VenueLayout{}, err
+		}
+	}
+
+	return MapGetVenueLayoutRows(venueID, rows), nil
+}
+
+func (r *VenueLayoutsRepo) ExecSetVenueLayout(
+	ctx context.Context,
+	queries db.Querier,
+	layout entities.VenueLayout,
+) error {
+	if _, err := queries.GetVenue(ctx, layout.VenueID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoSuchEntity
+		}
+		return err
+	}
+
+	// Sections, rows and seats are upserted by name, so that the ids of those
+	// that are kept, and any tickets for their seats, are unchanged.
+	sectionNames := make([]string, len(layout.Sections))
+	for idx, section := range layout.Sections {
+		sectionNames[idx] = section.Name
+	}
+	sections, err := queries.UpsertVenueSections(ctx, db.UpsertVenueSectionsParams{
+		VenueID: layout.VenueID,
+		Names:   sectionNames,
+	})
+	if err != nil {
+		return err
+	}
+
+	sectionIDs := make(map[string]int32, len(sections))
+	for _, section := range sections {
+		sectionIDs[section.Name] = section.ID
+	}
+
+	rowParams := db.UpsertVenueRowsParams{SectionIds: []int32{}, Names: []string{}}
+	for _, section := range layout.Sections {
+		for _, row := range section.Rows {
+			rowParams.SectionIds = append(rowParams.SectionIds, sectionIDs[section.Name])
+			rowParams.Names = append(rowParams.Names, row.Name)
+		}
+	}
+	rows, err := queries.UpsertVenueRows(ctx, rowParams)
+	if err != nil {
+		return err
+	}
+
+	type rowKey struct {
+		SectionID int32
+		Name      string
+	}
+	rowIDs := make(map[rowKey]int32, len(rows))
+	for _, row := range rows {
+		rowIDs[rowKey{SectionID: row.SectionID, Name: row.Name}] = row.ID
+	}
+
+	seatParams := db.UpsertVenueSeatsParams{
+		RowIds:     []int32{},
+		Numbers:    []string{},
+		Xs:         []float64{},
+		Ys:         []float64{},
+		Accessible: []bool{},
+		Obstructed: []bool{},
+	}
+	for _, section := range layout.Sections {
+		for _, row := range section.Rows {
+			rowID := rowIDs[rowKey{SectionID: sectionIDs[section.Name], Name: row.Name}]
+			for _, seat := range row.Seats {
+				seatParams.RowIds = append(seatParams.RowIds, rowID)
+				seatParams.Numbers = append(seatParams.Numbers, seat.Number)
+				seatParams.Xs = append(seatParams.Xs, seat.X)
+				seatParams.Ys = append(seatParams.Ys, seat.Y)
+				seatParams.Accessible = append(seatParams.Accessible, seat.Accessible)
+				seatParams.Obstructed = append(seatParams.Obstructed, seat.Obstructed)
+			}
+		}
+	}
+	seatIDs, err := queries.UpsertVenueSeats(ctx, seatParams)
+	if err != nil {
+		return err
+	}
+	if seatIDs == nil {
+		seatIDs = []int32{}
+	}
+
+	// Remove whatever is no longer part of the layout, from the bottom up.
+	err = queries.TrimVenueSeats(ctx, db.TrimVenueSeatsParams{VenueID: layout.VenueID, SeatIds: seatIDs})
+	if err != nil {
+		return mapForeignKeyViolation(err)
+	}
+
+	keptRowIDs := make([]int32, 0, len(rowIDs))
+	for _, id := range rowIDs {
+		keptRowIDs = append(keptRowIDs, id)
+	}
+	err = queries.TrimVenueRows(ctx, db.TrimVenueRowsParams{VenueID: layout.VenueID, RowIds: keptRowIDs})
+	if err != nil {
+		return err
+	}
+
+	keptSectionIDs := make([]int32, 0, len(sectionIDs))
+	for _, id := range sectionIDs {
+		keptSectionIDs = append(keptSectionIDs, id)
+	}
+	return queries.TrimVenueSections(ctx, db.TrimVenueSectionsParams{
+		VenueID:    layout.VenueID,
+		SectionIds: keptSectionIDs,
+	})
+}
+
+// SetVenueLayout creates or replaces the seating layout of the layout's venue,
+// in a single transaction. Seats that are kept retain their ids. If a seat
+// that would be removed has tickets released for it, nothing is written and
+// `ErrEntityInUse` is returned.
+func (r *VenueLayoutsRepo) SetVenueLayout(ctx context.Context, layout entities.VenueLayout) error {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.New(tx)
+	if err := r.ExecSetVenueLayout(ctx, qtx, layout); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteVenueLayout removes the seating layout of the venue given by id. If
+// any of the layout's seats have tickets released for them, `ErrEntityInUse`
+// is returned.
+func (r *VenueLayoutsRepo) DeleteVenueLayout(ctx context.Context, venueID int32) error {
+	countDeleted, err := r.queries.DeleteVenueLayout(ctx, venueID)
+	if err != nil {
+		return mapForeignKeyViolation(err)
+	}
+	if countDeleted == 0 {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
 type EventsRepo struct {
 	Conn    *pgxpool.Pool
 	queries db.Querier
@@ -153,11 +350,14 @@ func (r *EventsRepo) ExecCreateEvent(
 ) (int32, error) {
 	// Insert event.
 	params := db.CreateEventParams{
-		VenueID:     event.Venue.ID,
-		Name:        event.Name,
-		StartsAt:    MapTime(event.StartsAt),
-		EndsAt:      MapTime(event.EndsAt),
-		Description: MapNullableString(event.Description),
+		VenueID:           event.Venue.ID,
+		Name:              event.Name,
+		StartsAt:          MapTime(event.StartsAt),
+		EndsAt:            MapTime(event.EndsAt),
+		Description:       MapNullableString(event.Description),
+		MaxTicketsPerUser: MapNullableInt(event.MaxTicketsPerUser),
+		ResaleMinRate:     MapNullableInt(event.ResaleMinRate),
+		ResaleMaxRate:     MapNullableInt(event.ResaleMaxRate),
 	}
 	id, err := queries.CreateEvent(ctx, params)
 	if err != nil {
@@ -223,11 +423,14 @@ func (r *EventsRepo) ExecUpdateEvent(
 	closeBatch func(Closable) error,
 ) error {
 	params := db.UpdateEventParams{
-		EventID:     event.ID,
-		Name:        event.Name,
-		StartsAt:    MapTime(event.StartsAt),
-		EndsAt:      MapTime(event.EndsAt),
-		Description: MapNullableString(event.Description),
+		EventID:           event.ID,
+		Name:              event.Name,
+		StartsAt:          MapTime(event.StartsAt),
+		EndsAt:            MapTime(event.EndsAt),
+		Description:       MapNullableString(event.Description),
+		MaxTicketsPerUser: MapNullableInt(event.MaxTicketsPerUser),
+		ResaleMinRate:     MapNullableInt(event.ResaleMinRate),
+		ResaleMaxRate:     MapNullableInt(event.ResaleMaxRate),
 	}
 
 	if _, err := queries.UpdateEvent(ctx, params); err != nil {
@@ -286,16 +489,17 @@ func (r *EventsRepo) DeleteEvent(ctx context.Context, id int32) error {
 }
 
 type TicketsRepo struct {
+	Conn    *pgxpool.Pool
 	queries db.Querier
 }
 
-func NewTicketsRepo(conn db.DBTX) *TicketsRepo {
-	return &TicketsRepo{queries: db.New(conn)}
+func NewTicketsRepo(conn *pgxpool.Pool) *TicketsRepo {
+	return &TicketsRepo{Conn: conn, queries: db.New(conn)}
 }
 
 // For creating a repo with a mock queries object when testing.
 func NewTicketsRepoFromQueries(queries db.Querier) *TicketsRepo {
-	return &TicketsRepo{queries: queries}
+	return &TicketsRepo{Conn: nil, queries: queries}
 }
 
 func (r *TicketsRepo) ExecWriteTickets(
@@ -307,9 +511,13 @@ func (r *TicketsRepo) ExecWriteTickets(
 	params := make([]db.WriteNewTicketsParams, len(tickets))
 	for idx, ticket := range tickets {
 		params[idx] = db.WriteNewTicketsParams{
-			EventID: ticket.EventID,
-			Price:   int32(ticket.Price),
-			Seat:    ticket.Seat,
+			EventID:      ticket.EventID,
+			Price:        ticket.Price.Amount,
+			Currency:     ticket.Price.Currency,
+			Seat:         ticket.Seat,
+			VenueSeatID:  MapNullableID(ticket.VenueSeatID),
+			TicketTypeID: MapNullableID(ticket.TicketTypeID),
+			ReleaseID:    MapNullableID(ticket.ReleaseID),
 		}
 	}
 
@@ -342,6 +550,135 @@ func (r *TicketsRepo) WriteTickets(ctx context.Context, tickets []entities.Ticke
 	return nil
 }
 
+func (r *TicketsRepo) ExecWriteTicketRelease(
+	ctx context.Context,
+	queries db.Querier,
+	release entities.TicketRelease,
+	tickets []entities.Ticket,
+	queryRow func(QueryRowable),
+) (int32, error) {
+	releaseID, err := queries.CreateTicketRelease(ctx, db.CreateTicketReleaseParams{
+		OnSaleAt:  MapNullableTime(release.OnSaleAt),
+		OffSaleAt: MapNullableTime(release.OffSaleAt),
+		EventID:   release.EventID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoSuchEntity
+		}
+		return 0, err
+	}
+
+	for _, presale := range release.Presales {
+		presaleID, err := queries.CreatePresale(ctx, db.CreatePresaleParams{
+			ReleaseID:  releaseID,
+			Name:       presale.Name,
+			StartsAt:   MapTime(presale.StartsAt),
+			EndsAt:     MapTime(presale.EndsAt),
+			AccessCode: MapNullableString(presale.AccessCode),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		if len(presale.UserIDs) == 0 {
+			continue
+		}
+		err = queries.AddPresaleUsers(ctx, db.AddPresaleUsersParams{
+			PresaleID: presaleID,
+			UserIds:   presale.UserIDs,
+		})
+		if err != nil {
+			// The only foreign key that can be violated is for the user.
+			if isForeignKeyViolation(err) {
+				return 0, ErrNoSuchUser
+			}
+			return 0, err
+		}
+	}
+
+	released := make([]entities.Ticket, len(tickets))
+	for idx, ticket := range tickets {
+		ticket.ReleaseID = releaseID
+		released[idx] = ticket
+	}
+
+	r.ExecWriteTickets(ctx, queries, released, queryRow)
+	return releaseID, nil
+}
+
+// WriteTicketRelease creates a scheduled release of tickets for the release's
+// event, along with its presales, and returns the release's id. Either all of
+// the tickets are released, or none are.
+func (r *TicketsRepo) WriteTicketRelease(
+	ctx context.Context,
+	release entities.TicketRelease,
+	tickets []entities.Ticket,
+) (int32, error) {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var batchErr error
+	collectErr := func(_ int, _ int32, err error) {
+		if err != nil {
+			batchErr = err
+		}
+	}
+
+	qtx := db.New(tx)
+	releaseID, err := r.ExecWriteTicketRelease(ctx, qtx, release, tickets, func(br QueryRowable) {
+		br.QueryRow(collectErr)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if batchErr != nil {
+		if errors.Is(batchErr, sql.ErrNoRows) {
+			return 0, ErrNoSuchEntity
+		}
+		return 0, batchErr
+	}
+
+	return releaseID, tx.Commit(ctx)
+}
+
+// GetTicketReleases fetches the releases given by id, along with their
+// presales. Releases that don't exist are omitted.
+func (r *TicketsRepo) GetTicketReleases(ctx context.Context, ids []int32) ([]entities.TicketRelease, error) {
+	rows, err := r.queries.GetTicketReleases(ctx, ids)
+	if err != nil {
+		return []entities.TicketRelease{}, err
+	}
+	return MapGetTicketReleasesRows(rows), nil
+}
+
+// IsPresaleUser checks whether the user is on the presale's allowlist.
+func (r *TicketsRepo) IsPresaleUser(ctx context.Context, presaleID int32, userID int32) (bool, error) {
+	return r.queries.IsPresaleUser(ctx, db.IsPresaleUserParams{PresaleID: presaleID, UserID: userID})
+}
+
+// GetPurchaseLimits fetches the purchase limits of those of the given events
+// that limit the number of tickets per user, along with how many of each
+// event's tickets have been purchased by the purchaser.
+func (r *TicketsRepo) GetPurchaseLimits(
+	ctx context.Context,
+	eventIDs []int32,
+	purchaserID int32,
+) ([]entities.PurchaseLimit, error) {
+	params := db.GetPurchaseLimitsParams{
+		PurchaserID: MapPurchaserID(purchaserID),
+		EventIds:    eventIDs,
+	}
+	rows, err := r.queries.GetPurchaseLimits(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return MapGetPurchaseLimitsRows(rows), nil
+}
+
 // GetTicket fetches the ticket, given by id, from the database of record.
 func (r *TicketsRepo) GetTicket(ctx context.Context, id int32) (entities.Ticket, error) {
 	row, err := r.queries.GetTicket(ctx, id)
@@ -356,19 +693,186 @@ func (r *TicketsRepo) GetTicket(ctx context.Context, id int32) (entities.Ticket,
 }
 
 // GetAvailableTickets fetches tickets that are available for purchase, for the
-// given event.
+// given event. If none are available, the event is checked for, so that an
+// event that's sold out can be told apart from one that doesn't exist.
 func (r *TicketsRepo) GetAvailableTickets(ctx context.Context, eventID int32) ([]entities.Ticket, error) {
 	rows, err := r.queries.GetAvailableTickets(ctx, eventID)
 	if err != nil {
 		return []entities.Ticket{}, err
 	}
 	if len(rows) == 0 {
-		return []entities.Ticket{}, ErrNoSuchEntity
+		exists, err := r.queries.EventExists(ctx, eventID)
+		if err != nil {
+			return []entities.Ticket{}, err
+		}
+		if !exists {
+			return []entities.Ticket{}, ErrNoSuchEntity
+		}
+		return []entities.Ticket{}, nil
 	}
 
 	return MapGetAvailableTicketRows(rows), nil
 }
 
+// GetAvailableSeatedTickets fetches tickets for seats of the venue's layout
+// that are available for purchase, for the given event, along with where each
+// seat is. Tickets are ordered by section, row and position within the row.
+func (r *TicketsRepo) GetAvailableSeatedTickets(ctx context.Context, eventID int32) ([]entities.SeatedTicket, error) {
+	rows, err := r.queries.GetAvailableSeatedTickets(ctx, eventID)
+	if err != nil {
+		return []entities.SeatedTicket{}, err
+	}
+	return MapGetAvailableSeatedTicketsRows(rows), nil
+}
+
+// GetEventCurrencies fetches the currencies that an event's tickets are priced
+// in.
+func (r *TicketsRepo) GetEventCurrencies(ctx context.Context, eventID int32) ([]string, error) {
+	return r.queries.GetEventCurrencies(ctx, eventID)
+}
+
+// GetEventVenueSeats fetches those of the given seats that are part of the
+// layout of the event's venue, along with whether a ticket has been released
+// for each of them.
+func (r *TicketsRepo) GetEventVenueSeats(
+	ctx context.Context,
+	eventID int32,
+	seatIDs []int32,
+) ([]entities.EventVenueSeat, error) {
+	params := db.GetEventVenueSeatsParams{EventID: eventID, SeatIds: seatIDs}
+	rows, err := r.queries.GetEventVenueSeats(ctx, params)
+	if err != nil {
+		return []entities.EventVenueSeat{}, err
+	}
+
+	seats := make([]entities.EventVenueSeat, len(rows))
+	for idx, row := range rows {
+		seats[idx] = entities.EventVenueSeat{
+			ID:          row.ID,
+			SectionName: row.SectionName,
+			IsReleased:  row.Released,
+		}
+	}
+	return seats, nil
+}
+
+// CreateGATier creates a new general admission tier for the tier's event and
+// returns the new entity's id.
+func (r *TicketsRepo) CreateGATier(ctx context.Context, tier entities.GATier) (int32, error) {
+	params := db.CreateGaTierParams{
+		Name:     tier.Name,
+		Price:    tier.Price.Amount,
+		Currency: tier.Price.Currency,
+		Capacity: tier.Capacity,
+		EventID:  tier.EventID,
+	}
+	id, err := r.queries.CreateGaTier(ctx, params)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return id, ErrNoSuchEntity
+	}
+	return id, err
+}
+
+// GetGATier fetches the general admission tier given by id.
+func (r *TicketsRepo) GetGATier(ctx context.Context, id int32) (entities.GATier, error) {
+	row, err := r.queries.GetGaTier(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.GATier{}, ErrNoSuchEntity
+		}
+		return entities.GATier{}, err
+	}
+	return MapGATier(row), nil
+}
+
+// GetEventGATiers fetches the general admission tiers of the given event.
+func (r *TicketsRepo) GetEventGATiers(ctx context.Context, eventID int32) ([]entities.GATier, error) {
+	rows, err := r.queries.GetEventGaTiers(ctx, eventID)
+	if err != nil {
+		return []entities.GATier{}, err
+	}
+
+	tiers := make([]entities.GATier, len(rows))
+	for idx, row := range rows {
+		tiers[idx] = MapGATier(row)
+	}
+	return tiers, nil
+}
+
+// CreateTicketType creates a new ticket type for the type's event and returns
+// the new entity's id.
+func (r *TicketsRepo) CreateTicketType(ctx context.Context, ticketType entities.TicketType) (int32, error) {
+	params := db.CreateTicketTypeParams{
+		Name:         ticketType.Name,
+		Description:  MapNullableString(ticketType.Description),
+		Price:        ticketType.Price.Amount,
+		Currency:     ticketType.Price.Currency,
+		Eligibility:  ticketType.Eligibility,
+		SaleStartsAt: MapNullableTime(ticketType.SaleStartsAt),
+		SaleEndsAt:   MapNullableTime(ticketType.SaleEndsAt),
+		EventID:      ticketType.EventID,
+	}
+	if params.Eligibility == nil {
+		params.Eligibility = []string{}
+	}
+	id, err := r.queries.CreateTicketType(ctx, params)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return id, ErrNoSuchEntity
+	}
+	return id, err
+}
+
+// GetEventTicketTypes fetches the ticket types of the given event.
+func (r *TicketsRepo) GetEventTicketTypes(ctx context.Context, eventID int32) ([]entities.TicketType, error) {
+	rows, err := r.queries.GetEventTicketTypes(ctx, eventID)
+	if err != nil {
+		return []entities.TicketType{}, err
+	}
+
+	ticketTypes := make([]entities.TicketType, len(rows))
+	for idx, row := range rows {
+		ticketTypes[idx] = MapTicketType(row)
+	}
+	return ticketTypes, nil
+}
+
+// GetTicketTypes fetches the ticket types given by id. Ticket types that don't
+// exist are omitted.
+func (r *TicketsRepo) GetTicketTypes(ctx context.Context, ids []int32) ([]entities.TicketType, error) {
+	rows, err := r.queries.GetTicketTypes(ctx, ids)
+	if err != nil {
+		return []entities.TicketType{}, err
+	}
+
+	ticketTypes := make([]entities.TicketType, len(rows))
+	for idx, row := range rows {
+		ticketTypes[idx] = MapTicketType(row)
+	}
+	return ticketTypes, nil
+}
+
+// IssueGATickets issues `quantity` tickets for the general admission tier, and
+// returns their ids. Either all of the tickets are issued, or none are if the
+// tier doesn't have the capacity left for all of them.
+func (r *TicketsRepo) IssueGATickets(ctx context.Context, tierID int32, quantity int32) ([]int32, error) {
+	params := db.IssueGaTicketsParams{Quantity: quantity, GaTierID: tierID}
+	ticketIDs, err := r.queries.IssueGaTickets(ctx, params)
+	if err != nil {
+		return []int32{}, err
+	}
+	if len(ticketIDs) == 0 {
+		return []int32{}, ErrNoCapacityLeft
+	}
+	return ticketIDs, nil
+}
+
+// ReturnGATickets voids issued general admission tickets that weren't
+// purchased, returning them to their tiers' capacity.
+func (r *TicketsRepo) ReturnGATickets(ctx context.Context, ticketIDs []int32) error {
+	_, err := r.queries.ReturnGaTickets(ctx, ticketIDs)
+	return err
+}
+
 // SetTicketPurchaser updates a ticket to mark that it has been purchased by the
 // user given by `purchaserID`.
 func (r *TicketsRepo) SetTicketPurchaser(ctx context.Context, ticketID int32, purchaserID int32) error {
@@ -385,3 +889,1676 @@ func (r *TicketsRepo) SetTicketPurchaser(ctx context.Context, ticketID int32, pu
 	}
 	return nil
 }
+
+// GetTickets fetches the tickets, given by their ids, from the database of
+// record. If any of the tickets do not exist, `ErrNoSuchEntity` is returned.
+func (r *TicketsRepo) GetTickets(ctx context.Context, ids []int32) ([]entities.Ticket, error) {
+	rows, err := r.queries.GetTickets(ctx, ids)
+	if err != nil {
+		return []entities.Ticket{}, err
+	}
+	if len(rows) != len(ids) {
+		return []entities.Ticket{}, ErrNoSuchEntity
+	}
+
+	return MapGetTicketsRows(rows), nil
+}
+
+// SetTicketsPurchaser updates all of the given tickets to mark that they have
+// been purchased by the user given by `purchaserID`. No tickets are updated if
+// any of them do not exist or have already been purchased.
+func (r *TicketsRepo) SetTicketsPurchaser(ctx context.Context, ticketIDs []int32, purchaserID int32) error {
+	params := db.SetTicketsPurchaserParams{
+		TicketIds:   ticketIDs,
+		PurchaserID: MapPurchaserID(purchaserID),
+	}
+	countUpdated, err := r.queries.SetTicketsPurchaser(ctx, params)
+	if err != nil {
+		return err
+	}
+	if countUpdated != int64(len(ticketIDs)) {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
+// redeemPromoCode counts a redemption of the promo code by the purchaser. If
+// the code, or the purchaser, has no redemptions left, `ErrNoRedemptionsLeft`
+// is returned.
+func (r *TicketsRepo) redeemPromoCode(
+	ctx context.Context,
+	queries db.Querier,
+	promoCodeID int32,
+	purchaserID int32,
+) error {
+	countRedeemed, err := queries.RedeemPromoCode(ctx, promoCodeID)
+	if err != nil {
+		return err
+	}
+	if countRedeemed == 0 {
+		return ErrNoRedemptionsLeft
+	}
+
+	params := db.RedeemPromoCodeForUserParams{PromoCodeID: promoCodeID, PurchaserID: purchaserID}
+	countRedeemed, err = queries.RedeemPromoCodeForUser(ctx, params)
+	if err != nil {
+		return err
+	}
+	if countRedeemed == 0 {
+		return ErrNoRedemptionsLeft
+	}
+	return nil
+}
+
+func (r *TicketsRepo) ExecPurchaseTickets(
+	ctx context.Context,
+	queries db.Querier,
+	quote entities.Quote,
+	payment entities.Payment,
+	// Callback to capture the authorized payment, after everything else for
+	// the purchase has been written.
+	capture func(context.Context) error,
+	// Callback to close a batch results object. This allows for ease of
+	// testing, as the BatchResults object returned by a batch query doesn't
+	// have an interface to mock, and its call to `Close()` forwards the call to
+	// a private object.
+	closeBatch func(Closable) error,
+) (int32, error) {
+	var orderID int32
+
+	ticketIDs := make([]int32, len(quote.Items))
+	for idx, item := range quote.Items {
+		ticketIDs[idx] = item.TicketID
+	}
+
+	// Lock the purchaser before purchasing, so that the purchaser's tickets
+	// can be counted against the events' purchase limits.
+	if err := queries.LockPurchaser(ctx, payment.PurchaserID); err != nil {
+		return orderID, err
+	}
+
+	params := db.SetTicketsPurchaserParams{
+		TicketIds:   ticketIDs,
+		PurchaserID: MapPurchaserID(payment.PurchaserID),
+	}
+	countUpdated, err := queries.SetTicketsPurchaser(ctx, params)
+	if err != nil {
+		return orderID, err
+	}
+	if countUpdated != int64(len(ticketIDs)) {
+		return orderID, ErrNoSuchEntity
+	}
+
+	limitsParams := db.GetExceededPurchaseLimitsParams{
+		TicketIds:   ticketIDs,
+		PurchaserID: MapPurchaserID(payment.PurchaserID),
+	}
+	exceeded, err := queries.GetExceededPurchaseLimits(ctx, limitsParams)
+	if err != nil {
+		return orderID, err
+	}
+	if len(exceeded) > 0 {
+		return orderID, ErrPurchaseLimitExceeded
+	}
+
+	if quote.PromoCodeID != 0 {
+		err = r.redeemPromoCode(ctx, queries, quote.PromoCodeID, payment.PurchaserID)
+		if err != nil {
+			return orderID, err
+		}
+	}
+
+	orderID, err = createOrder(ctx, queries, quote, payment, closeBatch)
+	if err != nil {
+		return orderID, err
+	}
+
+	err = capturePayment(ctx, queries, payment, capture)
+	return orderID, err
+}
+
+// createOrder creates a completed order of the quote's items for the payment's
+// purchaser, and gives the order's id.
+func createOrder(
+	ctx context.Context,
+	queries db.Querier,
+	quote entities.Quote,
+	payment entities.Payment,
+	closeBatch func(Closable) error,
+) (int32, error) {
+	orderParams := db.CreateOrderParams{
+		PurchaserID: payment.PurchaserID,
+		PaymentID:   payment.ID,
+		Status:      entities.OrderStatusCompleted,
+		Total:       payment.Amount.Amount,
+		Currency:    payment.Amount.Currency,
+		FacilityFee: quote.FacilityFee.Amount,
+		Tax:         quote.Tax.Amount,
+		PromoCodeID: MapNullableID(quote.PromoCodeID),
+		Discount:    quote.Discount.Amount,
+	}
+	orderID, err := queries.CreateOrder(ctx, orderParams)
+	if err != nil {
+		return orderID, err
+	}
+
+	itemParams := make([]db.WriteOrderItemsParams, len(quote.Items))
+	for idx, item := range quote.Items {
+		itemParams[idx] = db.WriteOrderItemsParams{
+			OrderID:    orderID,
+			TicketID:   item.TicketID,
+			Price:      item.FaceValue.Amount,
+			ServiceFee: item.ServiceFee.Amount,
+			Tax:        item.Tax.Amount,
+			Discount:   item.Discount.Amount,
+		}
+	}
+
+	br := queries.WriteOrderItems(ctx, itemParams)
+	err = closeBatch(br)
+	return orderID, err
+}
+
+// capturePayment captures the authorized payment, and records it as captured.
+func capturePayment(
+	ctx context.Context,
+	queries db.Querier,
+	payment entities.Payment,
+	capture func(context.Context) error,
+) error {
+	if err := capture(ctx); err != nil {
+		return err
+	}
+
+	paymentParams := db.UpdatePaymentStatusParams{
+		Status:     entities.PaymentStatusCaptured,
+		PaymentID:  payment.ID,
+		FromStatus: entities.PaymentStatusAuthorized,
+	}
+	countUpdated, err := queries.UpdatePaymentStatus(ctx, paymentParams)
+	if err != nil {
+		return err
+	}
+	if countUpdated == 0 {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
+// PurchaseTickets marks all of the given tickets as purchased by the
+// authorized payment's purchaser, redeems the quote's promo code, creates an
+// order for them and captures the payment, in a single transaction. The
+// tickets are only purchased if the payment is captured. If any of the tickets
+// do not exist or have already been purchased, nothing is written and
+// `ErrNoSuchEntity` is returned, or `ErrNoRedemptionsLeft` if the promo code
+// can no longer be redeemed, or `ErrPurchaseLimitExceeded` if the purchaser
+// would have more of an event's tickets than its limit on tickets per user.
+// The order's id is returned, if successful. The payment is captured before
+// the transaction is committed, so if committing fails, the payment must be
+// refunded rather than voided.
+func (r *TicketsRepo) PurchaseTickets(
+	ctx context.Context,
+	quote entities.Quote,
+	payment entities.Payment,
+	capture func(context.Context) error,
+) (int32, error) {
+	var orderID int32
+
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return orderID, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.New(tx)
+	orderID, err = r.ExecPurchaseTickets(ctx, qtx, quote, payment, capture, closeBatch)
+	if err != nil {
+		return orderID, err
+	}
+
+	err = tx.Commit(ctx)
+	return orderID, err
+}
+
+// GetTicketPurchase fetches the current purchase of the ticket given by id. If
+// the ticket doesn't exist or isn't purchased, `ErrNoSuchEntity` is returned.
+func (r *TicketsRepo) GetTicketPurchase(ctx context.Context, ticketID int32) (entities.TicketPurchase, error) {
+	row, err := r.queries.GetTicketPurchase(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketPurchase{}, ErrNoSuchEntity
+		}
+		return entities.TicketPurchase{}, err
+	}
+	return MapGetTicketPurchaseRow(ticketID, row), nil
+}
+
+func (r *TicketsRepo) ExecRefundTicket(
+	ctx context.Context,
+	queries db.Querier,
+	purchase entities.TicketPurchase,
+	rerelease bool,
+) (entities.Refund, error) {
+	return execRefundTicket(ctx, queries, purchase, rerelease, false)
+}
+
+// execRefundTicket returns a purchased ticket and records a pending refund of
+// the price paid for it, and is shared by refunds requested by the purchaser
+// and refunds for cancelled events. The order's facility fee, and its tax, are
+// refunded along with the ticket if `withFacilityFee` is set, or if the ticket
+// is the last of its order to be refunded, unless they already have been.
+func execRefundTicket(
+	ctx context.Context,
+	queries db.Querier,
+	purchase entities.TicketPurchase,
+	rerelease bool,
+	withFacilityFee bool,
+) (entities.Refund, error) {
+	params := db.ClearTicketPurchaserParams{
+		Rerelease:   rerelease,
+		TicketID:    purchase.TicketID,
+		PurchaserID: MapPurchaserID(purchase.PurchaserID),
+	}
+	countUpdated, err := queries.ClearTicketPurchaser(ctx, params)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+	if countUpdated == 0 {
+		return entities.Refund{}, ErrNoSuchEntity
+	}
+
+	unrefunded, err := queries.GetUnrefundedFacilityFee(ctx, purchase.OrderID)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+	var facilityFee int64
+	if withFacilityFee || unrefunded.CountUnrefunded <= 1 {
+		facilityFee = unrefunded.FacilityFee
+	}
+	amount := purchase.Price
+	amount.Amount += facilityFee
+
+	record := entities.Refund{
+		PaymentID:        purchase.PaymentID,
+		TicketID:         purchase.TicketID,
+		Amount:           amount,
+		Status:           entities.RefundStatusPending,
+		PaymentReference: purchase.PaymentReference,
+	}
+	refundParams := db.CreateRefundParams{
+		PaymentID:   record.PaymentID,
+		TicketID:    record.TicketID,
+		Amount:      record.Amount.Amount,
+		FacilityFee: facilityFee,
+	}
+	record.ID, err = queries.CreateRefund(ctx, refundParams)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+	return record, nil
+}
+
+// RefundTicket returns a purchased ticket, and records a pending refund of the
+// price paid for it, in a single transaction. The ticket is returned to
+// inventory if `rerelease` is set, and is voided otherwise. If the ticket is
+// the last of its order to be refunded, the order's facility fee and its tax
+// are refunded with it. The refund is to be made with the payment processor
+// after the transaction is committed, and then completed. If the ticket is no
+// longer purchased by the purchaser, nothing is written and `ErrNoSuchEntity`
+// is returned.
+func (r *TicketsRepo) RefundTicket(
+	ctx context.Context,
+	purchase entities.TicketPurchase,
+	rerelease bool,
+) (entities.Refund, error) {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.New(tx)
+	record, err := r.ExecRefundTicket(ctx, qtx, purchase, rerelease)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+
+	err = tx.Commit(ctx)
+	return record, err
+}
+
+func (r *TicketsRepo) ExecCompleteRefund(
+	ctx context.Context,
+	queries db.Querier,
+	refund entities.Refund,
+) error {
+	params := db.CompleteRefundParams{
+		Reference: MapNullableString(refund.Reference),
+		RefundID:  refund.ID,
+	}
+	countUpdated, err := queries.CompleteRefund(ctx, params)
+	if err != nil {
+		return err
+	}
+	if countUpdated == 0 {
+		return ErrNoSuchEntity
+	}
+
+	_, err = queries.SetPaymentRefunded(ctx, refund.PaymentID)
+	return err
+}
+
+// CompleteRefund records a pending refund as made by the payment processor,
+// with the refund's reference, and marks its payment as refunded if all of it
+// has been refunded, in a single transaction. If the refund isn't pending,
+// nothing is written and `ErrNoSuchEntity` is returned.
+func (r *TicketsRepo) CompleteRefund(ctx context.Context, refund entities.Refund) error {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.New(tx)
+	if err := r.ExecCompleteRefund(ctx, qtx, refund); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// FailRefund records a pending refund as one that the payment processor won't
+// make. If the refund isn't pending, `ErrNoSuchEntity` is returned.
+func (r *TicketsRepo) FailRefund(ctx context.Context, refund entities.Refund) error {
+	countUpdated, err := r.queries.FailRefund(ctx, refund.ID)
+	if err != nil {
+		return err
+	}
+	if countUpdated == 0 {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
+// GetStalePendingRefunds fetches up to `limit` pending refunds that haven't
+// been updated since `before`, oldest first.
+func (r *TicketsRepo) GetStalePendingRefunds(
+	ctx context.Context,
+	before time.Time,
+	limit int32,
+) ([]entities.Refund, error) {
+	params := db.GetStalePendingRefundsParams{
+		UpdatedBefore: MapTime(before),
+		MaxRefunds:    limit,
+	}
+	rows, err := r.queries.GetStalePendingRefunds(ctx, params)
+	if err != nil {
+		return []entities.Refund{}, err
+	}
+
+	refunds := make([]entities.Refund, len(rows))
+	for idx, row := range rows {
+		refunds[idx] = MapGetStalePendingRefundsRow(row)
+	}
+	return refunds, nil
+}
+
+// GetTicketResaleTerms fetches the ticket given by id, along with its event's
+// start and resale rates. If the ticket doesn't exist, or has been voided,
+// `ErrNoSuchEntity` is returned.
+func (r *TicketsRepo) GetTicketResaleTerms(ctx context.Context, ticketID int32) (entities.TicketResaleTerms, error) {
+	row, err := r.queries.GetTicketResaleTerms(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketResaleTerms{}, ErrNoSuchEntity
+		}
+		return entities.TicketResaleTerms{}, err
+	}
+	return MapGetTicketResaleTermsRow(row), nil
+}
+
+// CreateResaleListing creates an active listing of the ticket by its seller.
+func (r *TicketsRepo) CreateResaleListing(
+	ctx context.Context,
+	listing entities.ResaleListing,
+) (entities.ResaleListing, error) {
+	row, err := r.queries.CreateResaleListing(ctx, db.CreateResaleListingParams{
+		TicketID: listing.TicketID,
+		SellerID: listing.SellerID,
+		Price:    listing.Price.Amount,
+		Currency: listing.Price.Currency,
+	})
+	if err != nil {
+		return entities.ResaleListing{}, err
+	}
+	return MapResaleListing(row), nil
+}
+
+// GetResaleListing fetches the listing given by id.
+func (r *TicketsRepo) GetResaleListing(ctx context.Context, id int32) (entities.ResaleListing, error) {
+	row, err := r.queries.GetResaleListing(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ResaleListing{}, ErrNoSuchEntity
+		}
+		return entities.ResaleListing{}, err
+	}
+	return MapResaleListing(row), nil
+}
+
+// GetActiveResaleListing fetches the active listing of the ticket given by id.
+// If the ticket isn't listed, `ErrNoSuchEntity` is returned.
+func (r *TicketsRepo) GetActiveResaleListing(ctx context.Context, ticketID int32) (entities.ResaleListing, error) {
+	row, err := r.queries.GetActiveResaleListing(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ResaleListing{}, ErrNoSuchEntity
+		}
+		return entities.ResaleListing{}, err
+	}
+	return MapResaleListing(row), nil
+}
+
+// GetEventResaleListings fetches the active listings of the event's tickets
+// that can be purchased, cheapest first.
+func (r *TicketsRepo) GetEventResaleListings(
+	ctx context.Context,
+	eventID int32,
+) ([]entities.AvailableResaleListing, error) {
+	rows, err := r.queries.GetEventResaleListings(ctx, eventID)
+	if err != nil {
+		return []entities.AvailableResaleListing{}, err
+	}
+	return MapGetEventResaleListingsRows(rows), nil
+}
+
+// GetSellerResaleListings fetches the listings of the seller given by id, most
+// recent first.
+func (r *TicketsRepo) GetSellerResaleListings(ctx context.Context, sellerID int32) ([]entities.ResaleListing, error) {
+	rows, err := r.queries.GetSellerResaleListings(ctx, sellerID)
+	if err != nil {
+		return []entities.ResaleListing{}, err
+	}
+	return MapResaleListings(rows), nil
+}
+
+// CancelResaleListing cancels the listing given by id. If the listing isn't
+// active, `ErrNoSuchEntity` is returned.
+func (r *TicketsRepo) CancelResaleListing(ctx context.Context, id int32) (entities.ResaleListing, error) {
+	row, err := r.queries.UpdateResaleListingStatus(ctx, db.UpdateResaleListingStatusParams{
+		Status:    entities.ResaleListingStatusCancelled,
+		ListingID: id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ResaleListing{}, ErrNoSuchEntity
+		}
+		return entities.ResaleListing{}, err
+	}
+	return MapResaleListing(row), nil
+}
+
+func (r *TicketsRepo) ExecPurchaseResaleListing(
+	ctx context.Context,
+	queries db.Querier,
+	listing entities.ResaleListing,
+	quote entities.Quote,
+	payment entities.Payment,
+	// Callback to capture the authorized payment, after everything else for
+	// the purchase has been written.
+	capture func(context.Context) error,
+	// Callback to close a batch results object, as for `ExecPurchaseTickets`.
+	closeBatch func(Closable) error,
+) (int32, error) {
+	var orderID int32
+
+	// Lock the purchaser before purchasing, so that the purchaser's tickets
+	// can be counted against the event's purchase limit.
+	if err := queries.LockPurchaser(ctx, payment.PurchaserID); err != nil {
+		return orderID, err
+	}
+
+	_, err := queries.UpdateResaleListingStatus(ctx, db.UpdateResaleListingStatusParams{
+		Status:    entities.ResaleListingStatusSold,
+		ListingID: listing.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return orderID, ErrNoSuchEntity
+		}
+		return orderID, err
+	}
+
+	countUpdated, err := queries.TransferTicket(ctx, db.TransferTicketParams{
+		TicketID:    listing.TicketID,
+		SenderID:    listing.SellerID,
+		RecipientID: MapPurchaserID(payment.PurchaserID),
+	})
+	if err != nil {
+		return orderID, err
+	}
+	if countUpdated == 0 {
+		return orderID, ErrNoSuchEntity
+	}
+
+	limitsParams := db.GetExceededPurchaseLimitsParams{
+		TicketIds:   []int32{listing.TicketID},
+		PurchaserID: MapPurchaserID(payment.PurchaserID),
+	}
+	exceeded, err := queries.GetExceededPurchaseLimits(ctx, limitsParams)
+	if err != nil {
+		return orderID, err
+	}
+	if len(exceeded) > 0 {
+		return orderID, ErrPurchaseLimitExceeded
+	}
+
+	orderID, err = createOrder(ctx, queries, quote, payment, closeBatch)
+	if err != nil {
+		return orderID, err
+	}
+
+	payoutParams := db.CreateResalePayoutParams{
+		ListingID: listing.ID,
+		SellerID:  listing.SellerID,
+		PaymentID: payment.ID,
+		Amount:    listing.Price.Amount,
+		Currency:  listing.Price.Currency,
+	}
+	if _, err = queries.CreateResalePayout(ctx, payoutParams); err != nil {
+		return orderID, err
+	}
+
+	err = capturePayment(ctx, queries, payment, capture)
+	return orderID, err
+}
+
+// PurchaseResaleListing marks the listing as sold, moves its ticket from the
+// seller to the authorized payment's purchaser, creates an order for it,
+// records the payout owed to the seller and captures the payment, in a single
+// transaction. The ticket is only moved if the payment is captured. If the
+// listing is no longer active, or the seller no longer owns the ticket,
+// nothing is written and `ErrNoSuchEntity` is returned, or
+// `ErrPurchaseLimitExceeded` if the purchaser would have more of the event's
+// tickets than its limit on tickets per user. The order's id is returned, if
+// successful.
+func (r *TicketsRepo) PurchaseResaleListing(
+	ctx context.Context,
+	listing entities.ResaleListing,
+	quote entities.Quote,
+	payment entities.Payment,
+	capture func(context.Context) error,
+) (int32, error) {
+	var orderID int32
+
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return orderID, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.New(tx)
+	orderID, err = r.ExecPurchaseResaleListing(ctx, qtx, listing, quote, payment, capture, closeBatch)
+	if err != nil {
+		return orderID, err
+	}
+
+	err = tx.Commit(ctx)
+	return orderID, err
+}
+
+type PaymentsRepo struct {
+	queries db.Querier
+}
+
+func NewPaymentsRepo(conn db.DBTX) *PaymentsRepo {
+	return &PaymentsRepo{queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewPaymentsRepoFromQueries(queries db.Querier) *PaymentsRepo {
+	return &PaymentsRepo{queries: queries}
+}
+
+// CreatePayment records a new payment in the database of record, and returns
+// its id.
+func (r *PaymentsRepo) CreatePayment(ctx context.Context, payment entities.Payment) (int32, error) {
+	params := db.CreatePaymentParams{
+		PurchaserID: payment.PurchaserID,
+		Amount:      payment.Amount.Amount,
+		Currency:    payment.Amount.Currency,
+		Status:      payment.Status,
+	}
+	return r.queries.CreatePayment(ctx, params)
+}
+
+// UpdatePaymentStatus updates the payment's status, along with its reference
+// and decline reason if they're set, if the payment's current status is
+// `from`. Otherwise, `ErrNoSuchEntity` is returned.
+func (r *PaymentsRepo) UpdatePaymentStatus(ctx context.Context, payment entities.Payment, from string) error {
+	params := db.UpdatePaymentStatusParams{
+		Status:        payment.Status,
+		Reference:     MapNullableString(payment.Reference),
+		DeclineReason: MapNullableString(payment.DeclineReason),
+		PaymentID:     payment.ID,
+		FromStatus:    from,
+	}
+	countUpdated, err := r.queries.UpdatePaymentStatus(ctx, params)
+	if err != nil {
+		return err
+	}
+	if countUpdated == 0 {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
+// GetStaleAuthorizedPayments fetches up to `limit` authorized payments that
+// haven't been updated since `before`, oldest first.
+func (r *PaymentsRepo) GetStaleAuthorizedPayments(
+	ctx context.Context,
+	before time.Time,
+	limit int32,
+) ([]entities.Payment, error) {
+	params := db.GetStaleAuthorizedPaymentsParams{
+		UpdatedBefore: MapTime(before),
+		MaxPayments:   limit,
+	}
+	rows, err := r.queries.GetStaleAuthorizedPayments(ctx, params)
+	if err != nil {
+		return []entities.Payment{}, err
+	}
+
+	payments := make([]entities.Payment, len(rows))
+	for idx, row := range rows {
+		payments[idx] = MapPayment(row)
+	}
+	return payments, nil
+}
+
+type OrdersRepo struct {
+	queries db.Querier
+}
+
+func NewOrdersRepo(conn db.DBTX) *OrdersRepo {
+	return &OrdersRepo{queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewOrdersRepoFromQueries(queries db.Querier) *OrdersRepo {
+	return &OrdersRepo{queries: queries}
+}
+
+// GetOrder fetches the order, given by id, along with its items from the
+// database of record.
+func (r *OrdersRepo) GetOrder(ctx context.Context, id int32) (entities.Order, error) {
+	rows, err := r.queries.GetOrder(ctx, id)
+	if err != nil {
+		return entities.Order{}, err
+	}
+	if len(rows) == 0 {
+		return entities.Order{}, ErrNoSuchEntity
+	}
+
+	return MapGetOrderRows(rows), nil
+}
+
+// GetUserOrders fetches all orders, along with their items, placed by the user
+// given by `userID`, from most to least recent.
+func (r *OrdersRepo) GetUserOrders(ctx context.Context, userID int32) ([]entities.Order, error) {
+	rows, err := r.queries.GetUserOrders(ctx, userID)
+	if err != nil {
+		return []entities.Order{}, err
+	}
+
+	return MapGetUserOrdersRows(rows), nil
+}
+
+type CancellationsRepo struct {
+	Conn    *pgxpool.Pool
+	queries db.Querier
+}
+
+func NewCancellationsRepo(conn *pgxpool.Pool) *CancellationsRepo {
+	return &CancellationsRepo{Conn: conn, queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewCancellationsRepoFromQueries(queries db.Querier) *CancellationsRepo {
+	return &CancellationsRepo{Conn: nil, queries: queries}
+}
+
+// GetPendingCancellationRefunds fetches up to `limit` refunds for cancelled
+// events that have yet to be processed, in the order they were queued.
+func (r *CancellationsRepo) GetPendingCancellationRefunds(
+	ctx context.Context,
+	limit int32,
+) ([]entities.CancellationRefund, error) {
+	rows, err := r.queries.GetPendingCancellationRefunds(ctx, limit)
+	if err != nil {
+		return []entities.CancellationRefund{}, err
+	}
+
+	refunds := make([]entities.CancellationRefund, len(rows))
+	for idx, row := range rows {
+		refunds[idx] = MapCancellationRefund(row)
+	}
+	return refunds, nil
+}
+
+// UpdateCancellationRefund records the outcome of processing a refund for a
+// cancelled event.
+func (r *CancellationsRepo) UpdateCancellationRefund(
+	ctx context.Context,
+	cancellationRefund entities.CancellationRefund,
+) error {
+	return r.queries.UpdateCancellationRefund(ctx, MapUpdateCancellationRefundParams(cancellationRefund))
+}
+
+// CompleteEventCancellations marks cancellations without any pending refunds
+// as completed, and returns how many were completed.
+func (r *CancellationsRepo) CompleteEventCancellations(ctx context.Context) (int64, error) {
+	return r.queries.CompleteEventCancellations(ctx)
+}
+
+// GetEventCancellation fetches the cancellation of the event given by id, with
+// counts of its refunds by status. If the event hasn't been cancelled,
+// `ErrNoSuchEntity` is returned.
+func (r *CancellationsRepo) GetEventCancellation(ctx context.Context, eventID int32) (entities.EventCancellation, error) {
+	row, err := r.queries.GetEventCancellation(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.EventCancellation{}, ErrNoSuchEntity
+		}
+		return entities.EventCancellation{}, err
+	}
+	return MapGetEventCancellationRow(row), nil
+}
+
+func (r *CancellationsRepo) ExecRefundCancelledTicket(
+	ctx context.Context,
+	queries db.Querier,
+	cancellationRefund entities.CancellationRefund,
+	purchase entities.TicketPurchase,
+) (entities.Refund, error) {
+	// The event won't go ahead, so the order's facility fee is refunded
+	// whether or not the rest of its tickets are.
+	record, err := execRefundTicket(ctx, queries, purchase, false, true)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+
+	cancellationRefund.Status = entities.CancellationRefundStatusRefunded
+	cancellationRefund.Attempts++
+	cancellationRefund.LastError = ""
+	cancellationRefund.RefundID = record.ID
+	err = queries.UpdateCancellationRefund(ctx, MapUpdateCancellationRefundParams(cancellationRefund))
+	if err != nil {
+		return entities.Refund{}, err
+	}
+	return record, nil
+}
+
+// RefundCancelledTicket voids a ticket purchased for a cancelled event, records
+// a pending refund of the price paid for it, along with its order's facility
+// fee and its tax if they haven't been refunded, and marks the cancellation's
+// refund as refunded, in a single transaction. The refund is to be made with
+// the payment processor after the transaction is committed. If the ticket is
+// no longer purchased by the purchaser, nothing is written and
+// `ErrNoSuchEntity` is returned.
+func (r *CancellationsRepo) RefundCancelledTicket(
+	ctx context.Context,
+	cancellationRefund entities.CancellationRefund,
+	purchase entities.TicketPurchase,
+) (entities.Refund, error) {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.New(tx)
+	record, err := r.ExecRefundCancelledTicket(ctx, qtx, cancellationRefund, purchase)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+
+	err = tx.Commit(ctx)
+	return record, err
+}
+
+type PricingRepo struct {
+	queries db.Querier
+}
+
+func NewPricingRepo(conn db.DBTX) *PricingRepo {
+	return &PricingRepo{queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewPricingRepoFromQueries(queries db.Querier) *PricingRepo {
+	return &PricingRepo{queries: queries}
+}
+
+// GetApplicableFeeRule fetches the fee rule that applies to the event given by
+// id. If neither the event nor its venue has a rule, no fees are charged.
+func (r *PricingRepo) GetApplicableFeeRule(ctx context.Context, eventID int32) (entities.FeeRule, error) {
+	row, err := r.queries.GetApplicableFeeRule(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.FeeRule{EventID: eventID}, nil
+		}
+		return entities.FeeRule{}, err
+	}
+	return MapFeeRule(row), nil
+}
+
+// GetEventTaxRate fetches the tax rate, in basis points, for the region of the
+// venue of the event given by id. Regions without a tax rate aren't taxed.
+func (r *PricingRepo) GetEventTaxRate(ctx context.Context, eventID int32) (int32, error) {
+	rate, err := r.queries.GetEventTaxRate(ctx, eventID)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return rate, err
+}
+
+// GetVenueFeeRule fetches the fee rule set for the venue given by id.
+func (r *PricingRepo) GetVenueFeeRule(ctx context.Context, venueID int32) (entities.FeeRule, error) {
+	row, err := r.queries.GetVenueFeeRule(ctx, venueID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.FeeRule{}, ErrNoSuchEntity
+		}
+		return entities.FeeRule{}, err
+	}
+	return MapFeeRule(row), nil
+}
+
+// GetEventFeeRule fetches the fee rule set for the event given by id. The
+// event's venue's rule isn't considered.
+func (r *PricingRepo) GetEventFeeRule(ctx context.Context, eventID int32) (entities.FeeRule, error) {
+	row, err := r.queries.GetEventFeeRule(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.FeeRule{}, ErrNoSuchEntity
+		}
+		return entities.FeeRule{}, err
+	}
+	return MapFeeRule(row), nil
+}
+
+// SetVenueFeeRule creates or replaces the fee rule for the rule's venue.
+func (r *PricingRepo) SetVenueFeeRule(ctx context.Context, rule entities.FeeRule) error {
+	_, err := r.queries.UpsertVenueFeeRule(ctx, db.UpsertVenueFeeRuleParams{
+		ServiceFeeRate: rule.ServiceFeeRate,
+		ServiceFeeFlat: rule.ServiceFeeFlat,
+		FacilityFee:    rule.FacilityFee,
+		VenueID:        rule.VenueID,
+	})
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return ErrNoSuchEntity
+	}
+	return err
+}
+
+// SetEventFeeRule creates or replaces the fee rule for the rule's event.
+func (r *PricingRepo) SetEventFeeRule(ctx context.Context, rule entities.FeeRule) error {
+	_, err := r.queries.UpsertEventFeeRule(ctx, db.UpsertEventFeeRuleParams{
+		ServiceFeeRate: rule.ServiceFeeRate,
+		ServiceFeeFlat: rule.ServiceFeeFlat,
+		FacilityFee:    rule.FacilityFee,
+		EventID:        rule.EventID,
+	})
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return ErrNoSuchEntity
+	}
+	return err
+}
+
+// SetTaxRate creates or replaces the tax rate for the rate's region.
+func (r *PricingRepo) SetTaxRate(ctx context.Context, rate entities.TaxRate) error {
+	return r.queries.UpsertTaxRate(ctx, db.UpsertTaxRateParams{
+		CountryCode: rate.CountryCode,
+		Subdivision: rate.Subdivision,
+		Rate:        rate.Rate,
+	})
+}
+
+type PromoCodesRepo struct {
+	queries db.Querier
+}
+
+func NewPromoCodesRepo(conn db.DBTX) *PromoCodesRepo {
+	return &PromoCodesRepo{queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewPromoCodesRepoFromQueries(queries db.Querier) *PromoCodesRepo {
+	return &PromoCodesRepo{queries: queries}
+}
+
+// CreatePromoCode inserts a new promo code into the database of record and
+// returns its id, if successful. If the code is for an event that doesn't
+// exist, `ErrNoSuchEntity` is returned.
+func (r *PromoCodesRepo) CreatePromoCode(ctx context.Context, promoCode entities.PromoCode) (int32, error) {
+	params := db.CreatePromoCodeParams{
+		Code:                  promoCode.Code,
+		DiscountType:          promoCode.DiscountType,
+		PercentOff:            promoCode.PercentOff,
+		AmountOff:             promoCode.AmountOff.Amount,
+		Currency:              MapNullableString(promoCode.AmountOff.Currency),
+		EventID:               MapNullableID(promoCode.EventID),
+		StartsAt:              MapNullableTime(promoCode.StartsAt),
+		EndsAt:                MapNullableTime(promoCode.EndsAt),
+		MaxRedemptions:        MapNullableInt(promoCode.MaxRedemptions),
+		MaxRedemptionsPerUser: MapNullableInt(promoCode.MaxRedemptionsPerUser),
+	}
+	id, err := r.queries.CreatePromoCode(ctx, params)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return id, ErrNoSuchEntity
+	}
+	return id, err
+}
+
+// GetPromoCode fetches the promo code, given by id, from the database of
+// record.
+func (r *PromoCodesRepo) GetPromoCode(ctx context.Context, id int32) (entities.PromoCode, error) {
+	row, err := r.queries.GetPromoCode(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.PromoCode{}, ErrNoSuchEntity
+		}
+		return entities.PromoCode{}, err
+	}
+	return MapPromoCode(row), nil
+}
+
+// GetPromoCodeByCode fetches the promo code with the given code from the
+// database of record.
+func (r *PromoCodesRepo) GetPromoCodeByCode(ctx context.Context, code string) (entities.PromoCode, error) {
+	row, err := r.queries.GetPromoCodeByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.PromoCode{}, ErrNoSuchEntity
+		}
+		return entities.PromoCode{}, err
+	}
+	return MapPromoCode(row), nil
+}
+
+// GetUserPromoCodeRedemptions fetches the number of times that the user has
+// redeemed the promo code.
+func (r *PromoCodesRepo) GetUserPromoCodeRedemptions(
+	ctx context.Context,
+	promoCodeID int32,
+	userID int32,
+) (int32, error) {
+	params := db.GetUserPromoCodeRedemptionsParams{PromoCodeID: promoCodeID, PurchaserID: userID}
+	redemptions, err := r.queries.GetUserPromoCodeRedemptions(ctx, params)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return redemptions, err
+}
+
+// UpdatePromoCode updates an existing promo code in the database of record. If
+// the code is for an event that doesn't exist, `ErrNoSuchEntity` is returned.
+func (r *PromoCodesRepo) UpdatePromoCode(ctx context.Context, promoCode entities.PromoCode) error {
+	params := db.UpdatePromoCodeParams{
+		Code:                  promoCode.Code,
+		DiscountType:          promoCode.DiscountType,
+		PercentOff:            promoCode.PercentOff,
+		AmountOff:             promoCode.AmountOff.Amount,
+		Currency:              MapNullableString(promoCode.AmountOff.Currency),
+		EventID:               MapNullableID(promoCode.EventID),
+		StartsAt:              MapNullableTime(promoCode.StartsAt),
+		EndsAt:                MapNullableTime(promoCode.EndsAt),
+		MaxRedemptions:        MapNullableInt(promoCode.MaxRedemptions),
+		MaxRedemptionsPerUser: MapNullableInt(promoCode.MaxRedemptionsPerUser),
+		PromoCodeID:           promoCode.ID,
+	}
+
+	if _, err := r.queries.UpdatePromoCode(ctx, params); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoSuchEntity
+		}
+		return err
+	}
+	return nil
+}
+
+// DeletePromoCode marks a promo code as deleted in the database of record.
+func (r *PromoCodesRepo) DeletePromoCode(ctx context.Context, id int32) error {
+	countDeleted, err := r.queries.DeletePromoCode(ctx, id)
+	if err != nil {
+		return err
+	}
+	if countDeleted == 0 {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
+type IdempotencyRepo struct {
+	queries db.Querier
+}
+
+func NewIdempotencyRepo(conn db.DBTX) *IdempotencyRepo {
+	return &IdempotencyRepo{queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewIdempotencyRepoFromQueries(queries db.Querier) *IdempotencyRepo {
+	return &IdempotencyRepo{queries: queries}
+}
+
+// ClaimIdempotencyKey claims the key for a request until `expiresAt`, and
+// returns the claimed key's id. If the key has already been claimed, and
+// hasn't expired, the key isn't claimed and `false` is returned.
+func (r *IdempotencyRepo) ClaimIdempotencyKey(
+	ctx context.Context,
+	key entities.IdempotencyKey,
+	expiresAt time.Time,
+) (int32, bool, error) {
+	params := db.ClaimIdempotencyKeyParams{
+		UserID:      key.UserID,
+		Key:         key.Key,
+		Fingerprint: key.Fingerprint,
+		ExpiresAt:   MapTime(expiresAt),
+	}
+	id, err := r.queries.ClaimIdempotencyKey(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// GetIdempotencyKey fetches the user's key, if it hasn't expired. Otherwise,
+// `ErrNoSuchEntity` is returned.
+func (r *IdempotencyRepo) GetIdempotencyKey(ctx context.Context, userID string, key string) (entities.IdempotencyKey, error) {
+	params := db.GetIdempotencyKeyParams{UserID: userID, Key: key}
+	row, err := r.queries.GetIdempotencyKey(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.IdempotencyKey{}, ErrNoSuchEntity
+		}
+		return entities.IdempotencyKey{}, err
+	}
+	return MapIdempotencyKey(row), nil
+}
+
+// CompleteIdempotencyKey records the response to the key's request.
+func (r *IdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, key entities.IdempotencyKey) error {
+	params := db.CompleteIdempotencyKeyParams{
+		StatusCode:       pgtype.Int4{Int32: int32(key.StatusCode), Valid: true},
+		ContentType:      MapNullableString(key.ContentType),
+		ResponseBody:     key.ResponseBody,
+		IdempotencyKeyID: key.ID,
+	}
+	return r.queries.CompleteIdempotencyKey(ctx, params)
+}
+
+// DeleteIdempotencyKey deletes the key given by id, so that it can be claimed
+// again.
+func (r *IdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, id int32) error {
+	return r.queries.DeleteIdempotencyKey(ctx, id)
+}
+
+// DeleteExpiredIdempotencyKeys deletes all expired keys, and returns how many
+// were deleted.
+func (r *IdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return r.queries.DeleteExpiredIdempotencyKeys(ctx)
+}
+
+type WaitingRoomsRepo struct {
+	queries db.Querier
+}
+
+func NewWaitingRoomsRepo(conn db.DBTX) *WaitingRoomsRepo {
+	return &WaitingRoomsRepo{queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewWaitingRoomsRepoFromQueries(queries db.Querier) *WaitingRoomsRepo {
+	return &WaitingRoomsRepo{queries: queries}
+}
+
+// GetWaitingRoom fetches the waiting room of the event given by id. If the
+// event doesn't have a waiting room, `ErrNoSuchEntity` is returned.
+func (r *WaitingRoomsRepo) GetWaitingRoom(ctx context.Context, eventID int32) (entities.WaitingRoom, error) {
+	row, err := r.queries.GetWaitingRoom(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.WaitingRoom{}, ErrNoSuchEntity
+		}
+		return entities.WaitingRoom{}, err
+	}
+	return MapWaitingRoom(row), nil
+}
+
+// GetWaitingRooms fetches the waiting rooms of all events that have one.
+func (r *WaitingRoomsRepo) GetWaitingRooms(ctx context.Context) ([]entities.WaitingRoom, error) {
+	rows, err := r.queries.GetWaitingRooms(ctx)
+	if err != nil {
+		return []entities.WaitingRoom{}, err
+	}
+
+	rooms := make([]entities.WaitingRoom, len(rows))
+	for idx, row := range rows {
+		rooms[idx] = MapWaitingRoom(row)
+	}
+	return rooms, nil
+}
+
+// SetWaitingRoom creates or replaces the waiting room for the room's event.
+func (r *WaitingRoomsRepo) SetWaitingRoom(ctx context.Context, room entities.WaitingRoom) error {
+	_, err := r.queries.UpsertWaitingRoom(ctx, db.UpsertWaitingRoomParams{
+		BatchSize: room.BatchSize,
+		EventID:   room.EventID,
+	})
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return ErrNoSuchEntity
+	}
+	return err
+}
+
+// DeleteWaitingRoom removes the waiting room of the event given by id.
+func (r *WaitingRoomsRepo) DeleteWaitingRoom(ctx context.Context, eventID int32) error {
+	countDeleted, err := r.queries.DeleteWaitingRoom(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if countDeleted == 0 {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
+type WaitlistsRepo struct {
+	queries db.Querier
+}
+
+func NewWaitlistsRepo(conn db.DBTX) *WaitlistsRepo {
+	return &WaitlistsRepo{queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewWaitlistsRepoFromQueries(queries db.Querier) *WaitlistsRepo {
+	return &WaitlistsRepo{queries: queries}
+}
+
+// JoinWaitlist adds the entry's user to the waitlist of the entry's event, or
+// changes the quantity they're waiting for if they're already waiting.
+func (r *WaitlistsRepo) JoinWaitlist(ctx context.Context, entry entities.WaitlistEntry) error {
+	_, err := r.queries.UpsertWaitlistEntry(ctx, db.UpsertWaitlistEntryParams{
+		UserID:   entry.UserID,
+		Quantity: entry.Quantity,
+		EventID:  entry.EventID,
+	})
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return ErrNoSuchEntity
+	}
+	return err
+}
+
+// GetWaitlistEntry fetches the user's entry on the event's waitlist, along
+// with their position. If the user isn't on the waitlist, `ErrNoSuchEntity` is
+// returned.
+func (r *WaitlistsRepo) GetWaitlistEntry(
+	ctx context.Context,
+	eventID int32,
+	userID int32,
+) (entities.WaitlistEntry, error) {
+	row, err := r.queries.GetWaitlistEntry(ctx, db.GetWaitlistEntryParams{EventID: eventID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.WaitlistEntry{}, ErrNoSuchEntity
+		}
+		return entities.WaitlistEntry{}, err
+	}
+	return MapGetWaitlistEntryRow(row), nil
+}
+
+// LeaveWaitlist removes the user from the event's waitlist.
+func (r *WaitlistsRepo) LeaveWaitlist(ctx context.Context, eventID int32, userID int32) error {
+	countDeleted, err := r.queries.DeleteWaitlistEntry(ctx, db.DeleteWaitlistEntryParams{
+		EventID: eventID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return err
+	}
+	if countDeleted == 0 {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
+// GetWaitlistedEvents fetches the ids of the events that have users waiting on
+// their waitlist.
+func (r *WaitlistsRepo) GetWaitlistedEvents(ctx context.Context) ([]int32, error) {
+	eventIDs, err := r.queries.GetWaitlistedEvents(ctx)
+	if err != nil {
+		return []int32{}, err
+	}
+	return eventIDs, nil
+}
+
+// GetWaitingEntries fetches up to `maxEntries` of the entries of the users
+// waiting on the event's waitlist, in the order they joined.
+func (r *WaitlistsRepo) GetWaitingEntries(
+	ctx context.Context,
+	eventID int32,
+	maxEntries int32,
+) ([]entities.WaitlistEntry, error) {
+	rows, err := r.queries.GetWaitingEntries(ctx, db.GetWaitingEntriesParams{
+		EventID:    eventID,
+		MaxEntries: maxEntries,
+	})
+	if err != nil {
+		return []entities.WaitlistEntry{}, err
+	}
+
+	entries := make([]entities.WaitlistEntry, len(rows))
+	for idx, row := range rows {
+		entries[idx] = MapWaitlistEntry(row)
+	}
+	return entries, nil
+}
+
+// SetWaitlistOffer records the offer made to the entry's user, by the entry's
+// offer token and expiration. If the user is no longer waiting, e.g. as they
+// left the waitlist, `ErrNoSuchEntity` is returned.
+func (r *WaitlistsRepo) SetWaitlistOffer(ctx context.Context, entry entities.WaitlistEntry) error {
+	countUpdated, err := r.queries.SetWaitlistOffer(ctx, db.SetWaitlistOfferParams{
+		OfferToken:     MapNullableString(entry.OfferToken),
+		OfferExpiresAt: MapNullableTime(entry.OfferExpiresAt),
+		EventID:        entry.EventID,
+		UserID:         entry.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	if countUpdated == 0 {
+		return ErrNoSuchEntity
+	}
+	return nil
+}
+
+type TransfersRepo struct {
+	Conn    *pgxpool.Pool
+	queries db.Querier
+}
+
+func NewTransfersRepo(conn *pgxpool.Pool) *TransfersRepo {
+	return &TransfersRepo{Conn: conn, queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewTransfersRepoFromQueries(queries db.Querier) *TransfersRepo {
+	return &TransfersRepo{Conn: nil, queries: queries}
+}
+
+// GetTicketOwnership fetches who owns the ticket given by id, if it's been
+// purchased, and when its event starts. If the ticket doesn't exist, or has
+// been voided, `ErrNoSuchEntity` is returned.
+func (r *TransfersRepo) GetTicketOwnership(ctx context.Context, ticketID int32) (entities.TicketOwnership, error) {
+	row, err := r.queries.GetTicketOwnership(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketOwnership{}, ErrNoSuchEntity
+		}
+		return entities.TicketOwnership{}, err
+	}
+	return MapGetTicketOwnershipRow(row), nil
+}
+
+// CreateTicketTransfer creates a pending transfer of the ticket from the
+// sender to the recipient. If the recipient doesn't exist, `ErrNoSuchUser` is
+// returned.
+func (r *TransfersRepo) CreateTicketTransfer(
+	ctx context.Context,
+	transfer entities.TicketTransfer,
+) (entities.TicketTransfer, error) {
+	row, err := r.queries.CreateTicketTransfer(ctx, db.CreateTicketTransferParams{
+		TicketID:    transfer.TicketID,
+		SenderID:    transfer.SenderID,
+		RecipientID: transfer.RecipientID,
+	})
+	if err != nil {
+		// The ticket and its owner exist, so the only foreign key that can be
+		// violated is for the recipient.
+		if isForeignKeyViolation(err) {
+			return entities.TicketTransfer{}, ErrNoSuchUser
+		}
+		return entities.TicketTransfer{}, err
+	}
+	return MapTicketTransfer(row), nil
+}
+
+// GetTicketTransfer fetches the transfer given by id.
+func (r *TransfersRepo) GetTicketTransfer(ctx context.Context, id int32) (entities.TicketTransfer, error) {
+	row, err := r.queries.GetTicketTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketTransfer{}, ErrNoSuchEntity
+		}
+		return entities.TicketTransfer{}, err
+	}
+	return MapTicketTransfer(row), nil
+}
+
+// GetPendingTicketTransfer fetches the pending transfer of the ticket given by
+// id. If the ticket doesn't have a pending transfer, `ErrNoSuchEntity` is
+// returned.
+func (r *TransfersRepo) GetPendingTicketTransfer(ctx context.Context, ticketID int32) (entities.TicketTransfer, error) {
+	row, err := r.queries.GetPendingTicketTransfer(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketTransfer{}, ErrNoSuchEntity
+		}
+		return entities.TicketTransfer{}, err
+	}
+	return MapTicketTransfer(row), nil
+}
+
+// GetTicketTransfers fetches the transfer history of the ticket given by id,
+// most recent first.
+func (r *TransfersRepo) GetTicketTransfers(ctx context.Context, ticketID int32) ([]entities.TicketTransfer, error) {
+	rows, err := r.queries.GetTicketTransfers(ctx, ticketID)
+	if err != nil {
+		return []entities.TicketTransfer{}, err
+	}
+	return MapTicketTransfers(rows), nil
+}
+
+// GetUserTicketTransfers fetches the transfers sent or received by the user
+// given by id, most recent first.
+func (r *TransfersRepo) GetUserTicketTransfers(ctx context.Context, userID int32) ([]entities.TicketTransfer, error) {
+	rows, err := r.queries.GetUserTicketTransfers(ctx, userID)
+	if err != nil {
+		return []entities.TicketTransfer{}, err
+	}
+	return MapTicketTransfers(rows), nil
+}
+
+// CancelTicketTransfer cancels the transfer given by id. If the transfer isn't
+// pending, `ErrNoSuchEntity` is returned.
+func (r *TransfersRepo) CancelTicketTransfer(ctx context.Context, id int32) (entities.TicketTransfer, error) {
+	row, err := r.queries.UpdateTicketTransferStatus(ctx, db.UpdateTicketTransferStatusParams{
+		Status:     entities.TransferStatusCancelled,
+		TransferID: id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketTransfer{}, ErrNoSuchEntity
+		}
+		return entities.TicketTransfer{}, err
+	}
+	return MapTicketTransfer(row), nil
+}
+
+func (r *TransfersRepo) ExecAcceptTicketTransfer(
+	ctx context.Context,
+	queries db.Querier,
+	transfer entities.TicketTransfer,
+) (entities.TicketTransfer, error) {
+	// Lock the recipient before moving the ticket to them, so that their
+	// tickets can be counted against the event's purchase limit, as for a
+	// purchase.
+	if err := queries.LockPurchaser(ctx, transfer.RecipientID); err != nil {
+		return entities.TicketTransfer{}, err
+	}
+
+	row, err := queries.UpdateTicketTransferStatus(ctx, db.UpdateTicketTransferStatusParams{
+		Status:     entities.TransferStatusAccepted,
+		TransferID: transfer.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketTransfer{}, ErrNoSuchEntity
+		}
+		return entities.TicketTransfer{}, err
+	}
+
+	countUpdated, err := queries.TransferTicket(ctx, db.TransferTicketParams{
+		TicketID:    transfer.TicketID,
+		SenderID:    transfer.SenderID,
+		RecipientID: MapPurchaserID(transfer.RecipientID),
+	})
+	if err != nil {
+		return entities.TicketTransfer{}, err
+	}
+	if countUpdated == 0 {
+		return entities.TicketTransfer{}, ErrNoSuchEntity
+	}
+
+	limitsParams := db.GetExceededPurchaseLimitsParams{
+		TicketIds:   []int32{transfer.TicketID},
+		PurchaserID: MapPurchaserID(transfer.RecipientID),
+	}
+	exceeded, err := queries.GetExceededPurchaseLimits(ctx, limitsParams)
+	if err != nil {
+		return entities.TicketTransfer{}, err
+	}
+	if len(exceeded) > 0 {
+		return entities.TicketTransfer{}, ErrPurchaseLimitExceeded
+	}
+	return MapTicketTransfer(row), nil
+}
+
+// AcceptTicketTransfer accepts the pending transfer, and moves its ticket to
+// the recipient, in a single transaction. If the transfer is no longer
+// pending, or the ticket can no longer be transferred, as the sender no longer
+// owns it or its event has started, nothing is written and `ErrNoSuchEntity`
+// is returned. If the recipient would have more of the event's tickets than
+// its limit on tickets per user, nothing is written and
+// `ErrPurchaseLimitExceeded` is returned.
+func (r *TransfersRepo) AcceptTicketTransfer(
+	ctx context.Context,
+	transfer entities.TicketTransfer,
+) (entities.TicketTransfer, error) {
+	tx, err := r.Conn.Begin(ctx)
+	if err != nil {
+		return entities.TicketTransfer{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.New(tx)
+	accepted, err := r.ExecAcceptTicketTransfer(ctx, qtx, transfer)
+	if err != nil {
+		return entities.TicketTransfer{}, err
+	}
+
+	err = tx.Commit(ctx)
+	return accepted, err
+}
+
+type CredentialsRepo struct {
+	Conn    *pgxpool.Pool
+	queries db.Querier
+}
+
+func NewCredentialsRepo(conn *pgxpool.Pool) *CredentialsRepo {
+	return &CredentialsRepo{Conn: conn, queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewCredentialsRepoFromQueries(queries db.Querier) *CredentialsRepo {
+	return &CredentialsRepo{Conn: nil, queries: queries}
+}
+
+// GetTicketCredential fetches the credential of the ticket given by id for its
+// owner, if it's been purchased. The credential has no nonce if its owner
+// hasn't fetched it yet. If the ticket doesn't exist, or has been voided,
+// `ErrNoSuchEntity` is returned.
+func (r *CredentialsRepo) GetTicketCredential(ctx context.Context, ticketID int32) (entities.TicketCredential, error) {
+	row, err := r.queries.GetTicketCredential(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketCredential{}, ErrNoSuchEntity
+		}
+		return entities.TicketCredential{}, err
+	}
+	return MapGetTicketCredentialRow(row), nil
+}
+
+// CreateTicketCredential creates the credential for its owner with its nonce,
+// unless the owner already has one, in which case the existing credential is
+// kept. Nothing is written if the owner no longer owns the ticket.
+func (r *CredentialsRepo) CreateTicketCredential(ctx context.Context, credential entities.TicketCredential) error {
+	return r.queries.CreateTicketCredential(ctx, db.CreateTicketCredentialParams{
+		TicketID: credential.TicketID,
+		OwnerID:  credential.OwnerID,
+		Nonce:    credential.Nonce,
+	})
+}
+
+// RotateTicketCredential replaces the nonce of the owner's credential with the
+// credential's nonce. If the owner no longer owns the ticket,
+// `ErrNoSuchEntity` is returned.
+func (r *CredentialsRepo) RotateTicketCredential(
+	ctx context.Context,
+	credential entities.TicketCredential,
+) (entities.TicketCredential, error) {
+	row, err := r.queries.RotateTicketCredential(ctx, db.RotateTicketCredentialParams{
+		TicketID: credential.TicketID,
+		OwnerID:  credential.OwnerID,
+		Nonce:    credential.Nonce,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketCredential{}, ErrNoSuchEntity
+		}
+		return entities.TicketCredential{}, err
+	}
+
+	credential.Nonce = row.Nonce
+	credential.RotatedAt = row.RotatedAt.Time
+	return credential, nil
+}
+
+type CheckinsRepo struct {
+	queries db.Querier
+}
+
+func NewCheckinsRepo(conn db.DBTX) *CheckinsRepo {
+	return &CheckinsRepo{queries: db.New(conn)}
+}
+
+// For creating a repo with a mock queries object when testing.
+func NewCheckinsRepoFromQueries(queries db.Querier) *CheckinsRepo {
+	return &CheckinsRepo{queries: queries}
+}
+
+// GetTicketAdmission fetches what the credential of the ticket given by id is
+// checked against when the ticket is scanned. If the ticket doesn't exist, or
+// has been voided, `ErrNoSuchEntity` is returned.
+func (r *CheckinsRepo) GetTicketAdmission(ctx context.Context, ticketID int32) (entities.TicketAdmission, error) {
+	row, err := r.queries.GetTicketAdmission(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.TicketAdmission{}, ErrNoSuchEntity
+		}
+		return entities.TicketAdmission{}, err
+	}
+	return MapGetTicketAdmissionRow(row), nil
+}
+
+// CreateCheckin records the check-in of its ticket. If the ticket has already
+// been checked in, `ErrAlreadyCheckedIn` is returned.
+func (r *CheckinsRepo) CreateCheckin(ctx context.Context, checkin entities.Checkin) (entities.Checkin, error) {
+	row, err := r.queries.CreateCheckin(ctx, db.CreateCheckinParams{
+		TicketID: checkin.TicketID,
+		EventID:  checkin.EventID,
+		OwnerID:  checkin.OwnerID,
+		Gate:     checkin.Gate,
+		Device:   checkin.Device,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.Checkin{}, ErrAlreadyCheckedIn
+		}
+		return entities.Checkin{}, err
+	}
+	return MapCheckin(row), nil
+}
+
+// CreateOfflineCheckin records the check-in of its ticket, as scanned while
+// offline. If the ticket has already been checked in, the earlier of the
+// check-ins is kept, and if that's the existing one, `ErrAlreadyCheckedIn` is
+// returned.
+func (r *CheckinsRepo) CreateOfflineCheckin(ctx context.Context, checkin entities.Checkin) (entities.Checkin, error) {
+	row, err := r.queries.CreateOfflineCheckin(ctx, db.CreateOfflineCheckinParams{
+		TicketID:  checkin.TicketID,
+		EventID:   checkin.EventID,
+		OwnerID:   checkin.OwnerID,
+		Gate:      checkin.Gate,
+		Device:    checkin.Device,
+		ScannedAt: pgtype.Timestamptz{Time: checkin.ScannedAt, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.Checkin{}, ErrAlreadyCheckedIn
+		}
+		return entities.Checkin{}, err
+	}
+	return MapCheckin(row), nil
+}
+
+// GetEventAttendance counts the checked in and purchased tickets of the event
+// given by id. If the event doesn't exist, or has been deleted,
+// `ErrNoSuchEntity` is returned.
+func (r *CheckinsRepo) GetEventAttendance(ctx context.Context, eventID int32) (entities.EventAttendance, error) {
+	row, err := r.queries.GetEventAttendance(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.EventAttendance{}, ErrNoSuchEntity
+		}
+		return entities.EventAttendance{}, err
+	}
+	return entities.EventAttendance{
+		EventID:   row.EventID,
+		CheckedIn: row.CheckedIn,
+		Purchased: row.Purchased,
+	}, nil
+}
+
+// GetScannerTickets fetches the purchased tickets of the event given by id,
+// ordered by id. If the event has none, the event is checked for, so that an
+// event without purchased tickets can be told apart from one that doesn't
+// exist.
+func (r *CheckinsRepo) GetScannerTickets(ctx context.Context, eventID int32) ([]entities.ScannerTicket, error) {
+	rows, err := r.queries.GetScannerTickets(ctx, eventID)
+	if err != nil {
+		return []entities.ScannerTicket{}, err
+	}
+	if len(rows) == 0 {
+		exists, err := r.queries.EventExists(ctx, eventID)
+		if err != nil {
+			return []entities.ScannerTicket{}, err
+		}
+		if !exists {
+			return []entities.ScannerTicket{}, ErrNoSuchEntity
+		}
+		return []entities.ScannerTicket{}, nil
+	}
+	return MapGetScannerTicketsRows(rows), nil
+}
+
+// GetRevokedTickets fetches the ids of the tickets of the event given by id
+// that have been refunded, and haven't been purchased again since, ordered by
+// id.
+func (r *CheckinsRepo) GetRevokedTickets(ctx context.Context, eventID int32) ([]int32, error) {
+	ids, err := r.queries.GetRevokedTickets(ctx, eventID)
+	if err != nil {
+		return []int32{}, err
+	}
+	if ids == nil {
+		return []int32{}, nil
+	}
+	return ids, nil
+}
@@ -9,7 +9,9 @@ import (
 
 	"github.com/dslaw/book-tickets/pkg/db"
 	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
 	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -177,6 +179,110 @@ func TestVenuesRepoDeleteVenueWhenDoesntExistOrDeleted(t *testing.T) {
 	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
 }
 
+func TestVenueLayoutsRepoGetVenueLayoutWhenNoLayout(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetVenueLayout", mock.Anything, venueID).Return([]db.GetVenueLayoutRow{}, nil)
+	mockQueries.On("GetVenue", mock.Anything, venueID).Return(db.GetVenueRow{}, nil)
+
+	repo := repos.NewVenueLayoutsRepoFromQueries(mockQueries)
+	actual, err := repo.GetVenueLayout(context.Background(), venueID)
+
+	assert.Equal(t, entities.VenueLayout{VenueID: venueID, Sections: []entities.VenueSection{}}, actual)
+	assert.Nil(t, err)
+}
+
+func TestVenueLayoutsRepoGetVenueLayoutWhenVenueDoesntExist(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetVenueLayout", mock.Anything, venueID).Return([]db.GetVenueLayoutRow{}, nil)
+	mockQueries.On("GetVenue", mock.Anything, venueID).Return(db.GetVenueRow{}, sql.ErrNoRows)
+
+	repo := repos.NewVenueLayoutsRepoFromQueries(mockQueries)
+	_, err := repo.GetVenueLayout(context.Background(), venueID)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestVenueLayoutsRepoExecSetVenueLayout(t *testing.T) {
+	ctx := context.Background()
+	layout := entities.VenueLayout{
+		VenueID: venueID,
+		Sections: []entities.VenueSection{
+			{Name: "Orchestra", Rows: []entities.VenueRow{
+				{Name: "A", Seats: []entities.VenueSeat{
+					{Number: "1", X: 0, Y: 0, Accessible: true},
+					{Number: "2", X: 1, Y: 0},
+				}},
+			}},
+			{Name: "Balcony", Rows: []entities.VenueRow{
+				{Name: "A", Seats: []entities.VenueSeat{{Number: "1", X: 0, Y: 5, Obstructed: true}}},
+			}},
+		},
+	}
+
+	sectionsParams := db.UpsertVenueSectionsParams{VenueID: venueID, Names: []string{"Orchestra", "Balcony"}}
+	rowsParams := db.UpsertVenueRowsParams{SectionIds: []int32{11, 12}, Names: []string{"A", "A"}}
+	seatsParams := db.UpsertVenueSeatsParams{
+		RowIds:     []int32{21, 21, 22},
+		Numbers:    []string{"1", "2", "1"},
+		Xs:         []float64{0, 1, 0},
+		Ys:         []float64{0, 0, 5},
+		Accessible: []bool{true, false, false},
+		Obstructed: []bool{false, false, true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetVenue", ctx, venueID).Return(db.GetVenueRow{}, nil)
+	mockQueries.On("UpsertVenueSections", ctx, sectionsParams).Return([]db.UpsertVenueSectionsRow{
+		{ID: 12, Name: "Balcony"},
+		{ID: 11, Name: "Orchestra"},
+	}, nil)
+	mockQueries.On("UpsertVenueRows", ctx, rowsParams).Return([]db.VenueRow{
+		{ID: 21, SectionID: 11, Name: "A"},
+		{ID: 22, SectionID: 12, Name: "A"},
+	}, nil)
+	mockQueries.On("UpsertVenueSeats", ctx, seatsParams).Return([]int32{31, 32, 33}, nil)
+	mockQueries.On("TrimVenueSeats", ctx, db.TrimVenueSeatsParams{
+		VenueID: venueID,
+		SeatIds: []int32{31, 32, 33},
+	}).Return(nil)
+	mockQueries.On("TrimVenueRows", ctx, mock.Anything).Return(nil)
+	mockQueries.On("TrimVenueSections", ctx, mock.Anything).Return(nil)
+
+	repo := repos.NewVenueLayoutsRepoFromQueries(mockQueries)
+	err := repo.ExecSetVenueLayout(ctx, mockQueries, layout)
+
+	assert.Nil(t, err)
+	mockQueries.AssertCalled(t, "UpsertVenueSeats", ctx, seatsParams)
+
+	trimmedRows := mockQueries.Calls[len(mockQueries.Calls)-2].Arguments.Get(1).(db.TrimVenueRowsParams)
+	assert.ElementsMatch(t, []int32{21, 22}, trimmedRows.RowIds)
+	trimmedSections := mockQueries.Calls[len(mockQueries.Calls)-1].Arguments.Get(1).(db.TrimVenueSectionsParams)
+	assert.ElementsMatch(t, []int32{11, 12}, trimmedSections.SectionIds)
+}
+
+func TestVenueLayoutsRepoExecSetVenueLayoutWhenVenueDoesntExist(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetVenue", mock.Anything, venueID).Return(db.GetVenueRow{}, sql.ErrNoRows)
+
+	repo := repos.NewVenueLayoutsRepoFromQueries(mockQueries)
+	err := repo.ExecSetVenueLayout(context.Background(), mockQueries, entities.VenueLayout{VenueID: venueID})
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	mockQueries.AssertNotCalled(t, "UpsertVenueSections", mock.Anything, mock.Anything)
+}
+
+func TestVenueLayoutsRepoDeleteVenueLayoutWhenSeatsHaveTickets(t *testing.T) {
+	fakeErr := &pgconn.PgError{Code: "23503"}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("DeleteVenueLayout", mock.Anything, venueID).Return(int64(0), fakeErr)
+
+	repo := repos.NewVenueLayoutsRepoFromQueries(mockQueries)
+	err := repo.DeleteVenueLayout(context.Background(), venueID)
+
+	assert.ErrorIs(t, err, repos.ErrEntityInUse)
+}
+
 func TestEventsRepoExecCreateEvent(t *testing.T) {
 	ctx := context.Background()
 	startsAt, _ := time.Parse(time.DateOnly, "2020-01-01")
@@ -448,14 +554,14 @@ func TestEventsRepoDeleteEventWhenDoesntExistOrDeleted(t *testing.T) {
 func TestTicketsRepoExecWriteTickets(t *testing.T) {
 	eventID := int32(1)
 	tickets := []entities.Ticket{
-		{EventID: eventID, Price: 10, Seat: "GA"},
-		{EventID: eventID, Price: 10, Seat: "GA"},
-		{EventID: eventID, Price: 20, Seat: "Balcony"},
+		{EventID: eventID, Price: money.New(10, "USD"), Seat: "GA"},
+		{EventID: eventID, Price: money.New(10, "USD"), Seat: "GA"},
+		{EventID: eventID, Price: money.New(20, "USD"), Seat: "Balcony"},
 	}
 	params := []db.WriteNewTicketsParams{
-		{EventID: eventID, Price: 10, Seat: "GA"},
-		{EventID: eventID, Price: 10, Seat: "GA"},
-		{EventID: eventID, Price: 20, Seat: "Balcony"},
+		{EventID: eventID, Price: 10, Currency: "USD", Seat: "GA"},
+		{EventID: eventID, Price: 10, Currency: "USD", Seat: "GA"},
+		{EventID: eventID, Price: 20, Currency: "USD", Seat: "Balcony"},
 	}
 
 	mockQueries := new(MockQuerier)
@@ -474,6 +580,71 @@ func TestTicketsRepoExecWriteTickets(t *testing.T) {
 	mockQueries.AssertCalled(t, "WriteNewTickets", mock.Anything, params)
 }
 
+func TestTicketsRepoExecWriteTicketRelease(t *testing.T) {
+	eventID := int32(1)
+	onSaleAt := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	startsAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	release := entities.TicketRelease{
+		EventID:  eventID,
+		OnSaleAt: onSaleAt,
+		Presales: []entities.Presale{
+			{Name: "Fan Club", StartsAt: startsAt, EndsAt: onSaleAt, AccessCode: "FANS"},
+			{Name: "Members", StartsAt: startsAt, EndsAt: onSaleAt, UserIDs: []int32{1, 2}},
+		},
+	}
+	tickets := []entities.Ticket{{EventID: eventID, Price: money.New(10, "USD"), Seat: "GA"}}
+	params := []db.WriteNewTicketsParams{
+		{
+			EventID:   eventID,
+			Price:     10,
+			Currency:  "USD",
+			Seat:      "GA",
+			ReleaseID: pgtype.Int4{Int32: 2, Valid: true},
+		},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("CreateTicketRelease", mock.Anything, db.CreateTicketReleaseParams{
+		OnSaleAt: pgtype.Timestamptz{Time: onSaleAt, Valid: true},
+		EventID:  eventID,
+	}).Return(int32(2), nil)
+	mockQueries.On("CreatePresale", mock.Anything, db.CreatePresaleParams{
+		ReleaseID:  2,
+		Name:       "Fan Club",
+		StartsAt:   pgtype.Timestamptz{Time: startsAt, Valid: true},
+		EndsAt:     pgtype.Timestamptz{Time: onSaleAt, Valid: true},
+		AccessCode: pgtype.Text{String: "FANS", Valid: true},
+	}).Return(int32(3), nil)
+	mockQueries.On("CreatePresale", mock.Anything, db.CreatePresaleParams{
+		ReleaseID: 2,
+		Name:      "Members",
+		StartsAt:  pgtype.Timestamptz{Time: startsAt, Valid: true},
+		EndsAt:    pgtype.Timestamptz{Time: onSaleAt, Valid: true},
+	}).Return(int32(4), nil)
+	mockQueries.On("AddPresaleUsers", mock.Anything, db.AddPresaleUsersParams{
+		PresaleID: 4,
+		UserIds:   []int32{1, 2},
+	}).Return(nil)
+	mockQueries.On("WriteNewTickets", mock.Anything, params).Return(
+		&db.WriteNewTicketsBatchResults{},
+	)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	releaseID, err := repo.ExecWriteTicketRelease(
+		context.Background(),
+		mockQueries,
+		release,
+		tickets,
+		func(_ repos.QueryRowable) {},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), releaseID)
+	mockQueries.AssertNumberOfCalls(t, "CreatePresale", 2)
+	mockQueries.AssertNumberOfCalls(t, "AddPresaleUsers", 1)
+	mockQueries.AssertCalled(t, "WriteNewTickets", mock.Anything, params)
+}
+
 func TestTicketsRepoGetTicket(t *testing.T) {
 	ticketID := int32(1)
 	row := db.GetTicketRow{
@@ -481,7 +652,8 @@ func TestTicketsRepoGetTicket(t *testing.T) {
 			ID:          ticketID,
 			EventID:     int32(11),
 			PurchaserID: pgtype.Int4{Int32: 0, Valid: false},
-			Price:       int32(25),
+			Price:       25,
+			Currency:    "USD",
 			Seat:        "GA",
 		},
 	}
@@ -497,7 +669,7 @@ func TestTicketsRepoGetTicket(t *testing.T) {
 		EventID:     int32(11),
 		PurchaserID: 0,
 		IsPurchased: false,
-		Price:       uint8(25),
+		Price:       money.New(25, "USD"),
 		Seat:        "GA",
 	}, actual)
 	assert.Nil(t, err)
@@ -519,14 +691,14 @@ func TestTicketsRepoGetAvailableTickets(t *testing.T) {
 	eventID := int32(1)
 	ctx := context.Background()
 	rows := []db.GetAvailableTicketsRow{
-		{Ticket: db.Ticket{ID: 1, EventID: eventID, PurchaserID: pgtype.Int4{Valid: false}, Price: 10, Seat: "GA"}},
-		{Ticket: db.Ticket{ID: 2, EventID: eventID, PurchaserID: pgtype.Int4{Valid: false}, Price: 10, Seat: "GA"}},
-		{Ticket: db.Ticket{ID: 3, EventID: eventID, PurchaserID: pgtype.Int4{Valid: false}, Price: 20, Seat: "Balcony"}},
+		{Ticket: db.Ticket{ID: 1, EventID: eventID, PurchaserID: pgtype.Int4{Valid: false}, Price: 10, Currency: "USD", Seat: "GA"}},
+		{Ticket: db.Ticket{ID: 2, EventID: eventID, PurchaserID: pgtype.Int4{Valid: false}, Price: 10, Currency: "USD", Seat: "GA"}},
+		{Ticket: db.Ticket{ID: 3, EventID: eventID, PurchaserID: pgtype.Int4{Valid: false}, Price: 20, Currency: "USD", Seat: "Balcony"}},
 	}
 	expected := []entities.Ticket{
-		{ID: 1, EventID: eventID, IsPurchased: false, Price: 10, Seat: "GA"},
-		{ID: 2, EventID: eventID, IsPurchased: false, Price: 10, Seat: "GA"},
-		{ID: 3, EventID: eventID, IsPurchased: false, Price: 20, Seat: "Balcony"},
+		{ID: 1, EventID: eventID, IsPurchased: false, Price: money.New(10, "USD"), Seat: "GA"},
+		{ID: 2, EventID: eventID, IsPurchased: false, Price: money.New(10, "USD"), Seat: "GA"},
+		{ID: 3, EventID: eventID, IsPurchased: false, Price: money.New(20, "USD"), Seat: "Balcony"},
 	}
 
 	mockQueries := new(MockQuerier)
@@ -547,6 +719,7 @@ func TestTicketsRepoGetAvailableTicketsWhenEventDoesntExistOrDeleted(t *testing.
 
 	mockQueries := new(MockQuerier)
 	mockQueries.On("GetAvailableTickets", ctx, eventID).Return(rows, nil)
+	mockQueries.On("EventExists", ctx, eventID).Return(false, nil)
 
 	repo := repos.NewTicketsRepoFromQueries(mockQueries)
 	_, err := repo.GetAvailableTickets(ctx, eventID)
@@ -555,6 +728,38 @@ func TestTicketsRepoGetAvailableTicketsWhenEventDoesntExistOrDeleted(t *testing.
 	mockQueries.AssertCalled(t, "GetAvailableTickets", ctx, eventID)
 }
 
+func TestTicketsRepoGetAvailableTicketsWhenSoldOut(t *testing.T) {
+	eventID := int32(1)
+	ctx := context.Background()
+	rows := []db.GetAvailableTicketsRow{}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetAvailableTickets", ctx, eventID).Return(rows, nil)
+	mockQueries.On("EventExists", ctx, eventID).Return(true, nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.GetAvailableTickets(ctx, eventID)
+
+	assert.Nil(t, err)
+	assert.Empty(t, actual)
+	mockQueries.AssertCalled(t, "EventExists", ctx, eventID)
+}
+
+func TestTicketsRepoIssueGATicketsWhenNoCapacityLeft(t *testing.T) {
+	tierID := int32(1)
+	ctx := context.Background()
+	params := db.IssueGaTicketsParams{Quantity: 2, GaTierID: tierID}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("IssueGaTickets", ctx, params).Return([]int32{}, nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.IssueGATickets(ctx, tierID, 2)
+
+	assert.ErrorIs(t, repos.ErrNoCapacityLeft, err)
+	mockQueries.AssertCalled(t, "IssueGaTickets", ctx, params)
+}
+
 func TestTicketsRepoSetTicketPurchaser(t *testing.T) {
 	ctx := context.Background()
 	ticketID := int32(1)
@@ -592,3 +797,1497 @@ func TestTicketsRepoSetTicketPurchaserWhenTicketDoesntExistOrPurchased(t *testin
 	assert.ErrorIs(t, repos.ErrNoSuchEntity, err)
 	mockQueries.AssertCalled(t, "SetTicketPurchaser", ctx, params)
 }
+
+func TestTicketsRepoGetTickets(t *testing.T) {
+	ctx := context.Background()
+	ids := []int32{1, 2}
+	rows := []db.GetTicketsRow{
+		{Ticket: db.Ticket{ID: 1, EventID: eventID, PurchaserID: pgtype.Int4{Valid: false}, Price: 10, Currency: "USD", Seat: "GA"}},
+		{Ticket: db.Ticket{ID: 2, EventID: eventID, PurchaserID: pgtype.Int4{Int32: 1, Valid: true}, Price: 20, Currency: "USD", Seat: "Balcony"}},
+	}
+	expected := []entities.Ticket{
+		{ID: 1, EventID: eventID, IsPurchased: false, Price: money.New(10, "USD"), Seat: "GA"},
+		{ID: 2, EventID: eventID, PurchaserID: 1, IsPurchased: true, Price: money.New(20, "USD"), Seat: "Balcony"},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetTickets", ctx, ids).Return(rows, nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.GetTickets(ctx, ids)
+
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, expected, actual)
+}
+
+func TestTicketsRepoGetTicketsWhenTicketDoesntExist(t *testing.T) {
+	ctx := context.Background()
+	ids := []int32{1, 2}
+	rows := []db.GetTicketsRow{
+		{Ticket: db.Ticket{ID: 1, EventID: eventID, PurchaserID: pgtype.Int4{Valid: false}, Price: 10, Currency: "USD", Seat: "GA"}},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetTickets", ctx, ids).Return(rows, nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.GetTickets(ctx, ids)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestTicketsRepoSetTicketsPurchaser(t *testing.T) {
+	ctx := context.Background()
+	ticketIDs := []int32{1, 2}
+	purchaserID := int32(11)
+	params := db.SetTicketsPurchaserParams{
+		TicketIds:   ticketIDs,
+		PurchaserID: pgtype.Int4{Int32: purchaserID, Valid: true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("SetTicketsPurchaser", ctx, params).Return(int64(2), nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	err := repo.SetTicketsPurchaser(ctx, ticketIDs, purchaserID)
+
+	assert.Nil(t, err)
+	mockQueries.AssertCalled(t, "SetTicketsPurchaser", ctx, params)
+}
+
+func TestTicketsRepoSetTicketsPurchaserWhenTicketPurchased(t *testing.T) {
+	ctx := context.Background()
+	ticketIDs := []int32{1, 2}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("SetTicketsPurchaser", ctx, mock.Anything).Return(int64(0), nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	err := repo.SetTicketsPurchaser(ctx, ticketIDs, int32(11))
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestTicketsRepoExecPurchaseTickets(t *testing.T) {
+	ctx := context.Background()
+	quote := entities.Quote{
+		Items: []entities.QuoteItem{
+			{
+				TicketID:   1,
+				FaceValue:  money.New(10, "USD"),
+				ServiceFee: money.New(1, "USD"),
+				Tax:        money.New(2, "USD"),
+				Total:      money.New(13, "USD"),
+			},
+			{
+				TicketID:   2,
+				FaceValue:  money.New(20, "USD"),
+				ServiceFee: money.New(2, "USD"),
+				Tax:        money.New(3, "USD"),
+				Total:      money.New(25, "USD"),
+			},
+		},
+		FaceValue:   money.New(30, "USD"),
+		ServiceFees: money.New(3, "USD"),
+		FacilityFee: money.New(5, "USD"),
+		Tax:         money.New(6, "USD"),
+		Total:       money.New(44, "USD"),
+	}
+	paymentID := int32(3)
+	orderID := int32(4)
+	purchaserID := int32(11)
+	paymentRecord := entities.Payment{
+		ID:          paymentID,
+		PurchaserID: purchaserID,
+		Amount:      money.New(44, "USD"),
+		Status:      entities.PaymentStatusAuthorized,
+		Reference:   "abc",
+	}
+	setTicketsPurchaserParams := db.SetTicketsPurchaserParams{
+		TicketIds:   []int32{1, 2},
+		PurchaserID: pgtype.Int4{Int32: purchaserID, Valid: true},
+	}
+	createOrderParams := db.CreateOrderParams{
+		PurchaserID: purchaserID,
+		PaymentID:   paymentID,
+		Status:      "completed",
+		Total:       44,
+		Currency:    "USD",
+		FacilityFee: 5,
+		Tax:         6,
+	}
+	writeOrderItemsParams := []db.WriteOrderItemsParams{
+		{OrderID: orderID, TicketID: 1, Price: 10, ServiceFee: 1, Tax: 2},
+		{OrderID: orderID, TicketID: 2, Price: 20, ServiceFee: 2, Tax: 3},
+	}
+	updatePaymentStatusParams := db.UpdatePaymentStatusParams{
+		Status:     "captured",
+		PaymentID:  paymentID,
+		FromStatus: "authorized",
+	}
+
+	getExceededPurchaseLimitsParams := db.GetExceededPurchaseLimitsParams{
+		TicketIds:   []int32{1, 2},
+		PurchaserID: pgtype.Int4{Int32: purchaserID, Valid: true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, purchaserID).Return(nil)
+	mockQueries.On("SetTicketsPurchaser", ctx, setTicketsPurchaserParams).Return(int64(2), nil)
+	mockQueries.On("GetExceededPurchaseLimits", ctx, getExceededPurchaseLimitsParams).Return([]int32{}, nil)
+	mockQueries.On("CreateOrder", ctx, createOrderParams).Return(orderID, nil)
+	mockQueries.On("WriteOrderItems", ctx, writeOrderItemsParams).Return(
+		&db.WriteOrderItemsBatchResults{},
+	)
+	mockQueries.On("UpdatePaymentStatus", ctx, updatePaymentStatusParams).Return(int64(1), nil)
+
+	captured := false
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.ExecPurchaseTickets(
+		ctx,
+		mockQueries,
+		quote,
+		paymentRecord,
+		func(ctx context.Context) error {
+			captured = true
+			return nil
+		},
+		func(br repos.Closable) error { return nil },
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, orderID, actual)
+	assert.True(t, captured)
+	mockQueries.AssertCalled(t, "LockPurchaser", ctx, purchaserID)
+	mockQueries.AssertCalled(t, "SetTicketsPurchaser", ctx, setTicketsPurchaserParams)
+	mockQueries.AssertCalled(t, "GetExceededPurchaseLimits", ctx, getExceededPurchaseLimitsParams)
+	mockQueries.AssertCalled(t, "CreateOrder", ctx, createOrderParams)
+	mockQueries.AssertCalled(t, "WriteOrderItems", ctx, writeOrderItemsParams)
+	mockQueries.AssertCalled(t, "UpdatePaymentStatus", ctx, updatePaymentStatusParams)
+}
+
+func TestTicketsRepoExecPurchaseTicketsWhenTicketPurchased(t *testing.T) {
+	ctx := context.Background()
+	quote := entities.Quote{Items: []entities.QuoteItem{{TicketID: 1}, {TicketID: 2}}}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, mock.Anything).Return(nil)
+	mockQueries.On("SetTicketsPurchaser", ctx, mock.Anything).Return(int64(1), nil)
+
+	captured := false
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.ExecPurchaseTickets(
+		ctx,
+		mockQueries,
+		quote,
+		entities.Payment{},
+		func(ctx context.Context) error {
+			captured = true
+			return nil
+		},
+		func(br repos.Closable) error { return nil },
+	)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	assert.False(t, captured)
+	mockQueries.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoExecPurchaseTicketsWhenCaptureFails(t *testing.T) {
+	ctx := context.Background()
+	quote := entities.Quote{Items: []entities.QuoteItem{{TicketID: 1}}}
+	captureErr := errors.New("capture failed")
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, mock.Anything).Return(nil)
+	mockQueries.On("SetTicketsPurchaser", ctx, mock.Anything).Return(int64(1), nil)
+	mockQueries.On("GetExceededPurchaseLimits", ctx, mock.Anything).Return([]int32{}, nil)
+	mockQueries.On("CreateOrder", ctx, mock.Anything).Return(int32(4), nil)
+	mockQueries.On("WriteOrderItems", ctx, mock.Anything).Return(&db.WriteOrderItemsBatchResults{})
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.ExecPurchaseTickets(
+		ctx,
+		mockQueries,
+		quote,
+		entities.Payment{},
+		func(ctx context.Context) error { return captureErr },
+		func(br repos.Closable) error { return nil },
+	)
+
+	assert.ErrorIs(t, err, captureErr)
+	mockQueries.AssertNotCalled(t, "UpdatePaymentStatus", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoExecPurchaseTicketsWhenNoRedemptionsLeft(t *testing.T) {
+	ctx := context.Background()
+	quote := entities.Quote{Items: []entities.QuoteItem{{TicketID: 1}}, PromoCodeID: 7}
+	params := db.RedeemPromoCodeForUserParams{PromoCodeID: 7, PurchaserID: 11}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, mock.Anything).Return(nil)
+	mockQueries.On("SetTicketsPurchaser", ctx, mock.Anything).Return(int64(1), nil)
+	mockQueries.On("GetExceededPurchaseLimits", ctx, mock.Anything).Return([]int32{}, nil)
+	mockQueries.On("RedeemPromoCode", ctx, int32(7)).Return(int64(1), nil)
+	mockQueries.On("RedeemPromoCodeForUser", ctx, params).Return(int64(0), nil)
+
+	captured := false
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.ExecPurchaseTickets(
+		ctx,
+		mockQueries,
+		quote,
+		entities.Payment{PurchaserID: 11},
+		func(ctx context.Context) error {
+			captured = true
+			return nil
+		},
+		func(br repos.Closable) error { return nil },
+	)
+
+	assert.ErrorIs(t, err, repos.ErrNoRedemptionsLeft)
+	assert.False(t, captured)
+	mockQueries.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoExecPurchaseTicketsWhenPurchaseLimitExceeded(t *testing.T) {
+	ctx := context.Background()
+	quote := entities.Quote{Items: []entities.QuoteItem{{TicketID: 1}}}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, int32(11)).Return(nil)
+	mockQueries.On("SetTicketsPurchaser", ctx, mock.Anything).Return(int64(1), nil)
+	mockQueries.On("GetExceededPurchaseLimits", ctx, mock.Anything).Return([]int32{2}, nil)
+
+	captured := false
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.ExecPurchaseTickets(
+		ctx,
+		mockQueries,
+		quote,
+		entities.Payment{PurchaserID: 11},
+		func(ctx context.Context) error {
+			captured = true
+			return nil
+		},
+		func(br repos.Closable) error { return nil },
+	)
+
+	assert.ErrorIs(t, err, repos.ErrPurchaseLimitExceeded)
+	assert.False(t, captured)
+	mockQueries.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoGetTicketResaleTerms(t *testing.T) {
+	startsAt, _ := time.Parse(time.DateOnly, "2020-01-01")
+	row := db.GetTicketResaleTermsRow{
+		Ticket: db.Ticket{
+			ID:          1,
+			EventID:     2,
+			PurchaserID: pgtype.Int4{Int32: 11, Valid: true},
+			Price:       1000,
+			Currency:    "USD",
+			Seat:        "A1",
+		},
+		StartsAt:      pgtype.Timestamptz{Time: startsAt, Valid: true},
+		ResaleMaxRate: pgtype.Int4{Int32: 12000, Valid: true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(row, nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.GetTicketResaleTerms(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(11), actual.Ticket.PurchaserID)
+	assert.True(t, actual.Ticket.IsPurchased)
+	assert.Equal(t, startsAt, actual.EventStartsAt)
+	// An unbounded rate is zero.
+	assert.Equal(t, int32(0), actual.MinRate)
+	assert.Equal(t, int32(12000), actual.MaxRate)
+}
+
+func TestTicketsRepoExecPurchaseResaleListing(t *testing.T) {
+	ctx := context.Background()
+	listing := entities.ResaleListing{
+		ID:       5,
+		TicketID: 1,
+		SellerID: 12,
+		Price:    money.New(15, "USD"),
+		Status:   entities.ResaleListingStatusActive,
+	}
+	quote := entities.Quote{
+		Items: []entities.QuoteItem{
+			{
+				TicketID:   1,
+				FaceValue:  money.New(15, "USD"),
+				ServiceFee: money.New(2, "USD"),
+				Tax:        money.New(1, "USD"),
+				Total:      money.New(18, "USD"),
+			},
+		},
+		FaceValue:   money.New(15, "USD"),
+		ServiceFees: money.New(2, "USD"),
+		Tax:         money.New(1, "USD"),
+		Total:       money.New(18, "USD"),
+	}
+	paymentID := int32(3)
+	orderID := int32(4)
+	purchaserID := int32(11)
+	paymentRecord := entities.Payment{
+		ID:          paymentID,
+		PurchaserID: purchaserID,
+		Amount:      money.New(18, "USD"),
+		Status:      entities.PaymentStatusAuthorized,
+		Reference:   "abc",
+	}
+	statusParams := db.UpdateResaleListingStatusParams{Status: "sold", ListingID: 5}
+	transferParams := db.TransferTicketParams{
+		TicketID:    1,
+		SenderID:    12,
+		RecipientID: pgtype.Int4{Int32: purchaserID, Valid: true},
+	}
+	createOrderParams := db.CreateOrderParams{
+		PurchaserID: purchaserID,
+		PaymentID:   paymentID,
+		Status:      "completed",
+		Total:       18,
+		Currency:    "USD",
+		Tax:         1,
+	}
+	// The seller is owed the listing's price, without the buyer's fees and tax.
+	payoutParams := db.CreateResalePayoutParams{
+		ListingID: 5,
+		SellerID:  12,
+		PaymentID: paymentID,
+		Amount:    15,
+		Currency:  "USD",
+	}
+	updatePaymentStatusParams := db.UpdatePaymentStatusParams{
+		Status:     "captured",
+		PaymentID:  paymentID,
+		FromStatus: "authorized",
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, purchaserID).Return(nil)
+	mockQueries.On("UpdateResaleListingStatus", ctx, statusParams).Return(db.ResaleListing{ID: 5, Status: "sold"}, nil)
+	mockQueries.On("TransferTicket", ctx, transferParams).Return(int64(1), nil)
+	mockQueries.On("GetExceededPurchaseLimits", ctx, mock.Anything).Return([]int32{}, nil)
+	mockQueries.On("CreateOrder", ctx, createOrderParams).Return(orderID, nil)
+	mockQueries.On("WriteOrderItems", ctx, mock.Anything).Return(&db.WriteOrderItemsBatchResults{})
+	mockQueries.On("CreateResalePayout", ctx, payoutParams).Return(int32(6), nil)
+	mockQueries.On("UpdatePaymentStatus", ctx, updatePaymentStatusParams).Return(int64(1), nil)
+
+	captured := false
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.ExecPurchaseResaleListing(
+		ctx,
+		mockQueries,
+		listing,
+		quote,
+		paymentRecord,
+		func(ctx context.Context) error {
+			captured = true
+			return nil
+		},
+		func(br repos.Closable) error { return nil },
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, orderID, actual)
+	assert.True(t, captured)
+	mockQueries.AssertCalled(t, "TransferTicket", ctx, transferParams)
+	mockQueries.AssertCalled(t, "CreateOrder", ctx, createOrderParams)
+	mockQueries.AssertCalled(t, "CreateResalePayout", ctx, payoutParams)
+	mockQueries.AssertCalled(t, "UpdatePaymentStatus", ctx, updatePaymentStatusParams)
+}
+
+func TestTicketsRepoExecPurchaseResaleListingWhenNotActive(t *testing.T) {
+	ctx := context.Background()
+	listing := entities.ResaleListing{ID: 5, TicketID: 1, SellerID: 12}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, mock.Anything).Return(nil)
+	mockQueries.On("UpdateResaleListingStatus", ctx, mock.Anything).Return(db.ResaleListing{}, sql.ErrNoRows)
+
+	captured := false
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.ExecPurchaseResaleListing(
+		ctx,
+		mockQueries,
+		listing,
+		entities.Quote{Items: []entities.QuoteItem{{TicketID: 1}}},
+		entities.Payment{PurchaserID: 11},
+		func(ctx context.Context) error {
+			captured = true
+			return nil
+		},
+		func(br repos.Closable) error { return nil },
+	)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	assert.False(t, captured)
+	mockQueries.AssertNotCalled(t, "TransferTicket", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoExecPurchaseResaleListingWhenNoLongerOwned(t *testing.T) {
+	ctx := context.Background()
+	listing := entities.ResaleListing{ID: 5, TicketID: 1, SellerID: 12}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, mock.Anything).Return(nil)
+	mockQueries.On("UpdateResaleListingStatus", ctx, mock.Anything).Return(db.ResaleListing{ID: 5, Status: "sold"}, nil)
+	mockQueries.On("TransferTicket", ctx, mock.Anything).Return(int64(0), nil)
+
+	captured := false
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.ExecPurchaseResaleListing(
+		ctx,
+		mockQueries,
+		listing,
+		entities.Quote{Items: []entities.QuoteItem{{TicketID: 1}}},
+		entities.Payment{PurchaserID: 11},
+		func(ctx context.Context) error {
+			captured = true
+			return nil
+		},
+		func(br repos.Closable) error { return nil },
+	)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	assert.False(t, captured)
+	mockQueries.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoGetPurchaseLimits(t *testing.T) {
+	ctx := context.Background()
+	params := db.GetPurchaseLimitsParams{
+		PurchaserID: pgtype.Int4{Int32: 11, Valid: true},
+		EventIds:    []int32{1, 2},
+	}
+	rows := []db.GetPurchaseLimitsRow{{EventID: 1, MaxTicketsPerUser: 4, Purchased: 3}}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetPurchaseLimits", ctx, params).Return(rows, nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.GetPurchaseLimits(ctx, []int32{1, 2}, 11)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entities.PurchaseLimit{{EventID: 1, MaxTicketsPerUser: 4, Purchased: 3}}, actual)
+}
+
+func TestTicketsRepoGetTicketPurchase(t *testing.T) {
+	ctx := context.Background()
+	ticketID := int32(1)
+	row := db.GetTicketPurchaseRow{
+		PurchaserID:      pgtype.Int4{Int32: 11, Valid: true},
+		OrderPurchaserID: 11,
+		OrderID:          4,
+		Price:            20,
+		Currency:         "USD",
+		PaymentID:        3,
+		PaymentReference: pgtype.Text{String: "abc", Valid: true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetTicketPurchase", ctx, ticketID).Return(row, nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.GetTicketPurchase(ctx, ticketID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.TicketPurchase{
+		TicketID:         ticketID,
+		PurchaserID:      11,
+		OrderPurchaserID: 11,
+		OrderID:          4,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: "abc",
+	}, actual)
+}
+
+func TestTicketsRepoGetTicketPurchaseWhenNotPurchased(t *testing.T) {
+	ctx := context.Background()
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetTicketPurchase", ctx, int32(1)).Return(db.GetTicketPurchaseRow{}, sql.ErrNoRows)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.GetTicketPurchase(ctx, int32(1))
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestTicketsRepoExecRefundTicket(t *testing.T) {
+	ctx := context.Background()
+	purchase := entities.TicketPurchase{
+		TicketID:         1,
+		PurchaserID:      11,
+		OrderID:          4,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: "abc",
+	}
+	clearParams := db.ClearTicketPurchaserParams{
+		Rerelease:   true,
+		TicketID:    1,
+		PurchaserID: pgtype.Int4{Int32: 11, Valid: true},
+	}
+	refundParams := db.CreateRefundParams{
+		PaymentID: 3,
+		TicketID:  1,
+		Amount:    20,
+	}
+
+	// Another of the order's tickets has yet to be refunded, so the facility
+	// fee isn't refunded with this one.
+	mockQueries := new(MockQuerier)
+	mockQueries.On("ClearTicketPurchaser", ctx, clearParams).Return(int64(1), nil)
+	mockQueries.On("GetUnrefundedFacilityFee", ctx, int32(4)).Return(
+		db.GetUnrefundedFacilityFeeRow{FacilityFee: 5, CountUnrefunded: 2},
+		nil,
+	)
+	mockQueries.On("CreateRefund", ctx, refundParams).Return(int32(5), nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.ExecRefundTicket(ctx, mockQueries, purchase, true)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.Refund{
+		ID:               5,
+		PaymentID:        3,
+		TicketID:         1,
+		Amount:           money.New(20, "USD"),
+		Status:           entities.RefundStatusPending,
+		PaymentReference: "abc",
+	}, actual)
+
+	// The payment isn't refunded until the refund is completed.
+	mockQueries.AssertNotCalled(t, "SetPaymentRefunded", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoExecRefundTicketWhenLastOfOrder(t *testing.T) {
+	ctx := context.Background()
+	purchase := entities.TicketPurchase{
+		TicketID:         1,
+		PurchaserID:      11,
+		OrderID:          4,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: "abc",
+	}
+	refundParams := db.CreateRefundParams{
+		PaymentID:   3,
+		TicketID:    1,
+		Amount:      25,
+		FacilityFee: 5,
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("ClearTicketPurchaser", ctx, mock.Anything).Return(int64(1), nil)
+	mockQueries.On("GetUnrefundedFacilityFee", ctx, int32(4)).Return(
+		db.GetUnrefundedFacilityFeeRow{FacilityFee: 5, CountUnrefunded: 1},
+		nil,
+	)
+	mockQueries.On("CreateRefund", ctx, refundParams).Return(int32(5), nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.ExecRefundTicket(ctx, mockQueries, purchase, false)
+
+	// The facility fee, and its tax, are refunded with the order's last ticket.
+	assert.Nil(t, err)
+	assert.Equal(t, money.New(25, "USD"), actual.Amount)
+	mockQueries.AssertCalled(t, "CreateRefund", ctx, refundParams)
+}
+
+func TestTicketsRepoExecRefundTicketWhenNoLongerPurchased(t *testing.T) {
+	ctx := context.Background()
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("ClearTicketPurchaser", ctx, mock.Anything).Return(int64(0), nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	_, err := repo.ExecRefundTicket(ctx, mockQueries, entities.TicketPurchase{TicketID: 1, PurchaserID: 11}, false)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	mockQueries.AssertNotCalled(t, "CreateRefund", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoExecCompleteRefund(t *testing.T) {
+	ctx := context.Background()
+	refund := entities.Refund{ID: 5, PaymentID: 3, TicketID: 1, Reference: "abc-refund"}
+	params := db.CompleteRefundParams{
+		Reference: pgtype.Text{String: "abc-refund", Valid: true},
+		RefundID:  5,
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("CompleteRefund", ctx, params).Return(int64(1), nil)
+	mockQueries.On("SetPaymentRefunded", ctx, int32(3)).Return(int64(1), nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	err := repo.ExecCompleteRefund(ctx, mockQueries, refund)
+
+	assert.Nil(t, err)
+	mockQueries.AssertCalled(t, "SetPaymentRefunded", ctx, int32(3))
+}
+
+func TestTicketsRepoExecCompleteRefundWhenNotPending(t *testing.T) {
+	ctx := context.Background()
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("CompleteRefund", ctx, mock.Anything).Return(int64(0), nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	err := repo.ExecCompleteRefund(ctx, mockQueries, entities.Refund{ID: 5, PaymentID: 3})
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	mockQueries.AssertNotCalled(t, "SetPaymentRefunded", mock.Anything, mock.Anything)
+}
+
+func TestTicketsRepoGetStalePendingRefunds(t *testing.T) {
+	ctx := context.Background()
+	before := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := db.GetStalePendingRefundsParams{
+		UpdatedBefore: pgtype.Timestamptz{Time: before, Valid: true},
+		MaxRefunds:    10,
+	}
+	rows := []db.GetStalePendingRefundsRow{
+		{
+			ID:               5,
+			PaymentID:        3,
+			TicketID:         1,
+			Amount:           20,
+			Currency:         "USD",
+			PaymentReference: pgtype.Text{String: "abc", Valid: true},
+		},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetStalePendingRefunds", ctx, params).Return(rows, nil)
+
+	repo := repos.NewTicketsRepoFromQueries(mockQueries)
+	actual, err := repo.GetStalePendingRefunds(ctx, before, 10)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entities.Refund{
+		{
+			ID:               5,
+			PaymentID:        3,
+			TicketID:         1,
+			Amount:           money.New(20, "USD"),
+			Status:           entities.RefundStatusPending,
+			PaymentReference: "abc",
+		},
+	}, actual)
+}
+
+func TestPaymentsRepoUpdatePaymentStatus(t *testing.T) {
+	ctx := context.Background()
+	paymentRecord := entities.Payment{
+		ID:            3,
+		Status:        entities.PaymentStatusFailed,
+		Reference:     "abc",
+		DeclineReason: "card_declined",
+	}
+	params := db.UpdatePaymentStatusParams{
+		Status:        "failed",
+		Reference:     pgtype.Text{String: "abc", Valid: true},
+		DeclineReason: pgtype.Text{String: "card_declined", Valid: true},
+		PaymentID:     3,
+		FromStatus:    "pending",
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("UpdatePaymentStatus", ctx, params).Return(int64(1), nil)
+
+	repo := repos.NewPaymentsRepoFromQueries(mockQueries)
+	err := repo.UpdatePaymentStatus(ctx, paymentRecord, entities.PaymentStatusPending)
+
+	assert.Nil(t, err)
+	mockQueries.AssertCalled(t, "UpdatePaymentStatus", ctx, params)
+}
+
+func TestPaymentsRepoUpdatePaymentStatusWhenStatusChanged(t *testing.T) {
+	ctx := context.Background()
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("UpdatePaymentStatus", ctx, mock.Anything).Return(int64(0), nil)
+
+	repo := repos.NewPaymentsRepoFromQueries(mockQueries)
+	err := repo.UpdatePaymentStatus(ctx, entities.Payment{ID: 3}, entities.PaymentStatusAuthorized)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestPaymentsRepoGetStaleAuthorizedPayments(t *testing.T) {
+	ctx := context.Background()
+	before, _ := time.Parse(time.DateOnly, "2020-01-01")
+	params := db.GetStaleAuthorizedPaymentsParams{
+		UpdatedBefore: pgtype.Timestamptz{Time: before, Valid: true},
+		MaxPayments:   10,
+	}
+	rows := []db.Payment{
+		{
+			ID:          1,
+			PurchaserID: 11,
+			Amount:      30,
+			Currency:    "USD",
+			Status:      "authorized",
+			Reference:   pgtype.Text{String: "abc", Valid: true},
+		},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetStaleAuthorizedPayments", ctx, params).Return(rows, nil)
+
+	repo := repos.NewPaymentsRepoFromQueries(mockQueries)
+	actual, err := repo.GetStaleAuthorizedPayments(ctx, before, 10)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entities.Payment{
+		{ID: 1, PurchaserID: 11, Amount: money.New(30, "USD"), Status: "authorized", Reference: "abc"},
+	}, actual)
+}
+
+func TestOrdersRepoGetOrder(t *testing.T) {
+	orderID := int32(1)
+	createdAt, _ := time.Parse(time.DateOnly, "2020-01-01")
+	order := db.Order{
+		ID:          orderID,
+		PurchaserID: 11,
+		PaymentID:   3,
+		Status:      "completed",
+		Total:       35,
+		Currency:    "USD",
+		FacilityFee: 5,
+		Tax:         3,
+		PromoCodeID: pgtype.Int4{Int32: 7, Valid: true},
+		Discount:    3,
+		CreatedAt:   pgtype.Timestamptz{Time: createdAt, Valid: true},
+		UpdatedAt:   pgtype.Timestamptz{Time: createdAt, Valid: true},
+	}
+	rows := []db.GetOrderRow{
+		{
+			Order:          order,
+			ItemTicketID:   pgtype.Int4{Int32: 1, Valid: true},
+			ItemPrice:      pgtype.Int8{Int64: 10, Valid: true},
+			ItemServiceFee: pgtype.Int8{Int64: 0, Valid: true},
+			ItemTax:        pgtype.Int8{Int64: 1, Valid: true},
+			ItemDiscount:   pgtype.Int8{Int64: 1, Valid: true},
+		},
+		{
+			Order:          order,
+			ItemTicketID:   pgtype.Int4{Int32: 2, Valid: true},
+			ItemPrice:      pgtype.Int8{Int64: 20, Valid: true},
+			ItemServiceFee: pgtype.Int8{Int64: 0, Valid: true},
+			ItemTax:        pgtype.Int8{Int64: 2, Valid: true},
+			ItemDiscount:   pgtype.Int8{Int64: 2, Valid: true},
+		},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetOrder", mock.Anything, orderID).Return(rows, nil)
+
+	repo := repos.NewOrdersRepoFromQueries(mockQueries)
+	actual, err := repo.GetOrder(context.Background(), orderID)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, entities.Order{
+		ID:          orderID,
+		PurchaserID: 11,
+		PaymentID:   3,
+		Status:      "completed",
+		Total:       money.New(35, "USD"),
+		PromoCodeID: 7,
+		Discount:    money.New(3, "USD"),
+		FacilityFee: money.New(5, "USD"),
+		Tax:         money.New(3, "USD"),
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+		Items: []entities.OrderItem{
+			{
+				TicketID:   1,
+				Price:      money.New(10, "USD"),
+				Discount:   money.New(1, "USD"),
+				ServiceFee: money.New(0, "USD"),
+				Tax:        money.New(1, "USD"),
+			},
+			{
+				TicketID:   2,
+				Price:      money.New(20, "USD"),
+				Discount:   money.New(2, "USD"),
+				ServiceFee: money.New(0, "USD"),
+				Tax:        money.New(2, "USD"),
+			},
+		},
+	}, actual)
+}
+
+func TestOrdersRepoGetOrderWhenDoesntExist(t *testing.T) {
+	orderID := int32(1)
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetOrder", mock.Anything, orderID).Return([]db.GetOrderRow{}, nil)
+
+	repo := repos.NewOrdersRepoFromQueries(mockQueries)
+	actual, err := repo.GetOrder(context.Background(), orderID)
+
+	assert.Empty(t, actual)
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestCancellationsRepoExecRefundCancelledTicket(t *testing.T) {
+	ctx := context.Background()
+	cancellationRefund := entities.CancellationRefund{
+		ID:             7,
+		CancellationID: 2,
+		TicketID:       1,
+		Status:         entities.CancellationRefundStatusPending,
+		Attempts:       1,
+		LastError:      "gateway unavailable",
+	}
+	purchase := entities.TicketPurchase{
+		TicketID:         1,
+		PurchaserID:      11,
+		OrderID:          4,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: "abc",
+	}
+	clearParams := db.ClearTicketPurchaserParams{
+		Rerelease:   false,
+		TicketID:    1,
+		PurchaserID: pgtype.Int4{Int32: 11, Valid: true},
+	}
+	updateParams := db.UpdateCancellationRefundParams{
+		Status:               "refunded",
+		Attempts:             2,
+		LastError:            pgtype.Text{},
+		RefundID:             pgtype.Int4{Int32: 5, Valid: true},
+		CancellationRefundID: 7,
+	}
+	refundParams := db.CreateRefundParams{
+		PaymentID:   3,
+		TicketID:    1,
+		Amount:      25,
+		FacilityFee: 5,
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("ClearTicketPurchaser", ctx, clearParams).Return(int64(1), nil)
+	mockQueries.On("GetUnrefundedFacilityFee", ctx, int32(4)).Return(
+		db.GetUnrefundedFacilityFeeRow{FacilityFee: 5, CountUnrefunded: 2},
+		nil,
+	)
+	mockQueries.On("CreateRefund", ctx, refundParams).Return(int32(5), nil)
+	mockQueries.On("UpdateCancellationRefund", ctx, updateParams).Return(nil)
+
+	repo := repos.NewCancellationsRepoFromQueries(mockQueries)
+	actual, err := repo.ExecRefundCancelledTicket(ctx, mockQueries, cancellationRefund, purchase)
+
+	// The event was cancelled, so the facility fee is refunded with the first
+	// of the order's tickets.
+	assert.Nil(t, err)
+	assert.Equal(t, int32(5), actual.ID)
+	assert.Equal(t, money.New(25, "USD"), actual.Amount)
+	mockQueries.AssertCalled(t, "ClearTicketPurchaser", ctx, clearParams)
+	mockQueries.AssertCalled(t, "UpdateCancellationRefund", ctx, updateParams)
+}
+
+func TestCancellationsRepoExecRefundCancelledTicketWhenNoLongerPurchased(t *testing.T) {
+	ctx := context.Background()
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("ClearTicketPurchaser", ctx, mock.Anything).Return(int64(0), nil)
+
+	repo := repos.NewCancellationsRepoFromQueries(mockQueries)
+	_, err := repo.ExecRefundCancelledTicket(
+		ctx,
+		mockQueries,
+		entities.CancellationRefund{ID: 7, TicketID: 1},
+		entities.TicketPurchase{TicketID: 1, PurchaserID: 11},
+	)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	mockQueries.AssertNotCalled(t, "UpdateCancellationRefund", mock.Anything, mock.Anything)
+}
+
+func TestCancellationsRepoGetEventCancellation(t *testing.T) {
+	ctx := context.Background()
+	createdAt, _ := time.Parse(time.DateOnly, "2020-01-01")
+	row := db.GetEventCancellationRow{
+		EventCancellation: db.EventCancellation{
+			ID:        2,
+			EventID:   1,
+			Status:    "pending",
+			CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+			UpdatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+		},
+		TotalCount:    4,
+		PendingCount:  1,
+		RefundedCount: 1,
+		FailedCount:   1,
+		SkippedCount:  1,
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetEventCancellation", ctx, int32(1)).Return(row, nil)
+
+	repo := repos.NewCancellationsRepoFromQueries(mockQueries)
+	actual, err := repo.GetEventCancellation(ctx, int32(1))
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.EventCancellation{
+		ID:            2,
+		EventID:       1,
+		Status:        "pending",
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+		TotalCount:    4,
+		PendingCount:  1,
+		RefundedCount: 1,
+		FailedCount:   1,
+		SkippedCount:  1,
+	}, actual)
+}
+
+func TestCancellationsRepoGetEventCancellationWhenNotCancelled(t *testing.T) {
+	ctx := context.Background()
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetEventCancellation", ctx, int32(1)).Return(db.GetEventCancellationRow{}, sql.ErrNoRows)
+
+	repo := repos.NewCancellationsRepoFromQueries(mockQueries)
+	_, err := repo.GetEventCancellation(ctx, int32(1))
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestPricingRepoGetApplicableFeeRule(t *testing.T) {
+	row := db.FeeRule{
+		ID:             1,
+		VenueID:        pgtype.Int4{Int32: venueID, Valid: true},
+		ServiceFeeRate: 1000,
+		ServiceFeeFlat: 50,
+		FacilityFee:    300,
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetApplicableFeeRule", mock.Anything, eventID).Return(row, nil)
+
+	repo := repos.NewPricingRepoFromQueries(mockQueries)
+	actual, err := repo.GetApplicableFeeRule(context.Background(), eventID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.FeeRule{
+		VenueID:        venueID,
+		ServiceFeeRate: 1000,
+		ServiceFeeFlat: 50,
+		FacilityFee:    300,
+	}, actual)
+}
+
+func TestPricingRepoGetApplicableFeeRuleWhenNoRule(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetApplicableFeeRule", mock.Anything, eventID).Return(db.FeeRule{}, sql.ErrNoRows)
+
+	repo := repos.NewPricingRepoFromQueries(mockQueries)
+	actual, err := repo.GetApplicableFeeRule(context.Background(), eventID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.FeeRule{EventID: eventID}, actual)
+}
+
+func TestPricingRepoGetEventTaxRateWhenNoRate(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetEventTaxRate", mock.Anything, eventID).Return(int32(0), sql.ErrNoRows)
+
+	repo := repos.NewPricingRepoFromQueries(mockQueries)
+	actual, err := repo.GetEventTaxRate(context.Background(), eventID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(0), actual)
+}
+
+func TestPricingRepoSetEventFeeRuleWhenEventDoesntExist(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("UpsertEventFeeRule", mock.Anything, mock.Anything).Return(int32(0), sql.ErrNoRows)
+
+	repo := repos.NewPricingRepoFromQueries(mockQueries)
+	err := repo.SetEventFeeRule(context.Background(), entities.FeeRule{EventID: eventID})
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestPromoCodesRepoCreatePromoCode(t *testing.T) {
+	endsAt, _ := time.Parse(time.DateOnly, "2020-01-02")
+	promoCode := entities.PromoCode{
+		Code:           "SUMMER25",
+		DiscountType:   entities.DiscountTypeFixed,
+		AmountOff:      money.New(500, "USD"),
+		EventID:        eventID,
+		EndsAt:         endsAt,
+		MaxRedemptions: 100,
+	}
+	params := db.CreatePromoCodeParams{
+		Code:           "SUMMER25",
+		DiscountType:   "fixed",
+		AmountOff:      500,
+		Currency:       pgtype.Text{String: "USD", Valid: true},
+		EventID:        pgtype.Int4{Int32: eventID, Valid: true},
+		EndsAt:         pgtype.Timestamptz{Time: endsAt, Valid: true},
+		MaxRedemptions: pgtype.Int4{Int32: 100, Valid: true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("CreatePromoCode", mock.Anything, params).Return(int32(1), nil)
+
+	repo := repos.NewPromoCodesRepoFromQueries(mockQueries)
+	actual, err := repo.CreatePromoCode(context.Background(), promoCode)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), actual)
+}
+
+func TestPromoCodesRepoCreatePromoCodeWhenEventDoesntExist(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("CreatePromoCode", mock.Anything, mock.Anything).Return(int32(0), sql.ErrNoRows)
+
+	repo := repos.NewPromoCodesRepoFromQueries(mockQueries)
+	_, err := repo.CreatePromoCode(context.Background(), entities.PromoCode{EventID: eventID})
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestPromoCodesRepoGetPromoCodeByCodeWhenDoesntExist(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetPromoCodeByCode", mock.Anything, "SUMMER25").Return(db.PromoCode{}, sql.ErrNoRows)
+
+	repo := repos.NewPromoCodesRepoFromQueries(mockQueries)
+	_, err := repo.GetPromoCodeByCode(context.Background(), "SUMMER25")
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestPromoCodesRepoGetUserPromoCodeRedemptionsWhenNeverRedeemed(t *testing.T) {
+	params := db.GetUserPromoCodeRedemptionsParams{PromoCodeID: 1, PurchaserID: 11}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetUserPromoCodeRedemptions", mock.Anything, params).Return(int32(0), sql.ErrNoRows)
+
+	repo := repos.NewPromoCodesRepoFromQueries(mockQueries)
+	actual, err := repo.GetUserPromoCodeRedemptions(context.Background(), 1, 11)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(0), actual)
+}
+
+func TestPromoCodesRepoDeletePromoCodeWhenDoesntExist(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("DeletePromoCode", mock.Anything, int32(1)).Return(int64(0), nil)
+
+	repo := repos.NewPromoCodesRepoFromQueries(mockQueries)
+	err := repo.DeletePromoCode(context.Background(), 1)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestIdempotencyRepoClaimIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	expiresAt, _ := time.Parse(time.DateOnly, "2020-01-02")
+	key := entities.IdempotencyKey{UserID: "11", Key: "abc", Fingerprint: "def"}
+	params := db.ClaimIdempotencyKeyParams{
+		UserID:      "11",
+		Key:         "abc",
+		Fingerprint: "def",
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("ClaimIdempotencyKey", ctx, params).Return(int32(1), nil)
+
+	repo := repos.NewIdempotencyRepoFromQueries(mockQueries)
+	id, claimed, err := repo.ClaimIdempotencyKey(ctx, key, expiresAt)
+
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, int32(1), id)
+}
+
+func TestIdempotencyRepoClaimIdempotencyKeyWhenAlreadyClaimed(t *testing.T) {
+	ctx := context.Background()
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("ClaimIdempotencyKey", ctx, mock.Anything).Return(int32(0), sql.ErrNoRows)
+
+	repo := repos.NewIdempotencyRepoFromQueries(mockQueries)
+	_, claimed, err := repo.ClaimIdempotencyKey(ctx, entities.IdempotencyKey{Key: "abc"}, time.Now())
+
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+}
+
+func TestIdempotencyRepoGetIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	params := db.GetIdempotencyKeyParams{UserID: "11", Key: "abc"}
+	row := db.IdempotencyKey{
+		ID:           1,
+		UserID:       "11",
+		Key:          "abc",
+		Fingerprint:  "def",
+		StatusCode:   pgtype.Int4{Int32: 200, Valid: true},
+		ContentType:  pgtype.Text{String: "application/json", Valid: true},
+		ResponseBody: []byte(`{"success":true}`),
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetIdempotencyKey", ctx, params).Return(row, nil)
+
+	repo := repos.NewIdempotencyRepoFromQueries(mockQueries)
+	actual, err := repo.GetIdempotencyKey(ctx, "11", "abc")
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.IdempotencyKey{
+		ID:           1,
+		UserID:       "11",
+		Key:          "abc",
+		Fingerprint:  "def",
+		StatusCode:   200,
+		ContentType:  "application/json",
+		ResponseBody: []byte(`{"success":true}`),
+	}, actual)
+}
+
+func TestWaitlistsRepoGetWaitlistEntry(t *testing.T) {
+	ctx := context.Background()
+	joinedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	params := db.GetWaitlistEntryParams{EventID: 1, UserID: 2}
+	row := db.GetWaitlistEntryRow{
+		WaitlistEntry: db.WaitlistEntry{
+			EventID:  1,
+			UserID:   2,
+			Quantity: 3,
+			JoinedAt: pgtype.Timestamptz{Time: joinedAt, Valid: true},
+		},
+		Ahead: 4,
+	}
+	expected := entities.WaitlistEntry{EventID: 1, UserID: 2, Quantity: 3, JoinedAt: joinedAt, Position: 5}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetWaitlistEntry", ctx, params).Return(row, nil)
+
+	repo := repos.NewWaitlistsRepoFromQueries(mockQueries)
+	actual, err := repo.GetWaitlistEntry(ctx, 1, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestWaitlistsRepoGetWaitlistEntryWhenOffered(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Date(2026, 10, 1, 12, 15, 0, 0, time.UTC)
+	params := db.GetWaitlistEntryParams{EventID: 1, UserID: 2}
+	row := db.GetWaitlistEntryRow{
+		WaitlistEntry: db.WaitlistEntry{
+			EventID:        1,
+			UserID:         2,
+			Quantity:       3,
+			OfferToken:     pgtype.Text{String: "abc", Valid: true},
+			OfferExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		},
+		Ahead: 4,
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetWaitlistEntry", ctx, params).Return(row, nil)
+
+	repo := repos.NewWaitlistsRepoFromQueries(mockQueries)
+	actual, err := repo.GetWaitlistEntry(ctx, 1, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(0), actual.Position)
+	assert.Equal(t, "abc", actual.OfferToken)
+	assert.Equal(t, expiresAt, actual.OfferExpiresAt)
+}
+
+func TestWaitlistsRepoGetWaitlistEntryWhenNotWaitlisted(t *testing.T) {
+	ctx := context.Background()
+	params := db.GetWaitlistEntryParams{EventID: 1, UserID: 2}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetWaitlistEntry", ctx, params).Return(db.GetWaitlistEntryRow{}, sql.ErrNoRows)
+
+	repo := repos.NewWaitlistsRepoFromQueries(mockQueries)
+	_, err := repo.GetWaitlistEntry(ctx, 1, 2)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestWaitlistsRepoSetWaitlistOfferWhenNotWaiting(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Date(2026, 10, 1, 12, 15, 0, 0, time.UTC)
+	entry := entities.WaitlistEntry{EventID: 1, UserID: 2, OfferToken: "abc", OfferExpiresAt: expiresAt}
+	params := db.SetWaitlistOfferParams{
+		OfferToken:     pgtype.Text{String: "abc", Valid: true},
+		OfferExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		EventID:        1,
+		UserID:         2,
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("SetWaitlistOffer", ctx, params).Return(int64(0), nil)
+
+	repo := repos.NewWaitlistsRepoFromQueries(mockQueries)
+	err := repo.SetWaitlistOffer(ctx, entry)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestTransfersRepoGetTicketOwnershipWhenNotPurchased(t *testing.T) {
+	ctx := context.Background()
+	startsAt := time.Date(2026, 11, 1, 20, 0, 0, 0, time.UTC)
+	row := db.GetTicketOwnershipRow{
+		TicketID: 1,
+		StartsAt: pgtype.Timestamptz{Time: startsAt, Valid: true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetTicketOwnership", ctx, int32(1)).Return(row, nil)
+
+	repo := repos.NewTransfersRepoFromQueries(mockQueries)
+	actual, err := repo.GetTicketOwnership(ctx, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.TicketOwnership{TicketID: 1, EventStartsAt: startsAt}, actual)
+}
+
+func TestTransfersRepoCreateTicketTransferWhenRecipientDoesntExist(t *testing.T) {
+	fakeErr := &pgconn.PgError{Code: "23503"}
+	params := db.CreateTicketTransferParams{TicketID: 1, SenderID: 2, RecipientID: 3}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("CreateTicketTransfer", mock.Anything, params).Return(db.TicketTransfer{}, fakeErr)
+
+	repo := repos.NewTransfersRepoFromQueries(mockQueries)
+	_, err := repo.CreateTicketTransfer(
+		context.Background(),
+		entities.TicketTransfer{TicketID: 1, SenderID: 2, RecipientID: 3},
+	)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchUser)
+}
+
+func TestTransfersRepoExecAcceptTicketTransfer(t *testing.T) {
+	ctx := context.Background()
+	transfer := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+	statusParams := db.UpdateTicketTransferStatusParams{Status: "accepted", TransferID: 4}
+	transferParams := db.TransferTicketParams{
+		TicketID:    1,
+		SenderID:    2,
+		RecipientID: pgtype.Int4{Int32: 3, Valid: true},
+	}
+
+	limitsParams := db.GetExceededPurchaseLimitsParams{
+		TicketIds:   []int32{1},
+		PurchaserID: pgtype.Int4{Int32: 3, Valid: true},
+	}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, int32(3)).Return(nil)
+	mockQueries.On("UpdateTicketTransferStatus", ctx, statusParams).Return(
+		db.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "accepted"},
+		nil,
+	)
+	mockQueries.On("TransferTicket", ctx, transferParams).Return(int64(1), nil)
+	mockQueries.On("GetExceededPurchaseLimits", ctx, limitsParams).Return([]int32{}, nil)
+
+	repo := repos.NewTransfersRepoFromQueries(mockQueries)
+	actual, err := repo.ExecAcceptTicketTransfer(ctx, mockQueries, transfer)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.TransferStatusAccepted, actual.Status)
+	mockQueries.AssertCalled(t, "TransferTicket", ctx, transferParams)
+}
+
+func TestTransfersRepoExecAcceptTicketTransferWhenNoLongerOwned(t *testing.T) {
+	ctx := context.Background()
+	transfer := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, int32(3)).Return(nil)
+	mockQueries.On("UpdateTicketTransferStatus", ctx, mock.Anything).Return(
+		db.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "accepted"},
+		nil,
+	)
+	mockQueries.On("TransferTicket", ctx, mock.Anything).Return(int64(0), nil)
+
+	repo := repos.NewTransfersRepoFromQueries(mockQueries)
+	_, err := repo.ExecAcceptTicketTransfer(ctx, mockQueries, transfer)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestTransfersRepoExecAcceptTicketTransferWhenPurchaseLimitExceeded(t *testing.T) {
+	ctx := context.Background()
+	transfer := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("LockPurchaser", ctx, int32(3)).Return(nil)
+	mockQueries.On("UpdateTicketTransferStatus", ctx, mock.Anything).Return(
+		db.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "accepted"},
+		nil,
+	)
+	mockQueries.On("TransferTicket", ctx, mock.Anything).Return(int64(1), nil)
+	mockQueries.On("GetExceededPurchaseLimits", ctx, mock.Anything).Return([]int32{10}, nil)
+
+	repo := repos.NewTransfersRepoFromQueries(mockQueries)
+	_, err := repo.ExecAcceptTicketTransfer(ctx, mockQueries, transfer)
+
+	assert.ErrorIs(t, err, repos.ErrPurchaseLimitExceeded)
+}
+
+func TestTransfersRepoCancelTicketTransferWhenNotPending(t *testing.T) {
+	ctx := context.Background()
+	params := db.UpdateTicketTransferStatusParams{Status: "cancelled", TransferID: 4}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("UpdateTicketTransferStatus", ctx, params).Return(db.TicketTransfer{}, sql.ErrNoRows)
+
+	repo := repos.NewTransfersRepoFromQueries(mockQueries)
+	_, err := repo.CancelTicketTransfer(ctx, 4)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestCredentialsRepoGetTicketCredential(t *testing.T) {
+	rotatedAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	type testCase struct {
+		Name     string
+		Row      db.GetTicketCredentialRow
+		Expected entities.TicketCredential
+	}
+
+	ticket := db.Ticket{ID: 1, EventID: 2, PurchaserID: pgtype.Int4{Int32: 3, Valid: true}}
+	testCases := []testCase{
+		{
+			Name: "WithNonce",
+			Row: db.GetTicketCredentialRow{
+				Ticket:    ticket,
+				Nonce:     pgtype.Text{String: "abc", Valid: true},
+				RotatedAt: pgtype.Timestamptz{Time: rotatedAt, Valid: true},
+			},
+			Expected: entities.TicketCredential{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", RotatedAt: rotatedAt},
+		},
+		{
+			Name:     "WithoutNonce",
+			Row:      db.GetTicketCredentialRow{Ticket: ticket},
+			Expected: entities.TicketCredential{TicketID: 1, EventID: 2, OwnerID: 3},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			mockQueries := new(MockQuerier)
+			mockQueries.On("GetTicketCredential", mock.Anything, int32(1)).Return(testCase.Row, nil)
+
+			repo := repos.NewCredentialsRepoFromQueries(mockQueries)
+			actual, err := repo.GetTicketCredential(context.Background(), 1)
+
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, actual)
+		})
+	}
+}
+
+func TestCredentialsRepoRotateTicketCredentialWhenNotOwner(t *testing.T) {
+	params := db.RotateTicketCredentialParams{TicketID: 1, OwnerID: 3, Nonce: "def"}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("RotateTicketCredential", mock.Anything, params).Return(db.TicketCredential{}, sql.ErrNoRows)
+
+	repo := repos.NewCredentialsRepoFromQueries(mockQueries)
+	_, err := repo.RotateTicketCredential(
+		context.Background(),
+		entities.TicketCredential{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "def"},
+	)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
+
+func TestCheckinsRepoGetTicketAdmission(t *testing.T) {
+	scannedAt, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	type testCase struct {
+		Name     string
+		Row      db.GetTicketAdmissionRow
+		Expected entities.TicketAdmission
+	}
+
+	testCases := []testCase{
+		{
+			Name: "NotCheckedIn",
+			Row: db.GetTicketAdmissionRow{
+				TicketID:    1,
+				EventID:     2,
+				PurchaserID: pgtype.Int4{Int32: 3, Valid: true},
+				Nonce:       pgtype.Text{String: "abc", Valid: true},
+			},
+			Expected: entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+		},
+		{
+			Name: "CheckedIn",
+			Row: db.GetTicketAdmissionRow{
+				TicketID:    1,
+				EventID:     2,
+				PurchaserID: pgtype.Int4{Int32: 3, Valid: true},
+				Nonce:       pgtype.Text{String: "abc", Valid: true},
+				ScannedAt:   pgtype.Timestamptz{Time: scannedAt, Valid: true},
+			},
+			Expected: entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", CheckedIn: true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			mockQueries := new(MockQuerier)
+			mockQueries.On("GetTicketAdmission", mock.Anything, int32(1)).Return(testCase.Row, nil)
+
+			repo := repos.NewCheckinsRepoFromQueries(mockQueries)
+			actual, err := repo.GetTicketAdmission(context.Background(), 1)
+
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, actual)
+		})
+	}
+}
+
+func TestCheckinsRepoCreateCheckinWhenAlreadyCheckedIn(t *testing.T) {
+	params := db.CreateCheckinParams{TicketID: 1, EventID: 2, OwnerID: 3, Gate: "North", Device: "scanner-1"}
+
+	mockQueries := new(MockQuerier)
+	mockQueries.On("CreateCheckin", mock.Anything, params).Return(db.Checkin{}, sql.ErrNoRows)
+
+	repo := repos.NewCheckinsRepoFromQueries(mockQueries)
+	_, err := repo.CreateCheckin(
+		context.Background(),
+		entities.Checkin{TicketID: 1, EventID: 2, OwnerID: 3, Gate: "North", Device: "scanner-1"},
+	)
+
+	assert.ErrorIs(t, err, repos.ErrAlreadyCheckedIn)
+}
+
+func TestCheckinsRepoGetScannerTicketsWhenNoSuchEvent(t *testing.T) {
+	mockQueries := new(MockQuerier)
+	mockQueries.On("GetScannerTickets", mock.Anything, int32(2)).Return([]db.GetScannerTicketsRow{}, nil)
+	mockQueries.On("EventExists", mock.Anything, int32(2)).Return(false, nil)
+
+	repo := repos.NewCheckinsRepoFromQueries(mockQueries)
+	_, err := repo.GetScannerTickets(context.Background(), 2)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+}
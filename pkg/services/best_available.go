@@ -0,0 +1,173 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+)
+
+// maxBestAvailableAttempts bounds how many blocks of seats are tried when
+// other buyers hold the best blocks first.
+const maxBestAvailableAttempts = 5
+
+// seatBlock is a run of adjacent seats in the same row.
+type seatBlock struct {
+	tickets     []entities.SeatedTicket
+	sectionRank int
+	y           float64
+	total       int64
+}
+
+// findSeatBlocks finds every block of adjacent seats matching the selection,
+// ordered from best to worst: by section preference, then from the front rows
+// to the back rows, and then from cheapest to most expensive.
+func findSeatBlocks(tickets []entities.SeatedTicket, selection entities.SeatSelection) ([]seatBlock, error) {
+	sectionRanks := make(map[string]int, len(selection.Sections))
+	for idx, section := range selection.Sections {
+		if _, ok := sectionRanks[section]; !ok {
+			sectionRanks[section] = idx
+		}
+	}
+
+	eligible := make([]entities.SeatedTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		if _, ok := sectionRanks[ticket.Position.Section]; len(sectionRanks) > 0 && !ok {
+			continue
+		}
+		if selection.MaxPrice != nil {
+			if ticket.Ticket.Price.Currency != selection.MaxPrice.Currency {
+				return nil, money.ErrCurrencyMismatch
+			}
+			if ticket.Ticket.Price.Amount > selection.MaxPrice.Amount {
+				continue
+			}
+		}
+		eligible = append(eligible, ticket)
+	}
+
+	slices.SortStableFunc(eligible, func(a, b entities.SeatedTicket) int {
+		return cmp.Or(
+			cmp.Compare(a.Position.RowID, b.Position.RowID),
+			cmp.Compare(a.Position.Position, b.Position.Position),
+		)
+	})
+
+	// As seats are sorted by row and then by position, a run of seats is a
+	// block if the first and last seats are in the same row and the run spans
+	// exactly as many positions as it has seats.
+	blocks := make([]seatBlock, 0)
+	for start := 0; start+selection.Quantity <= len(eligible); start++ {
+		run := eligible[start : start+selection.Quantity]
+		first, last := run[0].Position, run[len(run)-1].Position
+		if first.RowID != last.RowID || int(last.Position-first.Position) != selection.Quantity-1 {
+			continue
+		}
+
+		block := seatBlock{tickets: run, sectionRank: sectionRanks[first.Section]}
+		for _, ticket := range run {
+			block.y += ticket.Position.Y / float64(len(run))
+			block.total += ticket.Ticket.Price.Amount
+		}
+		blocks = append(blocks, block)
+	}
+
+	slices.SortStableFunc(blocks, func(a, b seatBlock) int {
+		return cmp.Or(
+			cmp.Compare(a.sectionRank, b.sectionRank),
+			cmp.Compare(a.y, b.y),
+			cmp.Compare(a.total, b.total),
+		)
+	})
+	return blocks, nil
+}
+
+// HoldBestAvailableSeats finds the best block of adjacent seats for the event
+// that matches the selection, and places a purchase hold on all of its tickets
+// at once for `holderID`. Seats that are already held are skipped, and if
+// another buyer holds a block first the next best block is tried. The hold is
+// returned along with the held seats. If the event has a waiting room, `access`
+// must admit the holder.
+func (svc *TicketsService) HoldBestAvailableSeats(
+	ctx context.Context,
+	eventID int32,
+	selection entities.SeatSelection,
+	holderID string,
+	access entities.SaleAccess,
+) (entities.TicketHold, []entities.SeatedTicket, error) {
+	if holderID == "" {
+		return entities.TicketHold{}, nil, ErrInvalidHoldID
+	}
+	if selection.Quantity < 1 {
+		return entities.TicketHold{}, nil, ErrEmptyHold
+	}
+	if selection.MaxPrice != nil {
+		if err := selection.MaxPrice.Validate(); err != nil {
+			return entities.TicketHold{}, nil, err
+		}
+	}
+	if err := svc.checkAdmission(ctx, []int32{eventID}, holderID, access); err != nil {
+		return entities.TicketHold{}, nil, err
+	}
+
+	tickets, err := svc.repo.GetAvailableSeatedTickets(ctx, eventID)
+	if err != nil {
+		return entities.TicketHold{}, nil, err
+	}
+	if len(tickets) == 0 {
+		return entities.TicketHold{}, nil, ErrNoSeatsAvailable
+	}
+
+	// Filter out seats whose tickets have a purchase hold on them, so that
+	// blocks are only made up of seats that can be held.
+	cacheKeys := make([]string, len(tickets))
+	for idx, ticket := range tickets {
+		cacheKeys[idx] = svc.ticketHoldClient.MakeKey(ticket.Ticket.ID)
+	}
+
+	ticketHolds, err := svc.ticketHoldClient.GetMany(ctx, cacheKeys...)
+	if err != nil {
+		return entities.TicketHold{}, nil, err
+	}
+
+	available := make([]entities.SeatedTicket, 0, len(tickets))
+	for idx, ticket := range tickets {
+		if _, hasHold := ticketHolds[cacheKeys[idx]]; !hasHold {
+			available = append(available, ticket)
+		}
+	}
+
+	blocks, err := findSeatBlocks(available, selection)
+	if err != nil {
+		return entities.TicketHold{}, nil, err
+	}
+
+	for idx, block := range blocks {
+		if idx >= maxBestAvailableAttempts {
+			break
+		}
+
+		ticketIDs := make([]int32, len(block.tickets))
+		for ticketIdx, ticket := range block.tickets {
+			ticketIDs[ticketIdx] = ticket.Ticket.ID
+		}
+
+		// Only tickets on general sale are selected, so `access` is only
+		// needed for admission.
+		hold, err := svc.SetTicketsHold(ctx, ticketIDs, holderID, access)
+		if errors.Is(err, cache.ErrAlreadyHasHold) || errors.Is(err, ErrTicketPurchased) {
+			// Lost the race for the block to another buyer.
+			continue
+		}
+		if err != nil {
+			return entities.TicketHold{}, nil, err
+		}
+		return hold, block.tickets, nil
+	}
+
+	return entities.TicketHold{}, nil, ErrNoSeatsAvailable
+}
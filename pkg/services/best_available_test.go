@@ -0,0 +1,147 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func makeSeatedTicket(id int32, section string, rowID int32, position int32, y float64, price int64) entities.SeatedTicket {
+	return entities.SeatedTicket{
+		Ticket: entities.Ticket{ID: id, EventID: 1, Price: money.New(price, "USD"), VenueSeatID: id},
+		Position: entities.SeatPosition{
+			Section:  section,
+			RowID:    rowID,
+			Row:      fmt.Sprintf("%d", rowID),
+			Number:   fmt.Sprintf("%d", position),
+			Position: position,
+			Y:        y,
+		},
+	}
+}
+
+func newBestAvailableMockClient(ids []int32, held map[string]string) *MockCacheClient {
+	mockClient := new(MockCacheClient)
+	for _, id := range ids {
+		mockClient.On("MakeKey", id).Return(fmt.Sprintf("%d", id))
+	}
+	mockClient.On("MakeHoldKey", mock.Anything).Return("hold")
+	mockClient.On("GetMany", mock.Anything, mock.Anything).Return(held, nil)
+	return mockClient
+}
+
+func TestTicketsServiceHoldBestAvailableSeats(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	tickets := []entities.SeatedTicket{
+		// Front row, with the only adjacent seats split by a held seat.
+		makeSeatedTicket(1, "Floor", 1, 1, 1, 100),
+		makeSeatedTicket(2, "Floor", 1, 2, 1, 100),
+		makeSeatedTicket(3, "Floor", 1, 3, 1, 100),
+		// Second row, with a gap between seats 4 and 5.
+		makeSeatedTicket(4, "Floor", 2, 1, 2, 100),
+		makeSeatedTicket(5, "Floor", 2, 3, 2, 100),
+		makeSeatedTicket(6, "Floor", 2, 4, 2, 100),
+		// Closer to the stage, but not a preferred section.
+		makeSeatedTicket(7, "Balcony", 3, 1, 0, 50),
+		makeSeatedTicket(8, "Balcony", 3, 2, 0, 50),
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetAvailableSeatedTickets", mock.Anything, int32(1)).Return(tickets, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{5, 6}).Return(
+		[]entities.Ticket{tickets[4].Ticket, tickets[5].Ticket},
+		nil,
+	)
+
+	mockClient := newBestAvailableMockClient([]int32{1, 2, 3, 4, 5, 6, 7, 8}, map[string]string{"2": "other"})
+	mockClient.On("SetMany", mock.Anything, mock.Anything, ticketHoldDuration).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	selection := entities.SeatSelection{Quantity: 2, Sections: []string{"Floor"}}
+	hold, seats, err := service.HoldBestAvailableSeats(context.Background(), 1, selection, "123", entities.SaleAccess{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{5, 6}, hold.TicketIDs)
+	assert.Equal(t, []entities.SeatedTicket{tickets[4], tickets[5]}, seats)
+}
+
+func TestTicketsServiceHoldBestAvailableSeatsWhenBestBlockTaken(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	tickets := []entities.SeatedTicket{
+		makeSeatedTicket(1, "Floor", 1, 1, 1, 100),
+		makeSeatedTicket(2, "Floor", 1, 2, 1, 100),
+		makeSeatedTicket(3, "Floor", 2, 1, 2, 100),
+		makeSeatedTicket(4, "Floor", 2, 2, 2, 100),
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetAvailableSeatedTickets", mock.Anything, int32(1)).Return(tickets, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{1, 2}).Return(
+		[]entities.Ticket{tickets[0].Ticket, tickets[1].Ticket},
+		nil,
+	)
+	mockRepo.On("GetTickets", mock.Anything, []int32{3, 4}).Return(
+		[]entities.Ticket{tickets[2].Ticket, tickets[3].Ticket},
+		nil,
+	)
+
+	mockClient := newBestAvailableMockClient([]int32{1, 2, 3, 4}, map[string]string{})
+	mockClient.On("SetMany", mock.Anything, mock.Anything, ticketHoldDuration).Return(cache.ErrAlreadyHasHold).Once()
+	mockClient.On("SetMany", mock.Anything, mock.Anything, ticketHoldDuration).Return(nil).Once()
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	selection := entities.SeatSelection{Quantity: 2}
+	hold, _, err := service.HoldBestAvailableSeats(context.Background(), 1, selection, "123", entities.SaleAccess{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int32{3, 4}, hold.TicketIDs)
+}
+
+func TestTicketsServiceHoldBestAvailableSeatsWhenOverMaxPrice(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	tickets := []entities.SeatedTicket{
+		makeSeatedTicket(1, "Floor", 1, 1, 1, 100),
+		makeSeatedTicket(2, "Floor", 1, 2, 1, 200),
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetAvailableSeatedTickets", mock.Anything, int32(1)).Return(tickets, nil)
+
+	mockClient := newBestAvailableMockClient([]int32{1, 2}, map[string]string{})
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	maxPrice := money.New(150, "USD")
+	selection := entities.SeatSelection{Quantity: 2, MaxPrice: &maxPrice}
+	_, _, err := service.HoldBestAvailableSeats(context.Background(), 1, selection, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrNoSeatsAvailable)
+	mockClient.AssertNotCalled(t, "SetMany", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceHoldBestAvailableSeatsWhenMaxPriceCurrencyDiffers(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	tickets := []entities.SeatedTicket{makeSeatedTicket(1, "Floor", 1, 1, 1, 100)}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetAvailableSeatedTickets", mock.Anything, int32(1)).Return(tickets, nil)
+
+	mockClient := newBestAvailableMockClient([]int32{1}, map[string]string{})
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	maxPrice := money.New(150, "EUR")
+	selection := entities.SeatSelection{Quantity: 1, MaxPrice: &maxPrice}
+	_, _, err := service.HoldBestAvailableSeats(context.Background(), 1, selection, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
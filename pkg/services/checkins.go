@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/credentials"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+)
+
+// CheckinsRepoer provides necessary methods for database operations against
+// check-ins.
+type CheckinsRepoer interface {
+	GetTicketAdmission(context.Context, int32) (entities.TicketAdmission, error)
+	CreateCheckin(context.Context, entities.Checkin) (entities.Checkin, error)
+	GetEventAttendance(context.Context, int32) (entities.EventAttendance, error)
+	GetScannerTickets(context.Context, int32) ([]entities.ScannerTicket, error)
+	GetRevokedTickets(context.Context, int32) ([]int32, error)
+	CreateOfflineCheckin(context.Context, entities.Checkin) (entities.Checkin, error)
+}
+
+// CheckinsService admits ticket holders to events, by checking in the tickets
+// whose credentials are scanned at the event's gates. Scanners that lose
+// connectivity check credentials against a signed bundle of the event's
+// tickets instead, and upload their scans once they reconnect.
+type CheckinsService struct {
+	repo     CheckinsRepoer
+	signer   *credentials.Signer
+	verifier *credentials.Verifier
+}
+
+func NewCheckinsService(repo CheckinsRepoer, signer *credentials.Signer) *CheckinsService {
+	return &CheckinsService{
+		repo:     repo,
+		signer:   signer,
+		verifier: credentials.NewVerifier(signer.PublicKey()),
+	}
+}
+
+// CheckIn checks in the check-in's ticket to its event, given the ticket's
+// scanned credential. The credential must be the ticket's current credential,
+// for the check-in's event, and a ticket can only be checked in once. A
+// ticket that has been voided, or whose event has been deleted, has no current
+// credential.
+func (svc *CheckinsService) CheckIn(
+	ctx context.Context,
+	checkin entities.Checkin,
+	credential string,
+) (entities.Checkin, error) {
+	claims, err := svc.verifier.Verify(credential)
+	if err != nil {
+		if errors.Is(err, credentials.ErrInvalidCredential) {
+			return entities.Checkin{}, ErrInvalidCredential
+		}
+		return entities.Checkin{}, err
+	}
+	if claims.TicketID != checkin.TicketID {
+		return entities.Checkin{}, ErrInvalidCredential
+	}
+	if claims.EventID != checkin.EventID {
+		return entities.Checkin{}, ErrWrongEvent
+	}
+
+	admission, err := svc.repo.GetTicketAdmission(ctx, checkin.TicketID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			return entities.Checkin{}, ErrCredentialNotCurrent
+		}
+		return entities.Checkin{}, err
+	}
+	if admission.EventID != checkin.EventID {
+		return entities.Checkin{}, ErrWrongEvent
+	}
+	if admission.CheckedIn {
+		return entities.Checkin{}, ErrAlreadyCheckedIn
+	}
+	if !admission.IsCurrent(claims.OwnerID, claims.Nonce) {
+		return entities.Checkin{}, ErrCredentialNotCurrent
+	}
+
+	checkin.OwnerID = claims.OwnerID
+	created, err := svc.repo.CreateCheckin(ctx, checkin)
+	if err != nil {
+		// The ticket was checked in concurrently.
+		if errors.Is(err, repos.ErrAlreadyCheckedIn) {
+			return entities.Checkin{}, ErrAlreadyCheckedIn
+		}
+		return entities.Checkin{}, err
+	}
+	return created, nil
+}
+
+// GetAttendance counts the checked in tickets of the event given by id.
+func (svc *CheckinsService) GetAttendance(ctx context.Context, eventID int32) (entities.EventAttendance, error) {
+	return svc.repo.GetEventAttendance(ctx, eventID)
+}
+
+// GetScannerBundle creates a signed bundle of the purchased and revoked tickets
+// of the event given by id, for scanners to check credentials against while
+// offline.
+func (svc *CheckinsService) GetScannerBundle(ctx context.Context, eventID int32) (entities.ScannerBundle, error) {
+	tickets, err := svc.repo.GetScannerTickets(ctx, eventID)
+	if err != nil {
+		return entities.ScannerBundle{}, err
+	}
+	revoked, err := svc.repo.GetRevokedTickets(ctx, eventID)
+	if err != nil {
+		return entities.ScannerBundle{}, err
+	}
+
+	bundle := credentials.Bundle{
+		EventID:  eventID,
+		IssuedAt: time.Now().Unix(),
+		Tickets:  make([]credentials.BundleTicket, len(tickets)),
+		Revoked:  revoked,
+	}
+	for idx, ticket := range tickets {
+		bundle.Tickets[idx] = credentials.BundleTicket{
+			TicketID:  ticket.TicketID,
+			OwnerID:   ticket.OwnerID,
+			Nonce:     ticket.Nonce,
+			CheckedIn: ticket.CheckedIn,
+		}
+	}
+
+	token, err := svc.signer.SignBundle(bundle)
+	if err != nil {
+		return entities.ScannerBundle{}, err
+	}
+
+	return entities.ScannerBundle{
+		EventID:  eventID,
+		IssuedAt: bundle.IssuedTime(),
+		Tickets:  tickets,
+		Revoked:  revoked,
+		Token:    token,
+	}, nil
+}
+
+// reconcileOfflineScan checks in the scan's ticket to the bundle's event, as
+// of when it was scanned. The scan's credential must have been its ticket's
+// current credential as of when the bundle that the scanner checked it against
+// was issued, as the ticket may have changed hands since it was scanned, and
+// the ticket mustn't have been refunded since. A scan can't have been made
+// before its bundle was issued, and a scan from the future, as the scanner's
+// clock is ahead, is taken to have been made now.
+func (svc *CheckinsService) reconcileOfflineScan(
+	ctx context.Context,
+	bundle credentials.Bundle,
+	revoked []int32,
+	now time.Time,
+	scan entities.OfflineScan,
+) (entities.ScanResult, error) {
+	rejected := func(reason error) (entities.ScanResult, error) {
+		return entities.ScanResult{
+			TicketID: scan.TicketID,
+			Status:   entities.ScanStatusRejected,
+			Reason:   reason.Error(),
+		}, nil
+	}
+
+	claims, err := svc.verifier.Verify(scan.Credential)
+	if err != nil {
+		if errors.Is(err, credentials.ErrInvalidCredential) {
+			return rejected(ErrInvalidCredential)
+		}
+		return entities.ScanResult{}, err
+	}
+	if claims.TicketID != scan.TicketID {
+		return rejected(ErrInvalidCredential)
+	}
+	if claims.EventID != bundle.EventID {
+		return rejected(ErrWrongEvent)
+	}
+	if !bundle.IsCurrent(claims) {
+		return rejected(ErrCredentialNotCurrent)
+	}
+	if _, isRevoked := slices.BinarySearch(revoked, scan.TicketID); isRevoked {
+		return rejected(ErrTicketRevoked)
+	}
+
+	scannedAt := scan.ScannedAt
+	if scannedAt.Before(bundle.IssuedTime()) {
+		return rejected(ErrInvalidScanTime)
+	}
+	if scannedAt.After(now) {
+		scannedAt = now
+	}
+
+	admission, err := svc.repo.GetTicketAdmission(ctx, scan.TicketID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			return rejected(ErrCredentialNotCurrent)
+		}
+		return entities.ScanResult{}, err
+	}
+	if admission.EventID != bundle.EventID {
+		return rejected(ErrWrongEvent)
+	}
+
+	_, err = svc.repo.CreateOfflineCheckin(ctx, entities.Checkin{
+		TicketID:  scan.TicketID,
+		EventID:   bundle.EventID,
+		OwnerID:   claims.OwnerID,
+		Gate:      scan.Gate,
+		Device:    scan.Device,
+		ScannedAt: scannedAt,
+	})
+	if err != nil && !errors.Is(err, repos.ErrAlreadyCheckedIn) {
+		return entities.ScanResult{}, err
+	}
+
+	// The ticket was admitted more than once, whether or not this scan was the
+	// earliest admission.
+	if err != nil || admission.CheckedIn {
+		return entities.ScanResult{TicketID: scan.TicketID, Status: entities.ScanStatusDuplicate}, nil
+	}
+	return entities.ScanResult{TicketID: scan.TicketID, Status: entities.ScanStatusAdmitted}, nil
+}
+
+// SyncOfflineScans reconciles scans made by devices while offline with the
+// check-ins of the event given by id. The device uploads the signed bundle
+// that it checked the scans against, which must be for the event. Scans are
+// reconciled in the order that they were scanned, so that, of the offline
+// scans of a ticket - whether uploaded together or by different devices - the
+// earliest is kept as its check-in, and the others are reported as
+// duplicates. A check-in made online is always kept. Results are given in the
+// order of the scans.
+func (svc *CheckinsService) SyncOfflineScans(
+	ctx context.Context,
+	eventID int32,
+	signedBundle string,
+	scans []entities.OfflineScan,
+) ([]entities.ScanResult, error) {
+	bundle, err := svc.verifier.VerifyBundle(signedBundle)
+	if err != nil {
+		if errors.Is(err, credentials.ErrInvalidBundle) {
+			return nil, ErrInvalidBundle
+		}
+		return nil, err
+	}
+	if bundle.EventID != eventID {
+		return nil, ErrWrongEvent
+	}
+
+	// Tickets refunded since the bundle was issued aren't in its revoked
+	// tickets.
+	revoked, err := svc.repo.GetRevokedTickets(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]int, len(scans))
+	for idx := range scans {
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scans[order[i]].ScannedAt.Before(scans[order[j]].ScannedAt)
+	})
+
+	now := time.Now()
+	results := make([]entities.ScanResult, len(scans))
+	for _, idx := range order {
+		result, err := svc.reconcileOfflineScan(ctx, bundle, revoked, now, scans[idx])
+		if err != nil {
+			return nil, err
+		}
+		results[idx] = result
+	}
+	return results, nil
+}
@@ -0,0 +1,446 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/credentials"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCheckinsRepo struct {
+	mock.Mock
+}
+
+func (mock *MockCheckinsRepo) GetTicketAdmission(ctx context.Context, ticketID int32) (entities.TicketAdmission, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(entities.TicketAdmission), args.Error(1)
+}
+
+func (mock *MockCheckinsRepo) CreateCheckin(ctx context.Context, checkin entities.Checkin) (entities.Checkin, error) {
+	args := mock.Called(ctx, checkin)
+	return args.Get(0).(entities.Checkin), args.Error(1)
+}
+
+func (mock *MockCheckinsRepo) GetEventAttendance(ctx context.Context, eventID int32) (entities.EventAttendance, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(entities.EventAttendance), args.Error(1)
+}
+
+func (mock *MockCheckinsRepo) GetScannerTickets(ctx context.Context, eventID int32) ([]entities.ScannerTicket, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]entities.ScannerTicket), args.Error(1)
+}
+
+func (mock *MockCheckinsRepo) GetRevokedTickets(ctx context.Context, eventID int32) ([]int32, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (mock *MockCheckinsRepo) CreateOfflineCheckin(ctx context.Context, checkin entities.Checkin) (entities.Checkin, error) {
+	args := mock.Called(ctx, checkin)
+	return args.Get(0).(entities.Checkin), args.Error(1)
+}
+
+func newTestCheckinCredential(t *testing.T, claims credentials.Claims) string {
+	credential, err := newTestCredentialsSigner().Sign(claims)
+	assert.Nil(t, err)
+	return credential
+}
+
+func newTestCheckinsService(repo services.CheckinsRepoer) *services.CheckinsService {
+	return services.NewCheckinsService(repo, newTestCredentialsSigner())
+}
+
+func TestCheckinsServiceCheckIn(t *testing.T) {
+	credential := newTestCheckinCredential(t, credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"})
+	checkin := entities.Checkin{TicketID: 1, EventID: 2, Gate: "North", Device: "scanner-1"}
+	expected := entities.Checkin{ID: 4, TicketID: 1, EventID: 2, OwnerID: 3, Gate: "North", Device: "scanner-1"}
+
+	mockRepo := new(MockCheckinsRepo)
+	mockRepo.On("GetTicketAdmission", mock.Anything, int32(1)).Return(
+		entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+		nil,
+	)
+	mockRepo.On("CreateCheckin", mock.Anything, entities.Checkin{
+		TicketID: 1,
+		EventID:  2,
+		OwnerID:  3,
+		Gate:     "North",
+		Device:   "scanner-1",
+	}).Return(expected, nil)
+
+	service := newTestCheckinsService(mockRepo)
+	actual, err := service.CheckIn(context.Background(), checkin, credential)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestCheckinsServiceCheckInWhenRejected(t *testing.T) {
+	current := entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"}
+
+	type testCase struct {
+		Name         string
+		Claims       credentials.Claims
+		Admission    entities.TicketAdmission
+		AdmissionErr error
+		ExpectedErr  error
+	}
+
+	testCases := []testCase{
+		{
+			Name:        "OtherTicket",
+			Claims:      credentials.Claims{TicketID: 5, EventID: 2, OwnerID: 3, Nonce: "abc"},
+			Admission:   current,
+			ExpectedErr: services.ErrInvalidCredential,
+		},
+		{
+			Name:        "OtherEvent",
+			Claims:      credentials.Claims{TicketID: 1, EventID: 5, OwnerID: 3, Nonce: "abc"},
+			Admission:   current,
+			ExpectedErr: services.ErrWrongEvent,
+		},
+		{
+			Name:        "Rotated",
+			Claims:      credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "old"},
+			Admission:   current,
+			ExpectedErr: services.ErrCredentialNotCurrent,
+		},
+		{
+			Name:        "PreviousOwner",
+			Claims:      credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 5, Nonce: "abc"},
+			Admission:   current,
+			ExpectedErr: services.ErrCredentialNotCurrent,
+		},
+		{
+			Name:         "Voided",
+			Claims:       credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+			AdmissionErr: repos.ErrNoSuchEntity,
+			ExpectedErr:  services.ErrCredentialNotCurrent,
+		},
+		{
+			Name:        "AlreadyCheckedIn",
+			Claims:      credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+			Admission:   entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", CheckedIn: true},
+			ExpectedErr: services.ErrAlreadyCheckedIn,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := new(MockCheckinsRepo)
+			mockRepo.On("GetTicketAdmission", mock.Anything, int32(1)).Return(tc.Admission, tc.AdmissionErr)
+
+			service := newTestCheckinsService(mockRepo)
+			_, err := service.CheckIn(
+				context.Background(),
+				entities.Checkin{TicketID: 1, EventID: 2, Gate: "North", Device: "scanner-1"},
+				newTestCheckinCredential(t, tc.Claims),
+			)
+
+			assert.ErrorIs(t, err, tc.ExpectedErr)
+			mockRepo.AssertNotCalled(t, "CreateCheckin", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCheckinsServiceCheckInWhenInvalidCredential(t *testing.T) {
+	mockRepo := new(MockCheckinsRepo)
+
+	service := newTestCheckinsService(mockRepo)
+	_, err := service.CheckIn(
+		context.Background(),
+		entities.Checkin{TicketID: 1, EventID: 2, Gate: "North", Device: "scanner-1"},
+		"payload.signature",
+	)
+
+	assert.ErrorIs(t, err, services.ErrInvalidCredential)
+	mockRepo.AssertNotCalled(t, "GetTicketAdmission", mock.Anything, mock.Anything)
+}
+
+func TestCheckinsServiceCheckInWhenCheckedInConcurrently(t *testing.T) {
+	credential := newTestCheckinCredential(t, credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"})
+
+	mockRepo := new(MockCheckinsRepo)
+	mockRepo.On("GetTicketAdmission", mock.Anything, int32(1)).Return(
+		entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+		nil,
+	)
+	mockRepo.On("CreateCheckin", mock.Anything, mock.Anything).Return(entities.Checkin{}, repos.ErrAlreadyCheckedIn)
+
+	service := newTestCheckinsService(mockRepo)
+	_, err := service.CheckIn(
+		context.Background(),
+		entities.Checkin{TicketID: 1, EventID: 2, Gate: "North", Device: "scanner-1"},
+		credential,
+	)
+
+	assert.ErrorIs(t, err, services.ErrAlreadyCheckedIn)
+}
+
+func TestCheckinsServiceGetScannerBundle(t *testing.T) {
+	tickets := []entities.ScannerTicket{
+		{TicketID: 1, OwnerID: 3, Nonce: "abc"},
+		{TicketID: 4, OwnerID: 5, CheckedIn: true},
+	}
+
+	mockRepo := new(MockCheckinsRepo)
+	mockRepo.On("GetScannerTickets", mock.Anything, int32(2)).Return(tickets, nil)
+	mockRepo.On("GetRevokedTickets", mock.Anything, int32(2)).Return([]int32{6}, nil)
+
+	service := newTestCheckinsService(mockRepo)
+	actual, err := service.GetScannerBundle(context.Background(), 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, tickets, actual.Tickets)
+	assert.Equal(t, []int32{6}, actual.Revoked)
+
+	bundle, err := credentials.NewVerifier(newTestCredentialsSigner().PublicKey()).VerifyBundle(actual.Token)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		credentials.Bundle{
+			EventID:  2,
+			IssuedAt: actual.IssuedAt.Unix(),
+			Tickets: []credentials.BundleTicket{
+				{TicketID: 1, OwnerID: 3, Nonce: "abc"},
+				{TicketID: 4, OwnerID: 5, CheckedIn: true},
+			},
+			Revoked: []int32{6},
+		},
+		bundle,
+	)
+}
+
+// newTestScannerBundle signs a bundle for event 2, issued at the given time,
+// of ticket 1, owned by user 3 with a credential signed over nonce "abc", and
+// of the given revoked tickets.
+func newTestScannerBundle(t *testing.T, issuedAt time.Time, revoked ...int32) string {
+	bundle := credentials.Bundle{
+		EventID:  2,
+		IssuedAt: issuedAt.Unix(),
+		Tickets:  []credentials.BundleTicket{{TicketID: 1, OwnerID: 3, Nonce: "abc"}},
+		Revoked:  revoked,
+	}
+	signed, err := newTestCredentialsSigner().SignBundle(bundle)
+	assert.Nil(t, err)
+	return signed
+}
+
+func TestCheckinsServiceSyncOfflineScans(t *testing.T) {
+	scannedAt := time.Date(2020, 1, 1, 20, 0, 0, 0, time.UTC)
+	bundle := newTestScannerBundle(t, scannedAt.Add(-time.Hour))
+	credential := newTestCheckinCredential(t, credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"})
+	otherEventCredential := newTestCheckinCredential(
+		t,
+		credentials.Claims{TicketID: 7, EventID: 8, OwnerID: 3, Nonce: "abc"},
+	)
+
+	// The same ticket scanned by two devices, uploaded out of order, along with
+	// a ticket for another event.
+	scans := []entities.OfflineScan{
+		{TicketID: 1, Credential: credential, Gate: "South", Device: "scanner-2", ScannedAt: scannedAt.Add(time.Minute)},
+		{TicketID: 7, Credential: otherEventCredential, Gate: "North", Device: "scanner-1", ScannedAt: scannedAt},
+		{TicketID: 1, Credential: credential, Gate: "North", Device: "scanner-1", ScannedAt: scannedAt},
+	}
+
+	mockRepo := new(MockCheckinsRepo)
+	mockRepo.On("GetRevokedTickets", mock.Anything, int32(2)).Return([]int32{}, nil)
+	mockRepo.On("GetTicketAdmission", mock.Anything, int32(1)).Return(
+		entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+		nil,
+	).Once()
+	mockRepo.On("GetTicketAdmission", mock.Anything, int32(1)).Return(
+		entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", CheckedIn: true},
+		nil,
+	).Once()
+	mockRepo.On("CreateOfflineCheckin", mock.Anything, entities.Checkin{
+		TicketID:  1,
+		EventID:   2,
+		OwnerID:   3,
+		Gate:      "North",
+		Device:    "scanner-1",
+		ScannedAt: scannedAt,
+	}).Return(entities.Checkin{ID: 4}, nil).Once()
+	mockRepo.On("CreateOfflineCheckin", mock.Anything, mock.Anything).Return(
+		entities.Checkin{},
+		repos.ErrAlreadyCheckedIn,
+	).Once()
+
+	service := newTestCheckinsService(mockRepo)
+	actual, err := service.SyncOfflineScans(context.Background(), 2, bundle, scans)
+
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]entities.ScanResult{
+			{TicketID: 1, Status: entities.ScanStatusDuplicate},
+			{TicketID: 7, Status: entities.ScanStatusRejected, Reason: services.ErrWrongEvent.Error()},
+			{TicketID: 1, Status: entities.ScanStatusAdmitted},
+		},
+		actual,
+	)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheckinsServiceSyncOfflineScansWhenCheckedInOnline(t *testing.T) {
+	bundle := newTestScannerBundle(t, time.Now().Add(-time.Hour))
+	credential := newTestCheckinCredential(t, credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"})
+	scans := []entities.OfflineScan{
+		{TicketID: 1, Credential: credential, Gate: "North", Device: "scanner-1", ScannedAt: time.Now().Add(-time.Minute)},
+	}
+
+	// The ticket was checked in online after it was scanned offline, and the
+	// online check-in is kept.
+	mockRepo := new(MockCheckinsRepo)
+	mockRepo.On("GetRevokedTickets", mock.Anything, int32(2)).Return([]int32{}, nil)
+	mockRepo.On("GetTicketAdmission", mock.Anything, int32(1)).Return(
+		entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", CheckedIn: true},
+		nil,
+	)
+	mockRepo.On("CreateOfflineCheckin", mock.Anything, mock.Anything).Return(entities.Checkin{}, repos.ErrAlreadyCheckedIn)
+
+	service := newTestCheckinsService(mockRepo)
+	actual, err := service.SyncOfflineScans(context.Background(), 2, bundle, scans)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entities.ScanResult{{TicketID: 1, Status: entities.ScanStatusDuplicate}}, actual)
+}
+
+func TestCheckinsServiceSyncOfflineScansWhenRejected(t *testing.T) {
+	issuedAt := time.Now().Add(-time.Hour)
+	scannedAt := time.Now().Add(-time.Minute)
+
+	type testCase struct {
+		Name      string
+		Claims    credentials.Claims
+		Revoked   []int32
+		Refunded  []int32
+		ScannedAt time.Time
+		Expected  error
+	}
+
+	testCases := []testCase{
+		{
+			Name:      "Rotated",
+			Claims:    credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "old"},
+			ScannedAt: scannedAt,
+			Expected:  services.ErrCredentialNotCurrent,
+		},
+		{
+			Name:      "PreviousOwner",
+			Claims:    credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 5, Nonce: "abc"},
+			ScannedAt: scannedAt,
+			Expected:  services.ErrCredentialNotCurrent,
+		},
+		{
+			Name:      "RevokedInBundle",
+			Claims:    credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+			Revoked:   []int32{1},
+			ScannedAt: scannedAt,
+			Expected:  services.ErrCredentialNotCurrent,
+		},
+		{
+			Name:      "RefundedSinceBundle",
+			Claims:    credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+			Refunded:  []int32{1},
+			ScannedAt: scannedAt,
+			Expected:  services.ErrTicketRevoked,
+		},
+		{
+			Name:      "ScannedBeforeBundle",
+			Claims:    credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+			ScannedAt: issuedAt.Add(-time.Hour),
+			Expected:  services.ErrInvalidScanTime,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			bundle := newTestScannerBundle(t, issuedAt, testCase.Revoked...)
+			credential := newTestCheckinCredential(t, testCase.Claims)
+			scans := []entities.OfflineScan{
+				{TicketID: 1, Credential: credential, Gate: "North", Device: "scanner-1", ScannedAt: testCase.ScannedAt},
+			}
+
+			mockRepo := new(MockCheckinsRepo)
+			mockRepo.On("GetRevokedTickets", mock.Anything, int32(2)).Return(testCase.Refunded, nil)
+
+			service := newTestCheckinsService(mockRepo)
+			actual, err := service.SyncOfflineScans(context.Background(), 2, bundle, scans)
+
+			assert.Nil(t, err)
+			assert.Equal(
+				t,
+				[]entities.ScanResult{{TicketID: 1, Status: entities.ScanStatusRejected, Reason: testCase.Expected.Error()}},
+				actual,
+			)
+			mockRepo.AssertNotCalled(t, "CreateOfflineCheckin", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCheckinsServiceSyncOfflineScansWhenScannedInFuture(t *testing.T) {
+	bundle := newTestScannerBundle(t, time.Now().Add(-time.Hour))
+	credential := newTestCheckinCredential(t, credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"})
+	scans := []entities.OfflineScan{
+		{TicketID: 1, Credential: credential, Gate: "North", Device: "scanner-1", ScannedAt: time.Now().Add(time.Hour)},
+	}
+
+	mockRepo := new(MockCheckinsRepo)
+	mockRepo.On("GetRevokedTickets", mock.Anything, int32(2)).Return([]int32{}, nil)
+	mockRepo.On("GetTicketAdmission", mock.Anything, int32(1)).Return(
+		entities.TicketAdmission{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"},
+		nil,
+	)
+	mockRepo.On("CreateOfflineCheckin", mock.Anything, mock.Anything).Return(entities.Checkin{ID: 4}, nil)
+
+	service := newTestCheckinsService(mockRepo)
+	actual, err := service.SyncOfflineScans(context.Background(), 2, bundle, scans)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entities.ScanResult{{TicketID: 1, Status: entities.ScanStatusAdmitted}}, actual)
+
+	// The scan is taken to have been made now, rather than when the scanner's
+	// clock says.
+	checkin := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(entities.Checkin)
+	assert.WithinDuration(t, time.Now(), checkin.ScannedAt, time.Second)
+}
+
+func TestCheckinsServiceSyncOfflineScansWhenInvalidBundle(t *testing.T) {
+	credential := newTestCheckinCredential(t, credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"})
+	otherEventBundle, _ := newTestCredentialsSigner().SignBundle(credentials.Bundle{EventID: 8})
+	scans := []entities.OfflineScan{
+		{TicketID: 1, Credential: credential, Gate: "North", Device: "scanner-1", ScannedAt: time.Now()},
+	}
+
+	type testCase struct {
+		Name     string
+		Bundle   string
+		Expected error
+	}
+
+	testCases := []testCase{
+		{Name: "Malformed", Bundle: "payload.signature", Expected: services.ErrInvalidBundle},
+		{Name: "Credential", Bundle: credential, Expected: services.ErrInvalidBundle},
+		{Name: "OtherEvent", Bundle: otherEventBundle, Expected: services.ErrWrongEvent},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			mockRepo := new(MockCheckinsRepo)
+
+			service := newTestCheckinsService(mockRepo)
+			_, err := service.SyncOfflineScans(context.Background(), 2, testCase.Bundle, scans)
+
+			assert.ErrorIs(t, err, testCase.Expected)
+			mockRepo.AssertNotCalled(t, "CreateOfflineCheckin", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/dslaw/book-tickets/pkg/credentials"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+)
+
+// CredentialsRepoer provides necessary methods for database operations against
+// ticket credentials.
+type CredentialsRepoer interface {
+	GetTicketCredential(context.Context, int32) (entities.TicketCredential, error)
+	CreateTicketCredential(context.Context, entities.TicketCredential) error
+	RotateTicketCredential(context.Context, entities.TicketCredential) (entities.TicketCredential, error)
+}
+
+// CredentialsService issues signed credentials for purchased tickets, which
+// are presented by their owners for entry. A ticket's credential is signed
+// over a nonce that can be rotated, so that copies of the credential stop
+// being current.
+type CredentialsService struct {
+	repo   CredentialsRepoer
+	signer *credentials.Signer
+}
+
+func NewCredentialsService(repo CredentialsRepoer, signer *credentials.Signer) *CredentialsService {
+	return &CredentialsService{repo: repo, signer: signer}
+}
+
+// newCredentialNonce generates a random, hex-encoded nonce for a credential.
+func newCredentialNonce() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// getOwnedCredential fetches the credential of the ticket given by id, and
+// checks that the ticket is owned by the user given by `ownerID`.
+func (svc *CredentialsService) getOwnedCredential(
+	ctx context.Context,
+	ticketID int32,
+	ownerID int32,
+) (entities.TicketCredential, error) {
+	credential, err := svc.repo.GetTicketCredential(ctx, ticketID)
+	if err != nil {
+		return entities.TicketCredential{}, err
+	}
+	if credential.OwnerID == 0 || credential.OwnerID != ownerID {
+		return entities.TicketCredential{}, ErrNotTicketOwner
+	}
+	return credential, nil
+}
+
+// sign sets the credential's token, signed over its claims.
+func (svc *CredentialsService) sign(credential entities.TicketCredential) (entities.TicketCredential, error) {
+	token, err := svc.signer.Sign(credentials.Claims{
+		TicketID: credential.TicketID,
+		EventID:  credential.EventID,
+		OwnerID:  credential.OwnerID,
+		Nonce:    credential.Nonce,
+		IssuedAt: credential.RotatedAt.Unix(),
+	})
+	if err != nil {
+		return entities.TicketCredential{}, err
+	}
+	credential.Token = token
+	return credential, nil
+}
+
+// GetCredential gets the credential of the ticket given by id, for its owner.
+// The credential's nonce is generated when the credential is first fetched by
+// the ticket's owner.
+func (svc *CredentialsService) GetCredential(
+	ctx context.Context,
+	ticketID int32,
+	ownerID int32,
+) (entities.TicketCredential, error) {
+	credential, err := svc.getOwnedCredential(ctx, ticketID, ownerID)
+	if err != nil {
+		return entities.TicketCredential{}, err
+	}
+
+	if !credential.HasNonce() {
+		nonce, err := newCredentialNonce()
+		if err != nil {
+			return entities.TicketCredential{}, err
+		}
+		credential.Nonce = nonce
+		if err := svc.repo.CreateTicketCredential(ctx, credential); err != nil {
+			return entities.TicketCredential{}, err
+		}
+
+		// Fetch the credential again, as it may have been created concurrently,
+		// in which case the nonce given here is discarded.
+		credential, err = svc.getOwnedCredential(ctx, ticketID, ownerID)
+		if err != nil {
+			return entities.TicketCredential{}, err
+		}
+	}
+
+	return svc.sign(credential)
+}
+
+// RotateCredential rotates the nonce of the credential of the ticket given by
+// id, for its owner, so that credentials signed over the previous nonce are no
+// longer current.
+func (svc *CredentialsService) RotateCredential(
+	ctx context.Context,
+	ticketID int32,
+	ownerID int32,
+) (entities.TicketCredential, error) {
+	credential, err := svc.getOwnedCredential(ctx, ticketID, ownerID)
+	if err != nil {
+		return entities.TicketCredential{}, err
+	}
+
+	nonce, err := newCredentialNonce()
+	if err != nil {
+		return entities.TicketCredential{}, err
+	}
+	credential.Nonce = nonce
+
+	credential, err = svc.repo.RotateTicketCredential(ctx, credential)
+	if err != nil {
+		// The ticket was transferred or refunded after it was fetched.
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			return entities.TicketCredential{}, ErrNotTicketOwner
+		}
+		return entities.TicketCredential{}, err
+	}
+
+	return svc.sign(credential)
+}
+
+// PublicKey gives the public key that credentials are verified with.
+func (svc *CredentialsService) PublicKey() ed25519.PublicKey {
+	return svc.signer.PublicKey()
+}
@@ -0,0 +1,158 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/credentials"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCredentialsRepo struct {
+	mock.Mock
+}
+
+func (mock *MockCredentialsRepo) GetTicketCredential(ctx context.Context, ticketID int32) (entities.TicketCredential, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(entities.TicketCredential), args.Error(1)
+}
+
+func (mock *MockCredentialsRepo) CreateTicketCredential(ctx context.Context, credential entities.TicketCredential) error {
+	args := mock.Called(ctx, credential)
+	return args.Error(0)
+}
+
+func (mock *MockCredentialsRepo) RotateTicketCredential(
+	ctx context.Context,
+	credential entities.TicketCredential,
+) (entities.TicketCredential, error) {
+	args := mock.Called(ctx, credential)
+	return args.Get(0).(entities.TicketCredential), args.Error(1)
+}
+
+func newTestCredentialsSigner() *credentials.Signer {
+	return credentials.NewSigner(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+}
+
+func TestCredentialsServiceGetCredential(t *testing.T) {
+	rotatedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	credential := entities.TicketCredential{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", RotatedAt: rotatedAt}
+
+	mockRepo := new(MockCredentialsRepo)
+	mockRepo.On("GetTicketCredential", mock.Anything, int32(1)).Return(credential, nil)
+
+	signer := newTestCredentialsSigner()
+	service := services.NewCredentialsService(mockRepo, signer)
+	actual, err := service.GetCredential(context.Background(), 1, 3)
+
+	assert.Nil(t, err)
+	mockRepo.AssertNotCalled(t, "CreateTicketCredential", mock.Anything, mock.Anything)
+
+	claims, err := credentials.NewVerifier(signer.PublicKey()).Verify(actual.Token)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		credentials.Claims{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", IssuedAt: rotatedAt.Unix()},
+		claims,
+	)
+}
+
+func TestCredentialsServiceGetCredentialWhenNoNonce(t *testing.T) {
+	rotatedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := entities.TicketCredential{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc", RotatedAt: rotatedAt}
+
+	mockRepo := new(MockCredentialsRepo)
+	mockRepo.On("GetTicketCredential", mock.Anything, int32(1)).Return(
+		entities.TicketCredential{TicketID: 1, EventID: 2, OwnerID: 3},
+		nil,
+	).Once()
+	mockRepo.On("GetTicketCredential", mock.Anything, int32(1)).Return(created, nil).Once()
+	mockRepo.On("CreateTicketCredential", mock.Anything, mock.MatchedBy(func(c entities.TicketCredential) bool {
+		return c.TicketID == 1 && c.OwnerID == 3 && c.HasNonce()
+	})).Return(nil)
+
+	service := services.NewCredentialsService(mockRepo, newTestCredentialsSigner())
+	actual, err := service.GetCredential(context.Background(), 1, 3)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", actual.Nonce)
+	assert.NotEmpty(t, actual.Token)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCredentialsServiceGetCredentialWhenNotOwner(t *testing.T) {
+	type testCase struct {
+		Name       string
+		Credential entities.TicketCredential
+	}
+
+	testCases := []testCase{
+		{Name: "OtherOwner", Credential: entities.TicketCredential{TicketID: 1, EventID: 2, OwnerID: 5}},
+		{Name: "NotPurchased", Credential: entities.TicketCredential{TicketID: 1, EventID: 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := new(MockCredentialsRepo)
+			mockRepo.On("GetTicketCredential", mock.Anything, int32(1)).Return(tc.Credential, nil)
+
+			service := services.NewCredentialsService(mockRepo, newTestCredentialsSigner())
+			_, err := service.GetCredential(context.Background(), 1, 3)
+
+			assert.ErrorIs(t, err, services.ErrNotTicketOwner)
+			mockRepo.AssertNotCalled(t, "CreateTicketCredential", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCredentialsServiceRotateCredential(t *testing.T) {
+	credential := entities.TicketCredential{
+		TicketID:  1,
+		EventID:   2,
+		OwnerID:   3,
+		Nonce:     "abc",
+		RotatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	rotated := credential
+	rotated.Nonce = "def"
+	rotated.RotatedAt = time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	mockRepo := new(MockCredentialsRepo)
+	mockRepo.On("GetTicketCredential", mock.Anything, int32(1)).Return(credential, nil)
+	mockRepo.On("RotateTicketCredential", mock.Anything, mock.MatchedBy(func(c entities.TicketCredential) bool {
+		return c.TicketID == 1 && c.OwnerID == 3 && c.Nonce != "abc"
+	})).Return(rotated, nil)
+
+	signer := newTestCredentialsSigner()
+	service := services.NewCredentialsService(mockRepo, signer)
+	actual, err := service.RotateCredential(context.Background(), 1, 3)
+
+	assert.Nil(t, err)
+
+	claims, err := credentials.NewVerifier(signer.PublicKey()).Verify(actual.Token)
+	assert.Nil(t, err)
+	assert.Equal(t, "def", claims.Nonce)
+	assert.Equal(t, rotated.RotatedAt.Unix(), claims.IssuedAt)
+}
+
+func TestCredentialsServiceRotateCredentialWhenNoLongerOwned(t *testing.T) {
+	credential := entities.TicketCredential{TicketID: 1, EventID: 2, OwnerID: 3, Nonce: "abc"}
+
+	mockRepo := new(MockCredentialsRepo)
+	mockRepo.On("GetTicketCredential", mock.Anything, int32(1)).Return(credential, nil)
+	mockRepo.On("RotateTicketCredential", mock.Anything, mock.Anything).Return(
+		entities.TicketCredential{},
+		repos.ErrNoSuchEntity,
+	)
+
+	service := services.NewCredentialsService(mockRepo, newTestCredentialsSigner())
+	_, err := service.RotateCredential(context.Background(), 1, 3)
+
+	assert.ErrorIs(t, err, services.ErrNotTicketOwner)
+}
@@ -5,4 +5,70 @@ import "errors"
 var (
 	ErrInvalidHoldID  = errors.New("Invalid hold id")
 	ErrHoldIDMismatch = errors.New("The given hold id does not match")
+
+	ErrInvalidHoldToken    = errors.New("Invalid hold token")
+	ErrTicketInTicketsHold = errors.New("The ticket is held along with other tickets, so its hold can only be released or extended by the hold's token at /holds/{token}")
+	ErrEmptyHold           = errors.New("No tickets were given to hold")
+	ErrTicketPurchased     = errors.New("The ticket has already been purchased")
+
+	ErrPurchaseInProgress = errors.New("A purchase of the ticket is already in progress")
+	ErrNotTicketOwner     = errors.New("The ticket is not owned by the user")
+	ErrTicketTransferred  = errors.New("The ticket was transferred to the user, and can only be refunded by its purchaser")
+
+	ErrInvalidPromoCode       = errors.New("No such promo code")
+	ErrPromoCodeExists        = errors.New("A promo code with the code already exists")
+	ErrPromoCodeUnavailable   = errors.New("The promo code isn't active or has no redemptions left")
+	ErrPromoCodeNotApplicable = errors.New("The promo code doesn't apply to the tickets")
+
+	ErrMissingSeat  = errors.New("A ticket must be given a seat")
+	ErrNoSuchSeat   = errors.New("The seat isn't part of the event's venue's layout")
+	ErrSeatReleased = errors.New("A ticket has already been released for the seat")
+
+	ErrNoSeatsAvailable = errors.New("No adjacent seats matching the selection are available")
+
+	ErrGATierExists  = errors.New("A general admission tier with the name already exists for the event")
+	ErrGATierSoldOut = errors.New("Not enough of the general admission tier is left")
+
+	ErrTicketTypeExists    = errors.New("A ticket type with the name already exists for the event")
+	ErrNoSuchTicketType    = errors.New("The ticket type isn't one of the event's")
+	ErrTicketTypePriced    = errors.New("A ticket of a ticket type is priced by its type")
+	ErrMissingPrice        = errors.New("A ticket must be given a price or a ticket type")
+	ErrTicketTypeNotOnSale = errors.New("The ticket's type isn't on sale")
+
+	ErrNotOnSale           = errors.New("The ticket isn't on sale")
+	ErrPresaleAccessDenied = errors.New("Access to the ticket's presale wasn't given")
+
+	ErrPurchaseLimitExceeded = errors.New("The event's limit on tickets per user would be exceeded")
+
+	ErrTransferToSelf       = errors.New("A ticket can't be transferred to its owner")
+	ErrTransferPending      = errors.New("The ticket already has a pending transfer")
+	ErrTransferNotPending   = errors.New("The transfer has already been accepted or cancelled")
+	ErrNotTransferSender    = errors.New("The transfer wasn't sent by the user")
+	ErrNotTransferRecipient = errors.New("The transfer isn't to the user")
+	ErrSenderNotOwner       = errors.New("The ticket is no longer owned by the transfer's sender")
+	ErrEventStarted         = errors.New("The ticket's event has already started")
+
+	ErrTicketListed          = errors.New("The ticket is already listed for resale")
+	ErrResalePriceNotAllowed = errors.New("The price is outside of the event's bounds on resale prices")
+	ErrListingNotActive      = errors.New("The listing has already been sold or cancelled")
+	ErrNotListingSeller      = errors.New("The listing wasn't created by the user")
+	ErrOwnListing            = errors.New("A user can't purchase their own listing")
+
+	ErrNotSoldOut      = errors.New("The event isn't sold out")
+	ErrWaitlistOffered = errors.New("The user has already been offered tickets from the waitlist")
+
+	ErrNotInQueue            = errors.New("The user isn't in the event's waiting room queue")
+	ErrAdmissionRequired     = errors.New("An admission token from the event's waiting room is required")
+	ErrInvalidAdmissionToken = errors.New("The admission token is invalid or has expired")
+
+	ErrInvalidCredential    = errors.New("The credential is invalid or isn't the ticket's")
+	ErrCredentialNotCurrent = errors.New("The credential has been rotated or the ticket has changed hands")
+	ErrWrongEvent           = errors.New("The ticket isn't for the event")
+	ErrAlreadyCheckedIn     = errors.New("The ticket has already been checked in")
+	ErrTicketRevoked        = errors.New("The ticket has been refunded")
+	ErrInvalidBundle        = errors.New("The scanner bundle is invalid")
+	ErrInvalidScanTime      = errors.New("The scan was made before its scanner bundle was issued")
+
+	ErrIdempotencyKeyMismatch   = errors.New("The idempotency key was used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("A request with the idempotency key is already in progress")
 )
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/repos"
+)
+
+// CreateGATier creates a new general admission tier for the tier's event and
+// returns the new entity's id. A tier must be priced in the same currency as
+// the rest of the event's tickets, and be uniquely named for the event.
+func (svc *TicketsService) CreateGATier(ctx context.Context, tier entities.GATier) (int32, error) {
+	if err := tier.Price.Validate(); err != nil {
+		return 0, err
+	}
+
+	currencies, err := svc.repo.GetEventCurrencies(ctx, tier.EventID)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range currencies {
+		if existing != tier.Price.Currency {
+			return 0, money.ErrCurrencyMismatch
+		}
+	}
+
+	tiers, err := svc.repo.GetEventGATiers(ctx, tier.EventID)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range tiers {
+		if existing.Name == tier.Name {
+			return 0, ErrGATierExists
+		}
+	}
+
+	return svc.repo.CreateGATier(ctx, tier)
+}
+
+// getRemainingQuantity counts how much of the tier's capacity is left to
+// purchase, once the quantity held by purchase holds is accounted for.
+func (svc *TicketsService) getRemainingQuantity(ctx context.Context, tier entities.GATier) (int32, error) {
+	key := svc.ticketHoldClient.MakeReservationsKey(tier.ID)
+	reserved, err := svc.ticketHoldClient.GetReservedQuantity(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return max(tier.Capacity-tier.Sold-int32(reserved), 0), nil
+}
+
+// GetAvailableInventory fetches the available inventory for the event given by
+// the event id: seated tickets that aren't purchased or held, grouped by seat,
+// ticket type and release, along with their types and releases, the quantity
+// left of each general admission tier, and the resale listings that aren't
+// held. The inventory of an event that's sold out is empty, whereas
+// `ErrNoSuchEntity` is returned if the event doesn't exist.
+func (svc *TicketsService) GetAvailableInventory(ctx context.Context, eventID int32) (entities.AvailableInventory, error) {
+	aggregates, err := svc.GetAvailableTickets(ctx, eventID)
+	if err != nil {
+		return entities.AvailableInventory{}, err
+	}
+
+	tiers, err := svc.repo.GetEventGATiers(ctx, eventID)
+	if err != nil {
+		return entities.AvailableInventory{}, err
+	}
+
+	ticketTypeIDs := make([]int32, len(aggregates))
+	releaseIDs := make([]int32, len(aggregates))
+	for idx, aggregate := range aggregates {
+		ticketTypeIDs[idx] = aggregate.TicketTypeID
+		releaseIDs[idx] = aggregate.ReleaseID
+	}
+	ticketTypes, err := svc.getTicketTypesByID(ctx, ticketTypeIDs)
+	if err != nil {
+		return entities.AvailableInventory{}, err
+	}
+	releases, err := svc.getReleasesByID(ctx, releaseIDs)
+	if err != nil {
+		return entities.AvailableInventory{}, err
+	}
+	resale, err := svc.getAvailableResaleListings(ctx, eventID)
+	if err != nil {
+		return entities.AvailableInventory{}, err
+	}
+
+	inventory := entities.AvailableInventory{
+		Seated:           aggregates,
+		GeneralAdmission: make([]entities.AvailableGATier, len(tiers)),
+		TicketTypes:      ticketTypes,
+		Releases:         releases,
+		Resale:           resale,
+	}
+	if inventory.Seated == nil {
+		inventory.Seated = []entities.AvailableTicketAggregate{}
+	}
+	for idx, tier := range tiers {
+		remaining, err := svc.getRemainingQuantity(ctx, tier)
+		if err != nil {
+			return entities.AvailableInventory{}, err
+		}
+		inventory.GeneralAdmission[idx] = entities.AvailableGATier{
+			ID:        tier.ID,
+			Name:      tier.Name,
+			Price:     tier.Price,
+			Remaining: remaining,
+		}
+	}
+	return inventory, nil
+}
+
+// HoldGATier places a time-bounded purchase hold on `quantity` of the general
+// admission tier given by `tierID`. The quantity is reserved in the cache,
+// against the tier's capacity that hasn't been sold, rather than against
+// particular tickets - the tickets are only issued once purchased. The
+// returned hold's token identifies the hold for purchase. If the tier's event
+// has a waiting room, `access` must admit the holder. The held quantity counts
+// against the event's limit on tickets per user, if it has one.
+func (svc *TicketsService) HoldGATier(
+	ctx context.Context,
+	tierID int32,
+	quantity int32,
+	holderID string,
+	access entities.SaleAccess,
+) (hold entities.GATierHold, err error) {
+	if holderID == "" {
+		err = ErrInvalidHoldID
+		return
+	}
+	if quantity < 1 {
+		err = ErrEmptyHold
+		return
+	}
+
+	tier, err := svc.repo.GetGATier(ctx, tierID)
+	if err != nil {
+		return
+	}
+	if err = svc.checkAdmission(ctx, []int32{tier.EventID}, holderID, access); err != nil {
+		return
+	}
+
+	return svc.placeGATierHold(ctx, tier, quantity, holderID, svc.TicketHoldDuration)
+}
+
+// placeGATierHold places a purchase hold on `quantity` of the tier for
+// `holderID`, lasting `duration`, and reserves the quantity against the tier's
+// event's limit on tickets per user.
+func (svc *TicketsService) placeGATierHold(
+	ctx context.Context,
+	tier entities.GATier,
+	quantity int32,
+	holderID string,
+	duration time.Duration,
+) (hold entities.GATierHold, err error) {
+	token, err := newHoldToken()
+	if err != nil {
+		return
+	}
+	record, err := json.Marshal(ticketHoldRecord{
+		HolderID:  holderID,
+		TicketIDs: []int32{},
+		GATierID:  tier.ID,
+		Quantity:  quantity,
+	})
+	if err != nil {
+		return
+	}
+
+	counts := map[int32]int32{tier.EventID: quantity}
+	limitedEventIDs, err := svc.reservePurchaseLimits(ctx, holderID, token, counts, duration)
+	if err != nil {
+		return
+	}
+	releaseLimits := func() {
+		svc.releasePurchaseLimits(context.WithoutCancel(ctx), holderID, token, limitedEventIDs)
+	}
+
+	key := svc.ticketHoldClient.MakeReservationsKey(tier.ID)
+	available := int(tier.Capacity - tier.Sold)
+	expiresAt := time.Now().Add(duration)
+	err = svc.ticketHoldClient.ReserveQuantity(ctx, key, token, int(quantity), available, duration)
+	if err != nil {
+		releaseLimits()
+		if errors.Is(err, cache.ErrInsufficientQuantity) {
+			err = ErrGATierSoldOut
+		}
+		return
+	}
+
+	holdKey := svc.ticketHoldClient.MakeHoldKey(token)
+	if err = svc.ticketHoldClient.Set(ctx, holdKey, string(record), duration); err != nil {
+		// Without its record, the hold can't be purchased, so its reservations
+		// are released rather than left to expire.
+		releaseLimits()
+		releaseErr := svc.ticketHoldClient.ReleaseQuantity(context.WithoutCancel(ctx), key, token)
+		err = errors.Join(err, releaseErr)
+		return
+	}
+
+	hold = entities.GATierHold{
+		Token:     token,
+		HolderID:  holderID,
+		GATierID:  tier.ID,
+		Quantity:  quantity,
+		ExpiresAt: expiresAt,
+	}
+	return
+}
+
+// lockHold acquires the purchase lock on the hold given by `token`, so that a
+// hold on a general admission tier can't be purchased more than once
+// concurrently. The returned function releases the lock.
+func (svc *TicketsService) lockHold(ctx context.Context, token string) (func(), error) {
+	lockToken, err := newHoldToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s:lock", svc.ticketHoldClient.MakeHoldKey(token))
+	if err := svc.ticketHoldClient.Set(ctx, key, lockToken, purchaseLockDuration); err != nil {
+		if errors.Is(err, cache.ErrAlreadyHasHold) {
+			return nil, ErrPurchaseInProgress
+		}
+		return nil, err
+	}
+
+	unlock := func() {
+		svc.ticketHoldClient.CompareAndDelete(context.WithoutCancel(ctx), key, lockToken)
+	}
+	return unlock, nil
+}
+
+// purchaseGATierHold purchases the quantity of a general admission tier held by
+// `holds`. The tickets are issued against the tier's capacity, and then
+// purchased as a set of held tickets. If the purchase doesn't go through, the
+// issued tickets are returned to the tier's capacity, and the hold is kept so
+// that the purchase can be retried.
+func (svc *TicketsService) purchaseGATierHold(
+	ctx context.Context,
+	token string,
+	record ticketHoldRecord,
+	holds map[string]string,
+	purchaserID int32,
+	card payment.Card,
+	promoCode string,
+) (purchase entities.PurchaseResult, err error) {
+	unlock, err := svc.lockHold(ctx, token)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	if err = svc.checkHolds(ctx, holds); err != nil {
+		return
+	}
+
+	ticketIDs, err := svc.repo.IssueGATickets(ctx, record.GATierID, record.Quantity)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoCapacityLeft) {
+			err = ErrGATierSoldOut
+		}
+		return
+	}
+
+	// Tickets issued for a tier aren't part of a release, so are always on sale.
+	access := entities.SaleAccess{UserID: purchaserID}
+	purchase, err = svc.purchaseTickets(
+		ctx,
+		ticketIDs,
+		holds,
+		record.HolderID,
+		token,
+		purchaserID,
+		card,
+		promoCode,
+		access,
+	)
+	if err != nil || !purchase.Accepted {
+		returnErr := svc.repo.ReturnGATickets(context.WithoutCancel(ctx), ticketIDs)
+		err = errors.Join(err, returnErr)
+		return
+	}
+
+	// The tickets are counted as sold now, so the reservation is released.
+	// Failing to release it isn't an error, as it still expires.
+	key := svc.ticketHoldClient.MakeReservationsKey(record.GATierID)
+	svc.ticketHoldClient.ReleaseQuantity(ctx, key, token)
+	return
+}
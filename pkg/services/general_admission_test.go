@@ -0,0 +1,268 @@
+package services_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTicketsServiceCreateGATierWhenEventHasOtherCurrency(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventCurrencies", mock.Anything, int32(1)).Return([]string{"EUR"}, nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	tier := entities.GATier{EventID: 1, Name: "Floor", Price: money.New(1000, "USD"), Capacity: 100}
+	_, err := service.CreateGATier(context.Background(), tier)
+
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+	mockRepo.AssertNotCalled(t, "CreateGATier", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceCreateGATierWhenNameTaken(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventCurrencies", mock.Anything, int32(1)).Return([]string{"USD"}, nil)
+	mockRepo.On("GetEventGATiers", mock.Anything, int32(1)).Return(
+		[]entities.GATier{{ID: 2, EventID: 1, Name: "Floor"}},
+		nil,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	tier := entities.GATier{EventID: 1, Name: "Floor", Price: money.New(1000, "USD"), Capacity: 100}
+	_, err := service.CreateGATier(context.Background(), tier)
+
+	assert.ErrorIs(t, err, services.ErrGATierExists)
+}
+
+func TestTicketsServiceGetAvailableInventory(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetAvailableTickets", mock.Anything, int32(1)).Return([]entities.Ticket{}, nil)
+	mockRepo.On("GetEventGATiers", mock.Anything, int32(1)).Return(
+		[]entities.GATier{
+			{ID: 2, EventID: 1, Name: "Floor", Price: money.New(1000, "USD"), Capacity: 100, Sold: 60},
+			{ID: 3, EventID: 1, Name: "Pit", Price: money.New(2000, "USD"), Capacity: 10, Sold: 8},
+		},
+		nil,
+	)
+	mockRepo.On("GetEventResaleListings", mock.Anything, int32(1)).Return(
+		[]entities.AvailableResaleListing{
+			{ID: 4, TicketID: 10, Seat: "A1", Price: money.New(1500, "USD"), FaceValue: money.New(1000, "USD")},
+			{ID: 5, TicketID: 11, Seat: "A2", Price: money.New(1800, "USD"), FaceValue: money.New(1000, "USD")},
+		},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", int32(10)).Return("ticket:10")
+	mockClient.On("MakeKey", int32(11)).Return("ticket:11")
+	// The second listing is held.
+	mockClient.On("GetMany", mock.Anything, []string{"ticket:10", "ticket:11"}).Return(
+		map[string]string{"ticket:11": "token"},
+		nil,
+	)
+	mockClient.On("MakeReservationsKey", int32(2)).Return("ga:2")
+	mockClient.On("MakeReservationsKey", int32(3)).Return("ga:3")
+	mockClient.On("GetReservedQuantity", mock.Anything, "ga:2").Return(15, nil)
+	// Holds may briefly over-count while their purchase is in progress.
+	mockClient.On("GetReservedQuantity", mock.Anything, "ga:3").Return(4, nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	inventory, err := service.GetAvailableInventory(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.Empty(t, inventory.Seated)
+	assert.Equal(
+		t,
+		[]entities.AvailableGATier{
+			{ID: 2, Name: "Floor", Price: money.New(1000, "USD"), Remaining: 25},
+			{ID: 3, Name: "Pit", Price: money.New(2000, "USD"), Remaining: 0},
+		},
+		inventory.GeneralAdmission,
+	)
+	assert.Equal(
+		t,
+		[]entities.AvailableResaleListing{
+			{ID: 4, TicketID: 10, Seat: "A1", Price: money.New(1500, "USD"), FaceValue: money.New(1000, "USD")},
+		},
+		inventory.Resale,
+	)
+}
+
+func TestTicketsServiceGetAvailableInventoryWhenSoldOut(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	// The event exists, but all of its seated tickets have been purchased.
+	mockRepo.On("GetAvailableTickets", mock.Anything, int32(1)).Return([]entities.Ticket{}, nil)
+	mockRepo.On("GetEventGATiers", mock.Anything, int32(1)).Return(
+		[]entities.GATier{
+			{ID: 2, EventID: 1, Name: "Floor", Price: money.New(1000, "USD"), Capacity: 100, Sold: 100},
+			{ID: 3, EventID: 1, Name: "Pit", Price: money.New(2000, "USD"), Capacity: 10, Sold: 8},
+		},
+		nil,
+	)
+	mockRepo.On("GetEventResaleListings", mock.Anything, int32(1)).Return(
+		[]entities.AvailableResaleListing{
+			{ID: 4, TicketID: 10, Seat: "A1", Price: money.New(1500, "USD"), FaceValue: money.New(1000, "USD")},
+		},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", int32(10)).Return("ticket:10")
+	mockClient.On("GetMany", mock.Anything, []string{"ticket:10"}).Return(map[string]string{}, nil)
+	mockClient.On("MakeReservationsKey", int32(2)).Return("ga:2")
+	mockClient.On("MakeReservationsKey", int32(3)).Return("ga:3")
+	mockClient.On("GetReservedQuantity", mock.Anything, "ga:2").Return(0, nil)
+	// What's left of the second tier is held.
+	mockClient.On("GetReservedQuantity", mock.Anything, "ga:3").Return(2, nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	inventory, err := service.GetAvailableInventory(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, inventory.Seated)
+	assert.Empty(t, inventory.Seated)
+	assert.Equal(
+		t,
+		[]entities.AvailableGATier{
+			{ID: 2, Name: "Floor", Price: money.New(1000, "USD"), Remaining: 0},
+			{ID: 3, Name: "Pit", Price: money.New(2000, "USD"), Remaining: 0},
+		},
+		inventory.GeneralAdmission,
+	)
+	// Resale listings don't keep the event from being sold out.
+	assert.Len(t, inventory.Resale, 1)
+	assert.True(t, inventory.IsSoldOut())
+}
+
+func TestTicketsServiceGetAvailableInventoryWhenEventDoesntExist(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetAvailableTickets", mock.Anything, int32(1)).Return([]entities.Ticket{}, repos.ErrNoSuchEntity)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.GetAvailableInventory(context.Background(), 1)
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	mockRepo.AssertNotCalled(t, "GetEventGATiers", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceHoldGATier(t *testing.T) {
+	ticketHoldDuration := time.Minute
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetGATier", mock.Anything, int32(2)).Return(
+		entities.GATier{ID: 2, EventID: 1, Name: "Floor", Price: money.New(1000, "USD"), Capacity: 100, Sold: 60},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeReservationsKey", int32(2)).Return("ga:2")
+	mockClient.On("MakeHoldKey", mock.Anything).Return("hold")
+	mockClient.On("ReserveQuantity", mock.Anything, "ga:2", mock.Anything, 4, 40, ticketHoldDuration).Return(nil)
+	mockClient.On("Set", mock.Anything, "hold", mock.Anything, ticketHoldDuration).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	hold, err := service.HoldGATier(context.Background(), 2, 4, "123", entities.SaleAccess{})
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, hold.Token)
+	assert.Equal(t, int32(2), hold.GATierID)
+	assert.Equal(t, int32(4), hold.Quantity)
+
+	// The hold is reserved and recorded under the same token.
+	mockClient.AssertCalled(t, "ReserveQuantity", mock.Anything, "ga:2", hold.Token, 4, 40, ticketHoldDuration)
+	record := mockClient.Calls[len(mockClient.Calls)-1].Arguments.String(2)
+	assert.JSONEq(t, `{"holder_id": "123", "ticket_ids": [], "ga_tier_id": 2, "quantity": 4}`, record)
+}
+
+func TestTicketsServiceHoldGATierWhenSoldOut(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetGATier", mock.Anything, int32(2)).Return(
+		entities.GATier{ID: 2, EventID: 1, Name: "Floor", Price: money.New(1000, "USD"), Capacity: 100, Sold: 98},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeReservationsKey", int32(2)).Return("ga:2")
+	mockClient.On("ReserveQuantity", mock.Anything, "ga:2", mock.Anything, 4, 2, mock.Anything).Return(cache.ErrInsufficientQuantity)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.HoldGATier(context.Background(), 2, 4, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrGATierSoldOut)
+	mockClient.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServicePurchaseHeldTicketsForGATierWhenPaymentDeclined(t *testing.T) {
+	token := "abc"
+	holdID := "123"
+	record := `{"holder_id": "123", "ticket_ids": [], "ga_tier_id": 2, "quantity": 2}`
+	ticketIDs := []int32{7, 8}
+	rules := []payment.FakeRule{
+		{Pattern: regexp.MustCompile(`^4000`), DeclineReason: payment.DeclineReasonInsufficientFunds},
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("IssueGATickets", mock.Anything, int32(2), int32(2)).Return(ticketIDs, nil)
+	mockRepo.On("GetTickets", mock.Anything, ticketIDs).Return(
+		[]entities.Ticket{
+			{ID: 7, EventID: 1, Price: money.New(1000, "USD"), Seat: "Floor"},
+			{ID: 8, EventID: 1, Price: money.New(1000, "USD"), Seat: "Floor"},
+		},
+		nil,
+	)
+	mockRepo.On("ReturnGATickets", mock.Anything, ticketIDs).Return(nil)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", token).Return("hold:abc")
+	mockClient.On("Get", mock.Anything, "hold:abc").Return(record, nil)
+	mockClient.On("Set", mock.Anything, "hold:abc:lock", mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{"hold:abc"}).Return(map[string]string{"hold:abc": record}, nil)
+	mockClient.On("MakeLockKey", int32(7)).Return("lock:7")
+	mockClient.On("MakeLockKey", int32(8)).Return("lock:8")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		payment.NewFakeProcessor(rules, 0),
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	purchase, err := service.PurchaseHeldTickets(
+		context.Background(),
+		token,
+		holdID,
+		int32(123),
+		payment.Card{Number: "4000000000000000"},
+		"",
+		"",
+	)
+
+	assert.Nil(t, err)
+	assert.False(t, purchase.Accepted)
+
+	// The issued tickets are returned to the tier, and the hold is kept.
+	mockRepo.AssertCalled(t, "ReturnGATickets", mock.Anything, ticketIDs)
+	mockClient.AssertNotCalled(t, "ReleaseQuantity", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+)
+
+// PricingRepoer provides necessary methods for database operations against
+// fee rules and tax rates.
+type PricingRepoer interface {
+	GetApplicableFeeRule(context.Context, int32) (entities.FeeRule, error)
+	GetEventTaxRate(context.Context, int32) (int32, error)
+	GetVenueFeeRule(context.Context, int32) (entities.FeeRule, error)
+	GetEventFeeRule(context.Context, int32) (entities.FeeRule, error)
+	SetVenueFeeRule(context.Context, entities.FeeRule) error
+	SetEventFeeRule(context.Context, entities.FeeRule) error
+	SetTaxRate(context.Context, entities.TaxRate) error
+}
+
+// DiscountTickets gives the discount, by ticket id, for each of the tickets
+// that the promo code applies to. A fixed discount is taken from the tickets in
+// order, up to each ticket's face value.
+func DiscountTickets(tickets []entities.Ticket, code entities.PromoCode) (map[int32]money.Money, error) {
+	discounts := make(map[int32]money.Money)
+	remaining := code.AmountOff.Amount
+	for _, ticket := range tickets {
+		if code.EventID != 0 && ticket.EventID != code.EventID {
+			continue
+		}
+
+		switch code.DiscountType {
+		case entities.DiscountTypePercentage:
+			discounts[ticket.ID] = ticket.Price.ApplyRate(int64(code.PercentOff))
+		case entities.DiscountTypeFixed:
+			if ticket.Price.Currency != code.AmountOff.Currency {
+				continue
+			}
+			amount := min(remaining, ticket.Price.Amount)
+			remaining -= amount
+			discounts[ticket.ID] = money.New(amount, ticket.Price.Currency)
+		}
+	}
+
+	if len(discounts) == 0 {
+		return nil, ErrPromoCodeNotApplicable
+	}
+	return discounts, nil
+}
+
+// PriceTickets itemizes the price of purchasing tickets for an event together,
+// under the event's fee rule and tax rate, and less any discounts given by
+// ticket id. Each ticket is charged a service fee on its face value, and is
+// taxed on its discounted face value and service fee. The order is charged a
+// single facility fee, which is also taxed.
+func PriceTickets(
+	tickets []entities.Ticket,
+	discounts map[int32]money.Money,
+	rule entities.FeeRule,
+	taxRate int32,
+) (entities.Quote, error) {
+	if len(tickets) == 0 {
+		return entities.Quote{Items: []entities.QuoteItem{}}, nil
+	}
+
+	currency := tickets[0].Price.Currency
+	facilityFee := money.New(rule.FacilityFee, currency)
+	items := make([]entities.QuoteItem, len(tickets))
+	faceValues := make([]money.Money, len(tickets))
+	itemDiscounts := make([]money.Money, len(tickets))
+	serviceFees := make([]money.Money, len(tickets))
+	taxes := make([]money.Money, len(tickets)+1)
+	taxes[len(tickets)] = facilityFee.ApplyRate(int64(taxRate))
+
+	for idx, ticket := range tickets {
+		if ticket.Price.Currency != currency {
+			return entities.Quote{}, money.ErrCurrencyMismatch
+		}
+
+		discount, ok := discounts[ticket.ID]
+		if !ok {
+			discount = money.New(0, currency)
+		}
+		serviceFee := ticket.Price.ApplyRate(int64(rule.ServiceFeeRate))
+		serviceFee.Amount += rule.ServiceFeeFlat
+		taxable := money.New(ticket.Price.Amount-discount.Amount+serviceFee.Amount, currency)
+		tax := taxable.ApplyRate(int64(taxRate))
+
+		items[idx] = entities.QuoteItem{
+			TicketID:   ticket.ID,
+			FaceValue:  ticket.Price,
+			Discount:   discount,
+			ServiceFee: serviceFee,
+			Tax:        tax,
+			Total:      money.New(taxable.Amount+tax.Amount, currency),
+		}
+		faceValues[idx] = ticket.Price
+		itemDiscounts[idx] = discount
+		serviceFees[idx] = serviceFee
+		taxes[idx] = tax
+	}
+
+	// The amounts are all of the same currency, so can't fail to be summed.
+	quote := entities.Quote{Items: items, FacilityFee: facilityFee}
+	quote.FaceValue, _ = money.Sum(faceValues...)
+	quote.Discount, _ = money.Sum(itemDiscounts...)
+	quote.ServiceFees, _ = money.Sum(serviceFees...)
+	quote.Tax, _ = money.Sum(taxes...)
+	quote.Total, _ = money.Sum(quote.FaceValue, quote.ServiceFees, quote.FacilityFee, quote.Tax)
+	quote.Total.Amount -= quote.Discount.Amount
+	return quote, nil
+}
+
+// combineQuotes adds the items and amounts of `other` to `quote`.
+func combineQuotes(quote entities.Quote, other entities.Quote) (entities.Quote, error) {
+	combined := entities.Quote{Items: append(quote.Items, other.Items...)}
+	var err error
+	if combined.FaceValue, err = quote.FaceValue.Add(other.FaceValue); err != nil {
+		return entities.Quote{}, err
+	}
+	if combined.Discount, err = quote.Discount.Add(other.Discount); err != nil {
+		return entities.Quote{}, err
+	}
+	if combined.ServiceFees, err = quote.ServiceFees.Add(other.ServiceFees); err != nil {
+		return entities.Quote{}, err
+	}
+	if combined.FacilityFee, err = quote.FacilityFee.Add(other.FacilityFee); err != nil {
+		return entities.Quote{}, err
+	}
+	if combined.Tax, err = quote.Tax.Add(other.Tax); err != nil {
+		return entities.Quote{}, err
+	}
+	if combined.Total, err = quote.Total.Add(other.Total); err != nil {
+		return entities.Quote{}, err
+	}
+	return combined, nil
+}
+
+type PricingService struct {
+	repo PricingRepoer
+}
+
+func NewPricingService(repo PricingRepoer) *PricingService {
+	return &PricingService{repo: repo}
+}
+
+// Quote itemizes the price of purchasing tickets together, with the promo code
+// applied if one is given. Tickets for each event are priced under that
+// event's fee rule and the tax rate for its venue's region, with a facility fee
+// charged for each event.
+func (svc *PricingService) Quote(
+	ctx context.Context,
+	tickets []entities.Ticket,
+	promoCode *entities.PromoCode,
+) (entities.Quote, error) {
+	var discounts map[int32]money.Money
+	if promoCode != nil {
+		var err error
+		if discounts, err = DiscountTickets(tickets, *promoCode); err != nil {
+			return entities.Quote{}, err
+		}
+	}
+
+	eventIDs := make([]int32, 0)
+	grouped := make(map[int32][]entities.Ticket)
+	for _, ticket := range tickets {
+		if _, ok := grouped[ticket.EventID]; !ok {
+			eventIDs = append(eventIDs, ticket.EventID)
+		}
+		grouped[ticket.EventID] = append(grouped[ticket.EventID], ticket)
+	}
+
+	quote := entities.Quote{Items: []entities.QuoteItem{}}
+	for idx, eventID := range eventIDs {
+		rule, err := svc.repo.GetApplicableFeeRule(ctx, eventID)
+		if err != nil {
+			return entities.Quote{}, err
+		}
+		taxRate, err := svc.repo.GetEventTaxRate(ctx, eventID)
+		if err != nil {
+			return entities.Quote{}, err
+		}
+
+		eventQuote, err := PriceTickets(grouped[eventID], discounts, rule, taxRate)
+		if err != nil {
+			return entities.Quote{}, err
+		}
+		if idx == 0 {
+			quote = eventQuote
+			continue
+		}
+		if quote, err = combineQuotes(quote, eventQuote); err != nil {
+			return entities.Quote{}, err
+		}
+	}
+
+	if promoCode != nil {
+		quote.PromoCodeID = promoCode.ID
+	}
+	return quote, nil
+}
+
+func (svc *PricingService) GetVenueFeeRule(ctx context.Context, venueID int32) (entities.FeeRule, error) {
+	return svc.repo.GetVenueFeeRule(ctx, venueID)
+}
+
+func (svc *PricingService) GetEventFeeRule(ctx context.Context, eventID int32) (entities.FeeRule, error) {
+	return svc.repo.GetEventFeeRule(ctx, eventID)
+}
+
+func (svc *PricingService) SetVenueFeeRule(ctx context.Context, rule entities.FeeRule) error {
+	return svc.repo.SetVenueFeeRule(ctx, rule)
+}
+
+func (svc *PricingService) SetEventFeeRule(ctx context.Context, rule entities.FeeRule) error {
+	return svc.repo.SetEventFeeRule(ctx, rule)
+}
+
+func (svc *PricingService) SetTaxRate(ctx context.Context, rate entities.TaxRate) error {
+	return svc.repo.SetTaxRate(ctx, rate)
+}
@@ -0,0 +1,229 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPricingRepo struct {
+	mock.Mock
+}
+
+func (mock *MockPricingRepo) GetApplicableFeeRule(ctx context.Context, eventID int32) (entities.FeeRule, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(entities.FeeRule), args.Error(1)
+}
+
+func (mock *MockPricingRepo) GetEventTaxRate(ctx context.Context, eventID int32) (int32, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockPricingRepo) GetVenueFeeRule(ctx context.Context, venueID int32) (entities.FeeRule, error) {
+	args := mock.Called(ctx, venueID)
+	return args.Get(0).(entities.FeeRule), args.Error(1)
+}
+
+func (mock *MockPricingRepo) GetEventFeeRule(ctx context.Context, eventID int32) (entities.FeeRule, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(entities.FeeRule), args.Error(1)
+}
+
+func (mock *MockPricingRepo) SetVenueFeeRule(ctx context.Context, rule entities.FeeRule) error {
+	args := mock.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (mock *MockPricingRepo) SetEventFeeRule(ctx context.Context, rule entities.FeeRule) error {
+	args := mock.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (mock *MockPricingRepo) SetTaxRate(ctx context.Context, rate entities.TaxRate) error {
+	args := mock.Called(ctx, rate)
+	return args.Error(0)
+}
+
+// newPricingService creates a pricing service that prices tickets for every
+// event under the same fee rule and tax rate.
+func newPricingService(rule entities.FeeRule, taxRate int32) *services.PricingService {
+	mockRepo := new(MockPricingRepo)
+	mockRepo.On("GetApplicableFeeRule", mock.Anything, mock.Anything).Return(rule, nil)
+	mockRepo.On("GetEventTaxRate", mock.Anything, mock.Anything).Return(taxRate, nil)
+	return services.NewPricingService(mockRepo)
+}
+
+func TestPriceTickets(t *testing.T) {
+	tickets := []entities.Ticket{
+		{ID: 1, EventID: 1, Price: money.New(1000, "USD")},
+		{ID: 2, EventID: 1, Price: money.New(2500, "USD")},
+	}
+	// A 10% + 0.50 service fee per ticket, 3.00 facility fee per order, and
+	// 8.25% tax.
+	rule := entities.FeeRule{EventID: 1, ServiceFeeRate: 1000, ServiceFeeFlat: 50, FacilityFee: 300}
+
+	actual, err := services.PriceTickets(tickets, nil, rule, 825)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.Quote{
+		Items: []entities.QuoteItem{
+			{
+				TicketID:   1,
+				FaceValue:  money.New(1000, "USD"),
+				Discount:   money.New(0, "USD"),
+				ServiceFee: money.New(150, "USD"),
+				Tax:        money.New(95, "USD"),
+				Total:      money.New(1245, "USD"),
+			},
+			{
+				TicketID:   2,
+				FaceValue:  money.New(2500, "USD"),
+				Discount:   money.New(0, "USD"),
+				ServiceFee: money.New(300, "USD"),
+				Tax:        money.New(231, "USD"),
+				Total:      money.New(3031, "USD"),
+			},
+		},
+		FaceValue:   money.New(3500, "USD"),
+		Discount:    money.New(0, "USD"),
+		ServiceFees: money.New(450, "USD"),
+		FacilityFee: money.New(300, "USD"),
+		Tax:         money.New(351, "USD"),
+		Total:       money.New(4601, "USD"),
+	}, actual)
+}
+
+func TestPriceTicketsWithoutFeesOrTax(t *testing.T) {
+	tickets := []entities.Ticket{{ID: 1, EventID: 1, Price: money.New(1000, "EUR")}}
+
+	actual, err := services.PriceTickets(tickets, nil, entities.FeeRule{}, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, money.New(1000, "EUR"), actual.Total)
+	assert.Equal(t, money.New(0, "EUR"), actual.Tax)
+}
+
+func TestPriceTicketsWhenCurrenciesDiffer(t *testing.T) {
+	tickets := []entities.Ticket{
+		{ID: 1, EventID: 1, Price: money.New(1000, "USD")},
+		{ID: 2, EventID: 1, Price: money.New(1000, "EUR")},
+	}
+
+	_, err := services.PriceTickets(tickets, nil, entities.FeeRule{}, 0)
+
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
+
+func TestPriceTicketsWithDiscounts(t *testing.T) {
+	tickets := []entities.Ticket{{ID: 1, EventID: 1, Price: money.New(1000, "USD")}}
+	discounts := map[int32]money.Money{1: money.New(200, "USD")}
+	// The service fee is charged on the face value, but tax is charged on the
+	// discounted face value.
+	rule := entities.FeeRule{EventID: 1, ServiceFeeRate: 1000}
+
+	actual, err := services.PriceTickets(tickets, discounts, rule, 1000)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.QuoteItem{
+		TicketID:   1,
+		FaceValue:  money.New(1000, "USD"),
+		Discount:   money.New(200, "USD"),
+		ServiceFee: money.New(100, "USD"),
+		Tax:        money.New(90, "USD"),
+		Total:      money.New(990, "USD"),
+	}, actual.Items[0])
+	assert.Equal(t, money.New(200, "USD"), actual.Discount)
+	assert.Equal(t, money.New(990, "USD"), actual.Total)
+}
+
+func TestDiscountTicketsPercentage(t *testing.T) {
+	tickets := []entities.Ticket{
+		{ID: 1, EventID: 1, Price: money.New(1000, "USD")},
+		{ID: 2, EventID: 2, Price: money.New(2000, "USD")},
+	}
+	code := entities.PromoCode{DiscountType: entities.DiscountTypePercentage, PercentOff: 2500, EventID: 1}
+
+	actual, err := services.DiscountTickets(tickets, code)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[int32]money.Money{1: money.New(250, "USD")}, actual)
+}
+
+func TestDiscountTicketsFixed(t *testing.T) {
+	tickets := []entities.Ticket{
+		{ID: 1, EventID: 1, Price: money.New(1000, "USD")},
+		{ID: 2, EventID: 1, Price: money.New(2500, "USD")},
+		{ID: 3, EventID: 1, Price: money.New(500, "USD")},
+	}
+	code := entities.PromoCode{DiscountType: entities.DiscountTypeFixed, AmountOff: money.New(1500, "USD")}
+
+	actual, err := services.DiscountTickets(tickets, code)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[int32]money.Money{
+		1: money.New(1000, "USD"),
+		2: money.New(500, "USD"),
+		3: money.New(0, "USD"),
+	}, actual)
+}
+
+func TestDiscountTicketsWhenNotApplicable(t *testing.T) {
+	tickets := []entities.Ticket{{ID: 1, EventID: 1, Price: money.New(1000, "USD")}}
+
+	type testCase struct {
+		Name string
+		Code entities.PromoCode
+	}
+
+	testCases := []testCase{
+		{
+			Name: "OtherEvent",
+			Code: entities.PromoCode{DiscountType: entities.DiscountTypePercentage, PercentOff: 1000, EventID: 2},
+		},
+		{
+			Name: "OtherCurrency",
+			Code: entities.PromoCode{DiscountType: entities.DiscountTypeFixed, AmountOff: money.New(100, "EUR")},
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := services.DiscountTickets(tickets, tc.Code)
+		assert.ErrorIs(t, err, services.ErrPromoCodeNotApplicable, tc.Name)
+	}
+}
+
+func TestPricingServiceQuote(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{
+		{ID: 1, EventID: 1, Price: money.New(1000, "USD")},
+		{ID: 2, EventID: 2, Price: money.New(2000, "USD")},
+		{ID: 3, EventID: 1, Price: money.New(1000, "USD")},
+	}
+
+	mockRepo := new(MockPricingRepo)
+	mockRepo.On("GetApplicableFeeRule", ctx, int32(1)).Return(entities.FeeRule{FacilityFee: 100}, nil)
+	mockRepo.On("GetApplicableFeeRule", ctx, int32(2)).Return(entities.FeeRule{ServiceFeeFlat: 200}, nil)
+	mockRepo.On("GetEventTaxRate", ctx, int32(1)).Return(int32(0), nil)
+	mockRepo.On("GetEventTaxRate", ctx, int32(2)).Return(int32(1000), nil)
+
+	service := services.NewPricingService(mockRepo)
+	actual, err := service.Quote(ctx, tickets, nil)
+
+	assert.Nil(t, err)
+	itemIDs := make([]int32, len(actual.Items))
+	for idx, item := range actual.Items {
+		itemIDs[idx] = item.TicketID
+	}
+	assert.ElementsMatch(t, []int32{1, 2, 3}, itemIDs)
+	assert.Equal(t, money.New(4000, "USD"), actual.FaceValue)
+	assert.Equal(t, money.New(200, "USD"), actual.ServiceFees)
+	assert.Equal(t, money.New(100, "USD"), actual.FacilityFee)
+	assert.Equal(t, money.New(220, "USD"), actual.Tax)
+	assert.Equal(t, money.New(4520, "USD"), actual.Total)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+)
+
+// PromoCodesRepoer provides necessary methods for database operations against
+// promo codes.
+type PromoCodesRepoer interface {
+	CreatePromoCode(context.Context, entities.PromoCode) (int32, error)
+	GetPromoCode(context.Context, int32) (entities.PromoCode, error)
+	GetPromoCodeByCode(context.Context, string) (entities.PromoCode, error)
+	GetUserPromoCodeRedemptions(context.Context, int32, int32) (int32, error)
+	UpdatePromoCode(context.Context, entities.PromoCode) error
+	DeletePromoCode(context.Context, int32) error
+}
+
+// NormalizePromoCode gives the form that a promo code is stored in, so that
+// codes are matched regardless of case.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+type PromoCodesService struct {
+	repo PromoCodesRepoer
+}
+
+func NewPromoCodesService(repo PromoCodesRepoer) *PromoCodesService {
+	return &PromoCodesService{repo: repo}
+}
+
+// checkCodeIsFree checks that no other promo code than the one given by id has
+// the code.
+func (svc *PromoCodesService) checkCodeIsFree(ctx context.Context, code string, id int32) error {
+	existing, err := svc.repo.GetPromoCodeByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != id {
+		return ErrPromoCodeExists
+	}
+	return nil
+}
+
+// CreatePromoCode creates a new promo code and returns the new entity's id.
+func (svc *PromoCodesService) CreatePromoCode(ctx context.Context, promoCode entities.PromoCode) (int32, error) {
+	promoCode.Code = NormalizePromoCode(promoCode.Code)
+	if err := svc.checkCodeIsFree(ctx, promoCode.Code, 0); err != nil {
+		return 0, err
+	}
+	return svc.repo.CreatePromoCode(ctx, promoCode)
+}
+
+// GetPromoCode fetches a promo code given by the id.
+func (svc *PromoCodesService) GetPromoCode(ctx context.Context, id int32) (entities.PromoCode, error) {
+	return svc.repo.GetPromoCode(ctx, id)
+}
+
+// UpdatePromoCode updates a promo code given by the id. The number of times
+// that the code has been redeemed is kept.
+func (svc *PromoCodesService) UpdatePromoCode(ctx context.Context, promoCode entities.PromoCode) error {
+	promoCode.Code = NormalizePromoCode(promoCode.Code)
+	if err := svc.checkCodeIsFree(ctx, promoCode.Code, promoCode.ID); err != nil {
+		return err
+	}
+	return svc.repo.UpdatePromoCode(ctx, promoCode)
+}
+
+// DeletePromoCode deletes a promo code given by the id.
+func (svc *PromoCodesService) DeletePromoCode(ctx context.Context, id int32) error {
+	return svc.repo.DeletePromoCode(ctx, id)
+}
+
+// GetRedeemablePromoCode fetches the promo code given by `code`, if it can be
+// redeemed now. If a purchaser is given, the purchaser must also have
+// redemptions of the code left. Redemptions are only counted on purchase, so
+// the code may still be exhausted by then.
+func (svc *PromoCodesService) GetRedeemablePromoCode(
+	ctx context.Context,
+	code string,
+	purchaserID int32,
+) (entities.PromoCode, error) {
+	promoCode, err := svc.repo.GetPromoCodeByCode(ctx, NormalizePromoCode(code))
+	if err != nil {
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			return entities.PromoCode{}, ErrInvalidPromoCode
+		}
+		return entities.PromoCode{}, err
+	}
+
+	if !promoCode.IsRedeemable(time.Now()) {
+		return entities.PromoCode{}, ErrPromoCodeUnavailable
+	}
+
+	if purchaserID != 0 && promoCode.MaxRedemptionsPerUser != 0 {
+		redemptions, err := svc.repo.GetUserPromoCodeRedemptions(ctx, promoCode.ID, purchaserID)
+		if err != nil {
+			return entities.PromoCode{}, err
+		}
+		if redemptions >= promoCode.MaxRedemptionsPerUser {
+			return entities.PromoCode{}, ErrPromoCodeUnavailable
+		}
+	}
+	return promoCode, nil
+}
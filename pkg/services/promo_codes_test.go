@@ -0,0 +1,143 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPromoCodesRepo struct {
+	mock.Mock
+}
+
+func (mock *MockPromoCodesRepo) CreatePromoCode(ctx context.Context, promoCode entities.PromoCode) (int32, error) {
+	args := mock.Called(ctx, promoCode)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockPromoCodesRepo) GetPromoCode(ctx context.Context, id int32) (entities.PromoCode, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(entities.PromoCode), args.Error(1)
+}
+
+func (mock *MockPromoCodesRepo) GetPromoCodeByCode(ctx context.Context, code string) (entities.PromoCode, error) {
+	args := mock.Called(ctx, code)
+	return args.Get(0).(entities.PromoCode), args.Error(1)
+}
+
+func (mock *MockPromoCodesRepo) GetUserPromoCodeRedemptions(
+	ctx context.Context,
+	promoCodeID int32,
+	userID int32,
+) (int32, error) {
+	args := mock.Called(ctx, promoCodeID, userID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockPromoCodesRepo) UpdatePromoCode(ctx context.Context, promoCode entities.PromoCode) error {
+	args := mock.Called(ctx, promoCode)
+	return args.Error(0)
+}
+
+func (mock *MockPromoCodesRepo) DeletePromoCode(ctx context.Context, id int32) error {
+	args := mock.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestPromoCodesServiceCreatePromoCode(t *testing.T) {
+	promoCode := entities.PromoCode{Code: " summer25 ", DiscountType: entities.DiscountTypePercentage, PercentOff: 2500}
+	normalized := promoCode
+	normalized.Code = "SUMMER25"
+
+	mockRepo := new(MockPromoCodesRepo)
+	mockRepo.On("GetPromoCodeByCode", mock.Anything, "SUMMER25").Return(entities.PromoCode{}, repos.ErrNoSuchEntity)
+	mockRepo.On("CreatePromoCode", mock.Anything, normalized).Return(int32(1), nil)
+
+	service := services.NewPromoCodesService(mockRepo)
+	id, err := service.CreatePromoCode(context.Background(), promoCode)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), id)
+}
+
+func TestPromoCodesServiceCreatePromoCodeWhenCodeExists(t *testing.T) {
+	mockRepo := new(MockPromoCodesRepo)
+	mockRepo.On("GetPromoCodeByCode", mock.Anything, "SUMMER25").Return(entities.PromoCode{ID: 1}, nil)
+
+	service := services.NewPromoCodesService(mockRepo)
+	_, err := service.CreatePromoCode(context.Background(), entities.PromoCode{Code: "summer25"})
+
+	assert.ErrorIs(t, err, services.ErrPromoCodeExists)
+	mockRepo.AssertNotCalled(t, "CreatePromoCode", mock.Anything, mock.Anything)
+}
+
+func TestPromoCodesServiceUpdatePromoCodeKeepingCode(t *testing.T) {
+	promoCode := entities.PromoCode{ID: 1, Code: "SUMMER25"}
+
+	mockRepo := new(MockPromoCodesRepo)
+	mockRepo.On("GetPromoCodeByCode", mock.Anything, "SUMMER25").Return(entities.PromoCode{ID: 1}, nil)
+	mockRepo.On("UpdatePromoCode", mock.Anything, promoCode).Return(nil)
+
+	service := services.NewPromoCodesService(mockRepo)
+	err := service.UpdatePromoCode(context.Background(), promoCode)
+
+	assert.Nil(t, err)
+}
+
+func TestPromoCodesServiceGetRedeemablePromoCode(t *testing.T) {
+	promoCode := entities.PromoCode{ID: 1, Code: "SUMMER25", MaxRedemptionsPerUser: 2}
+
+	mockRepo := new(MockPromoCodesRepo)
+	mockRepo.On("GetPromoCodeByCode", mock.Anything, "SUMMER25").Return(promoCode, nil)
+	mockRepo.On("GetUserPromoCodeRedemptions", mock.Anything, int32(1), int32(10)).Return(int32(1), nil)
+
+	service := services.NewPromoCodesService(mockRepo)
+	actual, err := service.GetRedeemablePromoCode(context.Background(), "summer25", 10)
+
+	assert.Nil(t, err)
+	assert.Equal(t, promoCode, actual)
+}
+
+func TestPromoCodesServiceGetRedeemablePromoCodeWhenNoSuchCode(t *testing.T) {
+	mockRepo := new(MockPromoCodesRepo)
+	mockRepo.On("GetPromoCodeByCode", mock.Anything, "SUMMER25").Return(entities.PromoCode{}, repos.ErrNoSuchEntity)
+
+	service := services.NewPromoCodesService(mockRepo)
+	_, err := service.GetRedeemablePromoCode(context.Background(), "summer25", 10)
+
+	assert.ErrorIs(t, err, services.ErrInvalidPromoCode)
+}
+
+func TestPromoCodesServiceGetRedeemablePromoCodeWhenUnavailable(t *testing.T) {
+	now := time.Now()
+
+	type testCase struct {
+		Name        string
+		PromoCode   entities.PromoCode
+		Redemptions int32
+	}
+
+	testCases := []testCase{
+		{"NotStarted", entities.PromoCode{ID: 1, StartsAt: now.Add(time.Hour)}, 0},
+		{"Ended", entities.PromoCode{ID: 1, EndsAt: now.Add(-time.Hour)}, 0},
+		{"Exhausted", entities.PromoCode{ID: 1, MaxRedemptions: 5, Redemptions: 5}, 0},
+		{"ExhaustedByUser", entities.PromoCode{ID: 1, MaxRedemptionsPerUser: 1}, 1},
+	}
+
+	for _, tc := range testCases {
+		mockRepo := new(MockPromoCodesRepo)
+		mockRepo.On("GetPromoCodeByCode", mock.Anything, "SUMMER25").Return(tc.PromoCode, nil)
+		mockRepo.On("GetUserPromoCodeRedemptions", mock.Anything, int32(1), int32(10)).Return(tc.Redemptions, nil)
+
+		service := services.NewPromoCodesService(mockRepo)
+		_, err := service.GetRedeemablePromoCode(context.Background(), "SUMMER25", 10)
+
+		assert.ErrorIs(t, err, services.ErrPromoCodeUnavailable, tc.Name)
+	}
+}
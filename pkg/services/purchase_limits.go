@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+)
+
+// countTicketsByEvent counts how many of the given tickets are for each event.
+func countTicketsByEvent(tickets []entities.Ticket) map[int32]int32 {
+	counts := make(map[int32]int32)
+	for _, ticket := range tickets {
+		counts[ticket.EventID]++
+	}
+	return counts
+}
+
+// countedEventIDs gives the ids of the events counted by `counts`, in order.
+func countedEventIDs(counts map[int32]int32) []int32 {
+	eventIDs := make([]int32, 0, len(counts))
+	for eventID := range counts {
+		eventIDs = append(eventIDs, eventID)
+	}
+	slices.Sort(eventIDs)
+	return eventIDs
+}
+
+// getPurchaseLimits fetches the purchase limits of those of the given events
+// that have one, along with how many of their tickets the holder has
+// purchased. A holder id that isn't numeric can't have purchased any tickets.
+func (svc *TicketsService) getPurchaseLimits(
+	ctx context.Context,
+	eventIDs []int32,
+	holderID string,
+) ([]entities.PurchaseLimit, error) {
+	purchaserID, err := strconv.Atoi(holderID)
+	if err != nil {
+		purchaserID = 0
+	}
+	return svc.repo.GetPurchaseLimits(ctx, eventIDs, int32(purchaserID))
+}
+
+// reservePurchaseLimits reserves the number of tickets held of each event,
+// given by `counts`, against what's left of the event's limit on tickets per
+// user once the holder's purchases are accounted for. The reservations are made
+// for the hold given by `token`, and expire along with it after `expiration`,
+// so that the holder's active holds count against the limit. The ids of the
+// events that have a limit, and so were reserved against, are returned. If any
+// event's limit would be exceeded, no reservations are kept and
+// `ErrPurchaseLimitExceeded` is returned.
+func (svc *TicketsService) reservePurchaseLimits(
+	ctx context.Context,
+	holderID string,
+	token string,
+	counts map[int32]int32,
+	expiration time.Duration,
+) ([]int32, error) {
+	eventIDs := countedEventIDs(counts)
+	limits, err := svc.getPurchaseLimits(ctx, eventIDs, holderID)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := make([]int32, 0, len(limits))
+	for _, limit := range limits {
+		key := svc.ticketHoldClient.MakeLimitKey(limit.EventID, holderID)
+		err := svc.ticketHoldClient.ReserveQuantity(
+			ctx,
+			key,
+			token,
+			int(counts[limit.EventID]),
+			int(limit.Remaining()),
+			expiration,
+		)
+		if err != nil {
+			svc.releasePurchaseLimits(context.WithoutCancel(ctx), holderID, token, reserved)
+			if errors.Is(err, cache.ErrInsufficientQuantity) {
+				err = ErrPurchaseLimitExceeded
+			}
+			return nil, err
+		}
+		reserved = append(reserved, limit.EventID)
+	}
+	return reserved, nil
+}
+
+// releasePurchaseLimits releases the reservations of the hold given by `token`
+// against the given events' limits on tickets per user. Failing to release a
+// reservation isn't an error, as it still expires.
+func (svc *TicketsService) releasePurchaseLimits(
+	ctx context.Context,
+	holderID string,
+	token string,
+	eventIDs []int32,
+) {
+	for _, eventID := range eventIDs {
+		key := svc.ticketHoldClient.MakeLimitKey(eventID, holderID)
+		svc.ticketHoldClient.ReleaseQuantity(ctx, key, token)
+	}
+}
+
+// checkPurchaseLimits checks that purchasing the tickets wouldn't give the
+// purchaser more of an event's tickets than the event's limit on tickets per
+// user. The ids of the events that have a limit are returned.
+func (svc *TicketsService) checkPurchaseLimits(
+	ctx context.Context,
+	tickets []entities.Ticket,
+	purchaserID int32,
+) ([]int32, error) {
+	counts := countTicketsByEvent(tickets)
+	limits, err := svc.repo.GetPurchaseLimits(ctx, countedEventIDs(counts), purchaserID)
+	if err != nil {
+		return nil, err
+	}
+
+	limitedEventIDs := make([]int32, len(limits))
+	for idx, limit := range limits {
+		if counts[limit.EventID] > limit.Remaining() {
+			return nil, ErrPurchaseLimitExceeded
+		}
+		limitedEventIDs[idx] = limit.EventID
+	}
+	return limitedEventIDs, nil
+}
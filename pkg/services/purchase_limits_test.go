@@ -0,0 +1,195 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTicketsServiceSetTicketsHoldReservesPurchaseLimits(t *testing.T) {
+	ticketIDs := []int32{1, 2, 3}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, ticketIDs).Return(
+		[]entities.Ticket{{ID: 1, EventID: 1}, {ID: 2, EventID: 1}, {ID: 3, EventID: 2}},
+		nil,
+	)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, []int32{1, 2}, int32(123)).Return(
+		[]entities.PurchaseLimit{{EventID: 1, MaxTicketsPerUser: 4, Purchased: 1}},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", mock.Anything).Return("hold")
+	mockClient.On("MakeKey", mock.Anything).Return("ticket")
+	mockClient.On("MakeLimitKey", int32(1), "123").Return("limit:1:123")
+	mockClient.On("ReserveQuantity", mock.Anything, "limit:1:123", mock.Anything, 2, 3, time.Minute).Return(nil)
+	mockClient.On("SetMany", mock.Anything, mock.Anything, time.Minute).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	hold, err := service.SetTicketsHold(context.Background(), ticketIDs, "123", entities.SaleAccess{})
+
+	assert.Nil(t, err)
+	mockClient.AssertCalled(t, "ReserveQuantity", mock.Anything, "limit:1:123", hold.Token, 2, 3, time.Minute)
+	mockClient.AssertNotCalled(t, "MakeLimitKey", int32(2), mock.Anything)
+}
+
+func TestTicketsServiceSetTicketsHoldWhenPurchaseLimitExceeded(t *testing.T) {
+	ticketIDs := []int32{1, 2, 3}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, ticketIDs).Return(
+		[]entities.Ticket{{ID: 1, EventID: 1}, {ID: 2, EventID: 1}, {ID: 3, EventID: 2}},
+		nil,
+	)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, []int32{1, 2}, int32(123)).Return(
+		[]entities.PurchaseLimit{
+			{EventID: 1, MaxTicketsPerUser: 4, Purchased: 1},
+			{EventID: 2, MaxTicketsPerUser: 2, Purchased: 2},
+		},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeLimitKey", int32(1), "123").Return("limit:1:123")
+	mockClient.On("MakeLimitKey", int32(2), "123").Return("limit:2:123")
+	mockClient.On("ReserveQuantity", mock.Anything, "limit:1:123", mock.Anything, 2, 3, mock.Anything).Return(nil)
+	mockClient.On("ReserveQuantity", mock.Anything, "limit:2:123", mock.Anything, 1, 0, mock.Anything).Return(
+		cache.ErrInsufficientQuantity,
+	)
+	mockClient.On("ReleaseQuantity", mock.Anything, "limit:1:123", mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.SetTicketsHold(context.Background(), ticketIDs, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrPurchaseLimitExceeded)
+	mockClient.AssertCalled(t, "ReleaseQuantity", mock.Anything, "limit:1:123", mock.Anything)
+	mockClient.AssertNotCalled(t, "SetMany", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceHoldGATierWhenPurchaseLimitExceeded(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetGATier", mock.Anything, int32(2)).Return(
+		entities.GATier{ID: 2, EventID: 1, Name: "Floor", Price: money.New(1000, "USD"), Capacity: 100},
+		nil,
+	)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, []int32{1}, int32(123)).Return(
+		[]entities.PurchaseLimit{{EventID: 1, MaxTicketsPerUser: 4}},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeLimitKey", int32(1), "123").Return("limit:1:123")
+	mockClient.On("ReserveQuantity", mock.Anything, "limit:1:123", mock.Anything, 6, 4, mock.Anything).Return(
+		cache.ErrInsufficientQuantity,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.HoldGATier(context.Background(), 2, 6, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrPurchaseLimitExceeded)
+	mockClient.AssertNotCalled(t, "MakeReservationsKey", mock.Anything)
+	mockClient.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServicePurchaseTicketWhenPurchaseLimitExceeded(t *testing.T) {
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, EventID: 2, Price: money.New(20, "USD")}},
+		nil,
+	)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, []int32{2}, int32(123)).Return(
+		[]entities.PurchaseLimit{{EventID: 2, MaxTicketsPerUser: 2, Purchased: 2}},
+		nil,
+	)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		payment.NewFakeProcessor(nil, 0),
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	_, err := service.PurchaseTicket(context.Background(), ticketID, holdID, int32(123), payment.Card{}, "", "")
+
+	assert.ErrorIs(t, err, services.ErrPurchaseLimitExceeded)
+	mockPaymentsRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "PurchaseTickets", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServicePurchaseTicketWhenPurchaseLimitRaceLost(t *testing.T) {
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, EventID: 2, Price: money.New(20, "USD")}},
+		nil,
+	)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, []int32{2}, int32(123)).Return(
+		[]entities.PurchaseLimit{{EventID: 2, MaxTicketsPerUser: 2, Purchased: 1}},
+		nil,
+	)
+	mockRepo.On("PurchaseTickets", mock.Anything, mock.Anything, mock.Anything).Return(
+		int32(0),
+		repos.ErrPurchaseLimitExceeded,
+	)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		processor,
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	purchase, err := service.PurchaseTicket(context.Background(), ticketID, holdID, int32(123), payment.Card{}, "", "")
+
+	assert.False(t, purchase.Accepted)
+	assert.ErrorIs(t, err, services.ErrPurchaseLimitExceeded)
+
+	paymentRecord := mockPaymentsRepo.Calls[len(mockPaymentsRepo.Calls)-1].Arguments.Get(1).(entities.Payment)
+	assert.Equal(t, entities.PaymentStatusVoided, paymentRecord.Status)
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/payment"
+)
+
+// RefundsRepoer provides necessary methods for database operations against
+// refunds.
+type RefundsRepoer interface {
+	CompleteRefund(context.Context, entities.Refund) error
+	FailRefund(context.Context, entities.Refund) error
+	GetStalePendingRefunds(context.Context, time.Time, int32) ([]entities.Refund, error)
+}
+
+// refundCompleter records the outcome of making a refund with the payment
+// processor.
+type refundCompleter interface {
+	CompleteRefund(context.Context, entities.Refund) error
+	FailRefund(context.Context, entities.Refund) error
+}
+
+// completeRefund makes a pending refund with the payment processor, and
+// records it as completed. The refund is keyed on its id, so that the payment
+// is only refunded once however many times the refund is attempted. A refund
+// that the payment processor won't make is recorded as failed, and is
+// otherwise left pending to be retried. The refund is made even if the context
+// has been cancelled, as its ticket has already been returned.
+func completeRefund(
+	ctx context.Context,
+	repo refundCompleter,
+	processor payment.PaymentProcessor,
+	refund entities.Refund,
+) (entities.Refund, error) {
+	ctx = context.WithoutCancel(ctx)
+	key := fmt.Sprintf("refund-%d", refund.ID)
+	reference, err := processor.Refund(ctx, refund.PaymentReference, refund.Amount, key)
+	if err != nil {
+		if payment.IsPermanent(err) {
+			refund.Status = entities.RefundStatusFailed
+			err = errors.Join(err, repo.FailRefund(ctx, refund))
+		}
+		return refund, err
+	}
+
+	refund.Status = entities.RefundStatusCompleted
+	refund.Reference = reference
+	return refund, repo.CompleteRefund(ctx, refund)
+}
+
+// RefundsService retries refunds that were recorded but not made, e.g. as the
+// payment processor was unavailable, or the process crashed after recording
+// them. Only refunds that haven't been updated within `MaxAge` are retried, so
+// that refunds that are still being made aren't attempted concurrently.
+type RefundsService struct {
+	repo             RefundsRepoer
+	paymentProcessor payment.PaymentProcessor
+	MaxAge           time.Duration
+	BatchSize        int32
+}
+
+func NewRefundsService(
+	repo RefundsRepoer,
+	paymentProcessor payment.PaymentProcessor,
+	maxAge time.Duration,
+	batchSize int32,
+) *RefundsService {
+	return &RefundsService{
+		repo:             repo,
+		paymentProcessor: paymentProcessor,
+		MaxAge:           maxAge,
+		BatchSize:        batchSize,
+	}
+}
+
+// RetryRefunds retries a batch of stale pending refunds, and returns the
+// number of refunds completed. Failing to complete one refund doesn't stop the
+// others from being completed.
+func (svc *RefundsService) RetryRefunds(ctx context.Context) (int, error) {
+	before := time.Now().Add(-svc.MaxAge)
+	refunds, err := svc.repo.GetStalePendingRefunds(ctx, before, svc.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	countCompleted := 0
+	var errs []error
+	for _, refund := range refunds {
+		if _, err := completeRefund(ctx, svc.repo, svc.paymentProcessor, refund); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		countCompleted++
+	}
+
+	return countCompleted, errors.Join(errs...)
+}
+
+// Run retries stale pending refunds every `interval`, until the context is
+// done.
+func (svc *RefundsService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			countCompleted, err := svc.RetryRefunds(ctx)
+			if err != nil {
+				slog.Error("Issue retrying pending refunds", "error", err)
+			}
+			if countCompleted > 0 {
+				slog.Info("Completed pending refunds", "count", countCompleted)
+			}
+		}
+	}
+}
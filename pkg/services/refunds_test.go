@@ -0,0 +1,123 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRefundsRepo struct {
+	mock.Mock
+}
+
+func (mock *MockRefundsRepo) CompleteRefund(ctx context.Context, refund entities.Refund) error {
+	args := mock.Called(ctx, refund)
+	return args.Error(0)
+}
+
+func (mock *MockRefundsRepo) FailRefund(ctx context.Context, refund entities.Refund) error {
+	args := mock.Called(ctx, refund)
+	return args.Error(0)
+}
+
+func (mock *MockRefundsRepo) GetStalePendingRefunds(
+	ctx context.Context,
+	before time.Time,
+	limit int32,
+) ([]entities.Refund, error) {
+	args := mock.Called(ctx, before, limit)
+	return args.Get(0).([]entities.Refund), args.Error(1)
+}
+
+func TestRefundsServiceRetryRefunds(t *testing.T) {
+	ctx := context.Background()
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(30, "USD"), payment.Card{})
+	processor.Capture(ctx, result.Reference)
+
+	completed := entities.Refund{
+		ID:               5,
+		PaymentID:        3,
+		TicketID:         1,
+		Amount:           money.New(20, "USD"),
+		Status:           entities.RefundStatusPending,
+		PaymentReference: result.Reference,
+	}
+	failed := entities.Refund{
+		ID:               6,
+		PaymentID:        4,
+		TicketID:         2,
+		Amount:           money.New(20, "USD"),
+		Status:           entities.RefundStatusPending,
+		PaymentReference: "unknown",
+	}
+
+	mockRepo := new(MockRefundsRepo)
+	mockRepo.On("GetStalePendingRefunds", ctx, mock.Anything, int32(10)).Return(
+		[]entities.Refund{completed, failed},
+		nil,
+	)
+	mockRepo.On("CompleteRefund", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("FailRefund", mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewRefundsService(mockRepo, processor, time.Minute, 10)
+	countCompleted, err := service.RetryRefunds(ctx)
+
+	assert.ErrorIs(t, err, payment.ErrUnknownPayment)
+	assert.Equal(t, 1, countCompleted)
+
+	completed.Status = entities.RefundStatusCompleted
+	mockRepo.AssertCalled(t, "CompleteRefund", mock.Anything, mock.MatchedBy(func(refund entities.Refund) bool {
+		return refund.ID == completed.ID && refund.Status == completed.Status && refund.Reference != ""
+	}))
+	failed.Status = entities.RefundStatusFailed
+	mockRepo.AssertCalled(t, "FailRefund", mock.Anything, failed)
+}
+
+func TestRefundsServiceRetryRefundsWhenAlreadyMade(t *testing.T) {
+	ctx := context.Background()
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(20, "USD"), payment.Card{})
+	processor.Capture(ctx, result.Reference)
+
+	refund := entities.Refund{
+		ID:               5,
+		PaymentID:        3,
+		TicketID:         1,
+		Amount:           money.New(20, "USD"),
+		Status:           entities.RefundStatusPending,
+		PaymentReference: result.Reference,
+	}
+
+	// The refund was made with the payment processor, but recording it as
+	// completed failed.
+	reference, err := processor.Refund(ctx, result.Reference, refund.Amount, "refund-5")
+	assert.Nil(t, err)
+
+	mockRepo := new(MockRefundsRepo)
+	mockRepo.On("GetStalePendingRefunds", ctx, mock.Anything, int32(10)).Return([]entities.Refund{refund}, nil)
+	mockRepo.On("CompleteRefund", mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewRefundsService(mockRepo, processor, time.Minute, 10)
+	countCompleted, err := service.RetryRefunds(ctx)
+
+	// Retrying the refund gives the original refund, rather than refunding the
+	// payment again.
+	assert.Nil(t, err)
+	assert.Equal(t, 1, countCompleted)
+
+	refund.Status = entities.RefundStatusCompleted
+	refund.Reference = reference
+	mockRepo.AssertCalled(t, "CompleteRefund", mock.Anything, refund)
+	status, _ := processor.Status(result.Reference)
+	assert.Equal(t, entities.PaymentStatusRefunded, status)
+}
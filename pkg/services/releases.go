@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+)
+
+// ReleaseTickets creates new tickets for the given event as part of the
+// release, which schedules when the tickets go on and off sale, and gives
+// early access to them through its presales. Tickets released without a
+// schedule are on sale as soon as they're released, as with AddTickets.
+func (svc *TicketsService) ReleaseTickets(
+	ctx context.Context,
+	release entities.TicketRelease,
+	tickets []entities.Ticket,
+) error {
+	if !release.IsScheduled() || len(tickets) == 0 {
+		return svc.AddTickets(ctx, release.EventID, tickets)
+	}
+
+	tickets, err := svc.prepareTickets(ctx, release.EventID, tickets)
+	if err != nil {
+		return err
+	}
+	_, err = svc.repo.WriteTicketRelease(ctx, release, tickets)
+	return err
+}
+
+// getReleasesByID fetches the releases given by id, skipping the ids of
+// tickets that aren't part of a release.
+func (svc *TicketsService) getReleasesByID(
+	ctx context.Context,
+	releaseIDs []int32,
+) ([]entities.TicketRelease, error) {
+	releaseIDs = slices.DeleteFunc(slices.Clone(releaseIDs), func(id int32) bool {
+		return id == 0
+	})
+	slices.Sort(releaseIDs)
+	releaseIDs = slices.Compact(releaseIDs)
+	if len(releaseIDs) == 0 {
+		return []entities.TicketRelease{}, nil
+	}
+	return svc.repo.GetTicketReleases(ctx, releaseIDs)
+}
+
+// hasPresaleAccess checks whether `access` gives access to the presale, either
+// by its access code, or by the user being on its allowlist.
+func (svc *TicketsService) hasPresaleAccess(
+	ctx context.Context,
+	presale entities.Presale,
+	access entities.SaleAccess,
+) (bool, error) {
+	if presale.AccessCode != "" && access.AccessCode == presale.AccessCode {
+		return true, nil
+	}
+	if !presale.HasAllowlist || access.UserID == 0 {
+		return false, nil
+	}
+	return svc.repo.IsPresaleUser(ctx, presale.ID, access.UserID)
+}
+
+// checkReleasesOnSale checks that each of the tickets that is part of a
+// release is on general sale at the given time, or that `access` gives access
+// to one of the release's active presales.
+func (svc *TicketsService) checkReleasesOnSale(
+	ctx context.Context,
+	tickets []entities.Ticket,
+	access entities.SaleAccess,
+	at time.Time,
+) error {
+	releaseIDs := make([]int32, len(tickets))
+	for idx, ticket := range tickets {
+		releaseIDs[idx] = ticket.ReleaseID
+	}
+
+	releases, err := svc.getReleasesByID(ctx, releaseIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, release := range releases {
+		if release.IsOnSale(at) {
+			continue
+		}
+
+		inPresale := false
+		hasAccess := false
+		for _, presale := range release.Presales {
+			if !presale.IsActive(at) {
+				continue
+			}
+			inPresale = true
+
+			hasAccess, err = svc.hasPresaleAccess(ctx, presale, access)
+			if err != nil {
+				return err
+			}
+			if hasAccess {
+				break
+			}
+		}
+
+		if !inPresale {
+			return ErrNotOnSale
+		}
+		if !hasAccess {
+			return ErrPresaleAccessDenied
+		}
+	}
+	return nil
+}
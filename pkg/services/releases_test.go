@@ -0,0 +1,159 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTicketsServiceReleaseTickets(t *testing.T) {
+	ctx := context.Background()
+	release := entities.TicketRelease{
+		EventID:  1,
+		OnSaleAt: time.Date(2026, 11, 1, 10, 0, 0, 0, time.UTC),
+		Presales: []entities.Presale{
+			{
+				Name:       "Fan Club",
+				StartsAt:   time.Date(2026, 10, 30, 10, 0, 0, 0, time.UTC),
+				EndsAt:     time.Date(2026, 11, 1, 10, 0, 0, 0, time.UTC),
+				AccessCode: "FANS",
+			},
+		},
+	}
+	tickets := []entities.Ticket{{EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra"}}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventCurrencies", ctx, int32(1)).Return([]string{"USD"}, nil)
+	mockRepo.On("WriteTicketRelease", ctx, release, tickets).Return(int32(2), nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.ReleaseTickets(ctx, release, tickets)
+
+	assert.Nil(t, err)
+	mockRepo.AssertCalled(t, "WriteTicketRelease", ctx, release, tickets)
+	mockRepo.AssertNotCalled(t, "WriteTickets", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceReleaseTicketsWhenNotScheduled(t *testing.T) {
+	ctx := context.Background()
+	release := entities.TicketRelease{EventID: 1}
+	tickets := []entities.Ticket{{EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra"}}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventCurrencies", ctx, int32(1)).Return([]string{"USD"}, nil)
+	mockRepo.On("WriteTickets", ctx, tickets).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.ReleaseTickets(ctx, release, tickets)
+
+	assert.Nil(t, err)
+	mockRepo.AssertCalled(t, "WriteTickets", ctx, tickets)
+	mockRepo.AssertNotCalled(t, "WriteTicketRelease", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceSetTicketHoldInPresale(t *testing.T) {
+	now := time.Now()
+	presale := entities.Presale{
+		ID:           3,
+		Name:         "Fan Club",
+		StartsAt:     now.Add(-time.Hour),
+		EndsAt:       now.Add(time.Hour),
+		AccessCode:   "FANS",
+		HasAllowlist: true,
+	}
+	release := entities.TicketRelease{ID: 2, EventID: 1, OnSaleAt: now.Add(time.Hour), Presales: []entities.Presale{presale}}
+
+	type testCase struct {
+		Name        string
+		Access      entities.SaleAccess
+		IsUser      bool
+		ExpectedErr error
+	}
+
+	testCases := []testCase{
+		{Name: "AccessCode", Access: entities.SaleAccess{UserID: 123, AccessCode: "FANS"}},
+		{Name: "Allowlist", Access: entities.SaleAccess{UserID: 123}, IsUser: true},
+		{Name: "Denied", Access: entities.SaleAccess{UserID: 123, AccessCode: "WRONG"}, ExpectedErr: services.ErrPresaleAccessDenied},
+		{Name: "NoAccess", Access: entities.SaleAccess{}, ExpectedErr: services.ErrPresaleAccessDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ticketID := int32(1)
+
+			mockRepo := new(MockTicketsRepo)
+			mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+			mockRepo.On("GetTicket", mock.Anything, ticketID).Return(entities.Ticket{ID: ticketID, ReleaseID: 2}, nil)
+			mockRepo.On("GetTicketReleases", mock.Anything, []int32{2}).Return([]entities.TicketRelease{release}, nil)
+			mockRepo.On("IsPresaleUser", mock.Anything, int32(3), int32(123)).Return(tc.IsUser, nil)
+
+			mockClient := new(MockCacheClient)
+			mockClient.On("MakeKey", ticketID).Return("1")
+			mockClient.On("Set", mock.Anything, "1", "123", time.Minute).Return(nil)
+
+			service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+			err := service.SetTicketHold(context.Background(), ticketID, "123", tc.Access)
+
+			if tc.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tc.ExpectedErr)
+				mockClient.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.Nil(t, err)
+			mockClient.AssertCalled(t, "Set", mock.Anything, "1", "123", time.Minute)
+		})
+	}
+}
+
+func TestTicketsServiceSetTicketHoldWhenReleaseNotOnSale(t *testing.T) {
+	now := time.Now()
+	ticketID := int32(1)
+	release := entities.TicketRelease{
+		ID:       2,
+		EventID:  1,
+		OnSaleAt: now.Add(2 * time.Hour),
+		Presales: []entities.Presale{
+			{ID: 3, Name: "Fan Club", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour), AccessCode: "FANS"},
+		},
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicket", mock.Anything, ticketID).Return(entities.Ticket{ID: ticketID, ReleaseID: 2}, nil)
+	mockRepo.On("GetTicketReleases", mock.Anything, []int32{2}).Return([]entities.TicketRelease{release}, nil)
+
+	mockClient := new(MockCacheClient)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	err := service.SetTicketHold(context.Background(), ticketID, "123", entities.SaleAccess{AccessCode: "FANS"})
+
+	assert.ErrorIs(t, err, services.ErrNotOnSale)
+	mockClient.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceSetTicketsHoldWhenReleaseOffSale(t *testing.T) {
+	now := time.Now()
+	ticketIDs := []int32{1, 2}
+	release := entities.TicketRelease{ID: 2, EventID: 1, OffSaleAt: now.Add(-time.Hour)}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, ticketIDs).Return(
+		[]entities.Ticket{{ID: 1}, {ID: 2, ReleaseID: 2}},
+		nil,
+	)
+	mockRepo.On("GetTicketReleases", mock.Anything, []int32{2}).Return([]entities.TicketRelease{release}, nil)
+
+	mockClient := new(MockCacheClient)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.SetTicketsHold(context.Background(), ticketIDs, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrNotOnSale)
+	mockClient.AssertNotCalled(t, "SetMany", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/repos"
+)
+
+// ListTicketForResale lists the listing's ticket for resale by the listing's
+// seller, who must own the ticket, at the listing's price. The price must be in
+// the currency of the ticket's face value, and within its event's resale
+// rates. A ticket can only have one active listing at a time, and can only be
+// listed until its event starts.
+func (svc *TicketsService) ListTicketForResale(
+	ctx context.Context,
+	listing entities.ResaleListing,
+) (entities.ResaleListing, error) {
+	terms, err := svc.repo.GetTicketResaleTerms(ctx, listing.TicketID)
+	if err != nil {
+		return entities.ResaleListing{}, err
+	}
+	if !terms.Ticket.IsPurchased || terms.Ticket.PurchaserID != listing.SellerID {
+		return entities.ResaleListing{}, ErrNotTicketOwner
+	}
+	if terms.HasEventStarted(time.Now()) {
+		return entities.ResaleListing{}, ErrEventStarted
+	}
+	if !terms.IsPriceAllowed(listing.Price) {
+		return entities.ResaleListing{}, ErrResalePriceNotAllowed
+	}
+
+	_, err = svc.repo.GetActiveResaleListing(ctx, listing.TicketID)
+	if err == nil {
+		return entities.ResaleListing{}, ErrTicketListed
+	}
+	if !errors.Is(err, repos.ErrNoSuchEntity) {
+		return entities.ResaleListing{}, err
+	}
+
+	return svc.repo.CreateResaleListing(ctx, listing)
+}
+
+// GetResaleListing fetches the listing given by id.
+func (svc *TicketsService) GetResaleListing(ctx context.Context, id int32) (entities.ResaleListing, error) {
+	return svc.repo.GetResaleListing(ctx, id)
+}
+
+// GetSellerResaleListings fetches the listings of the seller given by id, most
+// recent first.
+func (svc *TicketsService) GetSellerResaleListings(
+	ctx context.Context,
+	sellerID int32,
+) ([]entities.ResaleListing, error) {
+	return svc.repo.GetSellerResaleListings(ctx, sellerID)
+}
+
+// getActiveResaleListing fetches the listing given by id, checking that it's
+// still active.
+func (svc *TicketsService) getActiveResaleListing(ctx context.Context, id int32) (entities.ResaleListing, error) {
+	listing, err := svc.repo.GetResaleListing(ctx, id)
+	if err != nil {
+		return entities.ResaleListing{}, err
+	}
+	if listing.Status != entities.ResaleListingStatusActive {
+		return entities.ResaleListing{}, ErrListingNotActive
+	}
+	return listing, nil
+}
+
+// CancelResaleListing cancels the active listing given by id, which can only
+// be done by its seller, given by `userID`. A hold placed on the listing is
+// left to expire, as it can no longer be purchased.
+func (svc *TicketsService) CancelResaleListing(
+	ctx context.Context,
+	id int32,
+	userID int32,
+) (entities.ResaleListing, error) {
+	listing, err := svc.getActiveResaleListing(ctx, id)
+	if err != nil {
+		return entities.ResaleListing{}, err
+	}
+	if listing.SellerID != userID {
+		return entities.ResaleListing{}, ErrNotListingSeller
+	}
+
+	cancelled, err := svc.repo.CancelResaleListing(ctx, id)
+	if errors.Is(err, repos.ErrNoSuchEntity) {
+		// The listing was sold or cancelled in the meantime.
+		return entities.ResaleListing{}, ErrListingNotActive
+	}
+	return cancelled, err
+}
+
+// getAvailableResaleListings fetches the event's active resale listings that
+// aren't held, cheapest first.
+func (svc *TicketsService) getAvailableResaleListings(
+	ctx context.Context,
+	eventID int32,
+) ([]entities.AvailableResaleListing, error) {
+	listings, err := svc.repo.GetEventResaleListings(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if len(listings) == 0 {
+		return []entities.AvailableResaleListing{}, nil
+	}
+
+	// A listing is held by a hold on its ticket.
+	cacheKeys := make([]string, len(listings))
+	for idx, listing := range listings {
+		cacheKeys[idx] = svc.ticketHoldClient.MakeKey(listing.TicketID)
+	}
+
+	ticketHolds, err := svc.ticketHoldClient.GetMany(ctx, cacheKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(listings, func(listing entities.AvailableResaleListing) bool {
+		_, hasHold := ticketHolds[svc.ticketHoldClient.MakeKey(listing.TicketID)]
+		return hasHold
+	}), nil
+}
+
+// HoldResaleListing places a time-bounded purchase hold on the ticket of the
+// active listing given by `listingID`. The returned hold's token identifies the
+// hold for purchase, along with holds on the event's own tickets. A seller
+// can't hold their own listing, and a listing can't be held once its event
+// has started. As with the event's own tickets, the holder must be admitted if
+// the event has a waiting room, and the hold counts against the event's limit
+// on tickets per user.
+func (svc *TicketsService) HoldResaleListing(
+	ctx context.Context,
+	listingID int32,
+	holderID string,
+	access entities.SaleAccess,
+) (hold entities.TicketHold, err error) {
+	if holderID == "" {
+		err = ErrInvalidHoldID
+		return
+	}
+
+	listing, err := svc.getActiveResaleListing(ctx, listingID)
+	if err != nil {
+		return
+	}
+	if holderID == strconv.Itoa(int(listing.SellerID)) {
+		err = ErrOwnListing
+		return
+	}
+
+	terms, err := svc.repo.GetTicketResaleTerms(ctx, listing.TicketID)
+	if err != nil {
+		return
+	}
+	if terms.HasEventStarted(time.Now()) {
+		err = ErrEventStarted
+		return
+	}
+	ticket := terms.Ticket
+	if err = svc.checkAdmission(ctx, []int32{ticket.EventID}, holderID, access); err != nil {
+		return
+	}
+
+	record := ticketHoldRecord{
+		HolderID:  holderID,
+		TicketIDs: []int32{listing.TicketID},
+		ListingID: listing.ID,
+	}
+	counts := map[int32]int32{ticket.EventID: 1}
+	return svc.placeTicketsHold(ctx, record, counts, svc.TicketHoldDuration)
+}
+
+// purchaseResaleHold purchases the ticket of the resale listing held by
+// `holds`, under the purchase lock on the ticket. The ticket is priced at the
+// listing's price, on which the event's fees and tax are charged, and promo
+// codes don't apply. A listing can't be purchased once its event has started,
+// even if it was held before then. As with the event's own tickets, the
+// payment is only captured once the ticket has been moved from the seller to
+// the purchaser, along with creating the purchaser's order and recording the
+// payout owed to the seller - otherwise the authorization is voided.
+func (svc *TicketsService) purchaseResaleHold(
+	ctx context.Context,
+	token string,
+	record ticketHoldRecord,
+	holds map[string]string,
+	purchaserID int32,
+	card payment.Card,
+	promoCode string,
+) (purchase entities.PurchaseResult, err error) {
+	if promoCode != "" {
+		err = ErrPromoCodeNotApplicable
+		return
+	}
+
+	unlock, err := svc.lockTickets(ctx, record.TicketIDs)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	if err = svc.checkHolds(ctx, holds); err != nil {
+		return
+	}
+
+	listing, err := svc.getActiveResaleListing(ctx, record.ListingID)
+	if err != nil {
+		return
+	}
+	if listing.SellerID == purchaserID {
+		err = ErrOwnListing
+		return
+	}
+
+	terms, err := svc.repo.GetTicketResaleTerms(ctx, listing.TicketID)
+	if err != nil {
+		return
+	}
+	if terms.HasEventStarted(time.Now()) {
+		err = ErrEventStarted
+		return
+	}
+	ticket := terms.Ticket
+	if ticket.PurchaserID != listing.SellerID {
+		// The seller has since had the ticket refunded or transferred.
+		err = ErrListingNotActive
+		return
+	}
+
+	tickets := []entities.Ticket{ticket}
+	limitedEventIDs, err := svc.checkPurchaseLimits(ctx, tickets, purchaserID)
+	if err != nil {
+		return
+	}
+
+	tickets[0].Price = listing.Price
+	quote, err := svc.pricingService.Quote(ctx, tickets, nil)
+	if err != nil {
+		return
+	}
+
+	paymentRecord, err := svc.authorizePayment(ctx, purchaserID, quote.Total, card)
+	if err != nil {
+		return
+	}
+	if paymentRecord.Status != entities.PaymentStatusAuthorized {
+		purchase = entities.PurchaseResult{Accepted: false, DeclineReason: paymentRecord.DeclineReason}
+		return
+	}
+
+	var captured bool
+	capture := svc.newCapture(paymentRecord, &captured)
+	orderID, err := svc.repo.PurchaseResaleListing(ctx, listing, quote, paymentRecord, capture)
+	if err != nil {
+		// As with the event's own tickets, the authorization is voided now, or
+		// later by the sweeper if voiding fails, or the payment is refunded if
+		// it was captured.
+		if settleErr := svc.settleFailedPurchase(ctx, &paymentRecord, captured); settleErr != nil {
+			err = errors.Join(err, settleErr)
+			return
+		}
+
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			err = ErrListingNotActive
+		} else if errors.Is(err, repos.ErrPurchaseLimitExceeded) {
+			err = ErrPurchaseLimitExceeded
+		}
+		return
+	}
+
+	// The purchase has been recorded, so failing to remove the hold isn't an
+	// error - it still expires, and the listing is sold regardless.
+	for key, value := range holds {
+		svc.ticketHoldClient.CompareAndDelete(ctx, key, value)
+	}
+	svc.releasePurchaseLimits(ctx, record.HolderID, token, limitedEventIDs)
+
+	purchase = entities.PurchaseResult{Accepted: true, OrderID: orderID}
+	return
+}
@@ -0,0 +1,349 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newResaleTerms(minRate, maxRate int32) entities.TicketResaleTerms {
+	return entities.TicketResaleTerms{
+		Ticket: entities.Ticket{
+			ID:          1,
+			EventID:     2,
+			Price:       money.New(1000, "USD"),
+			IsPurchased: true,
+			PurchaserID: 123,
+		},
+		EventStartsAt: time.Now().Add(24 * time.Hour),
+		MinRate:       minRate,
+		MaxRate:       maxRate,
+	}
+}
+
+func TestTicketsServiceListTicketForResale(t *testing.T) {
+	listing := entities.ResaleListing{TicketID: 1, SellerID: 123, Price: money.New(1500, "USD")}
+	created := entities.ResaleListing{
+		ID:       4,
+		TicketID: 1,
+		SellerID: 123,
+		Price:    money.New(1500, "USD"),
+		Status:   entities.ResaleListingStatusActive,
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(newResaleTerms(0, 15000), nil)
+	mockRepo.On("GetActiveResaleListing", mock.Anything, int32(1)).Return(entities.ResaleListing{}, repos.ErrNoSuchEntity)
+	mockRepo.On("CreateResaleListing", mock.Anything, listing).Return(created, nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	actual, err := service.ListTicketForResale(context.Background(), listing)
+
+	assert.Nil(t, err)
+	assert.Equal(t, created, actual)
+}
+
+func TestTicketsServiceListTicketForResaleWhenNotOwner(t *testing.T) {
+	listing := entities.ResaleListing{TicketID: 1, SellerID: 456, Price: money.New(1500, "USD")}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(newResaleTerms(0, 0), nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.ListTicketForResale(context.Background(), listing)
+
+	assert.ErrorIs(t, err, services.ErrNotTicketOwner)
+	mockRepo.AssertNotCalled(t, "CreateResaleListing", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceListTicketForResaleWhenEventStarted(t *testing.T) {
+	listing := entities.ResaleListing{TicketID: 1, SellerID: 123, Price: money.New(1500, "USD")}
+	terms := newResaleTerms(0, 0)
+	terms.EventStartsAt = time.Now().Add(-time.Hour)
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(terms, nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.ListTicketForResale(context.Background(), listing)
+
+	assert.ErrorIs(t, err, services.ErrEventStarted)
+	mockRepo.AssertNotCalled(t, "CreateResaleListing", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceListTicketForResaleWhenPriceNotAllowed(t *testing.T) {
+	type testCase struct {
+		Name  string
+		Price money.Money
+	}
+
+	// Listings are bounded to 80%-120% of the face value.
+	testCases := []testCase{
+		{Name: "BelowMinimum", Price: money.New(799, "USD")},
+		{Name: "AboveMaximum", Price: money.New(1201, "USD")},
+		{Name: "OtherCurrency", Price: money.New(1000, "EUR")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			listing := entities.ResaleListing{TicketID: 1, SellerID: 123, Price: testCase.Price}
+
+			mockRepo := new(MockTicketsRepo)
+			mockRepo.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(newResaleTerms(8000, 12000), nil)
+
+			service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+			_, err := service.ListTicketForResale(context.Background(), listing)
+
+			assert.ErrorIs(t, err, services.ErrResalePriceNotAllowed)
+			mockRepo.AssertNotCalled(t, "CreateResaleListing", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTicketsServiceListTicketForResaleWhenListed(t *testing.T) {
+	listing := entities.ResaleListing{TicketID: 1, SellerID: 123, Price: money.New(1500, "USD")}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(newResaleTerms(0, 0), nil)
+	mockRepo.On("GetActiveResaleListing", mock.Anything, int32(1)).Return(
+		entities.ResaleListing{ID: 4, TicketID: 1, SellerID: 123, Status: entities.ResaleListingStatusActive},
+		nil,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.ListTicketForResale(context.Background(), listing)
+
+	assert.ErrorIs(t, err, services.ErrTicketListed)
+	mockRepo.AssertNotCalled(t, "CreateResaleListing", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceCancelResaleListingWhenNotSeller(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetResaleListing", mock.Anything, int32(4)).Return(
+		entities.ResaleListing{ID: 4, TicketID: 1, SellerID: 123, Status: entities.ResaleListingStatusActive},
+		nil,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.CancelResaleListing(context.Background(), 4, 456)
+
+	assert.ErrorIs(t, err, services.ErrNotListingSeller)
+	mockRepo.AssertNotCalled(t, "CancelResaleListing", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceCancelResaleListingWhenSold(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetResaleListing", mock.Anything, int32(4)).Return(
+		entities.ResaleListing{ID: 4, TicketID: 1, SellerID: 123, Status: entities.ResaleListingStatusSold},
+		nil,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.CancelResaleListing(context.Background(), 4, 123)
+
+	assert.ErrorIs(t, err, services.ErrListingNotActive)
+	mockRepo.AssertNotCalled(t, "CancelResaleListing", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceHoldResaleListing(t *testing.T) {
+	ticketHoldDuration := time.Minute
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetResaleListing", mock.Anything, int32(4)).Return(
+		entities.ResaleListing{ID: 4, TicketID: 1, SellerID: 123, Status: entities.ResaleListingStatusActive},
+		nil,
+	)
+	mockRepo.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(newResaleTerms(0, 0), nil)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, []int32{2}, int32(456)).Return([]entities.PurchaseLimit{}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", mock.Anything).Return("hold")
+	mockClient.On("MakeKey", int32(1)).Return("ticket:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, ticketHoldDuration).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	hold, err := service.HoldResaleListing(context.Background(), 4, "456", entities.SaleAccess{})
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, hold.Token)
+	assert.Equal(t, []int32{1}, hold.TicketIDs)
+
+	// The listing's ticket is held by the hold's token, and the hold's record
+	// refers to the listing.
+	values := mockClient.Calls[len(mockClient.Calls)-1].Arguments.Get(1).(map[string]string)
+	assert.Equal(t, hold.Token, values["ticket:1"])
+	assert.JSONEq(t, `{"holder_id": "456", "ticket_ids": [1], "listing_id": 4}`, values["hold"])
+}
+
+func TestTicketsServiceHoldResaleListingWhenOwnListing(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetResaleListing", mock.Anything, int32(4)).Return(
+		entities.ResaleListing{ID: 4, TicketID: 1, SellerID: 123, Status: entities.ResaleListingStatusActive},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.HoldResaleListing(context.Background(), 4, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrOwnListing)
+	mockClient.AssertNotCalled(t, "SetMany", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceHoldResaleListingWhenEventStarted(t *testing.T) {
+	terms := newResaleTerms(0, 0)
+	terms.EventStartsAt = time.Now().Add(-time.Hour)
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetResaleListing", mock.Anything, int32(4)).Return(
+		entities.ResaleListing{ID: 4, TicketID: 1, SellerID: 123, Status: entities.ResaleListingStatusActive},
+		nil,
+	)
+	mockRepo.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(terms, nil)
+
+	mockClient := new(MockCacheClient)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.HoldResaleListing(context.Background(), 4, "456", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrEventStarted)
+	mockClient.AssertNotCalled(t, "SetMany", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// setupResaleHoldPurchase sets up the purchase of the ticket of listing 4 by
+// user 456, held by hold "abc", under the given resale terms.
+func setupResaleHoldPurchase(terms entities.TicketResaleTerms) (*MockTicketsRepo, *MockPaymentsRepo, *MockCacheClient) {
+	record := `{"holder_id": "456", "ticket_ids": [1], "listing_id": 4}`
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetResaleListing", mock.Anything, int32(4)).Return(
+		entities.ResaleListing{
+			ID:       4,
+			TicketID: 1,
+			SellerID: 123,
+			Price:    money.New(1500, "USD"),
+			Status:   entities.ResaleListingStatusActive,
+		},
+		nil,
+	)
+	mockRepo.On("GetTicketResaleTerms", mock.Anything, int32(1)).Return(terms, nil)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, []int32{2}, int32(456)).Return([]entities.PurchaseLimit{}, nil)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", "abc").Return("hold:abc")
+	mockClient.On("MakeKey", int32(1)).Return("ticket:1")
+	mockClient.On("MakeLockKey", int32(1)).Return("lock:1")
+	mockClient.On("Get", mock.Anything, "hold:abc").Return(record, nil)
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{"hold:abc", "ticket:1"}).Return(
+		map[string]string{"hold:abc": record, "ticket:1": "abc"},
+		nil,
+	)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return mockRepo, mockPaymentsRepo, mockClient
+}
+
+func TestTicketsServicePurchaseHeldTicketsForResaleListing(t *testing.T) {
+	mockRepo, mockPaymentsRepo, mockClient := setupResaleHoldPurchase(newResaleTerms(0, 0))
+	mockRepo.On("PurchaseResaleListing", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(int32(5), nil)
+
+	// A 10% service fee per ticket.
+	processor := payment.NewFakeProcessor(nil, 0)
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		processor,
+		newPricingService(entities.FeeRule{ServiceFeeRate: 1000}, 0),
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	purchase, err := service.PurchaseHeldTickets(context.Background(), "abc", "456", 456, payment.Card{}, "", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.PurchaseResult{Accepted: true, OrderID: 5}, purchase)
+
+	// The ticket is priced at the listing's price, on which fees are charged.
+	call := mockRepo.Calls[len(mockRepo.Calls)-1]
+	listing := call.Arguments.Get(1).(entities.ResaleListing)
+	quote := call.Arguments.Get(2).(entities.Quote)
+	paymentRecord := call.Arguments.Get(3).(entities.Payment)
+	assert.Equal(t, int32(4), listing.ID)
+	assert.Equal(t, money.New(1500, "USD"), quote.FaceValue)
+	assert.Equal(t, money.New(1650, "USD"), quote.Total)
+	assert.Equal(t, money.New(1650, "USD"), paymentRecord.Amount)
+
+	// The payment is captured as part of the purchase, and the hold removed.
+	status, _ := processor.Status(paymentRecord.Reference)
+	assert.Equal(t, entities.PaymentStatusCaptured, status)
+	mockClient.AssertCalled(t, "CompareAndDelete", mock.Anything, "ticket:1", "abc")
+}
+
+func TestTicketsServicePurchaseHeldTicketsForResaleListingWhenSold(t *testing.T) {
+	mockRepo, mockPaymentsRepo, mockClient := setupResaleHoldPurchase(newResaleTerms(0, 0))
+	mockRepo.On("PurchaseResaleListing", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		int32(0),
+		repos.ErrNoSuchEntity,
+	)
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		processor,
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	_, err := service.PurchaseHeldTickets(context.Background(), "abc", "456", 456, payment.Card{}, "", "")
+
+	assert.ErrorIs(t, err, services.ErrListingNotActive)
+
+	// The authorization is voided, and the hold is kept.
+	paymentRecord := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(3).(entities.Payment)
+	status, _ := processor.Status(paymentRecord.Reference)
+	assert.Equal(t, entities.PaymentStatusVoided, status)
+	mockClient.AssertNotCalled(t, "CompareAndDelete", mock.Anything, "ticket:1", "abc")
+}
+
+func TestTicketsServicePurchaseHeldTicketsForResaleListingWithPromoCode(t *testing.T) {
+	mockRepo, mockPaymentsRepo, mockClient := setupResaleHoldPurchase(newResaleTerms(0, 0))
+
+	service := services.NewTicketsService(mockRepo, mockPaymentsRepo, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.PurchaseHeldTickets(context.Background(), "abc", "456", 456, payment.Card{}, "SAVE10", "")
+
+	assert.ErrorIs(t, err, services.ErrPromoCodeNotApplicable)
+	mockPaymentsRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServicePurchaseHeldTicketsForResaleListingWhenEventStarted(t *testing.T) {
+	// The listing was held before its event started.
+	terms := newResaleTerms(0, 0)
+	terms.EventStartsAt = time.Now().Add(-time.Minute)
+	mockRepo, mockPaymentsRepo, mockClient := setupResaleHoldPurchase(terms)
+
+	service := services.NewTicketsService(mockRepo, mockPaymentsRepo, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.PurchaseHeldTickets(context.Background(), "abc", "456", 456, payment.Card{}, "", "")
+
+	assert.ErrorIs(t, err, services.ErrEventStarted)
+	mockPaymentsRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "PurchaseResaleListing", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,12 +2,19 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"slices"
 	"time"
 
 	"github.com/dslaw/book-tickets/pkg/cache"
 	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
 	"github.com/dslaw/book-tickets/pkg/repos"
 	"github.com/dslaw/book-tickets/pkg/search"
 )
@@ -73,51 +80,250 @@ func (svc *EventsService) DeleteEvent(ctx context.Context, id int32) error {
 // tickets.
 type TicketsRepoer interface {
 	GetAvailableTickets(context.Context, int32) ([]entities.Ticket, error)
+	GetAvailableSeatedTickets(context.Context, int32) ([]entities.SeatedTicket, error)
+	GetEventCurrencies(context.Context, int32) ([]string, error)
+	GetEventVenueSeats(context.Context, int32, []int32) ([]entities.EventVenueSeat, error)
 	GetTicket(context.Context, int32) (entities.Ticket, error)
-	SetTicketPurchaser(context.Context, int32, int32) error
+	GetTickets(context.Context, []int32) ([]entities.Ticket, error)
+	GetTicketPurchase(context.Context, int32) (entities.TicketPurchase, error)
+	PurchaseTickets(context.Context, entities.Quote, entities.Payment, func(context.Context) error) (int32, error)
+	RefundTicket(context.Context, entities.TicketPurchase, bool) (entities.Refund, error)
+	CompleteRefund(context.Context, entities.Refund) error
+	FailRefund(context.Context, entities.Refund) error
 	WriteTickets(context.Context, []entities.Ticket) error
+	CreateGATier(context.Context, entities.GATier) (int32, error)
+	GetGATier(context.Context, int32) (entities.GATier, error)
+	GetEventGATiers(context.Context, int32) ([]entities.GATier, error)
+	IssueGATickets(context.Context, int32, int32) ([]int32, error)
+	ReturnGATickets(context.Context, []int32) error
+	CreateTicketType(context.Context, entities.TicketType) (int32, error)
+	GetEventTicketTypes(context.Context, int32) ([]entities.TicketType, error)
+	GetTicketTypes(context.Context, []int32) ([]entities.TicketType, error)
+	WriteTicketRelease(context.Context, entities.TicketRelease, []entities.Ticket) (int32, error)
+	GetTicketReleases(context.Context, []int32) ([]entities.TicketRelease, error)
+	IsPresaleUser(context.Context, int32, int32) (bool, error)
+	GetPurchaseLimits(context.Context, []int32, int32) ([]entities.PurchaseLimit, error)
+	GetTicketResaleTerms(context.Context, int32) (entities.TicketResaleTerms, error)
+	CreateResaleListing(context.Context, entities.ResaleListing) (entities.ResaleListing, error)
+	GetResaleListing(context.Context, int32) (entities.ResaleListing, error)
+	GetActiveResaleListing(context.Context, int32) (entities.ResaleListing, error)
+	GetEventResaleListings(context.Context, int32) ([]entities.AvailableResaleListing, error)
+	GetSellerResaleListings(context.Context, int32) ([]entities.ResaleListing, error)
+	CancelResaleListing(context.Context, int32) (entities.ResaleListing, error)
+	PurchaseResaleListing(
+		context.Context,
+		entities.ResaleListing,
+		entities.Quote,
+		entities.Payment,
+		func(context.Context) error,
+	) (int32, error)
+}
+
+// PaymentsRepoer provides necessary methods for database operations against
+// payments.
+type PaymentsRepoer interface {
+	payment.PaymentStore
+	CreatePayment(context.Context, entities.Payment) (int32, error)
+}
+
+// purchaseLockDuration bounds how long a purchase holds the lock on its
+// tickets, in case the lock is never released.
+const purchaseLockDuration = 30 * time.Second
+
+// ticketHoldRecord is the value stored in the cache for a hold placed on a set
+// of tickets, on a quantity of a general admission tier, or on a resale
+// listing's ticket, keyed by the hold's token.
+type ticketHoldRecord struct {
+	HolderID  string  `json:"holder_id"`
+	TicketIDs []int32 `json:"ticket_ids"`
+	GATierID  int32   `json:"ga_tier_id,omitempty"`
+	Quantity  int32   `json:"quantity,omitempty"`
+	ListingID int32   `json:"listing_id,omitempty"`
+}
+
+// newHoldToken generates a random, hex-encoded token to identify a hold.
+func newHoldToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
 
 type TicketsService struct {
-	repo               TicketsRepoer
-	ticketHoldClient   cache.CacheClienter
-	TicketHoldDuration time.Duration
+	repo                    TicketsRepoer
+	paymentsRepo            PaymentsRepoer
+	ticketHoldClient        cache.CacheClienter
+	paymentProcessor        payment.PaymentProcessor
+	pricingService          *PricingService
+	promoCodesService       *PromoCodesService
+	waitingRoomService      *WaitingRoomService
+	TicketHoldDuration      time.Duration
+	TicketHoldMaxExtensions int
 }
 
 func NewTicketsService(
 	repo TicketsRepoer,
+	paymentsRepo PaymentsRepoer,
 	ticketHoldClient cache.CacheClienter,
+	paymentProcessor payment.PaymentProcessor,
+	pricingService *PricingService,
+	promoCodesService *PromoCodesService,
+	waitingRoomService *WaitingRoomService,
 	ticketHoldDuration time.Duration,
+	ticketHoldMaxExtensions int,
 ) *TicketsService {
 	return &TicketsService{
-		repo:               repo,
-		ticketHoldClient:   ticketHoldClient,
-		TicketHoldDuration: ticketHoldDuration,
+		repo:                    repo,
+		paymentsRepo:            paymentsRepo,
+		ticketHoldClient:        ticketHoldClient,
+		paymentProcessor:        paymentProcessor,
+		pricingService:          pricingService,
+		promoCodesService:       promoCodesService,
+		waitingRoomService:      waitingRoomService,
+		TicketHoldDuration:      ticketHoldDuration,
+		TicketHoldMaxExtensions: ticketHoldMaxExtensions,
+	}
+}
+
+// labelVenueSeats checks that each of the tickets for a seat of the venue's
+// layout is for a distinct seat of the event's venue, that hasn't already had
+// a ticket released for it. Tickets without a seat label are labelled with
+// their seat's section.
+func (svc *TicketsService) labelVenueSeats(
+	ctx context.Context,
+	eventID int32,
+	tickets []entities.Ticket,
+) error {
+	seatIDs := make([]int32, 0)
+	for _, ticket := range tickets {
+		if ticket.VenueSeatID == 0 {
+			continue
+		}
+		if slices.Contains(seatIDs, ticket.VenueSeatID) {
+			return ErrSeatReleased
+		}
+		seatIDs = append(seatIDs, ticket.VenueSeatID)
+	}
+	if len(seatIDs) == 0 {
+		return nil
+	}
+
+	seats, err := svc.repo.GetEventVenueSeats(ctx, eventID, seatIDs)
+	if err != nil {
+		return err
+	}
+	if len(seats) != len(seatIDs) {
+		return ErrNoSuchSeat
+	}
+
+	sectionNames := make(map[int32]string, len(seats))
+	for _, seat := range seats {
+		if seat.IsReleased {
+			return ErrSeatReleased
+		}
+		sectionNames[seat.ID] = seat.SectionName
 	}
+
+	for idx, ticket := range tickets {
+		if ticket.VenueSeatID != 0 && ticket.Seat == "" {
+			tickets[idx].Seat = sectionNames[ticket.VenueSeatID]
+		}
+	}
+	return nil
 }
 
-// AddTickets creates new tickets for the given event.
+// AddTickets creates new tickets for the given event. All of an event's
+// tickets must be priced in the same currency. Each ticket is either for a
+// seat of the event's venue's layout, of which there can only be one ticket,
+// or is labelled with a seat. A ticket released against one of the event's
+// ticket types is priced at the type's price.
 func (svc *TicketsService) AddTickets(
 	ctx context.Context,
 	eventID int32,
 	tickets []entities.Ticket,
 ) error {
+	if len(tickets) == 0 {
+		return svc.repo.WriteTickets(ctx, tickets)
+	}
+
+	tickets, err := svc.prepareTickets(ctx, eventID, tickets)
+	if err != nil {
+		return err
+	}
 	return svc.repo.WriteTickets(ctx, tickets)
 }
 
-// AggregateTickets groups tickets for an event by seat.
+// prepareTickets checks that new tickets for the given event can be added,
+// and returns the tickets priced by their ticket types and labelled by their
+// venue seats. The given tickets aren't modified.
+func (svc *TicketsService) prepareTickets(
+	ctx context.Context,
+	eventID int32,
+	tickets []entities.Ticket,
+) ([]entities.Ticket, error) {
+	tickets = slices.Clone(tickets)
+	if err := svc.priceTicketTypes(ctx, eventID, tickets); err != nil {
+		return nil, err
+	}
+
+	currency := tickets[0].Price.Currency
+	for _, ticket := range tickets {
+		if err := ticket.Price.Validate(); err != nil {
+			return nil, err
+		}
+		if ticket.Price.Currency != currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+		if ticket.Seat == "" && ticket.VenueSeatID == 0 {
+			return nil, ErrMissingSeat
+		}
+	}
+
+	if err := svc.labelVenueSeats(ctx, eventID, tickets); err != nil {
+		return nil, err
+	}
+
+	currencies, err := svc.repo.GetEventCurrencies(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range currencies {
+		if existing != currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+	}
+
+	return tickets, nil
+}
+
+// aggregateKey identifies a group of an event's tickets that are for the same
+// seat, of the same ticket type and part of the same release.
+type aggregateKey struct {
+	seat         string
+	ticketTypeID int32
+	releaseID    int32
+}
+
+// AggregateTickets groups tickets for an event by seat, ticket type and
+// release.
 func (svc *TicketsService) AggregateTickets(tickets []entities.Ticket) []entities.AvailableTicketAggregate {
-	grouped := make(map[string][]entities.Ticket)
+	grouped := make(map[aggregateKey][]entities.Ticket)
 	for _, ticket := range tickets {
 		if ticket.IsPurchased {
 			continue
 		}
 
-		group, ok := grouped[ticket.Seat]
+		key := aggregateKey{
+			seat:         ticket.Seat,
+			ticketTypeID: ticket.TicketTypeID,
+			releaseID:    ticket.ReleaseID,
+		}
+		group, ok := grouped[key]
 		if !ok {
-			grouped[ticket.Seat] = make([]entities.Ticket, 0)
+			grouped[key] = make([]entities.Ticket, 0)
 		}
-		grouped[ticket.Seat] = append(group, ticket)
+		grouped[key] = append(group, ticket)
 	}
 
 	aggregates := make([]entities.AvailableTicketAggregate, len(grouped))
@@ -135,9 +341,11 @@ func (svc *TicketsService) AggregateTickets(tickets []entities.Ticket) []entitie
 
 		ticket := group[0]
 		aggregates[idx] = entities.AvailableTicketAggregate{
-			Price: ticket.Price,
-			Seat:  ticket.Seat,
-			IDs:   ids,
+			Price:        ticket.Price,
+			Seat:         ticket.Seat,
+			TicketTypeID: ticket.TicketTypeID,
+			ReleaseID:    ticket.ReleaseID,
+			IDs:          ids,
 		}
 		idx++
 	}
@@ -152,6 +360,9 @@ func (svc *TicketsService) GetAvailableTickets(ctx context.Context, eventID int3
 	if err != nil {
 		return nil, err
 	}
+	if len(tickets) == 0 {
+		return []entities.AvailableTicketAggregate{}, nil
+	}
 
 	// Check for tickets that have a purchase hold on them and filter them out.
 	cacheKeys := make([]string, len(tickets))
@@ -173,22 +384,72 @@ func (svc *TicketsService) GetAvailableTickets(ctx context.Context, eventID int3
 	return svc.AggregateTickets(tickets), nil
 }
 
+// checkAdmission checks that the holder has been admitted, by the admission
+// token given by `access`, to each of the events given by id that has a waiting
+// room.
+func (svc *TicketsService) checkAdmission(
+	ctx context.Context,
+	eventIDs []int32,
+	holderID string,
+	access entities.SaleAccess,
+) error {
+	if svc.waitingRoomService == nil {
+		return nil
+	}
+
+	eventIDs = slices.Clone(eventIDs)
+	slices.Sort(eventIDs)
+	for _, eventID := range slices.Compact(eventIDs) {
+		err := svc.waitingRoomService.CheckAdmission(ctx, eventID, holderID, access.AdmissionToken)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SetTicketHold places a time-bounded purchase hold on the ticket given by the
-// ticket id.
-func (svc *TicketsService) SetTicketHold(ctx context.Context, ticketID int32, holdID string) error {
+// ticket id, if it's on sale, or if `access` gives access to one of its
+// release's presales. If the ticket's event has a waiting room, `access` must
+// also admit the holder. The hold counts against the event's limit on tickets
+// per user, if it has one.
+func (svc *TicketsService) SetTicketHold(
+	ctx context.Context,
+	ticketID int32,
+	holdID string,
+	access entities.SaleAccess,
+) error {
 	if holdID == "" {
 		return ErrInvalidHoldID
 	}
 
 	// Check that the ticket exists, with lack of an error indicating that it
 	// exists.
-	_, err := svc.repo.GetTicket(ctx, ticketID)
+	ticket, err := svc.repo.GetTicket(ctx, ticketID)
 	if err != nil {
 		return err
 	}
+	if err := svc.checkAdmission(ctx, []int32{ticket.EventID}, holdID, access); err != nil {
+		return err
+	}
+	if err := svc.checkOnSale(ctx, []entities.Ticket{ticket}, access); err != nil {
+		return err
+	}
 
+	// The ticket is held before it's reserved against the purchase limit, so
+	// that holding a ticket that's already held doesn't touch the existing
+	// hold's reservation, which is made by the ticket's key.
 	key := svc.ticketHoldClient.MakeKey(ticketID)
-	return svc.ticketHoldClient.Set(ctx, key, holdID, svc.TicketHoldDuration)
+	if err := svc.ticketHoldClient.Set(ctx, key, holdID, svc.TicketHoldDuration); err != nil {
+		return err
+	}
+
+	counts := map[int32]int32{ticket.EventID: 1}
+	if _, err := svc.reservePurchaseLimits(ctx, holdID, key, counts, svc.TicketHoldDuration); err != nil {
+		deleteErr := svc.ticketHoldClient.CompareAndDelete(context.WithoutCancel(ctx), key, holdID)
+		return errors.Join(err, deleteErr)
+	}
+	return nil
 }
 
 // GetHeldTicket fetches the ticket given by `ticketID`, if it is currently held
@@ -215,8 +476,982 @@ func (svc *TicketsService) GetHeldTicket(ctx context.Context, ticketID int32, ho
 	return
 }
 
-func (svc *TicketsService) SetTicketPurchaser(ctx context.Context, ticketID int32, purchaserID int32) error {
-	return svc.repo.SetTicketPurchaser(ctx, ticketID, purchaserID)
+// ReleaseTicketHold removes the purchase hold on the ticket given by the ticket
+// id, if the given hold id matches the current purchase hold.
+func (svc *TicketsService) ReleaseTicketHold(ctx context.Context, ticketID int32, holdID string) error {
+	if holdID == "" {
+		return ErrInvalidHoldID
+	}
+
+	key := svc.ticketHoldClient.MakeKey(ticketID)
+	err := svc.ticketHoldClient.CompareAndDelete(ctx, key, holdID)
+	if errors.Is(err, cache.ErrValueMismatch) {
+		return svc.mapTicketHoldMismatch(ctx, key, holdID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The hold no longer counts against the event's purchase limit. The hold
+	// has been released regardless, and its reservation still expires.
+	if ticket, err := svc.repo.GetTicket(ctx, ticketID); err == nil {
+		svc.releasePurchaseLimits(ctx, holdID, key, []int32{ticket.EventID})
+	}
+	return nil
+}
+
+// ExtendTicketHold resets the expiration of the purchase hold on the ticket
+// given by the ticket id, if the given hold id matches the current purchase
+// hold. A hold can be extended at most `TicketHoldMaxExtensions` times. The new
+// expiration time is returned.
+func (svc *TicketsService) ExtendTicketHold(ctx context.Context, ticketID int32, holdID string) (time.Time, error) {
+	if holdID == "" {
+		return time.Time{}, ErrInvalidHoldID
+	}
+
+	key := svc.ticketHoldClient.MakeKey(ticketID)
+	expiresAt := time.Now().Add(svc.TicketHoldDuration)
+	err := svc.ticketHoldClient.CompareAndExtend(
+		ctx,
+		key,
+		holdID,
+		svc.TicketHoldDuration,
+		svc.TicketHoldMaxExtensions,
+	)
+	if err != nil {
+		if errors.Is(err, cache.ErrValueMismatch) {
+			return time.Time{}, svc.mapTicketHoldMismatch(ctx, key, holdID)
+		}
+		return time.Time{}, err
+	}
+
+	// Renew the hold's reservation against the event's purchase limit, so
+	// that it keeps counting until the hold expires. Failing to renew it isn't
+	// an error, as purchases are checked against the limit regardless.
+	if ticket, err := svc.repo.GetTicket(ctx, ticketID); err == nil {
+		counts := map[int32]int32{ticket.EventID: 1}
+		svc.reservePurchaseLimits(ctx, holdID, key, counts, svc.TicketHoldDuration)
+	}
+	return expiresAt, nil
+}
+
+// mapTicketHoldMismatch gives the error for the hold on a ticket, under `key`,
+// not matching `holdID`. A ticket that's part of one of the holder's holds on
+// several tickets is held by the hold's token instead, and its hold has to be
+// released or extended along with the rest of the hold's tickets.
+func (svc *TicketsService) mapTicketHoldMismatch(ctx context.Context, key string, holdID string) error {
+	token, err := svc.ticketHoldClient.Get(ctx, key)
+	if err != nil {
+		return ErrHoldIDMismatch
+	}
+	if _, _, err := svc.getTicketsHold(ctx, token, holdID); err != nil {
+		return ErrHoldIDMismatch
+	}
+	return ErrTicketInTicketsHold
+}
+
+// SetTicketsHold places a time-bounded purchase hold on all of the tickets
+// given by `ticketIDs` at once. If any of the tickets are already held, none of
+// them are. The returned hold's token identifies the hold for purchase. As with
+// a single ticket, the tickets must be on sale, or accessible by `access`, the
+// holder must be admitted to events with a waiting room, and the hold counts
+// against the events' limits on tickets per user.
+func (svc *TicketsService) SetTicketsHold(
+	ctx context.Context,
+	ticketIDs []int32,
+	holderID string,
+	access entities.SaleAccess,
+) (hold entities.TicketHold, err error) {
+	if holderID == "" {
+		err = ErrInvalidHoldID
+		return
+	}
+
+	ticketIDs = slices.Clone(ticketIDs)
+	slices.Sort(ticketIDs)
+	ticketIDs = slices.Compact(ticketIDs)
+	if len(ticketIDs) == 0 {
+		err = ErrEmptyHold
+		return
+	}
+
+	// Check that all of the tickets exist and are available before holding
+	// any of them.
+	tickets, err := svc.repo.GetTickets(ctx, ticketIDs)
+	if err != nil {
+		return
+	}
+	eventIDs := make([]int32, len(tickets))
+	for idx, ticket := range tickets {
+		if ticket.IsPurchased {
+			err = ErrTicketPurchased
+			return
+		}
+		eventIDs[idx] = ticket.EventID
+	}
+	if err = svc.checkAdmission(ctx, eventIDs, holderID, access); err != nil {
+		return
+	}
+	if err = svc.checkOnSale(ctx, tickets, access); err != nil {
+		return
+	}
+
+	record := ticketHoldRecord{HolderID: holderID, TicketIDs: ticketIDs}
+	return svc.placeTicketsHold(ctx, record, countTicketsByEvent(tickets), svc.TicketHoldDuration)
+}
+
+// placeTicketsHold places a purchase hold on all of the tickets of the hold's
+// record at once for the record's holder, lasting `duration`, and reserves the
+// number of tickets of each event, given by `counts`, against the events'
+// limits on tickets per user.
+func (svc *TicketsService) placeTicketsHold(
+	ctx context.Context,
+	record ticketHoldRecord,
+	counts map[int32]int32,
+	duration time.Duration,
+) (hold entities.TicketHold, err error) {
+	token, err := newHoldToken()
+	if err != nil {
+		return
+	}
+	value, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	holderID := record.HolderID
+	ticketIDs := record.TicketIDs
+
+	limitedEventIDs, err := svc.reservePurchaseLimits(ctx, holderID, token, counts, duration)
+	if err != nil {
+		return
+	}
+
+	// Each ticket is held by the hold's token, so that a purchase can check
+	// that every ticket still belongs to the hold.
+	values := map[string]string{svc.ticketHoldClient.MakeHoldKey(token): string(value)}
+	for _, ticketID := range ticketIDs {
+		values[svc.ticketHoldClient.MakeKey(ticketID)] = token
+	}
+
+	expiresAt := time.Now().Add(duration)
+	if err = svc.ticketHoldClient.SetMany(ctx, values, duration); err != nil {
+		svc.releasePurchaseLimits(context.WithoutCancel(ctx), holderID, token, limitedEventIDs)
+		return
+	}
+
+	hold = entities.TicketHold{
+		Token:     token,
+		HolderID:  holderID,
+		TicketIDs: ticketIDs,
+		ExpiresAt: expiresAt,
+	}
+	return
+}
+
+// getTicketsHold fetches the record of the hold given by `token`, along with
+// its raw cached value, if the hold is still active and was placed by
+// `holderID`.
+func (svc *TicketsService) getTicketsHold(
+	ctx context.Context,
+	token string,
+	holderID string,
+) (record ticketHoldRecord, value string, err error) {
+	if token == "" {
+		err = ErrInvalidHoldToken
+		return
+	}
+	if holderID == "" {
+		err = ErrInvalidHoldID
+		return
+	}
+
+	value, err = svc.ticketHoldClient.Get(ctx, svc.ticketHoldClient.MakeHoldKey(token))
+	if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal([]byte(value), &record); err != nil {
+		return
+	}
+	if record.HolderID != holderID {
+		err = ErrHoldIDMismatch
+	}
+	return
+}
+
+// makeTicketsHolds maps the cache keys of a hold on a set of tickets to their
+// expected values.
+func (svc *TicketsService) makeTicketsHolds(token string, record ticketHoldRecord, value string) map[string]string {
+	holds := map[string]string{svc.ticketHoldClient.MakeHoldKey(token): value}
+	for _, ticketID := range record.TicketIDs {
+		holds[svc.ticketHoldClient.MakeKey(ticketID)] = token
+	}
+	return holds
+}
+
+// checkHolds checks that each of the given cache keys is currently set to its
+// expected value.
+func (svc *TicketsService) checkHolds(ctx context.Context, holds map[string]string) error {
+	keys := make([]string, 0, len(holds))
+	for key := range holds {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	actual, err := svc.ticketHoldClient.GetMany(ctx, keys...)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		actualValue, ok := actual[key]
+		if !ok {
+			return cache.ErrNotFound
+		}
+		if actualValue != holds[key] {
+			return ErrHoldIDMismatch
+		}
+	}
+	return nil
+}
+
+// GetHeldTickets fetches the tickets held by the hold given by `token`, if the
+// hold is still active and was placed by `holderID`.
+func (svc *TicketsService) GetHeldTickets(
+	ctx context.Context,
+	token string,
+	holderID string,
+) ([]entities.Ticket, error) {
+	record, value, err := svc.getTicketsHold(ctx, token, holderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that each ticket is still held by this hold.
+	if err := svc.checkHolds(ctx, svc.makeTicketsHolds(token, record, value)); err != nil {
+		return nil, err
+	}
+
+	return svc.repo.GetTickets(ctx, record.TicketIDs)
+}
+
+// makeHoldReservationsKeys gives the cache keys that the hold's reservations
+// are under: the reservations of its general admission tier, if any, and of
+// its events' limits on tickets per user.
+func (svc *TicketsService) makeHoldReservationsKeys(ctx context.Context, record ticketHoldRecord) ([]string, error) {
+	var keys []string
+	var eventIDs []int32
+	if record.GATierID != 0 {
+		tier, err := svc.repo.GetGATier(ctx, record.GATierID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, svc.ticketHoldClient.MakeReservationsKey(tier.ID))
+		eventIDs = []int32{tier.EventID}
+	} else {
+		tickets, err := svc.repo.GetTickets(ctx, record.TicketIDs)
+		if err != nil {
+			return nil, err
+		}
+		eventIDs = countedEventIDs(countTicketsByEvent(tickets))
+	}
+
+	for _, eventID := range eventIDs {
+		keys = append(keys, svc.ticketHoldClient.MakeLimitKey(eventID, record.HolderID))
+	}
+	return keys, nil
+}
+
+// ReleaseTicketsHold removes the purchase hold given by `token`, if it was
+// placed by `holderID`. The hold is removed from all of its tickets at once,
+// along with its reservations against general admission tiers and events'
+// limits on tickets per user.
+func (svc *TicketsService) ReleaseTicketsHold(ctx context.Context, token string, holderID string) error {
+	record, value, err := svc.getTicketsHold(ctx, token, holderID)
+	if err != nil {
+		return err
+	}
+	reservationsKeys, err := svc.makeHoldReservationsKeys(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	holds := svc.makeTicketsHolds(token, record, value)
+	err = svc.ticketHoldClient.ReleaseHold(ctx, holds, token, reservationsKeys)
+	if errors.Is(err, cache.ErrValueMismatch) {
+		return ErrHoldIDMismatch
+	}
+	return err
+}
+
+// ExtendTicketsHold resets the expiration of the purchase hold given by
+// `token`, if it was placed by `holderID`. The hold is extended on all of its
+// tickets at once, along with its reservations against general admission
+// tiers and events' limits on tickets per user. As with a hold on a single
+// ticket, a hold can be extended at most `TicketHoldMaxExtensions` times. The
+// new expiration time is returned.
+func (svc *TicketsService) ExtendTicketsHold(ctx context.Context, token string, holderID string) (time.Time, error) {
+	record, value, err := svc.getTicketsHold(ctx, token, holderID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	reservationsKeys, err := svc.makeHoldReservationsKeys(ctx, record)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	holds := svc.makeTicketsHolds(token, record, value)
+	expiresAt := time.Now().Add(svc.TicketHoldDuration)
+	err = svc.ticketHoldClient.ExtendHold(
+		ctx,
+		holds,
+		token,
+		reservationsKeys,
+		svc.TicketHoldDuration,
+		svc.TicketHoldMaxExtensions,
+	)
+	if err != nil {
+		if errors.Is(err, cache.ErrValueMismatch) {
+			return time.Time{}, ErrHoldIDMismatch
+		}
+		return time.Time{}, err
+	}
+	return expiresAt, nil
+}
+
+// lockTickets acquires the purchase lock on all of the given tickets, so that
+// at most one purchase of a ticket can be in progress at a time. The returned
+// function releases the lock.
+func (svc *TicketsService) lockTickets(ctx context.Context, ticketIDs []int32) (func(), error) {
+	token, err := newHoldToken()
+	if err != nil {
+		return nil, err
+	}
+
+	locks := make(map[string]string, len(ticketIDs))
+	for _, ticketID := range ticketIDs {
+		locks[svc.ticketHoldClient.MakeLockKey(ticketID)] = token
+	}
+
+	if err := svc.ticketHoldClient.SetMany(ctx, locks, purchaseLockDuration); err != nil {
+		if errors.Is(err, cache.ErrAlreadyHasHold) {
+			return nil, ErrPurchaseInProgress
+		}
+		return nil, err
+	}
+
+	unlock := func() {
+		// Release the lock even if the request has been cancelled. If this
+		// fails, the lock still expires.
+		ctx := context.WithoutCancel(ctx)
+		for key := range locks {
+			svc.ticketHoldClient.CompareAndDelete(ctx, key, token)
+		}
+	}
+	return unlock, nil
+}
+
+// voidPayment voids an authorized payment, and records it as voided. The
+// payment is voided even if the context has been cancelled, as the user's
+// funds would otherwise stay reserved.
+func (svc *TicketsService) voidPayment(ctx context.Context, paymentRecord *entities.Payment) error {
+	ctx = context.WithoutCancel(ctx)
+	if err := svc.paymentProcessor.VoidPayment(ctx, paymentRecord.Reference); err != nil {
+		return err
+	}
+	return payment.Transition(ctx, svc.paymentsRepo, paymentRecord, entities.PaymentStatusVoided)
+}
+
+// refundPayment refunds a payment that was captured for a purchase which then
+// failed to be written, e.g. if committing it failed, so that the user isn't
+// charged without an order. The payment is still recorded as authorized, as
+// recording the capture is rolled back along with the purchase, so it's first
+// recorded as captured, which also keeps the sweeper from trying to void it.
+func (svc *TicketsService) refundPayment(ctx context.Context, paymentRecord *entities.Payment) error {
+	ctx = context.WithoutCancel(ctx)
+	if err := payment.Transition(ctx, svc.paymentsRepo, paymentRecord, entities.PaymentStatusCaptured); err != nil {
+		return err
+	}
+	key := fmt.Sprintf("payment-%d", paymentRecord.ID)
+	if _, err := svc.paymentProcessor.Refund(ctx, paymentRecord.Reference, paymentRecord.Amount, key); err != nil {
+		return err
+	}
+	return payment.Transition(ctx, svc.paymentsRepo, paymentRecord, entities.PaymentStatusRefunded)
+}
+
+// settleFailedPurchase voids the authorized payment of a purchase that failed,
+// or refunds it if it was captured before the purchase failed.
+func (svc *TicketsService) settleFailedPurchase(
+	ctx context.Context,
+	paymentRecord *entities.Payment,
+	captured bool,
+) error {
+	if captured {
+		return svc.refundPayment(ctx, paymentRecord)
+	}
+	return svc.voidPayment(ctx, paymentRecord)
+}
+
+// newCapture gives a callback that captures the authorized payment, and
+// records on `captured` whether it has been captured.
+func (svc *TicketsService) newCapture(
+	paymentRecord entities.Payment,
+	captured *bool,
+) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if err := svc.paymentProcessor.Capture(ctx, paymentRecord.Reference); err != nil {
+			return err
+		}
+		*captured = true
+		return nil
+	}
+}
+
+// getPromoCode fetches the promo code given by `code` if it can be redeemed by
+// the purchaser, or nil if no code is given.
+func (svc *TicketsService) getPromoCode(
+	ctx context.Context,
+	code string,
+	purchaserID int32,
+) (*entities.PromoCode, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	promoCode, err := svc.promoCodesService.GetRedeemablePromoCode(ctx, code, purchaserID)
+	if err != nil {
+		return nil, err
+	}
+	return &promoCode, nil
+}
+
+// authorizePayment records a pending payment of `amount` by the purchaser, and
+// then authorizes it. A declined payment is recorded as failed, along with the
+// reason it was declined, and is given without an error.
+func (svc *TicketsService) authorizePayment(
+	ctx context.Context,
+	purchaserID int32,
+	amount money.Money,
+	card payment.Card,
+) (paymentRecord entities.Payment, err error) {
+	paymentRecord = entities.Payment{
+		PurchaserID: purchaserID,
+		Amount:      amount,
+		Status:      entities.PaymentStatusPending,
+	}
+	paymentRecord.ID, err = svc.paymentsRepo.CreatePayment(ctx, paymentRecord)
+	if err != nil {
+		return
+	}
+
+	result, err := svc.paymentProcessor.Authorize(ctx, amount, card)
+	if err != nil {
+		failErr := payment.Transition(ctx, svc.paymentsRepo, &paymentRecord, entities.PaymentStatusFailed)
+		err = errors.Join(err, failErr)
+		return
+	}
+
+	paymentRecord.Reference = result.Reference
+	if !result.Accepted {
+		paymentRecord.DeclineReason = result.DeclineReason
+		err = payment.Transition(ctx, svc.paymentsRepo, &paymentRecord, entities.PaymentStatusFailed)
+		return
+	}
+
+	err = payment.Transition(ctx, svc.paymentsRepo, &paymentRecord, entities.PaymentStatusAuthorized)
+	if err != nil {
+		// The authorization isn't recorded, so it won't be found and voided
+		// later - void it now instead.
+		voidErr := svc.paymentProcessor.VoidPayment(context.WithoutCancel(ctx), result.Reference)
+		err = errors.Join(err, voidErr)
+	}
+	return
+}
+
+// purchaseTickets purchases all of the given tickets under the purchase lock,
+// given that they are held by `holds`. The payment, for the total of the
+// tickets' quote with the promo code applied, is recorded as pending, and then
+// authorized. The tickets are only purchased, along with creating their order
+// and redeeming the promo code, once the payment has been captured - otherwise
+// the authorization is voided. Once purchased, the holds, and the reservations
+// made for them by `holderID` and `token` against the events' purchase limits,
+// are removed.
+func (svc *TicketsService) purchaseTickets(
+	ctx context.Context,
+	ticketIDs []int32,
+	holds map[string]string,
+	holderID string,
+	token string,
+	purchaserID int32,
+	card payment.Card,
+	promoCode string,
+	access entities.SaleAccess,
+) (purchase entities.PurchaseResult, err error) {
+	unlock, err := svc.lockTickets(ctx, ticketIDs)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	// Validate the holds under the lock, as they may have expired and been
+	// replaced since the purchase was started.
+	if err = svc.checkHolds(ctx, holds); err != nil {
+		return
+	}
+
+	tickets, err := svc.repo.GetTickets(ctx, ticketIDs)
+	if err != nil {
+		return
+	}
+
+	for _, ticket := range tickets {
+		if ticket.IsPurchased {
+			err = ErrTicketPurchased
+			return
+		}
+	}
+	if err = svc.checkOnSale(ctx, tickets, access); err != nil {
+		return
+	}
+	limitedEventIDs, err := svc.checkPurchaseLimits(ctx, tickets, purchaserID)
+	if err != nil {
+		return
+	}
+
+	applied, err := svc.getPromoCode(ctx, promoCode, purchaserID)
+	if err != nil {
+		return
+	}
+
+	quote, err := svc.pricingService.Quote(ctx, tickets, applied)
+	if err != nil {
+		return
+	}
+
+	paymentRecord, err := svc.authorizePayment(ctx, purchaserID, quote.Total, card)
+	if err != nil {
+		return
+	}
+	if paymentRecord.Status != entities.PaymentStatusAuthorized {
+		purchase = entities.PurchaseResult{Accepted: false, DeclineReason: paymentRecord.DeclineReason}
+		return
+	}
+
+	var captured bool
+	capture := svc.newCapture(paymentRecord, &captured)
+	orderID, err := svc.repo.PurchaseTickets(ctx, quote, paymentRecord, capture)
+	if err != nil {
+		// The tickets haven't been purchased, e.g. if another purchase won the
+		// race for a ticket, so the authorization must be voided. If voiding
+		// fails, the payment is left as authorized and is voided later by the
+		// sweeper. If the payment was already captured, e.g. if committing the
+		// purchase failed, it's refunded instead.
+		if settleErr := svc.settleFailedPurchase(ctx, &paymentRecord, captured); settleErr != nil {
+			err = errors.Join(err, settleErr)
+			return
+		}
+
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			err = ErrTicketPurchased
+		} else if errors.Is(err, repos.ErrNoRedemptionsLeft) {
+			err = ErrPromoCodeUnavailable
+		} else if errors.Is(err, repos.ErrPurchaseLimitExceeded) {
+			err = ErrPurchaseLimitExceeded
+		}
+		return
+	}
+
+	// The purchase has been recorded, so failing to remove a hold isn't an
+	// error - it still expires, and purchased tickets are unavailable
+	// regardless.
+	for key, value := range holds {
+		svc.ticketHoldClient.CompareAndDelete(ctx, key, value)
+	}
+	svc.releasePurchaseLimits(ctx, holderID, token, limitedEventIDs)
+
+	purchase = entities.PurchaseResult{Accepted: true, OrderID: orderID}
+	return
+}
+
+// QuoteTickets itemizes the price of purchasing the tickets, given by id,
+// together, with the promo code applied if one is given. The quote's total is
+// the amount that a purchase of the tickets is charged.
+func (svc *TicketsService) QuoteTickets(
+	ctx context.Context,
+	ticketIDs []int32,
+	promoCode string,
+) (entities.Quote, error) {
+	tickets, err := svc.repo.GetTickets(ctx, ticketIDs)
+	if err != nil {
+		return entities.Quote{}, err
+	}
+	for _, ticket := range tickets {
+		if ticket.IsPurchased {
+			return entities.Quote{}, ErrTicketPurchased
+		}
+	}
+
+	applied, err := svc.getPromoCode(ctx, promoCode, 0)
+	if err != nil {
+		return entities.Quote{}, err
+	}
+	return svc.pricingService.Quote(ctx, tickets, applied)
+}
+
+// PurchaseTicket purchases the ticket given by `ticketID` for the user given
+// by `purchaserID`, if the ticket is held by the given hold id. The promo code
+// and presale access code are optional.
+func (svc *TicketsService) PurchaseTicket(
+	ctx context.Context,
+	ticketID int32,
+	holdID string,
+	purchaserID int32,
+	card payment.Card,
+	promoCode string,
+	accessCode string,
+) (entities.PurchaseResult, error) {
+	if holdID == "" {
+		return entities.PurchaseResult{}, ErrInvalidHoldID
+	}
+
+	key := svc.ticketHoldClient.MakeKey(ticketID)
+	holds := map[string]string{key: holdID}
+	access := entities.SaleAccess{UserID: purchaserID, AccessCode: accessCode}
+	return svc.purchaseTickets(ctx, []int32{ticketID}, holds, holdID, key, purchaserID, card, promoCode, access)
+}
+
+// PurchaseHeldTickets purchases all of the tickets held by the hold given by
+// `token` for the user given by `purchaserID`, if the hold was placed by
+// `holderID`. For a hold on a general admission tier, the held quantity of
+// tickets is purchased, and for a hold on a resale listing, the listing's
+// ticket is purchased from its seller. The promo code and presale access code
+// are optional.
+func (svc *TicketsService) PurchaseHeldTickets(
+	ctx context.Context,
+	token string,
+	holderID string,
+	purchaserID int32,
+	card payment.Card,
+	promoCode string,
+	accessCode string,
+) (entities.PurchaseResult, error) {
+	record, value, err := svc.getTicketsHold(ctx, token, holderID)
+	if err != nil {
+		return entities.PurchaseResult{}, err
+	}
+
+	holds := svc.makeTicketsHolds(token, record, value)
+	if record.GATierID != 0 {
+		return svc.purchaseGATierHold(ctx, token, record, holds, purchaserID, card, promoCode)
+	}
+	if record.ListingID != 0 {
+		return svc.purchaseResaleHold(ctx, token, record, holds, purchaserID, card, promoCode)
+	}
+	access := entities.SaleAccess{UserID: purchaserID, AccessCode: accessCode}
+	return svc.purchaseTickets(
+		ctx,
+		record.TicketIDs,
+		holds,
+		holderID,
+		token,
+		purchaserID,
+		card,
+		promoCode,
+		access,
+	)
+}
+
+// RefundTicket refunds the price paid for the ticket given by `ticketID` to
+// the user given by `userID`, who must have purchased it. The ticket is
+// returned to inventory if `rerelease` is set, and is voided otherwise. A
+// ticket that has been transferred can't be refunded, as the refund would be
+// paid to the card of the order it was purchased with. The refund is made with
+// the payment processor once the ticket has been returned, and if the
+// processor is unavailable, the refund is given as pending and retried later.
+func (svc *TicketsService) RefundTicket(
+	ctx context.Context,
+	ticketID int32,
+	userID int32,
+	rerelease bool,
+) (entities.Refund, error) {
+	// Refunds share the purchase lock, so that a ticket can't be refunded more
+	// than once concurrently.
+	unlock, err := svc.lockTickets(ctx, []int32{ticketID})
+	if err != nil {
+		return entities.Refund{}, err
+	}
+	defer unlock()
+
+	purchase, err := svc.repo.GetTicketPurchase(ctx, ticketID)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+	if purchase.PurchaserID != userID {
+		return entities.Refund{}, ErrNotTicketOwner
+	}
+	if purchase.OrderPurchaserID != purchase.PurchaserID {
+		return entities.Refund{}, ErrTicketTransferred
+	}
+
+	record, err := svc.repo.RefundTicket(ctx, purchase, rerelease)
+	if err != nil {
+		return entities.Refund{}, err
+	}
+
+	record, err = completeRefund(ctx, svc.repo, svc.paymentProcessor, record)
+	if err != nil && !payment.IsPermanent(err) {
+		slog.Error("Issue completing a refund, to be retried", "refund_id", record.ID, "error", err)
+		return record, nil
+	}
+	return record, err
+}
+
+type OrdersService struct {
+	repo *repos.OrdersRepo
+}
+
+func NewOrdersService(repo *repos.OrdersRepo) *OrdersService {
+	return &OrdersService{repo: repo}
+}
+
+// GetOrder fetches an order given by the id.
+func (svc *OrdersService) GetOrder(ctx context.Context, id int32) (entities.Order, error) {
+	return svc.repo.GetOrder(ctx, id)
+}
+
+// GetUserOrders fetches all orders placed by the user given by the id.
+func (svc *OrdersService) GetUserOrders(ctx context.Context, userID int32) ([]entities.Order, error) {
+	return svc.repo.GetUserOrders(ctx, userID)
+}
+
+// CancellationsRepoer provides necessary methods for database operations
+// against event cancellations.
+type CancellationsRepoer interface {
+	GetPendingCancellationRefunds(context.Context, int32) ([]entities.CancellationRefund, error)
+	UpdateCancellationRefund(context.Context, entities.CancellationRefund) error
+	CompleteEventCancellations(context.Context) (int64, error)
+	GetEventCancellation(context.Context, int32) (entities.EventCancellation, error)
+	RefundCancelledTicket(context.Context, entities.CancellationRefund, entities.TicketPurchase) (entities.Refund, error)
+}
+
+// CancellationsService refunds the purchased tickets of cancelled events.
+// Refunds are queued when an event is deleted, and processed in batches, so
+// that processing resumes where it left off after a crash. A refund that fails
+// is retried until it has been attempted `MaxAttempts` times.
+type CancellationsService struct {
+	repo             CancellationsRepoer
+	ticketsRepo      TicketsRepoer
+	paymentProcessor payment.PaymentProcessor
+	BatchSize        int32
+	MaxAttempts      int32
+}
+
+func NewCancellationsService(
+	repo CancellationsRepoer,
+	ticketsRepo TicketsRepoer,
+	paymentProcessor payment.PaymentProcessor,
+	batchSize int32,
+	maxAttempts int32,
+) *CancellationsService {
+	return &CancellationsService{
+		repo:             repo,
+		ticketsRepo:      ticketsRepo,
+		paymentProcessor: paymentProcessor,
+		BatchSize:        batchSize,
+		MaxAttempts:      maxAttempts,
+	}
+}
+
+// GetEventCancellation fetches the progress of refunds for the cancelled event
+// given by the id.
+func (svc *CancellationsService) GetEventCancellation(ctx context.Context, eventID int32) (entities.EventCancellation, error) {
+	return svc.repo.GetEventCancellation(ctx, eventID)
+}
+
+// refundTicket refunds the ticket for a cancelled event. If the ticket is no
+// longer purchased, e.g. as the purchaser refunded it themselves, the refund is
+// skipped. Once the ticket's refund has been recorded, a failure to make it
+// with the payment processor is left to be retried by the refunds service.
+func (svc *CancellationsService) refundTicket(
+	ctx context.Context,
+	cancellationRefund entities.CancellationRefund,
+) error {
+	purchase, err := svc.ticketsRepo.GetTicketPurchase(ctx, cancellationRefund.TicketID)
+	if err == nil {
+		var record entities.Refund
+		record, err = svc.repo.RefundCancelledTicket(ctx, cancellationRefund, purchase)
+		if err == nil {
+			if _, err := completeRefund(ctx, svc.ticketsRepo, svc.paymentProcessor, record); err != nil {
+				slog.Error("Issue completing a refund for a cancelled event", "refund_id", record.ID, "error", err)
+			}
+			return nil
+		}
+	}
+	if !errors.Is(err, repos.ErrNoSuchEntity) {
+		return err
+	}
+
+	cancellationRefund.Status = entities.CancellationRefundStatusSkipped
+	cancellationRefund.Attempts++
+	return svc.repo.UpdateCancellationRefund(ctx, cancellationRefund)
+}
+
+// recordFailure records an attempt to refund a ticket that failed, and gives
+// up on the refund once it has been attempted `MaxAttempts` times.
+func (svc *CancellationsService) recordFailure(
+	ctx context.Context,
+	cancellationRefund entities.CancellationRefund,
+	cause error,
+) error {
+	cancellationRefund.Attempts++
+	cancellationRefund.LastError = cause.Error()
+	if cancellationRefund.Attempts >= svc.MaxAttempts {
+		cancellationRefund.Status = entities.CancellationRefundStatusFailed
+	}
+	return svc.repo.UpdateCancellationRefund(ctx, cancellationRefund)
+}
+
+// ProcessRefunds processes a batch of pending refunds for cancelled events, and
+// returns the number of refunds processed, including skipped ones. Failing to
+// refund one ticket doesn't stop the others from being refunded. Cancellations
+// are completed once all of their refunds have been processed.
+func (svc *CancellationsService) ProcessRefunds(ctx context.Context) (int, error) {
+	cancellationRefunds, err := svc.repo.GetPendingCancellationRefunds(ctx, svc.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	countProcessed := 0
+	var errs []error
+	for _, cancellationRefund := range cancellationRefunds {
+		err := svc.refundTicket(ctx, cancellationRefund)
+		if err == nil {
+			countProcessed++
+			continue
+		}
+
+		errs = append(errs, err)
+		if err := svc.recordFailure(ctx, cancellationRefund, err); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if _, err := svc.repo.CompleteEventCancellations(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return countProcessed, errors.Join(errs...)
+}
+
+// Run processes pending refunds every `interval`, until the context is done.
+func (svc *CancellationsService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			countProcessed, err := svc.ProcessRefunds(ctx)
+			if err != nil {
+				slog.Error("Issue refunding tickets for cancelled events", "error", err)
+			}
+			if countProcessed > 0 {
+				slog.Info("Processed refunds for cancelled events", "count", countProcessed)
+			}
+		}
+	}
+}
+
+// IdempotencyRepoer provides necessary methods for database operations against
+// idempotency keys.
+type IdempotencyRepoer interface {
+	ClaimIdempotencyKey(context.Context, entities.IdempotencyKey, time.Time) (int32, bool, error)
+	GetIdempotencyKey(context.Context, string, string) (entities.IdempotencyKey, error)
+	CompleteIdempotencyKey(context.Context, entities.IdempotencyKey) error
+	DeleteIdempotencyKey(context.Context, int32) error
+	DeleteExpiredIdempotencyKeys(context.Context) (int64, error)
+}
+
+// IdempotencyService records the responses to requests made with an
+// idempotency key, so that retries of a request are given the original
+// response rather than being processed again. Keys are kept for `KeyTTL`.
+type IdempotencyService struct {
+	repo   IdempotencyRepoer
+	KeyTTL time.Duration
+}
+
+func NewIdempotencyService(repo IdempotencyRepoer, keyTTL time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, KeyTTL: keyTTL}
+}
+
+// Begin claims the user's key for the request identified by `fingerprint`. If
+// the key is claimed, the returned key should be completed with the response
+// to the request, or released if there's no response to replay. If the key
+// has already been used for the same request, the key is returned with the
+// recorded response to replay, and `replay` is set.
+func (svc *IdempotencyService) Begin(
+	ctx context.Context,
+	userID string,
+	key string,
+	fingerprint string,
+) (record entities.IdempotencyKey, replay bool, err error) {
+	record = entities.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint}
+	id, claimed, err := svc.repo.ClaimIdempotencyKey(ctx, record, time.Now().Add(svc.KeyTTL))
+	if err != nil {
+		return entities.IdempotencyKey{}, false, err
+	}
+	if claimed {
+		record.ID = id
+		return record, false, nil
+	}
+
+	existing, err := svc.repo.GetIdempotencyKey(ctx, userID, key)
+	if err != nil {
+		// The key was released, or expired, since it was claimed.
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			return entities.IdempotencyKey{}, false, ErrIdempotencyKeyInProgress
+		}
+		return entities.IdempotencyKey{}, false, err
+	}
+	if existing.Fingerprint != fingerprint {
+		return entities.IdempotencyKey{}, false, ErrIdempotencyKeyMismatch
+	}
+	if !existing.IsCompleted() {
+		return entities.IdempotencyKey{}, false, ErrIdempotencyKeyInProgress
+	}
+	return existing, true, nil
+}
+
+// Complete records the response to the key's request.
+func (svc *IdempotencyService) Complete(ctx context.Context, record entities.IdempotencyKey) error {
+	return svc.repo.CompleteIdempotencyKey(ctx, record)
+}
+
+// Release gives up the key given by id, so that the request can be retried.
+func (svc *IdempotencyService) Release(ctx context.Context, id int32) error {
+	return svc.repo.DeleteIdempotencyKey(ctx, id)
+}
+
+// Run deletes expired keys every `interval`, until the context is done.
+func (svc *IdempotencyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			countDeleted, err := svc.repo.DeleteExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				slog.Error("Issue deleting expired idempotency keys", "error", err)
+			}
+			if countDeleted > 0 {
+				slog.Info("Deleted expired idempotency keys", "count", countDeleted)
+			}
+		}
+	}
 }
 
 type SearchService struct {
@@ -2,10 +2,15 @@ package services_test
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"testing"
 	"time"
 
+	"github.com/dslaw/book-tickets/pkg/cache"
 	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
 	"github.com/dslaw/book-tickets/pkg/repos"
 	"github.com/dslaw/book-tickets/pkg/services"
 	"github.com/stretchr/testify/assert"
@@ -36,13 +41,103 @@ func (mock *MockCacheClient) GetMany(ctx context.Context, keys ...string) (map[s
 	return args.Get(0).(map[string]string), args.Error(1)
 }
 
+func (mock *MockCacheClient) SetMany(ctx context.Context, values map[string]string, expiration time.Duration) error {
+	args := mock.Called(ctx, values, expiration)
+	return args.Error(0)
+}
+
+func (mock *MockCacheClient) CompareAndDelete(ctx context.Context, key, value string) error {
+	args := mock.Called(ctx, key, value)
+	return args.Error(0)
+}
+
+func (mock *MockCacheClient) CompareAndExtend(
+	ctx context.Context,
+	key, value string,
+	expiration time.Duration,
+	maxExtensions int,
+) error {
+	args := mock.Called(ctx, key, value, expiration, maxExtensions)
+	return args.Error(0)
+}
+
+func (mock *MockCacheClient) ReleaseHold(
+	ctx context.Context,
+	holds map[string]string,
+	token string,
+	reservationsKeys []string,
+) error {
+	args := mock.Called(ctx, holds, token, reservationsKeys)
+	return args.Error(0)
+}
+
+func (mock *MockCacheClient) ExtendHold(
+	ctx context.Context,
+	holds map[string]string,
+	token string,
+	reservationsKeys []string,
+	expiration time.Duration,
+	maxExtensions int,
+) error {
+	args := mock.Called(ctx, holds, token, reservationsKeys, expiration, maxExtensions)
+	return args.Error(0)
+}
+
 func (mock *MockCacheClient) MakeKey(id int32) string {
 	args := mock.Called(id)
 	return args.Get(0).(string)
 }
 
+func (mock *MockCacheClient) MakeHoldKey(token string) string {
+	args := mock.Called(token)
+	return args.Get(0).(string)
+}
+
+func (mock *MockCacheClient) MakeLockKey(id int32) string {
+	args := mock.Called(id)
+	return args.Get(0).(string)
+}
+
+func (mock *MockCacheClient) ReserveQuantity(
+	ctx context.Context,
+	key, token string,
+	quantity, available int,
+	expiration time.Duration,
+) error {
+	args := mock.Called(ctx, key, token, quantity, available, expiration)
+	return args.Error(0)
+}
+
+func (mock *MockCacheClient) ReleaseQuantity(ctx context.Context, key, token string) error {
+	args := mock.Called(ctx, key, token)
+	return args.Error(0)
+}
+
+func (mock *MockCacheClient) GetReservedQuantity(ctx context.Context, key string) (int, error) {
+	args := mock.Called(ctx, key)
+	return args.Int(0), args.Error(1)
+}
+
+func (mock *MockCacheClient) MakeReservationsKey(id int32) string {
+	args := mock.Called(id)
+	return args.Get(0).(string)
+}
+
+func (mock *MockCacheClient) MakeLimitKey(eventID int32, holderID string) string {
+	args := mock.Called(eventID, holderID)
+	return args.Get(0).(string)
+}
+
 type MockTicketsRepo struct {
 	mock.Mock
+	// Error given by a purchase after its payment is captured, as if
+	// committing the purchase failed.
+	CommitErr error
+}
+
+func (mock *MockTicketsRepo) GetAvailableSeatedTickets(ctx context.Context, id int32) ([]entities.SeatedTicket, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).([]entities.SeatedTicket), args.Error(1)
 }
 
 func (mock *MockTicketsRepo) GetAvailableTickets(ctx context.Context, id int32) ([]entities.Ticket, error) {
@@ -50,14 +145,49 @@ func (mock *MockTicketsRepo) GetAvailableTickets(ctx context.Context, id int32)
 	return args.Get(0).([]entities.Ticket), args.Error(1)
 }
 
+func (mock *MockTicketsRepo) GetEventCurrencies(ctx context.Context, eventID int32) ([]string, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetEventVenueSeats(
+	ctx context.Context,
+	eventID int32,
+	seatIDs []int32,
+) ([]entities.EventVenueSeat, error) {
+	args := mock.Called(ctx, eventID, seatIDs)
+	return args.Get(0).([]entities.EventVenueSeat), args.Error(1)
+}
+
 func (mock *MockTicketsRepo) GetTicket(ctx context.Context, id int32) (entities.Ticket, error) {
 	args := mock.Called(ctx, id)
 	return args.Get(0).(entities.Ticket), args.Error(1)
 }
 
-func (mock *MockTicketsRepo) SetTicketPurchaser(ctx context.Context, ticketID int32, purchaserID int32) error {
-	args := mock.Called(ctx, ticketID, purchaserID)
-	return args.Error(0)
+func (mock *MockTicketsRepo) GetTickets(ctx context.Context, ids []int32) ([]entities.Ticket, error) {
+	args := mock.Called(ctx, ids)
+	return args.Get(0).([]entities.Ticket), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) PurchaseTickets(
+	ctx context.Context,
+	quote entities.Quote,
+	payment entities.Payment,
+	capture func(context.Context) error,
+) (int32, error) {
+	args := mock.Called(ctx, quote, payment)
+	if err := args.Error(1); err != nil {
+		return 0, err
+	}
+	// As with the actual repo, the purchase only succeeds if the payment is
+	// captured.
+	if err := capture(ctx); err != nil {
+		return 0, err
+	}
+	if mock.CommitErr != nil {
+		return 0, mock.CommitErr
+	}
+	return args.Get(0).(int32), nil
 }
 
 func (mock *MockTicketsRepo) WriteTickets(ctx context.Context, tickets []entities.Ticket) error {
@@ -65,17 +195,338 @@ func (mock *MockTicketsRepo) WriteTickets(ctx context.Context, tickets []entitie
 	return args.Error(0)
 }
 
+func (mock *MockTicketsRepo) GetTicketPurchase(ctx context.Context, id int32) (entities.TicketPurchase, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(entities.TicketPurchase), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) RefundTicket(
+	ctx context.Context,
+	purchase entities.TicketPurchase,
+	rerelease bool,
+) (entities.Refund, error) {
+	args := mock.Called(ctx, purchase, rerelease)
+	return args.Get(0).(entities.Refund), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) CompleteRefund(ctx context.Context, refund entities.Refund) error {
+	args := mock.Called(ctx, refund)
+	return args.Error(0)
+}
+
+func (mock *MockTicketsRepo) FailRefund(ctx context.Context, refund entities.Refund) error {
+	args := mock.Called(ctx, refund)
+	return args.Error(0)
+}
+
+func (mock *MockTicketsRepo) CreateGATier(ctx context.Context, tier entities.GATier) (int32, error) {
+	args := mock.Called(ctx, tier)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetGATier(ctx context.Context, id int32) (entities.GATier, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(entities.GATier), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetEventGATiers(ctx context.Context, eventID int32) ([]entities.GATier, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]entities.GATier), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) IssueGATickets(ctx context.Context, tierID int32, quantity int32) ([]int32, error) {
+	args := mock.Called(ctx, tierID, quantity)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) ReturnGATickets(ctx context.Context, ids []int32) error {
+	args := mock.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (mock *MockTicketsRepo) CreateTicketType(ctx context.Context, ticketType entities.TicketType) (int32, error) {
+	args := mock.Called(ctx, ticketType)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetEventTicketTypes(ctx context.Context, eventID int32) ([]entities.TicketType, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]entities.TicketType), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetTicketTypes(ctx context.Context, ids []int32) ([]entities.TicketType, error) {
+	args := mock.Called(ctx, ids)
+	return args.Get(0).([]entities.TicketType), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) WriteTicketRelease(
+	ctx context.Context,
+	release entities.TicketRelease,
+	tickets []entities.Ticket,
+) (int32, error) {
+	args := mock.Called(ctx, release, tickets)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetTicketReleases(ctx context.Context, ids []int32) ([]entities.TicketRelease, error) {
+	args := mock.Called(ctx, ids)
+	return args.Get(0).([]entities.TicketRelease), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) IsPresaleUser(ctx context.Context, presaleID int32, userID int32) (bool, error) {
+	args := mock.Called(ctx, presaleID, userID)
+	return args.Get(0).(bool), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetPurchaseLimits(
+	ctx context.Context,
+	eventIDs []int32,
+	purchaserID int32,
+) ([]entities.PurchaseLimit, error) {
+	args := mock.Called(ctx, eventIDs, purchaserID)
+	return args.Get(0).([]entities.PurchaseLimit), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetTicketResaleTerms(ctx context.Context, ticketID int32) (entities.TicketResaleTerms, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(entities.TicketResaleTerms), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) CreateResaleListing(
+	ctx context.Context,
+	listing entities.ResaleListing,
+) (entities.ResaleListing, error) {
+	args := mock.Called(ctx, listing)
+	return args.Get(0).(entities.ResaleListing), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetResaleListing(ctx context.Context, id int32) (entities.ResaleListing, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(entities.ResaleListing), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetActiveResaleListing(ctx context.Context, ticketID int32) (entities.ResaleListing, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(entities.ResaleListing), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetEventResaleListings(
+	ctx context.Context,
+	eventID int32,
+) ([]entities.AvailableResaleListing, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).([]entities.AvailableResaleListing), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) GetSellerResaleListings(ctx context.Context, sellerID int32) ([]entities.ResaleListing, error) {
+	args := mock.Called(ctx, sellerID)
+	return args.Get(0).([]entities.ResaleListing), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) CancelResaleListing(ctx context.Context, id int32) (entities.ResaleListing, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(entities.ResaleListing), args.Error(1)
+}
+
+func (mock *MockTicketsRepo) PurchaseResaleListing(
+	ctx context.Context,
+	listing entities.ResaleListing,
+	quote entities.Quote,
+	payment entities.Payment,
+	capture func(context.Context) error,
+) (int32, error) {
+	args := mock.Called(ctx, listing, quote, payment)
+	if err := args.Error(1); err != nil {
+		return 0, err
+	}
+	if err := capture(ctx); err != nil {
+		return 0, err
+	}
+	if mock.CommitErr != nil {
+		return 0, mock.CommitErr
+	}
+	return args.Get(0).(int32), nil
+}
+
+type MockPaymentsRepo struct {
+	mock.Mock
+}
+
+func (mock *MockPaymentsRepo) CreatePayment(ctx context.Context, payment entities.Payment) (int32, error) {
+	args := mock.Called(ctx, payment)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (mock *MockPaymentsRepo) UpdatePaymentStatus(ctx context.Context, payment entities.Payment, from string) error {
+	args := mock.Called(ctx, payment, from)
+	return args.Error(0)
+}
+
+type MockCancellationsRepo struct {
+	mock.Mock
+}
+
+func (mock *MockCancellationsRepo) GetPendingCancellationRefunds(
+	ctx context.Context,
+	limit int32,
+) ([]entities.CancellationRefund, error) {
+	args := mock.Called(ctx, limit)
+	return args.Get(0).([]entities.CancellationRefund), args.Error(1)
+}
+
+func (mock *MockCancellationsRepo) UpdateCancellationRefund(
+	ctx context.Context,
+	cancellationRefund entities.CancellationRefund,
+) error {
+	args := mock.Called(ctx, cancellationRefund)
+	return args.Error(0)
+}
+
+func (mock *MockCancellationsRepo) CompleteEventCancellations(ctx context.Context) (int64, error) {
+	args := mock.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (mock *MockCancellationsRepo) GetEventCancellation(ctx context.Context, eventID int32) (entities.EventCancellation, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(entities.EventCancellation), args.Error(1)
+}
+
+func (mock *MockCancellationsRepo) RefundCancelledTicket(
+	ctx context.Context,
+	cancellationRefund entities.CancellationRefund,
+	purchase entities.TicketPurchase,
+) (entities.Refund, error) {
+	args := mock.Called(ctx, cancellationRefund, purchase)
+	return args.Get(0).(entities.Refund), args.Error(1)
+}
+
+func TestTicketsServiceAddTickets(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{
+		{EventID: 1, Price: money.New(1000, "USD"), Seat: "GA"},
+		{EventID: 1, Price: money.New(2000, "USD"), Seat: "Balcony"},
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventCurrencies", ctx, int32(1)).Return([]string{"USD"}, nil)
+	mockRepo.On("WriteTickets", ctx, tickets).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.Nil(t, err)
+	mockRepo.AssertCalled(t, "WriteTickets", ctx, tickets)
+}
+
+func TestTicketsServiceAddTicketsWhenCurrenciesDiffer(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{
+		{EventID: 1, Price: money.New(1000, "USD"), Seat: "GA"},
+		{EventID: 1, Price: money.New(2000, "EUR"), Seat: "Balcony"},
+	}
+
+	mockRepo := new(MockTicketsRepo)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+	mockRepo.AssertNotCalled(t, "WriteTickets", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceAddTicketsWhenEventHasOtherCurrency(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{{EventID: 1, Price: money.New(1000, "EUR"), Seat: "GA"}}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventCurrencies", ctx, int32(1)).Return([]string{"USD"}, nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+	mockRepo.AssertNotCalled(t, "WriteTickets", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceAddTicketsWhenCurrencyUnsupported(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{{EventID: 1, Price: money.New(1000, "XYZ"), Seat: "GA"}}
+
+	service := services.NewTicketsService(new(MockTicketsRepo), nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+}
+
+func TestTicketsServiceAddTicketsForVenueSeats(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{
+		{EventID: 1, Price: money.New(1000, "USD"), VenueSeatID: 11},
+		{EventID: 1, Price: money.New(1000, "USD"), Seat: "Box 1", VenueSeatID: 12},
+	}
+	seats := []entities.EventVenueSeat{
+		{ID: 11, SectionName: "Orchestra", IsReleased: false},
+		{ID: 12, SectionName: "Boxes", IsReleased: false},
+	}
+	expected := []entities.Ticket{
+		{EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra", VenueSeatID: 11},
+		{EventID: 1, Price: money.New(1000, "USD"), Seat: "Box 1", VenueSeatID: 12},
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventCurrencies", ctx, int32(1)).Return([]string{}, nil)
+	mockRepo.On("GetEventVenueSeats", ctx, int32(1), []int32{11, 12}).Return(seats, nil)
+	mockRepo.On("WriteTickets", ctx, expected).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.Nil(t, err)
+	mockRepo.AssertCalled(t, "WriteTickets", ctx, expected)
+}
+
+func TestTicketsServiceAddTicketsWhenVenueSeatReleased(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{{EventID: 1, Price: money.New(1000, "USD"), VenueSeatID: 11}}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventVenueSeats", ctx, int32(1), []int32{11}).Return([]entities.EventVenueSeat{
+		{ID: 11, SectionName: "Orchestra", IsReleased: true},
+	}, nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.ErrorIs(t, err, services.ErrSeatReleased)
+	mockRepo.AssertNotCalled(t, "WriteTickets", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceAddTicketsWhenVenueSeatNotInLayout(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{{EventID: 1, Price: money.New(1000, "USD"), VenueSeatID: 11}}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventVenueSeats", ctx, int32(1), []int32{11}).Return([]entities.EventVenueSeat{}, nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.ErrorIs(t, err, services.ErrNoSuchSeat)
+	mockRepo.AssertNotCalled(t, "WriteTickets", mock.Anything, mock.Anything)
+}
+
 func TestTicketsServiceAggregateTickets(t *testing.T) {
 	service := &services.TicketsService{}
 	tickets := []entities.Ticket{
-		{ID: 1, EventID: 1, IsPurchased: false, Price: 10, Seat: "GA"},
-		{ID: 2, EventID: 1, IsPurchased: false, Price: 10, Seat: "GA"},
-		{ID: 3, EventID: 1, IsPurchased: false, Price: 20, Seat: "Balcony"},
-		{ID: 4, EventID: 1, PurchaserID: 1, IsPurchased: true, Price: 10, Seat: "GA"},
+		{ID: 1, EventID: 1, IsPurchased: false, Price: money.New(10, "USD"), Seat: "GA"},
+		{ID: 2, EventID: 1, IsPurchased: false, Price: money.New(10, "USD"), Seat: "GA"},
+		{ID: 3, EventID: 1, IsPurchased: false, Price: money.New(20, "USD"), Seat: "Balcony"},
+		{ID: 4, EventID: 1, PurchaserID: 1, IsPurchased: true, Price: money.New(10, "USD"), Seat: "GA"},
 	}
 	expected := []entities.AvailableTicketAggregate{
-		{Price: 10, Seat: "GA", IDs: []int32{1, 2}},
-		{Price: 20, Seat: "Balcony", IDs: []int32{3}},
+		{Price: money.New(10, "USD"), Seat: "GA", IDs: []int32{1, 2}},
+		{Price: money.New(20, "USD"), Seat: "Balcony", IDs: []int32{3}},
 	}
 
 	actual := service.AggregateTickets(tickets)
@@ -89,14 +540,15 @@ func TestTicketsServiceSetTicketHold(t *testing.T) {
 	holdID := "123"
 
 	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
 	mockRepo.On("GetTicket", mock.Anything, ticketID).Return(entities.Ticket{}, nil)
 
 	mockClient := new(MockCacheClient)
 	mockClient.On("MakeKey", ticketID).Return(field)
 	mockClient.On("Set", mock.Anything, field, holdID, ticketHoldDuration).Return(nil)
 
-	service := services.NewTicketsService(mockRepo, mockClient, ticketHoldDuration)
-	err := service.SetTicketHold(context.Background(), ticketID, holdID)
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	err := service.SetTicketHold(context.Background(), ticketID, holdID, entities.SaleAccess{})
 
 	assert.Nil(t, err)
 	mockClient.AssertCalled(t, "MakeKey", ticketID)
@@ -114,8 +566,8 @@ func TestTicketsServiceSetTicketHoldWhenTicketDoesntExist(t *testing.T) {
 		repos.ErrNoSuchEntity,
 	)
 
-	service := services.NewTicketsService(mockRepo, nil, ticketHoldDuration)
-	err := service.SetTicketHold(context.Background(), ticketID, holdID)
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	err := service.SetTicketHold(context.Background(), ticketID, holdID, entities.SaleAccess{})
 
 	assert.ErrorIs(t, repos.ErrNoSuchEntity, err)
 }
@@ -131,9 +583,1343 @@ func TestTicketsServiceGetHeldTicketWhenHoldIDMismatch(t *testing.T) {
 	mockClient.On("MakeKey", ticketID).Return(field)
 	mockClient.On("Get", mock.Anything, field).Return(actualHoldID, nil)
 
-	service := services.NewTicketsService(nil, mockClient, ticketHoldDuration)
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
 	ticket, err := service.GetHeldTicket(context.Background(), ticketID, holdID)
 
 	assert.Empty(t, ticket)
 	assert.ErrorIs(t, services.ErrHoldIDMismatch, err)
 }
+
+func TestTicketsServiceReleaseTicketHold(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicket", mock.Anything, ticketID).Return(entities.Ticket{ID: ticketID, EventID: 2}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("CompareAndDelete", mock.Anything, field, holdID).Return(nil)
+	mockClient.On("MakeLimitKey", int32(2), holdID).Return("limit:2:123")
+	mockClient.On("ReleaseQuantity", mock.Anything, "limit:2:123", field).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	err := service.ReleaseTicketHold(context.Background(), ticketID, holdID)
+
+	assert.Nil(t, err)
+	mockClient.AssertCalled(t, "CompareAndDelete", mock.Anything, field, holdID)
+	mockClient.AssertCalled(t, "ReleaseQuantity", mock.Anything, "limit:2:123", field)
+}
+
+func TestTicketsServiceReleaseTicketHoldWhenHoldIDMismatch(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "222"
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeHoldKey", "111").Return("hold:111")
+	mockClient.On("CompareAndDelete", mock.Anything, field, holdID).Return(cache.ErrValueMismatch)
+	mockClient.On("Get", mock.Anything, field).Return("111", nil)
+	mockClient.On("Get", mock.Anything, "hold:111").Return("", cache.ErrNotFound)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	err := service.ReleaseTicketHold(context.Background(), ticketID, holdID)
+
+	assert.ErrorIs(t, err, services.ErrHoldIDMismatch)
+}
+
+func TestTicketsServiceReleaseTicketHoldWhenInTicketsHold(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+	token := "abc"
+
+	// The ticket is held by the token of the holder's hold on several
+	// tickets.
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("CompareAndDelete", mock.Anything, field, holdID).Return(cache.ErrValueMismatch)
+	mockClient.On("Get", mock.Anything, field).Return(token, nil)
+	mockClient.On("Get", mock.Anything, "hold").Return(`{"holder_id": "123", "ticket_ids": [1, 2]}`, nil)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	err := service.ReleaseTicketHold(context.Background(), ticketID, holdID)
+
+	assert.ErrorIs(t, err, services.ErrTicketInTicketsHold)
+}
+
+func TestTicketsServiceReleaseTicketHoldWhenInOtherHoldersTicketsHold(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "222"
+	token := "abc"
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("CompareAndDelete", mock.Anything, field, holdID).Return(cache.ErrValueMismatch)
+	mockClient.On("Get", mock.Anything, field).Return(token, nil)
+	mockClient.On("Get", mock.Anything, "hold").Return(`{"holder_id": "123", "ticket_ids": [1, 2]}`, nil)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	err := service.ReleaseTicketHold(context.Background(), ticketID, holdID)
+
+	assert.ErrorIs(t, err, services.ErrHoldIDMismatch)
+}
+
+func TestTicketsServiceExtendTicketHold(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+	maxExtensions := 2
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicket", mock.Anything, ticketID).Return(entities.Ticket{ID: ticketID, EventID: 2}, nil)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("CompareAndExtend", mock.Anything, field, holdID, ticketHoldDuration, maxExtensions).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, maxExtensions)
+	expiresAt, err := service.ExtendTicketHold(context.Background(), ticketID, holdID)
+
+	assert.Nil(t, err)
+	assert.True(t, expiresAt.After(time.Now()))
+	mockClient.AssertCalled(t, "CompareAndExtend", mock.Anything, field, holdID, ticketHoldDuration, maxExtensions)
+}
+
+func TestTicketsServiceExtendTicketHoldWhenMaxExtensions(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("CompareAndExtend", mock.Anything, field, holdID, ticketHoldDuration, 1).Return(cache.ErrMaxExtensions)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	_, err := service.ExtendTicketHold(context.Background(), ticketID, holdID)
+
+	assert.ErrorIs(t, err, cache.ErrMaxExtensions)
+}
+
+func TestTicketsServiceExtendTicketHoldWhenInTicketsHold(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+	token := "abc"
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("CompareAndExtend", mock.Anything, field, holdID, ticketHoldDuration, 1).Return(cache.ErrValueMismatch)
+	mockClient.On("Get", mock.Anything, field).Return(token, nil)
+	mockClient.On("Get", mock.Anything, "hold").Return(`{"holder_id": "123", "ticket_ids": [1, 2]}`, nil)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	_, err := service.ExtendTicketHold(context.Background(), ticketID, holdID)
+
+	assert.ErrorIs(t, err, services.ErrTicketInTicketsHold)
+	mockClient.AssertNotCalled(t, "ExtendHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceSetTicketsHold(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketIDs := []int32{2, 1, 2}
+	holdID := "123"
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{1, 2}).Return(
+		[]entities.Ticket{{ID: 1}, {ID: 2}},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", mock.Anything).Return("hold")
+	mockClient.On("MakeKey", int32(1)).Return("1")
+	mockClient.On("MakeKey", int32(2)).Return("2")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, ticketHoldDuration).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	hold, err := service.SetTicketsHold(context.Background(), ticketIDs, holdID, entities.SaleAccess{})
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, hold.Token)
+	assert.Equal(t, holdID, hold.HolderID)
+	assert.Equal(t, []int32{1, 2}, hold.TicketIDs)
+
+	values := mockClient.Calls[len(mockClient.Calls)-1].Arguments.Get(1).(map[string]string)
+	assert.Equal(t, hold.Token, values["1"])
+	assert.Equal(t, hold.Token, values["2"])
+	assert.JSONEq(t, `{"holder_id": "123", "ticket_ids": [1, 2]}`, values["hold"])
+}
+
+func TestTicketsServiceSetTicketsHoldWhenTicketPurchased(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketIDs := []int32{1, 2}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, ticketIDs).Return(
+		[]entities.Ticket{{ID: 1}, {ID: 2, PurchaserID: 1, IsPurchased: true}},
+		nil,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	_, err := service.SetTicketsHold(context.Background(), ticketIDs, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrTicketPurchased)
+}
+
+func TestTicketsServiceSetTicketsHoldWhenNoTickets(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+
+	service := services.NewTicketsService(nil, nil, nil, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	_, err := service.SetTicketsHold(context.Background(), []int32{}, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrEmptyHold)
+}
+
+func TestTicketsServiceGetHeldTickets(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	token := "abc"
+	holdID := "123"
+	tickets := []entities.Ticket{{ID: 1}, {ID: 2}}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, []int32{1, 2}).Return(tickets, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("MakeKey", int32(1)).Return("1")
+	mockClient.On("MakeKey", int32(2)).Return("2")
+	mockClient.On("Get", mock.Anything, "hold").Return(`{"holder_id": "123", "ticket_ids": [1, 2]}`, nil)
+	mockClient.On("GetMany", mock.Anything, []string{"1", "2", "hold"}).Return(
+		map[string]string{"1": token, "2": token, "hold": `{"holder_id": "123", "ticket_ids": [1, 2]}`},
+		nil,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	actual, err := service.GetHeldTickets(context.Background(), token, holdID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, tickets, actual)
+}
+
+func TestTicketsServiceGetHeldTicketsWhenHoldIDMismatch(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	token := "abc"
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("Get", mock.Anything, "hold").Return(`{"holder_id": "111", "ticket_ids": [1, 2]}`, nil)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	actual, err := service.GetHeldTickets(context.Background(), token, "222")
+
+	assert.Empty(t, actual)
+	assert.ErrorIs(t, err, services.ErrHoldIDMismatch)
+}
+
+func TestTicketsServiceReleaseTicketsHold(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	token := "abc"
+	holdID := "123"
+	value := `{"holder_id": "123", "ticket_ids": [1, 2, 3]}`
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, []int32{1, 2, 3}).Return(
+		[]entities.Ticket{{ID: 1, EventID: 10}, {ID: 2, EventID: 10}, {ID: 3, EventID: 11}},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("MakeKey", int32(1)).Return("1")
+	mockClient.On("MakeKey", int32(2)).Return("2")
+	mockClient.On("MakeKey", int32(3)).Return("3")
+	mockClient.On("MakeLimitKey", int32(10), holdID).Return("limit:10")
+	mockClient.On("MakeLimitKey", int32(11), holdID).Return("limit:11")
+	mockClient.On("Get", mock.Anything, "hold").Return(value, nil)
+	mockClient.On("ReleaseHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	err := service.ReleaseTicketsHold(context.Background(), token, holdID)
+
+	// Every key of the hold is released at once, along with its reservations
+	// against each of its events' purchase limits.
+	assert.Nil(t, err)
+	mockClient.AssertCalled(
+		t,
+		"ReleaseHold",
+		mock.Anything,
+		map[string]string{"hold": value, "1": token, "2": token, "3": token},
+		token,
+		[]string{"limit:10", "limit:11"},
+	)
+}
+
+func TestTicketsServiceReleaseTicketsHoldWhenHoldIDMismatch(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	token := "abc"
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("Get", mock.Anything, "hold").Return(`{"holder_id": "111", "ticket_ids": [1, 2]}`, nil)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	err := service.ReleaseTicketsHold(context.Background(), token, "222")
+
+	assert.ErrorIs(t, err, services.ErrHoldIDMismatch)
+	mockClient.AssertNotCalled(t, "ReleaseHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceExtendTicketsHold(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	token := "abc"
+	holdID := "123"
+	value := `{"holder_id": "123", "ticket_ids": [1, 2]}`
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, []int32{1, 2}).Return(
+		[]entities.Ticket{{ID: 1, EventID: 10}, {ID: 2, EventID: 10}},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("MakeKey", int32(1)).Return("1")
+	mockClient.On("MakeKey", int32(2)).Return("2")
+	mockClient.On("MakeLimitKey", int32(10), holdID).Return("limit:10")
+	mockClient.On("Get", mock.Anything, "hold").Return(value, nil)
+	mockClient.On(
+		"ExtendHold",
+		mock.Anything,
+		map[string]string{"hold": value, "1": token, "2": token},
+		token,
+		[]string{"limit:10"},
+		ticketHoldDuration,
+		1,
+	).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	expiresAt, err := service.ExtendTicketsHold(context.Background(), token, holdID)
+
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(ticketHoldDuration), expiresAt, time.Second)
+}
+
+func TestTicketsServiceExtendTicketsHoldForGATier(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	token := "abc"
+	holdID := "123"
+	value := `{"holder_id": "123", "ticket_ids": [], "ga_tier_id": 5, "quantity": 2}`
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetGATier", mock.Anything, int32(5)).Return(entities.GATier{ID: 5, EventID: 10}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeHoldKey", token).Return("hold")
+	mockClient.On("MakeReservationsKey", int32(5)).Return("ga:5")
+	mockClient.On("MakeLimitKey", int32(10), holdID).Return("limit:10")
+	mockClient.On("Get", mock.Anything, "hold").Return(value, nil)
+	mockClient.On("ExtendHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		cache.ErrMaxExtensions,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	_, err := service.ExtendTicketsHold(context.Background(), token, holdID)
+
+	// The tier's reservation is extended along with the hold.
+	assert.ErrorIs(t, err, cache.ErrMaxExtensions)
+	mockClient.AssertCalled(
+		t,
+		"ExtendHold",
+		mock.Anything,
+		map[string]string{"hold": value},
+		token,
+		[]string{"ga:5", "limit:10"},
+		ticketHoldDuration,
+		1,
+	)
+}
+
+func TestTicketsServiceQuoteTicketsWhenTicketPurchased(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, []int32{1, 2}).Return(
+		[]entities.Ticket{
+			{ID: 1, Price: money.New(20, "USD")},
+			{ID: 2, Price: money.New(20, "USD"), IsPurchased: true},
+		},
+		nil,
+	)
+
+	service := services.NewTicketsService(
+		mockRepo,
+		nil,
+		nil,
+		nil,
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	_, err := service.QuoteTickets(context.Background(), []int32{1, 2}, "")
+
+	assert.ErrorIs(t, err, services.ErrTicketPurchased)
+}
+
+func TestTicketsServicePurchaseTicket(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	lockField := "lock:1"
+	holdID := "123"
+	purchaserID := int32(123)
+	paymentID := int32(3)
+	orderID := int32(2)
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, Price: money.New(20, "USD")}},
+		nil,
+	)
+	mockRepo.On("PurchaseTickets", mock.Anything, mock.Anything, mock.Anything).Return(orderID, nil)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(paymentID, nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return(lockField)
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		processor,
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		ticketHoldDuration,
+		1,
+	)
+	purchase, err := service.PurchaseTicket(context.Background(), ticketID, holdID, purchaserID, payment.Card{}, "", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.PurchaseResult{Accepted: true, OrderID: orderID}, purchase)
+
+	// The payment is created as pending, and authorized before the purchase.
+	mockPaymentsRepo.AssertCalled(t, "CreatePayment", mock.Anything, entities.Payment{
+		PurchaserID: purchaserID,
+		Amount:      money.New(20, "USD"),
+		Status:      entities.PaymentStatusPending,
+	})
+	paymentRecord := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).(entities.Payment)
+	assert.Equal(t, paymentID, paymentRecord.ID)
+	assert.Equal(t, entities.PaymentStatusAuthorized, paymentRecord.Status)
+	mockPaymentsRepo.AssertCalled(
+		t,
+		"UpdatePaymentStatus",
+		mock.Anything,
+		paymentRecord,
+		entities.PaymentStatusPending,
+	)
+
+	// The payment is captured as part of the purchase.
+	status, _ := processor.Status(paymentRecord.Reference)
+	assert.Equal(t, entities.PaymentStatusCaptured, status)
+
+	// The purchase hold and the lock are both removed.
+	mockClient.AssertCalled(t, "CompareAndDelete", mock.Anything, field, holdID)
+	mockClient.AssertCalled(t, "CompareAndDelete", mock.Anything, lockField, mock.Anything)
+}
+
+func TestTicketsServicePurchaseTicketChargesFees(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+	purchaserID := int32(123)
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, EventID: 1, Price: money.New(1000, "USD")}},
+		nil,
+	)
+	mockRepo.On("PurchaseTickets", mock.Anything, mock.Anything, mock.Anything).Return(int32(2), nil)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		payment.NewFakeProcessor(nil, 0),
+		newPricingService(entities.FeeRule{ServiceFeeRate: 1000, FacilityFee: 200}, 1000),
+		nil,
+		nil,
+		ticketHoldDuration,
+		1,
+	)
+	_, err := service.PurchaseTicket(context.Background(), ticketID, holdID, purchaserID, payment.Card{}, "", "")
+
+	assert.Nil(t, err)
+
+	// The payment is for the ticket's face value, plus fees and tax.
+	mockPaymentsRepo.AssertCalled(t, "CreatePayment", mock.Anything, entities.Payment{
+		PurchaserID: purchaserID,
+		Amount:      money.New(1430, "USD"),
+		Status:      entities.PaymentStatusPending,
+	})
+	quote := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(entities.Quote)
+	assert.Equal(t, []entities.QuoteItem{{
+		TicketID:   ticketID,
+		FaceValue:  money.New(1000, "USD"),
+		Discount:   money.New(0, "USD"),
+		ServiceFee: money.New(100, "USD"),
+		Tax:        money.New(110, "USD"),
+		Total:      money.New(1210, "USD"),
+	}}, quote.Items)
+	assert.Equal(t, money.New(200, "USD"), quote.FacilityFee)
+	assert.Equal(t, money.New(1430, "USD"), quote.Total)
+}
+
+// newPromoCodesService creates a promo codes service that redeems a single
+// promo code.
+func newPromoCodesService(promoCode entities.PromoCode) *services.PromoCodesService {
+	mockRepo := new(MockPromoCodesRepo)
+	mockRepo.On("GetPromoCodeByCode", mock.Anything, promoCode.Code).Return(promoCode, nil)
+	mockRepo.On("GetUserPromoCodeRedemptions", mock.Anything, promoCode.ID, mock.Anything).Return(int32(0), nil)
+	return services.NewPromoCodesService(mockRepo)
+}
+
+func TestTicketsServicePurchaseTicketWithPromoCode(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+	purchaserID := int32(123)
+	promoCode := entities.PromoCode{
+		ID:           7,
+		Code:         "SUMMER25",
+		DiscountType: entities.DiscountTypePercentage,
+		PercentOff:   2500,
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, EventID: 1, Price: money.New(1000, "USD")}},
+		nil,
+	)
+	mockRepo.On("PurchaseTickets", mock.Anything, mock.Anything, mock.Anything).Return(int32(2), nil)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		payment.NewFakeProcessor(nil, 0),
+		newPricingService(entities.FeeRule{ServiceFeeFlat: 100}, 0),
+		newPromoCodesService(promoCode),
+		nil,
+		ticketHoldDuration,
+		1,
+	)
+	_, err := service.PurchaseTicket(context.Background(), ticketID, holdID, purchaserID, payment.Card{}, "summer25", "")
+
+	assert.Nil(t, err)
+
+	// The payment is for the discounted face value, plus fees.
+	mockPaymentsRepo.AssertCalled(t, "CreatePayment", mock.Anything, entities.Payment{
+		PurchaserID: purchaserID,
+		Amount:      money.New(850, "USD"),
+		Status:      entities.PaymentStatusPending,
+	})
+	quote := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(entities.Quote)
+	assert.Equal(t, promoCode.ID, quote.PromoCodeID)
+	assert.Equal(t, money.New(250, "USD"), quote.Discount)
+}
+
+func TestTicketsServicePurchaseTicketWhenPromoCodeExhausted(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+	promoCode := entities.PromoCode{
+		ID:           7,
+		Code:         "SUMMER25",
+		DiscountType: entities.DiscountTypePercentage,
+		PercentOff:   2500,
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, EventID: 1, Price: money.New(1000, "USD")}},
+		nil,
+	)
+	// The code's last redemption is taken by another purchase first.
+	mockRepo.On("PurchaseTickets", mock.Anything, mock.Anything, mock.Anything).Return(
+		int32(0),
+		repos.ErrNoRedemptionsLeft,
+	)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		payment.NewFakeProcessor(nil, 0),
+		newPricingService(entities.FeeRule{}, 0),
+		newPromoCodesService(promoCode),
+		nil,
+		ticketHoldDuration,
+		1,
+	)
+	_, err := service.PurchaseTicket(context.Background(), ticketID, holdID, int32(123), payment.Card{}, "SUMMER25", "")
+
+	assert.ErrorIs(t, err, services.ErrPromoCodeUnavailable)
+}
+
+func TestTicketsServicePurchaseTicketWhenPaymentDeclined(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+	rules := []payment.FakeRule{
+		{Pattern: regexp.MustCompile(`^4000`), DeclineReason: payment.DeclineReasonInsufficientFunds},
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, Price: money.New(20, "USD")}},
+		nil,
+	)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	processor := payment.NewFakeProcessor(rules, 0)
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		processor,
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		ticketHoldDuration,
+		1,
+	)
+	purchase, err := service.PurchaseTicket(
+		context.Background(),
+		ticketID,
+		holdID,
+		int32(123),
+		payment.Card{Number: "4000000000000000"},
+		"",
+		"",
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		entities.PurchaseResult{Accepted: false, DeclineReason: payment.DeclineReasonInsufficientFunds},
+		purchase,
+	)
+
+	// The declined payment is recorded as failed, and the tickets aren't
+	// purchased.
+	paymentRecord := mockPaymentsRepo.Calls[len(mockPaymentsRepo.Calls)-1].Arguments.Get(1).(entities.Payment)
+	assert.Equal(t, entities.PaymentStatusFailed, paymentRecord.Status)
+	assert.Equal(t, payment.DeclineReasonInsufficientFunds, paymentRecord.DeclineReason)
+	mockRepo.AssertNotCalled(t, "PurchaseTickets", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServicePurchaseTicketWhenPurchaseInProgress(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return("1")
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(cache.ErrAlreadyHasHold)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	purchase, err := service.PurchaseTicket(context.Background(), ticketID, "123", int32(123), payment.Card{}, "", "")
+
+	assert.False(t, purchase.Accepted)
+	assert.ErrorIs(t, err, services.ErrPurchaseInProgress)
+}
+
+func TestTicketsServicePurchaseTicketWhenHoldExpired(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(nil, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	purchase, err := service.PurchaseTicket(context.Background(), ticketID, "123", int32(123), payment.Card{}, "", "")
+
+	assert.False(t, purchase.Accepted)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+}
+
+func TestTicketsServicePurchaseTicketWhenPurchaseRaceLost(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, Price: money.New(20, "USD")}},
+		nil,
+	)
+	mockRepo.On("PurchaseTickets", mock.Anything, mock.Anything, mock.Anything).Return(
+		int32(0),
+		repos.ErrNoSuchEntity,
+	)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		processor,
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		ticketHoldDuration,
+		1,
+	)
+	purchase, err := service.PurchaseTicket(context.Background(), ticketID, holdID, int32(123), payment.Card{}, "", "")
+
+	assert.False(t, purchase.Accepted)
+	assert.ErrorIs(t, err, services.ErrTicketPurchased)
+
+	// The authorization is voided and recorded as such, and the hold is left
+	// intact.
+	paymentRecord := mockPaymentsRepo.Calls[len(mockPaymentsRepo.Calls)-1].Arguments.Get(1).(entities.Payment)
+	assert.Equal(t, entities.PaymentStatusVoided, paymentRecord.Status)
+	status, _ := processor.Status(paymentRecord.Reference)
+	assert.Equal(t, entities.PaymentStatusVoided, status)
+	mockClient.AssertNotCalled(t, "CompareAndDelete", mock.Anything, field, holdID)
+}
+
+func TestTicketsServicePurchaseTicketWhenCaptureFails(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, Price: money.New(20, "USD")}},
+		nil,
+	)
+	mockRepo.On("PurchaseTickets", mock.Anything, mock.Anything, mock.Anything).Return(int32(2), nil)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Capturing fails because the authorization has already been voided,
+	// e.g. by the sweeper.
+	processor := &voidingProcessor{payment.NewFakeProcessor(nil, 0)}
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		processor,
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		ticketHoldDuration,
+		1,
+	)
+	purchase, err := service.PurchaseTicket(context.Background(), ticketID, holdID, int32(123), payment.Card{}, "", "")
+
+	assert.False(t, purchase.Accepted)
+	assert.ErrorIs(t, err, payment.ErrInvalidTransition)
+	mockClient.AssertNotCalled(t, "CompareAndDelete", mock.Anything, field, holdID)
+}
+
+func TestTicketsServicePurchaseTicketWhenCommitFails(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	field := "1"
+	holdID := "123"
+	commitErr := errors.New("commit failed")
+
+	mockRepo := &MockTicketsRepo{CommitErr: commitErr}
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTickets", mock.Anything, []int32{ticketID}).Return(
+		[]entities.Ticket{{ID: ticketID, Price: money.New(20, "USD")}},
+		nil,
+	)
+	mockRepo.On("PurchaseTickets", mock.Anything, mock.Anything, mock.Anything).Return(int32(2), nil)
+
+	mockPaymentsRepo := new(MockPaymentsRepo)
+	mockPaymentsRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(int32(3), nil)
+	mockPaymentsRepo.On("UpdatePaymentStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return(field)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("GetMany", mock.Anything, []string{field}).Return(map[string]string{field: holdID}, nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	service := services.NewTicketsService(
+		mockRepo,
+		mockPaymentsRepo,
+		mockClient,
+		processor,
+		newPricingService(entities.FeeRule{}, 0),
+		nil,
+		nil,
+		ticketHoldDuration,
+		1,
+	)
+	purchase, err := service.PurchaseTicket(context.Background(), ticketID, holdID, int32(123), payment.Card{}, "", "")
+
+	assert.False(t, purchase.Accepted)
+	assert.ErrorIs(t, err, commitErr)
+
+	// The payment was captured before the purchase failed, so it's refunded
+	// rather than voided, and is recorded as such.
+	paymentRecord := mockPaymentsRepo.Calls[len(mockPaymentsRepo.Calls)-1].Arguments.Get(1).(entities.Payment)
+	assert.Equal(t, entities.PaymentStatusRefunded, paymentRecord.Status)
+	status, _ := processor.Status(paymentRecord.Reference)
+	assert.Equal(t, entities.PaymentStatusRefunded, status)
+	mockClient.AssertNotCalled(t, "CompareAndDelete", mock.Anything, field, holdID)
+}
+
+// voidingProcessor voids every payment as soon as it's authorized.
+type voidingProcessor struct {
+	*payment.FakeProcessor
+}
+
+func (proc *voidingProcessor) Authorize(ctx context.Context, amount money.Money, card payment.Card) (payment.PaymentResult, error) {
+	result, err := proc.FakeProcessor.Authorize(ctx, amount, card)
+	if err != nil {
+		return result, err
+	}
+	return result, proc.FakeProcessor.VoidPayment(ctx, result.Reference)
+}
+
+func TestTicketsServiceRefundTicket(t *testing.T) {
+	ctx := context.Background()
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	userID := int32(11)
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(30, "USD"), payment.Card{})
+	processor.Capture(ctx, result.Reference)
+
+	purchase := entities.TicketPurchase{
+		TicketID:         ticketID,
+		PurchaserID:      userID,
+		OrderPurchaserID: userID,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: result.Reference,
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicketPurchase", mock.Anything, ticketID).Return(purchase, nil)
+	mockRepo.On("RefundTicket", mock.Anything, purchase, true).Return(
+		entities.Refund{
+			ID:               5,
+			PaymentID:        3,
+			TicketID:         ticketID,
+			Amount:           money.New(20, "USD"),
+			Status:           entities.RefundStatusPending,
+			PaymentReference: result.Reference,
+		},
+		nil,
+	)
+	mockRepo.On("CompleteRefund", mock.Anything, mock.Anything).Return(nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, processor, nil, nil, nil, ticketHoldDuration, 1)
+	refund, err := service.RefundTicket(ctx, ticketID, userID, true)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(5), refund.ID)
+	assert.Equal(t, entities.RefundStatusCompleted, refund.Status)
+	assert.NotEmpty(t, refund.Reference)
+	mockRepo.AssertCalled(t, "CompleteRefund", mock.Anything, refund)
+
+	// Part of the payment has been refunded, so it remains captured.
+	status, _ := processor.Status(result.Reference)
+	assert.Equal(t, entities.PaymentStatusCaptured, status)
+	mockClient.AssertCalled(t, "CompareAndDelete", mock.Anything, "lock:1", mock.Anything)
+}
+
+// unavailableProcessor is a payment processor that can't be reached to make
+// refunds.
+type unavailableProcessor struct {
+	*payment.FakeProcessor
+}
+
+func (proc *unavailableProcessor) Refund(
+	ctx context.Context,
+	reference string,
+	amount money.Money,
+	key string,
+) (string, error) {
+	return "", payment.ErrGatewayUnavailable
+}
+
+func TestTicketsServiceRefundTicketWhenProcessorUnavailable(t *testing.T) {
+	ctx := context.Background()
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+	userID := int32(11)
+
+	purchase := entities.TicketPurchase{
+		TicketID:         ticketID,
+		PurchaserID:      userID,
+		OrderPurchaserID: userID,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: "fake-1",
+	}
+	pending := entities.Refund{
+		ID:               5,
+		PaymentID:        3,
+		TicketID:         ticketID,
+		Amount:           money.New(20, "USD"),
+		Status:           entities.RefundStatusPending,
+		PaymentReference: "fake-1",
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicketPurchase", mock.Anything, ticketID).Return(purchase, nil)
+	mockRepo.On("RefundTicket", mock.Anything, purchase, false).Return(pending, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	processor := &unavailableProcessor{payment.NewFakeProcessor(nil, 0)}
+	service := services.NewTicketsService(mockRepo, nil, mockClient, processor, nil, nil, nil, ticketHoldDuration, 1)
+	refund, err := service.RefundTicket(ctx, ticketID, userID, false)
+
+	// The ticket has been returned, so the refund is left pending to be
+	// retried.
+	assert.Nil(t, err)
+	assert.Equal(t, pending, refund)
+	mockRepo.AssertNotCalled(t, "CompleteRefund", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "FailRefund", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceRefundTicketWhenNotOwner(t *testing.T) {
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicketPurchase", mock.Anything, ticketID).Return(
+		entities.TicketPurchase{TicketID: ticketID, PurchaserID: 12},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, ticketHoldDuration, 1)
+	_, err := service.RefundTicket(context.Background(), ticketID, int32(11), false)
+
+	assert.ErrorIs(t, err, services.ErrNotTicketOwner)
+	mockRepo.AssertNotCalled(t, "RefundTicket", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceRefundTicketWhenTransferred(t *testing.T) {
+	ctx := context.Background()
+	ticketHoldDuration, _ := time.ParseDuration("1m")
+	ticketID := int32(1)
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(20, "USD"), payment.Card{})
+	processor.Capture(ctx, result.Reference)
+
+	// The ticket was purchased by user 12, and transferred to user 11.
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTicketPurchase", mock.Anything, ticketID).Return(
+		entities.TicketPurchase{
+			TicketID:         ticketID,
+			PurchaserID:      11,
+			OrderPurchaserID: 12,
+			Price:            money.New(20, "USD"),
+			PaymentID:        3,
+			PaymentReference: result.Reference,
+		},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeLockKey", ticketID).Return("lock:1")
+	mockClient.On("SetMany", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("CompareAndDelete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, processor, nil, nil, nil, ticketHoldDuration, 1)
+	_, err := service.RefundTicket(ctx, ticketID, int32(11), false)
+
+	assert.ErrorIs(t, err, services.ErrTicketTransferred)
+	mockRepo.AssertNotCalled(t, "RefundTicket", mock.Anything, mock.Anything, mock.Anything)
+
+	status, _ := processor.Status(result.Reference)
+	assert.Equal(t, entities.PaymentStatusCaptured, status)
+}
+
+func TestCancellationsServiceProcessRefunds(t *testing.T) {
+	ctx := context.Background()
+
+	processor := payment.NewFakeProcessor(nil, 0)
+	result, _ := processor.Authorize(ctx, money.New(20, "USD"), payment.Card{})
+	processor.Capture(ctx, result.Reference)
+
+	refunded := entities.CancellationRefund{ID: 1, CancellationID: 2, TicketID: 10, Status: "pending"}
+	skipped := entities.CancellationRefund{ID: 2, CancellationID: 2, TicketID: 11, Status: "pending"}
+	purchase := entities.TicketPurchase{
+		TicketID:         10,
+		PurchaserID:      21,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: result.Reference,
+	}
+
+	mockRepo := new(MockCancellationsRepo)
+	mockRepo.On("GetPendingCancellationRefunds", ctx, int32(10)).Return(
+		[]entities.CancellationRefund{refunded, skipped},
+		nil,
+	)
+	mockRepo.On("RefundCancelledTicket", ctx, refunded, purchase).Return(
+		entities.Refund{
+			ID:               5,
+			PaymentID:        3,
+			TicketID:         10,
+			Amount:           money.New(20, "USD"),
+			Status:           entities.RefundStatusPending,
+			PaymentReference: result.Reference,
+		},
+		nil,
+	)
+	mockRepo.On("UpdateCancellationRefund", ctx, mock.Anything).Return(nil)
+	mockRepo.On("CompleteEventCancellations", ctx).Return(int64(1), nil)
+
+	mockTicketsRepo := new(MockTicketsRepo)
+	mockTicketsRepo.On("GetTicketPurchase", ctx, int32(10)).Return(purchase, nil)
+	mockTicketsRepo.On("GetTicketPurchase", ctx, int32(11)).Return(entities.TicketPurchase{}, repos.ErrNoSuchEntity)
+	mockTicketsRepo.On("CompleteRefund", mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewCancellationsService(mockRepo, mockTicketsRepo, processor, 10, 3)
+	countProcessed, err := service.ProcessRefunds(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, countProcessed)
+
+	status, _ := processor.Status(result.Reference)
+	assert.Equal(t, entities.PaymentStatusRefunded, status)
+	mockRepo.AssertCalled(t, "UpdateCancellationRefund", ctx, entities.CancellationRefund{
+		ID:             2,
+		CancellationID: 2,
+		TicketID:       11,
+		Status:         "skipped",
+		Attempts:       1,
+	})
+	mockRepo.AssertCalled(t, "CompleteEventCancellations", ctx)
+	mockTicketsRepo.AssertNumberOfCalls(t, "CompleteRefund", 1)
+}
+
+func TestCancellationsServiceProcessRefundsWhenPaymentRefundFails(t *testing.T) {
+	ctx := context.Background()
+	cancellationRefund := entities.CancellationRefund{ID: 1, CancellationID: 2, TicketID: 10, Status: "pending"}
+	purchase := entities.TicketPurchase{
+		TicketID:         10,
+		PurchaserID:      21,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: "unknown",
+	}
+	pending := entities.Refund{
+		ID:               5,
+		PaymentID:        3,
+		TicketID:         10,
+		Amount:           money.New(20, "USD"),
+		Status:           entities.RefundStatusPending,
+		PaymentReference: "unknown",
+	}
+
+	mockRepo := new(MockCancellationsRepo)
+	mockRepo.On("GetPendingCancellationRefunds", ctx, int32(10)).Return(
+		[]entities.CancellationRefund{cancellationRefund},
+		nil,
+	)
+	mockRepo.On("RefundCancelledTicket", ctx, cancellationRefund, purchase).Return(pending, nil)
+	mockRepo.On("CompleteEventCancellations", ctx).Return(int64(1), nil)
+
+	mockTicketsRepo := new(MockTicketsRepo)
+	mockTicketsRepo.On("GetTicketPurchase", ctx, int32(10)).Return(purchase, nil)
+	mockTicketsRepo.On("FailRefund", mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewCancellationsService(mockRepo, mockTicketsRepo, payment.NewFakeProcessor(nil, 0), 10, 3)
+	countProcessed, err := service.ProcessRefunds(ctx)
+
+	// The ticket was refunded, but the payment processor won't make the
+	// refund, so the refund is recorded as failed.
+	assert.Nil(t, err)
+	assert.Equal(t, 1, countProcessed)
+
+	failed := pending
+	failed.Status = entities.RefundStatusFailed
+	mockTicketsRepo.AssertCalled(t, "FailRefund", mock.Anything, failed)
+	mockRepo.AssertNotCalled(t, "UpdateCancellationRefund", mock.Anything, mock.Anything)
+}
+
+func TestCancellationsServiceProcessRefundsWhenRefundFails(t *testing.T) {
+	ctx := context.Background()
+	cancellationRefund := entities.CancellationRefund{
+		ID:             1,
+		CancellationID: 2,
+		TicketID:       10,
+		Status:         "pending",
+		Attempts:       2,
+	}
+	purchase := entities.TicketPurchase{
+		TicketID:         10,
+		PurchaserID:      21,
+		Price:            money.New(20, "USD"),
+		PaymentID:        3,
+		PaymentReference: "unknown",
+	}
+
+	mockRepo := new(MockCancellationsRepo)
+	mockRepo.On("GetPendingCancellationRefunds", ctx, int32(10)).Return(
+		[]entities.CancellationRefund{cancellationRefund},
+		nil,
+	)
+	refundErr := errors.New("connection reset")
+	mockRepo.On("RefundCancelledTicket", ctx, cancellationRefund, purchase).Return(entities.Refund{}, refundErr)
+	mockRepo.On("UpdateCancellationRefund", ctx, mock.Anything).Return(nil)
+	mockRepo.On("CompleteEventCancellations", ctx).Return(int64(0), nil)
+
+	mockTicketsRepo := new(MockTicketsRepo)
+	mockTicketsRepo.On("GetTicketPurchase", ctx, int32(10)).Return(purchase, nil)
+
+	service := services.NewCancellationsService(mockRepo, mockTicketsRepo, payment.NewFakeProcessor(nil, 0), 10, 3)
+	countProcessed, err := service.ProcessRefunds(ctx)
+
+	assert.ErrorIs(t, err, refundErr)
+	assert.Equal(t, 0, countProcessed)
+
+	// The last attempt failed, so the refund is given up on.
+	mockRepo.AssertCalled(t, "UpdateCancellationRefund", ctx, entities.CancellationRefund{
+		ID:             1,
+		CancellationID: 2,
+		TicketID:       10,
+		Status:         "failed",
+		Attempts:       3,
+		LastError:      refundErr.Error(),
+	})
+}
+
+type MockIdempotencyRepo struct {
+	mock.Mock
+}
+
+func (mock *MockIdempotencyRepo) ClaimIdempotencyKey(
+	ctx context.Context,
+	key entities.IdempotencyKey,
+	expiresAt time.Time,
+) (int32, bool, error) {
+	args := mock.Called(ctx, key)
+	return args.Get(0).(int32), args.Bool(1), args.Error(2)
+}
+
+func (mock *MockIdempotencyRepo) GetIdempotencyKey(ctx context.Context, userID string, key string) (entities.IdempotencyKey, error) {
+	args := mock.Called(ctx, userID, key)
+	return args.Get(0).(entities.IdempotencyKey), args.Error(1)
+}
+
+func (mock *MockIdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, key entities.IdempotencyKey) error {
+	args := mock.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (mock *MockIdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, id int32) error {
+	args := mock.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (mock *MockIdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	args := mock.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyServiceBegin(t *testing.T) {
+	ctx := context.Background()
+	key := entities.IdempotencyKey{UserID: "11", Key: "abc", Fingerprint: "def"}
+
+	mockRepo := new(MockIdempotencyRepo)
+	mockRepo.On("ClaimIdempotencyKey", ctx, key).Return(int32(1), true, nil)
+
+	service := services.NewIdempotencyService(mockRepo, time.Hour)
+	record, replay, err := service.Begin(ctx, "11", "abc", "def")
+
+	assert.Nil(t, err)
+	assert.False(t, replay)
+	assert.Equal(t, int32(1), record.ID)
+	mockRepo.AssertNotCalled(t, "GetIdempotencyKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyServiceBeginWhenCompleted(t *testing.T) {
+	ctx := context.Background()
+	existing := entities.IdempotencyKey{
+		ID:           1,
+		UserID:       "11",
+		Key:          "abc",
+		Fingerprint:  "def",
+		StatusCode:   200,
+		ResponseBody: []byte(`{}`),
+	}
+
+	mockRepo := new(MockIdempotencyRepo)
+	mockRepo.On("ClaimIdempotencyKey", ctx, mock.Anything).Return(int32(0), false, nil)
+	mockRepo.On("GetIdempotencyKey", ctx, "11", "abc").Return(existing, nil)
+
+	service := services.NewIdempotencyService(mockRepo, time.Hour)
+	record, replay, err := service.Begin(ctx, "11", "abc", "def")
+
+	assert.Nil(t, err)
+	assert.True(t, replay)
+	assert.Equal(t, existing, record)
+}
+
+func TestIdempotencyServiceBeginWhenFingerprintDiffers(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(MockIdempotencyRepo)
+	mockRepo.On("ClaimIdempotencyKey", ctx, mock.Anything).Return(int32(0), false, nil)
+	mockRepo.On("GetIdempotencyKey", ctx, "11", "abc").Return(
+		entities.IdempotencyKey{ID: 1, Fingerprint: "other", StatusCode: 200},
+		nil,
+	)
+
+	service := services.NewIdempotencyService(mockRepo, time.Hour)
+	_, _, err := service.Begin(ctx, "11", "abc", "def")
+
+	assert.ErrorIs(t, err, services.ErrIdempotencyKeyMismatch)
+}
+
+func TestIdempotencyServiceBeginWhenInProgress(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(MockIdempotencyRepo)
+	mockRepo.On("ClaimIdempotencyKey", ctx, mock.Anything).Return(int32(0), false, nil)
+	mockRepo.On("GetIdempotencyKey", ctx, "11", "abc").Return(
+		entities.IdempotencyKey{ID: 1, Fingerprint: "def"},
+		nil,
+	)
+
+	service := services.NewIdempotencyService(mockRepo, time.Hour)
+	_, _, err := service.Begin(ctx, "11", "abc", "def")
+
+	assert.ErrorIs(t, err, services.ErrIdempotencyKeyInProgress)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+)
+
+// CreateTicketType creates a new ticket type for the type's event and returns
+// the new entity's id. A type must be priced in the same currency as the rest
+// of the event's tickets, and be uniquely named for the event.
+func (svc *TicketsService) CreateTicketType(ctx context.Context, ticketType entities.TicketType) (int32, error) {
+	if err := ticketType.Price.Validate(); err != nil {
+		return 0, err
+	}
+
+	currencies, err := svc.repo.GetEventCurrencies(ctx, ticketType.EventID)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range currencies {
+		if existing != ticketType.Price.Currency {
+			return 0, money.ErrCurrencyMismatch
+		}
+	}
+
+	ticketTypes, err := svc.repo.GetEventTicketTypes(ctx, ticketType.EventID)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range ticketTypes {
+		if existing.Name == ticketType.Name {
+			return 0, ErrTicketTypeExists
+		}
+	}
+
+	return svc.repo.CreateTicketType(ctx, ticketType)
+}
+
+// GetTicketTypes fetches the ticket types of the event given by the event id.
+func (svc *TicketsService) GetTicketTypes(ctx context.Context, eventID int32) ([]entities.TicketType, error) {
+	return svc.repo.GetEventTicketTypes(ctx, eventID)
+}
+
+// priceTicketTypes prices each of the tickets that is of a ticket type at its
+// type's price, in place. A ticket of a type mustn't be given a price of its
+// own, and a ticket that isn't must be.
+func (svc *TicketsService) priceTicketTypes(
+	ctx context.Context,
+	eventID int32,
+	tickets []entities.Ticket,
+) error {
+	hasTicketTypes := false
+	for _, ticket := range tickets {
+		if ticket.TicketTypeID == 0 {
+			if ticket.Price == (money.Money{}) {
+				return ErrMissingPrice
+			}
+			continue
+		}
+		if ticket.Price != (money.Money{}) {
+			return ErrTicketTypePriced
+		}
+		hasTicketTypes = true
+	}
+	if !hasTicketTypes {
+		return nil
+	}
+
+	ticketTypes, err := svc.repo.GetEventTicketTypes(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	prices := make(map[int32]money.Money, len(ticketTypes))
+	for _, ticketType := range ticketTypes {
+		prices[ticketType.ID] = ticketType.Price
+	}
+
+	for idx, ticket := range tickets {
+		if ticket.TicketTypeID == 0 {
+			continue
+		}
+		price, ok := prices[ticket.TicketTypeID]
+		if !ok {
+			return ErrNoSuchTicketType
+		}
+		tickets[idx].Price = price
+	}
+	return nil
+}
+
+// getTicketTypesByID fetches the ticket types given by id, skipping the ids of
+// tickets that aren't of a type.
+func (svc *TicketsService) getTicketTypesByID(
+	ctx context.Context,
+	ticketTypeIDs []int32,
+) ([]entities.TicketType, error) {
+	ticketTypeIDs = slices.DeleteFunc(slices.Clone(ticketTypeIDs), func(id int32) bool {
+		return id == 0
+	})
+	slices.Sort(ticketTypeIDs)
+	ticketTypeIDs = slices.Compact(ticketTypeIDs)
+	if len(ticketTypeIDs) == 0 {
+		return []entities.TicketType{}, nil
+	}
+	return svc.repo.GetTicketTypes(ctx, ticketTypeIDs)
+}
+
+// checkOnSale checks that each of the tickets that is of a ticket type is on
+// sale, as of now, and that each of the tickets that is part of a release is
+// either on sale or accessible by `access`.
+func (svc *TicketsService) checkOnSale(
+	ctx context.Context,
+	tickets []entities.Ticket,
+	access entities.SaleAccess,
+) error {
+	ticketTypeIDs := make([]int32, len(tickets))
+	for idx, ticket := range tickets {
+		ticketTypeIDs[idx] = ticket.TicketTypeID
+	}
+
+	ticketTypes, err := svc.getTicketTypesByID(ctx, ticketTypeIDs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, ticketType := range ticketTypes {
+		if !ticketType.IsOnSale(now) {
+			return ErrTicketTypeNotOnSale
+		}
+	}
+	return svc.checkReleasesOnSale(ctx, tickets, access, now)
+}
@@ -0,0 +1,146 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTicketsServiceCreateTicketTypeWhenNameTaken(t *testing.T) {
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventCurrencies", mock.Anything, int32(1)).Return([]string{"USD"}, nil)
+	mockRepo.On("GetEventTicketTypes", mock.Anything, int32(1)).Return(
+		[]entities.TicketType{{ID: 2, EventID: 1, Name: "VIP"}},
+		nil,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	ticketType := entities.TicketType{EventID: 1, Name: "VIP", Price: money.New(5000, "USD")}
+	_, err := service.CreateTicketType(context.Background(), ticketType)
+
+	assert.ErrorIs(t, err, services.ErrTicketTypeExists)
+	mockRepo.AssertNotCalled(t, "CreateTicketType", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceAddTicketsWithTicketTypes(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{
+		{EventID: 1, Seat: "Orchestra", TicketTypeID: 2},
+		{EventID: 1, Seat: "Orchestra", TicketTypeID: 3},
+		{EventID: 1, Price: money.New(1000, "USD"), Seat: "Balcony"},
+	}
+	expected := []entities.Ticket{
+		{EventID: 1, Price: money.New(5000, "USD"), Seat: "Orchestra", TicketTypeID: 2},
+		{EventID: 1, Price: money.New(2000, "USD"), Seat: "Orchestra", TicketTypeID: 3},
+		{EventID: 1, Price: money.New(1000, "USD"), Seat: "Balcony"},
+	}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventTicketTypes", ctx, int32(1)).Return(
+		[]entities.TicketType{
+			{ID: 2, EventID: 1, Name: "VIP", Price: money.New(5000, "USD")},
+			{ID: 3, EventID: 1, Name: "Student", Price: money.New(2000, "USD")},
+		},
+		nil,
+	)
+	mockRepo.On("GetEventCurrencies", ctx, int32(1)).Return([]string{"USD"}, nil)
+	mockRepo.On("WriteTickets", ctx, expected).Return(nil)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.Nil(t, err)
+	mockRepo.AssertCalled(t, "WriteTickets", ctx, expected)
+	assert.Equal(t, money.Money{}, tickets[0].Price)
+}
+
+func TestTicketsServiceAddTicketsWhenTicketTypeNotEvents(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{{EventID: 1, Seat: "Orchestra", TicketTypeID: 4}}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetEventTicketTypes", ctx, int32(1)).Return(
+		[]entities.TicketType{{ID: 2, EventID: 1, Name: "VIP", Price: money.New(5000, "USD")}},
+		nil,
+	)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.ErrorIs(t, err, services.ErrNoSuchTicketType)
+	mockRepo.AssertNotCalled(t, "WriteTickets", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceAddTicketsWhenTicketTypeAndPriceGiven(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{
+		{EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra", TicketTypeID: 2},
+	}
+
+	mockRepo := new(MockTicketsRepo)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.ErrorIs(t, err, services.ErrTicketTypePriced)
+	mockRepo.AssertNotCalled(t, "WriteTickets", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceAddTicketsWhenPriceMissing(t *testing.T) {
+	ctx := context.Background()
+	tickets := []entities.Ticket{{EventID: 1, Seat: "Orchestra"}}
+
+	mockRepo := new(MockTicketsRepo)
+
+	service := services.NewTicketsService(mockRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	err := service.AddTickets(ctx, 1, tickets)
+
+	assert.ErrorIs(t, err, services.ErrMissingPrice)
+	mockRepo.AssertNotCalled(t, "WriteTickets", mock.Anything, mock.Anything)
+}
+
+func TestTicketsServiceAggregateTicketsByTicketType(t *testing.T) {
+	tickets := []entities.Ticket{
+		{ID: 1, Price: money.New(5000, "USD"), Seat: "Orchestra", TicketTypeID: 2},
+		{ID: 2, Price: money.New(2000, "USD"), Seat: "Orchestra", TicketTypeID: 3},
+		{ID: 3, Price: money.New(5000, "USD"), Seat: "Orchestra", TicketTypeID: 2},
+	}
+	expected := []entities.AvailableTicketAggregate{
+		{Price: money.New(5000, "USD"), Seat: "Orchestra", TicketTypeID: 2, IDs: []int32{1, 3}},
+		{Price: money.New(2000, "USD"), Seat: "Orchestra", TicketTypeID: 3, IDs: []int32{2}},
+	}
+
+	service := services.NewTicketsService(nil, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	actual := service.AggregateTickets(tickets)
+
+	assert.ElementsMatch(t, expected, actual)
+}
+
+func TestTicketsServiceSetTicketsHoldWhenTicketTypeNotOnSale(t *testing.T) {
+	ticketIDs := []int32{1, 2}
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetTickets", mock.Anything, ticketIDs).Return(
+		[]entities.Ticket{{ID: 1, TicketTypeID: 3}, {ID: 2, TicketTypeID: 3}},
+		nil,
+	)
+	mockRepo.On("GetTicketTypes", mock.Anything, []int32{3}).Return(
+		[]entities.TicketType{{ID: 3, Name: "Early Bird", SaleEndsAt: time.Now().Add(-time.Hour)}},
+		nil,
+	)
+
+	mockClient := new(MockCacheClient)
+
+	service := services.NewTicketsService(mockRepo, nil, mockClient, payment.NewFakeProcessor(nil, 0), nil, nil, nil, time.Minute, 1)
+	_, err := service.SetTicketsHold(context.Background(), ticketIDs, "123", entities.SaleAccess{})
+
+	assert.ErrorIs(t, err, services.ErrTicketTypeNotOnSale)
+	mockClient.AssertNotCalled(t, "SetMany", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+)
+
+// TransfersRepoer provides necessary methods for database operations against
+// ticket transfers.
+type TransfersRepoer interface {
+	GetTicketOwnership(context.Context, int32) (entities.TicketOwnership, error)
+	CreateTicketTransfer(context.Context, entities.TicketTransfer) (entities.TicketTransfer, error)
+	GetTicketTransfer(context.Context, int32) (entities.TicketTransfer, error)
+	GetPendingTicketTransfer(context.Context, int32) (entities.TicketTransfer, error)
+	GetTicketTransfers(context.Context, int32) ([]entities.TicketTransfer, error)
+	GetUserTicketTransfers(context.Context, int32) ([]entities.TicketTransfer, error)
+	CancelTicketTransfer(context.Context, int32) (entities.TicketTransfer, error)
+	AcceptTicketTransfer(context.Context, entities.TicketTransfer) (entities.TicketTransfer, error)
+}
+
+// TransfersService transfers purchased tickets between users. A transfer is
+// pending until its recipient accepts it, and can be cancelled by its sender
+// until then. Tickets can only be transferred until their event starts.
+type TransfersService struct {
+	repo TransfersRepoer
+}
+
+func NewTransfersService(repo TransfersRepoer) *TransfersService {
+	return &TransfersService{repo: repo}
+}
+
+// checkTransferable checks that the ticket given by id is owned by the user
+// given by `ownerID`, and that its event hasn't started.
+func (svc *TransfersService) checkTransferable(ctx context.Context, ticketID int32, ownerID int32) error {
+	ownership, err := svc.repo.GetTicketOwnership(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	if ownership.OwnerID == 0 || ownership.OwnerID != ownerID {
+		return ErrNotTicketOwner
+	}
+	if ownership.HasEventStarted(time.Now()) {
+		return ErrEventStarted
+	}
+	return nil
+}
+
+// CreateTransfer creates a pending transfer of the transfer's ticket, from its
+// sender, who must own the ticket, to its recipient. A ticket can only have
+// one pending transfer at a time.
+func (svc *TransfersService) CreateTransfer(
+	ctx context.Context,
+	transfer entities.TicketTransfer,
+) (entities.TicketTransfer, error) {
+	if transfer.SenderID == transfer.RecipientID {
+		return entities.TicketTransfer{}, ErrTransferToSelf
+	}
+
+	if err := svc.checkTransferable(ctx, transfer.TicketID, transfer.SenderID); err != nil {
+		return entities.TicketTransfer{}, err
+	}
+
+	_, err := svc.repo.GetPendingTicketTransfer(ctx, transfer.TicketID)
+	if err == nil {
+		return entities.TicketTransfer{}, ErrTransferPending
+	}
+	if !errors.Is(err, repos.ErrNoSuchEntity) {
+		return entities.TicketTransfer{}, err
+	}
+
+	return svc.repo.CreateTicketTransfer(ctx, transfer)
+}
+
+// GetTransfer fetches the transfer given by id.
+func (svc *TransfersService) GetTransfer(ctx context.Context, id int32) (entities.TicketTransfer, error) {
+	return svc.repo.GetTicketTransfer(ctx, id)
+}
+
+// GetTicketTransfers fetches the transfer history of the ticket given by id,
+// most recent first.
+func (svc *TransfersService) GetTicketTransfers(ctx context.Context, ticketID int32) ([]entities.TicketTransfer, error) {
+	return svc.repo.GetTicketTransfers(ctx, ticketID)
+}
+
+// GetUserTransfers fetches the transfers sent or received by the user given by
+// id, most recent first.
+func (svc *TransfersService) GetUserTransfers(ctx context.Context, userID int32) ([]entities.TicketTransfer, error) {
+	return svc.repo.GetUserTicketTransfers(ctx, userID)
+}
+
+// getPendingTransfer fetches the transfer given by id, checking that it's
+// still pending.
+func (svc *TransfersService) getPendingTransfer(ctx context.Context, id int32) (entities.TicketTransfer, error) {
+	transfer, err := svc.repo.GetTicketTransfer(ctx, id)
+	if err != nil {
+		return entities.TicketTransfer{}, err
+	}
+	if transfer.Status != entities.TransferStatusPending {
+		return entities.TicketTransfer{}, ErrTransferNotPending
+	}
+	return transfer, nil
+}
+
+// CancelTransfer cancels the pending transfer given by id, which can only be
+// done by its sender, given by `userID`.
+func (svc *TransfersService) CancelTransfer(
+	ctx context.Context,
+	id int32,
+	userID int32,
+) (entities.TicketTransfer, error) {
+	transfer, err := svc.getPendingTransfer(ctx, id)
+	if err != nil {
+		return entities.TicketTransfer{}, err
+	}
+	if transfer.SenderID != userID {
+		return entities.TicketTransfer{}, ErrNotTransferSender
+	}
+
+	cancelled, err := svc.repo.CancelTicketTransfer(ctx, id)
+	if errors.Is(err, repos.ErrNoSuchEntity) {
+		// The transfer was accepted or cancelled in the meantime.
+		return entities.TicketTransfer{}, ErrTransferNotPending
+	}
+	return cancelled, err
+}
+
+// AcceptTransfer accepts the pending transfer given by id, which can only be
+// done by its recipient, given by `userID`, moving the transfer's ticket to
+// the recipient. A transfer counts against the event's limit on tickets per
+// user, as a purchase does.
+func (svc *TransfersService) AcceptTransfer(
+	ctx context.Context,
+	id int32,
+	userID int32,
+) (entities.TicketTransfer, error) {
+	transfer, err := svc.getPendingTransfer(ctx, id)
+	if err != nil {
+		return entities.TicketTransfer{}, err
+	}
+	if transfer.RecipientID != userID {
+		return entities.TicketTransfer{}, ErrNotTransferRecipient
+	}
+
+	err = svc.checkTransferable(ctx, transfer.TicketID, transfer.SenderID)
+	if err != nil {
+		if errors.Is(err, ErrNotTicketOwner) || errors.Is(err, repos.ErrNoSuchEntity) {
+			// The sender has since had the ticket refunded.
+			return entities.TicketTransfer{}, ErrSenderNotOwner
+		}
+		return entities.TicketTransfer{}, err
+	}
+
+	accepted, err := svc.repo.AcceptTicketTransfer(ctx, transfer)
+	if errors.Is(err, repos.ErrNoSuchEntity) {
+		// The transfer was cancelled, or the ticket refunded, in the meantime.
+		return entities.TicketTransfer{}, ErrTransferNotPending
+	}
+	if errors.Is(err, repos.ErrPurchaseLimitExceeded) {
+		return entities.TicketTransfer{}, ErrPurchaseLimitExceeded
+	}
+	return accepted, err
+}
@@ -0,0 +1,290 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransfersRepo struct {
+	mock.Mock
+}
+
+func (mock *MockTransfersRepo) GetTicketOwnership(ctx context.Context, ticketID int32) (entities.TicketOwnership, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(entities.TicketOwnership), args.Error(1)
+}
+
+func (mock *MockTransfersRepo) CreateTicketTransfer(
+	ctx context.Context,
+	transfer entities.TicketTransfer,
+) (entities.TicketTransfer, error) {
+	args := mock.Called(ctx, transfer)
+	return args.Get(0).(entities.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockTransfersRepo) GetTicketTransfer(ctx context.Context, id int32) (entities.TicketTransfer, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(entities.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockTransfersRepo) GetPendingTicketTransfer(ctx context.Context, ticketID int32) (entities.TicketTransfer, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).(entities.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockTransfersRepo) GetTicketTransfers(ctx context.Context, ticketID int32) ([]entities.TicketTransfer, error) {
+	args := mock.Called(ctx, ticketID)
+	return args.Get(0).([]entities.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockTransfersRepo) GetUserTicketTransfers(ctx context.Context, userID int32) ([]entities.TicketTransfer, error) {
+	args := mock.Called(ctx, userID)
+	return args.Get(0).([]entities.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockTransfersRepo) CancelTicketTransfer(ctx context.Context, id int32) (entities.TicketTransfer, error) {
+	args := mock.Called(ctx, id)
+	return args.Get(0).(entities.TicketTransfer), args.Error(1)
+}
+
+func (mock *MockTransfersRepo) AcceptTicketTransfer(
+	ctx context.Context,
+	transfer entities.TicketTransfer,
+) (entities.TicketTransfer, error) {
+	args := mock.Called(ctx, transfer)
+	return args.Get(0).(entities.TicketTransfer), args.Error(1)
+}
+
+func TestTransfersServiceCreateTransfer(t *testing.T) {
+	transfer := entities.TicketTransfer{TicketID: 1, SenderID: 2, RecipientID: 3}
+	created := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+
+	mockRepo := new(MockTransfersRepo)
+	mockRepo.On("GetTicketOwnership", mock.Anything, int32(1)).Return(
+		entities.TicketOwnership{TicketID: 1, OwnerID: 2, EventStartsAt: time.Now().Add(time.Hour)},
+		nil,
+	)
+	mockRepo.On("GetPendingTicketTransfer", mock.Anything, int32(1)).Return(entities.TicketTransfer{}, repos.ErrNoSuchEntity)
+	mockRepo.On("CreateTicketTransfer", mock.Anything, transfer).Return(created, nil)
+
+	service := services.NewTransfersService(mockRepo)
+	actual, err := service.CreateTransfer(context.Background(), transfer)
+
+	assert.Nil(t, err)
+	assert.Equal(t, created, actual)
+}
+
+func TestTransfersServiceCreateTransferWhenNotTransferable(t *testing.T) {
+	type testCase struct {
+		Name        string
+		Transfer    entities.TicketTransfer
+		Ownership   entities.TicketOwnership
+		ExpectedErr error
+	}
+
+	startsAt := time.Now().Add(time.Hour)
+	testCases := []testCase{
+		{
+			Name:        "ToSelf",
+			Transfer:    entities.TicketTransfer{TicketID: 1, SenderID: 2, RecipientID: 2},
+			Ownership:   entities.TicketOwnership{TicketID: 1, OwnerID: 2, EventStartsAt: startsAt},
+			ExpectedErr: services.ErrTransferToSelf,
+		},
+		{
+			Name:        "NotOwner",
+			Transfer:    entities.TicketTransfer{TicketID: 1, SenderID: 2, RecipientID: 3},
+			Ownership:   entities.TicketOwnership{TicketID: 1, OwnerID: 5, EventStartsAt: startsAt},
+			ExpectedErr: services.ErrNotTicketOwner,
+		},
+		{
+			Name:        "NotPurchased",
+			Transfer:    entities.TicketTransfer{TicketID: 1, SenderID: 2, RecipientID: 3},
+			Ownership:   entities.TicketOwnership{TicketID: 1, EventStartsAt: startsAt},
+			ExpectedErr: services.ErrNotTicketOwner,
+		},
+		{
+			Name:        "EventStarted",
+			Transfer:    entities.TicketTransfer{TicketID: 1, SenderID: 2, RecipientID: 3},
+			Ownership:   entities.TicketOwnership{TicketID: 1, OwnerID: 2, EventStartsAt: time.Now().Add(-time.Minute)},
+			ExpectedErr: services.ErrEventStarted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := new(MockTransfersRepo)
+			mockRepo.On("GetTicketOwnership", mock.Anything, int32(1)).Return(tc.Ownership, nil)
+
+			service := services.NewTransfersService(mockRepo)
+			_, err := service.CreateTransfer(context.Background(), tc.Transfer)
+
+			assert.ErrorIs(t, err, tc.ExpectedErr)
+			mockRepo.AssertNotCalled(t, "CreateTicketTransfer", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransfersServiceCreateTransferWhenPending(t *testing.T) {
+	transfer := entities.TicketTransfer{TicketID: 1, SenderID: 2, RecipientID: 3}
+
+	mockRepo := new(MockTransfersRepo)
+	mockRepo.On("GetTicketOwnership", mock.Anything, int32(1)).Return(
+		entities.TicketOwnership{TicketID: 1, OwnerID: 2, EventStartsAt: time.Now().Add(time.Hour)},
+		nil,
+	)
+	mockRepo.On("GetPendingTicketTransfer", mock.Anything, int32(1)).Return(
+		entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 5, Status: "pending"},
+		nil,
+	)
+
+	service := services.NewTransfersService(mockRepo)
+	_, err := service.CreateTransfer(context.Background(), transfer)
+
+	assert.ErrorIs(t, err, services.ErrTransferPending)
+	mockRepo.AssertNotCalled(t, "CreateTicketTransfer", mock.Anything, mock.Anything)
+}
+
+func TestTransfersServiceCancelTransfer(t *testing.T) {
+	type testCase struct {
+		Name        string
+		UserID      int32
+		Transfer    entities.TicketTransfer
+		ExpectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			Name:     "Sender",
+			UserID:   2,
+			Transfer: entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"},
+		},
+		{
+			Name:        "Recipient",
+			UserID:      3,
+			Transfer:    entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"},
+			ExpectedErr: services.ErrNotTransferSender,
+		},
+		{
+			Name:        "Accepted",
+			UserID:      2,
+			Transfer:    entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "accepted"},
+			ExpectedErr: services.ErrTransferNotPending,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cancelled := tc.Transfer
+			cancelled.Status = entities.TransferStatusCancelled
+
+			mockRepo := new(MockTransfersRepo)
+			mockRepo.On("GetTicketTransfer", mock.Anything, int32(4)).Return(tc.Transfer, nil)
+			mockRepo.On("CancelTicketTransfer", mock.Anything, int32(4)).Return(cancelled, nil)
+
+			service := services.NewTransfersService(mockRepo)
+			actual, err := service.CancelTransfer(context.Background(), 4, tc.UserID)
+
+			if tc.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tc.ExpectedErr)
+				mockRepo.AssertNotCalled(t, "CancelTicketTransfer", mock.Anything, mock.Anything)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, entities.TransferStatusCancelled, actual.Status)
+		})
+	}
+}
+
+func TestTransfersServiceAcceptTransfer(t *testing.T) {
+	transfer := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+	accepted := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "accepted"}
+
+	mockRepo := new(MockTransfersRepo)
+	mockRepo.On("GetTicketTransfer", mock.Anything, int32(4)).Return(transfer, nil)
+	mockRepo.On("GetTicketOwnership", mock.Anything, int32(1)).Return(
+		entities.TicketOwnership{TicketID: 1, OwnerID: 2, EventStartsAt: time.Now().Add(time.Hour)},
+		nil,
+	)
+	mockRepo.On("AcceptTicketTransfer", mock.Anything, transfer).Return(accepted, nil)
+
+	service := services.NewTransfersService(mockRepo)
+	actual, err := service.AcceptTransfer(context.Background(), 4, 3)
+
+	assert.Nil(t, err)
+	assert.Equal(t, accepted, actual)
+}
+
+func TestTransfersServiceAcceptTransferWhenPurchaseLimitExceeded(t *testing.T) {
+	transfer := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+
+	mockRepo := new(MockTransfersRepo)
+	mockRepo.On("GetTicketTransfer", mock.Anything, int32(4)).Return(transfer, nil)
+	mockRepo.On("GetTicketOwnership", mock.Anything, int32(1)).Return(
+		entities.TicketOwnership{TicketID: 1, OwnerID: 2, EventStartsAt: time.Now().Add(time.Hour)},
+		nil,
+	)
+	mockRepo.On("AcceptTicketTransfer", mock.Anything, transfer).Return(
+		entities.TicketTransfer{},
+		repos.ErrPurchaseLimitExceeded,
+	)
+
+	service := services.NewTransfersService(mockRepo)
+	_, err := service.AcceptTransfer(context.Background(), 4, 3)
+
+	assert.ErrorIs(t, err, services.ErrPurchaseLimitExceeded)
+}
+
+func TestTransfersServiceAcceptTransferWhenNotRecipient(t *testing.T) {
+	transfer := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+
+	mockRepo := new(MockTransfersRepo)
+	mockRepo.On("GetTicketTransfer", mock.Anything, int32(4)).Return(transfer, nil)
+
+	service := services.NewTransfersService(mockRepo)
+	_, err := service.AcceptTransfer(context.Background(), 4, 5)
+
+	assert.ErrorIs(t, err, services.ErrNotTransferRecipient)
+	mockRepo.AssertNotCalled(t, "AcceptTicketTransfer", mock.Anything, mock.Anything)
+}
+
+func TestTransfersServiceAcceptTransferWhenSenderNoLongerOwner(t *testing.T) {
+	transfer := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+
+	mockRepo := new(MockTransfersRepo)
+	mockRepo.On("GetTicketTransfer", mock.Anything, int32(4)).Return(transfer, nil)
+	// The sender had the ticket refunded, and it was re-released.
+	mockRepo.On("GetTicketOwnership", mock.Anything, int32(1)).Return(
+		entities.TicketOwnership{TicketID: 1, EventStartsAt: time.Now().Add(time.Hour)},
+		nil,
+	)
+
+	service := services.NewTransfersService(mockRepo)
+	_, err := service.AcceptTransfer(context.Background(), 4, 3)
+
+	assert.ErrorIs(t, err, services.ErrSenderNotOwner)
+	mockRepo.AssertNotCalled(t, "AcceptTicketTransfer", mock.Anything, mock.Anything)
+}
+
+func TestTransfersServiceAcceptTransferWhenEventStarted(t *testing.T) {
+	transfer := entities.TicketTransfer{ID: 4, TicketID: 1, SenderID: 2, RecipientID: 3, Status: "pending"}
+
+	mockRepo := new(MockTransfersRepo)
+	mockRepo.On("GetTicketTransfer", mock.Anything, int32(4)).Return(transfer, nil)
+	mockRepo.On("GetTicketOwnership", mock.Anything, int32(1)).Return(
+		entities.TicketOwnership{TicketID: 1, OwnerID: 2, EventStartsAt: time.Now().Add(-time.Minute)},
+		nil,
+	)
+
+	service := services.NewTransfersService(mockRepo)
+	_, err := service.AcceptTransfer(context.Background(), 4, 3)
+
+	assert.ErrorIs(t, err, services.ErrEventStarted)
+	mockRepo.AssertNotCalled(t, "AcceptTicketTransfer", mock.Anything, mock.Anything)
+}
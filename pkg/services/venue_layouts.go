@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+)
+
+// VenueLayoutsRepoer provides necessary methods for database operations
+// against venues' seating layouts.
+type VenueLayoutsRepoer interface {
+	GetVenueLayout(context.Context, int32) (entities.VenueLayout, error)
+	SetVenueLayout(context.Context, entities.VenueLayout) error
+	DeleteVenueLayout(context.Context, int32) error
+}
+
+type VenueLayoutsService struct {
+	repo VenueLayoutsRepoer
+}
+
+func NewVenueLayoutsService(repo VenueLayoutsRepoer) *VenueLayoutsService {
+	return &VenueLayoutsService{repo: repo}
+}
+
+// GetVenueLayout fetches the seating layout of the venue given by the id.
+func (svc *VenueLayoutsService) GetVenueLayout(ctx context.Context, venueID int32) (entities.VenueLayout, error) {
+	return svc.repo.GetVenueLayout(ctx, venueID)
+}
+
+// SetVenueLayout creates or replaces the seating layout of the layout's venue.
+func (svc *VenueLayoutsService) SetVenueLayout(ctx context.Context, layout entities.VenueLayout) error {
+	return svc.repo.SetVenueLayout(ctx, layout)
+}
+
+// DeleteVenueLayout removes the seating layout of the venue given by the id.
+func (svc *VenueLayoutsService) DeleteVenueLayout(ctx context.Context, venueID int32) error {
+	return svc.repo.DeleteVenueLayout(ctx, venueID)
+}
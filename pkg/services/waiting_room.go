@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+)
+
+// WaitingRoomsRepoer provides necessary methods for database operations
+// against waiting rooms.
+type WaitingRoomsRepoer interface {
+	GetWaitingRoom(context.Context, int32) (entities.WaitingRoom, error)
+	GetWaitingRooms(context.Context) ([]entities.WaitingRoom, error)
+	SetWaitingRoom(context.Context, entities.WaitingRoom) error
+	DeleteWaitingRoom(context.Context, int32) error
+}
+
+// admissionClaims are the claims of an admission token, which admits the user
+// to hold the event's tickets until the token expires.
+type admissionClaims struct {
+	EventID   int32  `json:"event_id"`
+	UserID    string `json:"user_id"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// WaitingRoomService queues users wanting to hold the tickets of events with a
+// waiting room, and admits a batch of each event's queue every
+// `AdmitInterval`. Admitted users are given an admission token, signed with
+// the service's secret, which is valid for `AdmissionDuration`.
+type WaitingRoomService struct {
+	repo              WaitingRoomsRepoer
+	queueClient       cache.QueueClienter
+	secret            []byte
+	AdmitInterval     time.Duration
+	AdmissionDuration time.Duration
+}
+
+func NewWaitingRoomService(
+	repo WaitingRoomsRepoer,
+	queueClient cache.QueueClienter,
+	secret []byte,
+	admitInterval time.Duration,
+	admissionDuration time.Duration,
+) *WaitingRoomService {
+	return &WaitingRoomService{
+		repo:              repo,
+		queueClient:       queueClient,
+		secret:            secret,
+		AdmitInterval:     admitInterval,
+		AdmissionDuration: admissionDuration,
+	}
+}
+
+// GetWaitingRoom fetches the waiting room of the event given by id.
+func (svc *WaitingRoomService) GetWaitingRoom(ctx context.Context, eventID int32) (entities.WaitingRoom, error) {
+	return svc.repo.GetWaitingRoom(ctx, eventID)
+}
+
+// SetWaitingRoom enables the waiting room for the room's event, or changes its
+// batch size if it's already enabled.
+func (svc *WaitingRoomService) SetWaitingRoom(ctx context.Context, room entities.WaitingRoom) error {
+	return svc.repo.SetWaitingRoom(ctx, room)
+}
+
+// DeleteWaitingRoom disables the waiting room of the event given by id.
+func (svc *WaitingRoomService) DeleteWaitingRoom(ctx context.Context, eventID int32) error {
+	return svc.repo.DeleteWaitingRoom(ctx, eventID)
+}
+
+// sign gives the signature of the payload, base64 encoded.
+func (svc *WaitingRoomService) sign(payload string) string {
+	mac := hmac.New(sha256.New, svc.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// IssueAdmissionToken creates a token admitting the user given by `userID` to
+// hold the tickets of the event given by `eventID`, until `expiresAt`.
+func (svc *WaitingRoomService) IssueAdmissionToken(eventID int32, userID string, expiresAt time.Time) (string, error) {
+	claims, err := json.Marshal(admissionClaims{
+		EventID:   eventID,
+		UserID:    userID,
+		ExpiresAt: expiresAt.UnixMilli(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+	return payload + "." + svc.sign(payload), nil
+}
+
+// VerifyAdmissionToken checks that the token was issued by the service, and
+// admits the user given by `userID` to the event given by `eventID` at the
+// given time.
+func (svc *WaitingRoomService) VerifyAdmissionToken(token string, eventID int32, userID string, at time.Time) error {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(svc.sign(payload))) {
+		return ErrInvalidAdmissionToken
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ErrInvalidAdmissionToken
+	}
+	var claims admissionClaims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return ErrInvalidAdmissionToken
+	}
+
+	if claims.EventID != eventID || claims.UserID != userID {
+		return ErrInvalidAdmissionToken
+	}
+	if !at.Before(time.UnixMilli(claims.ExpiresAt)) {
+		return ErrInvalidAdmissionToken
+	}
+	return nil
+}
+
+// CheckAdmission checks that the user given by `userID` has been admitted to
+// hold the tickets of the event given by `eventID`, by the admission token, if
+// the event has a waiting room. Events without a waiting room don't require
+// admission.
+func (svc *WaitingRoomService) CheckAdmission(ctx context.Context, eventID int32, userID string, token string) error {
+	_, err := svc.repo.GetWaitingRoom(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, repos.ErrNoSuchEntity) {
+			return nil
+		}
+		return err
+	}
+
+	if token == "" {
+		return ErrAdmissionRequired
+	}
+	return svc.VerifyAdmissionToken(token, eventID, userID, time.Now())
+}
+
+// makeQueuePosition gives the user's position in the event's queue, issuing
+// an admission token if they've been admitted.
+func (svc *WaitingRoomService) makeQueuePosition(
+	eventID int32,
+	userID string,
+	position int64,
+	admittedUntil time.Time,
+) (entities.QueuePosition, error) {
+	queuePosition := entities.QueuePosition{EventID: eventID, Position: position}
+	if position != 0 {
+		return queuePosition, nil
+	}
+
+	token, err := svc.IssueAdmissionToken(eventID, userID, admittedUntil)
+	if err != nil {
+		return entities.QueuePosition{}, err
+	}
+	queuePosition.AdmissionToken = token
+	queuePosition.ExpiresAt = admittedUntil
+	return queuePosition, nil
+}
+
+// JoinQueue adds the user given by `userID` to the back of the queue of the
+// event given by `eventID`, if they aren't already queued or admitted, and
+// gives their position.
+func (svc *WaitingRoomService) JoinQueue(ctx context.Context, eventID int32, userID string) (entities.QueuePosition, error) {
+	if userID == "" {
+		return entities.QueuePosition{}, ErrInvalidHoldID
+	}
+
+	if _, err := svc.repo.GetWaitingRoom(ctx, eventID); err != nil {
+		return entities.QueuePosition{}, err
+	}
+
+	key := svc.queueClient.MakeQueueKey(eventID)
+	position, admittedUntil, err := svc.queueClient.JoinQueue(ctx, key, userID)
+	if err != nil {
+		return entities.QueuePosition{}, err
+	}
+	return svc.makeQueuePosition(eventID, userID, position, admittedUntil)
+}
+
+// GetQueuePosition gives the position of the user given by `userID` in the
+// queue of the event given by `eventID`. Once the user has been admitted, they
+// are given an admission token.
+func (svc *WaitingRoomService) GetQueuePosition(
+	ctx context.Context,
+	eventID int32,
+	userID string,
+) (entities.QueuePosition, error) {
+	if userID == "" {
+		return entities.QueuePosition{}, ErrInvalidHoldID
+	}
+
+	key := svc.queueClient.MakeQueueKey(eventID)
+	position, admittedUntil, err := svc.queueClient.GetQueuePosition(ctx, key, userID)
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return entities.QueuePosition{}, ErrNotInQueue
+		}
+		return entities.QueuePosition{}, err
+	}
+	return svc.makeQueuePosition(eventID, userID, position, admittedUntil)
+}
+
+// AdmitBatches admits the next batch of users from the queue of each event
+// with a waiting room, and returns how many users were admitted in total.
+func (svc *WaitingRoomService) AdmitBatches(ctx context.Context) (int, error) {
+	rooms, err := svc.repo.GetWaitingRooms(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Batches are admitted at most once per interval, so the interval is
+	// shortened slightly for the next batch not to be skipped due to the
+	// ticker drifting.
+	interval := svc.AdmitInterval * 9 / 10
+
+	countAdmitted := 0
+	for _, room := range rooms {
+		key := svc.queueClient.MakeQueueKey(room.EventID)
+		count, err := svc.queueClient.AdmitBatch(
+			ctx,
+			key,
+			int(room.BatchSize),
+			svc.AdmissionDuration,
+			interval,
+		)
+		if err != nil {
+			return countAdmitted, err
+		}
+		countAdmitted += count
+	}
+	return countAdmitted, nil
+}
+
+func (svc *WaitingRoomService) Run(ctx context.Context) {
+	ticker := time.NewTicker(svc.AdmitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			countAdmitted, err := svc.AdmitBatches(ctx)
+			if err != nil {
+				slog.Error("Issue admitting users from waiting rooms", "error", err)
+			}
+			if countAdmitted > 0 {
+				slog.Info("Admitted users from waiting rooms", "count", countAdmitted)
+			}
+		}
+	}
+}
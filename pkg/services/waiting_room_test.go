@@ -0,0 +1,280 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWaitingRoomsRepo struct {
+	mock.Mock
+}
+
+func (mock *MockWaitingRoomsRepo) GetWaitingRoom(ctx context.Context, eventID int32) (entities.WaitingRoom, error) {
+	args := mock.Called(ctx, eventID)
+	return args.Get(0).(entities.WaitingRoom), args.Error(1)
+}
+
+func (mock *MockWaitingRoomsRepo) GetWaitingRooms(ctx context.Context) ([]entities.WaitingRoom, error) {
+	args := mock.Called(ctx)
+	return args.Get(0).([]entities.WaitingRoom), args.Error(1)
+}
+
+func (mock *MockWaitingRoomsRepo) SetWaitingRoom(ctx context.Context, room entities.WaitingRoom) error {
+	args := mock.Called(ctx, room)
+	return args.Error(0)
+}
+
+func (mock *MockWaitingRoomsRepo) DeleteWaitingRoom(ctx context.Context, eventID int32) error {
+	args := mock.Called(ctx, eventID)
+	return args.Error(0)
+}
+
+type MockQueueClient struct {
+	mock.Mock
+}
+
+func (mock *MockQueueClient) Close() error {
+	args := mock.Called()
+	return args.Error(0)
+}
+
+func (mock *MockQueueClient) JoinQueue(ctx context.Context, key string, member string) (int64, time.Time, error) {
+	args := mock.Called(ctx, key, member)
+	return args.Get(0).(int64), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (mock *MockQueueClient) GetQueuePosition(ctx context.Context, key string, member string) (int64, time.Time, error) {
+	args := mock.Called(ctx, key, member)
+	return args.Get(0).(int64), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (mock *MockQueueClient) AdmitBatch(
+	ctx context.Context,
+	key string,
+	batchSize int,
+	admission time.Duration,
+	interval time.Duration,
+) (int, error) {
+	args := mock.Called(ctx, key, batchSize, admission, interval)
+	return args.Int(0), args.Error(1)
+}
+
+func (mock *MockQueueClient) MakeQueueKey(eventID int32) string {
+	args := mock.Called(eventID)
+	return args.String(0)
+}
+
+func TestWaitingRoomServiceVerifyAdmissionToken(t *testing.T) {
+	now := time.Now()
+	service := services.NewWaitingRoomService(nil, nil, []byte("secret"), time.Second, time.Minute)
+	token, err := service.IssueAdmissionToken(1, "123", now.Add(time.Minute))
+	assert.Nil(t, err)
+
+	type testCase struct {
+		Name        string
+		Token       string
+		EventID     int32
+		UserID      string
+		At          time.Time
+		ExpectedErr error
+	}
+
+	testCases := []testCase{
+		{Name: "Valid", Token: token, EventID: 1, UserID: "123", At: now},
+		{Name: "OtherEvent", Token: token, EventID: 2, UserID: "123", At: now, ExpectedErr: services.ErrInvalidAdmissionToken},
+		{Name: "OtherUser", Token: token, EventID: 1, UserID: "456", At: now, ExpectedErr: services.ErrInvalidAdmissionToken},
+		{Name: "Expired", Token: token, EventID: 1, UserID: "123", At: now.Add(time.Hour), ExpectedErr: services.ErrInvalidAdmissionToken},
+		{Name: "Tampered", Token: token + "x", EventID: 1, UserID: "123", At: now, ExpectedErr: services.ErrInvalidAdmissionToken},
+		{Name: "Malformed", Token: "token", EventID: 1, UserID: "123", At: now, ExpectedErr: services.ErrInvalidAdmissionToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := service.VerifyAdmissionToken(tc.Token, tc.EventID, tc.UserID, tc.At)
+			assert.ErrorIs(t, err, tc.ExpectedErr)
+		})
+	}
+}
+
+func TestWaitingRoomServiceVerifyAdmissionTokenWithOtherSecret(t *testing.T) {
+	expiresAt := time.Now().Add(time.Minute)
+	other := services.NewWaitingRoomService(nil, nil, []byte("other"), time.Second, time.Minute)
+	token, _ := other.IssueAdmissionToken(1, "123", expiresAt)
+
+	service := services.NewWaitingRoomService(nil, nil, []byte("secret"), time.Second, time.Minute)
+	err := service.VerifyAdmissionToken(token, 1, "123", time.Now())
+
+	assert.ErrorIs(t, err, services.ErrInvalidAdmissionToken)
+}
+
+func TestWaitingRoomServiceJoinQueue(t *testing.T) {
+	mockRepo := new(MockWaitingRoomsRepo)
+	mockRepo.On("GetWaitingRoom", mock.Anything, int32(1)).Return(entities.WaitingRoom{EventID: 1, BatchSize: 10}, nil)
+
+	mockClient := new(MockQueueClient)
+	mockClient.On("MakeQueueKey", int32(1)).Return("queue:1")
+	mockClient.On("JoinQueue", mock.Anything, "queue:1", "123").Return(int64(42), time.Time{}, nil)
+
+	service := services.NewWaitingRoomService(mockRepo, mockClient, []byte("secret"), time.Second, time.Minute)
+	actual, err := service.JoinQueue(context.Background(), 1, "123")
+
+	assert.Nil(t, err)
+	assert.Equal(t, entities.QueuePosition{EventID: 1, Position: 42}, actual)
+	assert.False(t, actual.IsAdmitted())
+}
+
+func TestWaitingRoomServiceJoinQueueWhenNoWaitingRoom(t *testing.T) {
+	mockRepo := new(MockWaitingRoomsRepo)
+	mockRepo.On("GetWaitingRoom", mock.Anything, int32(1)).Return(entities.WaitingRoom{}, repos.ErrNoSuchEntity)
+
+	mockClient := new(MockQueueClient)
+
+	service := services.NewWaitingRoomService(mockRepo, mockClient, []byte("secret"), time.Second, time.Minute)
+	_, err := service.JoinQueue(context.Background(), 1, "123")
+
+	assert.ErrorIs(t, err, repos.ErrNoSuchEntity)
+	mockClient.AssertNotCalled(t, "JoinQueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWaitingRoomServiceGetQueuePositionWhenAdmitted(t *testing.T) {
+	admittedUntil := time.UnixMilli(time.Now().Add(time.Minute).UnixMilli())
+
+	mockClient := new(MockQueueClient)
+	mockClient.On("MakeQueueKey", int32(1)).Return("queue:1")
+	mockClient.On("GetQueuePosition", mock.Anything, "queue:1", "123").Return(int64(0), admittedUntil, nil)
+
+	service := services.NewWaitingRoomService(nil, mockClient, []byte("secret"), time.Second, time.Minute)
+	actual, err := service.GetQueuePosition(context.Background(), 1, "123")
+
+	assert.Nil(t, err)
+	assert.True(t, actual.IsAdmitted())
+	assert.Equal(t, admittedUntil, actual.ExpiresAt)
+	assert.Nil(t, service.VerifyAdmissionToken(actual.AdmissionToken, 1, "123", time.Now()))
+}
+
+func TestWaitingRoomServiceGetQueuePositionWhenNotQueued(t *testing.T) {
+	mockClient := new(MockQueueClient)
+	mockClient.On("MakeQueueKey", int32(1)).Return("queue:1")
+	mockClient.On("GetQueuePosition", mock.Anything, "queue:1", "123").Return(int64(0), time.Time{}, cache.ErrNotFound)
+
+	service := services.NewWaitingRoomService(nil, mockClient, []byte("secret"), time.Second, time.Minute)
+	_, err := service.GetQueuePosition(context.Background(), 1, "123")
+
+	assert.ErrorIs(t, err, services.ErrNotInQueue)
+}
+
+func TestWaitingRoomServiceAdmitBatches(t *testing.T) {
+	mockRepo := new(MockWaitingRoomsRepo)
+	mockRepo.On("GetWaitingRooms", mock.Anything).Return(
+		[]entities.WaitingRoom{{EventID: 1, BatchSize: 10}, {EventID: 2, BatchSize: 5}},
+		nil,
+	)
+
+	mockClient := new(MockQueueClient)
+	mockClient.On("MakeQueueKey", int32(1)).Return("queue:1")
+	mockClient.On("MakeQueueKey", int32(2)).Return("queue:2")
+	mockClient.On("AdmitBatch", mock.Anything, "queue:1", 10, time.Minute, mock.Anything).Return(10, nil)
+	mockClient.On("AdmitBatch", mock.Anything, "queue:2", 5, time.Minute, mock.Anything).Return(3, nil)
+
+	service := services.NewWaitingRoomService(mockRepo, mockClient, []byte("secret"), time.Second, time.Minute)
+	actual, err := service.AdmitBatches(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 13, actual)
+}
+
+func TestTicketsServiceSetTicketHoldWithWaitingRoom(t *testing.T) {
+	ticketID := int32(1)
+
+	mockRoomsRepo := new(MockWaitingRoomsRepo)
+	mockRoomsRepo.On("GetWaitingRoom", mock.Anything, int32(2)).Return(entities.WaitingRoom{EventID: 2, BatchSize: 10}, nil)
+	waitingRoomService := services.NewWaitingRoomService(mockRoomsRepo, nil, []byte("secret"), time.Second, time.Minute)
+	token, _ := waitingRoomService.IssueAdmissionToken(2, "123", time.Now().Add(time.Minute))
+
+	type testCase struct {
+		Name        string
+		Token       string
+		ExpectedErr error
+	}
+
+	testCases := []testCase{
+		{Name: "Admitted", Token: token},
+		{Name: "NoToken", Token: "", ExpectedErr: services.ErrAdmissionRequired},
+		{Name: "InvalidToken", Token: "token", ExpectedErr: services.ErrInvalidAdmissionToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := new(MockTicketsRepo)
+			mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+			mockRepo.On("GetTicket", mock.Anything, ticketID).Return(entities.Ticket{ID: ticketID, EventID: 2}, nil)
+
+			mockClient := new(MockCacheClient)
+			mockClient.On("MakeKey", ticketID).Return("1")
+			mockClient.On("Set", mock.Anything, "1", "123", time.Minute).Return(nil)
+
+			service := services.NewTicketsService(
+				mockRepo,
+				nil,
+				mockClient,
+				payment.NewFakeProcessor(nil, 0),
+				nil,
+				nil,
+				waitingRoomService,
+				time.Minute,
+				1,
+			)
+			access := entities.SaleAccess{AdmissionToken: tc.Token}
+			err := service.SetTicketHold(context.Background(), ticketID, "123", access)
+
+			if tc.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tc.ExpectedErr)
+				mockClient.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.Nil(t, err)
+			mockClient.AssertCalled(t, "Set", mock.Anything, "1", "123", time.Minute)
+		})
+	}
+}
+
+func TestTicketsServiceSetTicketHoldWithoutWaitingRoom(t *testing.T) {
+	ticketID := int32(1)
+
+	mockRoomsRepo := new(MockWaitingRoomsRepo)
+	mockRoomsRepo.On("GetWaitingRoom", mock.Anything, int32(2)).Return(entities.WaitingRoom{}, repos.ErrNoSuchEntity)
+	waitingRoomService := services.NewWaitingRoomService(mockRoomsRepo, nil, []byte("secret"), time.Second, time.Minute)
+
+	mockRepo := new(MockTicketsRepo)
+	mockRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+	mockRepo.On("GetTicket", mock.Anything, ticketID).Return(entities.Ticket{ID: ticketID, EventID: 2}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", ticketID).Return("1")
+	mockClient.On("Set", mock.Anything, "1", "123", time.Minute).Return(nil)
+
+	service := services.NewTicketsService(
+		mockRepo,
+		nil,
+		mockClient,
+		payment.NewFakeProcessor(nil, 0),
+		nil,
+		nil,
+		waitingRoomService,
+		time.Minute,
+		1,
+	)
+	err := service.SetTicketHold(context.Background(), ticketID, "123", entities.SaleAccess{})
+
+	assert.Nil(t, err)
+	mockClient.AssertCalled(t, "Set", mock.Anything, "1", "123", time.Minute)
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/cache"
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/repos"
+)
+
+// WaitlistsRepoer provides necessary methods for database operations against
+// waitlists.
+type WaitlistsRepoer interface {
+	JoinWaitlist(context.Context, entities.WaitlistEntry) error
+	GetWaitlistEntry(context.Context, int32, int32) (entities.WaitlistEntry, error)
+	LeaveWaitlist(context.Context, int32, int32) error
+	GetWaitlistedEvents(context.Context) ([]int32, error)
+	GetWaitingEntries(context.Context, int32, int32) ([]entities.WaitlistEntry, error)
+	SetWaitlistOffer(context.Context, entities.WaitlistEntry) error
+}
+
+// WaitlistService keeps the waitlists of sold-out events. Tickets that return
+// to inventory, e.g. as holds expire, tickets are refunded or new tickets are
+// released, are offered to the next users waiting on the event's waitlist by
+// a purchase hold that's exclusive to the user for `OfferDuration`.
+type WaitlistService struct {
+	repo           WaitlistsRepoer
+	ticketsService *TicketsService
+	OfferDuration  time.Duration
+}
+
+func NewWaitlistService(
+	repo WaitlistsRepoer,
+	ticketsService *TicketsService,
+	offerDuration time.Duration,
+) *WaitlistService {
+	return &WaitlistService{
+		repo:           repo,
+		ticketsService: ticketsService,
+		OfferDuration:  offerDuration,
+	}
+}
+
+// JoinWaitlist adds the entry's user to the waitlist of the entry's event,
+// waiting for the entry's quantity of tickets, and gives their place on it.
+// Only a sold-out event can be waitlisted, though a user that's already
+// waiting can change the quantity they're waiting for. A user that's been
+// offered tickets can only join again once the offer has expired, and goes to
+// the back of the waitlist.
+func (svc *WaitlistService) JoinWaitlist(
+	ctx context.Context,
+	entry entities.WaitlistEntry,
+) (entities.WaitlistEntry, error) {
+	if entry.Quantity < 1 {
+		return entities.WaitlistEntry{}, ErrEmptyHold
+	}
+
+	existing, err := svc.repo.GetWaitlistEntry(ctx, entry.EventID, entry.UserID)
+	isWaitlisted := err == nil
+	if err != nil && !errors.Is(err, repos.ErrNoSuchEntity) {
+		return entities.WaitlistEntry{}, err
+	}
+
+	status := existing.Status(time.Now())
+	if isWaitlisted && status == entities.WaitlistStatusOffered {
+		return entities.WaitlistEntry{}, ErrWaitlistOffered
+	}
+	if !isWaitlisted || status != entities.WaitlistStatusWaiting {
+		inventory, err := svc.ticketsService.GetAvailableInventory(ctx, entry.EventID)
+		if err != nil {
+			return entities.WaitlistEntry{}, err
+		}
+		if !inventory.IsSoldOut() {
+			return entities.WaitlistEntry{}, ErrNotSoldOut
+		}
+	}
+
+	if err := svc.repo.JoinWaitlist(ctx, entry); err != nil {
+		return entities.WaitlistEntry{}, err
+	}
+	return svc.repo.GetWaitlistEntry(ctx, entry.EventID, entry.UserID)
+}
+
+// GetWaitlistEntry fetches the user's entry on the event's waitlist, which
+// gives their place on it, or the offer made to them.
+func (svc *WaitlistService) GetWaitlistEntry(
+	ctx context.Context,
+	eventID int32,
+	userID int32,
+) (entities.WaitlistEntry, error) {
+	return svc.repo.GetWaitlistEntry(ctx, eventID, userID)
+}
+
+// LeaveWaitlist removes the user from the event's waitlist. A hold that was
+// offered to the user is kept until it expires.
+func (svc *WaitlistService) LeaveWaitlist(ctx context.Context, eventID int32, userID int32) error {
+	return svc.repo.LeaveWaitlist(ctx, eventID, userID)
+}
+
+// offerableTickets gives the inventory's seated tickets that are on general
+// sale at the given time, so that offers don't give early access to a
+// release's tickets. Only the fields needed to check whether a ticket is on
+// sale are set.
+func offerableTickets(inventory entities.AvailableInventory, at time.Time) []entities.Ticket {
+	offSaleReleaseIDs := make([]int32, 0, len(inventory.Releases))
+	for _, release := range inventory.Releases {
+		if !release.IsOnSale(at) {
+			offSaleReleaseIDs = append(offSaleReleaseIDs, release.ID)
+		}
+	}
+
+	tickets := []entities.Ticket{}
+	for _, aggregate := range inventory.Seated {
+		if slices.Contains(offSaleReleaseIDs, aggregate.ReleaseID) {
+			continue
+		}
+		for _, id := range aggregate.IDs {
+			tickets = append(tickets, entities.Ticket{
+				ID:           id,
+				TicketTypeID: aggregate.TicketTypeID,
+				ReleaseID:    aggregate.ReleaseID,
+			})
+		}
+	}
+	return tickets
+}
+
+// onSaleTicketIDs gives the ids of up to `quantity` of the tickets that the
+// user can purchase, as checked at checkout, in order. Tickets of the same
+// ticket type and release are on sale to the user alike, so are only checked
+// once.
+func (svc *WaitlistService) onSaleTicketIDs(
+	ctx context.Context,
+	tickets []entities.Ticket,
+	access entities.SaleAccess,
+	quantity int32,
+) ([]int32, error) {
+	type saleGroup struct {
+		TicketTypeID int32
+		ReleaseID    int32
+	}
+
+	onSale := map[saleGroup]bool{}
+	ticketIDs := []int32{}
+	for _, ticket := range tickets {
+		if int32(len(ticketIDs)) == quantity {
+			break
+		}
+
+		group := saleGroup{TicketTypeID: ticket.TicketTypeID, ReleaseID: ticket.ReleaseID}
+		isOnSale, ok := onSale[group]
+		if !ok {
+			err := svc.ticketsService.checkOnSale(ctx, []entities.Ticket{ticket}, access)
+			if err != nil &&
+				!errors.Is(err, ErrTicketTypeNotOnSale) &&
+				!errors.Is(err, ErrNotOnSale) &&
+				!errors.Is(err, ErrPresaleAccessDenied) {
+				return nil, err
+			}
+			isOnSale = err == nil
+			onSale[group] = isOnSale
+		}
+		if isOnSale {
+			ticketIDs = append(ticketIDs, ticket.ID)
+		}
+	}
+	return ticketIDs, nil
+}
+
+// offerEntry places a hold for the entry's user on the entry's quantity of
+// tickets, preferring the seated tickets given by `tickets` that the user can
+// purchase to the general admission tiers. The held tickets or quantity are
+// taken from what's available, and the hold's token and expiration are given.
+// If not enough is available for the entry, an empty token is given.
+func (svc *WaitlistService) offerEntry(
+	ctx context.Context,
+	entry entities.WaitlistEntry,
+	tickets *[]entities.Ticket,
+	tiers []entities.AvailableGATier,
+) (string, time.Time, error) {
+	holderID := strconv.Itoa(int(entry.UserID))
+	access := entities.SaleAccess{UserID: entry.UserID}
+
+	offered, err := svc.onSaleTicketIDs(ctx, *tickets, access, entry.Quantity)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if int(entry.Quantity) == len(offered) {
+		// The tickets are taken even if they can't be held, as they'd most
+		// likely fail to be held for the next user too. Tickets that aren't
+		// on sale to the user are left for the next users.
+		*tickets = slices.DeleteFunc(*tickets, func(ticket entities.Ticket) bool {
+			return slices.Contains(offered, ticket.ID)
+		})
+
+		counts := map[int32]int32{entry.EventID: entry.Quantity}
+		record := ticketHoldRecord{HolderID: holderID, TicketIDs: offered}
+		hold, err := svc.ticketsService.placeTicketsHold(ctx, record, counts, svc.OfferDuration)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return hold.Token, hold.ExpiresAt, nil
+	}
+
+	// Tickets issued for a tier aren't of a ticket type or part of a release,
+	// so a tier is on sale to every user.
+	tierIdx := slices.IndexFunc(tiers, func(tier entities.AvailableGATier) bool {
+		return tier.Remaining >= entry.Quantity
+	})
+	if tierIdx < 0 {
+		return "", time.Time{}, nil
+	}
+
+	tier, err := svc.ticketsService.repo.GetGATier(ctx, tiers[tierIdx].ID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	hold, err := svc.ticketsService.placeGATierHold(ctx, tier, entry.Quantity, holderID, svc.OfferDuration)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	tiers[tierIdx].Remaining -= entry.Quantity
+	return hold.Token, hold.ExpiresAt, nil
+}
+
+// offerEventTickets offers the event's available tickets to the users waiting
+// on its waitlist, in the order they joined, and gives how many users were
+// offered tickets. Users waiting for more tickets than are available are
+// skipped, and keep their place.
+func (svc *WaitlistService) offerEventTickets(ctx context.Context, eventID int32) (int, error) {
+	inventory, err := svc.ticketsService.GetAvailableInventory(ctx, eventID)
+	if err != nil {
+		return 0, err
+	}
+
+	tickets := offerableTickets(inventory, time.Now())
+	tiers := slices.Clone(inventory.GeneralAdmission)
+	available := int32(len(tickets))
+	for _, tier := range tiers {
+		available += tier.Remaining
+	}
+	if available == 0 {
+		return 0, nil
+	}
+
+	// Each entry is for at least one ticket, so at most as many users as there
+	// are tickets available can be offered them.
+	entries, err := svc.repo.GetWaitingEntries(ctx, eventID, available)
+	if err != nil {
+		return 0, err
+	}
+
+	countOffered := 0
+	for _, entry := range entries {
+		token, expiresAt, err := svc.offerEntry(ctx, entry, &tickets, tiers)
+		if errors.Is(err, cache.ErrAlreadyHasHold) ||
+			errors.Is(err, ErrGATierSoldOut) ||
+			errors.Is(err, ErrPurchaseLimitExceeded) {
+			// The tickets were held by another buyer first, or the user
+			// can't hold any more of the event's tickets, so the user keeps
+			// their place until the next offers.
+			continue
+		}
+		if err != nil {
+			return countOffered, err
+		}
+		if token == "" {
+			continue
+		}
+
+		entry.OfferToken = token
+		entry.OfferExpiresAt = expiresAt
+		if err := svc.repo.SetWaitlistOffer(ctx, entry); err != nil {
+			// The user left the waitlist in the meantime, so the hold is left
+			// to expire.
+			if errors.Is(err, repos.ErrNoSuchEntity) {
+				continue
+			}
+			return countOffered, err
+		}
+		countOffered++
+	}
+	return countOffered, nil
+}
+
+// OfferReturnedTickets offers the available tickets of each event with users
+// waiting on its waitlist, and gives how many users were offered tickets in
+// total.
+func (svc *WaitlistService) OfferReturnedTickets(ctx context.Context) (int, error) {
+	eventIDs, err := svc.repo.GetWaitlistedEvents(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	countOffered := 0
+	for _, eventID := range eventIDs {
+		count, err := svc.offerEventTickets(ctx, eventID)
+		countOffered += count
+		if err != nil {
+			return countOffered, err
+		}
+	}
+	return countOffered, nil
+}
+
+func (svc *WaitlistService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			countOffered, err := svc.OfferReturnedTickets(ctx)
+			if err != nil {
+				slog.Error("Issue offering tickets to waitlisted users", "error", err)
+			}
+			if countOffered > 0 {
+				slog.Info("Offered tickets to waitlisted users", "count", countOffered)
+			}
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dslaw/book-tickets/pkg/entities"
+	"github.com/dslaw/book-tickets/pkg/money"
+	"github.com/dslaw/book-tickets/pkg/payment"
+	"github.com/dslaw/book-tickets/pkg/repos"
+	"github.com/dslaw/book-tickets/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWaitlistsRepo struct {
+	mock.Mock
+}
+
+func (mock *MockWaitlistsRepo) JoinWaitlist(ctx context.Context, entry entities.WaitlistEntry) error {
+	args := mock.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (mock *MockWaitlistsRepo) GetWaitlistEntry(ctx context.Context, eventID, userID int32) (entities.WaitlistEntry, error) {
+	args := mock.Called(ctx, eventID, userID)
+	return args.Get(0).(entities.WaitlistEntry), args.Error(1)
+}
+
+func (mock *MockWaitlistsRepo) LeaveWaitlist(ctx context.Context, eventID, userID int32) error {
+	args := mock.Called(ctx, eventID, userID)
+	return args.Error(0)
+}
+
+func (mock *MockWaitlistsRepo) GetWaitlistedEvents(ctx context.Context) ([]int32, error) {
+	args := mock.Called(ctx)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (mock *MockWaitlistsRepo) GetWaitingEntries(
+	ctx context.Context,
+	eventID int32,
+	maxEntries int32,
+) ([]entities.WaitlistEntry, error) {
+	args := mock.Called(ctx, eventID, maxEntries)
+	return args.Get(0).([]entities.WaitlistEntry), args.Error(1)
+}
+
+func (mock *MockWaitlistsRepo) SetWaitlistOffer(ctx context.Context, entry entities.WaitlistEntry) error {
+	args := mock.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func TestWaitlistServiceJoinWaitlist(t *testing.T) {
+	entry := entities.WaitlistEntry{EventID: 1, UserID: 123, Quantity: 2}
+
+	mockTicketsRepo := new(MockTicketsRepo)
+	mockTicketsRepo.On("GetAvailableTickets", mock.Anything, int32(1)).Return([]entities.Ticket{}, nil)
+	mockTicketsRepo.On("GetEventGATiers", mock.Anything, int32(1)).Return([]entities.GATier{}, nil)
+	mockTicketsRepo.On("GetEventResaleListings", mock.Anything, int32(1)).Return([]entities.AvailableResaleListing{}, nil)
+
+	mockRepo := new(MockWaitlistsRepo)
+	mockRepo.On("GetWaitlistEntry", mock.Anything, int32(1), int32(123)).Return(
+		entities.WaitlistEntry{},
+		repos.ErrNoSuchEntity,
+	).Once()
+	mockRepo.On("JoinWaitlist", mock.Anything, entry).Return(nil)
+	mockRepo.On("GetWaitlistEntry", mock.Anything, int32(1), int32(123)).Return(
+		entities.WaitlistEntry{EventID: 1, UserID: 123, Quantity: 2, Position: 3},
+		nil,
+	)
+
+	ticketsService := services.NewTicketsService(mockTicketsRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	service := services.NewWaitlistService(mockRepo, ticketsService, time.Minute)
+	actual, err := service.JoinWaitlist(context.Background(), entry)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), actual.Position)
+	mockRepo.AssertCalled(t, "JoinWaitlist", mock.Anything, entry)
+}
+
+func TestWaitlistServiceJoinWaitlistWhenNotSoldOut(t *testing.T) {
+	entry := entities.WaitlistEntry{EventID: 1, UserID: 123, Quantity: 2}
+
+	mockTicketsRepo := new(MockTicketsRepo)
+	mockTicketsRepo.On("GetAvailableTickets", mock.Anything, int32(1)).Return(
+		[]entities.Ticket{{ID: 1, EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra"}},
+		nil,
+	)
+	mockTicketsRepo.On("GetEventGATiers", mock.Anything, int32(1)).Return([]entities.GATier{}, nil)
+	mockTicketsRepo.On("GetEventResaleListings", mock.Anything, int32(1)).Return([]entities.AvailableResaleListing{}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", int32(1)).Return("1")
+	mockClient.On("GetMany", mock.Anything, []string{"1"}).Return(map[string]string{}, nil)
+
+	mockRepo := new(MockWaitlistsRepo)
+	mockRepo.On("GetWaitlistEntry", mock.Anything, int32(1), int32(123)).Return(
+		entities.WaitlistEntry{},
+		repos.ErrNoSuchEntity,
+	)
+
+	ticketsService := services.NewTicketsService(mockTicketsRepo, nil, mockClient, nil, nil, nil, nil, time.Minute, 1)
+	service := services.NewWaitlistService(mockRepo, ticketsService, time.Minute)
+	_, err := service.JoinWaitlist(context.Background(), entry)
+
+	assert.ErrorIs(t, err, services.ErrNotSoldOut)
+	mockRepo.AssertNotCalled(t, "JoinWaitlist", mock.Anything, mock.Anything)
+}
+
+func TestWaitlistServiceJoinWaitlistWhenAlreadyWaiting(t *testing.T) {
+	entry := entities.WaitlistEntry{EventID: 1, UserID: 123, Quantity: 4}
+
+	mockTicketsRepo := new(MockTicketsRepo)
+
+	mockRepo := new(MockWaitlistsRepo)
+	mockRepo.On("GetWaitlistEntry", mock.Anything, int32(1), int32(123)).Return(
+		entities.WaitlistEntry{EventID: 1, UserID: 123, Quantity: 2, Position: 1},
+		nil,
+	)
+	mockRepo.On("JoinWaitlist", mock.Anything, entry).Return(nil)
+
+	ticketsService := services.NewTicketsService(mockTicketsRepo, nil, nil, nil, nil, nil, nil, time.Minute, 1)
+	service := services.NewWaitlistService(mockRepo, ticketsService, time.Minute)
+	_, err := service.JoinWaitlist(context.Background(), entry)
+
+	// The user keeps their place without the event needing to still be sold
+	// out.
+	assert.Nil(t, err)
+	mockRepo.AssertCalled(t, "JoinWaitlist", mock.Anything, entry)
+	mockTicketsRepo.AssertNotCalled(t, "GetAvailableTickets", mock.Anything, mock.Anything)
+}
+
+func TestWaitlistServiceJoinWaitlistWhenOffered(t *testing.T) {
+	entry := entities.WaitlistEntry{EventID: 1, UserID: 123, Quantity: 2}
+
+	mockRepo := new(MockWaitlistsRepo)
+	mockRepo.On("GetWaitlistEntry", mock.Anything, int32(1), int32(123)).Return(
+		entities.WaitlistEntry{
+			EventID:        1,
+			UserID:         123,
+			Quantity:       2,
+			OfferToken:     "token",
+			OfferExpiresAt: time.Now().Add(time.Minute),
+		},
+		nil,
+	)
+
+	service := services.NewWaitlistService(mockRepo, nil, time.Minute)
+	_, err := service.JoinWaitlist(context.Background(), entry)
+
+	assert.ErrorIs(t, err, services.ErrWaitlistOffered)
+	mockRepo.AssertNotCalled(t, "JoinWaitlist", mock.Anything, mock.Anything)
+}
+
+func TestWaitlistServiceOfferReturnedTickets(t *testing.T) {
+	offerDuration := 10 * time.Minute
+
+	mockTicketsRepo := new(MockTicketsRepo)
+	mockTicketsRepo.On("GetAvailableTickets", mock.Anything, int32(1)).Return(
+		[]entities.Ticket{
+			{ID: 1, EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra"},
+			{ID: 2, EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra"},
+		},
+		nil,
+	)
+	mockTicketsRepo.On("GetEventGATiers", mock.Anything, int32(1)).Return([]entities.GATier{}, nil)
+	mockTicketsRepo.On("GetEventResaleListings", mock.Anything, int32(1)).Return([]entities.AvailableResaleListing{}, nil)
+	mockTicketsRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", int32(1)).Return("1")
+	mockClient.On("MakeKey", int32(2)).Return("2")
+	mockClient.On("MakeHoldKey", mock.Anything).Return("hold")
+	mockClient.On("GetMany", mock.Anything, mock.Anything).Return(map[string]string{}, nil)
+	mockClient.On("SetMany", mock.Anything, mock.Anything, offerDuration).Return(nil)
+
+	mockRepo := new(MockWaitlistsRepo)
+	mockRepo.On("GetWaitlistedEvents", mock.Anything).Return([]int32{1}, nil)
+	mockRepo.On("GetWaitingEntries", mock.Anything, int32(1), int32(2)).Return(
+		[]entities.WaitlistEntry{
+			{EventID: 1, UserID: 123, Quantity: 3},
+			{EventID: 1, UserID: 456, Quantity: 2},
+		},
+		nil,
+	)
+	mockRepo.On("SetWaitlistOffer", mock.Anything, mock.Anything).Return(nil)
+
+	ticketsService := services.NewTicketsService(
+		mockTicketsRepo,
+		nil,
+		mockClient,
+		payment.NewFakeProcessor(nil, 0),
+		nil,
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	service := services.NewWaitlistService(mockRepo, ticketsService, offerDuration)
+	countOffered, err := service.OfferReturnedTickets(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, countOffered)
+
+	// The first user is waiting for more tickets than are available, so is
+	// skipped in favor of the next user.
+	mockRepo.AssertNumberOfCalls(t, "SetWaitlistOffer", 1)
+	offered := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(entities.WaitlistEntry)
+	assert.Equal(t, int32(456), offered.UserID)
+	assert.NotEmpty(t, offered.OfferToken)
+	assert.WithinDuration(t, time.Now().Add(offerDuration), offered.OfferExpiresAt, time.Second)
+
+	values := mockClient.Calls[len(mockClient.Calls)-1].Arguments.Get(1).(map[string]string)
+	assert.Equal(t, offered.OfferToken, values["1"])
+	assert.Equal(t, offered.OfferToken, values["2"])
+}
+
+func TestWaitlistServiceOfferReturnedTicketsForGATier(t *testing.T) {
+	offerDuration := 10 * time.Minute
+	tier := entities.GATier{ID: 2, EventID: 1, Name: "Floor", Price: money.New(1000, "USD"), Capacity: 100, Sold: 96}
+
+	mockTicketsRepo := new(MockTicketsRepo)
+	mockTicketsRepo.On("GetAvailableTickets", mock.Anything, int32(1)).Return([]entities.Ticket{}, nil)
+	mockTicketsRepo.On("GetEventGATiers", mock.Anything, int32(1)).Return([]entities.GATier{tier}, nil)
+	mockTicketsRepo.On("GetEventResaleListings", mock.Anything, int32(1)).Return([]entities.AvailableResaleListing{}, nil)
+	mockTicketsRepo.On("GetGATier", mock.Anything, int32(2)).Return(tier, nil)
+	mockTicketsRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeReservationsKey", int32(2)).Return("ga:2")
+	mockClient.On("MakeHoldKey", mock.Anything).Return("hold")
+	mockClient.On("GetReservedQuantity", mock.Anything, "ga:2").Return(1, nil)
+	mockClient.On("ReserveQuantity", mock.Anything, "ga:2", mock.Anything, 3, 4, offerDuration).Return(nil)
+	mockClient.On("Set", mock.Anything, "hold", mock.Anything, offerDuration).Return(nil)
+
+	mockRepo := new(MockWaitlistsRepo)
+	mockRepo.On("GetWaitlistedEvents", mock.Anything).Return([]int32{1}, nil)
+	mockRepo.On("GetWaitingEntries", mock.Anything, int32(1), int32(3)).Return(
+		[]entities.WaitlistEntry{{EventID: 1, UserID: 123, Quantity: 3}},
+		nil,
+	)
+	mockRepo.On("SetWaitlistOffer", mock.Anything, mock.Anything).Return(nil)
+
+	ticketsService := services.NewTicketsService(
+		mockTicketsRepo,
+		nil,
+		mockClient,
+		payment.NewFakeProcessor(nil, 0),
+		nil,
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	service := services.NewWaitlistService(mockRepo, ticketsService, offerDuration)
+	countOffered, err := service.OfferReturnedTickets(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, countOffered)
+
+	offered := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(entities.WaitlistEntry)
+	assert.Equal(t, int32(123), offered.UserID)
+	mockClient.AssertCalled(t, "ReserveQuantity", mock.Anything, "ga:2", offered.OfferToken, 3, 4, offerDuration)
+}
+
+func TestWaitlistServiceOfferReturnedTicketsWhenTicketTypeNotOnSale(t *testing.T) {
+	offerDuration := 10 * time.Minute
+	ticketType := entities.TicketType{
+		ID:           7,
+		EventID:      1,
+		Name:         "Student",
+		Price:        money.New(500, "USD"),
+		SaleStartsAt: time.Now().Add(time.Hour),
+	}
+
+	mockTicketsRepo := new(MockTicketsRepo)
+	mockTicketsRepo.On("GetAvailableTickets", mock.Anything, int32(1)).Return(
+		[]entities.Ticket{
+			{ID: 1, EventID: 1, Price: money.New(500, "USD"), Seat: "Balcony", TicketTypeID: 7},
+			{ID: 2, EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra"},
+			{ID: 3, EventID: 1, Price: money.New(1000, "USD"), Seat: "Orchestra"},
+		},
+		nil,
+	)
+	mockTicketsRepo.On("GetTicketTypes", mock.Anything, []int32{7}).Return([]entities.TicketType{ticketType}, nil)
+	mockTicketsRepo.On("GetEventGATiers", mock.Anything, int32(1)).Return([]entities.GATier{}, nil)
+	mockTicketsRepo.On("GetEventResaleListings", mock.Anything, int32(1)).Return([]entities.AvailableResaleListing{}, nil)
+	mockTicketsRepo.On("GetPurchaseLimits", mock.Anything, mock.Anything, mock.Anything).Return([]entities.PurchaseLimit{}, nil)
+
+	mockClient := new(MockCacheClient)
+	mockClient.On("MakeKey", int32(1)).Return("1")
+	mockClient.On("MakeKey", int32(2)).Return("2")
+	mockClient.On("MakeKey", int32(3)).Return("3")
+	mockClient.On("MakeHoldKey", mock.Anything).Return("hold")
+	mockClient.On("GetMany", mock.Anything, mock.Anything).Return(map[string]string{}, nil)
+	mockClient.On("SetMany", mock.Anything, mock.Anything, offerDuration).Return(nil)
+
+	mockRepo := new(MockWaitlistsRepo)
+	mockRepo.On("GetWaitlistedEvents", mock.Anything).Return([]int32{1}, nil)
+	mockRepo.On("GetWaitingEntries", mock.Anything, int32(1), int32(3)).Return(
+		[]entities.WaitlistEntry{
+			{EventID: 1, UserID: 123, Quantity: 3},
+			{EventID: 1, UserID: 456, Quantity: 2},
+		},
+		nil,
+	)
+	mockRepo.On("SetWaitlistOffer", mock.Anything, mock.Anything).Return(nil)
+
+	ticketsService := services.NewTicketsService(
+		mockTicketsRepo,
+		nil,
+		mockClient,
+		payment.NewFakeProcessor(nil, 0),
+		nil,
+		nil,
+		nil,
+		time.Minute,
+		1,
+	)
+	service := services.NewWaitlistService(mockRepo, ticketsService, offerDuration)
+	countOffered, err := service.OfferReturnedTickets(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, countOffered)
+
+	// The first user can't be offered the ticket whose type isn't on sale yet,
+	// so is skipped, and the next user is only offered tickets that are.
+	mockRepo.AssertNumberOfCalls(t, "SetWaitlistOffer", 1)
+	offered := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(entities.WaitlistEntry)
+	assert.Equal(t, int32(456), offered.UserID)
+
+	values := mockClient.Calls[len(mockClient.Calls)-1].Arguments.Get(1).(map[string]string)
+	assert.Equal(t, offered.OfferToken, values["2"])
+	assert.Equal(t, offered.OfferToken, values["3"])
+	assert.NotContains(t, values, "1")
+}